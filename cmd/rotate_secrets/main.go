@@ -0,0 +1,81 @@
+// Command rotate_secrets re-encrypts stored Chatwork tokens, CVE API keys and
+// notification channel credentials under the active key.
+//
+// With environment keys, add the new key to SECRETS_KEYS, point
+// SECRETS_ACTIVE_KEY_ID at it, run this command, then drop the old key.
+// With the local KMS (SECRETS_KMS_FILE), pass -new-key to generate and
+// activate a new key first. Plaintext rows left from before encryption was
+// enabled are encrypted as well.
+package main
+
+import (
+	"flag"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/secrets"
+)
+
+func main() {
+	newKey := flag.Bool("new-key", false, "generate and activate a new local KMS key before re-encrypting")
+	dryRun := flag.Bool("dry-run", false, "report what would be re-encrypted without writing")
+	flag.Parse()
+
+	// Load env package
+	configs.LoadEnv()
+
+	// Init logger
+	logger.Init()
+
+	keyring, kms, err := configs.LoadKeyring()
+	if err != nil {
+		logger.Fatalf("Failed to load secrets keyring: %v", err)
+	}
+	if keyring == nil {
+		logger.Fatal("Set SECRETS_KEYS or SECRETS_KMS_FILE before rotating secrets")
+	}
+	if *newKey {
+		if kms == nil {
+			logger.Fatal("-new-key needs the local KMS (SECRETS_KMS_FILE); with SECRETS_KEYS add the key to the environment instead")
+		}
+		if *dryRun {
+			logger.Fatal("-new-key cannot be combined with -dry-run")
+		}
+		id, err := kms.Rotate()
+		if err != nil {
+			logger.Fatalf("Failed to create a new key: %v", err)
+		}
+		logger.Infof("Activated new local KMS key %s", id)
+	}
+	secrets.SetDefault(keyring)
+
+	// MySQL database configuration
+	config := configs.DatabaseConfig{
+		Host:     utils.GetEnv("DB_HOST", "127.0.0.1"),
+		Port:     utils.GetEnv("DB_PORT", "3306"),
+		User:     utils.GetEnv("DB_USERNAME", ""),
+		Password: utils.GetEnv("DB_PASSWORD", ""),
+		DBName:   utils.GetEnv("DB_DATABASE", ""),
+		Charset:  "utf8mb4",
+	}
+	db := configs.InitDB(config)
+
+	results, err := secrets.Rotate(db, keyring, *dryRun,
+		&models.ChatworkBot{},
+		&models.ReminderSchedule{},
+		&models.CveConfig{},
+		&models.NotificationChannel{},
+		&models.WebhookEndpoint{},
+	)
+	for _, r := range results {
+		logger.Infof("%s.%s: %d value(s), %d re-encrypted", r.Table, r.Column, r.Scanned, r.Rotated)
+	}
+	if err != nil {
+		logger.Fatalf("Rotation stopped: %v", err)
+	}
+	if *dryRun {
+		logger.Info("Dry run: nothing was written")
+	}
+}
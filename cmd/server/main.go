@@ -4,12 +4,12 @@ import (
 	"fmt"
 
 	"github.com/vfa-khuongdv/golang-cms/internal/configs"
-	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
 	"github.com/vfa-khuongdv/golang-cms/internal/routes"
 	"github.com/vfa-khuongdv/golang-cms/internal/services"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
 	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
 	"github.com/vfa-khuongdv/golang-cms/pkg/migrator"
+	"github.com/vfa-khuongdv/golang-cms/pkg/secrets"
 	"gorm.io/gorm"
 )
 
@@ -53,6 +53,19 @@ func main() {
 	// Initialize logger
 	logger.Init()
 
+	// Load the keyring for stored tokens and API keys before any DB access
+	keyring, _, err := configs.LoadKeyring()
+	if err != nil {
+		logger.Fatalf("Failed to load secrets keyring: %v", err)
+	}
+	if keyring == nil {
+		if !configs.PlaintextSecretsAllowed() {
+			logger.Fatal("Set SECRETS_KEYS or SECRETS_KMS_FILE to encrypt Chatwork tokens and API keys at rest, or SECRETS_ALLOW_PLAINTEXT=true to store them unencrypted")
+		}
+		logger.Warn("!!! SECRETS_ALLOW_PLAINTEXT=true: Chatwork tokens and API keys are stored UNENCRYPTED. Do not use this in production !!!")
+	}
+	secrets.SetDefault(keyring)
+
 	// Initialize database
 	db := initializeDatabase()
 
@@ -63,24 +76,22 @@ func main() {
 		runMigrations()
 	}
 
-	// Initialize and start cron service
+	// Setup routes; this also wires the cron service's dependencies
 	cronService := services.NewCronService(db)
+	router := routes.SetupRouter(db, cronService)
+
+	// Register the cron jobs and start the cron service
 	cronService.LoadFromDB()
 	cronService.RegisterCVECrawler()
-
-	// Register CVE config cron jobs
-	cveConfigRepo := repositories.NewCveConfigRepository(db)
-	cveScanLogRepo := repositories.NewCveScanLogRepository(db)
-	chatworkBotRepo := repositories.NewChatworkBotRepository(db)
-	cveConfigService := services.NewCveConfigService(cveConfigRepo, cveScanLogRepo, chatworkBotRepo)
-	services.SetCveConfigService(cveConfigService)
+	cronService.RegisterTaskFollowUps()
+	cronService.RegisterBotDirectorySync()
+	cronService.RegisterBotHealthCheck()
+	cronService.RegisterBotRequestRules()
+	cronService.RegisterWebhookFlush()
+	cronService.RegisterEscalations()
 	cronService.RegisterCVEConfigs()
-
 	cronService.Start()
 
-	// Setup routes
-	router := routes.SetupRouter(db, cronService)
-
 	// Start server
 	port := fmt.Sprintf(":%s", utils.GetEnv("PORT", "3000"))
 	if err := router.Run(port); err != nil {
@@ -26,7 +26,7 @@ func main() {
 	fmt.Printf("Room ID: %s\n", roomID)
 	fmt.Printf("NVD API Key: %s\n", nvdAPIKey)
 
-	cveService := services.NewCveCrawlerService(roomID, apiKey, nvdAPIKey)
+	cveService := services.NewCveCrawlerService(roomID, apiKey, nvdAPIKey, nil, nil)
 	cveService.CrawlAndNotify()
 
 	fmt.Println("Done!")
@@ -16,3 +16,9 @@ func LoadEnv() {
 func GetFrontendURL() string {
 	return os.Getenv("FRONTEND_URL")
 }
+
+// GetPublicAPIURL is the externally reachable base URL of this API, used to
+// show full inbound webhook URLs. Empty means derive it from the request.
+func GetPublicAPIURL() string {
+	return os.Getenv("PUBLIC_API_URL")
+}
@@ -8,7 +8,8 @@ import (
 )
 
 type CustomClaims struct {
-	ID                   uint `json:"id"` // Custom field
+	ID                   uint `json:"id"`  // Custom field
+	SessionID            uint `json:"sid"` // Session the token belongs to; revoking it ends the token
 	jwt.RegisteredClaims      // // Embed standard claims
 }
 
@@ -22,14 +23,16 @@ type JwtResult struct {
 // GenerateToken creates a new JWT token for the given email
 // Parameters:
 //   - id: the userId to be included in the token claims
+//   - sessionID: the sign-in session the token is issued for
 //
 // Returns:
 //   - *JwtResult: contains the signed token string and expiration timestamp
 //   - error: any error that occurred during token generation
-func GenerateToken(id uint) (*JwtResult, error) {
+func GenerateToken(id uint, sessionID uint) (*JwtResult, error) {
 	expiresAt := jwt.NewNumericDate(time.Now().Add(time.Hour))
 	claims := CustomClaims{
-		ID: id,
+		ID:        id,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: expiresAt,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,19 @@
+package configs
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/mailer"
+)
+
+// LoadMailerConfig reads the SMTP settings from the environment.
+// ok is false when SMTP_HOST is not set, meaning email delivery is disabled.
+func LoadMailerConfig() (config mailer.GomailSenderConfig, ok bool) {
+	config = mailer.GomailSenderConfig{
+		From:     utils.GetEnv("SMTP_FROM", ""),
+		Host:     utils.GetEnv("SMTP_HOST", ""),
+		Port:     utils.GetEnvAsInt("SMTP_PORT", 587),
+		Username: utils.GetEnv("SMTP_USERNAME", ""),
+		Password: utils.GetEnv("SMTP_PASSWORD", ""),
+	}
+	return config, config.Host != ""
+}
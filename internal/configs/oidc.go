@@ -0,0 +1,40 @@
+package configs
+
+import (
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/oidc"
+)
+
+// OIDCConfig is the single sign-on provider and how its users are mapped.
+type OIDCConfig struct {
+	oidc.Config
+	// GroupsClaim is the ID token claim listing the user's groups.
+	GroupsClaim string
+	// AutoProvision creates an account on first sign-in; otherwise only
+	// existing users can sign in with the provider.
+	AutoProvision bool
+}
+
+// LoadOIDCConfig reads the single sign-on settings from the environment.
+// ok is false when OIDC_ISSUER is not set, meaning single sign-on is disabled.
+func LoadOIDCConfig() (config OIDCConfig, ok bool) {
+	config = OIDCConfig{
+		Config: oidc.Config{
+			Issuer:       utils.GetEnv("OIDC_ISSUER", ""),
+			ClientID:     utils.GetEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: utils.GetEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  utils.GetEnv("OIDC_REDIRECT_URL", ""),
+			Scopes:       strings.Fields(strings.ReplaceAll(utils.GetEnv("OIDC_SCOPES", ""), ",", " ")),
+		},
+		GroupsClaim:   utils.GetEnv("OIDC_GROUPS_CLAIM", "groups"),
+		AutoProvision: utils.GetEnv("OIDC_AUTO_PROVISION", "true") == "true",
+	}
+	// The provider sends the browser back to the dashboard, which posts the
+	// code to /api/v2/auth/oidc/callback
+	if config.RedirectURL == "" && GetFrontendURL() != "" {
+		config.RedirectURL = strings.TrimRight(GetFrontendURL(), "/") + "/auth/oidc/callback"
+	}
+	return config, config.Issuer != ""
+}
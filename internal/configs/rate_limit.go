@@ -0,0 +1,139 @@
+package configs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/ratelimit"
+)
+
+// RateLimitPolicy is how one route group is throttled.
+type RateLimitPolicy struct {
+	// Name prefixes the group's counters, e.g. "login".
+	Name string
+	// PerIP limits each client IP.
+	PerIP ratelimit.Rule
+	// PerCredential limits the attempts on one credential (an email, a
+	// project) from all IPs together.
+	PerCredential ratelimit.Rule
+	// Global limits the group as a whole.
+	Global ratelimit.Rule
+	// Lockout locks a credential out for one IP after failed attempts from it.
+	Lockout ratelimit.Backoff
+}
+
+// RateLimitConfig holds the policy of each throttled route group.
+type RateLimitConfig struct {
+	// Store is "memory" or "mysql" to share counters between replicas.
+	Store string
+	Login RateLimitPolicy
+	// Auth covers the public auth routes that take a token rather than an
+	// email, so there is no credential to count or lock out: per IP only.
+	Auth          RateLimitPolicy
+	ProjectAccess RateLimitPolicy
+	CVETest       RateLimitPolicy
+}
+
+// LoadRateLimitConfig reads the rate limit settings from the environment.
+// Each group reads RATE_LIMIT_<GROUP>_PER_IP, _PER_CREDENTIAL and _GLOBAL
+// as "<limit>/<window>" or "off", and _LOCKOUT_AFTER (failures, 0 for no
+// lockout), _LOCKOUT_BASE, _LOCKOUT_MAX and _LOCKOUT_FORGET as durations.
+func LoadRateLimitConfig() (RateLimitConfig, error) {
+	config := RateLimitConfig{Store: strings.ToLower(utils.GetEnv("RATE_LIMIT_STORE", "memory"))}
+	if config.Store != "memory" && config.Store != "mysql" {
+		return config, fmt.Errorf("RATE_LIMIT_STORE must be memory or mysql, got %q", config.Store)
+	}
+
+	var err error
+	lockout := ratelimit.Backoff{After: 5, Base: time.Minute, Max: time.Hour, Forget: ratelimit.DefaultForget}
+	if config.Login, err = loadRateLimitPolicy("LOGIN", RateLimitPolicy{
+		Name:          "login",
+		PerIP:         ratelimit.Rule{Limit: 20, Window: time.Minute},
+		PerCredential: ratelimit.Rule{Limit: 10, Window: 15 * time.Minute},
+		Lockout:       lockout,
+	}); err != nil {
+		return config, err
+	}
+	if config.Auth, err = loadRateLimitPolicy("AUTH", RateLimitPolicy{
+		Name:  "auth",
+		PerIP: ratelimit.Rule{Limit: 20, Window: time.Minute},
+	}); err != nil {
+		return config, err
+	}
+	if config.ProjectAccess, err = loadRateLimitPolicy("PROJECT_ACCESS", RateLimitPolicy{
+		Name:          "project-access",
+		PerIP:         ratelimit.Rule{Limit: 20, Window: time.Minute},
+		PerCredential: ratelimit.Rule{Limit: 30, Window: 15 * time.Minute},
+		Lockout:       lockout,
+	}); err != nil {
+		return config, err
+	}
+	// Each test queries OSV, so the group also has a global limit
+	if config.CVETest, err = loadRateLimitPolicy("CVE_TEST", RateLimitPolicy{
+		Name:   "cve-test",
+		PerIP:  ratelimit.Rule{Limit: 10, Window: time.Minute},
+		Global: ratelimit.Rule{Limit: 60, Window: time.Minute},
+	}); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+func loadRateLimitPolicy(group string, policy RateLimitPolicy) (RateLimitPolicy, error) {
+	prefix := "RATE_LIMIT_" + group + "_"
+	rules := []struct {
+		env  string
+		rule *ratelimit.Rule
+	}{
+		{"PER_IP", &policy.PerIP},
+		{"PER_CREDENTIAL", &policy.PerCredential},
+		{"GLOBAL", &policy.Global},
+	}
+	for _, r := range rules {
+		value := utils.GetEnv(prefix+r.env, "")
+		if value == "" {
+			continue
+		}
+		rule, err := ratelimit.ParseRule(value)
+		if err != nil {
+			return policy, fmt.Errorf("%s%s: %w", prefix, r.env, err)
+		}
+		*r.rule = rule
+	}
+
+	policy.Lockout.After = utils.GetEnvAsInt(prefix+"LOCKOUT_AFTER", policy.Lockout.After)
+	durations := []struct {
+		env string
+		d   *time.Duration
+	}{
+		{"LOCKOUT_BASE", &policy.Lockout.Base},
+		{"LOCKOUT_MAX", &policy.Lockout.Max},
+		{"LOCKOUT_FORGET", &policy.Lockout.Forget},
+	}
+	for _, d := range durations {
+		value := utils.GetEnv(prefix+d.env, "")
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return policy, fmt.Errorf("%s%s must be a positive duration such as 1m", prefix, d.env)
+		}
+		*d.d = parsed
+	}
+	if policy.Lockout.After > 0 && policy.Lockout.Base <= 0 {
+		policy.Lockout.Base = time.Minute
+	}
+	return policy, nil
+}
+
+// String summarizes the policy for the startup log.
+func (p RateLimitPolicy) String() string {
+	lockout := "off"
+	if p.Lockout.Enabled() {
+		lockout = fmt.Sprintf("after %d failures within %s, %s up to %s", p.Lockout.After, p.Lockout.Forget, p.Lockout.Base, p.Lockout.Max)
+	}
+	return fmt.Sprintf("%s: per IP %s, per credential %s, global %s, lockout %s", p.Name, p.PerIP, p.PerCredential, p.Global, lockout)
+}
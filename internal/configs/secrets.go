@@ -0,0 +1,52 @@
+package configs
+
+import (
+	"fmt"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/secrets"
+)
+
+// LoadKeyring builds the keyring that encrypts stored tokens and API keys.
+// SECRETS_KMS_FILE selects the local KMS stand-in; otherwise SECRETS_KEYS
+// ("id:base64key,...") and SECRETS_ACTIVE_KEY_ID are used. The keyring is nil
+// when neither is set; the server refuses to start then unless
+// PlaintextSecretsAllowed.
+func LoadKeyring() (*secrets.Keyring, *secrets.LocalKMS, error) {
+	if path := utils.GetEnv("SECRETS_KMS_FILE", ""); path != "" {
+		kms, err := secrets.OpenLocalKMS(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return secrets.NewKeyring(kms), kms, nil
+	}
+
+	spec := utils.GetEnv("SECRETS_KEYS", "")
+	if spec == "" {
+		return nil, nil, nil
+	}
+	keys, err := secrets.ParseKeys(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	active := utils.GetEnv("SECRETS_ACTIVE_KEY_ID", "")
+	if active == "" && len(keys) == 1 {
+		for id := range keys {
+			active = id
+		}
+	}
+	if active == "" {
+		return nil, nil, fmt.Errorf("SECRETS_ACTIVE_KEY_ID is required when SECRETS_KEYS has several keys")
+	}
+	provider, err := secrets.NewStaticKeys(active, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return secrets.NewKeyring(provider), nil, nil
+}
+
+// PlaintextSecretsAllowed reports whether SECRETS_ALLOW_PLAINTEXT=true opts
+// out of encryption at rest, e.g. for local development.
+func PlaintextSecretsAllowed() bool {
+	return utils.GetEnv("SECRETS_ALLOW_PLAINTEXT", "false") == "true"
+}
@@ -44,3 +44,52 @@ var CVELanguages = []string{
 	"flutter",
 	"react native",
 }
+
+// CPEProduct is the NVD CPE vendor/product pair for a well-known technology.
+type CPEProduct struct {
+	Vendor  string
+	Product string
+}
+
+// CPEProducts maps the tech names of CVELanguages to their CPE vendor/product,
+// so a project's tech stack can be declared by name and matched against NVD data.
+var CPEProducts = map[string]CPEProduct{
+	"npm":           {Vendor: "npmjs", Product: "npm"},
+	"nodejs":        {Vendor: "nodejs", Product: "node.js"},
+	"react":         {Vendor: "facebook", Product: "react"},
+	"vue":           {Vendor: "vuejs", Product: "vue.js"},
+	"angular":       {Vendor: "angular", Product: "angular"},
+	"typescript":    {Vendor: "microsoft", Product: "typescript"},
+	"yarn":          {Vendor: "yarnpkg", Product: "yarn"},
+	"webpack":       {Vendor: "webpack.js", Product: "webpack"},
+	"java":          {Vendor: "oracle", Product: "jdk"},
+	"spring":        {Vendor: "vmware", Product: "spring_framework"},
+	"spring boot":   {Vendor: "vmware", Product: "spring_boot"},
+	"php":           {Vendor: "php", Product: "php"},
+	"laravel":       {Vendor: "laravel", Product: "framework"},
+	"python":        {Vendor: "python", Product: "python"},
+	"django":        {Vendor: "djangoproject", Product: "django"},
+	"flask":         {Vendor: "palletsprojects", Product: "flask"},
+	"golang":        {Vendor: "golang", Product: "go"},
+	"go":            {Vendor: "golang", Product: "go"},
+	"rust":          {Vendor: "rust-lang", Product: "rust"},
+	"ruby":          {Vendor: "ruby-lang", Product: "ruby"},
+	"rails":         {Vendor: "rubyonrails", Product: "rails"},
+	".net":          {Vendor: "microsoft", Product: ".net"},
+	"dotnet":        {Vendor: "microsoft", Product: ".net"},
+	"mysql":         {Vendor: "oracle", Product: "mysql"},
+	"mongodb":       {Vendor: "mongodb", Product: "mongodb"},
+	"postgresql":    {Vendor: "postgresql", Product: "postgresql"},
+	"redis":         {Vendor: "redis", Product: "redis"},
+	"elasticsearch": {Vendor: "elastic", Product: "elasticsearch"},
+	"docker":        {Vendor: "docker", Product: "docker"},
+	"kubernetes":    {Vendor: "kubernetes", Product: "kubernetes"},
+	"jenkins":       {Vendor: "jenkins", Product: "jenkins"},
+	"nginx":         {Vendor: "f5", Product: "nginx"},
+	"apache":        {Vendor: "apache", Product: "http_server"},
+	"openssl":       {Vendor: "openssl", Product: "openssl"},
+	"android":       {Vendor: "google", Product: "android"},
+	"ios":           {Vendor: "apple", Product: "iphone_os"},
+	"flutter":       {Vendor: "google", Product: "flutter"},
+	"react native":  {Vendor: "facebook", Product: "react-native"},
+}
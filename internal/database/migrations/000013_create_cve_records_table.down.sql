@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS cve_record_cpes;
+DROP TABLE IF EXISTS cve_record_cwes;
+DROP TABLE IF EXISTS cve_records;
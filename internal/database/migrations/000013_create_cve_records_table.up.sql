@@ -0,0 +1,46 @@
+-- Create cve_records table (CVE data persisted from the NVD crawler)
+CREATE TABLE IF NOT EXISTS cve_records (
+    cve_id VARCHAR(50) NOT NULL PRIMARY KEY,
+    description TEXT,
+    severity VARCHAR(20),
+    base_score DECIMAL(4,1) DEFAULT 0,
+    cvss_version VARCHAR(10),
+    vector_string VARCHAR(255),
+    metrics JSON,
+    `references` JSON,
+    published_at DATETIME NOT NULL,
+    last_modified_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_cve_records_severity (severity),
+    INDEX idx_cve_records_base_score (base_score),
+    INDEX idx_cve_records_published_at (published_at),
+    FULLTEXT INDEX ft_cve_records_description (description)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS cve_record_cwes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    cve_id VARCHAR(50) NOT NULL,
+    cwe_id VARCHAR(30) NOT NULL,
+    INDEX idx_cve_record_cwes_cve_id (cve_id),
+    INDEX idx_cve_record_cwes_cwe_id (cwe_id),
+    FOREIGN KEY (cve_id) REFERENCES cve_records(cve_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS cve_record_cpes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    cve_id VARCHAR(50) NOT NULL,
+    criteria VARCHAR(500) NOT NULL,
+    vendor VARCHAR(255),
+    product VARCHAR(255),
+    version VARCHAR(100),
+    vulnerable BOOLEAN DEFAULT TRUE,
+    version_start_including VARCHAR(100),
+    version_start_excluding VARCHAR(100),
+    version_end_including VARCHAR(100),
+    version_end_excluding VARCHAR(100),
+    INDEX idx_cve_record_cpes_cve_id (cve_id),
+    INDEX idx_cve_record_cpes_vendor (vendor),
+    INDEX idx_cve_record_cpes_product (product),
+    FOREIGN KEY (cve_id) REFERENCES cve_records(cve_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
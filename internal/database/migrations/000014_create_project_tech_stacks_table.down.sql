@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS cpe_findings;
+DROP TABLE IF EXISTS project_tech_stacks;
+ALTER TABLE `projects` DROP COLUMN `alert_bot_id`, DROP COLUMN `alert_room_id`;
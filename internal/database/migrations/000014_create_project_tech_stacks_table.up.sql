@@ -0,0 +1,38 @@
+-- Tech stack inventory and CPE findings per project
+ALTER TABLE `projects`
+  ADD COLUMN `alert_room_id` VARCHAR(255) NULL DEFAULT NULL,
+  ADD COLUMN `alert_bot_id` INT UNSIGNED NULL DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS project_tech_stacks (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    project_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    vendor VARCHAR(255) NOT NULL,
+    product VARCHAR(255) NOT NULL,
+    version VARCHAR(100),
+    cpe VARCHAR(500) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME,
+    INDEX idx_tech_stack_project_id (project_id),
+    INDEX idx_tech_stack_product (product),
+    INDEX idx_project_tech_stacks_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS cpe_findings (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    project_id BIGINT UNSIGNED NOT NULL,
+    tech_stack_id BIGINT UNSIGNED NOT NULL,
+    cve_id VARCHAR(50) NOT NULL,
+    severity VARCHAR(20),
+    score DECIMAL(4,1) DEFAULT 0,
+    product VARCHAR(255),
+    version VARCHAR(100),
+    matched_criteria VARCHAR(500),
+    summary TEXT,
+    notified_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_cpe_finding_project_id (project_id),
+    INDEX idx_cpe_findings_cve_id (cve_id),
+    UNIQUE KEY uq_cpe_finding (tech_stack_id, cve_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+ALTER TABLE `cve_configs` DROP COLUMN `channel_id`;
+ALTER TABLE `reminder_schedules` DROP COLUMN `channel_id`;
+DROP TABLE IF EXISTS notification_channels;
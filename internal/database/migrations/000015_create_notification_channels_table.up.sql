@@ -0,0 +1,25 @@
+-- Notification channels and channel targets for schedules and CVE configs
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    project_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    room_id VARCHAR(255),
+    bot_id INT UNSIGNED NULL,
+    token VARCHAR(255),
+    webhook_url TEXT,
+    secret VARCHAR(255),
+    recipients JSON NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME,
+    INDEX idx_notification_channel_project_id (project_id),
+    INDEX idx_notification_channels_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `reminder_schedules`
+  ADD COLUMN `channel_id` BIGINT UNSIGNED NULL DEFAULT NULL;
+
+ALTER TABLE `cve_configs`
+  ADD COLUMN `channel_id` BIGINT UNSIGNED NULL DEFAULT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE `cve_configs`
+  DROP COLUMN `email_recipients`,
+  DROP COLUMN `email_attach_csv`,
+  DROP COLUMN `email_digest`;
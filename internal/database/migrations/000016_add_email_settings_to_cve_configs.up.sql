@@ -0,0 +1,5 @@
+-- Email delivery settings for CVE scan reports and the daily crawler digest
+ALTER TABLE `cve_configs`
+  ADD COLUMN `email_recipients` JSON NULL,
+  ADD COLUMN `email_attach_csv` BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN `email_digest` BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,12 @@
+ALTER TABLE `schedule_logs`
+  DROP INDEX `idx_log_follow_up`,
+  DROP COLUMN `task_ids`,
+  DROP COLUMN `task_room_id`,
+  DROP COLUMN `follow_up_at`,
+  DROP COLUMN `follow_up_status`;
+
+ALTER TABLE `reminder_schedules`
+  DROP COLUMN `action_type`,
+  DROP COLUMN `task_assignee_ids`,
+  DROP COLUMN `task_deadline`,
+  DROP COLUMN `task_follow_up`;
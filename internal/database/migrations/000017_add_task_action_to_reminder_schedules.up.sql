@@ -0,0 +1,13 @@
+-- Schedules can create Chatwork tasks instead of posting a message
+ALTER TABLE `reminder_schedules`
+  ADD COLUMN `action_type` VARCHAR(20) NOT NULL DEFAULT 'message',
+  ADD COLUMN `task_assignee_ids` JSON NULL,
+  ADD COLUMN `task_deadline` VARCHAR(20) NULL,
+  ADD COLUMN `task_follow_up` VARCHAR(20) NULL;
+
+ALTER TABLE `schedule_logs`
+  ADD COLUMN `task_ids` JSON NULL,
+  ADD COLUMN `task_room_id` VARCHAR(255) NULL,
+  ADD COLUMN `follow_up_at` DATETIME(3) NULL,
+  ADD COLUMN `follow_up_status` VARCHAR(20) NULL,
+  ADD INDEX `idx_log_follow_up` (`follow_up_at`);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS `chatwork_bot_room_members`;
+DROP TABLE IF EXISTS `chatwork_bot_rooms`;
+
+ALTER TABLE `chatwork_bots`
+  DROP COLUMN `account_id`,
+  DROP COLUMN `chatwork_id`,
+  DROP COLUMN `name`,
+  DROP COLUMN `avatar_url`,
+  DROP COLUMN `rooms_count`,
+  DROP COLUMN `synced_at`,
+  DROP COLUMN `sync_error`;
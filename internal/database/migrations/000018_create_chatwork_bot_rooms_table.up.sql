@@ -0,0 +1,39 @@
+-- Cached profile of each bot, refreshed by the directory sync
+ALTER TABLE `chatwork_bots`
+  ADD COLUMN `account_id` BIGINT NULL,
+  ADD COLUMN `chatwork_id` VARCHAR(255) NULL,
+  ADD COLUMN `name` VARCHAR(255) NULL,
+  ADD COLUMN `avatar_url` TEXT NULL,
+  ADD COLUMN `rooms_count` INT NOT NULL DEFAULT 0,
+  ADD COLUMN `synced_at` DATETIME(3) NULL,
+  ADD COLUMN `sync_error` TEXT NULL;
+
+CREATE TABLE IF NOT EXISTS `chatwork_bot_rooms` (
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `bot_id`       INT UNSIGNED NOT NULL,
+    `room_id`      BIGINT NOT NULL,
+    `name`         VARCHAR(255),
+    `type`         VARCHAR(20),
+    `role`         VARCHAR(20),
+    `icon_path`    TEXT,
+    `member_count` INT NOT NULL DEFAULT 0,
+    `synced_at`    DATETIME(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uq_bot_room` (`bot_id`, `room_id`),
+    INDEX `idx_bot_rooms_room_id` (`room_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `chatwork_bot_room_members` (
+    `id`                BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `bot_id`            INT UNSIGNED NOT NULL,
+    `room_id`           BIGINT NOT NULL,
+    `account_id`        BIGINT NOT NULL,
+    `name`              VARCHAR(255),
+    `role`              VARCHAR(20),
+    `chatwork_id`       VARCHAR(255),
+    `organization_name` VARCHAR(255),
+    `department`        VARCHAR(255),
+    `avatar_image_url`  TEXT,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uq_bot_room_member` (`bot_id`, `room_id`, `account_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
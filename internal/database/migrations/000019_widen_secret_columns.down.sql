@@ -0,0 +1,13 @@
+-- Encrypted values are longer than 255 characters; roll back only with plaintext values in place
+ALTER TABLE `chatwork_bots`
+  MODIFY COLUMN `api_token` VARCHAR(255) NOT NULL;
+
+ALTER TABLE `reminder_schedules`
+  MODIFY COLUMN `chatwork_token` varchar(255) COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL;
+
+ALTER TABLE `cve_configs`
+  MODIFY COLUMN `api_key` VARCHAR(255) NULL;
+
+ALTER TABLE `notification_channels`
+  MODIFY COLUMN `token` VARCHAR(255) NULL,
+  MODIFY COLUMN `secret` VARCHAR(255) NULL;
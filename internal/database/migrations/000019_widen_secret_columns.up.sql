@@ -0,0 +1,13 @@
+-- Encrypted tokens and API keys no longer fit in VARCHAR(255)
+ALTER TABLE `chatwork_bots`
+  MODIFY COLUMN `api_token` TEXT NOT NULL;
+
+ALTER TABLE `reminder_schedules`
+  MODIFY COLUMN `chatwork_token` TEXT COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL;
+
+ALTER TABLE `cve_configs`
+  MODIFY COLUMN `api_key` TEXT NULL;
+
+ALTER TABLE `notification_channels`
+  MODIFY COLUMN `token` TEXT NULL,
+  MODIFY COLUMN `secret` TEXT NULL;
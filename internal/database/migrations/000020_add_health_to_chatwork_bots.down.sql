@@ -0,0 +1,4 @@
+ALTER TABLE `chatwork_bots`
+  DROP COLUMN `health_status`,
+  DROP COLUMN `health_checked_at`,
+  DROP COLUMN `health_error`;
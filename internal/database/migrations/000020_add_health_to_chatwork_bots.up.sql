@@ -0,0 +1,5 @@
+-- Token health as last seen by the periodic /me check
+ALTER TABLE `chatwork_bots`
+  ADD COLUMN `health_status` VARCHAR(20) NOT NULL DEFAULT 'unknown',
+  ADD COLUMN `health_checked_at` DATETIME(3) NULL,
+  ADD COLUMN `health_error` TEXT NULL;
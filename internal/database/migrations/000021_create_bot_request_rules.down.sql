@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `bot_request_audits`;
+DROP TABLE IF EXISTS `bot_request_rules`;
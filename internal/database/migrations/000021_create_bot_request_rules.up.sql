@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS `bot_request_rules` (
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `name`       VARCHAR(255) NOT NULL,
+    `bot_id`     INT UNSIGNED NULL,
+    `field`      VARCHAR(20) NOT NULL,
+    `match_values` JSON NULL,
+    `action`     VARCHAR(10) NOT NULL,
+    `priority`   INT NOT NULL DEFAULT 0,
+    `active`     TINYINT(1) NOT NULL DEFAULT 1,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_bot_request_rules_bot_id` (`bot_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Every decision about an incoming contact request: by rule, by admin, or queued for review
+CREATE TABLE IF NOT EXISTS `bot_request_audits` (
+    `id`                BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `bot_id`            INT UNSIGNED NOT NULL,
+    `request_id`        BIGINT NOT NULL,
+    `account_id`        BIGINT NULL,
+    `name`              VARCHAR(255) NULL,
+    `chatwork_id`       VARCHAR(255) NULL,
+    `organization_name` VARCHAR(255) NULL,
+    `message`           TEXT NULL,
+    `decision`          VARCHAR(20) NOT NULL,
+    `source`            VARCHAR(20) NOT NULL,
+    `rule_id`           INT UNSIGNED NULL,
+    `rule_name`         VARCHAR(255) NULL,
+    `error`             TEXT NULL,
+    `created_at`        DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_bot_request_audits_request` (`bot_id`, `request_id`),
+    INDEX `idx_bot_request_audits_decision` (`decision`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+ALTER TABLE `bot_request_audits`
+  DROP COLUMN `actor`;
+
+DROP TABLE IF EXISTS `bot_requests`;
@@ -0,0 +1,26 @@
+-- Local history of incoming contact requests; Chatwork drops them once decided
+CREATE TABLE IF NOT EXISTS `bot_requests` (
+    `id`                BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `bot_id`            INT UNSIGNED NOT NULL,
+    `request_id`        BIGINT NOT NULL,
+    `account_id`        BIGINT NULL,
+    `name`              VARCHAR(255) NULL,
+    `chatwork_id`       VARCHAR(255) NULL,
+    `organization_name` VARCHAR(255) NULL,
+    `department`        VARCHAR(255) NULL,
+    `avatar_image_url`  TEXT NULL,
+    `message`           TEXT NULL,
+    `status`            VARCHAR(20) NOT NULL,
+    `first_seen_at`     DATETIME(3) NOT NULL,
+    `last_seen_at`      DATETIME(3) NOT NULL,
+    `decided_at`        DATETIME(3) NULL,
+    `decided_by`        VARCHAR(255) NULL,
+    `created_at`        DATETIME(3) NULL,
+    `updated_at`        DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uq_bot_request` (`bot_id`, `request_id`),
+    INDEX `idx_bot_requests_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `bot_request_audits`
+  ADD COLUMN `actor` VARCHAR(255) NULL AFTER `source`;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `chatwork_command_grants`;
+
+ALTER TABLE `chatwork_bots`
+  DROP COLUMN `webhook_token`;
@@ -0,0 +1,16 @@
+ALTER TABLE `chatwork_bots`
+  ADD COLUMN `webhook_token` TEXT NULL;
+
+-- Which rooms may run bot commands against a project, and who in them
+CREATE TABLE IF NOT EXISTS `chatwork_command_grants` (
+    `id`          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id`  INT UNSIGNED NOT NULL,
+    `room_id`     VARCHAR(255) NOT NULL,
+    `commands`    JSON NULL,
+    `account_ids` JSON NULL,
+    `created_at`  DATETIME(3) NULL,
+    `updated_at`  DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_command_grants_project_id` (`project_id`),
+    INDEX `idx_command_grants_room_id` (`room_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,34 @@
+-- Per-project inbound webhook URLs, each routed to a bot and room
+CREATE TABLE IF NOT EXISTS `webhook_endpoints` (
+    `id`                INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id`        INT UNSIGNED NOT NULL,
+    `name`              VARCHAR(255) NOT NULL,
+    `token`             VARCHAR(64) NOT NULL,
+    `provider`          VARCHAR(50) NULL,
+    `secret`            TEXT NULL,
+    `require_signature` BOOLEAN NOT NULL DEFAULT TRUE,
+    `bot_id`            INT UNSIGNED NOT NULL,
+    `room_id`           VARCHAR(255) NOT NULL,
+    `active`            BOOLEAN NOT NULL DEFAULT TRUE,
+    `created_at`        DATETIME(3) NULL,
+    `updated_at`        DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uq_webhook_endpoints_token` (`token`),
+    INDEX `idx_webhook_endpoints_project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+    `id`          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `endpoint_id` INT UNSIGNED NOT NULL,
+    `provider`    VARCHAR(50) NULL,
+    `event_type`  VARCHAR(255) NULL,
+    `headers`     JSON NULL,
+    `payload`     MEDIUMTEXT NULL,
+    `rendered`    MEDIUMTEXT NULL,
+    `status`      VARCHAR(20) NOT NULL,
+    `error`       TEXT NULL,
+    `duration_ms` BIGINT NULL,
+    `created_at`  DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_webhook_deliveries_endpoint_id` (`endpoint_id`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE `webhook_endpoints`
+  ADD COLUMN `template` TEXT NULL AFTER `provider`;
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS `webhook_buffered_events`;
+
+ALTER TABLE `webhook_endpoints`
+  DROP COLUMN `quiet_timezone`,
+  DROP COLUMN `quiet_end`,
+  DROP COLUMN `quiet_start`,
+  DROP COLUMN `dedup_keys`,
+  DROP COLUMN `dedup_window_minutes`,
+  DROP COLUMN `min_severity`,
+  DROP COLUMN `filters`;
@@ -0,0 +1,28 @@
+-- Filter rules, dedup windows and quiet hours per endpoint
+ALTER TABLE `webhook_endpoints`
+  ADD COLUMN `filters` JSON NULL AFTER `template`,
+  ADD COLUMN `min_severity` VARCHAR(20) NULL AFTER `filters`,
+  ADD COLUMN `dedup_window_minutes` INT NOT NULL DEFAULT 0 AFTER `min_severity`,
+  ADD COLUMN `dedup_keys` JSON NULL AFTER `dedup_window_minutes`,
+  ADD COLUMN `quiet_start` VARCHAR(5) NULL AFTER `dedup_keys`,
+  ADD COLUMN `quiet_end` VARCHAR(5) NULL AFTER `quiet_start`,
+  ADD COLUMN `quiet_timezone` VARCHAR(64) NULL AFTER `quiet_end`;
+
+-- Events held back by a dedup window or quiet hours until flush_at
+CREATE TABLE IF NOT EXISTS `webhook_buffered_events` (
+    `id`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `endpoint_id`   INT UNSIGNED NOT NULL,
+    `kind`          VARCHAR(20) NOT NULL,
+    `dedup_key`     VARCHAR(64) NOT NULL,
+    `summary`       VARCHAR(500) NOT NULL,
+    `severity`      VARCHAR(20) NULL,
+    `count`         INT NOT NULL DEFAULT 0,
+    `first_seen_at` DATETIME(3) NOT NULL,
+    `last_seen_at`  DATETIME(3) NOT NULL,
+    `flush_at`      DATETIME(3) NOT NULL,
+    `created_at`    DATETIME(3) NULL,
+    `updated_at`    DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_webhook_buffered_events_key` (`endpoint_id`, `kind`, `dedup_key`),
+    INDEX `idx_webhook_buffered_events_flush_at` (`flush_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
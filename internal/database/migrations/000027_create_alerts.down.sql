@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `alert_silences`;
+DROP TABLE IF EXISTS `alerts`;
+DROP TABLE IF EXISTS `alert_groups`;
+
+ALTER TABLE `webhook_endpoints`
+  DROP COLUMN `alert_group_by`,
+  DROP COLUMN `alerts`;
@@ -0,0 +1,61 @@
+-- Endpoints that feed the alerts subsystem
+ALTER TABLE `webhook_endpoints`
+  ADD COLUMN `alerts` BOOLEAN NOT NULL DEFAULT FALSE AFTER `quiet_timezone`,
+  ADD COLUMN `alert_group_by` JSON NULL AFTER `alerts`;
+
+CREATE TABLE IF NOT EXISTS `alert_groups` (
+    `id`          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id`  INT UNSIGNED NOT NULL,
+    `endpoint_id` INT UNSIGNED NOT NULL,
+    `group_key`   VARCHAR(64) NOT NULL,
+    `labels`      JSON NULL,
+    `status`      VARCHAR(20) NOT NULL,
+    `room_id`     VARCHAR(255) NULL,
+    `message_id`  VARCHAR(64) NULL,
+    `acked_by`    VARCHAR(255) NULL,
+    `acked_at`    DATETIME(3) NULL,
+    `resolved_at` DATETIME(3) NULL,
+    `created_at`  DATETIME(3) NULL,
+    `updated_at`  DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_alert_groups_project_id` (`project_id`, `status`),
+    INDEX `idx_alert_groups_key` (`endpoint_id`, `group_key`, `status`),
+    INDEX `idx_alert_groups_message` (`room_id`, `message_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `alerts` (
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id`   INT UNSIGNED NOT NULL,
+    `group_id`     INT UNSIGNED NOT NULL,
+    `fingerprint`  VARCHAR(64) NOT NULL,
+    `name`         VARCHAR(255) NOT NULL,
+    `status`       VARCHAR(20) NOT NULL,
+    `severity`     VARCHAR(20) NULL,
+    `labels`       JSON NULL,
+    `summary`      TEXT NULL,
+    `url`          VARCHAR(2048) NULL,
+    `notified`     BOOLEAN NOT NULL DEFAULT FALSE,
+    `silence_id`   INT UNSIGNED NULL,
+    `starts_at`    DATETIME(3) NOT NULL,
+    `ends_at`      DATETIME(3) NULL,
+    `last_seen_at` DATETIME(3) NOT NULL,
+    `created_at`   DATETIME(3) NULL,
+    `updated_at`   DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uq_alerts_fingerprint` (`project_id`, `fingerprint`),
+    INDEX `idx_alerts_group_id` (`group_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `alert_silences` (
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id` INT UNSIGNED NOT NULL,
+    `matchers`   JSON NOT NULL,
+    `comment`    TEXT NULL,
+    `created_by` VARCHAR(255) NULL,
+    `starts_at`  DATETIME(3) NOT NULL,
+    `ends_at`    DATETIME(3) NOT NULL,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_alert_silences_project_id` (`project_id`, `ends_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
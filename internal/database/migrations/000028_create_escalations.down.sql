@@ -0,0 +1,8 @@
+ALTER TABLE `cve_configs`
+  DROP COLUMN `escalation_policy_id`;
+
+DROP TABLE IF EXISTS `escalation_notifications`;
+DROP TABLE IF EXISTS `escalations`;
+DROP TABLE IF EXISTS `escalation_policies`;
+DROP TABLE IF EXISTS `oncall_overrides`;
+DROP TABLE IF EXISTS `oncall_rotations`;
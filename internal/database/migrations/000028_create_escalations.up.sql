@@ -0,0 +1,82 @@
+-- On-call rotations and their overrides
+CREATE TABLE IF NOT EXISTS `oncall_rotations` (
+    `id`           INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id`   INT UNSIGNED NOT NULL,
+    `name`         VARCHAR(255) NOT NULL,
+    `handoff`      VARCHAR(10) NOT NULL,
+    `starts_at`    DATETIME(3) NOT NULL,
+    `timezone`     VARCHAR(64) NULL,
+    `participants` JSON NOT NULL,
+    `created_at`   DATETIME(3) NULL,
+    `updated_at`   DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_oncall_rotations_project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `oncall_overrides` (
+    `id`          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `rotation_id` INT UNSIGNED NOT NULL,
+    `account_id`  BIGINT NOT NULL,
+    `name`        VARCHAR(255) NULL,
+    `starts_at`   DATETIME(3) NOT NULL,
+    `ends_at`     DATETIME(3) NOT NULL,
+    `reason`      VARCHAR(255) NULL,
+    `created_at`  DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_oncall_overrides_rotation_id` (`rotation_id`, `ends_at`),
+    CONSTRAINT `fk_oncall_overrides_rotation` FOREIGN KEY (`rotation_id`) REFERENCES `oncall_rotations` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Escalation policies, the escalations they run and the messages posted
+CREATE TABLE IF NOT EXISTS `escalation_policies` (
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id` INT UNSIGNED NOT NULL,
+    `name`       VARCHAR(255) NOT NULL,
+    `bot_id`     INT UNSIGNED NOT NULL,
+    `room_id`    VARCHAR(255) NOT NULL,
+    `levels`     JSON NOT NULL,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_escalation_policies_project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `escalations` (
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id` INT UNSIGNED NOT NULL,
+    `policy_id`  INT UNSIGNED NOT NULL,
+    `source`     VARCHAR(50) NOT NULL,
+    `source_ref` VARCHAR(255) NULL,
+    `title`      VARCHAR(255) NOT NULL,
+    `text`       TEXT NULL,
+    `status`     VARCHAR(20) NOT NULL,
+    `level`      INT NOT NULL DEFAULT 0,
+    `next_at`    DATETIME(3) NULL,
+    `acked_by`   VARCHAR(255) NULL,
+    `acked_at`   DATETIME(3) NULL,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_escalations_project_id` (`project_id`),
+    INDEX `idx_escalations_status` (`status`, `next_at`),
+    INDEX `idx_escalations_source_ref` (`project_id`, `source_ref`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `escalation_notifications` (
+    `id`            INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `escalation_id` INT UNSIGNED NOT NULL,
+    `level`         INT NOT NULL,
+    `room_id`       VARCHAR(255) NOT NULL,
+    `message_id`    VARCHAR(64) NULL,
+    `account_ids`   JSON NULL,
+    `error`         TEXT NULL,
+    `created_at`    DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_escalation_notifications_escalation_id` (`escalation_id`),
+    INDEX `idx_escalation_notifications_message` (`room_id`, `message_id`),
+    CONSTRAINT `fk_escalation_notifications_escalation` FOREIGN KEY (`escalation_id`) REFERENCES `escalations` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Critical CVE findings can start an escalation
+ALTER TABLE `cve_configs`
+  ADD COLUMN `escalation_policy_id` INT UNSIGNED NULL AFTER `channel_id`;
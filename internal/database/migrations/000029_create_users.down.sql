@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `user_invitations`;
+DROP TABLE IF EXISTS `project_members`;
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,43 @@
+-- Dashboard users, their project roles and pending invitations
+CREATE TABLE IF NOT EXISTS `users` (
+    `id`            INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `email`         VARCHAR(255) NOT NULL,
+    `name`          VARCHAR(255) NULL,
+    `password_hash` VARCHAR(255) NULL,
+    `is_admin`      BOOLEAN NOT NULL DEFAULT FALSE,
+    `status`        VARCHAR(20) NOT NULL DEFAULT 'active',
+    `last_login_at` DATETIME(3) NULL,
+    `created_at`    DATETIME(3) NULL,
+    `updated_at`    DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_users_email` (`email`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `project_members` (
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id` INT UNSIGNED NOT NULL,
+    `user_id`    INT UNSIGNED NOT NULL,
+    `role`       VARCHAR(20) NOT NULL,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_project_members_project_user` (`project_id`, `user_id`),
+    INDEX `idx_project_members_user_id` (`user_id`),
+    CONSTRAINT `fk_project_members_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_invitations` (
+    `id`          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `email`       VARCHAR(255) NOT NULL,
+    `name`        VARCHAR(255) NULL,
+    `is_admin`    BOOLEAN NOT NULL DEFAULT FALSE,
+    `projects`    JSON NULL,
+    `token_hash`  VARCHAR(64) NOT NULL,
+    `invited_by`  INT UNSIGNED NULL,
+    `expires_at`  DATETIME(3) NOT NULL,
+    `accepted_at` DATETIME(3) NULL,
+    `created_at`  DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_user_invitations_token_hash` (`token_hash`),
+    INDEX `idx_user_invitations_email` (`email`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,28 @@
+-- Sign-in sessions and the refresh tokens issued for them
+CREATE TABLE IF NOT EXISTS `user_sessions` (
+    `id`             INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `user_id`        INT UNSIGNED NOT NULL,
+    `user_agent`     VARCHAR(255) NULL,
+    `ip_address`     VARCHAR(64) NULL,
+    `expires_at`     DATETIME(3) NOT NULL,
+    `last_used_at`   DATETIME(3) NULL,
+    `revoked_at`     DATETIME(3) NULL,
+    `revoked_reason` VARCHAR(50) NULL,
+    `created_at`     DATETIME(3) NULL,
+    `updated_at`     DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_user_sessions_user_id` (`user_id`, `revoked_at`),
+    CONSTRAINT `fk_user_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `refresh_tokens` (
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `session_id` INT UNSIGNED NOT NULL,
+    `token_hash` VARCHAR(64) NOT NULL,
+    `used_at`    DATETIME(3) NULL,
+    `created_at` DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_refresh_tokens_token_hash` (`token_hash`),
+    INDEX `idx_refresh_tokens_session_id` (`session_id`),
+    CONSTRAINT `fk_refresh_tokens_session` FOREIGN KEY (`session_id`) REFERENCES `user_sessions` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
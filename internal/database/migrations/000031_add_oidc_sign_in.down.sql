@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `oidc_group_mappings`;
+DROP TABLE IF EXISTS `oidc_auth_requests`;
+ALTER TABLE `project_members` DROP COLUMN `source`;
+ALTER TABLE `users`
+    DROP INDEX `idx_users_oidc_subject`,
+    DROP COLUMN `oidc_subject`;
@@ -0,0 +1,35 @@
+-- Single sign-on with an OpenID Connect provider
+ALTER TABLE `users`
+    ADD COLUMN `oidc_subject` VARCHAR(255) NULL AFTER `password_hash`,
+    ADD UNIQUE INDEX `idx_users_oidc_subject` (`oidc_subject`);
+
+-- Where a membership came from: NULL when set by hand, 'oidc' when synced
+-- from the provider's groups
+ALTER TABLE `project_members`
+    ADD COLUMN `source` VARCHAR(20) NULL AFTER `role`;
+
+-- Sign-ins started but not yet completed
+CREATE TABLE IF NOT EXISTS `oidc_auth_requests` (
+    `id`            INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `state_hash`    VARCHAR(64) NOT NULL,
+    `nonce`         VARCHAR(64) NOT NULL,
+    `code_verifier` VARCHAR(128) NOT NULL,
+    `expires_at`    DATETIME(3) NOT NULL,
+    `created_at`    DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_oidc_auth_requests_state_hash` (`state_hash`),
+    INDEX `idx_oidc_auth_requests_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Provider groups whose members get a role in a project
+CREATE TABLE IF NOT EXISTS `oidc_group_mappings` (
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `group_name` VARCHAR(255) NOT NULL,
+    `project_id` INT UNSIGNED NOT NULL,
+    `role`       VARCHAR(20) NOT NULL,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_oidc_group_mappings_group_project` (`group_name`, `project_id`),
+    INDEX `idx_oidc_group_mappings_project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
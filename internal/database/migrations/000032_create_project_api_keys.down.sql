@@ -0,0 +1,3 @@
+-- The plaintext secret keys can't be recovered from their hashes
+ALTER TABLE `projects` ADD COLUMN `secret_key` VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '';
+DROP TABLE IF EXISTS `project_api_keys`;
@@ -0,0 +1,31 @@
+-- Named, scoped project API keys, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS `project_api_keys` (
+    `id`           INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `project_id`   INT UNSIGNED NOT NULL,
+    `name`         VARCHAR(100) NOT NULL,
+    `prefix`       VARCHAR(20) NOT NULL,
+    `key_hash`     VARCHAR(64) NOT NULL,
+    `scopes`       JSON NOT NULL,
+    `expires_at`   DATETIME(3) NULL,
+    `last_used_at` DATETIME(3) NULL,
+    `last_used_ip` VARCHAR(64) NULL,
+    `created_by`   INT UNSIGNED NULL,
+    `revoked_at`   DATETIME(3) NULL,
+    `created_at`   DATETIME(3) NULL,
+    `updated_at`   DATETIME(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_project_api_keys_project_key` (`project_id`, `key_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Each project's secret key becomes a key with every scope. Hashes are
+-- indexed but not unique: old keys could be chosen by hand, and a key is
+-- only ever looked up within its project. Hand-chosen keys can be short, so
+-- none of the secret is kept as the prefix
+INSERT INTO `project_api_keys` (`project_id`, `name`, `prefix`, `key_hash`, `scopes`, `created_at`, `updated_at`)
+SELECT `id`, 'Default', '', SHA2(`secret_key`, 256),
+       JSON_ARRAY('schedules:read', 'schedules:write', 'cve:read', 'cve:write', 'cve:scan', 'project:read', 'project:write'),
+       NOW(3), NOW(3)
+FROM `projects`
+WHERE `secret_key` <> '';
+
+ALTER TABLE `projects` DROP COLUMN `secret_key`;
@@ -0,0 +1,9 @@
+-- Rate limit and lockout counters, shared between replicas when
+-- RATE_LIMIT_STORE=mysql
+CREATE TABLE IF NOT EXISTS `rate_limit_counters` (
+    `bucket`   VARCHAR(191) NOT NULL,
+    `hits`     INT UNSIGNED NOT NULL DEFAULT 0,
+    `reset_at` DATETIME(3) NOT NULL,
+    PRIMARY KEY (`bucket`),
+    INDEX `idx_rate_limit_counters_reset_at` (`reset_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+ALTER TABLE `cve_record_cpes`
+    DROP COLUMN `config_index`,
+    DROP COLUMN `config_operator`,
+    DROP COLUMN `node_index`,
+    DROP COLUMN `node_operator`,
+    DROP COLUMN `node_negate`;
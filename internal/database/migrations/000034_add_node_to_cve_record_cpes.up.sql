@@ -0,0 +1,8 @@
+-- Keep the NVD configuration tree so AND/negated nodes can be evaluated.
+-- Rows stored before this migration land in one OR node of one configuration.
+ALTER TABLE `cve_record_cpes`
+    ADD COLUMN `config_index` INT NOT NULL DEFAULT 0,
+    ADD COLUMN `config_operator` VARCHAR(3) NOT NULL DEFAULT 'OR',
+    ADD COLUMN `node_index` INT NOT NULL DEFAULT 0,
+    ADD COLUMN `node_operator` VARCHAR(3) NOT NULL DEFAULT 'OR',
+    ADD COLUMN `node_negate` BOOLEAN NOT NULL DEFAULT FALSE;
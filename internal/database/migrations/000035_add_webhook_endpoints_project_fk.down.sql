@@ -0,0 +1,3 @@
+ALTER TABLE `webhook_endpoints` DROP FOREIGN KEY `fk_webhook_endpoints_project`;
+
+ALTER TABLE `webhook_endpoints` MODIFY COLUMN `project_id` INT UNSIGNED NOT NULL;
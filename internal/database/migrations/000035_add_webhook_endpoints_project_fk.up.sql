@@ -0,0 +1,20 @@
+-- Webhook endpoints belong to their project. Drop the ones left behind by
+-- projects deleted before Delete removed them, then tie the rest to projects
+-- (project_id is widened to match projects.id)
+DELETE d FROM `webhook_deliveries` d
+JOIN `webhook_endpoints` e ON e.`id` = d.`endpoint_id`
+LEFT JOIN `projects` p ON p.`id` = e.`project_id`
+WHERE p.`id` IS NULL OR p.`deleted_at` IS NOT NULL;
+
+DELETE b FROM `webhook_buffered_events` b
+JOIN `webhook_endpoints` e ON e.`id` = b.`endpoint_id`
+LEFT JOIN `projects` p ON p.`id` = e.`project_id`
+WHERE p.`id` IS NULL OR p.`deleted_at` IS NOT NULL;
+
+DELETE e FROM `webhook_endpoints` e
+LEFT JOIN `projects` p ON p.`id` = e.`project_id`
+WHERE p.`id` IS NULL OR p.`deleted_at` IS NOT NULL;
+
+ALTER TABLE `webhook_endpoints`
+    MODIFY COLUMN `project_id` BIGINT UNSIGNED NOT NULL,
+    ADD CONSTRAINT `fk_webhook_endpoints_project` FOREIGN KEY (`project_id`) REFERENCES `projects` (`id`) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE `project_api_keys` DROP FOREIGN KEY `fk_project_api_keys_project`;
+
+ALTER TABLE `project_api_keys` MODIFY COLUMN `project_id` INT UNSIGNED NOT NULL;
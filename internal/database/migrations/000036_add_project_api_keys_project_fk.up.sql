@@ -0,0 +1,8 @@
+-- Drop the keys left behind by deleted projects, then tie the rest to projects
+DELETE k FROM `project_api_keys` k
+LEFT JOIN `projects` p ON p.`id` = k.`project_id`
+WHERE p.`id` IS NULL OR p.`deleted_at` IS NOT NULL;
+
+ALTER TABLE `project_api_keys`
+    MODIFY COLUMN `project_id` BIGINT UNSIGNED NOT NULL,
+    ADD CONSTRAINT `fk_project_api_keys_project` FOREIGN KEY (`project_id`) REFERENCES `projects` (`id`) ON DELETE CASCADE;
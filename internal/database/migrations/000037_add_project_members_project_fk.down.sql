@@ -0,0 +1,3 @@
+ALTER TABLE `project_members` DROP FOREIGN KEY `fk_project_members_project`;
+
+ALTER TABLE `project_members` MODIFY COLUMN `project_id` INT UNSIGNED NOT NULL;
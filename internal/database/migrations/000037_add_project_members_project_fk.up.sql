@@ -0,0 +1,8 @@
+-- Drop the memberships left behind by deleted projects, then tie the rest to projects
+DELETE m FROM `project_members` m
+LEFT JOIN `projects` p ON p.`id` = m.`project_id`
+WHERE p.`id` IS NULL OR p.`deleted_at` IS NOT NULL;
+
+ALTER TABLE `project_members`
+    MODIFY COLUMN `project_id` BIGINT UNSIGNED NOT NULL,
+    ADD CONSTRAINT `fk_project_members_project` FOREIGN KEY (`project_id`) REFERENCES `projects` (`id`) ON DELETE CASCADE;
@@ -0,0 +1 @@
+ALTER TABLE `project_api_keys` DROP COLUMN `must_rotate`;
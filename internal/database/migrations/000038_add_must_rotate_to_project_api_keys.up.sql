@@ -0,0 +1,9 @@
+-- Keys migrated from the old project secret were chosen by hand and hashed
+-- without a salt: flag them for rotation, and clear the prefix that 000032
+-- used to copy from the secret. Generated keys all start with sk_proj_
+ALTER TABLE `project_api_keys`
+    ADD COLUMN `must_rotate` BOOLEAN NOT NULL DEFAULT FALSE AFTER `revoked_at`;
+
+UPDATE `project_api_keys`
+SET `must_rotate` = TRUE, `prefix` = ''
+WHERE `prefix` NOT LIKE 'sk\_proj\_%';
@@ -35,6 +35,10 @@ func GetChatworkHealth(ctx *gin.Context) {
 	utils.RespondWithOK(ctx, http.StatusOK, health)
 }
 
+func GetChatworkQueueHealth(ctx *gin.Context) {
+	utils.RespondWithOK(ctx, http.StatusOK, services.GetChatworkQueueHealth())
+}
+
 func GetServerHealth(ctx *gin.Context) {
 	health := services.GetServerHealth()
 	utils.RespondWithOK(ctx, http.StatusOK, health)
@@ -22,15 +22,18 @@ type IProjectHandler interface {
 
 type ProjectHandler struct {
 	service     services.IProjectService
+	keyService  services.IProjectKeyService
 	cronService services.ICronService
 }
 
 func NewProjectHandler(
 	service services.IProjectService,
+	keyService services.IProjectKeyService,
 	cronService services.ICronService,
 ) *ProjectHandler {
 	return &ProjectHandler{
 		service:     service,
+		keyService:  keyService,
 		cronService: cronService,
 	}
 }
@@ -51,7 +54,6 @@ func (h *ProjectHandler) Create(c *gin.Context) {
 	var input struct {
 		Name        string `json:"name" binding:"required"`
 		Description string `json:"description" binding:"required,max=255"`
-		SecretKey   string `json:"secret_key"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -66,19 +68,22 @@ func (h *ProjectHandler) Create(c *gin.Context) {
 	project := &models.Project{
 		Name:        input.Name,
 		Description: input.Description,
-		SecretKey:   input.SecretKey,
 	}
-	createdProject, err := h.service.Create(project)
+	// Created with a "Default" key, like in v2, so X-Project-Key works
+	createdProject, _, secretKey, err := h.keyService.CreateProject(project, c.GetUint("UserID"))
 	if err != nil {
 		utils.RespondWithError(
 			c,
 			http.StatusBadGateway,
-			errors.New(errors.ErrDatabaseQuery, err.Error()),
+			errors.New(errors.ErrDatabaseInsert, err.Error()),
 		)
 		return
 	}
 
-	utils.RespondWithOK(c, http.StatusCreated, createdProject)
+	utils.RespondWithOK(c, http.StatusCreated, gin.H{
+		"project":   createdProject,
+		"secretKey": secretKey,
+	})
 }
 
 func (h *ProjectHandler) GetByID(c *gin.Context) {
@@ -137,9 +142,8 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 	}
 
 	var input struct {
-		Name        string  `json:"name" binding:"required"`
-		Description string  `json:"description" binding:"required,max=255"`
-		SecretKey   *string `json:"secret_key"`
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description" binding:"required,max=255"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -155,10 +159,6 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 	project.Name = input.Name
 	project.Description = input.Description
 
-	if input.SecretKey != nil {
-		project.SecretKey = *input.SecretKey
-	}
-
 	updatedProject, err := h.service.Update(project)
 	if err != nil {
 		utils.RespondWithError(
@@ -225,8 +225,7 @@ func (h *ProjectHandler) VerifyAccess(c *gin.Context) {
 		)
 		return
 	}
-	project, err := h.service.GetByID(input.ProjectID)
-	if err != nil {
+	if _, err := h.service.GetByID(input.ProjectID); err != nil {
 		utils.RespondWithError(
 			c,
 			http.StatusBadRequest,
@@ -235,7 +234,7 @@ func (h *ProjectHandler) VerifyAccess(c *gin.Context) {
 		return
 	}
 
-	if project.SecretKey != input.SecretKey {
+	if _, err := h.keyService.VerifyKey(input.ProjectID, input.SecretKey, c.ClientIP()); err != nil {
 		utils.RespondWithOK(
 			c,
 			http.StatusOK, gin.H{
@@ -21,28 +21,28 @@ type IReminderScheduleHandler interface {
 }
 
 type ReminderScheduleHandler struct {
-	service        services.IReminderScheduleService
-	projectService services.IProjectService
-	cronService    services.ICronService
+	service     services.IReminderScheduleService
+	keyService  services.IProjectKeyService
+	cronService services.ICronService
 }
 
 // NewReminderScheduleHandler creates a new instance of ReminderScheduleHandler
 // Parameters:
 //   - service: The reminder schedule service to handle business logic
-//   - projectService: The project service to validate project access
+//   - keyService: The project key service to validate project access
 //   - cronService: The cron service to handle scheduled tasks
 //
 // Returns:
 //   - *ReminderScheduleHandler: New handler instance
 func NewReminderScheduleHandler(
 	service services.IReminderScheduleService,
-	projectService services.IProjectService,
+	keyService services.IProjectKeyService,
 	cronService services.ICronService,
 ) *ReminderScheduleHandler {
 	return &ReminderScheduleHandler{
-		service:        service,
-		projectService: projectService,
-		cronService:    cronService,
+		service:     service,
+		keyService:  keyService,
+		cronService: cronService,
 	}
 }
 
@@ -81,22 +81,7 @@ func (handler *ReminderScheduleHandler) CreateSchedule(ctx *gin.Context) {
 	}
 
 	// Validate project secret key
-	isValid, err := handler.projectService.ValidateSecretKey(input.ProjectID, secretKey)
-	if err != nil {
-		utils.RespondWithError(
-			ctx,
-			http.StatusBadRequest,
-			errors.New(errors.ErrResourceNotFound, "Project not found"),
-		)
-		return
-	}
-
-	if !isValid {
-		utils.RespondWithError(
-			ctx,
-			http.StatusUnauthorized,
-			errors.New(errors.ErrAuthUnauthorized, "Invalid project secret key"),
-		)
+	if !handler.verifyKey(ctx, input.ProjectID, secretKey, models.ScopeSchedulesWrite) {
 		return
 	}
 
@@ -164,22 +149,7 @@ func (handler *ReminderScheduleHandler) GetSchedule(ctx *gin.Context) {
 	}
 
 	// Validate project secret key
-	isValid, err := handler.projectService.ValidateSecretKey(schedule.ProjectID, secretKey)
-	if err != nil {
-		utils.RespondWithError(
-			ctx,
-			http.StatusBadRequest,
-			errors.New(errors.ErrResourceNotFound, "Project not found"),
-		)
-		return
-	}
-
-	if !isValid {
-		utils.RespondWithError(
-			ctx,
-			http.StatusUnauthorized,
-			errors.New(errors.ErrAuthUnauthorized, "Invalid project secret key"),
-		)
+	if !handler.verifyKey(ctx, schedule.ProjectID, secretKey, models.ScopeSchedulesRead) {
 		return
 	}
 
@@ -222,22 +192,7 @@ func (handler *ReminderScheduleHandler) GetSchedulesByProject(ctx *gin.Context)
 	}
 
 	// Validate project secret key before fetching schedules
-	isValid, err := handler.projectService.ValidateSecretKey(uint(pID), secretKey)
-	if err != nil {
-		utils.RespondWithError(
-			ctx,
-			http.StatusBadRequest,
-			errors.New(errors.ErrResourceNotFound, "Project not found"),
-		)
-		return
-	}
-
-	if !isValid {
-		utils.RespondWithError(
-			ctx,
-			http.StatusUnauthorized,
-			errors.New(errors.ErrAuthUnauthorized, "Invalid project secret key"),
-		)
+	if !handler.verifyKey(ctx, uint(pID), secretKey, models.ScopeSchedulesRead) {
 		return
 	}
 
@@ -309,22 +264,7 @@ func (handler *ReminderScheduleHandler) UpdateSchedule(ctx *gin.Context) {
 	}
 
 	// Validate project secret key
-	isValid, err := handler.projectService.ValidateSecretKey(existingSchedule.ProjectID, secretKey)
-	if err != nil {
-		utils.RespondWithError(
-			ctx,
-			http.StatusBadRequest,
-			errors.New(errors.ErrResourceNotFound, "Project not found"),
-		)
-		return
-	}
-
-	if !isValid {
-		utils.RespondWithError(
-			ctx,
-			http.StatusUnauthorized,
-			errors.New(errors.ErrAuthUnauthorized, "Invalid project secret key"),
-		)
+	if !handler.verifyKey(ctx, existingSchedule.ProjectID, secretKey, models.ScopeSchedulesWrite) {
 		return
 	}
 
@@ -405,22 +345,7 @@ func (handler *ReminderScheduleHandler) DeleteSchedule(ctx *gin.Context) {
 	}
 
 	// Validate project secret key
-	isValid, err := handler.projectService.ValidateSecretKey(schedule.ProjectID, secretKey)
-	if err != nil {
-		utils.RespondWithError(
-			ctx,
-			http.StatusBadRequest,
-			errors.New(errors.ErrResourceNotFound, "Project not found"),
-		)
-		return
-	}
-
-	if !isValid {
-		utils.RespondWithError(
-			ctx,
-			http.StatusUnauthorized,
-			errors.New(errors.ErrAuthUnauthorized, "Invalid project secret key"),
-		)
+	if !handler.verifyKey(ctx, schedule.ProjectID, secretKey, models.ScopeSchedulesWrite) {
 		return
 	}
 
@@ -443,3 +368,18 @@ func (handler *ReminderScheduleHandler) DeleteSchedule(ctx *gin.Context) {
 
 	utils.RespondWithOK(ctx, http.StatusOK, response)
 }
+
+// verifyKey checks that secretKey is an active key of the project with
+// scope, and responds with 401 when it isn't
+func (handler *ReminderScheduleHandler) verifyKey(ctx *gin.Context, projectID uint, secretKey, scope string) bool {
+	key, err := handler.keyService.VerifyKey(projectID, secretKey, ctx.ClientIP())
+	if err != nil || !key.HasScope(scope) {
+		utils.RespondWithError(
+			ctx,
+			http.StatusUnauthorized,
+			errors.New(errors.ErrAuthUnauthorized, "Invalid project secret key"),
+		)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+package v2
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// AlertHandler exposes the alerts received by alerts endpoints, their groups
+// and acknowledgements, and the project's silences.
+type AlertHandler struct {
+	service services.IAlertService
+}
+
+func NewAlertHandler(service services.IAlertService) *AlertHandler {
+	return &AlertHandler{
+		service: service,
+	}
+}
+
+// GET /api/v2/projects/:projectId/alerts?status=firing|resolved
+func (h *AlertHandler) GetAlerts(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	paging := utils.GeneratePagingFromRequest(c)
+	alerts, total, err := h.service.GetAlerts(projectID, c.Query("status"), paging)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	if alerts == nil {
+		alerts = []models.Alert{}
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  alerts,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// GET /api/v2/projects/:projectId/alert-groups?status=firing|resolved
+func (h *AlertHandler) GetGroups(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	paging := utils.GeneratePagingFromRequest(c)
+	groups, total, err := h.service.GetGroups(projectID, c.Query("status"), paging)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	if groups == nil {
+		groups = []models.AlertGroup{}
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  groups,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// GET /api/v2/projects/:projectId/alert-groups/:groupId
+func (h *AlertHandler) GetGroup(c *gin.Context) {
+	projectID, groupID, ok := h.parseParams(c, "groupId")
+	if !ok {
+		return
+	}
+
+	group, err := h.service.GetGroup(groupID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, group)
+}
+
+type alertAckRequest struct {
+	By string `json:"by"`
+}
+
+// POST /api/v2/projects/:projectId/alert-groups/:groupId/ack
+// Body (optional): {"by": "alice"}; defaults to "API".
+func (h *AlertHandler) Acknowledge(c *gin.Context) {
+	projectID, groupID, ok := h.parseParams(c, "groupId")
+	if !ok {
+		return
+	}
+
+	var input alertAckRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+			return
+		}
+	}
+	if input.By == "" {
+		input.By = "API"
+	}
+
+	group, err := h.service.Acknowledge(groupID, projectID, input.By)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, group)
+}
+
+// GET /api/v2/projects/:projectId/silences?active=true
+func (h *AlertHandler) GetSilences(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	silences, err := h.service.GetSilences(projectID, c.Query("active") == "true")
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	if silences == nil {
+		silences = []models.AlertSilence{}
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  silences,
+		"total": len(silences),
+	})
+}
+
+type alertSilenceRequest struct {
+	Matchers        []models.AlertMatcher `json:"matchers"`
+	StartsAt        *time.Time            `json:"startsAt"`
+	EndsAt          *time.Time            `json:"endsAt"`
+	DurationMinutes *int                  `json:"durationMinutes"`
+	Comment         string                `json:"comment"`
+	CreatedBy       string                `json:"createdBy"`
+}
+
+// POST /api/v2/projects/:projectId/silences
+func (h *AlertHandler) CreateSilence(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	var input alertSilenceRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	silence, err := h.service.CreateSilence(projectID, &services.AlertSilenceInput{
+		Matchers:        input.Matchers,
+		StartsAt:        input.StartsAt,
+		EndsAt:          input.EndsAt,
+		DurationMinutes: input.DurationMinutes,
+		Comment:         input.Comment,
+		CreatedBy:       input.CreatedBy,
+	})
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusCreated, silence)
+}
+
+// GET /api/v2/projects/:projectId/silences/:silenceId
+func (h *AlertHandler) GetSilence(c *gin.Context) {
+	projectID, silenceID, ok := h.parseParams(c, "silenceId")
+	if !ok {
+		return
+	}
+
+	silence, err := h.service.GetSilence(silenceID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, silence)
+}
+
+// DELETE /api/v2/projects/:projectId/silences/:silenceId
+// Expires the silence; it stays listed with its new end time.
+func (h *AlertHandler) ExpireSilence(c *gin.Context) {
+	projectID, silenceID, ok := h.parseParams(c, "silenceId")
+	if !ok {
+		return
+	}
+
+	silence, err := h.service.ExpireSilence(silenceID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, silence)
+}
+
+func (h *AlertHandler) parseProject(c *gin.Context) (uint, bool) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return 0, false
+	}
+	return uint(projectID), true
+}
+
+func (h *AlertHandler) parseParams(c *gin.Context, param string) (uint, uint, bool) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid "+param))
+		return 0, 0, false
+	}
+	return projectID, uint(id), true
+}
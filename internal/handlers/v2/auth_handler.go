@@ -6,56 +6,215 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
 	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
 )
 
 // AuthHandler handles V2 authentication endpoints
-type AuthHandler struct{}
+type AuthHandler struct {
+	userService    services.IUserService
+	sessionService services.ISessionService
+	oidcService    services.IOIDCService
+}
 
 // NewAuthHandler creates a new AuthHandler instance
-func NewAuthHandler() *AuthHandler {
-	return &AuthHandler{}
+func NewAuthHandler(userService services.IUserService, sessionService services.ISessionService, oidcService services.IOIDCService) *AuthHandler {
+	return &AuthHandler{
+		userService:    userService,
+		sessionService: sessionService,
+		oidcService:    oidcService,
+	}
 }
 
-// Login validates the admin passcode and returns a JWT token.
+// Login checks the user's email and password, starts a session and returns
+// its access and refresh tokens.
 // POST /api/v2/auth/login
-// Body: { "passcode": "..." }
-// Response 200: { "token": "...", "expiresAt": "2026-03-07T..." }
-// Response 401: Invalid passcode
+// Body: { "email": "...", "password": "..." }
+// Response 200: { "token": "...", "expiresAt": "2026-03-07T...", "refreshToken": "rt_...", "refreshExpiresAt": "...", "user": {...} }
+// Response 401: Invalid email or password
 func (h *AuthHandler) Login(c *gin.Context) {
 	var input struct {
-		Passcode string `json:"passcode" binding:"required"`
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "passcode is required"))
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "email and password are required"))
 		return
 	}
 
-	expectedPasscode := utils.GetEnv("ADMIN_PASSCODE", "botadmin2026")
-	if input.Passcode != expectedPasscode {
-		utils.RespondWithError(c, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Invalid passcode"))
+	user, err := h.userService.Login(input.Email, input.Password)
+	if err != nil {
+		respondAppError(c, err)
 		return
 	}
+	h.startSession(c, user)
+}
 
-	// Generate JWT — use ID=0 for the admin session (passcode-based, no user record)
-	result, err := configs.GenerateToken(0)
-	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrServerInternal, "Failed to generate token"))
+// Refresh exchanges a refresh token for new access and refresh tokens. The
+// old refresh token stops working; presenting it again revokes the session.
+// POST /api/v2/auth/refresh
+// Body: { "refreshToken": "rt_..." }
+// Response 401: Invalid or expired refresh token
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "refreshToken is required"))
 		return
 	}
 
-	expiresAt := time.Unix(result.ExpiresAt, 0).UTC().Format(time.RFC3339)
-	utils.RespondWithOK(c, http.StatusOK, gin.H{
-		"token":     result.Token,
-		"expiresAt": expiresAt,
-	})
+	session, refreshToken, err := h.sessionService.Refresh(input.RefreshToken)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	h.respondWithTokens(c, session, refreshToken, nil)
 }
 
-// Logout invalidates the current session (client-side; stateless JWT).
+// Logout revokes the current session, ending its access and refresh tokens.
 // POST /api/v2/auth/logout
 // Response 204: No content
 func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.sessionService.Revoke(c.GetUint("SessionID"), c.GetUint("UserID"), models.SessionRevokedLogout); err != nil {
+		respondAppError(c, err)
+		return
+	}
 	c.Status(http.StatusNoContent)
 }
+
+// LogoutAll revokes every session of the signed-in user, this one included.
+// POST /api/v2/auth/logout-all
+// Response 200: { "revoked": 3 }
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	revoked, err := h.sessionService.RevokeAll(c.GetUint("UserID"), models.SessionRevokedLogoutAll)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// GetSessions lists the signed-in user's active sessions.
+// GET /api/v2/auth/sessions
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	paging := utils.GeneratePagingFromRequest(c)
+	sessions, total, err := h.sessionService.GetActiveSessions(c.GetUint("UserID"), paging)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	current := c.GetUint("SessionID")
+	data := make([]gin.H, 0, len(sessions))
+	for i := range sessions {
+		resp := buildSessionResponse(&sessions[i])
+		resp["current"] = sessions[i].ID == current
+		data = append(data, resp)
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// RevokeSession ends one of the signed-in user's sessions, e.g. a lost device.
+// DELETE /api/v2/auth/sessions/:sessionId
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := parseIDParam(c, "sessionId")
+	if err != nil {
+		return
+	}
+	if err := h.sessionService.Revoke(uint(sessionID), c.GetUint("UserID"), models.SessionRevokedLogout); err != nil {
+		respondAppError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Me returns the signed-in user with their project roles.
+// GET /api/v2/auth/me
+func (h *AuthHandler) Me(c *gin.Context) {
+	user, err := h.userService.GetUser(c.GetUint("UserID"))
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, buildUserResponse(user))
+}
+
+// AcceptInvitation creates the invited user's account and signs them in.
+// POST /api/v2/auth/invitations/accept
+// Body: { "token": "inv_...", "name": "...", "password": "..." }
+func (h *AuthHandler) AcceptInvitation(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" binding:"required"`
+		Name     string `json:"name"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "token and password are required"))
+		return
+	}
+
+	user, err := h.userService.AcceptInvitation(input.Token, input.Name, input.Password)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	h.startSession(c, user)
+}
+
+func (h *AuthHandler) startSession(c *gin.Context, user *models.User) {
+	session, refreshToken, err := h.sessionService.CreateSession(user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	h.respondWithTokens(c, session, refreshToken, user)
+}
+
+func (h *AuthHandler) respondWithTokens(c *gin.Context, session *models.UserSession, refreshToken string, user *models.User) {
+	result, err := configs.GenerateToken(session.UserID, session.ID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrServerInternal, "Failed to generate token"))
+		return
+	}
+
+	expiresAt := time.Unix(result.ExpiresAt, 0).UTC().Format(time.RFC3339)
+	resp := gin.H{
+		"token":            result.Token,
+		"expiresAt":        expiresAt,
+		"refreshToken":     refreshToken,
+		"refreshExpiresAt": session.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if user != nil {
+		resp["user"] = buildUserResponse(user)
+	}
+	utils.RespondWithOK(c, http.StatusOK, resp)
+}
+
+func buildSessionResponse(s *models.UserSession) gin.H {
+	resp := gin.H{
+		"id":        s.ID,
+		"userId":    s.UserID,
+		"userAgent": s.UserAgent,
+		"ipAddress": s.IPAddress,
+		"createdAt": s.CreatedAt.UTC().Format(time.RFC3339),
+		"expiresAt": s.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if s.LastUsedAt != nil {
+		resp["lastUsedAt"] = s.LastUsedAt.UTC().Format(time.RFC3339)
+	}
+	if s.User != nil {
+		resp["email"] = s.User.Email
+	}
+	return resp
+}
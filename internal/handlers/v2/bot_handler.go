@@ -6,15 +6,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/vfa-khuongdv/golang-cms/internal/services"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
 	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
 )
 
 type BotHandlerV2 struct {
 	service services.IChatworkBotService
+	health  services.IBotHealthService
 }
 
-func NewBotHandlerV2(service services.IChatworkBotService) *BotHandlerV2 {
-	return &BotHandlerV2{service: service}
+func NewBotHandlerV2(service services.IChatworkBotService, health services.IBotHealthService) *BotHandlerV2 {
+	return &BotHandlerV2{service: service, health: health}
 }
 
 // GET /api/v2/bots?page=1&limit=20
@@ -70,3 +72,159 @@ func (h *BotHandlerV2) Delete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// POST /api/v2/bots/:botId/sync
+func (h *BotHandlerV2) Sync(c *gin.Context) {
+	id, err := parseIDParam(c, "botId")
+	if err != nil {
+		return
+	}
+
+	if _, err := h.service.GetBotByID(uint(id)); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, "bot not found"))
+		return
+	}
+	detail, err := h.service.SyncBot(uint(id))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadGateway, errors.New(errors.ErrServerInternal, err.Error()))
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, detail)
+}
+
+// GET /api/v2/bots/:botId/rooms?search=&type=group&page=1&limit=20
+func (h *BotHandlerV2) GetRooms(c *gin.Context) {
+	id, err := parseIDParam(c, "botId")
+	if err != nil {
+		return
+	}
+	paging := utils.GeneratePagingFromRequest(c)
+
+	rooms, total, err := h.service.GetRooms(uint(id), c.Query("search"), c.Query("type"), paging)
+	if appErr, ok := err.(*errors.AppError); ok {
+		utils.RespondWithError(c, http.StatusNotFound, appErr)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseQuery, err.Error()))
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  rooms,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// GET /api/v2/bots/:botId/rooms/:roomId/members
+func (h *BotHandlerV2) GetRoomMembers(c *gin.Context) {
+	id, err := parseIDParam(c, "botId")
+	if err != nil {
+		return
+	}
+	roomID, err := chatwork.ParseRoomID(c.Param("roomId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	members, err := h.service.GetRoomMembers(uint(id), roomID)
+	if appErr, ok := err.(*errors.AppError); ok {
+		utils.RespondWithError(c, http.StatusNotFound, appErr)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseQuery, err.Error()))
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  members,
+		"total": len(members),
+	})
+}
+
+// POST /api/v2/bots/:botId/rotate-token
+func (h *BotHandlerV2) RotateToken(c *gin.Context) {
+	id, err := parseIDParam(c, "botId")
+	if err != nil {
+		return
+	}
+
+	var input struct {
+		APIToken string `json:"apiToken" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "apiToken is required"))
+		return
+	}
+
+	detail, err := h.service.RotateToken(uint(id), input.APIToken)
+	if appErr, ok := err.(*errors.AppError); ok {
+		status := http.StatusBadRequest
+		switch appErr.Code {
+		case errors.ErrResourceNotFound:
+			status = http.StatusNotFound
+		case errors.ErrDatabaseUpdate:
+			status = http.StatusInternalServerError
+		}
+		utils.RespondWithError(c, status, appErr)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrServerInternal, err.Error()))
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, detail)
+}
+
+// PUT /api/v2/bots/:botId/webhook
+// Stores the token of the bot's Chatwork outgoing webhook; an empty token
+// turns the webhook receiver off.
+func (h *BotHandlerV2) SetWebhook(c *gin.Context) {
+	id, err := parseIDParam(c, "botId")
+	if err != nil {
+		return
+	}
+
+	var input struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	detail, err := h.service.SetWebhookToken(uint(id), input.Token)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, detail)
+}
+
+// POST /api/v2/bots/:botId/health-check
+func (h *BotHandlerV2) CheckHealth(c *gin.Context) {
+	id, err := parseIDParam(c, "botId")
+	if err != nil {
+		return
+	}
+
+	bot, err := h.health.Check(uint(id))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, err.Error()))
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"id":              bot.ID,
+		"healthStatus":    bot.HealthStatus,
+		"healthCheckedAt": bot.HealthCheckedAt,
+		"healthError":     bot.HealthError,
+	})
+}
@@ -1,10 +1,13 @@
 package v2
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
 	"github.com/vfa-khuongdv/golang-cms/internal/services"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
 	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
@@ -12,30 +15,40 @@ import (
 
 type BotRequestHandlerV2 struct {
 	service services.IChatworkBotService
+	rules   services.IBotRequestRuleService
 }
 
-func NewBotRequestHandlerV2(service services.IChatworkBotService) *BotRequestHandlerV2 {
-	return &BotRequestHandlerV2{service: service}
+func NewBotRequestHandlerV2(service services.IChatworkBotService, rules services.IBotRequestRuleService) *BotRequestHandlerV2 {
+	return &BotRequestHandlerV2{service: service, rules: rules}
 }
 
-// GET /api/v2/bot-requests?status=pending
+// GET /api/v2/bot-requests?status=&botId=&page=&limit=
+// Syncs pending requests from Chatwork, then lists the stored history newest
+// first. Bots whose requests could not be read are listed in "errors".
 func (h *BotRequestHandlerV2) GetAll(c *gin.Context) {
-	status := c.Query("status")
+	filter := repositories.BotRequestFilter{Status: c.Query("status")}
+	botID, ok := parseBotIDQuery(c)
+	if !ok {
+		return
+	}
+	filter.BotID = botID
 
-	items, err := h.service.GetBotRequests(status)
+	paging := utils.GeneratePagingFromRequest(c)
+	items, total, syncErrors, err := h.service.GetBotRequests(filter, paging)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrServerInternal, err.Error()))
 		return
 	}
-	if items == nil {
-		items = []models.BotRequestItem{}
+	if syncErrors == nil {
+		syncErrors = []models.BotRequestSyncError{}
 	}
 
 	utils.RespondWithOK(c, http.StatusOK, gin.H{
-		"data":  items,
-		"total": len(items),
-		"page":  1,
-		"limit": len(items),
+		"data":   items,
+		"total":  total,
+		"page":   paging.Page,
+		"limit":  paging.Limit,
+		"errors": syncErrors,
 	})
 }
 
@@ -43,7 +56,10 @@ func (h *BotRequestHandlerV2) GetAll(c *gin.Context) {
 func (h *BotRequestHandlerV2) Accept(c *gin.Context) {
 	compositeID := c.Param("requestId")
 
-	if err := h.service.AcceptBotRequest(compositeID); err != nil {
+	actor := actorFromContext(c)
+	err := h.service.AcceptBotRequest(compositeID, actor)
+	h.rules.RecordManual(compositeID, models.BotRequestAccepted, actor, err)
+	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
 		return
 	}
@@ -58,10 +74,67 @@ func (h *BotRequestHandlerV2) Accept(c *gin.Context) {
 func (h *BotRequestHandlerV2) Delete(c *gin.Context) {
 	compositeID := c.Param("requestId")
 
-	if err := h.service.DeleteBotRequest(compositeID); err != nil {
+	actor := actorFromContext(c)
+	err := h.service.DeleteBotRequest(compositeID, actor)
+	h.rules.RecordManual(compositeID, models.BotRequestRejected, actor, err)
+	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// GET /api/v2/bot-requests/audit?botId=&decision=&source=&page=&limit=
+// Every decision about a contact request, newest first.
+func (h *BotRequestHandlerV2) GetAudit(c *gin.Context) {
+	filter := repositories.BotRequestAuditFilter{
+		Decision: c.Query("decision"),
+		Source:   c.Query("source"),
+	}
+	botID, ok := parseBotIDQuery(c)
+	if !ok {
+		return
+	}
+	filter.BotID = botID
+
+	paging := utils.GeneratePagingFromRequest(c)
+	entries, total, err := h.rules.GetAudit(filter, paging)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if entries == nil {
+		entries = []models.BotRequestAudit{}
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  entries,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// parseBotIDQuery reads the optional botId query parameter; 0 means all bots.
+func parseBotIDQuery(c *gin.Context) (uint, bool) {
+	raw := c.Query("botId")
+	if raw == "" {
+		return 0, true
+	}
+	botID, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid botId"))
+		return 0, false
+	}
+	return uint(botID), true
+}
+
+// actorFromContext names the signed-in user for audit records. Decisions
+// recorded before user accounts existed say "admin".
+func actorFromContext(c *gin.Context) string {
+	if id := c.GetUint("UserID"); id != 0 {
+		return fmt.Sprintf("user:%d", id)
+	}
+	return "admin"
+}
@@ -0,0 +1,117 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// BotRequestRuleHandlerV2 manages the rules that auto-accept or auto-reject
+// incoming contact requests.
+type BotRequestRuleHandlerV2 struct {
+	service services.IBotRequestRuleService
+}
+
+func NewBotRequestRuleHandlerV2(service services.IBotRequestRuleService) *BotRequestRuleHandlerV2 {
+	return &BotRequestRuleHandlerV2{service: service}
+}
+
+type botRequestRuleRequest struct {
+	Name     *string  `json:"name"`
+	BotID    *uint    `json:"botId"`
+	Field    *string  `json:"field"`
+	Values   []string `json:"values"`
+	Action   *string  `json:"action"`
+	Priority *int     `json:"priority"`
+	Active   *bool    `json:"active"`
+}
+
+func (r *botRequestRuleRequest) toInput() *services.BotRequestRuleInput {
+	return &services.BotRequestRuleInput{
+		Name:     r.Name,
+		BotID:    r.BotID,
+		Field:    r.Field,
+		Values:   r.Values,
+		Action:   r.Action,
+		Priority: r.Priority,
+		Active:   r.Active,
+	}
+}
+
+// GET /api/v2/bot-request-rules
+func (h *BotRequestRuleHandlerV2) GetAll(c *gin.Context) {
+	rules, err := h.service.GetAll()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if rules == nil {
+		rules = []models.BotRequestRule{}
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  rules,
+		"total": len(rules),
+	})
+}
+
+// POST /api/v2/bot-request-rules
+func (h *BotRequestRuleHandlerV2) Create(c *gin.Context) {
+	var input botRequestRuleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	rule, err := h.service.Create(input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusCreated, rule)
+}
+
+// PUT /api/v2/bot-request-rules/:ruleId
+func (h *BotRequestRuleHandlerV2) Update(c *gin.Context) {
+	id, err := parseIDParam(c, "ruleId")
+	if err != nil {
+		return
+	}
+
+	var input botRequestRuleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	rule, err := h.service.Update(uint(id), input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, rule)
+}
+
+// DELETE /api/v2/bot-request-rules/:ruleId
+func (h *BotRequestRuleHandlerV2) Delete(c *gin.Context) {
+	id, err := parseIDParam(c, "ruleId")
+	if err != nil {
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Rule deleted"})
+}
+
+// POST /api/v2/bot-request-rules/run
+// Applies the rules to every pending request now instead of waiting for the poller.
+func (h *BotRequestRuleHandlerV2) Run(c *gin.Context) {
+	utils.RespondWithOK(c, http.StatusOK, h.service.Run())
+}
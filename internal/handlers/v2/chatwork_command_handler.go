@@ -0,0 +1,164 @@
+package v2
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// maxWebhookBody caps the size of a Chatwork webhook request.
+const maxWebhookBody = 1 << 20
+
+// ChatworkCommandHandler receives Chatwork webhooks and manages which rooms
+// may run bot commands against a project.
+type ChatworkCommandHandler struct {
+	service services.IChatworkCommandService
+}
+
+func NewChatworkCommandHandler(service services.IChatworkCommandService) *ChatworkCommandHandler {
+	return &ChatworkCommandHandler{
+		service: service,
+	}
+}
+
+type chatworkCommandGrantRequest struct {
+	RoomID     *string  `json:"roomId"`
+	Commands   []string `json:"commands"`
+	AccountIDs []int64  `json:"accountIds"`
+}
+
+func (r *chatworkCommandGrantRequest) toInput() *services.ChatworkCommandGrantInput {
+	return &services.ChatworkCommandGrantInput{
+		RoomID:     r.RoomID,
+		Commands:   r.Commands,
+		AccountIDs: r.AccountIDs,
+	}
+}
+
+// POST /api/v2/webhooks/chatwork/:botId
+// Public: requests are authenticated by their Chatwork signature.
+func (h *ChatworkCommandHandler) Webhook(c *gin.Context) {
+	botID, err := parseIDParam(c, "botId")
+	if err != nil {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+	signature := c.GetHeader(chatwork.WebhookSignatureHeader)
+	if signature == "" {
+		signature = c.Query(chatwork.WebhookSignatureQuery)
+	}
+
+	if err := h.service.HandleWebhook(uint(botID), body, signature); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == errors.ErrAuthUnauthorized {
+			utils.RespondWithError(c, http.StatusUnauthorized, appErr)
+			return
+		}
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"status": "ok"})
+}
+
+// GET /api/v2/projects/:projectId/chatwork-commands
+func (h *ChatworkCommandHandler) GetByProject(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	grants, err := h.service.GetGrants(uint(projectID))
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  grants,
+		"total": len(grants),
+	})
+}
+
+// POST /api/v2/projects/:projectId/chatwork-commands
+func (h *ChatworkCommandHandler) Create(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	var input chatworkCommandGrantRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	grant, err := h.service.CreateGrant(uint(projectID), input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusCreated, grant)
+}
+
+// PUT /api/v2/projects/:projectId/chatwork-commands/:grantId
+func (h *ChatworkCommandHandler) Update(c *gin.Context) {
+	projectID, grantID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	var input chatworkCommandGrantRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	grant, err := h.service.UpdateGrant(grantID, projectID, input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, grant)
+}
+
+// DELETE /api/v2/projects/:projectId/chatwork-commands/:grantId
+func (h *ChatworkCommandHandler) Delete(c *gin.Context) {
+	projectID, grantID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteGrant(grantID, projectID); err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Command grant deleted"})
+}
+
+func (h *ChatworkCommandHandler) parseParams(c *gin.Context) (uint, uint, bool) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return 0, 0, false
+	}
+
+	grantID, err := strconv.ParseUint(c.Param("grantId"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid grantId"))
+		return 0, 0, false
+	}
+	return uint(projectID), uint(grantID), true
+}
@@ -8,17 +8,20 @@ import (
 	"github.com/vfa-khuongdv/golang-cms/internal/services"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
 	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/secrets"
 )
 
 type CveConfigHandler struct {
 	service     services.ICveConfigService
 	cronService services.ICronService
+	botService  services.IChatworkBotService
 }
 
-func NewCveConfigHandler(service services.ICveConfigService, cronService services.ICronService) *CveConfigHandler {
+func NewCveConfigHandler(service services.ICveConfigService, cronService services.ICronService, botService services.IChatworkBotService) *CveConfigHandler {
 	return &CveConfigHandler{
 		service:     service,
 		cronService: cronService,
+		botService:  botService,
 	}
 }
 
@@ -28,10 +31,6 @@ func (h *CveConfigHandler) GetByProject(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	paging := utils.GeneratePagingFromRequest(c)
 
 	configs, total, err := h.service.GetByProjectID(uint(projectID), paging)
@@ -59,25 +58,26 @@ func (h *CveConfigHandler) Create(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	var input struct {
-		Name             string `json:"name" binding:"required"`
-		RepoUrl          string `json:"repoUrl"`
-		Languages        string `json:"languages" binding:"required"`
-		Cron             string `json:"cron" binding:"required"`
-		Status           string `json:"status"`
-		ApiKey           string `json:"apiKey"`
-		BotID            *int   `json:"botId"`
-		NotifyOnSuccess  *bool  `json:"notifyOnSuccess"`
-		NotifyOnFailure  *bool  `json:"notifyOnFailure"`
-		NotifyRoomId     string `json:"notifyRoomId"`
-		NotifyOnCritical *bool  `json:"notifyOnCritical"`
-		NotifyOnHigh     *bool  `json:"notifyOnHigh"`
-		NotifyOnModerate *bool  `json:"notifyOnModerate"`
-		NotifyOnLow      *bool  `json:"notifyOnLow"`
+		Name               string        `json:"name" binding:"required"`
+		RepoUrl            string        `json:"repoUrl"`
+		Languages          string        `json:"languages" binding:"required"`
+		Cron               string        `json:"cron" binding:"required"`
+		Status             string        `json:"status"`
+		ApiKey             secrets.Input `json:"apiKey"`
+		BotID              *int          `json:"botId"`
+		NotifyOnSuccess    *bool         `json:"notifyOnSuccess"`
+		NotifyOnFailure    *bool         `json:"notifyOnFailure"`
+		NotifyRoomId       string        `json:"notifyRoomId"`
+		ChannelID          *uint         `json:"channelId"`
+		EscalationPolicyID *uint         `json:"escalationPolicyId"`
+		NotifyOnCritical   *bool         `json:"notifyOnCritical"`
+		NotifyOnHigh       *bool         `json:"notifyOnHigh"`
+		NotifyOnModerate   *bool         `json:"notifyOnModerate"`
+		NotifyOnLow        *bool         `json:"notifyOnLow"`
+		EmailRecipients    []string      `json:"emailRecipients"`
+		EmailAttachCsv     bool          `json:"emailAttachCsv"`
+		EmailDigest        bool          `json:"emailDigest"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -110,23 +110,32 @@ func (h *CveConfigHandler) Create(c *gin.Context) {
 		notifyOnLow = *input.NotifyOnLow
 	}
 	serviceInput := &services.CveConfigInput{
-		Name:             input.Name,
-		RepoUrl:          input.RepoUrl,
-		Languages:        input.Languages,
-		Cron:             input.Cron,
-		Status:           input.Status,
-		ApiKey:           input.ApiKey,
-		BotID:            input.BotID,
-		NotifyOnSuccess:  notifyOnSuccess,
-		NotifyOnFailure:  notifyOnFailure,
-		NotifyRoomId:     input.NotifyRoomId,
-		NotifyOnCritical: notifyOnCritical,
-		NotifyOnHigh:     notifyOnHigh,
-		NotifyOnModerate: notifyOnModerate,
-		NotifyOnLow:      notifyOnLow,
+		Name:               input.Name,
+		RepoUrl:            input.RepoUrl,
+		Languages:          input.Languages,
+		Cron:               input.Cron,
+		Status:             input.Status,
+		ApiKey:             input.ApiKey.Value(),
+		BotID:              input.BotID,
+		NotifyOnSuccess:    notifyOnSuccess,
+		NotifyOnFailure:    notifyOnFailure,
+		NotifyRoomId:       input.NotifyRoomId,
+		ChannelID:          input.ChannelID,
+		EscalationPolicyID: input.EscalationPolicyID,
+		NotifyOnCritical:   notifyOnCritical,
+		NotifyOnHigh:       notifyOnHigh,
+		NotifyOnModerate:   notifyOnModerate,
+		NotifyOnLow:        notifyOnLow,
+		EmailRecipients:    input.EmailRecipients,
+		EmailAttachCsv:     input.EmailAttachCsv,
+		EmailDigest:        input.EmailDigest,
 	}
 
 	config, err := h.service.Create(uint(projectID), serviceInput)
+	if appErr, ok := err.(*errors.AppError); ok {
+		utils.RespondWithError(c, http.StatusBadRequest, appErr)
+		return
+	}
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseInsert, err.Error()))
 		return
@@ -134,7 +143,7 @@ func (h *CveConfigHandler) Create(c *gin.Context) {
 
 	h.cronService.SyncCVEConfigs()
 
-	utils.RespondWithOK(c, http.StatusCreated, buildCveConfigResponse(config))
+	utils.RespondWithOK(c, http.StatusCreated, h.withRoomWarnings(config))
 }
 
 func (h *CveConfigHandler) Update(c *gin.Context) {
@@ -143,10 +152,6 @@ func (h *CveConfigHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	configID := c.Param("configId")
 	if configID == "" {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "configId is required"))
@@ -154,20 +159,25 @@ func (h *CveConfigHandler) Update(c *gin.Context) {
 	}
 
 	var input struct {
-		Name             *string `json:"name"`
-		RepoUrl          *string `json:"repoUrl"`
-		Languages        *string `json:"languages"`
-		Cron             *string `json:"cron"`
-		Status           *string `json:"status"`
-		ApiKey           *string `json:"apiKey"`
-		BotID            *int    `json:"botId"`
-		NotifyOnSuccess  *bool   `json:"notifyOnSuccess"`
-		NotifyOnFailure  *bool   `json:"notifyOnFailure"`
-		NotifyRoomId     *string `json:"notifyRoomId"`
-		NotifyOnCritical *bool   `json:"notifyOnCritical"`
-		NotifyOnHigh     *bool   `json:"notifyOnHigh"`
-		NotifyOnModerate *bool   `json:"notifyOnModerate"`
-		NotifyOnLow      *bool   `json:"notifyOnLow"`
+		Name               *string       `json:"name"`
+		RepoUrl            *string       `json:"repoUrl"`
+		Languages          *string       `json:"languages"`
+		Cron               *string       `json:"cron"`
+		Status             *string       `json:"status"`
+		ApiKey             secrets.Input `json:"apiKey"`
+		BotID              *int          `json:"botId"`
+		NotifyOnSuccess    *bool         `json:"notifyOnSuccess"`
+		NotifyOnFailure    *bool         `json:"notifyOnFailure"`
+		NotifyRoomId       *string       `json:"notifyRoomId"`
+		ChannelID          *uint         `json:"channelId"`
+		EscalationPolicyID *uint         `json:"escalationPolicyId"`
+		NotifyOnCritical   *bool         `json:"notifyOnCritical"`
+		NotifyOnHigh       *bool         `json:"notifyOnHigh"`
+		NotifyOnModerate   *bool         `json:"notifyOnModerate"`
+		NotifyOnLow        *bool         `json:"notifyOnLow"`
+		EmailRecipients    []string      `json:"emailRecipients"`
+		EmailAttachCsv     *bool         `json:"emailAttachCsv"`
+		EmailDigest        *bool         `json:"emailDigest"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -176,23 +186,32 @@ func (h *CveConfigHandler) Update(c *gin.Context) {
 	}
 
 	serviceInput := &services.CveConfigUpdateInput{
-		Name:             input.Name,
-		RepoUrl:          input.RepoUrl,
-		Languages:        input.Languages,
-		Cron:             input.Cron,
-		Status:           input.Status,
-		ApiKey:           input.ApiKey,
-		BotID:            input.BotID,
-		NotifyOnSuccess:  input.NotifyOnSuccess,
-		NotifyOnFailure:  input.NotifyOnFailure,
-		NotifyRoomId:     input.NotifyRoomId,
-		NotifyOnCritical: input.NotifyOnCritical,
-		NotifyOnHigh:     input.NotifyOnHigh,
-		NotifyOnModerate: input.NotifyOnModerate,
-		NotifyOnLow:      input.NotifyOnLow,
+		Name:               input.Name,
+		RepoUrl:            input.RepoUrl,
+		Languages:          input.Languages,
+		Cron:               input.Cron,
+		Status:             input.Status,
+		ApiKey:             input.ApiKey.Ptr(),
+		BotID:              input.BotID,
+		NotifyOnSuccess:    input.NotifyOnSuccess,
+		NotifyOnFailure:    input.NotifyOnFailure,
+		NotifyRoomId:       input.NotifyRoomId,
+		ChannelID:          input.ChannelID,
+		EscalationPolicyID: input.EscalationPolicyID,
+		NotifyOnCritical:   input.NotifyOnCritical,
+		NotifyOnHigh:       input.NotifyOnHigh,
+		NotifyOnModerate:   input.NotifyOnModerate,
+		NotifyOnLow:        input.NotifyOnLow,
+		EmailRecipients:    input.EmailRecipients,
+		EmailAttachCsv:     input.EmailAttachCsv,
+		EmailDigest:        input.EmailDigest,
 	}
 
 	config, err := h.service.Update(configID, uint(projectID), serviceInput)
+	if appErr, ok := err.(*errors.AppError); ok {
+		utils.RespondWithError(c, http.StatusBadRequest, appErr)
+		return
+	}
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseUpdate, err.Error()))
 		return
@@ -200,7 +219,20 @@ func (h *CveConfigHandler) Update(c *gin.Context) {
 
 	h.cronService.SyncCVEConfigs()
 
-	utils.RespondWithOK(c, http.StatusOK, buildCveConfigResponse(config))
+	utils.RespondWithOK(c, http.StatusOK, h.withRoomWarnings(config))
+}
+
+// withRoomWarnings adds "warnings" when the cached directory shows the bot
+// can't post to the notification room.
+func (h *CveConfigHandler) withRoomWarnings(config *models.CveConfig) gin.H {
+	resp := buildCveConfigResponse(config)
+	if config.BotID == nil || config.ChannelID != nil || config.NotifyRoomId == "" {
+		return resp
+	}
+	if warnings := h.botService.RoomWarnings(uint(*config.BotID), config.NotifyRoomId, nil); len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	return resp
 }
 
 func (h *CveConfigHandler) Delete(c *gin.Context) {
@@ -209,10 +241,6 @@ func (h *CveConfigHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	configID := c.Param("configId")
 	if configID == "" {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "configId is required"))
@@ -235,10 +263,6 @@ func (h *CveConfigHandler) Toggle(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	configID := c.Param("configId")
 	if configID == "" {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "configId is required"))
@@ -265,10 +289,6 @@ func (h *CveConfigHandler) Scan(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	configID := c.Param("configId")
 	if configID == "" {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "configId is required"))
@@ -286,12 +306,7 @@ func (h *CveConfigHandler) Scan(c *gin.Context) {
 }
 
 func (h *CveConfigHandler) Test(c *gin.Context) {
-	projectID, err := parseIDParam(c, "projectId")
-	if err != nil {
-		return
-	}
-
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
+	if _, err := parseIDParam(c, "projectId"); err != nil {
 		return
 	}
 
@@ -354,10 +369,6 @@ func (h *CveConfigHandler) GetVulnerabilities(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	configID := c.Param("configId")
 	if configID == "" {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "configId is required"))
@@ -387,10 +398,6 @@ func (h *CveConfigHandler) GetScanLogs(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	configID := c.Param("configId")
 	if configID == "" {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "configId is required"))
@@ -424,10 +431,6 @@ func (h *CveConfigHandler) GetAnalysis(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	analysis, err := h.service.GetAnalysisByProject(uint(projectID))
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseQuery, err.Error()))
@@ -458,25 +461,6 @@ func (h *CveConfigHandler) GetAnalysis(c *gin.Context) {
 	})
 }
 
-func (h *CveConfigHandler) checkProjectAccess(c *gin.Context, projectID uint) error {
-	projectSecretKey := c.GetHeader("X-Project-Key")
-	if projectSecretKey == "" {
-		projectSecretKey = c.Query("project_key")
-	}
-
-	if projectSecretKey != "" {
-		return nil
-	}
-
-	_, exists := c.Get("projectID")
-	if exists {
-		return nil
-	}
-
-	utils.RespondWithError(c, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Unauthorized"))
-	return &errors.AppError{Code: errors.ErrAuthUnauthorized, Message: "Unauthorized"}
-}
-
 func buildCveScanLogResponse(log *models.CveScanLog) gin.H {
 	resp := gin.H{
 		"id":             log.ID,
@@ -525,9 +509,16 @@ func buildCveConfigResponse(config *models.CveConfig) gin.H {
 	if config.NotifyRoomId != "" {
 		resp["notifyRoomId"] = config.NotifyRoomId
 	}
-	if config.ApiKey != "" {
-		resp["apiKey"] = "cwk_***hidden***"
+	if config.ChannelID != nil {
+		resp["channelId"] = *config.ChannelID
+	}
+	if config.EscalationPolicyID != nil {
+		resp["escalationPolicyId"] = *config.EscalationPolicyID
 	}
+	resp["emailRecipients"] = config.EmailRecipients
+	resp["emailAttachCsv"] = config.EmailAttachCsv
+	resp["emailDigest"] = config.EmailDigest
+	resp["apiKey"] = secrets.RefOf(config.ApiKey)
 
 	return resp
 }
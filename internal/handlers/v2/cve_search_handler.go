@@ -0,0 +1,142 @@
+package v2
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+const maxCveSearchLimit = 100
+
+// CveSearchHandler serves the local search over crawled NVD records
+type CveSearchHandler struct {
+	service services.ICveSearchService
+}
+
+// NewCveSearchHandler creates a new CveSearchHandler
+func NewCveSearchHandler(service services.ICveSearchService) *CveSearchHandler {
+	return &CveSearchHandler{service: service}
+}
+
+// Search lists crawled CVEs with filters and facet counts.
+// GET /api/v2/cves?q=overflow&cwe=CWE-787&vendor=apache&product=log4j&severity=critical&minScore=7&maxScore=10&from=2026-01-01&to=2026-01-31&page=1&limit=20
+func (h *CveSearchHandler) Search(c *gin.Context) {
+	filter, err := parseCveSearchFilter(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	paging := utils.GeneratePagingFromRequest(c)
+	if paging.Limit > maxCveSearchLimit {
+		paging.Limit = maxCveSearchLimit
+	}
+
+	result, err := h.service.Search(filter, paging)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseQuery, err.Error()))
+		return
+	}
+
+	data := make([]gin.H, 0, len(result.Records))
+	for i := range result.Records {
+		data = append(data, buildCveRecordResponse(&result.Records[i]))
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":   data,
+		"total":  result.Total,
+		"page":   paging.Page,
+		"limit":  paging.Limit,
+		"facets": result.Facets,
+	})
+}
+
+// GetByID returns a single crawled CVE.
+// GET /api/v2/cves/:cveId
+func (h *CveSearchHandler) GetByID(c *gin.Context) {
+	cveID := strings.ToUpper(c.Param("cveId"))
+
+	record, err := h.service.GetByCveID(cveID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, "CVE not found"))
+		return
+	}
+
+	resp := buildCveRecordResponse(record)
+	resp["metrics"] = record.Metrics
+	resp["references"] = record.References
+	utils.RespondWithOK(c, http.StatusOK, resp)
+}
+
+func parseCveSearchFilter(c *gin.Context) (*repositories.CveSearchFilter, error) {
+	filter := &repositories.CveSearchFilter{
+		Query:    c.Query("q"),
+		Cwe:      c.Query("cwe"),
+		Vendor:   c.Query("vendor"),
+		Product:  c.Query("product"),
+		Severity: c.Query("severity"),
+	}
+
+	if raw := c.Query("minScore"); raw != "" {
+		score, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.New(errors.ErrInvalidData, "minScore must be a number")
+		}
+		filter.MinScore = &score
+	}
+	if raw := c.Query("maxScore"); raw != "" {
+		score, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.New(errors.ErrInvalidData, "maxScore must be a number")
+		}
+		filter.MaxScore = &score
+	}
+	if raw := c.Query("from"); raw != "" {
+		from, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, errors.New(errors.ErrInvalidData, "from must be YYYY-MM-DD")
+		}
+		filter.From = &from
+	}
+	if raw := c.Query("to"); raw != "" {
+		to, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, errors.New(errors.ErrInvalidData, "to must be YYYY-MM-DD")
+		}
+		// Include the whole end day
+		to = to.Add(24*time.Hour - time.Second)
+		filter.To = &to
+	}
+
+	return filter, nil
+}
+
+func buildCveRecordResponse(record *models.CveRecord) gin.H {
+	cwes := make([]string, 0, len(record.Cwes))
+	for _, cwe := range record.Cwes {
+		cwes = append(cwes, cwe.CweID)
+	}
+
+	return gin.H{
+		"cveId":          record.CveID,
+		"description":    record.Description,
+		"severity":       record.Severity,
+		"baseScore":      record.BaseScore,
+		"cvssVersion":    record.CvssVersion,
+		"vectorString":   record.VectorString,
+		"cwes":           cwes,
+		"cpes":           record.Cpes,
+		"publishedAt":    record.PublishedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		"lastModifiedAt": record.LastModifiedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		"nvdUrl":         "https://nvd.nist.gov/vuln/detail/" + record.CveID,
+	}
+}
@@ -0,0 +1,100 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type fakeCveSearchService struct {
+	filter  *repositories.CveSearchFilter
+	paging  *utils.Paging
+	records map[string]*models.CveRecord
+}
+
+func (s *fakeCveSearchService) Search(filter *repositories.CveSearchFilter, paging *utils.Paging) (*services.CveSearchResult, error) {
+	s.filter, s.paging = filter, paging
+	var records []models.CveRecord
+	for _, r := range s.records {
+		records = append(records, *r)
+	}
+	return &services.CveSearchResult{Records: records, Total: int64(len(records)), Facets: &models.CveSearchFacets{}}, nil
+}
+
+func (s *fakeCveSearchService) GetByCveID(cveID string) (*models.CveRecord, error) {
+	if r, ok := s.records[cveID]; ok {
+		return r, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func newCveSearchTestRouter() (*gin.Engine, *fakeCveSearchService) {
+	gin.SetMode(gin.TestMode)
+	published := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	service := &fakeCveSearchService{records: map[string]*models.CveRecord{
+		"CVE-2026-0001": {CveID: "CVE-2026-0001", Description: "Heap overflow", Severity: "HIGH", PublishedAt: published, LastModifiedAt: published},
+	}}
+	h := NewCveSearchHandler(service)
+	router := gin.New()
+	router.GET("/cves", h.Search)
+	router.GET("/cves/:cveId", h.GetByID)
+	return router, service
+}
+
+func getJSON(router *gin.Engine, target string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func TestCveSearchFilter(t *testing.T) {
+	router, service := newCveSearchTestRouter()
+
+	w, body := getJSON(router, `/cves?q=%22heap+(overflow&severity=high&minScore=7.5&from=2026-10-01&to=2026-10-18&limit=500`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	f := service.filter
+	if f.Query != `"heap (overflow` || f.Severity != "high" || f.MinScore == nil || *f.MinScore != 7.5 || f.MaxScore != nil {
+		t.Fatalf("unexpected filter %+v", f)
+	}
+	if !f.To.Equal(time.Date(2026, 10, 18, 23, 59, 59, 0, time.UTC)) {
+		t.Fatalf("to = %s, want the end of the day", f.To)
+	}
+	if service.paging.Limit != maxCveSearchLimit {
+		t.Fatalf("limit = %d, want it capped at %d", service.paging.Limit, maxCveSearchLimit)
+	}
+	data := body["data"].([]interface{})
+	if len(data) != 1 || data[0].(map[string]interface{})["nvdUrl"] != "https://nvd.nist.gov/vuln/detail/CVE-2026-0001" {
+		t.Fatalf("unexpected data %v", data)
+	}
+
+	for _, target := range []string{"/cves?minScore=high", "/cves?maxScore=x", "/cves?from=18-10-2026", "/cves?to=yesterday"} {
+		if w, _ := getJSON(router, target); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", target, w.Code)
+		}
+	}
+}
+
+func TestCveGetByID(t *testing.T) {
+	router, _ := newCveSearchTestRouter()
+
+	w, body := getJSON(router, "/cves/cve-2026-0001")
+	if w.Code != http.StatusOK || body["cveId"] != "CVE-2026-0001" {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if w, _ := getJSON(router, "/cves/CVE-2026-9999"); w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,459 @@
+package v2
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// EscalationHandler exposes a project's on-call rotations, escalation
+// policies and the escalations run through them.
+type EscalationHandler struct {
+	service services.IEscalationService
+}
+
+func NewEscalationHandler(service services.IEscalationService) *EscalationHandler {
+	return &EscalationHandler{
+		service: service,
+	}
+}
+
+// GET /api/v2/projects/:projectId/oncall-rotations
+// Each rotation comes with who is on call now.
+func (h *EscalationHandler) GetRotations(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	rotations, err := h.service.GetRotations(projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	data := make([]rotationResponse, 0, len(rotations))
+	for i := range rotations {
+		data = append(data, h.buildRotationResponse(&rotations[i], false))
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": len(data),
+	})
+}
+
+type rotationRequest struct {
+	Name         *string                     `json:"name"`
+	Handoff      *string                     `json:"handoff"`
+	StartsAt     *time.Time                  `json:"startsAt"`
+	Timezone     *string                     `json:"timezone"`
+	Participants *[]models.OnCallParticipant `json:"participants"`
+}
+
+func (r *rotationRequest) input() *services.OnCallRotationInput {
+	return &services.OnCallRotationInput{
+		Name:         r.Name,
+		Handoff:      r.Handoff,
+		StartsAt:     r.StartsAt,
+		Timezone:     r.Timezone,
+		Participants: r.Participants,
+	}
+}
+
+// POST /api/v2/projects/:projectId/oncall-rotations
+func (h *EscalationHandler) CreateRotation(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	var req rotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	rotation, err := h.service.CreateRotation(projectID, req.input())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusCreated, h.buildRotationResponse(rotation, true))
+}
+
+// GET /api/v2/projects/:projectId/oncall-rotations/:rotationId
+// Includes the current and next shifts and the overrides that haven't ended.
+func (h *EscalationHandler) GetRotation(c *gin.Context) {
+	projectID, rotationID, ok := h.parseParams(c, "rotationId")
+	if !ok {
+		return
+	}
+
+	rotation, err := h.service.GetRotation(rotationID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, h.buildRotationResponse(rotation, true))
+}
+
+// PUT /api/v2/projects/:projectId/oncall-rotations/:rotationId
+func (h *EscalationHandler) UpdateRotation(c *gin.Context) {
+	projectID, rotationID, ok := h.parseParams(c, "rotationId")
+	if !ok {
+		return
+	}
+
+	var req rotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	rotation, err := h.service.UpdateRotation(rotationID, projectID, req.input())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, h.buildRotationResponse(rotation, true))
+}
+
+// DELETE /api/v2/projects/:projectId/oncall-rotations/:rotationId
+func (h *EscalationHandler) DeleteRotation(c *gin.Context) {
+	projectID, rotationID, ok := h.parseParams(c, "rotationId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRotation(rotationID, projectID); err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Rotation deleted"})
+}
+
+type overrideRequest struct {
+	AccountID int64     `json:"accountId" binding:"required"`
+	Name      string    `json:"name"`
+	StartsAt  time.Time `json:"startsAt" binding:"required"`
+	EndsAt    time.Time `json:"endsAt" binding:"required"`
+	Reason    string    `json:"reason"`
+}
+
+// POST /api/v2/projects/:projectId/oncall-rotations/:rotationId/overrides
+func (h *EscalationHandler) CreateOverride(c *gin.Context) {
+	projectID, rotationID, ok := h.parseParams(c, "rotationId")
+	if !ok {
+		return
+	}
+
+	var req overrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	override, err := h.service.CreateOverride(rotationID, projectID, &services.OnCallOverrideInput{
+		AccountID: req.AccountID,
+		Name:      req.Name,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusCreated, override)
+}
+
+// DELETE /api/v2/projects/:projectId/oncall-rotations/:rotationId/overrides/:overrideId
+func (h *EscalationHandler) DeleteOverride(c *gin.Context) {
+	projectID, rotationID, ok := h.parseParams(c, "rotationId")
+	if !ok {
+		return
+	}
+	overrideID, err := strconv.ParseUint(c.Param("overrideId"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid overrideId"))
+		return
+	}
+
+	if err := h.service.DeleteOverride(rotationID, uint(overrideID), projectID); err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Override deleted"})
+}
+
+// GET /api/v2/projects/:projectId/escalation-policies
+func (h *EscalationHandler) GetPolicies(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	policies, err := h.service.GetPolicies(projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	if policies == nil {
+		policies = []models.EscalationPolicy{}
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  policies,
+		"total": len(policies),
+	})
+}
+
+type policyRequest struct {
+	Name   *string                   `json:"name"`
+	BotID  *uint                     `json:"botId"`
+	RoomID *string                   `json:"roomId"`
+	Levels *[]models.EscalationLevel `json:"levels"`
+}
+
+func (r *policyRequest) input() *services.EscalationPolicyInput {
+	return &services.EscalationPolicyInput{
+		Name:   r.Name,
+		BotID:  r.BotID,
+		RoomID: r.RoomID,
+		Levels: r.Levels,
+	}
+}
+
+// POST /api/v2/projects/:projectId/escalation-policies
+func (h *EscalationHandler) CreatePolicy(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	var req policyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	policy, err := h.service.CreatePolicy(projectID, req.input())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusCreated, policy)
+}
+
+// GET /api/v2/projects/:projectId/escalation-policies/:policyId
+func (h *EscalationHandler) GetPolicy(c *gin.Context) {
+	projectID, policyID, ok := h.parseParams(c, "policyId")
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.GetPolicy(policyID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, policy)
+}
+
+// PUT /api/v2/projects/:projectId/escalation-policies/:policyId
+func (h *EscalationHandler) UpdatePolicy(c *gin.Context) {
+	projectID, policyID, ok := h.parseParams(c, "policyId")
+	if !ok {
+		return
+	}
+
+	var req policyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(policyID, projectID, req.input())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, policy)
+}
+
+// DELETE /api/v2/projects/:projectId/escalation-policies/:policyId
+func (h *EscalationHandler) DeletePolicy(c *gin.Context) {
+	projectID, policyID, ok := h.parseParams(c, "policyId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePolicy(policyID, projectID); err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Escalation policy deleted"})
+}
+
+type triggerRequest struct {
+	Title     string `json:"title" binding:"required"`
+	Text      string `json:"text"`
+	SourceRef string `json:"sourceRef"`
+}
+
+// POST /api/v2/projects/:projectId/escalation-policies/:policyId/trigger
+// Starts an escalation by hand, e.g. to try a policy out.
+func (h *EscalationHandler) Trigger(c *gin.Context) {
+	projectID, policyID, ok := h.parseParams(c, "policyId")
+	if !ok {
+		return
+	}
+
+	var req triggerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	escalation, err := h.service.Trigger(projectID, policyID, &services.EscalationTrigger{
+		Source:    "manual",
+		SourceRef: req.SourceRef,
+		Title:     req.Title,
+		Text:      req.Text,
+	})
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusCreated, escalation)
+}
+
+// GET /api/v2/projects/:projectId/escalations?status=open|acknowledged|exhausted
+func (h *EscalationHandler) GetEscalations(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	paging := utils.GeneratePagingFromRequest(c)
+	escalations, total, err := h.service.GetEscalations(projectID, c.Query("status"), paging)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	if escalations == nil {
+		escalations = []models.Escalation{}
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  escalations,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// GET /api/v2/projects/:projectId/escalations/:escalationId
+func (h *EscalationHandler) GetEscalation(c *gin.Context) {
+	projectID, escalationID, ok := h.parseParams(c, "escalationId")
+	if !ok {
+		return
+	}
+
+	escalation, err := h.service.GetEscalation(escalationID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, escalation)
+}
+
+// POST /api/v2/projects/:projectId/escalations/:escalationId/ack
+// Body (optional): {"by": "alice"}; defaults to "API".
+func (h *EscalationHandler) Acknowledge(c *gin.Context) {
+	projectID, escalationID, ok := h.parseParams(c, "escalationId")
+	if !ok {
+		return
+	}
+
+	var input alertAckRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+			return
+		}
+	}
+	if input.By == "" {
+		input.By = "API"
+	}
+
+	escalation, err := h.service.Acknowledge(escalationID, projectID, input.By)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, escalation)
+}
+
+type rotationResponse struct {
+	*models.OnCallRotation
+	Current *models.OnCallShift `json:"current"`
+	Next    *models.OnCallShift `json:"next,omitempty"`
+}
+
+// buildRotationResponse adds who is on call now and, with next, who takes
+// over at the end of the current shift.
+func (h *EscalationHandler) buildRotationResponse(rotation *models.OnCallRotation, next bool) rotationResponse {
+	resp := rotationResponse{OnCallRotation: rotation}
+	now := time.Now()
+	if current, err := h.service.OnCall(rotation.ID, rotation.ProjectID, now); err == nil {
+		resp.Current = current
+		if next && current != nil {
+			if shift, err := h.service.OnCall(rotation.ID, rotation.ProjectID, current.EndsAt); err == nil {
+				resp.Next = shift
+			}
+		}
+	}
+	return resp
+}
+
+func (h *EscalationHandler) parseProject(c *gin.Context) (uint, bool) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return 0, false
+	}
+	return uint(projectID), true
+}
+
+func (h *EscalationHandler) parseParams(c *gin.Context, param string) (uint, uint, bool) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid "+param))
+		return 0, 0, false
+	}
+	return projectID, uint(id), true
+}
@@ -0,0 +1,229 @@
+package v2
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/secrets"
+)
+
+// NotificationChannelHandler manages a project's notification channels.
+type NotificationChannelHandler struct {
+	service services.INotificationService
+}
+
+func NewNotificationChannelHandler(service services.INotificationService) *NotificationChannelHandler {
+	return &NotificationChannelHandler{
+		service: service,
+	}
+}
+
+type notificationChannelRequest struct {
+	Name       *string       `json:"name"`
+	Type       *string       `json:"type"`
+	RoomID     *string       `json:"roomId"`
+	BotID      *uint         `json:"botId"`
+	Token      secrets.Input `json:"token"`
+	WebhookURL secrets.Input `json:"webhookUrl"`
+	Secret     secrets.Input `json:"secret"`
+	Recipients []string      `json:"recipients"`
+	Active     *bool         `json:"active"`
+}
+
+func (r *notificationChannelRequest) toInput() *services.NotificationChannelInput {
+	return &services.NotificationChannelInput{
+		Name:       r.Name,
+		Type:       r.Type,
+		RoomID:     r.RoomID,
+		BotID:      r.BotID,
+		Token:      r.Token.Ptr(),
+		WebhookURL: r.WebhookURL.Ptr(),
+		Secret:     r.Secret.Ptr(),
+		Recipients: r.Recipients,
+		Active:     r.Active,
+	}
+}
+
+// GET /api/v2/projects/:projectId/channels
+func (h *NotificationChannelHandler) GetByProject(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	channels, err := h.service.GetByProjectID(uint(projectID))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(channels))
+	for i := range channels {
+		data = append(data, buildNotificationChannelResponse(&channels[i]))
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": len(data),
+	})
+}
+
+// POST /api/v2/projects/:projectId/channels
+func (h *NotificationChannelHandler) Create(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	var input notificationChannelRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	channel, err := h.service.Create(uint(projectID), input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	// The webhook secret is only returned on creation, like the project secret key
+	resp := buildNotificationChannelResponse(channel)
+	if channel.Secret != "" {
+		resp["secret"] = channel.Secret
+	}
+	utils.RespondWithOK(c, http.StatusCreated, resp)
+}
+
+// PUT /api/v2/projects/:projectId/channels/:channelId
+func (h *NotificationChannelHandler) Update(c *gin.Context) {
+	projectID, channelID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	var input notificationChannelRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	channel, err := h.service.Update(channelID, projectID, input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, buildNotificationChannelResponse(channel))
+}
+
+// DELETE /api/v2/projects/:projectId/channels/:channelId
+func (h *NotificationChannelHandler) Delete(c *gin.Context) {
+	projectID, channelID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(channelID, projectID); err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Channel deleted"})
+}
+
+// POST /api/v2/projects/:projectId/channels/:channelId/test
+func (h *NotificationChannelHandler) Test(c *gin.Context) {
+	projectID, channelID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Test(channelID, projectID); err != nil {
+		if _, isAppErr := err.(*errors.AppError); isAppErr {
+			respondAppError(c, err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusBadGateway, errors.New(errors.ErrServerInternal, err.Error()))
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Test notification sent"})
+}
+
+func (h *NotificationChannelHandler) parseParams(c *gin.Context) (uint, uint, bool) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return 0, 0, false
+	}
+
+	channelID, err := strconv.ParseUint(c.Param("channelId"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid channelId"))
+		return 0, 0, false
+	}
+	return uint(projectID), uint(channelID), true
+}
+
+// respondAppError maps validation and not-found AppErrors to 400 and 404, anything else to 500.
+func respondAppError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if appErr, ok := err.(*errors.AppError); ok {
+		switch appErr.Code {
+		case errors.ErrInvalidData:
+			status = http.StatusBadRequest
+		case errors.ErrResourceNotFound:
+			status = http.StatusNotFound
+		case errors.ErrAuthUnauthorized:
+			status = http.StatusUnauthorized
+		case errors.ErrAuthForbidden:
+			status = http.StatusForbidden
+		}
+	}
+	utils.RespondWithError(c, status, err)
+}
+
+// buildNotificationChannelResponse never includes tokens, secrets or full webhook URLs:
+// they are returned as secrets.Ref values that can be sent back unchanged on update.
+func buildNotificationChannelResponse(ch *models.NotificationChannel) gin.H {
+	resp := gin.H{
+		"id":        ch.ID,
+		"projectId": ch.ProjectID,
+		"name":      ch.Name,
+		"type":      ch.Type,
+		"active":    ch.Active,
+		"createdAt": ch.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if ch.RoomID != "" {
+		resp["roomId"] = ch.RoomID
+	}
+	if ch.BotID != nil {
+		resp["botId"] = *ch.BotID
+	}
+	if ch.Token != "" {
+		resp["token"] = secrets.RefOf(ch.Token)
+	}
+	if ch.WebhookURL != "" {
+		resp["webhookUrl"] = secrets.Ref{Set: true, Hint: maskWebhookURL(ch.WebhookURL)}
+	}
+	if len(ch.Recipients) > 0 {
+		resp["recipients"] = ch.Recipients
+	}
+	resp["hasSecret"] = ch.Secret != ""
+	return resp
+}
+
+// maskWebhookURL keeps the scheme and host; webhook paths usually embed credentials.
+func maskWebhookURL(raw string) string {
+	for i := len("https://"); i < len(raw); i++ {
+		if raw[i] == '/' {
+			return raw[:i] + "/***"
+		}
+	}
+	return raw
+}
@@ -0,0 +1,120 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// OIDCAuthorize starts a single sign-on. The dashboard keeps the state and
+// sends the browser to the authorization URL; the provider redirects back to
+// OIDC_REDIRECT_URL with a code and the same state.
+// GET /api/v2/auth/oidc/authorize
+// Response 200: { "authorizationUrl": "https://idp.example.com/authorize?...", "state": "..." }
+// Response 404: Single sign-on is not configured
+func (h *AuthHandler) OIDCAuthorize(c *gin.Context) {
+	authURL, state, err := h.oidcService.Begin(c.Request.Context())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"authorizationUrl": authURL, "state": state})
+}
+
+// OIDCCallback completes a single sign-on with the code and state the
+// provider redirected back with, and signs the user in like Login.
+// POST /api/v2/auth/oidc/callback
+// Body: { "code": "...", "state": "..." }
+// Response 401: The sign-in is invalid, expired or refused by the provider
+// Response 403: No account for the email and auto-provisioning is off
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	var input struct {
+		Code  string `json:"code" binding:"required"`
+		State string `json:"state" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "code and state are required"))
+		return
+	}
+
+	user, err := h.oidcService.Complete(c.Request.Context(), input.Code, input.State)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	h.startSession(c, user)
+}
+
+// GetOIDCGroupMappings lists which provider groups get which project roles.
+// GET /api/v2/oidc/group-mappings
+func (h *AuthHandler) GetOIDCGroupMappings(c *gin.Context) {
+	mappings, err := h.oidcService.GetGroupMappings()
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(mappings))
+	for i := range mappings {
+		data = append(data, buildOIDCGroupMappingResponse(&mappings[i]))
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"data": data, "total": len(data)})
+}
+
+// CreateOIDCGroupMapping gives members of a provider group a role in a
+// project from their next single sign-on.
+// POST /api/v2/oidc/group-mappings
+// Body: { "group": "platform-team", "projectId": 3, "role": "maintainer" }
+func (h *AuthHandler) CreateOIDCGroupMapping(c *gin.Context) {
+	var input struct {
+		Group     string `json:"group" binding:"required"`
+		ProjectID uint   `json:"projectId" binding:"required"`
+		Role      string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "group, projectId and role are required"))
+		return
+	}
+
+	mapping, err := h.oidcService.CreateGroupMapping(&services.OIDCGroupMappingInput{
+		Group:     input.Group,
+		ProjectID: input.ProjectID,
+		Role:      input.Role,
+	})
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusCreated, buildOIDCGroupMappingResponse(mapping))
+}
+
+// DeleteOIDCGroupMapping stops the group from granting the role. Roles
+// already synced are removed on the members' next single sign-on.
+// DELETE /api/v2/oidc/group-mappings/:mappingId
+func (h *AuthHandler) DeleteOIDCGroupMapping(c *gin.Context) {
+	id, err := parseIDParam(c, "mappingId")
+	if err != nil {
+		return
+	}
+	if err := h.oidcService.DeleteGroupMapping(uint(id)); err != nil {
+		respondAppError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func buildOIDCGroupMappingResponse(m *models.OIDCGroupMapping) gin.H {
+	return gin.H{
+		"id":        m.ID,
+		"group":     m.Group,
+		"projectId": m.ProjectID,
+		"role":      m.Role,
+		"createdAt": m.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
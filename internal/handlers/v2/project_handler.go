@@ -1,11 +1,11 @@
 package v2
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/middlewares"
 	"github.com/vfa-khuongdv/golang-cms/internal/models"
 	"github.com/vfa-khuongdv/golang-cms/internal/services"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
@@ -15,24 +15,32 @@ import (
 // ProjectHandlerV2 handles V2 project endpoints
 type ProjectHandlerV2 struct {
 	service     services.IProjectService
+	keyService  services.IProjectKeyService
 	cronService services.ICronService
 }
 
 // NewProjectHandlerV2 creates a new ProjectHandlerV2
-func NewProjectHandlerV2(service services.IProjectService, cronService services.ICronService) *ProjectHandlerV2 {
+func NewProjectHandlerV2(service services.IProjectService, keyService services.IProjectKeyService, cronService services.ICronService) *ProjectHandlerV2 {
 	return &ProjectHandlerV2{
 		service:     service,
+		keyService:  keyService,
 		cronService: cronService,
 	}
 }
 
-// GetAll lists all projects with pagination and optional status filter.
+// GetAll lists projects with pagination and optional status filter. Admins
+// see every project, other users the projects they are a member of.
 // GET /api/v2/projects?page=1&limit=20&status=active
 func (h *ProjectHandlerV2) GetAll(c *gin.Context) {
 	paging := utils.GeneratePagingFromRequest(c)
 	statusFilter := c.Query("status") // "active" | "inactive" | ""
 
-	projects, total, err := h.service.GetAllV2(statusFilter, paging)
+	var memberID uint
+	if user := middlewares.CurrentUser(c); user != nil && !user.IsAdmin {
+		memberID = user.ID
+	}
+
+	projects, total, err := h.service.GetAllV2(statusFilter, memberID, paging)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseQuery, err.Error()))
 		return
@@ -51,13 +59,16 @@ func (h *ProjectHandlerV2) GetAll(c *gin.Context) {
 	})
 }
 
-// Create creates a new project. secretKey is auto-generated server-side.
+// Create creates a new project with a "Default" API key that has every
+// scope. The key's secret is only returned here.
 // POST /api/v2/projects
 func (h *ProjectHandlerV2) Create(c *gin.Context) {
 	var input struct {
 		Name        string `json:"name" binding:"required"`
 		Description string `json:"description"`
 		Status      string `json:"status"`
+		AlertRoomID string `json:"alertRoomId"`
+		AlertBotID  *uint  `json:"alertBotId"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -70,19 +81,17 @@ func (h *ProjectHandlerV2) Create(c *gin.Context) {
 		status = "active"
 	}
 
-	// Auto-generate secret key: sk_proj_<12 random chars>
-	secretKey := fmt.Sprintf("sk_proj_%s", utils.GenerateRandomString(12))
-
 	project := &models.Project{
 		Name:        input.Name,
 		Description: input.Description,
 		Status:      status,
-		SecretKey:   secretKey,
+		AlertRoomID: input.AlertRoomID,
+		AlertBotID:  input.AlertBotID,
 	}
 
-	created, err := h.service.Create(project)
+	created, key, secretKey, err := h.keyService.CreateProject(project, c.GetUint("UserID"))
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseQuery, err.Error()))
+		respondAppError(c, err)
 		return
 	}
 
@@ -92,7 +101,8 @@ func (h *ProjectHandlerV2) Create(c *gin.Context) {
 		"name":           created.Name,
 		"description":    created.Description,
 		"status":         created.Status,
-		"secretKey":      created.SecretKey,
+		"secretKey":      secretKey,
+		"apiKey":         buildProjectKeyResponse(key),
 		"createdAt":      created.CreatedAt.Format("2006-01-02"),
 		"schedulesCount": 0,
 	})
@@ -115,7 +125,7 @@ func (h *ProjectHandlerV2) GetByID(c *gin.Context) {
 	utils.RespondWithOK(c, http.StatusOK, buildProjectResponse(project))
 }
 
-// Update partially updates a project. secretKey cannot be changed.
+// Update partially updates a project. API keys are managed under /api-keys.
 // PATCH /api/v2/projects/:projectId
 func (h *ProjectHandlerV2) Update(c *gin.Context) {
 	id, err := parseIDParam(c, "projectId")
@@ -133,6 +143,8 @@ func (h *ProjectHandlerV2) Update(c *gin.Context) {
 		Name        *string `json:"name"`
 		Description *string `json:"description"`
 		Status      *string `json:"status"`
+		AlertRoomID *string `json:"alertRoomId"`
+		AlertBotID  *uint   `json:"alertBotId"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -149,6 +161,12 @@ func (h *ProjectHandlerV2) Update(c *gin.Context) {
 	if input.Status != nil {
 		project.Status = *input.Status
 	}
+	if input.AlertRoomID != nil {
+		project.AlertRoomID = *input.AlertRoomID
+	}
+	if input.AlertBotID != nil {
+		project.AlertBotID = input.AlertBotID
+	}
 
 	updated, err := h.service.Update(project)
 	if err != nil {
@@ -185,7 +203,7 @@ func (h *ProjectHandlerV2) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// Access validates a project's secret key.
+// Access validates one of the project's API keys.
 // POST /api/v2/projects/:projectId/access
 func (h *ProjectHandlerV2) Access(c *gin.Context) {
 	id, err := parseIDParam(c, "projectId")
@@ -208,8 +226,7 @@ func (h *ProjectHandlerV2) Access(c *gin.Context) {
 		return
 	}
 
-	valid, err := h.service.ValidateSecretKey(uint(id), input.SecretKey)
-	if err != nil || !valid {
+	if _, err := h.keyService.VerifyKey(uint(id), input.SecretKey, c.ClientIP()); err != nil {
 		utils.RespondWithError(c, http.StatusForbidden, errors.New(errors.ErrAuthForbidden, "Invalid secret key"))
 		return
 	}
@@ -245,5 +262,7 @@ func buildProjectResponse(p *models.Project) gin.H {
 		"status":         p.Status,
 		"createdAt":      p.CreatedAt.Format("2006-01-02"),
 		"schedulesCount": schedulesCount,
+		"alertRoomId":    p.AlertRoomID,
+		"alertBotId":     p.AlertBotID,
 	}
 }
@@ -0,0 +1,192 @@
+package v2
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// ProjectKeyHandler lets project owners manage the project's API keys.
+type ProjectKeyHandler struct {
+	service services.IProjectKeyService
+}
+
+func NewProjectKeyHandler(service services.IProjectKeyService) *ProjectKeyHandler {
+	return &ProjectKeyHandler{service: service}
+}
+
+type projectKeyRequest struct {
+	Name      *string    `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+func (r *projectKeyRequest) input() *services.ProjectKeyInput {
+	return &services.ProjectKeyInput{
+		Name:      r.Name,
+		Scopes:    r.Scopes,
+		ExpiresAt: r.ExpiresAt,
+	}
+}
+
+// GetAll lists the project's keys, including expired and revoked ones.
+// GET /api/v2/projects/:projectId/api-keys
+func (h *ProjectKeyHandler) GetAll(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	keys, err := h.service.GetKeys(uint(projectID))
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(keys))
+	for i := range keys {
+		data = append(data, buildProjectKeyResponse(&keys[i]))
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"data": data, "total": len(data)})
+}
+
+// Create issues a key. The secret is only returned in this response.
+// POST /api/v2/projects/:projectId/api-keys
+// Body: { "name": "CI", "scopes": ["schedules:read", "cve:scan"], "expiresAt": "2027-01-01T00:00:00Z" }
+func (h *ProjectKeyHandler) Create(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	var req projectKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	key, secret, err := h.service.CreateKey(uint(projectID), req.input(), c.GetUint("UserID"))
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusCreated, buildProjectKeySecretResponse(key, secret))
+}
+
+// Update changes a key's name, scopes or expiry.
+// PATCH /api/v2/projects/:projectId/api-keys/:keyId
+func (h *ProjectKeyHandler) Update(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+	id, err := parseIDParam(c, "keyId")
+	if err != nil {
+		return
+	}
+
+	var req projectKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	key, err := h.service.UpdateKey(uint(projectID), uint(id), req.input())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, buildProjectKeyResponse(key))
+}
+
+// Rotate replaces a key with a new secret. The old secret keeps working for
+// gracePeriodMinutes (default 0, at most 7 days) so clients can switch over.
+// POST /api/v2/projects/:projectId/api-keys/:keyId/rotate
+// Body: { "gracePeriodMinutes": 1440 }
+func (h *ProjectKeyHandler) Rotate(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+	id, err := parseIDParam(c, "keyId")
+	if err != nil {
+		return
+	}
+
+	var input struct {
+		GracePeriodMinutes int `json:"gracePeriodMinutes"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+			return
+		}
+	}
+
+	grace := time.Duration(input.GracePeriodMinutes) * time.Minute
+	key, secret, err := h.service.RotateKey(uint(projectID), uint(id), grace, c.GetUint("UserID"))
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusCreated, buildProjectKeySecretResponse(key, secret))
+}
+
+// Revoke stops a key from working at once. It stays listed as revoked.
+// DELETE /api/v2/projects/:projectId/api-keys/:keyId
+func (h *ProjectKeyHandler) Revoke(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+	id, err := parseIDParam(c, "keyId")
+	if err != nil {
+		return
+	}
+
+	if err := h.service.RevokeKey(uint(projectID), uint(id)); err != nil {
+		respondAppError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func buildProjectKeyResponse(k *models.ProjectAPIKey) gin.H {
+	resp := gin.H{
+		"id":         k.ID,
+		"name":       k.Name,
+		"prefix":     k.Prefix,
+		"scopes":     k.Scopes,
+		"expiresAt":  nil,
+		"lastUsedAt": nil,
+		"lastUsedIp": k.LastUsedIP,
+		"revokedAt":  nil,
+		"active":     k.Active(time.Now()),
+		"mustRotate": k.MustRotate,
+		"createdBy":  k.CreatedBy,
+		"createdAt":  k.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if k.ExpiresAt != nil {
+		resp["expiresAt"] = k.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if k.LastUsedAt != nil {
+		resp["lastUsedAt"] = k.LastUsedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if k.RevokedAt != nil {
+		resp["revokedAt"] = k.RevokedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return resp
+}
+
+// buildProjectKeySecretResponse adds the secret to a key that was just
+// issued; it can't be shown again.
+func buildProjectKeySecretResponse(k *models.ProjectAPIKey, secret string) gin.H {
+	resp := buildProjectKeyResponse(k)
+	resp["secret"] = secret
+	return resp
+}
@@ -8,31 +8,32 @@ import (
 	"github.com/vfa-khuongdv/golang-cms/internal/services"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
 	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/secrets"
 )
 
 // ScheduleHandlerV2 handles V2 schedule endpoints
 type ScheduleHandlerV2 struct {
 	service         services.IReminderScheduleService
-	projectService  services.IProjectService
 	cronService     services.ICronService
 	chatworkService services.IChatworkService
 	botService      services.IChatworkBotService
+	notifications   services.INotificationService
 }
 
 // NewScheduleHandlerV2 creates a new ScheduleHandlerV2
 func NewScheduleHandlerV2(
 	service services.IReminderScheduleService,
-	projectService services.IProjectService,
 	cronService services.ICronService,
 	chatworkService services.IChatworkService,
 	botService services.IChatworkBotService,
+	notifications services.INotificationService,
 ) *ScheduleHandlerV2 {
 	return &ScheduleHandlerV2{
 		service:         service,
-		projectService:  projectService,
 		cronService:     cronService,
 		chatworkService: chatworkService,
 		botService:      botService,
+		notifications:   notifications,
 	}
 }
 
@@ -44,10 +45,6 @@ func (h *ScheduleHandlerV2) GetByProject(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	paging := utils.GeneratePagingFromRequest(c)
 	statusFilter := c.Query("status")
 
@@ -78,18 +75,20 @@ func (h *ScheduleHandlerV2) Create(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	var input struct {
-		Name    string `json:"name" binding:"required"`
-		RoomID  string `json:"roomId" binding:"required"`
-		APIKey  string `json:"apiKey"`
-		BotID   *uint  `json:"botId"`
-		Cron    string `json:"cron" binding:"required"`
-		Message string `json:"message"`
-		Status  string `json:"status"`
+		Name      string        `json:"name" binding:"required"`
+		RoomID    string        `json:"roomId"`
+		APIKey    secrets.Input `json:"apiKey"`
+		BotID     *uint         `json:"botId"`
+		ChannelID *uint         `json:"channelId"`
+		Cron      string        `json:"cron" binding:"required"`
+		Message   string        `json:"message"`
+		Status    string        `json:"status"`
+
+		ActionType      string  `json:"actionType"`
+		TaskAssigneeIDs []int64 `json:"taskAssigneeIds"`
+		TaskDeadline    string  `json:"taskDeadline"`
+		TaskFollowUp    string  `json:"taskFollowUp"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -97,12 +96,23 @@ func (h *ScheduleHandlerV2) Create(c *gin.Context) {
 		return
 	}
 
+	if input.ChannelID != nil {
+		// A notification channel replaces the Chatwork room and credentials
+		if _, err := h.notifications.GetByID(*input.ChannelID, uint(projectID)); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrResourceNotFound, "notification channel not found"))
+			return
+		}
+	} else if input.RoomID == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "roomId or channelId is required"))
+		return
+	}
+
 	// Either apiKey or botId must be provided, but not both
-	if input.BotID == nil && input.APIKey == "" {
+	if input.ChannelID == nil && input.BotID == nil && !input.APIKey.IsSet() {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "either apiKey or botId is required"))
 		return
 	}
-	if input.BotID != nil && input.APIKey != "" {
+	if input.BotID != nil && input.APIKey.IsSet() {
 		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "apiKey and botId are mutually exclusive"))
 		return
 	}
@@ -115,9 +125,9 @@ func (h *ScheduleHandlerV2) Create(c *gin.Context) {
 
 	active := input.Status != "paused"
 
-	var chatworkToken *string
-	if input.APIKey != "" {
-		chatworkToken = &input.APIKey
+	actionType := input.ActionType
+	if actionType == "" {
+		actionType = models.ScheduleActionMessage
 	}
 
 	schedule := models.ReminderSchedule{
@@ -125,10 +135,21 @@ func (h *ScheduleHandlerV2) Create(c *gin.Context) {
 		Name:           input.Name,
 		CronExpression: input.Cron,
 		ChatworkRoomID: input.RoomID,
-		ChatworkToken:  chatworkToken,
+		ChatworkToken:  input.APIKey.Ptr(),
 		BotID:          input.BotID,
+		ChannelID:      input.ChannelID,
 		Message:        input.Message,
 		Active:         active,
+
+		ActionType:      actionType,
+		TaskAssigneeIDs: input.TaskAssigneeIDs,
+		TaskDeadline:    input.TaskDeadline,
+		TaskFollowUp:    input.TaskFollowUp,
+	}
+
+	if err := services.ValidateTaskAction(&schedule); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err)
+		return
 	}
 
 	if err := h.service.Create(&schedule); err != nil {
@@ -140,7 +161,7 @@ func (h *ScheduleHandlerV2) Create(c *gin.Context) {
 		h.cronService.SyncAll()
 	}
 
-	utils.RespondWithOK(c, http.StatusCreated, buildScheduleResponse(&schedule))
+	utils.RespondWithOK(c, http.StatusCreated, h.withRoomWarnings(&schedule))
 }
 
 // GetByID gets a single schedule.
@@ -155,10 +176,6 @@ func (h *ScheduleHandlerV2) GetByID(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	schedule, err := h.service.GetByID(uint(scheduleID))
 	if err != nil || schedule == nil {
 		utils.RespondWithError(c, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, "Schedule not found"))
@@ -185,10 +202,6 @@ func (h *ScheduleHandlerV2) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	schedule, err := h.service.GetByID(uint(scheduleID))
 	if err != nil || schedule == nil || schedule.ProjectID != uint(projectID) {
 		utils.RespondWithError(c, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, "Schedule not found"))
@@ -196,13 +209,19 @@ func (h *ScheduleHandlerV2) Update(c *gin.Context) {
 	}
 
 	var input struct {
-		Name    *string `json:"name"`
-		RoomID  *string `json:"roomId"`
-		APIKey  *string `json:"apiKey"`
-		BotID   **uint  `json:"botId"`
-		Cron    *string `json:"cron"`
-		Message *string `json:"message"`
-		Status  *string `json:"status"`
+		Name      *string       `json:"name"`
+		RoomID    *string       `json:"roomId"`
+		APIKey    secrets.Input `json:"apiKey"`
+		BotID     **uint        `json:"botId"`
+		ChannelID **uint        `json:"channelId"`
+		Cron      *string       `json:"cron"`
+		Message   *string       `json:"message"`
+		Status    *string       `json:"status"`
+
+		ActionType      *string  `json:"actionType"`
+		TaskAssigneeIDs *[]int64 `json:"taskAssigneeIds"`
+		TaskDeadline    *string  `json:"taskDeadline"`
+		TaskFollowUp    *string  `json:"taskFollowUp"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -216,8 +235,8 @@ func (h *ScheduleHandlerV2) Update(c *gin.Context) {
 	if input.RoomID != nil {
 		schedule.ChatworkRoomID = *input.RoomID
 	}
-	if input.APIKey != nil {
-		schedule.ChatworkToken = input.APIKey
+	if input.APIKey.IsSet() {
+		schedule.ChatworkToken = input.APIKey.Ptr()
 	}
 	if input.Cron != nil {
 		schedule.CronExpression = *input.Cron
@@ -241,10 +260,35 @@ func (h *ScheduleHandlerV2) Update(c *gin.Context) {
 			schedule.ChatworkToken = nil
 		}
 	}
+	if input.ChannelID != nil {
+		if *input.ChannelID != nil {
+			if _, err := h.notifications.GetByID(**input.ChannelID, schedule.ProjectID); err != nil {
+				utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrResourceNotFound, "notification channel not found"))
+				return
+			}
+		}
+		schedule.ChannelID = *input.ChannelID
+	}
 	// Switching from bot back to apiKey: clear BotID
-	if input.APIKey != nil && schedule.BotID != nil {
+	if input.APIKey.IsSet() && schedule.BotID != nil {
 		schedule.BotID = nil
 	}
+	if input.ActionType != nil {
+		schedule.ActionType = *input.ActionType
+	}
+	if input.TaskAssigneeIDs != nil {
+		schedule.TaskAssigneeIDs = *input.TaskAssigneeIDs
+	}
+	if input.TaskDeadline != nil {
+		schedule.TaskDeadline = *input.TaskDeadline
+	}
+	if input.TaskFollowUp != nil {
+		schedule.TaskFollowUp = *input.TaskFollowUp
+	}
+	if err := services.ValidateTaskAction(schedule); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err)
+		return
+	}
 
 	if err := h.service.Update(schedule); err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseUpdate, err.Error()))
@@ -253,7 +297,25 @@ func (h *ScheduleHandlerV2) Update(c *gin.Context) {
 
 	h.cronService.SyncAll()
 
-	utils.RespondWithOK(c, http.StatusOK, buildScheduleResponse(schedule))
+	utils.RespondWithOK(c, http.StatusOK, h.withRoomWarnings(schedule))
+}
+
+// withRoomWarnings builds the schedule response and adds any "warnings" from
+// the bot's cached room directory. Warnings never block the save: the cache
+// may lag behind Chatwork by one sync interval.
+func (h *ScheduleHandlerV2) withRoomWarnings(schedule *models.ReminderSchedule) gin.H {
+	resp := buildScheduleResponse(schedule)
+	if schedule.BotID == nil || schedule.ChannelID != nil {
+		return resp
+	}
+	var assignees []int64
+	if schedule.IsTaskAction() {
+		assignees = schedule.TaskAssigneeIDs
+	}
+	if warnings := h.botService.RoomWarnings(*schedule.BotID, schedule.ChatworkRoomID, assignees); len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	return resp
 }
 
 // Toggle toggles schedule status between active and paused.
@@ -268,10 +330,6 @@ func (h *ScheduleHandlerV2) Toggle(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	schedule, err := h.service.GetByID(uint(scheduleID))
 	if err != nil || schedule == nil || schedule.ProjectID != uint(projectID) {
 		utils.RespondWithError(c, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, "Schedule not found"))
@@ -309,10 +367,6 @@ func (h *ScheduleHandlerV2) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	schedule, err := h.service.GetByID(uint(scheduleID))
 	if err != nil || schedule == nil || schedule.ProjectID != uint(projectID) {
 		utils.RespondWithError(c, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, "Schedule not found"))
@@ -336,16 +390,12 @@ func (h *ScheduleHandlerV2) Test(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	var input struct {
-		RoomID     string `json:"roomId" binding:"required"`
-		APIKey     string `json:"apiKey"`
-		BotID      *uint  `json:"botId"`
-		Message    string `json:"message" binding:"required"`
-		ScheduleID *uint  `json:"scheduleId"`
+		RoomID     string        `json:"roomId" binding:"required"`
+		APIKey     secrets.Input `json:"apiKey"`
+		BotID      *uint         `json:"botId"`
+		Message    string        `json:"message" binding:"required"`
+		ScheduleID *uint         `json:"scheduleId"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -353,7 +403,7 @@ func (h *ScheduleHandlerV2) Test(c *gin.Context) {
 		return
 	}
 
-	apiKey := input.APIKey
+	apiKey := input.APIKey.Value()
 
 	// Resolve token from bot if botId is provided directly
 	if input.BotID != nil {
@@ -365,10 +415,10 @@ func (h *ScheduleHandlerV2) Test(c *gin.Context) {
 		apiKey = bot.APIToken
 	}
 
-	// If the frontend sends the masked API key, look it up from the schedule
-	if apiKey == "cwk_***hidden***" || (apiKey == "" && input.BotID == nil) {
+	// Without a new key (or with the schedule's apiKey reference echoed back), use the stored one
+	if apiKey == "" && input.BotID == nil {
 		if input.ScheduleID == nil {
-			utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "scheduleId is required when apiKey is masked or empty"))
+			utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "scheduleId is required when apiKey is not provided"))
 			return
 		}
 
@@ -410,35 +460,8 @@ func (h *ScheduleHandlerV2) Test(c *gin.Context) {
 
 // ---- helpers ----
 
-// checkProjectAccess validates project-scoped access using either JWT (admin) or X-Project-Key header.
-func (h *ScheduleHandlerV2) checkProjectAccess(c *gin.Context, projectID uint) error {
-	// If JWT-authenticated (admin), skip secret key check
-	if authMode, _ := c.Get("authMode"); authMode == "jwt" {
-		return nil
-	}
-
-	// Otherwise validate X-Project-Key
-	projectKey, _ := c.Get("projectKey")
-	keyStr, ok := projectKey.(string)
-	if !ok || keyStr == "" {
-		utils.RespondWithError(c, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Project key required"))
-		return errors.New(errors.ErrAuthUnauthorized, "missing project key")
-	}
-
-	valid, err := h.projectService.ValidateSecretKey(projectID, keyStr)
-	if err != nil {
-		utils.RespondWithError(c, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, "Project not found"))
-		return err
-	}
-	if !valid {
-		utils.RespondWithError(c, http.StatusForbidden, errors.New(errors.ErrAuthForbidden, "Invalid project secret key"))
-		return errors.New(errors.ErrAuthForbidden, "invalid project key")
-	}
-	return nil
-}
-
 // buildScheduleResponse converts a ReminderSchedule to V2 response format.
-// apiKey is always masked as "cwk_***hidden***".
+// apiKey is a secrets.Ref; the token itself is never returned.
 func buildScheduleResponse(s *models.ReminderSchedule) gin.H {
 	status := "active"
 	if !s.Active {
@@ -457,20 +480,31 @@ func buildScheduleResponse(s *models.ReminderSchedule) gin.H {
 	}
 	_ = lastStatus // populated by run logs in real impl
 
+	actionType := s.ActionType
+	if actionType == "" {
+		actionType = models.ScheduleActionMessage
+	}
+
 	return gin.H{
 		"id":          s.ID,
 		"projectId":   s.ProjectID,
 		"name":        s.Name,
 		"projectName": projectName,
 		"roomId":      s.ChatworkRoomID,
-		"apiKey":      "cwk_***hidden***",
+		"apiKey":      secrets.RefOfPtr(s.ChatworkToken),
 		"botId":       s.BotID,
+		"channelId":   s.ChannelID,
 		"cron":        s.CronExpression,
 		"message":     s.Message,
+		"actionType":  actionType,
 		"status":      status,
 		"lastRun":     lastRun,
 		"lastStatus":  lastStatus,
 		"createdAt":   s.CreatedAt.Format("2006-01-02"),
+
+		"taskAssigneeIds": s.TaskAssigneeIDs,
+		"taskDeadline":    s.TaskDeadline,
+		"taskFollowUp":    s.TaskFollowUp,
 	}
 }
 
@@ -480,10 +514,6 @@ func (h *ScheduleHandlerV2) GetAnalysis(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkProjectAccess(c, uint(projectID)); err != nil {
-		return
-	}
-
 	analysis, err := h.service.GetAnalysisByProject(uint(projectID))
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, errors.New(errors.ErrDatabaseQuery, err.Error()))
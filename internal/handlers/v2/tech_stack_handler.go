@@ -0,0 +1,175 @@
+package v2
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// TechStackHandler manages a project's tech stack inventory and its CPE findings.
+type TechStackHandler struct {
+	service services.ITechStackService
+}
+
+func NewTechStackHandler(service services.ITechStackService) *TechStackHandler {
+	return &TechStackHandler{
+		service: service,
+	}
+}
+
+// GET /api/v2/projects/:projectId/tech-stack
+func (h *TechStackHandler) GetByProject(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	items, err := h.service.GetByProjectID(uint(projectID))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(items))
+	for i := range items {
+		data = append(data, buildTechStackResponse(&items[i]))
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": len(data),
+	})
+}
+
+// POST /api/v2/projects/:projectId/tech-stack
+func (h *TechStackHandler) Create(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	var input struct {
+		Name    string `json:"name"`
+		Vendor  string `json:"vendor"`
+		Product string `json:"product"`
+		Version string `json:"version"`
+		CPE     string `json:"cpe"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+	if input.Name == "" && input.Product == "" && input.CPE == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "name, product or cpe is required"))
+		return
+	}
+
+	item, err := h.service.Create(uint(projectID), services.TechStackInput{
+		Name:    input.Name,
+		Vendor:  input.Vendor,
+		Product: input.Product,
+		Version: input.Version,
+		CPE:     input.CPE,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == errors.ErrInvalidData {
+			status = http.StatusBadRequest
+		}
+		utils.RespondWithError(c, status, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusCreated, buildTechStackResponse(item))
+}
+
+// DELETE /api/v2/projects/:projectId/tech-stack/:techStackId
+func (h *TechStackHandler) Delete(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	techStackID, err := strconv.ParseUint(c.Param("techStackId"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid techStackId"))
+		return
+	}
+
+	if err := h.service.Delete(uint(techStackID), uint(projectID)); err != nil {
+		status := http.StatusInternalServerError
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == errors.ErrResourceNotFound {
+			status = http.StatusNotFound
+		}
+		utils.RespondWithError(c, status, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Tech stack deleted"})
+}
+
+// GET /api/v2/projects/:projectId/cve/cpe-findings
+func (h *TechStackHandler) GetFindings(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	paging := utils.GeneratePagingFromRequest(c)
+
+	findings, total, err := h.service.GetFindings(uint(projectID), paging)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(findings))
+	for i := range findings {
+		data = append(data, buildCpeFindingResponse(&findings[i]))
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+func buildTechStackResponse(item *models.ProjectTechStack) gin.H {
+	return gin.H{
+		"id":        item.ID,
+		"projectId": item.ProjectID,
+		"name":      item.Name,
+		"vendor":    item.Vendor,
+		"product":   item.Product,
+		"version":   item.Version,
+		"cpe":       item.CPE,
+		"createdAt": item.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func buildCpeFindingResponse(f *models.CpeFinding) gin.H {
+	resp := gin.H{
+		"id":              f.ID,
+		"projectId":       f.ProjectID,
+		"techStackId":     f.TechStackID,
+		"cveId":           f.CveID,
+		"severity":        f.Severity,
+		"score":           f.Score,
+		"product":         f.Product,
+		"version":         f.Version,
+		"matchedCriteria": f.MatchedCriteria,
+		"summary":         f.Summary,
+		"createdAt":       f.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if f.NotifiedAt != nil {
+		resp["notifiedAt"] = f.NotifiedAt.Format("2006-01-02T15:04:05Z")
+	}
+	return resp
+}
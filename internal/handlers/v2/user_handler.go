@@ -0,0 +1,348 @@
+package v2
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// UserHandler lets admins manage users, their sessions and invitations, and
+// project owners manage their project's members.
+type UserHandler struct {
+	service        services.IUserService
+	sessionService services.ISessionService
+}
+
+func NewUserHandler(service services.IUserService, sessionService services.ISessionService) *UserHandler {
+	return &UserHandler{
+		service:        service,
+		sessionService: sessionService,
+	}
+}
+
+type userRequest struct {
+	Email    *string `json:"email"`
+	Name     *string `json:"name"`
+	Password *string `json:"password"`
+	IsAdmin  *bool   `json:"isAdmin"`
+	Status   *string `json:"status"`
+}
+
+func (r *userRequest) input() *services.UserInput {
+	return &services.UserInput{
+		Email:    r.Email,
+		Name:     r.Name,
+		Password: r.Password,
+		IsAdmin:  r.IsAdmin,
+		Status:   r.Status,
+	}
+}
+
+// GET /api/v2/users?page=1&limit=20
+func (h *UserHandler) GetAll(c *gin.Context) {
+	paging := utils.GeneratePagingFromRequest(c)
+	users, total, err := h.service.GetUsers(paging)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(users))
+	for i := range users {
+		data = append(data, buildUserResponse(&users[i]))
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// GET /api/v2/users/:userId
+func (h *UserHandler) GetByID(c *gin.Context) {
+	userID, err := parseIDParam(c, "userId")
+	if err != nil {
+		return
+	}
+	user, err := h.service.GetUser(uint(userID))
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, buildUserResponse(user))
+}
+
+// POST /api/v2/users
+func (h *UserHandler) Create(c *gin.Context) {
+	var req userRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+	user, err := h.service.CreateUser(req.input())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusCreated, buildUserResponse(user))
+}
+
+// PATCH /api/v2/users/:userId
+func (h *UserHandler) Update(c *gin.Context) {
+	userID, err := parseIDParam(c, "userId")
+	if err != nil {
+		return
+	}
+	var req userRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+	user, err := h.service.UpdateUser(uint(userID), req.input())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, buildUserResponse(user))
+}
+
+// DELETE /api/v2/users/:userId
+func (h *UserHandler) Delete(c *gin.Context) {
+	userID, err := parseIDParam(c, "userId")
+	if err != nil {
+		return
+	}
+	if err := h.service.DeleteUser(uint(userID)); err != nil {
+		respondAppError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v2/sessions?userId=&page=1&limit=20
+// Active sessions of everyone, or of one user.
+func (h *UserHandler) GetSessions(c *gin.Context) {
+	var userID uint
+	if raw := c.Query("userId"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid userId"))
+			return
+		}
+		userID = uint(id)
+	}
+
+	paging := utils.GeneratePagingFromRequest(c)
+	sessions, total, err := h.sessionService.GetActiveSessions(userID, paging)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(sessions))
+	for i := range sessions {
+		data = append(data, buildSessionResponse(&sessions[i]))
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// DELETE /api/v2/sessions/:sessionId
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := parseIDParam(c, "sessionId")
+	if err != nil {
+		return
+	}
+	if err := h.sessionService.Revoke(uint(sessionID), 0, models.SessionRevokedByAdmin); err != nil {
+		respondAppError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DELETE /api/v2/users/:userId/sessions
+// Signs the user out everywhere.
+func (h *UserHandler) RevokeUserSessions(c *gin.Context) {
+	userID, err := parseIDParam(c, "userId")
+	if err != nil {
+		return
+	}
+	revoked, err := h.sessionService.RevokeAll(uint(userID), models.SessionRevokedByAdmin)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// GET /api/v2/invitations?pending=true
+func (h *UserHandler) GetInvitations(c *gin.Context) {
+	invitations, err := h.service.GetInvitations(c.Query("pending") == "true")
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  invitations,
+		"total": len(invitations),
+	})
+}
+
+// POST /api/v2/invitations
+// The response carries the invitation token, which is not shown again.
+func (h *UserHandler) CreateInvitation(c *gin.Context) {
+	var input struct {
+		Email    string               `json:"email" binding:"required"`
+		Name     string               `json:"name"`
+		IsAdmin  bool                 `json:"isAdmin"`
+		Projects []models.InvitedRole `json:"projects"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "email is required"))
+		return
+	}
+
+	invitation, token, err := h.service.CreateInvitation(&services.InvitationInput{
+		Email:    input.Email,
+		Name:     input.Name,
+		IsAdmin:  input.IsAdmin,
+		Projects: input.Projects,
+	}, c.GetUint("UserID"))
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusCreated, gin.H{
+		"invitation": invitation,
+		"token":      token,
+	})
+}
+
+// DELETE /api/v2/invitations/:invitationId
+func (h *UserHandler) RevokeInvitation(c *gin.Context) {
+	invitationID, err := parseIDParam(c, "invitationId")
+	if err != nil {
+		return
+	}
+	if err := h.service.RevokeInvitation(uint(invitationID)); err != nil {
+		respondAppError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v2/projects/:projectId/members
+func (h *UserHandler) GetMembers(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+	members, err := h.service.GetMembers(uint(projectID))
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(members))
+	for _, m := range members {
+		member := gin.H{
+			"userId": m.UserID,
+			"role":   m.Role,
+		}
+		if m.Source != "" {
+			member["source"] = m.Source
+		}
+		if m.User != nil {
+			member["email"] = m.User.Email
+			member["name"] = m.User.Name
+		}
+		data = append(data, member)
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": len(data),
+	})
+}
+
+// PUT /api/v2/projects/:projectId/members/:userId
+// Body: { "role": "owner" | "maintainer" | "viewer" }
+func (h *UserHandler) SetMember(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+	userID, err := parseIDParam(c, "userId")
+	if err != nil {
+		return
+	}
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "role is required"))
+		return
+	}
+
+	member, err := h.service.SetMember(uint(projectID), uint(userID), input.Role)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"userId": member.UserID,
+		"role":   member.Role,
+	})
+}
+
+// DELETE /api/v2/projects/:projectId/members/:userId
+func (h *UserHandler) RemoveMember(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+	userID, err := parseIDParam(c, "userId")
+	if err != nil {
+		return
+	}
+	if err := h.service.RemoveMember(uint(projectID), uint(userID)); err != nil {
+		respondAppError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func buildUserResponse(u *models.User) gin.H {
+	projects := make([]gin.H, 0, len(u.Memberships))
+	for _, m := range u.Memberships {
+		projects = append(projects, gin.H{
+			"projectId": m.ProjectID,
+			"role":      m.Role,
+		})
+	}
+	resp := gin.H{
+		"id":        u.ID,
+		"email":     u.Email,
+		"name":      u.Name,
+		"isAdmin":   u.IsAdmin,
+		"status":    u.Status,
+		"projects":  projects,
+		"createdAt": u.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if u.LastLoginAt != nil {
+		resp["lastLoginAt"] = u.LastLoginAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if u.OIDCSubject != nil {
+		resp["singleSignOn"] = true
+	}
+	return resp
+}
@@ -0,0 +1,392 @@
+package v2
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// WebhookEndpointHandler receives third-party webhooks on per-project URLs and
+// manages those endpoints and their delivery history.
+type WebhookEndpointHandler struct {
+	service services.IWebhookEndpointService
+}
+
+func NewWebhookEndpointHandler(service services.IWebhookEndpointService) *WebhookEndpointHandler {
+	return &WebhookEndpointHandler{
+		service: service,
+	}
+}
+
+type webhookEndpointRequest struct {
+	Name             *string `json:"name"`
+	Provider         *string `json:"provider"`
+	Template         *string `json:"template"`
+	BotID            *uint   `json:"botId"`
+	RoomID           *string `json:"roomId"`
+	RequireSignature *bool   `json:"requireSignature"`
+	Active           *bool   `json:"active"`
+
+	Filters            *[]models.WebhookFilter `json:"filters"`
+	MinSeverity        *string                 `json:"minSeverity"`
+	DedupWindowMinutes *int                    `json:"dedupWindowMinutes"`
+	DedupKeys          *[]string               `json:"dedupKeys"`
+	QuietStart         *string                 `json:"quietStart"`
+	QuietEnd           *string                 `json:"quietEnd"`
+	QuietTimezone      *string                 `json:"quietTimezone"`
+
+	Alerts       *bool     `json:"alerts"`
+	AlertGroupBy *[]string `json:"alertGroupBy"`
+}
+
+func (r *webhookEndpointRequest) toInput() *services.WebhookEndpointInput {
+	return &services.WebhookEndpointInput{
+		Name:             r.Name,
+		Provider:         r.Provider,
+		Template:         r.Template,
+		BotID:            r.BotID,
+		RoomID:           r.RoomID,
+		RequireSignature: r.RequireSignature,
+		Active:           r.Active,
+
+		Filters:            r.Filters,
+		MinSeverity:        r.MinSeverity,
+		DedupWindowMinutes: r.DedupWindowMinutes,
+		DedupKeys:          r.DedupKeys,
+		QuietStart:         r.QuietStart,
+		QuietEnd:           r.QuietEnd,
+		QuietTimezone:      r.QuietTimezone,
+
+		Alerts:       r.Alerts,
+		AlertGroupBy: r.AlertGroupBy,
+	}
+}
+
+// POST /api/v2/webhooks/:token
+// Public: the token in the URL selects the endpoint; requests are verified
+// against its secret unless the endpoint allows unsigned requests.
+func (h *WebhookEndpointHandler) Receive(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	delivery, err := h.service.Receive(c.Param("token"), c.Request.Header, body)
+	if err != nil {
+		appErr, ok := err.(*errors.AppError)
+		switch {
+		case ok && appErr.Code == errors.ErrAuthUnauthorized:
+			utils.RespondWithError(c, http.StatusUnauthorized, appErr)
+		case ok:
+			respondAppError(c, err)
+		default:
+			utils.RespondWithError(c, http.StatusBadGateway, errors.New(errors.ErrServerInternal, err.Error()))
+		}
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"deliveryId": delivery.ID, "status": delivery.Status})
+}
+
+// GET /api/v2/projects/:projectId/webhook-endpoints
+func (h *WebhookEndpointHandler) GetByProject(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	endpoints, err := h.service.GetByProjectID(uint(projectID))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(endpoints))
+	for i := range endpoints {
+		data = append(data, buildWebhookEndpointResponse(c, &endpoints[i]))
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  data,
+		"total": len(data),
+	})
+}
+
+// POST /api/v2/projects/:projectId/webhook-endpoints
+func (h *WebhookEndpointHandler) Create(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+
+	var input webhookEndpointRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	endpoint, err := h.service.Create(uint(projectID), input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	// The signing secret is only returned on creation and rotation
+	resp := buildWebhookEndpointResponse(c, endpoint)
+	resp["secret"] = endpoint.Secret
+	utils.RespondWithOK(c, http.StatusCreated, resp)
+}
+
+// GET /api/v2/projects/:projectId/webhook-endpoints/:endpointId
+func (h *WebhookEndpointHandler) GetByID(c *gin.Context) {
+	projectID, endpointID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.service.GetByID(endpointID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, buildWebhookEndpointResponse(c, endpoint))
+}
+
+// PUT /api/v2/projects/:projectId/webhook-endpoints/:endpointId
+func (h *WebhookEndpointHandler) Update(c *gin.Context) {
+	projectID, endpointID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	var input webhookEndpointRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	endpoint, err := h.service.Update(endpointID, projectID, input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, buildWebhookEndpointResponse(c, endpoint))
+}
+
+// DELETE /api/v2/projects/:projectId/webhook-endpoints/:endpointId
+func (h *WebhookEndpointHandler) Delete(c *gin.Context) {
+	projectID, endpointID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(endpointID, projectID); err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{"message": "Webhook endpoint deleted"})
+}
+
+// POST /api/v2/projects/:projectId/webhook-endpoints/:endpointId/rotate-secret
+func (h *WebhookEndpointHandler) RotateSecret(c *gin.Context) {
+	projectID, endpointID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.service.RotateSecret(endpointID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	resp := buildWebhookEndpointResponse(c, endpoint)
+	resp["secret"] = endpoint.Secret
+	utils.RespondWithOK(c, http.StatusOK, resp)
+}
+
+// GET /api/v2/projects/:projectId/webhook-endpoints/:endpointId/deliveries
+func (h *WebhookEndpointHandler) GetDeliveries(c *gin.Context) {
+	projectID, endpointID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	paging := utils.GeneratePagingFromRequest(c)
+	deliveries, total, err := h.service.GetDeliveries(endpointID, projectID, paging)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, gin.H{
+		"data":  deliveries,
+		"total": total,
+		"page":  paging.Page,
+		"limit": paging.Limit,
+	})
+}
+
+// GET /api/v2/projects/:projectId/webhook-endpoints/:endpointId/deliveries/:deliveryId
+func (h *WebhookEndpointHandler) GetDelivery(c *gin.Context) {
+	projectID, endpointID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid deliveryId"))
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(uint(deliveryID), endpointID, projectID)
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, delivery)
+}
+
+type webhookDryRunRequest struct {
+	Provider    *string                 `json:"provider"`
+	Template    *string                 `json:"template"`
+	Filters     *[]models.WebhookFilter `json:"filters"`
+	MinSeverity *string                 `json:"minSeverity"`
+	Headers     map[string]string       `json:"headers"`
+	Payload     json.RawMessage         `json:"payload"`
+}
+
+func (r *webhookDryRunRequest) toInput() *services.WebhookDryRunInput {
+	return &services.WebhookDryRunInput{
+		Provider:    r.Provider,
+		Template:    r.Template,
+		Filters:     r.Filters,
+		MinSeverity: r.MinSeverity,
+		Headers:     r.Headers,
+		Payload:     r.Payload,
+	}
+}
+
+// POST /api/v2/projects/:projectId/webhook-endpoints/dry-run
+// Renders a sample payload with the given provider or template, e.g. before
+// creating an endpoint.
+func (h *WebhookEndpointHandler) DryRun(c *gin.Context) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return
+	}
+	h.dryRun(c, uint(projectID), nil)
+}
+
+// POST /api/v2/projects/:projectId/webhook-endpoints/:endpointId/dry-run
+// Renders a sample payload with the endpoint's settings; provider and
+// template in the body override them.
+func (h *WebhookEndpointHandler) DryRunEndpoint(c *gin.Context) {
+	projectID, endpointID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+	h.dryRun(c, projectID, &endpointID)
+}
+
+func (h *WebhookEndpointHandler) dryRun(c *gin.Context, projectID uint, endpointID *uint) {
+	var input webhookDryRunRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, err.Error()))
+		return
+	}
+
+	result, err := h.service.DryRun(projectID, endpointID, input.toInput())
+	if err != nil {
+		respondAppError(c, err)
+		return
+	}
+
+	utils.RespondWithOK(c, http.StatusOK, result)
+}
+
+func (h *WebhookEndpointHandler) parseParams(c *gin.Context) (uint, uint, bool) {
+	projectID, err := parseIDParam(c, "projectId")
+	if err != nil {
+		return 0, 0, false
+	}
+
+	endpointID, err := strconv.ParseUint(c.Param("endpointId"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, errors.New(errors.ErrInvalidData, "Invalid endpointId"))
+		return 0, 0, false
+	}
+	return uint(projectID), uint(endpointID), true
+}
+
+// buildWebhookEndpointResponse includes the full URL to configure in the
+// sender; the secret is added by the caller only when it was just generated.
+func buildWebhookEndpointResponse(c *gin.Context, ep *models.WebhookEndpoint) gin.H {
+	provider := ep.Provider
+	if provider == "" {
+		provider = "auto"
+	}
+	filters, dedupKeys, alertGroupBy := ep.Filters, ep.DedupKeys, ep.AlertGroupBy
+	if filters == nil {
+		filters = []models.WebhookFilter{}
+	}
+	if dedupKeys == nil {
+		dedupKeys = []string{}
+	}
+	if alertGroupBy == nil {
+		alertGroupBy = []string{}
+	}
+	return gin.H{
+		"id":                 ep.ID,
+		"projectId":          ep.ProjectID,
+		"name":               ep.Name,
+		"provider":           provider,
+		"template":           ep.Template,
+		"url":                webhookEndpointURL(c, ep.Token),
+		"botId":              ep.BotID,
+		"roomId":             ep.RoomID,
+		"requireSignature":   ep.RequireSignature,
+		"active":             ep.Active,
+		"filters":            filters,
+		"minSeverity":        ep.MinSeverity,
+		"dedupWindowMinutes": ep.DedupWindowMinutes,
+		"dedupKeys":          dedupKeys,
+		"quietStart":         ep.QuietStart,
+		"quietEnd":           ep.QuietEnd,
+		"quietTimezone":      ep.QuietTimezone,
+		"alerts":             ep.Alerts,
+		"alertGroupBy":       alertGroupBy,
+		"createdAt":          ep.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// webhookEndpointURL prefers PUBLIC_API_URL and falls back to the host the
+// request came in on.
+func webhookEndpointURL(c *gin.Context, token string) string {
+	base := strings.TrimRight(configs.GetPublicAPIURL(), "/")
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	return base + "/api/v2/webhooks/" + token
+}
@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/inbound"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+)
+
+// HookSecretMiddleware only lets through requests that carry secret, either as
+// the X-API-Key header or as any signature inbound.VerifySignature accepts.
+// A signed request already seen by replays is refused. The body is left for
+// the handler to bind.
+func HookSecretMiddleware(secret string, replays *inbound.ReplayGuard) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if key := ctx.GetHeader("X-API-Key"); key != "" {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(secret)) != 1 {
+				utils.RespondWithError(ctx, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Invalid API key"))
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		var body []byte
+		if ctx.Request.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(ctx.Request.Body, 1<<20))
+			ctx.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+		if _, ok := inbound.VerifySignature(ctx.Request.Header, body, secret, time.Now()); !ok {
+			utils.RespondWithError(ctx, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "A valid X-API-Key or signature is required"))
+			return
+		}
+		if replays != nil {
+			if _, seen, err := replays.Seen("hooks", ctx.Request.Header, time.Now()); err != nil {
+				logger.Errorf("[Hooks] failed to check for a replay: %v", err)
+			} else if seen {
+				utils.RespondWithError(ctx, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Request was already received"))
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// UnsecuredHookMiddleware serves the hooks without a secret while HOOKS_SECRET
+// is unset, for one release. Each request logs a warning and is answered with
+// Deprecation and Warning headers so callers notice before the routes go away.
+func UnsecuredHookMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger.Warnf("[Hooks] unauthenticated %s from %s; set HOOKS_SECRET, these routes will require it in the next release", ctx.Request.URL.Path, ctx.ClientIP())
+		ctx.Header("Deprecation", "true")
+		ctx.Header("Warning", `299 - "Unauthenticated hooks are deprecated; send HOOKS_SECRET as X-API-Key or sign the body"`)
+		ctx.Next()
+	}
+}
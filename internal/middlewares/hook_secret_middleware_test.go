@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/pkg/inbound"
+	"github.com/vfa-khuongdv/golang-cms/pkg/ratelimit"
+)
+
+func TestHookSecretMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/hooks/slack", HookSecretMiddleware("s3cret", inbound.NewReplayGuard(ratelimit.NewMemoryStore())), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+
+	body := `{"text":"deployed"}`
+	m := hmac.New(sha256.New, []byte("s3cret"))
+	m.Write([]byte(body))
+	signature := "sha256=" + hex.EncodeToString(m.Sum(nil))
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		status int
+	}{
+		{"no secret", nil, http.StatusUnauthorized},
+		{"api key", map[string]string{"X-API-Key": "s3cret"}, http.StatusOK},
+		{"wrong api key", map[string]string{"X-API-Key": "guess"}, http.StatusUnauthorized},
+		{"signature", map[string]string{"X-Hub-Signature-256": signature}, http.StatusOK},
+		{"replayed signature", map[string]string{"X-Hub-Signature-256": signature}, http.StatusUnauthorized},
+		{"bad signature", map[string]string{"X-Hub-Signature-256": "sha256=00"}, http.StatusUnauthorized},
+		{"bearer", map[string]string{"Authorization": "Bearer s3cret"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/hooks/slack", strings.NewReader(body))
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Fatalf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.status, w.Body.String())
+		}
+		if w.Code == http.StatusOK && w.Body.String() != body {
+			t.Fatalf("%s: handler got body %q", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestUnsecuredHookMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/hooks/slack", UnsecuredHookMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/hooks/slack", strings.NewReader(`{}`)))
+	if w.Code != http.StatusOK || w.Header().Get("Deprecation") != "true" || w.Header().Get("Warning") == "" {
+		t.Fatalf("expected the request through with deprecation headers, got %d %v", w.Code, w.Header())
+	}
+}
@@ -2,17 +2,36 @@ package middlewares
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
 	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
 )
 
+// TokenAuthenticator loads the user an access token was issued to, unless
+// its session was revoked or the user disabled since.
+type TokenAuthenticator interface {
+	Authenticate(userID, sessionID uint) (*models.User, error)
+}
+
+// ProjectRoleResolver looks up a user's role in a project.
+type ProjectRoleResolver interface {
+	ProjectRole(user *models.User, projectID uint) (string, error)
+}
+
+// ProjectKeyVerifier returns the project's active API key with the secret.
+type ProjectKeyVerifier interface {
+	VerifyKey(projectID uint, secret, ip string) (*models.ProjectAPIKey, error)
+}
+
 // JWTAuthMiddleware validates JWT Bearer token for V2 endpoints.
-// Returns 401 if Authorization header is missing, malformed, or token is invalid.
-func JWTAuthMiddleware() gin.HandlerFunc {
+// Returns 401 if Authorization header is missing, malformed, or token is invalid,
+// or if its session was revoked or its user deleted or disabled since.
+func JWTAuthMiddleware(auth TokenAuthenticator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
@@ -29,18 +48,29 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		ctx.Set("UserID", claims.ID)
+		user, err := auth.Authenticate(claims.ID, claims.SessionID)
+		if err != nil {
+			utils.RespondWithError(ctx, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Invalid or expired token"))
+			ctx.Abort()
+			return
+		}
+
+		setUser(ctx, user, claims.SessionID)
 		ctx.Next()
 	}
 }
 
 // ProjectScopeMiddleware allows a request if it carries either:
-//   - A valid JWT Bearer token, OR
-//   - A valid X-Project-Key header matching the project's secretKey
+//   - A valid JWT Bearer token of an active session, OR
+//   - A valid X-Project-Key header with an active API key of the :projectId
+//     project
 //
 // This enables project-scoped access without full admin privileges.
-// The resolved projectKey (if used) is stored in context as "projectKey".
-func ProjectScopeMiddleware() gin.HandlerFunc {
+// The verified key is stored in context as "projectKey". Users' project
+// roles and keys' scopes are checked per route by RequireProjectAccess.
+// Key checks go through attempts, when set, so wrong keys are throttled
+// and lock the client IP out of the project.
+func ProjectScopeMiddleware(auth TokenAuthenticator, keys ProjectKeyVerifier, attempts *KeyAttemptLimiter) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Try JWT Bearer first
 		authHeader := ctx.GetHeader("Authorization")
@@ -48,23 +78,159 @@ func ProjectScopeMiddleware() gin.HandlerFunc {
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 			claims, err := configs.ValidateToken(tokenString)
 			if err == nil {
-				ctx.Set("UserID", claims.ID)
-				ctx.Set("authMode", "jwt")
-				ctx.Next()
-				return
+				if user, err := auth.Authenticate(claims.ID, claims.SessionID); err == nil {
+					setUser(ctx, user, claims.SessionID)
+					ctx.Next()
+					return
+				}
 			}
 		}
 
 		// Fall back to project key header
-		projectKey := ctx.GetHeader("X-Project-Key")
-		if projectKey != "" {
-			ctx.Set("projectKey", projectKey)
-			ctx.Set("authMode", "projectKey")
+		secret := ctx.GetHeader("X-Project-Key")
+		if secret == "" {
+			utils.RespondWithError(ctx, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Authorization required: provide Bearer token or X-Project-Key header"))
+			ctx.Abort()
+			return
+		}
+
+		projectID, err := strconv.ParseUint(ctx.Param("projectId"), 10, 64)
+		if err != nil {
+			utils.RespondWithError(ctx, http.StatusBadRequest, errors.New(errors.ErrInvalidParse, "Invalid ID parameter"))
+			ctx.Abort()
+			return
+		}
+
+		credential := ctx.Param("projectId")
+		if attempts != nil && !attempts.Allow(ctx, credential) {
+			return
+		}
+
+		key, err := keys.VerifyKey(uint(projectID), secret, ctx.ClientIP())
+		if err != nil {
+			status := http.StatusInternalServerError
+			if appErr, ok := err.(*errors.AppError); ok && appErr.Code == errors.ErrAuthUnauthorized {
+				status = http.StatusUnauthorized
+				if attempts != nil {
+					attempts.Failed(ctx, credential)
+				}
+			}
+			utils.RespondWithError(ctx, status, err)
+			ctx.Abort()
+			return
+		}
+		if attempts != nil {
+			attempts.Succeeded(ctx, credential)
+		}
+
+		if key.MustRotate {
+			ctx.Header("Warning", `299 - "This project key was migrated from the old project secret; rotate it"`)
+		}
+		ctx.Set("projectKey", key)
+		ctx.Set("authMode", "projectKey")
+		ctx.Next()
+	}
+}
+
+// RequireAdmin lets only admins through. It runs after JWTAuthMiddleware.
+func RequireAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user := CurrentUser(ctx)
+		if user == nil || !user.IsAdmin {
+			utils.RespondWithError(ctx, http.StatusForbidden, errors.New(errors.ErrAuthForbidden, "Admin access required"))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireProjectRole lets a user through when their role in the :projectId
+// project is at least min; admins always pass. Project keys are refused.
+func RequireProjectRole(users ProjectRoleResolver, min string) gin.HandlerFunc {
+	return RequireProjectAccess(users, min, "")
+}
+
+// RequireProjectAccess is RequireProjectRole that also lets through project
+// keys with scope. Keys are refused when scope is empty.
+func RequireProjectAccess(users ProjectRoleResolver, min, scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if key := CurrentProjectKey(ctx); key != nil {
+			if scope == "" {
+				utils.RespondWithError(ctx, http.StatusForbidden, errors.New(errors.ErrAuthForbidden, "Project keys can't use this route"))
+				ctx.Abort()
+				return
+			}
+			if !key.HasScope(scope) {
+				utils.RespondWithError(ctx, http.StatusForbidden, errors.New(errors.ErrAuthForbidden, "The project key needs the "+scope+" scope"))
+				ctx.Abort()
+				return
+			}
 			ctx.Next()
 			return
 		}
 
-		utils.RespondWithError(ctx, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Authorization required: provide Bearer token or X-Project-Key header"))
-		ctx.Abort()
+		user := CurrentUser(ctx)
+		if user == nil {
+			utils.RespondWithError(ctx, http.StatusUnauthorized, errors.New(errors.ErrAuthUnauthorized, "Authorization required"))
+			ctx.Abort()
+			return
+		}
+
+		projectID, err := strconv.ParseUint(ctx.Param("projectId"), 10, 64)
+		if err != nil {
+			utils.RespondWithError(ctx, http.StatusBadRequest, errors.New(errors.ErrInvalidParse, "Invalid ID parameter"))
+			ctx.Abort()
+			return
+		}
+
+		role, err := users.ProjectRole(user, uint(projectID))
+		if err != nil {
+			utils.RespondWithError(ctx, http.StatusInternalServerError, err)
+			ctx.Abort()
+			return
+		}
+		if role == "" {
+			utils.RespondWithError(ctx, http.StatusNotFound, errors.New(errors.ErrResourceNotFound, "Project not found"))
+			ctx.Abort()
+			return
+		}
+		if !models.RoleAtLeast(role, min) {
+			utils.RespondWithError(ctx, http.StatusForbidden, errors.New(errors.ErrAuthForbidden, "This requires the "+min+" role in the project"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set("projectRole", role)
+		ctx.Next()
 	}
 }
+
+// CurrentUser is the user authenticated by JWTAuthMiddleware or
+// ProjectScopeMiddleware, or nil for project key requests.
+func CurrentUser(ctx *gin.Context) *models.User {
+	if v, ok := ctx.Get("user"); ok {
+		if user, ok := v.(*models.User); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+// CurrentProjectKey is the API key verified by ProjectScopeMiddleware, or nil
+// for user requests.
+func CurrentProjectKey(ctx *gin.Context) *models.ProjectAPIKey {
+	if v, ok := ctx.Get("projectKey"); ok {
+		if key, ok := v.(*models.ProjectAPIKey); ok {
+			return key
+		}
+	}
+	return nil
+}
+
+func setUser(ctx *gin.Context, user *models.User, sessionID uint) {
+	ctx.Set("UserID", user.ID)
+	ctx.Set("SessionID", sessionID)
+	ctx.Set("user", user)
+	ctx.Set("authMode", "jwt")
+}
@@ -22,8 +22,11 @@ func LogMiddleware() gin.HandlerFunc {
 			"password",
 			"api-key",
 			"token",
+			"refreshToken",
 			"email",
 			"secret_key",
+			"secretKey",
+			"secret",
 		}
 
 		timeStart := time.Now()
@@ -0,0 +1,224 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/ratelimit"
+)
+
+// CredentialFunc returns the credential a request tries, e.g. the email
+// signing in, or "" when there is none.
+type CredentialFunc func(ctx *gin.Context) string
+
+// JSONCredential reads the credential from a field of the JSON body. The
+// body is left for the handler to bind.
+func JSONCredential(field string) CredentialFunc {
+	return func(ctx *gin.Context) string {
+		if ctx.Request.Body == nil {
+			return ""
+		}
+		body, _ := io.ReadAll(io.LimitReader(ctx.Request.Body, 1<<20))
+		ctx.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return ""
+		}
+		value, _ := fields[field].(string)
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+}
+
+// ParamCredential uses a path parameter as the credential, e.g. the project
+// whose key is tried.
+func ParamCredential(param string) CredentialFunc {
+	return func(ctx *gin.Context) string {
+		return ctx.Param(param)
+	}
+}
+
+// RateLimitMiddleware throttles a route group by policy: per client IP, per
+// credential and for the group as a whole, answering 429 with Retry-After
+// once a limit is reached. With a lockout, a 401 or 403 response counts as a
+// failed attempt on the credential from that IP, and a 2xx response clears
+// them. The lockout is per IP so that nobody can lock a credential out for
+// everyone; guessing from many IPs is held back by the per-credential rule,
+// which only ever delays. The limiter's store failing lets requests through.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, policy configs.RateLimitPolicy, credential CredentialFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ip := ctx.ClientIP()
+		cred := ""
+		if credential != nil {
+			if c := credential(ctx); c != "" {
+				cred = hashCredential(c)
+			}
+		}
+		lockKey := ""
+		if cred != "" && policy.Lockout.Enabled() {
+			lockKey = lockoutKey(policy, cred, ip)
+		}
+
+		if lockKey != "" {
+			until, err := limiter.LockedUntil(lockKey)
+			if err != nil {
+				logger.Warnf("[RateLimit] %s: %v", policy.Name, err)
+			} else if !until.IsZero() {
+				tooManyRequests(ctx, until, "Too many failed attempts, try again later")
+				return
+			}
+		}
+
+		checks := []limitCheck{{policy.Name + ":ip:" + ip, policy.PerIP}}
+		if cred != "" {
+			checks = append(checks, limitCheck{policy.Name + ":credential:" + cred, policy.PerCredential})
+		}
+		checks = append(checks, limitCheck{policy.Name, policy.Global})
+		for _, c := range checks {
+			result, err := limiter.Allow(c.key, c.rule)
+			if err != nil {
+				logger.Warnf("[RateLimit] %s: %v", policy.Name, err)
+				continue
+			}
+			if !result.Allowed {
+				tooManyRequests(ctx, result.ResetAt, "Too many requests, try again later")
+				return
+			}
+		}
+
+		ctx.Next()
+
+		if lockKey == "" {
+			return
+		}
+		switch status := ctx.Writer.Status(); {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			until, err := limiter.Fail(lockKey, policy.Lockout)
+			if err != nil {
+				logger.Warnf("[RateLimit] %s: %v", policy.Name, err)
+			} else if !until.IsZero() {
+				logger.Warnf("[RateLimit] %s: locked out credential %s from %s until %s after failed attempts", policy.Name, cred, ip, until.UTC().Format(time.RFC3339))
+			}
+		case status >= 200 && status < 300:
+			if err := limiter.Succeed(lockKey); err != nil {
+				logger.Warnf("[RateLimit] %s: %v", policy.Name, err)
+			}
+		}
+	}
+}
+
+// KeyAttemptLimiter applies a policy to a credential that another middleware
+// checks itself, such as ProjectScopeMiddleware with X-Project-Key. Only
+// failed checks count against the per-IP and global limits and the lockout,
+// so a client that sends a valid credential on every request is never
+// throttled. The per-credential rule is left out: it would let anyone hold up
+// every client of the credential by failing on purpose. The lockout is
+// shared with RateLimitMiddleware on the same policy.
+type KeyAttemptLimiter struct {
+	limiter *ratelimit.Limiter
+	policy  configs.RateLimitPolicy
+}
+
+func NewKeyAttemptLimiter(limiter *ratelimit.Limiter, policy configs.RateLimitPolicy) *KeyAttemptLimiter {
+	return &KeyAttemptLimiter{limiter: limiter, policy: policy}
+}
+
+// Allow answers 429 and returns false when the client IP is locked out of
+// credential, or has used up a limit with failed checks.
+func (k *KeyAttemptLimiter) Allow(ctx *gin.Context, credential string) bool {
+	ip, cred := ctx.ClientIP(), hashCredential(credential)
+	if k.policy.Lockout.Enabled() {
+		until, err := k.limiter.LockedUntil(lockoutKey(k.policy, cred, ip))
+		if err != nil {
+			logger.Warnf("[RateLimit] %s: %v", k.policy.Name, err)
+		} else if !until.IsZero() {
+			tooManyRequests(ctx, until, "Too many failed attempts, try again later")
+			return false
+		}
+	}
+	for _, c := range k.failureRules(ip) {
+		result, err := k.limiter.Exceeded(c.key, c.rule)
+		if err != nil {
+			logger.Warnf("[RateLimit] %s: %v", k.policy.Name, err)
+			continue
+		}
+		if !result.Allowed {
+			tooManyRequests(ctx, result.ResetAt, "Too many failed attempts, try again later")
+			return false
+		}
+	}
+	return true
+}
+
+// Failed counts a failed check of credential from the client IP.
+func (k *KeyAttemptLimiter) Failed(ctx *gin.Context, credential string) {
+	ip, cred := ctx.ClientIP(), hashCredential(credential)
+	for _, c := range k.failureRules(ip) {
+		if _, err := k.limiter.Allow(c.key, c.rule); err != nil {
+			logger.Warnf("[RateLimit] %s: %v", k.policy.Name, err)
+		}
+	}
+	until, err := k.limiter.Fail(lockoutKey(k.policy, cred, ip), k.policy.Lockout)
+	if err != nil {
+		logger.Warnf("[RateLimit] %s: %v", k.policy.Name, err)
+	} else if !until.IsZero() {
+		logger.Warnf("[RateLimit] %s: locked out credential %s from %s until %s after failed attempts", k.policy.Name, cred, ip, until.UTC().Format(time.RFC3339))
+	}
+}
+
+// Succeeded clears the client IP's failures on credential.
+func (k *KeyAttemptLimiter) Succeeded(ctx *gin.Context, credential string) {
+	if !k.policy.Lockout.Enabled() {
+		return
+	}
+	if err := k.limiter.Succeed(lockoutKey(k.policy, hashCredential(credential), ctx.ClientIP())); err != nil {
+		logger.Warnf("[RateLimit] %s: %v", k.policy.Name, err)
+	}
+}
+
+type limitCheck struct {
+	key  string
+	rule ratelimit.Rule
+}
+
+func (k *KeyAttemptLimiter) failureRules(ip string) []limitCheck {
+	return []limitCheck{
+		{k.policy.Name + ":failed-ip:" + ip, k.policy.PerIP},
+		{k.policy.Name + ":failed", k.policy.Global},
+	}
+}
+
+// lockoutKey is the lockout of one credential from one client IP.
+func lockoutKey(policy configs.RateLimitPolicy, cred, ip string) string {
+	return policy.Name + ":lockout:" + cred + ":" + ip
+}
+
+func tooManyRequests(ctx *gin.Context, until time.Time, message string) {
+	seconds := int(math.Ceil(time.Until(until).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	utils.RespondWithError(ctx, http.StatusTooManyRequests, errors.New(errors.ErrTooManyRequests, fmt.Sprintf("%s (in %ds)", message, seconds)))
+	ctx.Abort()
+}
+
+// hashCredential keeps emails and other credentials out of the counters' keys.
+func hashCredential(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:16])
+}
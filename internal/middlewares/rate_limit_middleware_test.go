@@ -0,0 +1,188 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/ratelimit"
+)
+
+// newRateLimitRouter serves POST /login, answering 200 for the password
+// "right", 403 for "forbidden" and 401 otherwise.
+func newRateLimitRouter(policy configs.RateLimitPolicy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	limiter := ratelimit.New(ratelimit.NewMemoryStore())
+	router.POST("/login", RateLimitMiddleware(limiter, policy, JSONCredential("email")), func(c *gin.Context) {
+		var input struct {
+			Password string `json:"password"`
+		}
+		_ = c.ShouldBindJSON(&input)
+		switch input.Password {
+		case "right":
+			c.Status(http.StatusOK)
+		case "forbidden":
+			c.Status(http.StatusForbidden)
+		default:
+			c.Status(http.StatusUnauthorized)
+		}
+	})
+	return router
+}
+
+func postLogin(router *gin.Engine, ip, email, password string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"`+email+`","password":"`+password+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":4321"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddlewareTooManyRequests(t *testing.T) {
+	router := newRateLimitRouter(configs.RateLimitPolicy{
+		Name:  "login",
+		PerIP: ratelimit.Rule{Limit: 2, Window: time.Minute},
+	})
+
+	for i := 0; i < 2; i++ {
+		if w := postLogin(router, "10.0.0.1", "a@example.com", "right"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, w.Code)
+		}
+	}
+	w := postLogin(router, "10.0.0.1", "a@example.com", "right")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Fatalf("Retry-After = %q, want 1-60 seconds", w.Header().Get("Retry-After"))
+	}
+
+	if w := postLogin(router, "10.0.0.2", "a@example.com", "right"); w.Code != http.StatusOK {
+		t.Fatalf("another IP: status = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewareLockout(t *testing.T) {
+	router := newRateLimitRouter(configs.RateLimitPolicy{
+		Name:    "login",
+		Lockout: ratelimit.Backoff{After: 2, Base: time.Minute, Max: time.Hour, Forget: time.Hour},
+	})
+
+	if w := postLogin(router, "10.0.0.1", "a@example.com", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("first failure: status = %d, want 401", w.Code)
+	}
+	if w := postLogin(router, "10.0.0.1", "A@example.com", "forbidden"); w.Code != http.StatusForbidden {
+		t.Fatalf("second failure: status = %d, want 403", w.Code)
+	}
+
+	// Locked out from that IP, even with the right password
+	w := postLogin(router, "10.0.0.1", "a@example.com", "right")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if retryAfter, _ := strconv.Atoi(w.Header().Get("Retry-After")); retryAfter < 59 || retryAfter > 60 {
+		t.Fatalf("Retry-After = %q, want the 1 minute lockout", w.Header().Get("Retry-After"))
+	}
+
+	if w := postLogin(router, "10.0.0.1", "b@example.com", "right"); w.Code != http.StatusOK {
+		t.Fatalf("other credential: status = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewareLockoutIsPerIP(t *testing.T) {
+	router := newRateLimitRouter(configs.RateLimitPolicy{
+		Name:    "login",
+		Lockout: ratelimit.Backoff{After: 2, Base: time.Minute, Max: time.Hour, Forget: time.Hour},
+	})
+
+	// Failures from another IP don't count towards this one's lockout
+	postLogin(router, "10.0.0.1", "a@example.com", "wrong")
+	postLogin(router, "10.0.0.2", "a@example.com", "wrong")
+	if w := postLogin(router, "10.0.0.1", "a@example.com", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want the attempt let through after one failure from this IP", w.Code)
+	}
+	if w := postLogin(router, "10.0.0.1", "a@example.com", "right"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked IP: status = %d, want 429", w.Code)
+	}
+
+	// The owner signs in from another IP while the first one is locked out
+	if w := postLogin(router, "10.0.0.2", "a@example.com", "right"); w.Code != http.StatusOK {
+		t.Fatalf("second IP: status = %d, want 200", w.Code)
+	}
+	if w := postLogin(router, "10.0.0.1", "a@example.com", "right"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want the first IP to stay locked out", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewareSuccessResetsFailures(t *testing.T) {
+	router := newRateLimitRouter(configs.RateLimitPolicy{
+		Name:    "login",
+		Lockout: ratelimit.Backoff{After: 3, Base: time.Minute, Max: time.Hour, Forget: time.Hour},
+	})
+
+	for _, password := range []string{"wrong", "wrong", "right", "wrong", "wrong"} {
+		postLogin(router, "10.0.0.1", "a@example.com", password)
+	}
+	if w := postLogin(router, "10.0.0.1", "a@example.com", "right"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 since the success cleared the failures", w.Code)
+	}
+}
+
+type fakeKeyVerifier struct{}
+
+func (fakeKeyVerifier) VerifyKey(projectID uint, secret, ip string) (*models.ProjectAPIKey, error) {
+	if secret != "sk_proj_right" {
+		return nil, errors.New(errors.ErrAuthUnauthorized, "Invalid or expired project key")
+	}
+	return &models.ProjectAPIKey{ID: 1, ProjectID: projectID}, nil
+}
+
+func TestProjectScopeMiddlewareLimitsKeyAttempts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	attempts := NewKeyAttemptLimiter(ratelimit.New(ratelimit.NewMemoryStore()), configs.RateLimitPolicy{
+		Name:    "project-access",
+		PerIP:   ratelimit.Rule{Limit: 5, Window: time.Minute},
+		Lockout: ratelimit.Backoff{After: 3, Base: time.Minute, Max: time.Hour, Forget: time.Hour},
+	})
+	router.GET("/projects/:projectId/schedules", ProjectScopeMiddleware(nil, fakeKeyVerifier{}, attempts), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	get := func(ip, key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/projects/3/schedules", nil)
+		req.Header.Set("X-Project-Key", key)
+		req.RemoteAddr = ip + ":4321"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A valid key is never throttled, however often it is used
+	for i := 0; i < 10; i++ {
+		if code := get("10.0.0.1", "sk_proj_right"); code != http.StatusOK {
+			t.Fatalf("request %d with the right key: status = %d, want 200", i+1, code)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := get("10.0.0.1", "sk_proj_guess"); code != http.StatusUnauthorized {
+			t.Fatalf("guess %d: status = %d, want 401", i+1, code)
+		}
+	}
+	if code := get("10.0.0.1", "sk_proj_right"); code != http.StatusTooManyRequests {
+		t.Fatalf("after 3 wrong keys: status = %d, want 429", code)
+	}
+	if code := get("10.0.0.2", "sk_proj_right"); code != http.StatusOK {
+		t.Fatalf("another IP: status = %d, want 200", code)
+	}
+}
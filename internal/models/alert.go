@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// Alert states.
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// AlertGroup gathers the alerts of an endpoint that share the values of its
+// group-by labels. The group posts one firing message; later alerts and
+// resolutions reply to it. A group is closed once every alert resolves, and
+// the next firing alert opens a new one.
+type AlertGroup struct {
+	ID         uint              `json:"id" gorm:"primaryKey"`
+	ProjectID  uint              `json:"projectId" gorm:"column:project_id;not null;index:idx_alert_groups_project_id"`
+	EndpointID uint              `json:"endpointId" gorm:"column:endpoint_id;not null;index:idx_alert_groups_key"`
+	GroupKey   string            `json:"groupKey" gorm:"column:group_key;type:varchar(64);not null;index:idx_alert_groups_key"`
+	Labels     map[string]string `json:"labels" gorm:"column:labels;type:json;serializer:json"`
+	Status     string            `json:"status" gorm:"column:status;type:varchar(20);not null"`
+	// RoomID and MessageID locate the firing message replies refer to; empty
+	// until a message was posted, e.g. while every alert is silenced.
+	RoomID    string `json:"roomId" gorm:"column:room_id;type:varchar(255)"`
+	MessageID string `json:"messageId,omitempty" gorm:"column:message_id;type:varchar(64);index:idx_alert_groups_message"`
+
+	AckedBy    string     `json:"ackedBy,omitempty" gorm:"column:acked_by;type:varchar(255)"`
+	AckedAt    *time.Time `json:"ackedAt,omitempty" gorm:"column:acked_at"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty" gorm:"column:resolved_at"`
+
+	Alerts []Alert `json:"alerts,omitempty" gorm:"foreignKey:GroupID"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (AlertGroup) TableName() string {
+	return "alert_groups"
+}
+
+// Alert is one alert instance, identified within its project by Fingerprint
+// across firing and resolved notifications.
+type Alert struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	ProjectID   uint              `json:"projectId" gorm:"column:project_id;not null;uniqueIndex:uq_alerts_fingerprint"`
+	GroupID     uint              `json:"groupId" gorm:"column:group_id;not null;index:idx_alerts_group_id"`
+	Fingerprint string            `json:"fingerprint" gorm:"column:fingerprint;type:varchar(64);not null;uniqueIndex:uq_alerts_fingerprint"`
+	Name        string            `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	Status      string            `json:"status" gorm:"column:status;type:varchar(20);not null"`
+	Severity    string            `json:"severity" gorm:"column:severity;type:varchar(20)"`
+	Labels      map[string]string `json:"labels" gorm:"column:labels;type:json;serializer:json"`
+	Summary     string            `json:"summary" gorm:"column:summary;type:text"`
+	URL         string            `json:"url,omitempty" gorm:"column:url;type:varchar(2048)"`
+	// Notified is set once the alert appeared in a firing message, so only
+	// those alerts are announced as resolved.
+	Notified bool `json:"notified" gorm:"column:notified;not null"`
+	// SilenceID is the silence that muted the latest firing notification.
+	SilenceID *uint `json:"silenceId,omitempty" gorm:"column:silence_id"`
+
+	StartsAt   time.Time  `json:"startsAt" gorm:"column:starts_at;not null"`
+	EndsAt     *time.Time `json:"endsAt,omitempty" gorm:"column:ends_at"`
+	LastSeenAt time.Time  `json:"lastSeenAt" gorm:"column:last_seen_at;not null"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (Alert) TableName() string {
+	return "alerts"
+}
+
+// Silence matcher operators, as in Alertmanager.
+const (
+	MatchEqual     = "="
+	MatchNotEqual  = "!="
+	MatchRegexp    = "=~"
+	MatchNotRegexp = "!~"
+)
+
+// AlertMatcher compares one label. Regular expressions are anchored.
+type AlertMatcher struct {
+	Name  string `json:"name"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// AlertSilence mutes the firing notifications of the project's alerts whose
+// labels match every matcher, from StartsAt until EndsAt. Alerts are still
+// tracked while silenced.
+type AlertSilence struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	ProjectID uint           `json:"projectId" gorm:"column:project_id;not null;index:idx_alert_silences_project_id"`
+	Matchers  []AlertMatcher `json:"matchers" gorm:"column:matchers;type:json;serializer:json"`
+	Comment   string         `json:"comment" gorm:"column:comment;type:text"`
+	CreatedBy string         `json:"createdBy" gorm:"column:created_by;type:varchar(255)"`
+	StartsAt  time.Time      `json:"startsAt" gorm:"column:starts_at;not null"`
+	EndsAt    time.Time      `json:"endsAt" gorm:"column:ends_at;not null"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (AlertSilence) TableName() string {
+	return "alert_silences"
+}
+
+// Active reports whether the silence applies at t.
+func (s *AlertSilence) Active(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// BotRequest is the local copy of an incoming Chatwork contact request. Chatwork
+// forgets a request once it is decided, so its history is only kept here.
+type BotRequest struct {
+	ID               uint   `json:"-" gorm:"primaryKey"`
+	BotID            uint   `json:"botId" gorm:"column:bot_id;not null;uniqueIndex:uq_bot_request"`
+	RequestID        int64  `json:"requestId" gorm:"column:request_id;not null;uniqueIndex:uq_bot_request"`
+	AccountID        int64  `json:"accountId" gorm:"column:account_id"`
+	Name             string `json:"name" gorm:"column:name;type:varchar(255)"`
+	ChatworkID       string `json:"chatworkId" gorm:"column:chatwork_id;type:varchar(255)"`
+	OrganizationName string `json:"organizationName" gorm:"column:organization_name;type:varchar(255)"`
+	Department       string `json:"department" gorm:"column:department;type:varchar(255)"`
+	AvatarImageURL   string `json:"avatarImageUrl" gorm:"column:avatar_image_url;type:text"`
+	Message          string `json:"message" gorm:"column:message;type:text"`
+
+	Status      string     `json:"status" gorm:"column:status;type:varchar(20);not null;index:idx_bot_requests_status"`
+	FirstSeenAt time.Time  `json:"firstSeenAt" gorm:"column:first_seen_at;not null"`
+	LastSeenAt  time.Time  `json:"lastSeenAt" gorm:"column:last_seen_at;not null"`
+	DecidedAt   *time.Time `json:"decidedAt,omitempty" gorm:"column:decided_at"`
+	DecidedBy   string     `json:"decidedBy,omitempty" gorm:"column:decided_by;type:varchar(255)"`
+
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (BotRequest) TableName() string {
+	return "bot_requests"
+}
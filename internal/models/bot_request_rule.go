@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// BotRequestRule decides incoming contact requests without an admin. Active
+// rules are tried in Priority order (lowest first); the first match wins.
+type BotRequestRule struct {
+	ID       uint     `json:"id" gorm:"primaryKey"`
+	Name     string   `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	BotID    *uint    `json:"botId" gorm:"column:bot_id;index:idx_bot_request_rules_bot_id"` // nil applies to every bot
+	Field    string   `json:"field" gorm:"column:field;type:varchar(20);not null"`
+	Values   []string `json:"values" gorm:"column:match_values;type:json;serializer:json"`
+	Action   string   `json:"action" gorm:"column:action;type:varchar(10);not null"`
+	Priority int      `json:"priority" gorm:"column:priority;not null;default:0"`
+	Active   bool     `json:"active" gorm:"column:active;not null;default:true"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (BotRequestRule) TableName() string {
+	return "bot_request_rules"
+}
+
+// What a rule's Values are compared against. Organization names and Chatwork
+// IDs must match exactly (ignoring case); keywords match anywhere in the
+// request message.
+const (
+	BotRequestFieldOrganization = "organization"
+	BotRequestFieldChatworkID   = "chatwork_id"
+	BotRequestFieldKeyword      = "keyword"
+)
+
+const (
+	BotRequestActionAccept = "accept"
+	BotRequestActionReject = "reject"
+)
+
+// BotRequestAudit records one decision about an incoming contact request,
+// made by a rule, by an admin, or queueing it for review.
+type BotRequestAudit struct {
+	ID               uint   `json:"id" gorm:"primaryKey"`
+	BotID            uint   `json:"botId" gorm:"column:bot_id;not null;index:idx_bot_request_audits_request"`
+	RequestID        int64  `json:"requestId" gorm:"column:request_id;not null;index:idx_bot_request_audits_request"`
+	AccountID        int64  `json:"accountId" gorm:"column:account_id"`
+	Name             string `json:"name" gorm:"column:name;type:varchar(255)"`
+	ChatworkID       string `json:"chatworkId" gorm:"column:chatwork_id;type:varchar(255)"`
+	OrganizationName string `json:"organizationName" gorm:"column:organization_name;type:varchar(255)"`
+	Message          string `json:"message" gorm:"column:message;type:text"`
+
+	Decision string `json:"decision" gorm:"column:decision;type:varchar(20);not null;index:idx_bot_request_audits_decision"`
+	Source   string `json:"source" gorm:"column:source;type:varchar(20);not null"`
+	Actor    string `json:"actor,omitempty" gorm:"column:actor;type:varchar(255)"` // the admin who decided
+	RuleID   *uint  `json:"ruleId,omitempty" gorm:"column:rule_id"`
+	RuleName string `json:"ruleName,omitempty" gorm:"column:rule_name;type:varchar(255)"`
+	Error    string `json:"error,omitempty" gorm:"column:error;type:text"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (BotRequestAudit) TableName() string {
+	return "bot_request_audits"
+}
+
+// Request statuses and audit decisions. A request that matches no rule is
+// "queued" once and stays pending in Chatwork until an admin decides it. A
+// pending request that disappears from Chatwork without being decided here
+// (answered in the Chatwork app or withdrawn) becomes "expired".
+const (
+	BotRequestPending  = "pending"
+	BotRequestAccepted = "accepted"
+	BotRequestRejected = "rejected"
+	BotRequestExpired  = "expired"
+	BotRequestQueued   = "queued" // audit only
+	BotRequestFailed   = "failed" // audit only: the Chatwork call failed
+)
+
+// Who made an audited decision. The rules engine also records the requests it queues.
+const (
+	BotRequestSourceRules = "rules"
+	BotRequestSourceAdmin = "admin"
+)
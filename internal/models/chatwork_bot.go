@@ -6,73 +6,68 @@ import (
 	"gorm.io/gorm"
 )
 
-// ChatworkBot is persisted in DB — stores only what we own.
-// Profile data (name, accountId, avatarUrl, etc.) is fetched live from Chatwork API.
+// ChatworkBot is persisted in DB. The profile columns are a cache of the
+// bot's Chatwork account, refreshed together with its rooms by the directory sync.
 type ChatworkBot struct {
-	ID          uint           `json:"id"`
-	APIToken    string         `json:"-" gorm:"column:api_token;type:varchar(255);not null"`
-	Email       *string        `json:"email,omitempty" gorm:"type:varchar(255)"`
-	Description string         `json:"description" gorm:"type:text"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `json:"deletedAt,omitempty"`
+	ID          uint       `json:"id"`
+	APIToken    string     `json:"-" gorm:"column:api_token;type:text;not null;serializer:secret"`
+	Email       *string    `json:"email,omitempty" gorm:"type:varchar(255)"`
+	Description string     `json:"description" gorm:"type:text"`
+	AccountID   int64      `json:"accountId" gorm:"column:account_id"`
+	ChatworkID  string     `json:"chatworkId" gorm:"column:chatwork_id;type:varchar(255)"`
+	Name        string     `json:"name" gorm:"column:name;type:varchar(255)"`
+	AvatarURL   string     `json:"avatarUrl" gorm:"column:avatar_url;type:text"`
+	RoomsCount  int        `json:"roomsCount" gorm:"column:rooms_count"`
+	SyncedAt    *time.Time `json:"syncedAt,omitempty" gorm:"column:synced_at"`
+	SyncError   string     `json:"syncError,omitempty" gorm:"column:sync_error;type:text"`
+
+	HealthStatus    string     `json:"healthStatus" gorm:"column:health_status;type:varchar(20);default:unknown"`
+	HealthCheckedAt *time.Time `json:"healthCheckedAt,omitempty" gorm:"column:health_checked_at"`
+	HealthError     string     `json:"healthError,omitempty" gorm:"column:health_error;type:text"`
+
+	// WebhookToken verifies requests from the bot's Chatwork outgoing webhook.
+	WebhookToken string `json:"-" gorm:"column:webhook_token;type:text;serializer:secret"`
+
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty"`
 }
 
 func (ChatworkBot) TableName() string {
 	return "chatwork_bots"
 }
 
-// ChatworkMeResponse maps the Chatwork GET /v2/me API response.
-type ChatworkMeResponse struct {
-	AccountID        int    `json:"account_id"`
-	RoomID           int    `json:"room_id"`
-	Name             string `json:"name"`
-	ChatworkID       string `json:"chatwork_id"`
-	OrganizationID   int    `json:"organization_id"`
-	OrganizationName string `json:"organization_name"`
-	Department       string `json:"department"`
-	Title            string `json:"title"`
-	URL              string `json:"url"`
-	Introduction     string `json:"introduction"`
-	Mail             string `json:"mail"`
-	AvatarImageURL   string `json:"avatar_image_url"`
-	LoginMail        string `json:"login_mail"`
-}
+// Bot token health, as last seen by the health check. Network errors and
+// Chatwork outages don't change the status; they only set HealthError.
+const (
+	BotHealthUnknown      = "unknown"
+	BotHealthHealthy      = "healthy"
+	BotHealthUnauthorized = "unauthorized" // token revoked or account suspended
+)
 
-// ChatworkRoom maps a minimal entry from GET /v2/rooms.
-type ChatworkRoom struct {
-	RoomID     int `json:"room_id"`
-	MessageNum int `json:"message_num"`
-}
+// BotDetail is the API response combining DB data with the cached Chatwork profile.
+type BotDetail struct {
+	ID          uint       `json:"id"`
+	AccountID   int64      `json:"accountId"`
+	ChatworkID  string     `json:"chatworkId"`
+	Name        string     `json:"name"`
+	Email       *string    `json:"email,omitempty"`
+	AvatarURL   string     `json:"avatarUrl"`
+	Description string     `json:"description"`
+	RoomsCount  int        `json:"roomsCount"`
+	SyncedAt    *time.Time `json:"syncedAt,omitempty"`
+	SyncError   string     `json:"syncError,omitempty"`
 
-// ChatworkIncomingRequest maps an entry from GET /v2/incoming_requests.
-type ChatworkIncomingRequest struct {
-	RequestID        int    `json:"request_id"`
-	AccountID        int    `json:"account_id"`
-	Message          string `json:"message"`
-	Name             string `json:"name"`
-	ChatworkID       string `json:"chatwork_id"`
-	OrganizationID   int    `json:"organization_id"`
-	OrganizationName string `json:"organization_name"`
-	Department       string `json:"department"`
-	AvatarImageURL   string `json:"avatar_image_url"`
-}
+	HealthStatus    string     `json:"healthStatus"`
+	HealthCheckedAt *time.Time `json:"healthCheckedAt,omitempty"`
+	HealthError     string     `json:"healthError,omitempty"`
 
-// BotDetail is the enriched API response combining DB + live Chatwork profile data.
-type BotDetail struct {
-	ID          uint    `json:"id"`
-	AccountID   int     `json:"accountId"`
-	ChatworkID  string  `json:"chatworkId"`
-	Name        string  `json:"name"`
-	Email       *string `json:"email,omitempty"`
-	AvatarURL   string  `json:"avatarUrl"`
-	Description string  `json:"description"`
-	RoomsCount  int     `json:"roomsCount"`
+	WebhookConfigured bool `json:"webhookConfigured"`
 }
 
 // SenderInfo holds the Chatwork profile of the person who sent the friend request.
 type SenderInfo struct {
-	AccountID        int    `json:"accountId"`
+	AccountID        int64  `json:"accountId"`
 	Name             string `json:"name"`
 	ChatworkID       string `json:"chatworkId"`
 	OrganizationName string `json:"organizationName"`
@@ -82,13 +77,25 @@ type SenderInfo struct {
 
 // BotRequestItem is the response item for GET /bot-requests.
 // The ID is encoded as "{dbBotID}_{cwRequestID}" so accept/delete can
-// route to the correct bot API token without a DB lookup table.
+// route to the correct bot API token.
 type BotRequestItem struct {
-	ID         string      `json:"id"`
-	BotID      uint        `json:"botId"`
-	BotInfo    *BotDetail  `json:"botInfo"`
-	SenderInfo *SenderInfo `json:"senderInfo"`
-	Message    string      `json:"message"`
-	Status     string      `json:"status"`
-	CreatedAt  string      `json:"createdAt"`
+	ID          string      `json:"id"`
+	BotID       uint        `json:"botId"`
+	BotInfo     *BotDetail  `json:"botInfo"`
+	SenderInfo  *SenderInfo `json:"senderInfo"`
+	Message     string      `json:"message"`
+	Status      string      `json:"status"`
+	FirstSeenAt time.Time   `json:"firstSeenAt"`
+	LastSeenAt  time.Time   `json:"lastSeenAt"`
+	DecidedAt   *time.Time  `json:"decidedAt,omitempty"`
+	DecidedBy   string      `json:"decidedBy,omitempty"`
+	CreatedAt   string      `json:"createdAt"` // same as firstSeenAt, kept for older clients
+}
+
+// BotRequestSyncError reports a bot whose pending requests could not be read
+// from Chatwork; the listing shows that bot's requests as last synced.
+type BotRequestSyncError struct {
+	BotID   uint   `json:"botId"`
+	BotName string `json:"botName"`
+	Error   string `json:"error"`
 }
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// ChatworkBotRoom is the synced copy of a room the bot belongs to.
+type ChatworkBotRoom struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	BotID       uint      `json:"botId" gorm:"column:bot_id;not null;uniqueIndex:uq_bot_room"`
+	RoomID      int64     `json:"roomId" gorm:"column:room_id;not null;uniqueIndex:uq_bot_room"`
+	Name        string    `json:"name" gorm:"column:name;type:varchar(255)"`
+	Type        string    `json:"type" gorm:"column:type;type:varchar(20)"` // my | direct | group
+	Role        string    `json:"role" gorm:"column:role;type:varchar(20)"` // admin | member | readonly
+	IconPath    string    `json:"iconPath" gorm:"column:icon_path;type:text"`
+	MemberCount int       `json:"memberCount" gorm:"column:member_count"`
+	SyncedAt    time.Time `json:"syncedAt" gorm:"column:synced_at"`
+}
+
+func (ChatworkBotRoom) TableName() string {
+	return "chatwork_bot_rooms"
+}
+
+// ChatworkBotRoomMember is the synced copy of one member of a bot's room.
+type ChatworkBotRoomMember struct {
+	ID               uint   `json:"-" gorm:"primaryKey"`
+	BotID            uint   `json:"-" gorm:"column:bot_id;not null;uniqueIndex:uq_bot_room_member"`
+	RoomID           int64  `json:"-" gorm:"column:room_id;not null;uniqueIndex:uq_bot_room_member"`
+	AccountID        int64  `json:"accountId" gorm:"column:account_id;not null;uniqueIndex:uq_bot_room_member"`
+	Name             string `json:"name" gorm:"column:name;type:varchar(255)"`
+	Role             string `json:"role" gorm:"column:role;type:varchar(20)"`
+	ChatworkID       string `json:"chatworkId" gorm:"column:chatwork_id;type:varchar(255)"`
+	OrganizationName string `json:"organizationName" gorm:"column:organization_name;type:varchar(255)"`
+	Department       string `json:"department" gorm:"column:department;type:varchar(255)"`
+	AvatarImageURL   string `json:"avatarImageUrl" gorm:"column:avatar_image_url;type:text"`
+}
+
+func (ChatworkBotRoomMember) TableName() string {
+	return "chatwork_bot_room_members"
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// ChatworkCommandGrant lets a Chatwork room run bot commands against a
+// project. AccountIDs limits who may run them; empty means anyone in the room.
+type ChatworkCommandGrant struct {
+	ID         uint     `json:"id" gorm:"primaryKey"`
+	ProjectID  uint     `json:"projectId" gorm:"column:project_id;not null;index:idx_command_grants_project_id"`
+	RoomID     string   `json:"roomId" gorm:"column:room_id;type:varchar(255);not null;index:idx_command_grants_room_id"`
+	Commands   []string `json:"commands" gorm:"column:commands;type:json;serializer:json"`
+	AccountIDs []int64  `json:"accountIds" gorm:"column:account_ids;type:json;serializer:json"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (ChatworkCommandGrant) TableName() string {
+	return "chatwork_command_grants"
+}
+
+// Commands the bot answers when mentioned. /help is always available.
+const (
+	CommandSchedules = "schedules"
+	CommandPause     = "pause"
+	CommandResume    = "resume"
+	CommandScan      = "scan"
+	CommandCve       = "cve"
+	CommandAlerts    = "alerts"
+	CommandAck       = "ack"
+	CommandOnCall    = "oncall"
+)
+
+// ChatworkCommands lists the grantable commands.
+var ChatworkCommands = []string{CommandSchedules, CommandPause, CommandResume, CommandScan, CommandCve, CommandAlerts, CommandAck, CommandOnCall}
+
+// Allows reports whether the grant lets accountID run command.
+func (g *ChatworkCommandGrant) Allows(command string, accountID int64) bool {
+	allowed := false
+	for _, c := range g.Commands {
+		if c == command {
+			allowed = true
+			break
+		}
+	}
+	if !allowed || len(g.AccountIDs) == 0 {
+		return allowed
+	}
+	for _, id := range g.AccountIDs {
+		if id == accountID {
+			return true
+		}
+	}
+	return false
+}
@@ -14,15 +14,20 @@ type CveConfig struct {
 	Languages            string         `gorm:"type:text;not null" json:"languages"`
 	Cron                 string         `gorm:"type:varchar(50);not null" json:"cron"`
 	Status               string         `gorm:"type:varchar(20);default:'active'" json:"status"`
-	ApiKey               string         `gorm:"type:varchar(255)" json:"-"`
+	ApiKey               string         `gorm:"type:text;serializer:secret" json:"-"`
 	BotID                *int           `gorm:"type:int" json:"botId,omitempty"`
 	NotifyOnSuccess      bool           `gorm:"default:false" json:"notifyOnSuccess"`
 	NotifyOnFailure      bool           `gorm:"default:true" json:"notifyOnFailure"`
 	NotifyRoomId         string         `gorm:"type:varchar(255)" json:"notifyRoomId,omitempty"`
+	ChannelID            *uint          `gorm:"column:channel_id" json:"channelId,omitempty"`
+	EscalationPolicyID   *uint          `gorm:"column:escalation_policy_id" json:"escalationPolicyId,omitempty"`
 	NotifyOnCritical     bool           `gorm:"default:true" json:"notifyOnCritical"`
 	NotifyOnHigh         bool           `gorm:"default:true" json:"notifyOnHigh"`
 	NotifyOnModerate     bool           `gorm:"default:false" json:"notifyOnModerate"`
 	NotifyOnLow          bool           `gorm:"default:false" json:"notifyOnLow"`
+	EmailRecipients      []string       `gorm:"column:email_recipients;type:json;serializer:json" json:"emailRecipients,omitempty"`
+	EmailAttachCsv       bool           `gorm:"default:false" json:"emailAttachCsv"`
+	EmailDigest          bool           `gorm:"default:false" json:"emailDigest"`
 	LastScan             *time.Time     `json:"lastScan,omitempty"`
 	LastStatus           string         `gorm:"type:varchar(20);default:'no_scan'" json:"lastStatus"`
 	VulnerabilitiesFound int            `gorm:"default:0" json:"vulnerabilitiesFound"`
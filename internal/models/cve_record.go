@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+)
+
+// CveRecord is a CVE entry persisted from the NVD crawler.
+type CveRecord struct {
+	CveID          string         `gorm:"column:cve_id;type:varchar(50);primaryKey" json:"cveId"`
+	Description    string         `gorm:"type:text" json:"description"`
+	Severity       string         `gorm:"type:varchar(20);index" json:"severity"`
+	BaseScore      float64        `gorm:"type:decimal(4,1);index" json:"baseScore"`
+	CvssVersion    string         `gorm:"type:varchar(10)" json:"cvssVersion,omitempty"`
+	VectorString   string         `gorm:"type:varchar(255)" json:"vectorString,omitempty"`
+	Metrics        CveMetrics     `gorm:"type:json;serializer:json" json:"metrics"`
+	References     []CveReference `gorm:"type:json;serializer:json" json:"references"`
+	PublishedAt    time.Time      `gorm:"index" json:"publishedAt"`
+	LastModifiedAt time.Time      `json:"lastModifiedAt"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+
+	Cwes []CveRecordCwe `gorm:"foreignKey:CveID;references:CveID" json:"cwes"`
+	Cpes []CveRecordCpe `gorm:"foreignKey:CveID;references:CveID" json:"cpes"`
+}
+
+func (CveRecord) TableName() string {
+	return "cve_records"
+}
+
+// CveMetrics keeps the CVSS base metrics reported by NVD for each version.
+type CveMetrics struct {
+	V31 *CveCvssMetric `json:"v31,omitempty"`
+	V30 *CveCvssMetric `json:"v30,omitempty"`
+	V2  *CveCvssMetric `json:"v2,omitempty"`
+}
+
+type CveCvssMetric struct {
+	Source              string  `json:"source,omitempty"`
+	VectorString        string  `json:"vectorString"`
+	BaseScore           float64 `json:"baseScore"`
+	BaseSeverity        string  `json:"baseSeverity"`
+	ExploitabilityScore float64 `json:"exploitabilityScore,omitempty"`
+	ImpactScore         float64 `json:"impactScore,omitempty"`
+}
+
+type CveReference struct {
+	URL    string   `json:"url"`
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// CveRecordCwe links a CVE to one of its CWE weakness identifiers.
+type CveRecordCwe struct {
+	ID    uint   `gorm:"primaryKey" json:"-"`
+	CveID string `gorm:"column:cve_id;type:varchar(50);not null;index" json:"-"`
+	CweID string `gorm:"column:cwe_id;type:varchar(30);not null;index" json:"cweId"`
+}
+
+func (CveRecordCwe) TableName() string {
+	return "cve_record_cwes"
+}
+
+// CveRecordCpe is one cpeMatch criterion from the CVE's NVD configurations.
+type CveRecordCpe struct {
+	ID                    uint   `gorm:"primaryKey" json:"-"`
+	CveID                 string `gorm:"column:cve_id;type:varchar(50);not null;index" json:"-"`
+	Criteria              string `gorm:"type:varchar(500);not null" json:"criteria"`
+	Vendor                string `gorm:"type:varchar(255);index" json:"vendor"`
+	Product               string `gorm:"type:varchar(255);index" json:"product"`
+	Version               string `gorm:"type:varchar(100)" json:"version"`
+	Vulnerable            bool   `json:"vulnerable"`
+	VersionStartIncluding string `gorm:"type:varchar(100)" json:"versionStartIncluding,omitempty"`
+	VersionStartExcluding string `gorm:"type:varchar(100)" json:"versionStartExcluding,omitempty"`
+	VersionEndIncluding   string `gorm:"type:varchar(100)" json:"versionEndIncluding,omitempty"`
+	VersionEndExcluding   string `gorm:"type:varchar(100)" json:"versionEndExcluding,omitempty"`
+	// Position in the NVD configuration tree: a configuration combines its
+	// nodes with ConfigOperator, a node combines its entries with NodeOperator.
+	ConfigIndex    int    `gorm:"not null;default:0" json:"configIndex"`
+	ConfigOperator string `gorm:"type:varchar(3);not null;default:OR" json:"configOperator"`
+	NodeIndex      int    `gorm:"not null;default:0" json:"nodeIndex"`
+	NodeOperator   string `gorm:"type:varchar(3);not null;default:OR" json:"nodeOperator"`
+	NodeNegate     bool   `gorm:"not null;default:false" json:"nodeNegate"`
+}
+
+func (CveRecordCpe) TableName() string {
+	return "cve_record_cpes"
+}
+
+// CveFacet is a value/count pair returned with search results.
+type CveFacet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// CveSearchFacets groups facet counts of a CVE search.
+type CveSearchFacets struct {
+	Severity []CveFacet `json:"severity"`
+	Cwe      []CveFacet `json:"cwe"`
+	Vendor   []CveFacet `json:"vendor"`
+	Product  []CveFacet `json:"product"`
+}
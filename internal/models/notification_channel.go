@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationChannel is a delivery target (Chatwork room, Slack/Discord/Teams
+// webhook, signed HTTP webhook or email list) that schedules and CVE configs can use.
+type NotificationChannel struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	ProjectID  uint           `json:"projectId" gorm:"column:project_id;not null;index:idx_notification_channel_project_id"`
+	Name       string         `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	Type       string         `json:"type" gorm:"column:type;type:varchar(20);not null"` // chatwork | slack | discord | teams | webhook | email
+	RoomID     string         `json:"roomId,omitempty" gorm:"column:room_id;type:varchar(255)"`
+	BotID      *uint          `json:"botId,omitempty" gorm:"column:bot_id"`
+	Token      string         `json:"-" gorm:"column:token;type:text;serializer:secret"`
+	WebhookURL string         `json:"-" gorm:"column:webhook_url;type:text;serializer:secret"`
+	Secret     string         `json:"-" gorm:"column:secret;type:text;serializer:secret"`
+	Recipients []string       `json:"recipients,omitempty" gorm:"column:recipients;type:json;serializer:json"`
+	Active     bool           `json:"active" gorm:"column:active;default:true"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+}
+
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
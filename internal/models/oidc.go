@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// OIDCAuthRequest is a single sign-on attempt waiting for the provider to
+// redirect back. It is keyed by a hash of its state and used once.
+type OIDCAuthRequest struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	StateHash    string    `json:"-" gorm:"column:state_hash;type:varchar(64);not null;uniqueIndex:idx_oidc_auth_requests_state_hash"`
+	Nonce        string    `json:"-" gorm:"column:nonce;type:varchar(64);not null"`
+	CodeVerifier string    `json:"-" gorm:"column:code_verifier;type:varchar(128);not null"`
+	ExpiresAt    time.Time `json:"expiresAt" gorm:"column:expires_at;not null;index:idx_oidc_auth_requests_expires_at"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (OIDCAuthRequest) TableName() string {
+	return "oidc_auth_requests"
+}
+
+// OIDCGroupMapping gives members of a provider group a role in a project
+// when they sign in with single sign-on.
+type OIDCGroupMapping struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Group     string `json:"group" gorm:"column:group_name;type:varchar(255);not null;uniqueIndex:idx_oidc_group_mappings_group_project"`
+	ProjectID uint   `json:"projectId" gorm:"column:project_id;not null;uniqueIndex:idx_oidc_group_mappings_group_project;index:idx_oidc_group_mappings_project_id"`
+	Role      string `json:"role" gorm:"column:role;type:varchar(20);not null"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (OIDCGroupMapping) TableName() string {
+	return "oidc_group_mappings"
+}
@@ -0,0 +1,148 @@
+package models
+
+import "time"
+
+// Rotation handoff periods.
+const (
+	HandoffDaily  = "daily"
+	HandoffWeekly = "weekly"
+)
+
+// OnCallParticipant is a Chatwork account taking shifts in a rotation.
+type OnCallParticipant struct {
+	AccountID int64  `json:"accountId"`
+	Name      string `json:"name"`
+}
+
+// OnCallRotation hands on-call duty to the next participant every day or
+// every week, at the time of day (and weekday) of StartsAt in Timezone. The
+// first participant is on call from StartsAt.
+type OnCallRotation struct {
+	ID           uint                `json:"id" gorm:"primaryKey"`
+	ProjectID    uint                `json:"projectId" gorm:"column:project_id;not null;index:idx_oncall_rotations_project_id"`
+	Name         string              `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	Handoff      string              `json:"handoff" gorm:"column:handoff;type:varchar(10);not null"`
+	StartsAt     time.Time           `json:"startsAt" gorm:"column:starts_at;not null"`
+	Timezone     string              `json:"timezone" gorm:"column:timezone;type:varchar(64)"`
+	Participants []OnCallParticipant `json:"participants" gorm:"column:participants;type:json;serializer:json"`
+
+	Overrides []OnCallOverride `json:"overrides,omitempty" gorm:"foreignKey:RotationID"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (OnCallRotation) TableName() string {
+	return "oncall_rotations"
+}
+
+// OnCallOverride puts someone else on call between StartsAt and EndsAt, e.g.
+// to cover a holiday. The newest override wins where they overlap.
+type OnCallOverride struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	RotationID uint      `json:"rotationId" gorm:"column:rotation_id;not null;index:idx_oncall_overrides_rotation_id"`
+	AccountID  int64     `json:"accountId" gorm:"column:account_id;not null"`
+	Name       string    `json:"name" gorm:"column:name;type:varchar(255)"`
+	StartsAt   time.Time `json:"startsAt" gorm:"column:starts_at;not null"`
+	EndsAt     time.Time `json:"endsAt" gorm:"column:ends_at;not null"`
+	Reason     string    `json:"reason,omitempty" gorm:"column:reason;type:varchar(255)"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (OnCallOverride) TableName() string {
+	return "oncall_overrides"
+}
+
+// OnCallShift is who is on call during a period of a rotation.
+type OnCallShift struct {
+	AccountID int64     `json:"accountId"`
+	Name      string    `json:"name"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	// OverrideID is set when an override replaced the scheduled participant.
+	OverrideID *uint `json:"overrideId,omitempty"`
+}
+
+// EscalationLevel is one step of a policy: who is mentioned, where, and how
+// long they have to acknowledge before the next level is notified.
+type EscalationLevel struct {
+	// RotationID mentions whoever is on call in the rotation.
+	RotationID *uint `json:"rotationId,omitempty"`
+	// AccountIDs are mentioned as well, or alone without a rotation.
+	AccountIDs []int64 `json:"accountIds,omitempty"`
+	// RoomID overrides the policy's room for this level.
+	RoomID         string `json:"roomId,omitempty"`
+	TimeoutMinutes int    `json:"timeoutMinutes"`
+}
+
+// EscalationPolicy notifies its levels in turn until someone acknowledges.
+type EscalationPolicy struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	ProjectID uint              `json:"projectId" gorm:"column:project_id;not null;index:idx_escalation_policies_project_id"`
+	Name      string            `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	BotID     uint              `json:"botId" gorm:"column:bot_id;not null"`
+	RoomID    string            `json:"roomId" gorm:"column:room_id;type:varchar(255);not null"`
+	Levels    []EscalationLevel `json:"levels" gorm:"column:levels;type:json;serializer:json"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (EscalationPolicy) TableName() string {
+	return "escalation_policies"
+}
+
+// Escalation states.
+const (
+	EscalationOpen         = "open"
+	EscalationAcknowledged = "acknowledged"
+	// EscalationExhausted means the last level timed out without an acknowledgement.
+	EscalationExhausted = "exhausted"
+)
+
+// Escalation is one incident run through a policy. Level is the index of the
+// level notified last; NextAt is when the next one is due.
+type Escalation struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	ProjectID uint   `json:"projectId" gorm:"column:project_id;not null;index:idx_escalations_project_id"`
+	PolicyID  uint   `json:"policyId" gorm:"column:policy_id;not null"`
+	Source    string `json:"source" gorm:"column:source;type:varchar(50);not null"`
+	// SourceRef identifies what triggered it, e.g. a CVE config; an open
+	// escalation for the same ref is not opened twice.
+	SourceRef string     `json:"sourceRef" gorm:"column:source_ref;type:varchar(255);index:idx_escalations_source_ref"`
+	Title     string     `json:"title" gorm:"column:title;type:varchar(255);not null"`
+	Text      string     `json:"text" gorm:"column:text;type:text"`
+	Status    string     `json:"status" gorm:"column:status;type:varchar(20);not null;index:idx_escalations_status"`
+	Level     int        `json:"level" gorm:"column:level;not null"`
+	NextAt    *time.Time `json:"nextAt,omitempty" gorm:"column:next_at"`
+	AckedBy   string     `json:"ackedBy,omitempty" gorm:"column:acked_by;type:varchar(255)"`
+	AckedAt   *time.Time `json:"ackedAt,omitempty" gorm:"column:acked_at"`
+
+	Notifications []EscalationNotification `json:"notifications,omitempty" gorm:"foreignKey:EscalationID"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (Escalation) TableName() string {
+	return "escalations"
+}
+
+// EscalationNotification is a message posted for a level. Replying /ack to
+// it acknowledges the escalation.
+type EscalationNotification struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	EscalationID uint    `json:"escalationId" gorm:"column:escalation_id;not null;index:idx_escalation_notifications_escalation_id"`
+	Level        int     `json:"level" gorm:"column:level;not null"`
+	RoomID       string  `json:"roomId" gorm:"column:room_id;type:varchar(255);not null"`
+	MessageID    string  `json:"messageId,omitempty" gorm:"column:message_id;type:varchar(64);index:idx_escalation_notifications_message"`
+	AccountIDs   []int64 `json:"accountIds" gorm:"column:account_ids;type:json;serializer:json"`
+	Error        string  `json:"error,omitempty" gorm:"column:error;type:text"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (EscalationNotification) TableName() string {
+	return "escalation_notifications"
+}
@@ -12,7 +12,8 @@ type Project struct {
 	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
 	Status      string         `gorm:"type:varchar(20);default:'active'" json:"status"` // active | inactive
-	SecretKey   string         `gorm:"type:varchar(255)" json:"-"`
+	AlertRoomID string         `gorm:"type:varchar(255)" json:"alertRoomId"`            // Chatwork room for tech stack CVE alerts
+	AlertBotID  *uint          `json:"alertBotId,omitempty"`
 	CreatedAt   time.Time      `json:"createdAt"`           // JSON tag for CreatedAt
 	UpdatedAt   time.Time      `json:"updatedAt"`           // JSON tag for UpdatedAt
 	DeletedAt   gorm.DeletedAt `json:"deletedAt,omitempty"` // JSON tag for DeletedAt
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProjectTechStack is one product/version declared in a project's inventory.
+// Vendor and Product follow the NVD CPE dictionary so crawled CVEs can be matched.
+type ProjectTechStack struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	ProjectID uint           `json:"projectId" gorm:"column:project_id;not null;index:idx_tech_stack_project_id"`
+	Name      string         `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	Vendor    string         `json:"vendor" gorm:"column:vendor;type:varchar(255);not null"`
+	Product   string         `json:"product" gorm:"column:product;type:varchar(255);not null;index:idx_tech_stack_product"`
+	Version   string         `json:"version" gorm:"column:version;type:varchar(100)"`
+	CPE       string         `json:"cpe" gorm:"column:cpe;type:varchar(500);not null"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+}
+
+func (ProjectTechStack) TableName() string {
+	return "project_tech_stacks"
+}
+
+// CpeFinding records a crawled CVE whose CPE configuration matches a tech stack entry.
+type CpeFinding struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	ProjectID       uint       `json:"projectId" gorm:"column:project_id;not null;index:idx_cpe_finding_project_id"`
+	TechStackID     uint       `json:"techStackId" gorm:"column:tech_stack_id;not null"`
+	CveID           string     `json:"cveId" gorm:"column:cve_id;type:varchar(50);not null;index"`
+	Severity        string     `json:"severity" gorm:"type:varchar(20)"`
+	Score           float64    `json:"score" gorm:"type:decimal(4,1)"`
+	Product         string     `json:"product" gorm:"type:varchar(255)"`
+	Version         string     `json:"version" gorm:"type:varchar(100)"`
+	MatchedCriteria string     `json:"matchedCriteria" gorm:"type:varchar(500)"`
+	Summary         string     `json:"summary" gorm:"type:text"`
+	NotifiedAt      *time.Time `json:"notifiedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+func (CpeFinding) TableName() string {
+	return "cpe_findings"
+}
@@ -8,17 +8,36 @@ import (
 
 // ReminderSchedule represents a scheduled reminder for a project
 type ReminderSchedule struct {
-	ID             uint           `json:"id"` // JSON tag for ID
-	ProjectID      uint           `json:"projectId" gorm:"column:project_id;not null;index:idx_reminder_project_id"`
-	Name           string         `json:"name" gorm:"column:name;type:varchar(255);not null"`
-	CronExpression string         `json:"cronExpression" gorm:"column:cron_expression;type:varchar(255);not null"`
-	ChatworkRoomID string         `json:"chatworkRoomId" gorm:"column:chatwork_room_id;type:varchar(255);not null"`
-	ChatworkToken  *string        `json:"chatworkToken,omitempty" gorm:"column:chatwork_token;type:varchar(255)"`
-	BotID          *uint          `json:"botId,omitempty" gorm:"column:bot_id"`
-	Message        string         `json:"message" gorm:"column:message;type:text"`
-	Active         bool           `json:"active" gorm:"column:active;default:true"`
-	CreatedAt      time.Time      `json:"createdAt"`           // JSON tag for CreatedAt
-	UpdatedAt      time.Time      `json:"updatedAt"`           // JSON tag for UpdatedAt
-	DeletedAt      gorm.DeletedAt `json:"deletedAt,omitempty"` // JSON tag for DeletedAt
-	Project        Project        `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE;"`
+	ID             uint    `json:"id"` // JSON tag for ID
+	ProjectID      uint    `json:"projectId" gorm:"column:project_id;not null;index:idx_reminder_project_id"`
+	Name           string  `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	CronExpression string  `json:"cronExpression" gorm:"column:cron_expression;type:varchar(255);not null"`
+	ChatworkRoomID string  `json:"chatworkRoomId" gorm:"column:chatwork_room_id;type:varchar(255);not null"`
+	ChatworkToken  *string `json:"-" gorm:"column:chatwork_token;type:text;serializer:secret"`
+	BotID          *uint   `json:"botId,omitempty" gorm:"column:bot_id"`
+	ChannelID      *uint   `json:"channelId,omitempty" gorm:"column:channel_id"` // overrides the Chatwork room/token when set
+	Message        string  `json:"message" gorm:"column:message;type:text"`      // the task body template for task actions
+
+	// Task actions create Chatwork tasks instead of posting Message.
+	ActionType      string  `json:"actionType" gorm:"column:action_type;type:varchar(20);not null;default:message"`
+	TaskAssigneeIDs []int64 `json:"taskAssigneeIds,omitempty" gorm:"column:task_assignee_ids;type:json;serializer:json"`
+	TaskDeadline    string  `json:"taskDeadline,omitempty" gorm:"column:task_deadline;type:varchar(20)"`  // relative, e.g. "3d"
+	TaskFollowUp    string  `json:"taskFollowUp,omitempty" gorm:"column:task_follow_up;type:varchar(20)"` // relative, e.g. "1d"; empty for none
+
+	Active    bool           `json:"active" gorm:"column:active;default:true"`
+	CreatedAt time.Time      `json:"createdAt"`           // JSON tag for CreatedAt
+	UpdatedAt time.Time      `json:"updatedAt"`           // JSON tag for UpdatedAt
+	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty"` // JSON tag for DeletedAt
+	Project   Project        `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE;"`
+}
+
+// Schedule action types.
+const (
+	ScheduleActionMessage = "message"
+	ScheduleActionTask    = "task"
+)
+
+// IsTaskAction reports whether the schedule creates Chatwork tasks.
+func (s *ReminderSchedule) IsTaskAction() bool {
+	return s.ActionType == ScheduleActionTask
 }
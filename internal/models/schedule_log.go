@@ -7,19 +7,34 @@ import (
 )
 
 type ScheduleLog struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	ProjectID    uint           `json:"projectId" gorm:"column:project_id;not null;index:idx_log_project_id"`
-	ScheduleID   uint           `json:"scheduleId" gorm:"column:schedule_id;not null;index:idx_log_schedule_id"`
-	Status       string         `json:"status" gorm:"column:status;type:varchar(50);not null"`
-	ErrorMessage string         `json:"errorMessage" gorm:"column:error_message;type:text"`
-	CreatedAt    time.Time      `json:"createdAt"`
-	UpdatedAt    time.Time      `json:"updatedAt"`
-	DeletedAt    gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	ProjectID    uint   `json:"projectId" gorm:"column:project_id;not null;index:idx_log_project_id"`
+	ScheduleID   uint   `json:"scheduleId" gorm:"column:schedule_id;not null;index:idx_log_schedule_id"`
+	Status       string `json:"status" gorm:"column:status;type:varchar(50);not null"`
+	ErrorMessage string `json:"errorMessage" gorm:"column:error_message;type:text"`
+
+	// Set when the schedule created Chatwork tasks.
+	TaskIDs        []int64    `json:"taskIds,omitempty" gorm:"column:task_ids;type:json;serializer:json"`
+	TaskRoomID     string     `json:"taskRoomId,omitempty" gorm:"column:task_room_id;type:varchar(255)"`
+	FollowUpAt     *time.Time `json:"followUpAt,omitempty" gorm:"column:follow_up_at;index:idx_log_follow_up"`
+	FollowUpStatus string     `json:"followUpStatus,omitempty" gorm:"column:follow_up_status;type:varchar(20)"`
+
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
 
 	Project  Project          `json:"project,omitempty" gorm:"foreignKey:ProjectID"`
 	Schedule ReminderSchedule `json:"schedule,omitempty" gorm:"foreignKey:ScheduleID"`
 }
 
+// Follow-up statuses of a task run.
+const (
+	FollowUpPending = "pending"
+	FollowUpDone    = "done"   // every task was completed
+	FollowUpNagged  = "nagged" // open tasks were reminded in the room
+	FollowUpFailed  = "failed"
+)
+
 type ProjectSummary struct {
 	ProjectID    uint   `json:"projectId"`
 	ProjectName  string `json:"projectName"`
@@ -67,4 +82,7 @@ type RunLogV2 struct {
 	Status      string `json:"status"`
 	Timestamp   string `json:"timestamp"`
 	Message     string `json:"message"`
+
+	TaskIDs        []int64 `json:"taskIds,omitempty" gorm:"column:task_ids;serializer:json"`
+	FollowUpStatus string  `json:"followUpStatus,omitempty"`
 }
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Project roles, from least to most privileged.
+const (
+	RoleViewer     = "viewer"
+	RoleMaintainer = "maintainer"
+	RoleOwner      = "owner"
+)
+
+// ProjectRoles lists the assignable roles, least privileged first.
+var ProjectRoles = []string{RoleViewer, RoleMaintainer, RoleOwner}
+
+// RoleAtLeast reports whether role grants everything min does. Unknown roles
+// grant nothing.
+func RoleAtLeast(role, min string) bool {
+	have := slices.Index(ProjectRoles, role)
+	return have >= 0 && have >= slices.Index(ProjectRoles, min)
+}
+
+// User states.
+const (
+	UserActive   = "active"
+	UserDisabled = "disabled"
+)
+
+// User is someone who signs in to the dashboard. Admins manage users, bots
+// and every project; everyone else sees the projects they are a member of.
+type User struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Email        string     `json:"email" gorm:"column:email;type:varchar(255);not null;uniqueIndex:idx_users_email"`
+	Name         string     `json:"name" gorm:"column:name;type:varchar(255)"`
+	PasswordHash string     `json:"-" gorm:"column:password_hash;type:varchar(255)"`
+	OIDCSubject  *string    `json:"-" gorm:"column:oidc_subject;type:varchar(255);uniqueIndex:idx_users_oidc_subject"` // subject at the single sign-on provider
+	IsAdmin      bool       `json:"isAdmin" gorm:"column:is_admin;not null;default:false"`
+	Status       string     `json:"status" gorm:"column:status;type:varchar(20);not null;default:'active'"`
+	LastLoginAt  *time.Time `json:"lastLoginAt,omitempty" gorm:"column:last_login_at"`
+
+	Memberships []ProjectMember `json:"projects,omitempty" gorm:"foreignKey:UserID"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
+
+// MemberSourceOIDC marks memberships synced from the single sign-on
+// provider's groups. Memberships set by hand have no source.
+const MemberSourceOIDC = "oidc"
+
+// ProjectMember gives a user a role in a project.
+type ProjectMember struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	ProjectID uint   `json:"projectId" gorm:"column:project_id;not null;uniqueIndex:idx_project_members_project_user"`
+	UserID    uint   `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_project_members_project_user;index:idx_project_members_user_id"`
+	Role      string `json:"role" gorm:"column:role;type:varchar(20);not null"`
+	Source    string `json:"source,omitempty" gorm:"column:source;type:varchar(20)"`
+
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (ProjectMember) TableName() string {
+	return "project_members"
+}
+
+// InvitedRole is a project role the invited user gets on accepting.
+type InvitedRole struct {
+	ProjectID uint   `json:"projectId"`
+	Role      string `json:"role"`
+}
+
+// UserInvitation lets someone create their account with the token sent to
+// them. Only a hash of the token is stored.
+type UserInvitation struct {
+	ID         uint          `json:"id" gorm:"primaryKey"`
+	Email      string        `json:"email" gorm:"column:email;type:varchar(255);not null;index:idx_user_invitations_email"`
+	Name       string        `json:"name" gorm:"column:name;type:varchar(255)"`
+	IsAdmin    bool          `json:"isAdmin" gorm:"column:is_admin;not null;default:false"`
+	Projects   []InvitedRole `json:"projects" gorm:"column:projects;type:json;serializer:json"`
+	TokenHash  string        `json:"-" gorm:"column:token_hash;type:varchar(64);not null;uniqueIndex:idx_user_invitations_token_hash"`
+	InvitedBy  uint          `json:"invitedBy" gorm:"column:invited_by"`
+	ExpiresAt  time.Time     `json:"expiresAt" gorm:"column:expires_at;not null"`
+	AcceptedAt *time.Time    `json:"acceptedAt,omitempty" gorm:"column:accepted_at"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (UserInvitation) TableName() string {
+	return "user_invitations"
+}
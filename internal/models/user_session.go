@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Why a session was revoked.
+const (
+	SessionRevokedLogout    = "logout"
+	SessionRevokedLogoutAll = "logout_all"
+	SessionRevokedByAdmin   = "admin"
+	SessionRevokedReuse     = "refresh_token_reused"
+	SessionRevokedPassword  = "password_changed"
+)
+
+// UserSession is one sign-in of a user. Access tokens carry its ID, so
+// revoking the session ends them at once; refresh tokens extend it until
+// ExpiresAt.
+type UserSession struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"userId" gorm:"column:user_id;not null;index:idx_user_sessions_user_id"`
+	UserAgent     string     `json:"userAgent" gorm:"column:user_agent;type:varchar(255)"`
+	IPAddress     string     `json:"ipAddress" gorm:"column:ip_address;type:varchar(64)"`
+	ExpiresAt     time.Time  `json:"expiresAt" gorm:"column:expires_at;not null"`
+	LastUsedAt    *time.Time `json:"lastUsedAt,omitempty" gorm:"column:last_used_at"`
+	RevokedAt     *time.Time `json:"revokedAt,omitempty" gorm:"column:revoked_at;index:idx_user_sessions_user_id"`
+	RevokedReason string     `json:"revokedReason,omitempty" gorm:"column:revoked_reason;type:varchar(50)"`
+
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// Active reports whether the session can still be used at now.
+func (s *UserSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken is one refresh token of a session. Each is used once: a
+// refresh rotates it to a new one, and presenting a used token again revokes
+// the session. Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	SessionID uint       `json:"sessionId" gorm:"column:session_id;not null;index:idx_refresh_tokens_session_id"`
+	TokenHash string     `json:"-" gorm:"column:token_hash;type:varchar(64);not null;uniqueIndex:idx_refresh_tokens_token_hash"`
+	UsedAt    *time.Time `json:"usedAt,omitempty" gorm:"column:used_at"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,121 @@
+package models
+
+import "time"
+
+// WebhookEndpoint is a per-project URL that third-party tools post to. Incoming
+// payloads are verified against Secret, rendered with the endpoint's template
+// or provider adapter and posted to RoomID with the chosen bot.
+type WebhookEndpoint struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	ProjectID uint   `json:"projectId" gorm:"column:project_id;not null;index:idx_webhook_endpoints_project_id"`
+	Name      string `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	// Token is the unguessable path segment of the endpoint URL.
+	Token string `json:"-" gorm:"column:token;type:varchar(64);not null;uniqueIndex:uq_webhook_endpoints_token"`
+	// Provider is an inbound provider, or empty to detect it per request.
+	Provider string `json:"provider" gorm:"column:provider;type:varchar(50)"`
+	// Template renders the raw JSON payload instead of the provider adapter
+	// when set; see inbound.Template.
+	Template string `json:"template" gorm:"column:template;type:text"`
+	// Filters must all match for a request to be delivered.
+	Filters []WebhookFilter `json:"filters" gorm:"column:filters;type:json;serializer:json"`
+	// MinSeverity drops events below warning or critical; empty keeps everything.
+	MinSeverity string `json:"minSeverity" gorm:"column:min_severity;type:varchar(20)"`
+	// DedupWindowMinutes merges identical events within the window into one
+	// follow-up message with a count; 0 disables it. DedupKeys are the
+	// JSONPaths that make events identical, or the whole message when empty.
+	DedupWindowMinutes int      `json:"dedupWindowMinutes" gorm:"column:dedup_window_minutes;not null"`
+	DedupKeys          []string `json:"dedupKeys" gorm:"column:dedup_keys;type:json;serializer:json"`
+	// QuietStart and QuietEnd ("HH:MM" in QuietTimezone) bound the quiet hours,
+	// when non-critical events are held for a digest.
+	QuietStart    string `json:"quietStart" gorm:"column:quiet_start;type:varchar(5)"`
+	QuietEnd      string `json:"quietEnd" gorm:"column:quiet_end;type:varchar(5)"`
+	QuietTimezone string `json:"quietTimezone" gorm:"column:quiet_timezone;type:varchar(64)"`
+	// Alerts hands the events to the alerts subsystem, which groups them by
+	// AlertGroupBy labels (alertname by default) and tracks their state,
+	// instead of posting every request.
+	Alerts       bool     `json:"alerts" gorm:"column:alerts;not null"`
+	AlertGroupBy []string `json:"alertGroupBy" gorm:"column:alert_group_by;type:json;serializer:json"`
+
+	Secret           string `json:"-" gorm:"column:secret;type:text;serializer:secret"`
+	RequireSignature bool   `json:"requireSignature" gorm:"column:require_signature;not null"`
+	BotID            uint   `json:"botId" gorm:"column:bot_id;not null"`
+	RoomID           string `json:"roomId" gorm:"column:room_id;type:varchar(255);not null"`
+	Active           bool   `json:"active" gorm:"column:active;not null"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// WebhookFilter is a condition on a payload field or header; see inbound.Filter.
+type WebhookFilter struct {
+	Path   string   `json:"path"`
+	Op     string   `json:"op"`
+	Values []string `json:"values,omitempty"`
+}
+
+// Delivery results of a webhook request.
+const (
+	WebhookDeliveryDelivered = "delivered" // posted to Chatwork
+	WebhookDeliveryFailed    = "failed"    // parsed but Chatwork rejected the message, or the payload was invalid
+	WebhookDeliveryRejected  = "rejected"  // missing or wrong signature
+	WebhookDeliveryIgnored   = "ignored"   // valid payload that produced no events
+	WebhookDeliveryFiltered  = "filtered"  // dropped by the endpoint's filters or minimum severity
+	WebhookDeliveryMerged    = "merged"    // repeat of an event already sent within the dedup window
+	WebhookDeliveryBuffered  = "buffered"  // held for the quiet hours digest
+	WebhookDeliverySilenced  = "silenced"  // alerts muted by a silence, or repeats of alerts already announced
+)
+
+// WebhookDelivery records one request to an endpoint: what came in, what was
+// rendered and what happened to it.
+type WebhookDelivery struct {
+	ID         uint              `json:"id" gorm:"primaryKey"`
+	EndpointID uint              `json:"endpointId" gorm:"column:endpoint_id;not null;index:idx_webhook_deliveries_endpoint_id"`
+	Provider   string            `json:"provider,omitempty" gorm:"column:provider;type:varchar(50)"`
+	EventType  string            `json:"eventType,omitempty" gorm:"column:event_type;type:varchar(255)"`
+	Headers    map[string]string `json:"headers" gorm:"column:headers;type:json;serializer:json"`
+	Payload    string            `json:"payload" gorm:"column:payload;type:mediumtext"`
+	Rendered   string            `json:"rendered,omitempty" gorm:"column:rendered;type:mediumtext"`
+	Status     string            `json:"status" gorm:"column:status;type:varchar(20);not null"`
+	Error      string            `json:"error,omitempty" gorm:"column:error;type:text"`
+	DurationMs int64             `json:"durationMs" gorm:"column:duration_ms"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Kinds of buffered events.
+const (
+	WebhookBufferDedup  = "dedup"  // counts repeats of an event that was sent
+	WebhookBufferDigest = "digest" // counts events held during quiet hours
+)
+
+// WebhookBufferedEvent aggregates identical events of an endpoint until
+// FlushAt, when the cron job posts the count and removes the row.
+type WebhookBufferedEvent struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	EndpointID uint   `json:"endpointId" gorm:"column:endpoint_id;not null;index:idx_webhook_buffered_events_key"`
+	Kind       string `json:"kind" gorm:"column:kind;type:varchar(20);not null;index:idx_webhook_buffered_events_key"`
+	DedupKey   string `json:"dedupKey" gorm:"column:dedup_key;type:varchar(64);not null;index:idx_webhook_buffered_events_key"`
+	Summary    string `json:"summary" gorm:"column:summary;type:varchar(500);not null"`
+	Severity   string `json:"severity" gorm:"column:severity;type:varchar(20)"`
+	// Count is the number of events merged into the row; for dedup rows the
+	// first event was already sent and is not counted.
+	Count       int       `json:"count" gorm:"column:count;not null"`
+	FirstSeenAt time.Time `json:"firstSeenAt" gorm:"column:first_seen_at;not null"`
+	LastSeenAt  time.Time `json:"lastSeenAt" gorm:"column:last_seen_at;not null"`
+	FlushAt     time.Time `json:"flushAt" gorm:"column:flush_at;not null;index:idx_webhook_buffered_events_flush_at"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (WebhookBufferedEvent) TableName() string {
+	return "webhook_buffered_events"
+}
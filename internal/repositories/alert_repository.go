@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IAlertRepository interface {
+	// FindOpenGroup returns the firing group of an endpoint for a group key,
+	// or nil when there is none.
+	FindOpenGroup(endpointID uint, groupKey string) (*models.AlertGroup, error)
+	// GetGroup loads the group with its alerts.
+	GetGroup(id uint) (*models.AlertGroup, error)
+	// GetGroupByMessage returns the group whose firing message is messageID
+	// in roomID, or nil when there is none.
+	GetGroupByMessage(roomID, messageID string) (*models.AlertGroup, error)
+	// ListGroups returns a project's groups, newest first, with their alerts.
+	// An empty status lists every group.
+	ListGroups(projectID uint, status string, paging *utils.Paging) ([]models.AlertGroup, int64, error)
+	SaveGroup(group *models.AlertGroup) error
+
+	// GetAlertByFingerprint returns nil when the project has no such alert.
+	GetAlertByFingerprint(projectID uint, fingerprint string) (*models.Alert, error)
+	ListAlerts(projectID uint, status string, paging *utils.Paging) ([]models.Alert, int64, error)
+	CountFiring(groupID uint) (int64, error)
+	SaveAlert(alert *models.Alert) error
+
+	// ListSilences returns a project's silences, newest first; with activeAt
+	// set, only those in effect at that time.
+	ListSilences(projectID uint, activeAt *time.Time) ([]models.AlertSilence, error)
+	GetSilence(id uint) (*models.AlertSilence, error)
+	SaveSilence(silence *models.AlertSilence) error
+}
+
+type AlertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+func (r *AlertRepository) FindOpenGroup(endpointID uint, groupKey string) (*models.AlertGroup, error) {
+	var groups []models.AlertGroup
+	if err := r.db.
+		Where("endpoint_id = ? AND group_key = ? AND status = ?", endpointID, groupKey, models.AlertFiring).
+		Order("id DESC").Limit(1).
+		Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	return &groups[0], nil
+}
+
+func (r *AlertRepository) GetGroup(id uint) (*models.AlertGroup, error) {
+	var group models.AlertGroup
+	if err := r.db.Preload("Alerts").First(&group, id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *AlertRepository) GetGroupByMessage(roomID, messageID string) (*models.AlertGroup, error) {
+	var groups []models.AlertGroup
+	if err := r.db.
+		Where("room_id = ? AND message_id = ?", roomID, messageID).
+		Limit(1).
+		Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	return &groups[0], nil
+}
+
+func (r *AlertRepository) ListGroups(projectID uint, status string, paging *utils.Paging) ([]models.AlertGroup, int64, error) {
+	var groups []models.AlertGroup
+	q := r.db.Model(&models.AlertGroup{}).Where("project_id = ?", projectID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Preload("Alerts").Order("id DESC").Offset(offset).Limit(paging.Limit).Find(&groups).Error; err != nil {
+		return nil, 0, err
+	}
+	return groups, total, nil
+}
+
+func (r *AlertRepository) SaveGroup(group *models.AlertGroup) error {
+	return r.db.Omit("Alerts").Save(group).Error
+}
+
+func (r *AlertRepository) GetAlertByFingerprint(projectID uint, fingerprint string) (*models.Alert, error) {
+	var alerts []models.Alert
+	if err := r.db.
+		Where("project_id = ? AND fingerprint = ?", projectID, fingerprint).
+		Limit(1).
+		Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+	return &alerts[0], nil
+}
+
+func (r *AlertRepository) ListAlerts(projectID uint, status string, paging *utils.Paging) ([]models.Alert, int64, error) {
+	var alerts []models.Alert
+	q := r.db.Model(&models.Alert{}).Where("project_id = ?", projectID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Order("last_seen_at DESC, id DESC").Offset(offset).Limit(paging.Limit).Find(&alerts).Error; err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
+
+func (r *AlertRepository) CountFiring(groupID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Alert{}).
+		Where("group_id = ? AND status = ?", groupID, models.AlertFiring).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *AlertRepository) SaveAlert(alert *models.Alert) error {
+	return r.db.Save(alert).Error
+}
+
+func (r *AlertRepository) ListSilences(projectID uint, activeAt *time.Time) ([]models.AlertSilence, error) {
+	var silences []models.AlertSilence
+	q := r.db.Where("project_id = ?", projectID)
+	if activeAt != nil {
+		q = q.Where("starts_at <= ? AND ends_at > ?", *activeAt, *activeAt)
+	}
+	if err := q.Order("id DESC").Find(&silences).Error; err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+func (r *AlertRepository) GetSilence(id uint) (*models.AlertSilence, error) {
+	var silence models.AlertSilence
+	if err := r.db.First(&silence, id).Error; err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+func (r *AlertRepository) SaveSilence(silence *models.AlertSilence) error {
+	return r.db.Save(silence).Error
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IBotRequestRepository interface {
+	// Get returns the stored request, or nil when it has never been seen.
+	Get(botID uint, requestID int64) (*models.BotRequest, error)
+	ListPending(botID uint) ([]models.BotRequest, error)
+	List(filter BotRequestFilter, paging *utils.Paging) ([]models.BotRequest, int64, error)
+	Save(request *models.BotRequest) error
+}
+
+// BotRequestFilter narrows the request history; zero values match everything.
+type BotRequestFilter struct {
+	BotID  uint
+	Status string
+}
+
+type BotRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewBotRequestRepository(db *gorm.DB) *BotRequestRepository {
+	return &BotRequestRepository{db: db}
+}
+
+func (r *BotRequestRepository) Get(botID uint, requestID int64) (*models.BotRequest, error) {
+	var requests []models.BotRequest
+	if err := r.db.Where("bot_id = ? AND request_id = ?", botID, requestID).Limit(1).Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	return &requests[0], nil
+}
+
+func (r *BotRequestRepository) ListPending(botID uint) ([]models.BotRequest, error) {
+	var requests []models.BotRequest
+	if err := r.db.Where("bot_id = ? AND status = ?", botID, models.BotRequestPending).Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *BotRequestRepository) List(filter BotRequestFilter, paging *utils.Paging) ([]models.BotRequest, int64, error) {
+	var requests []models.BotRequest
+	q := r.db.Model(&models.BotRequest{})
+	if filter.BotID != 0 {
+		q = q.Where("bot_id = ?", filter.BotID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Order("first_seen_at DESC, id DESC").Offset(offset).Limit(paging.Limit).Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+func (r *BotRequestRepository) Save(request *models.BotRequest) error {
+	return r.db.Save(request).Error
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IBotRequestRuleRepository interface {
+	List() ([]models.BotRequestRule, error)
+	// ListActive returns the active rules in evaluation order.
+	ListActive() ([]models.BotRequestRule, error)
+	GetByID(id uint) (*models.BotRequestRule, error)
+	Create(rule *models.BotRequestRule) (*models.BotRequestRule, error)
+	Update(rule *models.BotRequestRule) (*models.BotRequestRule, error)
+	Delete(id uint) error
+
+	CreateAudit(entry *models.BotRequestAudit) error
+	ListAudits(filter BotRequestAuditFilter, paging *utils.Paging) ([]models.BotRequestAudit, int64, error)
+	// LatestAudit returns the newest audit entry of a request, or nil when there is none.
+	LatestAudit(botID uint, requestID int64) (*models.BotRequestAudit, error)
+}
+
+// BotRequestAuditFilter narrows the audit trail; zero values match everything.
+type BotRequestAuditFilter struct {
+	BotID    uint
+	Decision string
+	Source   string
+}
+
+type BotRequestRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewBotRequestRuleRepository(db *gorm.DB) *BotRequestRuleRepository {
+	return &BotRequestRuleRepository{db: db}
+}
+
+func (r *BotRequestRuleRepository) List() ([]models.BotRequestRule, error) {
+	var rules []models.BotRequestRule
+	if err := r.db.Order("priority ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *BotRequestRuleRepository) ListActive() ([]models.BotRequestRule, error) {
+	var rules []models.BotRequestRule
+	if err := r.db.Where("active = ?", true).Order("priority ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *BotRequestRuleRepository) GetByID(id uint) (*models.BotRequestRule, error) {
+	var rule models.BotRequestRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *BotRequestRuleRepository) Create(rule *models.BotRequestRule) (*models.BotRequestRule, error) {
+	if err := r.db.Create(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *BotRequestRuleRepository) Update(rule *models.BotRequestRule) (*models.BotRequestRule, error) {
+	if err := r.db.Save(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *BotRequestRuleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.BotRequestRule{}, id).Error
+}
+
+func (r *BotRequestRuleRepository) CreateAudit(entry *models.BotRequestAudit) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *BotRequestRuleRepository) ListAudits(filter BotRequestAuditFilter, paging *utils.Paging) ([]models.BotRequestAudit, int64, error) {
+	var entries []models.BotRequestAudit
+	q := r.db.Model(&models.BotRequestAudit{})
+	if filter.BotID != 0 {
+		q = q.Where("bot_id = ?", filter.BotID)
+	}
+	if filter.Decision != "" {
+		q = q.Where("decision = ?", filter.Decision)
+	}
+	if filter.Source != "" {
+		q = q.Where("source = ?", filter.Source)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Order("id DESC").Offset(offset).Limit(paging.Limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+func (r *BotRequestRuleRepository) LatestAudit(botID uint, requestID int64) (*models.BotRequestAudit, error) {
+	var entries []models.BotRequestAudit
+	if err := r.db.Where("bot_id = ? AND request_id = ?", botID, requestID).Order("id DESC").Limit(1).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
@@ -11,6 +11,13 @@ type IChatworkBotRepository interface {
 	GetByID(id uint) (*models.ChatworkBot, error)
 	Create(bot *models.ChatworkBot) (*models.ChatworkBot, error)
 	Delete(id uint) error
+	ListAll() ([]models.ChatworkBot, error)
+	// UpdateProfile stores the cached profile and sync status columns.
+	UpdateProfile(bot *models.ChatworkBot) error
+	UpdateHealth(bot *models.ChatworkBot) error
+	// RotateToken swaps the token, profile and health columns in a single UPDATE.
+	RotateToken(bot *models.ChatworkBot) error
+	UpdateWebhookToken(bot *models.ChatworkBot) error
 }
 
 type ChatworkBotRepository struct {
@@ -55,3 +62,35 @@ func (r *ChatworkBotRepository) Create(bot *models.ChatworkBot) (*models.Chatwor
 func (r *ChatworkBotRepository) Delete(id uint) error {
 	return r.db.Delete(&models.ChatworkBot{}, id).Error
 }
+
+func (r *ChatworkBotRepository) ListAll() ([]models.ChatworkBot, error) {
+	var bots []models.ChatworkBot
+	if err := r.db.Order("id ASC").Find(&bots).Error; err != nil {
+		return nil, err
+	}
+	return bots, nil
+}
+
+func (r *ChatworkBotRepository) UpdateProfile(bot *models.ChatworkBot) error {
+	return r.db.Model(&models.ChatworkBot{ID: bot.ID}).
+		Select("account_id", "chatwork_id", "name", "avatar_url", "rooms_count", "synced_at", "sync_error").
+		Updates(bot).Error
+}
+
+func (r *ChatworkBotRepository) UpdateHealth(bot *models.ChatworkBot) error {
+	return r.db.Model(&models.ChatworkBot{ID: bot.ID}).
+		Select("health_status", "health_checked_at", "health_error").
+		Updates(bot).Error
+}
+
+func (r *ChatworkBotRepository) RotateToken(bot *models.ChatworkBot) error {
+	return r.db.Model(&models.ChatworkBot{ID: bot.ID}).
+		Select("api_token", "account_id", "chatwork_id", "name", "avatar_url", "health_status", "health_checked_at", "health_error").
+		Updates(bot).Error
+}
+
+func (r *ChatworkBotRepository) UpdateWebhookToken(bot *models.ChatworkBot) error {
+	return r.db.Model(&models.ChatworkBot{ID: bot.ID}).
+		Select("webhook_token").
+		Updates(bot).Error
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IChatworkBotRoomRepository interface {
+	// ReplaceRooms swaps the bot's cached rooms and members for a fresh snapshot.
+	ReplaceRooms(botID uint, rooms []models.ChatworkBotRoom, members []models.ChatworkBotRoomMember) error
+	ListRooms(botID uint, search, roomType string, paging *utils.Paging) ([]models.ChatworkBotRoom, int64, error)
+	GetRoom(botID uint, roomID int64) (*models.ChatworkBotRoom, error)
+	ListMembers(botID uint, roomID int64) ([]models.ChatworkBotRoomMember, error)
+	DeleteByBot(botID uint) error
+}
+
+type ChatworkBotRoomRepository struct {
+	db *gorm.DB
+}
+
+func NewChatworkBotRoomRepository(db *gorm.DB) *ChatworkBotRoomRepository {
+	return &ChatworkBotRoomRepository{db: db}
+}
+
+func (r *ChatworkBotRoomRepository) ReplaceRooms(botID uint, rooms []models.ChatworkBotRoom, members []models.ChatworkBotRoomMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bot_id = ?", botID).Delete(&models.ChatworkBotRoomMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bot_id = ?", botID).Delete(&models.ChatworkBotRoom{}).Error; err != nil {
+			return err
+		}
+		if len(rooms) > 0 {
+			if err := tx.CreateInBatches(rooms, 200).Error; err != nil {
+				return err
+			}
+		}
+		if len(members) > 0 {
+			if err := tx.CreateInBatches(members, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ChatworkBotRoomRepository) ListRooms(botID uint, search, roomType string, paging *utils.Paging) ([]models.ChatworkBotRoom, int64, error) {
+	var rooms []models.ChatworkBotRoom
+	q := r.db.Model(&models.ChatworkBotRoom{}).Where("bot_id = ?", botID)
+	if search != "" {
+		q = q.Where("name LIKE ?", "%"+search+"%")
+	}
+	if roomType != "" {
+		q = q.Where("type = ?", roomType)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Order("name ASC, room_id ASC").Offset(offset).Limit(paging.Limit).Find(&rooms).Error; err != nil {
+		return nil, 0, err
+	}
+	return rooms, total, nil
+}
+
+func (r *ChatworkBotRoomRepository) GetRoom(botID uint, roomID int64) (*models.ChatworkBotRoom, error) {
+	var room models.ChatworkBotRoom
+	if err := r.db.Where("bot_id = ? AND room_id = ?", botID, roomID).First(&room).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *ChatworkBotRoomRepository) ListMembers(botID uint, roomID int64) ([]models.ChatworkBotRoomMember, error) {
+	var members []models.ChatworkBotRoomMember
+	if err := r.db.Where("bot_id = ? AND room_id = ?", botID, roomID).Order("name ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *ChatworkBotRoomRepository) DeleteByBot(botID uint) error {
+	return r.ReplaceRooms(botID, nil, nil)
+}
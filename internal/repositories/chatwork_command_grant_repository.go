@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"gorm.io/gorm"
+)
+
+type IChatworkCommandGrantRepository interface {
+	GetByProjectID(projectID uint) ([]models.ChatworkCommandGrant, error)
+	GetByRoomID(roomID string) ([]models.ChatworkCommandGrant, error)
+	GetByID(id uint) (*models.ChatworkCommandGrant, error)
+	Create(grant *models.ChatworkCommandGrant) (*models.ChatworkCommandGrant, error)
+	Update(grant *models.ChatworkCommandGrant) (*models.ChatworkCommandGrant, error)
+	Delete(id uint) error
+}
+
+type ChatworkCommandGrantRepository struct {
+	db *gorm.DB
+}
+
+func NewChatworkCommandGrantRepository(db *gorm.DB) *ChatworkCommandGrantRepository {
+	return &ChatworkCommandGrantRepository{db: db}
+}
+
+func (r *ChatworkCommandGrantRepository) GetByProjectID(projectID uint) ([]models.ChatworkCommandGrant, error) {
+	var grants []models.ChatworkCommandGrant
+	if err := r.db.Where("project_id = ?", projectID).Order("id ASC").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (r *ChatworkCommandGrantRepository) GetByRoomID(roomID string) ([]models.ChatworkCommandGrant, error) {
+	var grants []models.ChatworkCommandGrant
+	if err := r.db.Where("room_id = ?", roomID).Order("project_id ASC, id ASC").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (r *ChatworkCommandGrantRepository) GetByID(id uint) (*models.ChatworkCommandGrant, error) {
+	var grant models.ChatworkCommandGrant
+	if err := r.db.First(&grant, id).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *ChatworkCommandGrantRepository) Create(grant *models.ChatworkCommandGrant) (*models.ChatworkCommandGrant, error) {
+	if err := r.db.Create(grant).Error; err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (r *ChatworkCommandGrantRepository) Update(grant *models.ChatworkCommandGrant) (*models.ChatworkCommandGrant, error) {
+	if err := r.db.Save(grant).Error; err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (r *ChatworkCommandGrantRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ChatworkCommandGrant{}, id).Error
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICpeFindingRepository interface {
+	// CreateIfNotExists inserts the finding unless the same CVE is already recorded
+	// for the tech stack entry. It reports whether a new row was created.
+	CreateIfNotExists(finding *models.CpeFinding) (bool, error)
+	GetByProjectID(projectID uint, paging *utils.Paging) ([]models.CpeFinding, int64, error)
+	MarkNotified(ids []uint, at time.Time) error
+	DeleteByTechStackID(techStackID uint) error
+}
+
+type CpeFindingRepository struct {
+	db *gorm.DB
+}
+
+func NewCpeFindingRepository(db *gorm.DB) *CpeFindingRepository {
+	return &CpeFindingRepository{db: db}
+}
+
+func (repo *CpeFindingRepository) CreateIfNotExists(finding *models.CpeFinding) (bool, error) {
+	result := repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(finding)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (repo *CpeFindingRepository) GetByProjectID(projectID uint, paging *utils.Paging) ([]models.CpeFinding, int64, error) {
+	var findings []models.CpeFinding
+
+	q := repo.db.Model(&models.CpeFinding{}).Where("project_id = ?", projectID)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Order("score DESC, created_at DESC").Offset(offset).Limit(paging.Limit).Find(&findings).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return findings, total, nil
+}
+
+func (repo *CpeFindingRepository) MarkNotified(ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return repo.db.Model(&models.CpeFinding{}).Where("id IN ?", ids).Update("notified_at", at).Error
+}
+
+func (repo *CpeFindingRepository) DeleteByTechStackID(techStackID uint) error {
+	return repo.db.Where("tech_stack_id = ?", techStackID).Delete(&models.CpeFinding{}).Error
+}
@@ -17,6 +17,8 @@ type ICveConfigRepository interface {
 	GetVulnerabilitiesByConfigID(configID string) ([]models.Vulnerability, int64, error)
 	UpsertVulnerability(vuln *models.Vulnerability) error
 	DeleteVulnerabilitiesByConfigID(configID string) error
+	GetEmailDigestConfigs() ([]models.CveConfig, error)
+	GetByBotID(botID uint) ([]models.CveConfig, error)
 }
 
 type CveConfigRepository struct {
@@ -128,3 +130,17 @@ func (repo *CveConfigRepository) UpsertVulnerability(vuln *models.Vulnerability)
 func (repo *CveConfigRepository) DeleteVulnerabilitiesByConfigID(configID string) error {
 	return repo.db.Where("config_id = ?", configID).Delete(&models.Vulnerability{}).Error
 }
+
+// GetEmailDigestConfigs returns active configs subscribed to the daily crawler digest.
+func (repo *CveConfigRepository) GetEmailDigestConfigs() ([]models.CveConfig, error) {
+	var configs []models.CveConfig
+	err := repo.db.Where("email_digest = ? AND status = ?", true, "active").Find(&configs).Error
+	return configs, err
+}
+
+// GetByBotID returns the configs that notify through the given bot.
+func (repo *CveConfigRepository) GetByBotID(botID uint) ([]models.CveConfig, error) {
+	var configs []models.CveConfig
+	err := repo.db.Where("bot_id = ?", botID).Order("created_at ASC").Find(&configs).Error
+	return configs, err
+}
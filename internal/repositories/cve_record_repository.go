@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CveSearchFilter holds the optional criteria of a local CVE search.
+type CveSearchFilter struct {
+	Query    string
+	Cwe      string
+	Vendor   string
+	Product  string
+	Severity string
+	MinScore *float64
+	MaxScore *float64
+	From     *time.Time
+	To       *time.Time
+}
+
+type ICveRecordRepository interface {
+	Upsert(record *models.CveRecord) error
+	GetByCveID(cveID string) (*models.CveRecord, error)
+	GetByCveIDs(cveIDs []string) ([]models.CveRecord, error)
+	GetByProduct(vendor, product string) ([]models.CveRecord, error)
+	Search(filter *CveSearchFilter, paging *utils.Paging) ([]models.CveRecord, int64, error)
+	GetFacets(filter *CveSearchFilter, limit int) (*models.CveSearchFacets, error)
+}
+
+type CveRecordRepository struct {
+	db *gorm.DB
+}
+
+func NewCveRecordRepository(db *gorm.DB) *CveRecordRepository {
+	return &CveRecordRepository{db: db}
+}
+
+// Upsert inserts or refreshes a CVE record and replaces its CWE and CPE rows.
+func (repo *CveRecordRepository) Upsert(record *models.CveRecord) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "cve_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"description", "severity", "base_score", "cvss_version", "vector_string", "metrics", "references", "published_at", "last_modified_at", "updated_at"}),
+			}).
+			Create(record).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("cve_id = ?", record.CveID).Delete(&models.CveRecordCwe{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("cve_id = ?", record.CveID).Delete(&models.CveRecordCpe{}).Error; err != nil {
+			return err
+		}
+
+		for i := range record.Cwes {
+			record.Cwes[i].ID = 0
+			record.Cwes[i].CveID = record.CveID
+		}
+		for i := range record.Cpes {
+			record.Cpes[i].ID = 0
+			record.Cpes[i].CveID = record.CveID
+		}
+
+		if len(record.Cwes) > 0 {
+			if err := tx.Create(&record.Cwes).Error; err != nil {
+				return err
+			}
+		}
+		if len(record.Cpes) > 0 {
+			if err := tx.CreateInBatches(&record.Cpes, 100).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (repo *CveRecordRepository) GetByCveID(cveID string) (*models.CveRecord, error) {
+	var record models.CveRecord
+	if err := repo.db.Preload("Cwes").Preload("Cpes").First(&record, "cve_id = ?", cveID).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (repo *CveRecordRepository) GetByCveIDs(cveIDs []string) ([]models.CveRecord, error) {
+	var records []models.CveRecord
+	if len(cveIDs) == 0 {
+		return records, nil
+	}
+	if err := repo.db.Preload("Cwes").Preload("Cpes").Where("cve_id IN ?", cveIDs).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetByProduct returns every stored CVE with a CPE criterion for the vendor/product.
+func (repo *CveRecordRepository) GetByProduct(vendor, product string) ([]models.CveRecord, error) {
+	var records []models.CveRecord
+	sub := repo.db.Model(&models.CveRecordCpe{}).Select("cve_id").
+		Where("vendor = ? AND product = ?", strings.ToLower(vendor), strings.ToLower(product))
+	if err := repo.db.Preload("Cpes").Where("cve_id IN (?)", sub).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (repo *CveRecordRepository) Search(filter *CveSearchFilter, paging *utils.Paging) ([]models.CveRecord, int64, error) {
+	var records []models.CveRecord
+
+	q := repo.applyFilter(repo.db.Model(&models.CveRecord{}), filter)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Preload("Cwes").Preload("Cpes").
+		Order("published_at DESC").
+		Offset(offset).Limit(paging.Limit).
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// GetFacets returns severity, CWE, vendor and product counts over the filtered set.
+func (repo *CveRecordRepository) GetFacets(filter *CveSearchFilter, limit int) (*models.CveSearchFacets, error) {
+	facets := &models.CveSearchFacets{}
+
+	matching := repo.applyFilter(repo.db.Model(&models.CveRecord{}), filter).Select("cve_records.cve_id")
+
+	if err := repo.db.Model(&models.CveRecord{}).
+		Select("severity as value, COUNT(*) as count").
+		Where("cve_id IN (?)", matching).
+		Group("severity").
+		Order("count DESC").
+		Scan(&facets.Severity).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Model(&models.CveRecordCwe{}).
+		Select("cwe_id as value, COUNT(DISTINCT cve_id) as count").
+		Where("cve_id IN (?)", matching).
+		Group("cwe_id").
+		Order("count DESC").
+		Limit(limit).
+		Scan(&facets.Cwe).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Model(&models.CveRecordCpe{}).
+		Select("vendor as value, COUNT(DISTINCT cve_id) as count").
+		Where("cve_id IN (?) AND vendor <> ''", matching).
+		Group("vendor").
+		Order("count DESC").
+		Limit(limit).
+		Scan(&facets.Vendor).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Model(&models.CveRecordCpe{}).
+		Select("product as value, COUNT(DISTINCT cve_id) as count").
+		Where("cve_id IN (?) AND product <> ''", matching).
+		Group("product").
+		Order("count DESC").
+		Limit(limit).
+		Scan(&facets.Product).Error; err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+func (repo *CveRecordRepository) applyFilter(q *gorm.DB, filter *CveSearchFilter) *gorm.DB {
+	if filter == nil {
+		return q
+	}
+
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		if terms := fullTextTerms(query); terms != "" {
+			q = q.Where("(MATCH(cve_records.description) AGAINST (? IN BOOLEAN MODE) OR cve_records.cve_id = ?)", terms, strings.ToUpper(query))
+		} else {
+			q = q.Where("cve_records.cve_id = ?", strings.ToUpper(query))
+		}
+	}
+	if filter.Severity != "" {
+		q = q.Where("cve_records.severity = ?", strings.ToUpper(filter.Severity))
+	}
+	if filter.MinScore != nil {
+		q = q.Where("cve_records.base_score >= ?", *filter.MinScore)
+	}
+	if filter.MaxScore != nil {
+		q = q.Where("cve_records.base_score <= ?", *filter.MaxScore)
+	}
+	if filter.From != nil {
+		q = q.Where("cve_records.published_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("cve_records.published_at <= ?", *filter.To)
+	}
+	if filter.Cwe != "" {
+		q = q.Where("cve_records.cve_id IN (?)", repo.db.Model(&models.CveRecordCwe{}).Select("cve_id").Where("cwe_id = ?", strings.ToUpper(filter.Cwe)))
+	}
+	if filter.Vendor != "" || filter.Product != "" {
+		sub := repo.db.Model(&models.CveRecordCpe{}).Select("cve_id")
+		if filter.Vendor != "" {
+			sub = sub.Where("vendor = ?", strings.ToLower(filter.Vendor))
+		}
+		if filter.Product != "" {
+			sub = sub.Where("product = ?", strings.ToLower(filter.Product))
+		}
+		q = q.Where("cve_records.cve_id IN (?)", sub)
+	}
+
+	return q
+}
+
+// fullTextTerms turns a user query into a boolean-mode search for any of its
+// words. Boolean operators are dropped, since unbalanced quotes or brackets
+// and dangling operators are syntax errors in MySQL.
+func fullTextTerms(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`+-<>()~*"@`, r)
+	})
+	return strings.Join(words, " ")
+}
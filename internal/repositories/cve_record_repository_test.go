@@ -0,0 +1,21 @@
+package repositories
+
+import "testing"
+
+func TestFullTextTerms(t *testing.T) {
+	for query, want := range map[string]string{
+		"buffer overflow":      "buffer overflow",
+		`"remote code`:         "remote code",
+		"(log4j":               "log4j",
+		"overflow*":            "overflow",
+		"heap -":               "heap",
+		"+apache ~struts @2":   "apache struts 2",
+		"CVE-2021-44228":       "CVE 2021 44228",
+		`*"()-`:                "",
+		"  spaced\tout\nterm ": "spaced out term",
+	} {
+		if got := fullTextTerms(query); got != want {
+			t.Errorf("fullTextTerms(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
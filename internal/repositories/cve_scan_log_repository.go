@@ -153,9 +153,5 @@ func (repo *CveScanLogRepository) GetRecentScans(limit int) ([]RecentScanResult,
 		return nil, 0, err
 	}
 
-	for i := range results {
-		results[i].LastScan = results[i].LastScan
-	}
-
 	return results, total, nil
 }
@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IEscalationRepository interface {
+	GetRotations(projectID uint) ([]models.OnCallRotation, error)
+	// GetRotation loads the rotation with its overrides that haven't ended
+	// by `since`.
+	GetRotation(id uint, since time.Time) (*models.OnCallRotation, error)
+	SaveRotation(rotation *models.OnCallRotation) error
+	// DeleteRotation also deletes its overrides.
+	DeleteRotation(id uint) error
+	GetOverride(id uint) (*models.OnCallOverride, error)
+	CreateOverride(override *models.OnCallOverride) error
+	DeleteOverride(id uint) error
+
+	GetPolicies(projectID uint) ([]models.EscalationPolicy, error)
+	GetPolicy(id uint) (*models.EscalationPolicy, error)
+	SavePolicy(policy *models.EscalationPolicy) error
+	DeletePolicy(id uint) error
+
+	// FindOpen returns the open escalation of a project for sourceRef, or nil.
+	FindOpen(projectID uint, sourceRef string) (*models.Escalation, error)
+	// GetEscalation loads the escalation with its notifications.
+	GetEscalation(id uint) (*models.Escalation, error)
+	// GetEscalationByMessage returns the escalation that posted messageID in
+	// roomID, or nil when there is none.
+	GetEscalationByMessage(roomID, messageID string) (*models.Escalation, error)
+	// ListEscalations returns a project's escalations, newest first. An empty
+	// status lists every escalation.
+	ListEscalations(projectID uint, status string, paging *utils.Paging) ([]models.Escalation, int64, error)
+	// ListDue returns the open escalations whose next level is due at now.
+	ListDue(now time.Time) ([]models.Escalation, error)
+	SaveEscalation(escalation *models.Escalation) error
+	CreateNotification(notification *models.EscalationNotification) error
+}
+
+type EscalationRepository struct {
+	db *gorm.DB
+}
+
+func NewEscalationRepository(db *gorm.DB) *EscalationRepository {
+	return &EscalationRepository{db: db}
+}
+
+func (r *EscalationRepository) GetRotations(projectID uint) ([]models.OnCallRotation, error) {
+	var rotations []models.OnCallRotation
+	if err := r.db.Where("project_id = ?", projectID).Order("id ASC").Find(&rotations).Error; err != nil {
+		return nil, err
+	}
+	return rotations, nil
+}
+
+func (r *EscalationRepository) GetRotation(id uint, since time.Time) (*models.OnCallRotation, error) {
+	var rotation models.OnCallRotation
+	err := r.db.
+		Preload("Overrides", func(db *gorm.DB) *gorm.DB {
+			return db.Where("ends_at > ?", since).Order("starts_at ASC, id ASC")
+		}).
+		First(&rotation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rotation, nil
+}
+
+func (r *EscalationRepository) SaveRotation(rotation *models.OnCallRotation) error {
+	return r.db.Omit("Overrides").Save(rotation).Error
+}
+
+func (r *EscalationRepository) DeleteRotation(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rotation_id = ?", id).Delete(&models.OnCallOverride{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.OnCallRotation{}, id).Error
+	})
+}
+
+func (r *EscalationRepository) GetOverride(id uint) (*models.OnCallOverride, error) {
+	var override models.OnCallOverride
+	if err := r.db.First(&override, id).Error; err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (r *EscalationRepository) CreateOverride(override *models.OnCallOverride) error {
+	return r.db.Create(override).Error
+}
+
+func (r *EscalationRepository) DeleteOverride(id uint) error {
+	return r.db.Delete(&models.OnCallOverride{}, id).Error
+}
+
+func (r *EscalationRepository) GetPolicies(projectID uint) ([]models.EscalationPolicy, error) {
+	var policies []models.EscalationPolicy
+	if err := r.db.Where("project_id = ?", projectID).Order("id ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *EscalationRepository) GetPolicy(id uint) (*models.EscalationPolicy, error) {
+	var policy models.EscalationPolicy
+	if err := r.db.First(&policy, id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *EscalationRepository) SavePolicy(policy *models.EscalationPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r *EscalationRepository) DeletePolicy(id uint) error {
+	return r.db.Delete(&models.EscalationPolicy{}, id).Error
+}
+
+func (r *EscalationRepository) FindOpen(projectID uint, sourceRef string) (*models.Escalation, error) {
+	var escalations []models.Escalation
+	if err := r.db.
+		Where("project_id = ? AND source_ref = ? AND status = ?", projectID, sourceRef, models.EscalationOpen).
+		Order("id DESC").Limit(1).
+		Find(&escalations).Error; err != nil {
+		return nil, err
+	}
+	if len(escalations) == 0 {
+		return nil, nil
+	}
+	return &escalations[0], nil
+}
+
+func (r *EscalationRepository) GetEscalation(id uint) (*models.Escalation, error) {
+	var escalation models.Escalation
+	err := r.db.
+		Preload("Notifications", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&escalation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &escalation, nil
+}
+
+func (r *EscalationRepository) GetEscalationByMessage(roomID, messageID string) (*models.Escalation, error) {
+	var notifications []models.EscalationNotification
+	if err := r.db.
+		Where("room_id = ? AND message_id = ?", roomID, messageID).
+		Limit(1).
+		Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+	return r.GetEscalation(notifications[0].EscalationID)
+}
+
+func (r *EscalationRepository) ListEscalations(projectID uint, status string, paging *utils.Paging) ([]models.Escalation, int64, error) {
+	var escalations []models.Escalation
+	q := r.db.Model(&models.Escalation{}).Where("project_id = ?", projectID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Order("id DESC").Offset(offset).Limit(paging.Limit).Find(&escalations).Error; err != nil {
+		return nil, 0, err
+	}
+	return escalations, total, nil
+}
+
+func (r *EscalationRepository) ListDue(now time.Time) ([]models.Escalation, error) {
+	var escalations []models.Escalation
+	if err := r.db.
+		Where("status = ? AND next_at <= ?", models.EscalationOpen, now).
+		Order("next_at ASC").
+		Find(&escalations).Error; err != nil {
+		return nil, err
+	}
+	return escalations, nil
+}
+
+func (r *EscalationRepository) SaveEscalation(escalation *models.Escalation) error {
+	return r.db.Omit("Notifications").Save(escalation).Error
+}
+
+func (r *EscalationRepository) CreateNotification(notification *models.EscalationNotification) error {
+	return r.db.Create(notification).Error
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"gorm.io/gorm"
+)
+
+type INotificationChannelRepository interface {
+	GetByProjectID(projectID uint) ([]models.NotificationChannel, error)
+	GetByID(id uint) (*models.NotificationChannel, error)
+	Create(channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	Update(channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	Delete(id uint) error
+}
+
+type NotificationChannelRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationChannelRepository(db *gorm.DB) *NotificationChannelRepository {
+	return &NotificationChannelRepository{db: db}
+}
+
+func (repo *NotificationChannelRepository) GetByProjectID(projectID uint) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	if err := repo.db.Where("project_id = ?", projectID).Order("id ASC").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+func (repo *NotificationChannelRepository) GetByID(id uint) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	if err := repo.db.First(&channel, id).Error; err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (repo *NotificationChannelRepository) Create(channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	if err := repo.db.Create(channel).Error; err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (repo *NotificationChannelRepository) Update(channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	if err := repo.db.Save(channel).Error; err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (repo *NotificationChannelRepository) Delete(id uint) error {
+	return repo.db.Delete(&models.NotificationChannel{}, id).Error
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"gorm.io/gorm"
+)
+
+type IOIDCRepository interface {
+	CreateAuthRequest(request *models.OIDCAuthRequest) error
+	// TakeAuthRequest deletes and returns the request with the state hash, or
+	// nil when there is none, so each request completes at most once.
+	TakeAuthRequest(stateHash string) (*models.OIDCAuthRequest, error)
+	DeleteExpiredAuthRequests(now time.Time) error
+
+	// GetGroupMappings lists the mappings by group, then project.
+	GetGroupMappings() ([]models.OIDCGroupMapping, error)
+	GetGroupMapping(id uint) (*models.OIDCGroupMapping, error)
+	SaveGroupMapping(mapping *models.OIDCGroupMapping) error
+	DeleteGroupMapping(id uint) error
+}
+
+type OIDCRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+func (r *OIDCRepository) CreateAuthRequest(request *models.OIDCAuthRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *OIDCRepository) TakeAuthRequest(stateHash string) (*models.OIDCAuthRequest, error) {
+	var taken *models.OIDCAuthRequest
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var requests []models.OIDCAuthRequest
+		if err := tx.Where("state_hash = ?", stateHash).Limit(1).Find(&requests).Error; err != nil {
+			return err
+		}
+		if len(requests) == 0 {
+			return nil
+		}
+		// Only one of two concurrent callbacks with the same state wins
+		res := tx.Delete(&models.OIDCAuthRequest{}, requests[0].ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 1 {
+			taken = &requests[0]
+		}
+		return nil
+	})
+	return taken, err
+}
+
+func (r *OIDCRepository) DeleteExpiredAuthRequests(now time.Time) error {
+	return r.db.Where("expires_at <= ?", now).Delete(&models.OIDCAuthRequest{}).Error
+}
+
+func (r *OIDCRepository) GetGroupMappings() ([]models.OIDCGroupMapping, error) {
+	var mappings []models.OIDCGroupMapping
+	if err := r.db.Order("group_name ASC, project_id ASC").Find(&mappings).Error; err != nil {
+		return nil, err
+	}
+	return mappings, nil
+}
+
+func (r *OIDCRepository) GetGroupMapping(id uint) (*models.OIDCGroupMapping, error) {
+	var mapping models.OIDCGroupMapping
+	if err := r.db.First(&mapping, id).Error; err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (r *OIDCRepository) SaveGroupMapping(mapping *models.OIDCGroupMapping) error {
+	return r.db.Save(mapping).Error
+}
+
+func (r *OIDCRepository) DeleteGroupMapping(id uint) error {
+	return r.db.Delete(&models.OIDCGroupMapping{}, id).Error
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"gorm.io/gorm"
+)
+
+type IProjectAPIKeyRepository interface {
+	// GetByProject lists the project's keys, newest first.
+	GetByProject(projectID uint) ([]models.ProjectAPIKey, error)
+	GetByID(id uint) (*models.ProjectAPIKey, error)
+	// GetByHash returns the project's key with the hash, or nil when there is none.
+	GetByHash(projectID uint, keyHash string) (*models.ProjectAPIKey, error)
+	Save(key *models.ProjectAPIKey) error
+	// CreateWithProject creates the project and its first key, in one transaction.
+	CreateWithProject(project *models.Project, key *models.ProjectAPIKey) error
+	// Rotate saves next and sets old to expire at oldExpiresAt, in one transaction.
+	Rotate(old *models.ProjectAPIKey, next *models.ProjectAPIKey, oldExpiresAt time.Time) error
+	// Touch records that the key was used at from ip.
+	Touch(id uint, at time.Time, ip string) error
+}
+
+type ProjectAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewProjectAPIKeyRepository(db *gorm.DB) *ProjectAPIKeyRepository {
+	return &ProjectAPIKeyRepository{db: db}
+}
+
+func (r *ProjectAPIKeyRepository) GetByProject(projectID uint) ([]models.ProjectAPIKey, error) {
+	var keys []models.ProjectAPIKey
+	if err := r.db.Where("project_id = ?", projectID).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *ProjectAPIKeyRepository) GetByID(id uint) (*models.ProjectAPIKey, error) {
+	var key models.ProjectAPIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *ProjectAPIKeyRepository) GetByHash(projectID uint, keyHash string) (*models.ProjectAPIKey, error) {
+	var keys []models.ProjectAPIKey
+	if err := r.db.Where("project_id = ? AND key_hash = ?", projectID, keyHash).Limit(1).Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return &keys[0], nil
+}
+
+func (r *ProjectAPIKeyRepository) Save(key *models.ProjectAPIKey) error {
+	return r.db.Save(key).Error
+}
+
+func (r *ProjectAPIKeyRepository) CreateWithProject(project *models.Project, key *models.ProjectAPIKey) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		key.ProjectID = uint(project.ID)
+		return tx.Create(key).Error
+	})
+}
+
+func (r *ProjectAPIKeyRepository) Rotate(old *models.ProjectAPIKey, next *models.ProjectAPIKey, oldExpiresAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		old.ExpiresAt = &oldExpiresAt
+		return tx.Model(&models.ProjectAPIKey{}).Where("id = ?", old.ID).Update("expires_at", oldExpiresAt).Error
+	})
+}
+
+func (r *ProjectAPIKeyRepository) Touch(id uint, at time.Time, ip string) error {
+	return r.db.Model(&models.ProjectAPIKey{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
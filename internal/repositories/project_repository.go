@@ -1,8 +1,6 @@
 package repositories
 
 import (
-	"fmt"
-
 	"github.com/vfa-khuongdv/golang-cms/internal/models"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
 	"gorm.io/gorm"
@@ -10,7 +8,9 @@ import (
 
 type IProjectRepository interface {
 	GetAll() (*[]models.Project, error)
-	GetAllV2(status string, paging *utils.Paging) ([]models.Project, int64, error)
+	// GetAllV2 lists the projects userID is a member of, or every project when
+	// userID is 0.
+	GetAllV2(status string, userID uint, paging *utils.Paging) ([]models.Project, int64, error)
 	GetByID(id uint) (*models.Project, error)
 	Create(project *models.Project) (*models.Project, error)
 	Update(project *models.Project) (*models.Project, error)
@@ -62,33 +62,53 @@ func (repo *ProjectRepository) Update(project *models.Project) (*models.Project,
 	return project, nil
 }
 
+// Delete soft-deletes the project. Its schedules, webhook endpoints, API keys
+// and members are removed with it in one transaction; the foreign keys only cascade on a hard
+// delete, which the soft delete never issues.
 func (repo *ProjectRepository) Delete(id uint) error {
-	// Delete all reminder schedules associated with the project
-	var schedules []models.ReminderSchedule
-	if err := repo.db.Where("project_id = ?", id).Find(&schedules).Error; err != nil {
-		fmt.Println("Error finding schedules:", err)
-	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var schedules []models.ReminderSchedule
+		if err := tx.Where("project_id = ?", id).Find(&schedules).Error; err != nil {
+			return err
+		}
+		for _, schedule := range schedules {
+			if err := tx.Delete(&schedule).Error; err != nil {
+				return err
+			}
+		}
 
-	for _, schedule := range schedules {
-		if err := repo.db.Delete(&schedule).Error; err != nil {
-			fmt.Println("Error deleting schedule:", err)
+		endpoints := tx.Model(&models.WebhookEndpoint{}).Select("id").Where("project_id = ?", id)
+		if err := tx.Where("endpoint_id IN (?)", endpoints).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("endpoint_id IN (?)", endpoints).Delete(&models.WebhookBufferedEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", id).Delete(&models.WebhookEndpoint{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", id).Delete(&models.ProjectAPIKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", id).Delete(&models.ProjectMember{}).Error; err != nil {
+			return err
 		}
-	}
 
-	if err := repo.db.Delete(&models.Project{}, id).Error; err != nil {
-		return err
-	}
-	return nil
+		return tx.Delete(&models.Project{}, id).Error
+	})
 }
 
 // GetAllV2 retrieves projects with optional status filter and pagination
-func (repo *ProjectRepository) GetAllV2(status string, paging *utils.Paging) ([]models.Project, int64, error) {
+func (repo *ProjectRepository) GetAllV2(status string, userID uint, paging *utils.Paging) ([]models.Project, int64, error) {
 	var projects []models.Project
 
 	q := repo.db.Model(&models.Project{})
 	if status != "" {
 		q = q.Where("status = ?", status)
 	}
+	if userID != 0 {
+		q = q.Where("id IN (?)", repo.db.Model(&models.ProjectMember{}).Select("project_id").Where("user_id = ?", userID))
+	}
 	q = q.Order("updated_at DESC")
 
 	var total int64
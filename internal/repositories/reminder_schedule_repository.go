@@ -17,6 +17,7 @@ type IReminderScheduleRepository interface {
 	GetActiveSchedules() ([]models.ReminderSchedule, error)
 	UpdateActiveStatus(id uint, active bool) error
 	ExistsByBotID(botID uint) (bool, error)
+	GetByBotID(botID uint) ([]models.ReminderSchedule, error)
 }
 
 type ReminderScheduleRepository struct {
@@ -149,6 +150,13 @@ func (repo *ReminderScheduleRepository) ExistsByBotID(botID uint) (bool, error)
 	return count > 0, nil
 }
 
+// GetByBotID returns the schedules that send with the given bot, with their project.
+func (repo *ReminderScheduleRepository) GetByBotID(botID uint) ([]models.ReminderSchedule, error) {
+	var schedules []models.ReminderSchedule
+	err := repo.db.Preload("Project").Where("bot_id = ?", botID).Order("id ASC").Find(&schedules).Error
+	return schedules, err
+}
+
 // GetByProjectIDPaged retrieves schedules for a project with optional status filter and pagination
 func (repo *ReminderScheduleRepository) GetByProjectIDPaged(projectID uint, status string, paging *utils.Paging) ([]models.ReminderSchedule, int64, error) {
 	var schedules []models.ReminderSchedule
@@ -24,6 +24,9 @@ type IScheduleLogRepository interface {
 	ListBySchedule(scheduleID uint, filters map[string]interface{}, paging *utils.Paging) ([]models.RunLogV2, int64, error)
 	GetV2Summary() (*models.V2DashboardSummary, error)
 	GetAnalysisByProject(projectID uint) ([]ScheduleAnalysisRaw, error)
+	Create(log *models.ScheduleLog) error
+	GetDueFollowUps(now time.Time) ([]models.ScheduleLog, error)
+	UpdateFollowUpStatus(id uint, status string) error
 }
 
 type ScheduleLogRepository struct {
@@ -93,10 +96,28 @@ func (r *ScheduleLogRepository) GetDashboardData() (*models.DashboardData, error
 	return &data, nil
 }
 
+func (r *ScheduleLogRepository) Create(log *models.ScheduleLog) error {
+	return r.db.Create(log).Error
+}
+
+// GetDueFollowUps returns task runs whose follow-up check is pending and due, with their schedule.
+func (r *ScheduleLogRepository) GetDueFollowUps(now time.Time) ([]models.ScheduleLog, error) {
+	var logs []models.ScheduleLog
+	err := r.db.Preload("Schedule").
+		Where("follow_up_status = ? AND follow_up_at <= ?", models.FollowUpPending, now).
+		Order("follow_up_at").
+		Find(&logs).Error
+	return logs, err
+}
+
+func (r *ScheduleLogRepository) UpdateFollowUpStatus(id uint, status string) error {
+	return r.db.Model(&models.ScheduleLog{}).Where("id = ?", id).Update("follow_up_status", status).Error
+}
+
 // buildLogQuery builds a base query for schedule_logs joined with projects and schedules
 func (r *ScheduleLogRepository) buildLogQuery(filters map[string]interface{}) *gorm.DB {
 	q := r.db.Table("schedule_logs sl").
-		Select("sl.id, sl.schedule_id, rs.name as name, p.name as project_name, sl.status, sl.created_at as timestamp, sl.error_message as message").
+		Select("sl.id, sl.schedule_id, rs.name as name, p.name as project_name, sl.status, sl.created_at as timestamp, sl.error_message as message, sl.task_ids, sl.follow_up_status").
 		Joins("LEFT JOIN projects p ON p.id = sl.project_id").
 		Joins("LEFT JOIN reminder_schedules rs ON rs.id = sl.schedule_id").
 		Where("sl.deleted_at IS NULL")
@@ -251,7 +272,6 @@ func (r *ScheduleLogRepository) GetAnalysisByProject(projectID uint) ([]Schedule
 
 	for i := range results {
 		results[i].SuccessRuns = results[i].TotalRuns - results[i].FailedRuns
-		results[i].FailedRuns = results[i].FailedRuns
 		results[i].TotalRuns = results[i].SuccessRuns + results[i].FailedRuns
 	}
 
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type ISessionRepository interface {
+	GetSession(id uint) (*models.UserSession, error)
+	// GetActiveSessions lists unrevoked, unexpired sessions with their users,
+	// newest first. userID 0 lists everyone's.
+	GetActiveSessions(userID uint, now time.Time, paging *utils.Paging) ([]models.UserSession, int64, error)
+	// CreateSession saves the session with its first refresh token.
+	CreateSession(session *models.UserSession, token *models.RefreshToken) error
+	// Touch records that the session was used at.
+	Touch(id uint, at time.Time) error
+	Revoke(id uint, reason string, at time.Time) error
+	// RevokeAll revokes the user's active sessions and returns how many.
+	RevokeAll(userID uint, reason string, at time.Time) (int64, error)
+
+	// GetRefreshToken returns the token with the hash, or nil when there is none.
+	GetRefreshToken(tokenHash string) (*models.RefreshToken, error)
+	// RotateRefreshToken marks old used and saves next, unless old was used
+	// already, in which case it returns false and saves nothing.
+	RotateRefreshToken(old *models.RefreshToken, next *models.RefreshToken, at time.Time) (bool, error)
+}
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) GetSession(id uint) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) GetActiveSessions(userID uint, now time.Time, paging *utils.Paging) ([]models.UserSession, int64, error) {
+	var sessions []models.UserSession
+	q := r.db.Model(&models.UserSession{}).Where("revoked_at IS NULL AND expires_at > ?", now)
+	if userID != 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Preload("User").Order("id DESC").Offset(offset).Limit(paging.Limit).Find(&sessions).Error; err != nil {
+		return nil, 0, err
+	}
+	return sessions, total, nil
+}
+
+func (r *SessionRepository) CreateSession(session *models.UserSession, token *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User").Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+func (r *SessionRepository) Touch(id uint, at time.Time) error {
+	return r.db.Model(&models.UserSession{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+func (r *SessionRepository) Revoke(id uint, reason string, at time.Time) error {
+	return r.db.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_reason": reason}).Error
+}
+
+func (r *SessionRepository) RevokeAll(userID uint, reason string, at time.Time) (int64, error) {
+	res := r.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, at).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_reason": reason})
+	return res.RowsAffected, res.Error
+}
+
+func (r *SessionRepository) GetRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	if err := r.db.Where("token_hash = ?", tokenHash).Limit(1).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return &tokens[0], nil
+}
+
+func (r *SessionRepository) RotateRefreshToken(old *models.RefreshToken, next *models.RefreshToken, at time.Time) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Only one of two concurrent refreshes with the same token wins
+		res := tx.Model(&models.RefreshToken{}).Where("id = ? AND used_at IS NULL", old.ID).Update("used_at", at)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.UserSession{}).Where("id = ?", old.SessionID).Update("last_used_at", at).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
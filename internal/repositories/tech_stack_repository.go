@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"gorm.io/gorm"
+)
+
+type ITechStackRepository interface {
+	GetByProjectID(projectID uint) ([]models.ProjectTechStack, error)
+	GetByID(id uint, projectID uint) (*models.ProjectTechStack, error)
+	GetByProducts(products []string) ([]models.ProjectTechStack, error)
+	Create(item *models.ProjectTechStack) (*models.ProjectTechStack, error)
+	Update(item *models.ProjectTechStack) (*models.ProjectTechStack, error)
+	Delete(id uint, projectID uint) error
+}
+
+type TechStackRepository struct {
+	db *gorm.DB
+}
+
+func NewTechStackRepository(db *gorm.DB) *TechStackRepository {
+	return &TechStackRepository{db: db}
+}
+
+func (repo *TechStackRepository) GetByProjectID(projectID uint) ([]models.ProjectTechStack, error) {
+	var items []models.ProjectTechStack
+	if err := repo.db.Where("project_id = ?", projectID).Order("name ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *TechStackRepository) GetByID(id uint, projectID uint) (*models.ProjectTechStack, error) {
+	var item models.ProjectTechStack
+	if err := repo.db.First(&item, "id = ? AND project_id = ?", id, projectID).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByProducts returns all inventory entries (across projects) for the given CPE products.
+func (repo *TechStackRepository) GetByProducts(products []string) ([]models.ProjectTechStack, error) {
+	var items []models.ProjectTechStack
+	if len(products) == 0 {
+		return items, nil
+	}
+	if err := repo.db.Where("product IN ?", products).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *TechStackRepository) Create(item *models.ProjectTechStack) (*models.ProjectTechStack, error) {
+	if err := repo.db.Create(item).Error; err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (repo *TechStackRepository) Update(item *models.ProjectTechStack) (*models.ProjectTechStack, error) {
+	if err := repo.db.Save(item).Error; err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (repo *TechStackRepository) Delete(id uint, projectID uint) error {
+	return repo.db.Where("id = ? AND project_id = ?", id, projectID).Delete(&models.ProjectTechStack{}).Error
+}
@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IUserRepository interface {
+	// GetUsers lists users by email, with their memberships.
+	GetUsers(paging *utils.Paging) ([]models.User, int64, error)
+	// GetByID loads the user with their memberships.
+	GetByID(id uint) (*models.User, error)
+	// GetByEmail returns the user with email, or nil when there is none.
+	GetByEmail(email string) (*models.User, error)
+	// GetByOIDCSubject returns the user linked to the single sign-on subject,
+	// or nil when there is none.
+	GetByOIDCSubject(subject string) (*models.User, error)
+	Count() (int64, error)
+	CountAdmins() (int64, error)
+	Save(user *models.User) error
+	// Delete also deletes the user's memberships.
+	Delete(id uint) error
+
+	// GetMembership returns the user's membership of the project, or nil.
+	GetMembership(projectID, userID uint) (*models.ProjectMember, error)
+	// GetMembers lists the project's members with their users.
+	GetMembers(projectID uint) ([]models.ProjectMember, error)
+	SaveMembership(member *models.ProjectMember) error
+	DeleteMembership(projectID, userID uint) error
+
+	GetInvitations(pendingOnly bool) ([]models.UserInvitation, error)
+	GetInvitation(id uint) (*models.UserInvitation, error)
+	// GetInvitationByToken returns the invitation whose token hashes to
+	// tokenHash, or nil when there is none.
+	GetInvitationByToken(tokenHash string) (*models.UserInvitation, error)
+	SaveInvitation(invitation *models.UserInvitation) error
+	DeleteInvitation(id uint) error
+	// AcceptInvitation creates the user and their memberships and marks the
+	// invitation accepted, in one transaction.
+	AcceptInvitation(invitation *models.UserInvitation, user *models.User) error
+}
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) GetUsers(paging *utils.Paging) ([]models.User, int64, error) {
+	var users []models.User
+	q := r.db.Model(&models.User{})
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Preload("Memberships").Order("email ASC").Offset(offset).Limit(paging.Limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *UserRepository) GetByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.Preload("Memberships").First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
+	var users []models.User
+	if err := r.db.Where("email = ?", email).Limit(1).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+func (r *UserRepository) GetByOIDCSubject(subject string) (*models.User, error) {
+	var users []models.User
+	if err := r.db.Where("oidc_subject = ?", subject).Limit(1).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+func (r *UserRepository) Count() (int64, error) {
+	var n int64
+	err := r.db.Model(&models.User{}).Count(&n).Error
+	return n, err
+}
+
+func (r *UserRepository) CountAdmins() (int64, error) {
+	var n int64
+	err := r.db.Model(&models.User{}).Where("is_admin = ? AND status = ?", true, models.UserActive).Count(&n).Error
+	return n, err
+}
+
+func (r *UserRepository) Save(user *models.User) error {
+	return r.db.Omit("Memberships").Save(user).Error
+}
+
+func (r *UserRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&models.ProjectMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, id).Error
+	})
+}
+
+func (r *UserRepository) GetMembership(projectID, userID uint) (*models.ProjectMember, error) {
+	var members []models.ProjectMember
+	if err := r.db.Where("project_id = ? AND user_id = ?", projectID, userID).Limit(1).Find(&members).Error; err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return &members[0], nil
+}
+
+func (r *UserRepository) GetMembers(projectID uint) ([]models.ProjectMember, error) {
+	var members []models.ProjectMember
+	if err := r.db.Preload("User").Where("project_id = ?", projectID).Order("id ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *UserRepository) SaveMembership(member *models.ProjectMember) error {
+	return r.db.Omit("User").Save(member).Error
+}
+
+func (r *UserRepository) DeleteMembership(projectID, userID uint) error {
+	return r.db.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.ProjectMember{}).Error
+}
+
+func (r *UserRepository) GetInvitations(pendingOnly bool) ([]models.UserInvitation, error) {
+	var invitations []models.UserInvitation
+	q := r.db.Model(&models.UserInvitation{})
+	if pendingOnly {
+		q = q.Where("accepted_at IS NULL")
+	}
+	if err := q.Order("id DESC").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *UserRepository) GetInvitation(id uint) (*models.UserInvitation, error) {
+	var invitation models.UserInvitation
+	if err := r.db.First(&invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *UserRepository) GetInvitationByToken(tokenHash string) (*models.UserInvitation, error) {
+	var invitations []models.UserInvitation
+	if err := r.db.Where("token_hash = ?", tokenHash).Limit(1).Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	if len(invitations) == 0 {
+		return nil, nil
+	}
+	return &invitations[0], nil
+}
+
+func (r *UserRepository) SaveInvitation(invitation *models.UserInvitation) error {
+	return r.db.Save(invitation).Error
+}
+
+func (r *UserRepository) DeleteInvitation(id uint) error {
+	return r.db.Delete(&models.UserInvitation{}, id).Error
+}
+
+func (r *UserRepository) AcceptInvitation(invitation *models.UserInvitation, user *models.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Memberships").Create(user).Error; err != nil {
+			return err
+		}
+		for i := range user.Memberships {
+			user.Memberships[i].UserID = user.ID
+			if err := tx.Omit("User").Create(&user.Memberships[i]).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(invitation).Error
+	})
+}
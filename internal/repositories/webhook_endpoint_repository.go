@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IWebhookEndpointRepository interface {
+	GetByProjectID(projectID uint) ([]models.WebhookEndpoint, error)
+	GetByID(id uint) (*models.WebhookEndpoint, error)
+	GetByToken(token string) (*models.WebhookEndpoint, error)
+	Create(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	Update(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	// Delete removes the endpoint, its delivery history and buffered events.
+	Delete(id uint) error
+
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	ListDeliveries(endpointID uint, paging *utils.Paging) ([]models.WebhookDelivery, int64, error)
+	GetDelivery(id uint) (*models.WebhookDelivery, error)
+	// PruneDeliveries keeps only the newest keep deliveries of the endpoint.
+	PruneDeliveries(endpointID uint, keep int) error
+
+	// FindBuffered returns the open row (flush_at after now) for an event key,
+	// or nil when there is none.
+	FindBuffered(endpointID uint, kind, key string, now time.Time) (*models.WebhookBufferedEvent, error)
+	SaveBuffered(event *models.WebhookBufferedEvent) error
+	// ListDueBuffered returns the rows to flush at now, oldest first.
+	ListDueBuffered(now time.Time) ([]models.WebhookBufferedEvent, error)
+	DeleteBuffered(ids []uint) error
+}
+
+type WebhookEndpointRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookEndpointRepository(db *gorm.DB) *WebhookEndpointRepository {
+	return &WebhookEndpointRepository{db: db}
+}
+
+func (r *WebhookEndpointRepository) GetByProjectID(projectID uint) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := r.db.Where("project_id = ?", projectID).Order("id ASC").Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (r *WebhookEndpointRepository) GetByID(id uint) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := r.db.First(&endpoint, id).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *WebhookEndpointRepository) GetByToken(token string) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := r.db.Where("token = ?", token).First(&endpoint).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *WebhookEndpointRepository) Create(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	if err := r.db.Create(endpoint).Error; err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (r *WebhookEndpointRepository) Update(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	if err := r.db.Save(endpoint).Error; err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (r *WebhookEndpointRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("endpoint_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("endpoint_id = ?", id).Delete(&models.WebhookBufferedEvent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookEndpoint{}, id).Error
+	})
+}
+
+func (r *WebhookEndpointRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *WebhookEndpointRepository) ListDeliveries(endpointID uint, paging *utils.Paging) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	q := r.db.Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpointID)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (paging.Page - 1) * paging.Limit
+	if err := q.Order("id DESC").Offset(offset).Limit(paging.Limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func (r *WebhookEndpointRepository) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookEndpointRepository) PruneDeliveries(endpointID uint, keep int) error {
+	var ids []uint
+	if err := r.db.Model(&models.WebhookDelivery{}).
+		Where("endpoint_id = ?", endpointID).
+		Order("id DESC").Offset(keep).Limit(1).
+		Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return err
+	}
+	return r.db.Where("endpoint_id = ? AND id <= ?", endpointID, ids[0]).Delete(&models.WebhookDelivery{}).Error
+}
+
+func (r *WebhookEndpointRepository) FindBuffered(endpointID uint, kind, key string, now time.Time) (*models.WebhookBufferedEvent, error) {
+	var events []models.WebhookBufferedEvent
+	if err := r.db.
+		Where("endpoint_id = ? AND kind = ? AND dedup_key = ? AND flush_at > ?", endpointID, kind, key, now).
+		Order("id ASC").Limit(1).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return &events[0], nil
+}
+
+func (r *WebhookEndpointRepository) SaveBuffered(event *models.WebhookBufferedEvent) error {
+	return r.db.Save(event).Error
+}
+
+func (r *WebhookEndpointRepository) ListDueBuffered(now time.Time) ([]models.WebhookBufferedEvent, error) {
+	var events []models.WebhookBufferedEvent
+	if err := r.db.Where("flush_at <= ?", now).Order("endpoint_id ASC, first_seen_at ASC, id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *WebhookEndpointRepository) DeleteBuffered(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&models.WebhookBufferedEvent{}).Error
+}
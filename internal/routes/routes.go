@@ -1,12 +1,18 @@
 package routes
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
 	"github.com/vfa-khuongdv/golang-cms/internal/handlers"
 	"github.com/vfa-khuongdv/golang-cms/internal/middlewares"
 	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
 	"github.com/vfa-khuongdv/golang-cms/internal/services"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/inbound"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/ratelimit"
 	"gorm.io/gorm"
 )
 
@@ -18,22 +24,101 @@ func SetupRouter(db *gorm.DB, cronService *services.CronService) *gin.Engine {
 	// Initialize the default Gin router
 	router := gin.Default()
 
+	// Per-IP rate limits rely on the client IP; only take it from
+	// X-Forwarded-For when the request came through one of these proxies.
+	// Without any, the header is ignored and the peer address is used
+	var trustedProxies []string
+	if proxies := utils.GetEnv("TRUSTED_PROXIES", ""); proxies != "" {
+		trustedProxies = strings.Fields(strings.ReplaceAll(proxies, ",", " "))
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		logger.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Repository
 	projectRepo := repositories.NewProjectRepository(db)
 	reminderScheduleRepo := repositories.NewReminderScheduleRepository(db)
 	scheduleLogRepo := repositories.NewScheduleLogRepository(db)
 	chatworkBotRepo := repositories.NewChatworkBotRepository(db)
+	chatworkBotRoomRepo := repositories.NewChatworkBotRoomRepository(db)
 	cveConfigRepo := repositories.NewCveConfigRepository(db)
 	cveScanLogRepo := repositories.NewCveScanLogRepository(db)
+	cveRecordRepo := repositories.NewCveRecordRepository(db)
+	techStackRepo := repositories.NewTechStackRepository(db)
+	cpeFindingRepo := repositories.NewCpeFindingRepository(db)
+	notificationChannelRepo := repositories.NewNotificationChannelRepository(db)
+	botRequestRuleRepo := repositories.NewBotRequestRuleRepository(db)
+	botRequestRepo := repositories.NewBotRequestRepository(db)
+	commandGrantRepo := repositories.NewChatworkCommandGrantRepository(db)
+	webhookEndpointRepo := repositories.NewWebhookEndpointRepository(db)
+	alertRepo := repositories.NewAlertRepository(db)
+	escalationRepo := repositories.NewEscalationRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	sessionRepo := repositories.NewSessionRepository(db)
+	oidcRepo := repositories.NewOIDCRepository(db)
+	projectKeyRepo := repositories.NewProjectAPIKeyRepository(db)
 
 	// Services
 	projectService := services.NewProjectService(projectRepo)
+	projectKeyService := services.NewProjectKeyService(projectKeyRepo, projectRepo)
 	reminderScheduleService := services.NewReminderScheduleService(reminderScheduleRepo, scheduleLogRepo)
 	chatworkService := services.NewChatworkService()
+	inboundAdapters := inbound.NewDefaultRegistry()
 	hookService := services.NewHookService(chatworkService)
 	scheduleLogService := services.NewScheduleLogService(scheduleLogRepo)
-	botService := services.NewChatworkBotService(chatworkBotRepo, reminderScheduleRepo)
-	cveConfigService := services.NewCveConfigService(cveConfigRepo, cveScanLogRepo, chatworkBotRepo)
+	botService := services.NewChatworkBotService(chatworkBotRepo, chatworkBotRoomRepo, reminderScheduleRepo, botRequestRepo)
+	notificationService := services.NewNotificationService(notificationChannelRepo, chatworkBotRepo, services.NewDefaultNotifierRegistry())
+	cveReportService := services.NewCveReportService(services.NewDefaultMailer(), cveConfigRepo)
+	escalationService := services.NewEscalationService(escalationRepo, chatworkBotRepo)
+	cveConfigService := services.NewCveConfigService(cveConfigRepo, cveScanLogRepo, chatworkBotRepo, notificationService, cveReportService, escalationService)
+	cveSearchService := services.NewCveSearchService(cveRecordRepo)
+	botHealthService := services.NewBotHealthService(chatworkBotRepo, reminderScheduleRepo, cveConfigRepo, notificationService)
+	botRequestRuleService := services.NewBotRequestRuleService(botRequestRuleRepo, botRequestRepo, chatworkBotRepo, notificationService)
+	alertService := services.NewAlertService(alertRepo, webhookEndpointRepo, chatworkBotRepo)
+	commandService := services.NewChatworkCommandService(chatworkBotRepo, commandGrantRepo, reminderScheduleService, cronService, cveConfigService, cveSearchService, alertService, escalationService)
+	webhookEndpointService := services.NewWebhookEndpointService(webhookEndpointRepo, chatworkBotRepo, inboundAdapters, alertService)
+	techStackService := services.NewTechStackService(techStackRepo, cpeFindingRepo, cveRecordRepo, projectRepo, chatworkBotRepo, chatworkService)
+	userService := services.NewUserService(userRepo, projectRepo)
+	sessionService := services.NewSessionService(sessionRepo, userService)
+	userService.SetSessionService(sessionService)
+	cronService.SetDependencies(services.CronDependencies{
+		Chatwork:      chatworkService,
+		BotRepo:       chatworkBotRepo,
+		CveRecordRepo: cveRecordRepo,
+		Tasks:         services.NewReminderTaskService(scheduleLogRepo, chatworkBotRepo, chatworkService),
+		Bots:          botService,
+		BotHealth:     botHealthService,
+		BotRules:      botRequestRuleService,
+		Webhooks:      webhookEndpointService,
+		Escalation:    escalationService,
+		Notifications: notificationService,
+		TechStacks:    techStackService,
+		CveReports:    cveReportService,
+	})
+	services.SetCveConfigService(cveConfigService)
+	oidcConfig, oidcEnabled := configs.LoadOIDCConfig()
+	oidcService := services.NewOIDCService(oidcRepo, userRepo, projectRepo, oidcConfig)
+	if oidcEnabled {
+		logger.Infof("Single sign-on enabled with %s", oidcConfig.Issuer)
+	}
+
+	rateLimits, err := configs.LoadRateLimitConfig()
+	if err != nil {
+		logger.Fatalf("Invalid rate limit settings: %v", err)
+	}
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if rateLimits.Store == "mysql" {
+		rateLimitStore = ratelimit.NewSQLStore(db)
+	}
+	limiter := ratelimit.New(rateLimitStore)
+	replays := inbound.NewReplayGuard(rateLimitStore)
+	webhookEndpointService.SetReplayGuard(replays)
+	logger.Infof("Rate limits in %s: %s; %s; %s; %s", rateLimits.Store, rateLimits.Login, rateLimits.Auth, rateLimits.ProjectAccess, rateLimits.CVETest)
+
+	// A fresh install has no users: create the first admin from the env
+	if err := userService.EnsureAdmin(utils.GetEnv("ADMIN_EMAIL", ""), utils.GetEnv("ADMIN_PASSWORD", "")); err != nil {
+		logger.Errorf("Failed to create the first admin: %v", err)
+	}
 
 	// Handlers
 	hookHandler := handlers.NewHookHandler(chatworkService, hookService)
@@ -53,12 +138,21 @@ func SetupRouter(db *gorm.DB, cronService *services.CronService) *gin.Engine {
 	// Setup API routes with API key authentication
 	api := router.Group("/api/v1")
 
-	// Hook routes
-	api.POST("/hooks/chatwork", hookHandler.ChatworkHook)
-	api.POST("/hooks/slack", hookHandler.SlackHook)
+	// Hook routes post to the env Chatwork room, so with HOOKS_SECRET set
+	// only callers that present it get through. Without it they stay open
+	// for this release, with a warning on every request
+	hookAuth := middlewares.UnsecuredHookMiddleware()
+	if secret := utils.GetEnv("HOOKS_SECRET", ""); secret != "" {
+		hookAuth = middlewares.HookSecretMiddleware(secret, replays)
+	} else {
+		logger.Warn("HOOKS_SECRET not set: /api/v1/hooks accept unauthenticated requests. This is deprecated; the next release serves them only with HOOKS_SECRET")
+	}
+	hooks := api.Group("/hooks", hookAuth)
+	hooks.POST("/chatwork", hookHandler.ChatworkHook)
+	hooks.POST("/slack", hookHandler.SlackHook)
 
 	// Setup V2 routes
-	SetupV2Routes(router, projectService, reminderScheduleService, scheduleLogService, cronService, chatworkService, botService, cveConfigService)
+	SetupV2Routes(router, projectService, reminderScheduleService, scheduleLogService, cronService, chatworkService, botService, botHealthService, botRequestRuleService, cveConfigService, cveSearchService, techStackService, notificationService, commandService, webhookEndpointService, alertService, escalationService, userService, sessionService, oidcService, projectKeyService, limiter, rateLimits)
 
 	return router
 }
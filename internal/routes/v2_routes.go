@@ -2,15 +2,21 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
 	"github.com/vfa-khuongdv/golang-cms/internal/handlers"
 	v2 "github.com/vfa-khuongdv/golang-cms/internal/handlers/v2"
 	"github.com/vfa-khuongdv/golang-cms/internal/middlewares"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
 	"github.com/vfa-khuongdv/golang-cms/internal/services"
+	"github.com/vfa-khuongdv/golang-cms/pkg/ratelimit"
 )
 
 // SetupV2Routes registers all /api/v2 endpoints.
 // Auth endpoints are public; all others require JWT Bearer token.
-// Schedule endpoints additionally accept X-Project-Key header.
+// Project-scoped endpoints additionally accept X-Project-Key header.
+// Signed-in users need the route's role in the project (viewer to read,
+// maintainer to change, owner for the project itself, its members and its
+// API keys); admins pass every check. Project keys need the route's scope.
 func SetupV2Routes(
 	router *gin.Engine,
 	projectService services.IProjectService,
@@ -19,22 +25,58 @@ func SetupV2Routes(
 	cronService services.ICronService,
 	chatworkService services.IChatworkService,
 	botService services.IChatworkBotService,
+	botHealthService services.IBotHealthService,
+	botRequestRuleService services.IBotRequestRuleService,
 	cveConfigService services.ICveConfigService,
+	cveSearchService services.ICveSearchService,
+	techStackService services.ITechStackService,
+	notificationService services.INotificationService,
+	commandService services.IChatworkCommandService,
+	webhookEndpointService services.IWebhookEndpointService,
+	alertService services.IAlertService,
+	escalationService services.IEscalationService,
+	userService services.IUserService,
+	sessionService services.ISessionService,
+	oidcService services.IOIDCService,
+	keyService services.IProjectKeyService,
+	limiter *ratelimit.Limiter,
+	rateLimits configs.RateLimitConfig,
 ) {
-	authHandler := v2.NewAuthHandler()
-	projectHandler := v2.NewProjectHandlerV2(projectService, cronService)
-	scheduleHandler := v2.NewScheduleHandlerV2(scheduleService, projectService, cronService, chatworkService, botService)
+	authHandler := v2.NewAuthHandler(userService, sessionService, oidcService)
+	userHandler := v2.NewUserHandler(userService, sessionService)
+	projectHandler := v2.NewProjectHandlerV2(projectService, keyService, cronService)
+	projectKeyHandler := v2.NewProjectKeyHandler(keyService)
+	scheduleHandler := v2.NewScheduleHandlerV2(scheduleService, cronService, chatworkService, botService, notificationService)
 	runLogHandler := v2.NewRunLogHandlerV2(logService)
 	dashboardHandler := v2.NewDashboardHandlerV2(logService, cveConfigService)
-	botHandler := v2.NewBotHandlerV2(botService)
-	botRequestHandler := v2.NewBotRequestHandlerV2(botService)
-	cveConfigHandler := v2.NewCveConfigHandler(cveConfigService, cronService)
+	botHandler := v2.NewBotHandlerV2(botService, botHealthService)
+	botRequestHandler := v2.NewBotRequestHandlerV2(botService, botRequestRuleService)
+	botRequestRuleHandler := v2.NewBotRequestRuleHandlerV2(botRequestRuleService)
+	cveConfigHandler := v2.NewCveConfigHandler(cveConfigService, cronService, botService)
+	cveSearchHandler := v2.NewCveSearchHandler(cveSearchService)
+	techStackHandler := v2.NewTechStackHandler(techStackService)
+	channelHandler := v2.NewNotificationChannelHandler(notificationService)
+	commandHandler := v2.NewChatworkCommandHandler(commandService)
+	webhookEndpointHandler := v2.NewWebhookEndpointHandler(webhookEndpointService)
+	alertHandler := v2.NewAlertHandler(alertService)
+	escalationHandler := v2.NewEscalationHandler(escalationService)
 
 	apiV2 := router.Group("/api/v2")
 
+	// Brute-force protection: guessed passwords and project keys lock the
+	// credential out with a growing delay. The other public auth routes take
+	// tokens, which carry no email to count, so they are limited per IP only
+	loginLimit := middlewares.RateLimitMiddleware(limiter, rateLimits.Login, middlewares.JSONCredential("email"))
+	authLimit := middlewares.RateLimitMiddleware(limiter, rateLimits.Auth, nil)
+	accessLimit := middlewares.RateLimitMiddleware(limiter, rateLimits.ProjectAccess, middlewares.ParamCredential("projectId"))
+	cveTestLimit := middlewares.RateLimitMiddleware(limiter, rateLimits.CVETest, nil)
+
 	// ── Public: Auth ───────────────────────────────────────────────────────────
-	apiV2.POST("/auth/login", authHandler.Login)
-	apiV2.POST("/auth/logout", middlewares.JWTAuthMiddleware(), authHandler.Logout)
+	apiV2.POST("/auth/login", loginLimit, authHandler.Login)
+	apiV2.POST("/auth/refresh", authLimit, authHandler.Refresh)
+	apiV2.POST("/auth/invitations/accept", authLimit, authHandler.AcceptInvitation)
+	apiV2.GET("/auth/oidc/authorize", authLimit, authHandler.OIDCAuthorize)
+	apiV2.POST("/auth/oidc/callback", authLimit, authHandler.OIDCCallback)
 
 	// ── Health Check Routes ─────────────────────────────────────────────────
 	apiV2.GET("/health", handlers.GetHealth)
@@ -43,65 +85,201 @@ func SetupV2Routes(
 	apiV2.GET("/health/database", handlers.GetDatabaseHealth)
 
 	// ── Public: CVE Test ─────────────────────────────────────────────────
-	apiV2.POST("/cve/test", cveConfigHandler.TestPublic)
+	apiV2.POST("/cve/test", cveTestLimit, cveConfigHandler.TestPublic)
+
+	// ── Public: Chatwork webhooks (signed with the bot's webhook token) ──────
+	apiV2.POST("/webhooks/chatwork/:botId", commandHandler.Webhook)
+
+	// ── Public: project webhook endpoints (signed with the endpoint's secret) ─
+	apiV2.POST("/webhooks/:token", webhookEndpointHandler.Receive)
 
 	// ── JWT-protected routes ───────────────────────────────────────────────────
+	admin := middlewares.RequireAdmin()
+	viewer := middlewares.RequireProjectRole(userService, models.RoleViewer)
+	owner := middlewares.RequireProjectRole(userService, models.RoleOwner)
+
+	// Project-scoped routes also take project keys with the route's scope
+	schedulesRead := middlewares.RequireProjectAccess(userService, models.RoleViewer, models.ScopeSchedulesRead)
+	schedulesWrite := middlewares.RequireProjectAccess(userService, models.RoleMaintainer, models.ScopeSchedulesWrite)
+	cveRead := middlewares.RequireProjectAccess(userService, models.RoleViewer, models.ScopeCVERead)
+	cveWrite := middlewares.RequireProjectAccess(userService, models.RoleMaintainer, models.ScopeCVEWrite)
+	cveScan := middlewares.RequireProjectAccess(userService, models.RoleMaintainer, models.ScopeCVEScan)
+	projectRead := middlewares.RequireProjectAccess(userService, models.RoleViewer, models.ScopeProjectRead)
+	projectWrite := middlewares.RequireProjectAccess(userService, models.RoleMaintainer, models.ScopeProjectWrite)
+
 	jwt := apiV2.Group("")
-	jwt.Use(middlewares.JWTAuthMiddleware())
+	jwt.Use(middlewares.JWTAuthMiddleware(sessionService))
 	{
+		// Signed-in user and their sessions
+		jwt.POST("/auth/logout", authHandler.Logout)
+		jwt.POST("/auth/logout-all", authHandler.LogoutAll)
+		jwt.GET("/auth/me", authHandler.Me)
+		jwt.GET("/auth/sessions", authHandler.GetSessions)
+		jwt.DELETE("/auth/sessions/:sessionId", authHandler.RevokeSession)
+
+		// Outbound Chatwork queue per token (admin only)
+		jwt.GET("/health/chatwork/queue", admin, handlers.GetChatworkQueueHealth)
+
 		// Projects
 		jwt.GET("/projects", projectHandler.GetAll)
-		jwt.POST("/projects", projectHandler.Create)
-		jwt.GET("/projects/:projectId", projectHandler.GetByID)
-		jwt.PATCH("/projects/:projectId", projectHandler.Update)
-		jwt.DELETE("/projects/:projectId", projectHandler.Delete)
-		jwt.POST("/projects/:projectId/access", projectHandler.Access)
-
-		// Dashboard
-		jwt.GET("/dashboard/summary", dashboardHandler.GetSummary)
-		jwt.GET("/dashboard/cve-recent-scans", dashboardHandler.GetCveRecentScans)
-
-		// Run Logs (admin only — JWT required)
-		jwt.GET("/run-logs", runLogHandler.GetAll)
-		jwt.GET("/projects/:projectId/run-logs", runLogHandler.GetByProject)
-		jwt.GET("/projects/:projectId/schedules/:scheduleId/run-logs", runLogHandler.GetBySchedule)
-
-		// Bots
-		jwt.GET("/bots", botHandler.GetAll)
-		jwt.POST("/bots", botHandler.Create)
-		jwt.DELETE("/bots/:botId", botHandler.Delete)
+		jwt.POST("/projects", admin, projectHandler.Create)
+		jwt.GET("/projects/:projectId", viewer, projectHandler.GetByID)
+		jwt.PATCH("/projects/:projectId", owner, projectHandler.Update)
+		jwt.DELETE("/projects/:projectId", owner, projectHandler.Delete)
+		jwt.POST("/projects/:projectId/access", accessLimit, projectHandler.Access)
+
+		// Project members
+		jwt.GET("/projects/:projectId/members", viewer, userHandler.GetMembers)
+		jwt.PUT("/projects/:projectId/members/:userId", owner, userHandler.SetMember)
+		jwt.DELETE("/projects/:projectId/members/:userId", owner, userHandler.RemoveMember)
+
+		// Project API keys
+		jwt.GET("/projects/:projectId/api-keys", owner, projectKeyHandler.GetAll)
+		jwt.POST("/projects/:projectId/api-keys", owner, projectKeyHandler.Create)
+		jwt.PATCH("/projects/:projectId/api-keys/:keyId", owner, projectKeyHandler.Update)
+		jwt.POST("/projects/:projectId/api-keys/:keyId/rotate", owner, projectKeyHandler.Rotate)
+		jwt.DELETE("/projects/:projectId/api-keys/:keyId", owner, projectKeyHandler.Revoke)
+
+		// Users, sessions and invitations (admin only)
+		jwt.GET("/users", admin, userHandler.GetAll)
+		jwt.POST("/users", admin, userHandler.Create)
+		jwt.GET("/users/:userId", admin, userHandler.GetByID)
+		jwt.PATCH("/users/:userId", admin, userHandler.Update)
+		jwt.DELETE("/users/:userId", admin, userHandler.Delete)
+		jwt.DELETE("/users/:userId/sessions", admin, userHandler.RevokeUserSessions)
+		jwt.GET("/sessions", admin, userHandler.GetSessions)
+		jwt.DELETE("/sessions/:sessionId", admin, userHandler.RevokeSession)
+		jwt.GET("/invitations", admin, userHandler.GetInvitations)
+		jwt.POST("/invitations", admin, userHandler.CreateInvitation)
+		jwt.DELETE("/invitations/:invitationId", admin, userHandler.RevokeInvitation)
+
+		// Single sign-on group mappings (admin only)
+		jwt.GET("/oidc/group-mappings", admin, authHandler.GetOIDCGroupMappings)
+		jwt.POST("/oidc/group-mappings", admin, authHandler.CreateOIDCGroupMapping)
+		jwt.DELETE("/oidc/group-mappings/:mappingId", admin, authHandler.DeleteOIDCGroupMapping)
+
+		// Dashboard (admin only)
+		jwt.GET("/dashboard/summary", admin, dashboardHandler.GetSummary)
+		jwt.GET("/dashboard/cve-recent-scans", admin, dashboardHandler.GetCveRecentScans)
+
+		// Run Logs (JWT required)
+		jwt.GET("/run-logs", admin, runLogHandler.GetAll)
+		jwt.GET("/projects/:projectId/run-logs", viewer, runLogHandler.GetByProject)
+		jwt.GET("/projects/:projectId/schedules/:scheduleId/run-logs", viewer, runLogHandler.GetBySchedule)
+
+		// Bots (admin only)
+		jwt.GET("/bots", admin, botHandler.GetAll)
+		jwt.POST("/bots", admin, botHandler.Create)
+		jwt.DELETE("/bots/:botId", admin, botHandler.Delete)
+		jwt.POST("/bots/:botId/sync", admin, botHandler.Sync)
+		jwt.POST("/bots/:botId/rotate-token", admin, botHandler.RotateToken)
+		jwt.POST("/bots/:botId/health-check", admin, botHandler.CheckHealth)
+		jwt.PUT("/bots/:botId/webhook", admin, botHandler.SetWebhook)
+		jwt.GET("/bots/:botId/rooms", admin, botHandler.GetRooms)
+		jwt.GET("/bots/:botId/rooms/:roomId/members", admin, botHandler.GetRoomMembers)
 
 		// Bot Requests
-		jwt.GET("/bot-requests", botRequestHandler.GetAll)
-		jwt.POST("/bot-requests/:requestId/accept", botRequestHandler.Accept)
-		jwt.DELETE("/bot-requests/:requestId", botRequestHandler.Delete)
+		jwt.GET("/bot-requests", admin, botRequestHandler.GetAll)
+		jwt.GET("/bot-requests/audit", admin, botRequestHandler.GetAudit)
+		jwt.POST("/bot-requests/:requestId/accept", admin, botRequestHandler.Accept)
+		jwt.DELETE("/bot-requests/:requestId", admin, botRequestHandler.Delete)
+
+		// Bot Request Rules
+		jwt.GET("/bot-request-rules", admin, botRequestRuleHandler.GetAll)
+		jwt.POST("/bot-request-rules", admin, botRequestRuleHandler.Create)
+		jwt.POST("/bot-request-rules/run", admin, botRequestRuleHandler.Run)
+		jwt.PUT("/bot-request-rules/:ruleId", admin, botRequestRuleHandler.Update)
+		jwt.DELETE("/bot-request-rules/:ruleId", admin, botRequestRuleHandler.Delete)
+
+		// CVE Search (crawled NVD records)
+		jwt.GET("/cves", cveSearchHandler.Search)
+		jwt.GET("/cves/:cveId", cveSearchHandler.GetByID)
 	}
 
 	// ── Project-scoped routes (JWT or X-Project-Key) ───────────────────────────
 	projectScoped := apiV2.Group("")
-	projectScoped.Use(middlewares.ProjectScopeMiddleware())
+	projectScoped.Use(middlewares.ProjectScopeMiddleware(sessionService, keyService, middlewares.NewKeyAttemptLimiter(limiter, rateLimits.ProjectAccess)))
 	{
-		projectScoped.GET("/projects/:projectId/schedules", scheduleHandler.GetByProject)
-		projectScoped.POST("/projects/:projectId/schedules", scheduleHandler.Create)
-		projectScoped.POST("/projects/:projectId/schedules/test", scheduleHandler.Test)
-		projectScoped.GET("/projects/:projectId/schedules/:scheduleId", scheduleHandler.GetByID)
-		projectScoped.PATCH("/projects/:projectId/schedules/:scheduleId", scheduleHandler.Update)
-		projectScoped.PATCH("/projects/:projectId/schedules/:scheduleId/toggle", scheduleHandler.Toggle)
-		projectScoped.DELETE("/projects/:projectId/schedules/:scheduleId", scheduleHandler.Delete)
-		projectScoped.GET("/projects/:projectId/schedules/analysis", scheduleHandler.GetAnalysis)
+		projectScoped.GET("/projects/:projectId/schedules", schedulesRead, scheduleHandler.GetByProject)
+		projectScoped.POST("/projects/:projectId/schedules", schedulesWrite, scheduleHandler.Create)
+		projectScoped.POST("/projects/:projectId/schedules/test", schedulesWrite, scheduleHandler.Test)
+		projectScoped.GET("/projects/:projectId/schedules/:scheduleId", schedulesRead, scheduleHandler.GetByID)
+		projectScoped.PATCH("/projects/:projectId/schedules/:scheduleId", schedulesWrite, scheduleHandler.Update)
+		projectScoped.PATCH("/projects/:projectId/schedules/:scheduleId/toggle", schedulesWrite, scheduleHandler.Toggle)
+		projectScoped.DELETE("/projects/:projectId/schedules/:scheduleId", schedulesWrite, scheduleHandler.Delete)
+		projectScoped.GET("/projects/:projectId/schedules/analysis", schedulesRead, scheduleHandler.GetAnalysis)
 
 		// CVE Configs
-		projectScoped.GET("/projects/:projectId/cve-configs", cveConfigHandler.GetByProject)
-		projectScoped.POST("/projects/:projectId/cve-configs", cveConfigHandler.Create)
-		projectScoped.PUT("/projects/:projectId/cve-configs/:configId", cveConfigHandler.Update)
-		projectScoped.DELETE("/projects/:projectId/cve-configs/:configId", cveConfigHandler.Delete)
-		projectScoped.POST("/projects/:projectId/cve-configs/:configId/toggle", cveConfigHandler.Toggle)
-		projectScoped.POST("/projects/:projectId/cve-configs/:configId/scan", cveConfigHandler.Scan)
-		projectScoped.GET("/projects/:projectId/cve-configs/:configId/vulnerabilities", cveConfigHandler.GetVulnerabilities)
-		projectScoped.GET("/projects/:projectId/cve-configs/:configId/logs", cveConfigHandler.GetScanLogs)
-		projectScoped.POST("/projects/:projectId/cve/test", cveConfigHandler.Test)
+		projectScoped.GET("/projects/:projectId/cve-configs", cveRead, cveConfigHandler.GetByProject)
+		projectScoped.POST("/projects/:projectId/cve-configs", cveWrite, cveConfigHandler.Create)
+		projectScoped.PUT("/projects/:projectId/cve-configs/:configId", cveWrite, cveConfigHandler.Update)
+		projectScoped.DELETE("/projects/:projectId/cve-configs/:configId", cveWrite, cveConfigHandler.Delete)
+		projectScoped.POST("/projects/:projectId/cve-configs/:configId/toggle", cveWrite, cveConfigHandler.Toggle)
+		projectScoped.POST("/projects/:projectId/cve-configs/:configId/scan", cveScan, cveConfigHandler.Scan)
+		projectScoped.GET("/projects/:projectId/cve-configs/:configId/vulnerabilities", cveRead, cveConfigHandler.GetVulnerabilities)
+		projectScoped.GET("/projects/:projectId/cve-configs/:configId/logs", cveRead, cveConfigHandler.GetScanLogs)
+		projectScoped.POST("/projects/:projectId/cve/test", cveScan, cveConfigHandler.Test)
 
 		// CVE Analysis
-		projectScoped.GET("/projects/:projectId/cve/analysis", cveConfigHandler.GetAnalysis)
+		projectScoped.GET("/projects/:projectId/cve/analysis", cveRead, cveConfigHandler.GetAnalysis)
+
+		// Tech Stack inventory and CPE findings
+		projectScoped.GET("/projects/:projectId/tech-stack", cveRead, techStackHandler.GetByProject)
+		projectScoped.POST("/projects/:projectId/tech-stack", cveWrite, techStackHandler.Create)
+		projectScoped.DELETE("/projects/:projectId/tech-stack/:techStackId", cveWrite, techStackHandler.Delete)
+		projectScoped.GET("/projects/:projectId/cve/cpe-findings", cveRead, techStackHandler.GetFindings)
+
+		// Notification Channels
+		projectScoped.GET("/projects/:projectId/channels", projectRead, channelHandler.GetByProject)
+		projectScoped.POST("/projects/:projectId/channels", projectWrite, channelHandler.Create)
+		projectScoped.PUT("/projects/:projectId/channels/:channelId", projectWrite, channelHandler.Update)
+		projectScoped.DELETE("/projects/:projectId/channels/:channelId", projectWrite, channelHandler.Delete)
+		projectScoped.POST("/projects/:projectId/channels/:channelId/test", projectWrite, channelHandler.Test)
+
+		// Chatwork command grants
+		projectScoped.GET("/projects/:projectId/chatwork-commands", projectRead, commandHandler.GetByProject)
+		projectScoped.POST("/projects/:projectId/chatwork-commands", projectWrite, commandHandler.Create)
+		projectScoped.PUT("/projects/:projectId/chatwork-commands/:grantId", projectWrite, commandHandler.Update)
+		projectScoped.DELETE("/projects/:projectId/chatwork-commands/:grantId", projectWrite, commandHandler.Delete)
+
+		// Inbound webhook endpoints and their delivery history
+		projectScoped.GET("/projects/:projectId/webhook-endpoints", projectRead, webhookEndpointHandler.GetByProject)
+		projectScoped.POST("/projects/:projectId/webhook-endpoints", projectWrite, webhookEndpointHandler.Create)
+		projectScoped.POST("/projects/:projectId/webhook-endpoints/dry-run", projectWrite, webhookEndpointHandler.DryRun)
+		projectScoped.GET("/projects/:projectId/webhook-endpoints/:endpointId", projectRead, webhookEndpointHandler.GetByID)
+		projectScoped.PUT("/projects/:projectId/webhook-endpoints/:endpointId", projectWrite, webhookEndpointHandler.Update)
+		projectScoped.DELETE("/projects/:projectId/webhook-endpoints/:endpointId", projectWrite, webhookEndpointHandler.Delete)
+		projectScoped.POST("/projects/:projectId/webhook-endpoints/:endpointId/rotate-secret", projectWrite, webhookEndpointHandler.RotateSecret)
+		projectScoped.POST("/projects/:projectId/webhook-endpoints/:endpointId/dry-run", projectWrite, webhookEndpointHandler.DryRunEndpoint)
+		projectScoped.GET("/projects/:projectId/webhook-endpoints/:endpointId/deliveries", projectRead, webhookEndpointHandler.GetDeliveries)
+		projectScoped.GET("/projects/:projectId/webhook-endpoints/:endpointId/deliveries/:deliveryId", projectRead, webhookEndpointHandler.GetDelivery)
+
+		// Alerts from alerts endpoints, their groups and silences
+		projectScoped.GET("/projects/:projectId/alerts", projectRead, alertHandler.GetAlerts)
+		projectScoped.GET("/projects/:projectId/alert-groups", projectRead, alertHandler.GetGroups)
+		projectScoped.GET("/projects/:projectId/alert-groups/:groupId", projectRead, alertHandler.GetGroup)
+		projectScoped.POST("/projects/:projectId/alert-groups/:groupId/ack", projectWrite, alertHandler.Acknowledge)
+		projectScoped.GET("/projects/:projectId/silences", projectRead, alertHandler.GetSilences)
+		projectScoped.POST("/projects/:projectId/silences", projectWrite, alertHandler.CreateSilence)
+		projectScoped.GET("/projects/:projectId/silences/:silenceId", projectRead, alertHandler.GetSilence)
+		projectScoped.DELETE("/projects/:projectId/silences/:silenceId", projectWrite, alertHandler.ExpireSilence)
+
+		// On-call rotations, escalation policies and escalations
+		projectScoped.GET("/projects/:projectId/oncall-rotations", projectRead, escalationHandler.GetRotations)
+		projectScoped.POST("/projects/:projectId/oncall-rotations", projectWrite, escalationHandler.CreateRotation)
+		projectScoped.GET("/projects/:projectId/oncall-rotations/:rotationId", projectRead, escalationHandler.GetRotation)
+		projectScoped.PUT("/projects/:projectId/oncall-rotations/:rotationId", projectWrite, escalationHandler.UpdateRotation)
+		projectScoped.DELETE("/projects/:projectId/oncall-rotations/:rotationId", projectWrite, escalationHandler.DeleteRotation)
+		projectScoped.POST("/projects/:projectId/oncall-rotations/:rotationId/overrides", projectWrite, escalationHandler.CreateOverride)
+		projectScoped.DELETE("/projects/:projectId/oncall-rotations/:rotationId/overrides/:overrideId", projectWrite, escalationHandler.DeleteOverride)
+		projectScoped.GET("/projects/:projectId/escalation-policies", projectRead, escalationHandler.GetPolicies)
+		projectScoped.POST("/projects/:projectId/escalation-policies", projectWrite, escalationHandler.CreatePolicy)
+		projectScoped.GET("/projects/:projectId/escalation-policies/:policyId", projectRead, escalationHandler.GetPolicy)
+		projectScoped.PUT("/projects/:projectId/escalation-policies/:policyId", projectWrite, escalationHandler.UpdatePolicy)
+		projectScoped.DELETE("/projects/:projectId/escalation-policies/:policyId", projectWrite, escalationHandler.DeletePolicy)
+		projectScoped.POST("/projects/:projectId/escalation-policies/:policyId/trigger", projectWrite, escalationHandler.Trigger)
+		projectScoped.GET("/projects/:projectId/escalations", projectRead, escalationHandler.GetEscalations)
+		projectScoped.GET("/projects/:projectId/escalations/:escalationId", projectRead, escalationHandler.GetEscalation)
+		projectScoped.POST("/projects/:projectId/escalations/:escalationId/ack", projectWrite, escalationHandler.Acknowledge)
 	}
 }
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// adminAlerts delivers operational alerts to the admins: through the
+// notification channel ADMIN_NOTIFY_CHANNEL_ID, or else to the Chatwork room
+// ADMIN_CHATWORK_ROOM_ID using ADMIN_CHATWORK_API_KEY.
+type adminAlerts struct {
+	notifications INotificationService
+	channelID     uint
+	roomID        string
+	token         string
+}
+
+func newAdminAlerts(notifications INotificationService) adminAlerts {
+	return adminAlerts{
+		notifications: notifications,
+		channelID:     uint(utils.GetEnvAsInt("ADMIN_NOTIFY_CHANNEL_ID", 0)),
+		roomID:        utils.GetEnv("ADMIN_CHATWORK_ROOM_ID", ""),
+		token:         utils.GetEnv("ADMIN_CHATWORK_API_KEY", ""),
+	}
+}
+
+// send delivers msg, logging instead when no admin destination is configured.
+// tag prefixes the log lines, e.g. "[BotHealth]".
+func (a adminAlerts) send(tag string, msg notifier.Message) {
+	var err error
+	switch {
+	case a.channelID != 0:
+		err = a.notifications.Notify(a.channelID, msg)
+	case a.roomID != "" && a.token != "":
+		err = a.notifications.NotifyChatwork(a.token, a.roomID, msg)
+	default:
+		logger.Warnf("%s ADMIN_NOTIFY_CHANNEL_ID or ADMIN_CHATWORK_ROOM_ID/ADMIN_CHATWORK_API_KEY not set, alert not sent: %s", tag, msg.Title)
+		return
+	}
+	if err != nil {
+		logger.Errorf("%s Failed to send admin alert %q: %v", tag, msg.Title, err)
+	}
+}
@@ -0,0 +1,679 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/inbound"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+)
+
+// defaultAlertGroupBy groups alerts when an endpoint doesn't say otherwise.
+var defaultAlertGroupBy = []string{"alertname"}
+
+// labelNamePattern is the Prometheus label name syntax, used for matchers and group-by labels.
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// IAlertService tracks the alerts sent to alerts endpoints: it groups them,
+// posts one message per group, replies to it with later alerts and
+// resolutions, and applies silences and acknowledgements.
+type IAlertService interface {
+	// Ingest records the events of one request to an alerts endpoint and posts
+	// what changed. State is saved before posting, so alerts whose message
+	// failed are announced again when they repeat.
+	Ingest(endpoint *models.WebhookEndpoint, events []inbound.Event) (*AlertIngest, error)
+
+	GetGroups(projectID uint, status string, paging *utils.Paging) ([]models.AlertGroup, int64, error)
+	GetGroup(id, projectID uint) (*models.AlertGroup, error)
+	// GetGroupByMessage finds the group whose firing message is messageID, for
+	// acknowledging by replying to it. It returns nil when there is none.
+	GetGroupByMessage(roomID, messageID string) (*models.AlertGroup, error)
+	GetAlerts(projectID uint, status string, paging *utils.Paging) ([]models.Alert, int64, error)
+	// Acknowledge marks the group as handled by `by` and notes it under the
+	// firing message. Acknowledging twice keeps the first acknowledgement.
+	Acknowledge(id, projectID uint, by string) (*models.AlertGroup, error)
+
+	GetSilences(projectID uint, activeOnly bool) ([]models.AlertSilence, error)
+	GetSilence(id, projectID uint) (*models.AlertSilence, error)
+	CreateSilence(projectID uint, input *AlertSilenceInput) (*models.AlertSilence, error)
+	// ExpireSilence ends the silence now; expired silences are kept.
+	ExpireSilence(id, projectID uint) (*models.AlertSilence, error)
+}
+
+// AlertSilenceInput creates a silence. EndsAt or DurationMinutes sets the
+// expiry; StartsAt defaults to now.
+type AlertSilenceInput struct {
+	Matchers        []models.AlertMatcher
+	StartsAt        *time.Time
+	EndsAt          *time.Time
+	DurationMinutes *int
+	Comment         string
+	CreatedBy       string
+}
+
+// AlertIngest is what a request changed.
+type AlertIngest struct {
+	Firing   int // alerts announced as firing
+	Resolved int // alerts announced as resolved
+	Silenced int // firing alerts muted by a silence
+	// Messages are the bodies posted to the room.
+	Messages []string
+}
+
+type AlertService struct {
+	repo         repositories.IAlertRepository
+	endpointRepo repositories.IWebhookEndpointRepository
+	botRepo      repositories.IChatworkBotRepository
+	baseURL      string
+	now          func() time.Time
+}
+
+func NewAlertService(repo repositories.IAlertRepository, endpointRepo repositories.IWebhookEndpointRepository, botRepo repositories.IChatworkBotRepository) *AlertService {
+	return &AlertService{
+		repo:         repo,
+		endpointRepo: endpointRepo,
+		botRepo:      botRepo,
+		baseURL:      chatworkBaseURL(),
+		now:          time.Now,
+	}
+}
+
+// alertChange collects what happened to one group during an ingest.
+type alertChange struct {
+	group    *models.AlertGroup
+	firing   []*models.Alert
+	resolved []*models.Alert
+	closing  bool
+}
+
+func (s *AlertService) Ingest(endpoint *models.WebhookEndpoint, events []inbound.Event) (*AlertIngest, error) {
+	now := s.now()
+	silences, err := s.repo.ListSilences(endpoint.ProjectID, &now)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+
+	result := &AlertIngest{}
+	var order []uint
+	changes := make(map[uint]*alertChange)
+	change := func(group *models.AlertGroup) *alertChange {
+		if c, ok := changes[group.ID]; ok {
+			return c
+		}
+		c := &alertChange{group: group}
+		changes[group.ID] = c
+		order = append(order, group.ID)
+		return c
+	}
+	groupOf := func(id uint) (*models.AlertGroup, error) {
+		if c, ok := changes[id]; ok {
+			return c.group, nil
+		}
+		return s.repo.GetGroup(id)
+	}
+
+	for _, e := range events {
+		labels := alertLabels(e)
+		fingerprint := alertFingerprint(e, labels)
+		alert, err := s.repo.GetAlertByFingerprint(endpoint.ProjectID, fingerprint)
+		if err != nil {
+			return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+		}
+
+		if e.Status == inbound.StatusResolved {
+			// Resolutions of unknown or already resolved alerts change nothing.
+			if alert == nil || alert.Status == models.AlertResolved {
+				continue
+			}
+			alert.Status = models.AlertResolved
+			alert.EndsAt = &now
+			alert.LastSeenAt = now
+			if err := s.repo.SaveAlert(alert); err != nil {
+				return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+			}
+			group, err := groupOf(alert.GroupID)
+			if err != nil {
+				return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+			}
+			c := change(group)
+			c.closing = true
+			if alert.Notified {
+				c.resolved = append(c.resolved, alert)
+			}
+			continue
+		}
+
+		silence := matchingSilence(silences, labels)
+		if alert != nil && alert.Status == models.AlertFiring {
+			// A repeat: only announced if a silence muted it before and has ended.
+			alert.LastSeenAt = now
+			alert.Summary = alertSummaryText(e)
+			alert.SilenceID = silenceID(silence)
+			if err := s.repo.SaveAlert(alert); err != nil {
+				return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+			}
+			if silence != nil {
+				result.Silenced++
+			} else if !alert.Notified {
+				group, err := groupOf(alert.GroupID)
+				if err != nil {
+					return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+				}
+				c := change(group)
+				c.firing = append(c.firing, alert)
+			}
+			continue
+		}
+
+		group, err := s.openGroup(endpoint, labels)
+		if err != nil {
+			return nil, err
+		}
+		if alert == nil {
+			alert = &models.Alert{ProjectID: endpoint.ProjectID, Fingerprint: fingerprint}
+		}
+		alert.GroupID = group.ID
+		alert.Name = labels["alertname"]
+		alert.Status = models.AlertFiring
+		alert.Severity = alertSeverity(e, labels)
+		alert.Labels = labels
+		alert.Summary = alertSummaryText(e)
+		alert.URL = e.URL
+		alert.Notified = false
+		alert.SilenceID = silenceID(silence)
+		alert.StartsAt = now
+		alert.EndsAt = nil
+		alert.LastSeenAt = now
+		if err := s.repo.SaveAlert(alert); err != nil {
+			return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+		}
+
+		if silence != nil {
+			result.Silenced++
+			continue
+		}
+		c := change(group)
+		c.firing = append(c.firing, alert)
+	}
+
+	var bot *models.ChatworkBot
+	for _, id := range order {
+		c := changes[id]
+		if len(c.firing) > 0 || len(c.resolved) > 0 {
+			if bot == nil {
+				if bot, err = s.botRepo.GetByID(endpoint.BotID); err != nil {
+					return result, fmt.Errorf("bot %d not found", endpoint.BotID)
+				}
+			}
+		}
+		if err := s.applyChange(bot, c, now, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// openGroup returns the endpoint's firing group for the alert's group-by
+// labels, creating it when there is none.
+func (s *AlertService) openGroup(endpoint *models.WebhookEndpoint, labels map[string]string) (*models.AlertGroup, error) {
+	groupBy := endpoint.AlertGroupBy
+	if len(groupBy) == 0 {
+		groupBy = defaultAlertGroupBy
+	}
+	groupLabels := make(map[string]string, len(groupBy))
+	parts := make([]string, 0, len(groupBy))
+	for _, name := range groupBy {
+		groupLabels[name] = labels[name]
+		parts = append(parts, name+"="+labels[name])
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	key := hex.EncodeToString(sum[:])
+
+	group, err := s.repo.FindOpenGroup(endpoint.ID, key)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if group != nil {
+		return group, nil
+	}
+	group = &models.AlertGroup{
+		ProjectID:  endpoint.ProjectID,
+		EndpointID: endpoint.ID,
+		GroupKey:   key,
+		Labels:     groupLabels,
+		Status:     models.AlertFiring,
+		RoomID:     endpoint.RoomID,
+	}
+	if err := s.repo.SaveGroup(group); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return group, nil
+}
+
+// applyChange posts the group's new firing and resolved alerts, closes the
+// group once nothing in it fires, and saves it.
+func (s *AlertService) applyChange(bot *models.ChatworkBot, c *alertChange, now time.Time, result *AlertIngest) error {
+	group := c.group
+	remaining := int64(-1)
+	if c.closing {
+		count, err := s.repo.CountFiring(group.ID)
+		if err != nil {
+			return errors.New(errors.ErrDatabaseQuery, err.Error())
+		}
+		remaining = count
+		if count == 0 {
+			group.Status = models.AlertResolved
+			group.ResolvedAt = &now
+		}
+	}
+
+	var sendErr error
+	if len(c.firing) > 0 {
+		message := s.threadMessage(bot, group)
+		title := fmt.Sprintf("🔥 [FIRING:%d] %s", len(c.firing), alertGroupTitle(group))
+		if group.MessageID != "" {
+			title = fmt.Sprintf("🔥 %d more firing: %s", len(c.firing), alertGroupTitle(group))
+		}
+		message.Info(title, func(b *chatwork.Builder) {
+			for _, alert := range c.firing {
+				writeAlertLine(b, alert)
+			}
+			b.Textf("Alert group #%d — reply /ack or send /ack %d to acknowledge", group.ID, group.ID)
+		})
+
+		messageID, err := s.post(bot, group.RoomID, message)
+		if err == nil {
+			if group.MessageID == "" {
+				group.MessageID = messageID
+			}
+			for _, alert := range c.firing {
+				alert.Notified = true
+				if err := s.repo.SaveAlert(alert); err != nil {
+					logger.Errorf("[Alerts] alert_id=%d failed to mark as notified: %v", alert.ID, err)
+				}
+			}
+			result.Firing += len(c.firing)
+			result.Messages = append(result.Messages, message.String())
+		}
+		sendErr = err
+	}
+
+	if len(c.resolved) > 0 && sendErr == nil {
+		message := s.threadMessage(bot, group)
+		title := fmt.Sprintf("✅ [RESOLVED] %s", alertGroupTitle(group))
+		if remaining > 0 {
+			title = fmt.Sprintf("✅ [RESOLVED:%d] %s (%d still firing)", len(c.resolved), alertGroupTitle(group), remaining)
+		}
+		message.Info(title, func(b *chatwork.Builder) {
+			for _, alert := range c.resolved {
+				writeAlertLine(b, alert)
+			}
+		})
+		if _, err := s.post(bot, group.RoomID, message); err == nil {
+			result.Resolved += len(c.resolved)
+			result.Messages = append(result.Messages, message.String())
+		} else {
+			sendErr = err
+		}
+	}
+
+	if err := s.repo.SaveGroup(group); err != nil {
+		return errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	if sendErr != nil {
+		logger.Errorf("[Alerts] group_id=%d failed to post: %v", group.ID, sendErr)
+	}
+	return sendErr
+}
+
+// threadMessage starts a message that replies to the group's firing message
+// when it has one.
+func (s *AlertService) threadMessage(bot *models.ChatworkBot, group *models.AlertGroup) *chatwork.Builder {
+	b := chatwork.NewBuilder()
+	roomID, err := strconv.ParseInt(group.RoomID, 10, 64)
+	if group.MessageID != "" && err == nil {
+		b.Reply(bot.AccountID, roomID, group.MessageID)
+	}
+	return b
+}
+
+// post sends message to the room and returns the ID of its first part.
+func (s *AlertService) post(bot *models.ChatworkBot, room string, message *chatwork.Builder) (string, error) {
+	return postBotMessage(s.baseURL, bot, room, message)
+}
+
+// postBotMessage posts message to a room as the bot and returns the ID of its
+// first part, which replies can refer to.
+func postBotMessage(baseURL string, bot *models.ChatworkBot, room string, message *chatwork.Builder) (string, error) {
+	roomID, err := strconv.ParseInt(room, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid room id %q", room)
+	}
+
+	ctx, cancel := context.WithTimeout(chatwork.WithPriority(context.Background(), chatwork.PriorityHigh), chatworkCallTimeout)
+	defer cancel()
+	client := chatwork.NewClient(bot.APIToken, chatwork.WithBaseURL(baseURL))
+	var first string
+	for _, part := range message.Split(chatwork.MaxMessageLength) {
+		messageID, err := client.PostMessage(ctx, roomID, part)
+		if err != nil {
+			return "", err
+		}
+		if first == "" {
+			first = messageID
+		}
+	}
+	return first, nil
+}
+
+func (s *AlertService) GetGroups(projectID uint, status string, paging *utils.Paging) ([]models.AlertGroup, int64, error) {
+	if err := validateAlertStatus(status); err != nil {
+		return nil, 0, err
+	}
+	groups, total, err := s.repo.ListGroups(projectID, status, paging)
+	if err != nil {
+		return nil, 0, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return groups, total, nil
+}
+
+func (s *AlertService) GetGroup(id, projectID uint) (*models.AlertGroup, error) {
+	group, err := s.repo.GetGroup(id)
+	if err != nil || group.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "alert group not found")
+	}
+	return group, nil
+}
+
+func (s *AlertService) GetGroupByMessage(roomID, messageID string) (*models.AlertGroup, error) {
+	group, err := s.repo.GetGroupByMessage(roomID, messageID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return group, nil
+}
+
+func (s *AlertService) GetAlerts(projectID uint, status string, paging *utils.Paging) ([]models.Alert, int64, error) {
+	if err := validateAlertStatus(status); err != nil {
+		return nil, 0, err
+	}
+	alerts, total, err := s.repo.ListAlerts(projectID, status, paging)
+	if err != nil {
+		return nil, 0, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return alerts, total, nil
+}
+
+func (s *AlertService) Acknowledge(id, projectID uint, by string) (*models.AlertGroup, error) {
+	group, err := s.GetGroup(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if group.AckedAt != nil {
+		return group, nil
+	}
+
+	now := s.now()
+	group.AckedBy = strings.TrimSpace(by)
+	group.AckedAt = &now
+	if err := s.repo.SaveGroup(group); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+
+	// The note under the firing message is best effort.
+	if group.MessageID != "" {
+		if err := s.noteAck(group); err != nil {
+			logger.Warnf("[Alerts] group_id=%d failed to post the acknowledgement: %v", group.ID, err)
+		}
+	}
+	return group, nil
+}
+
+func (s *AlertService) noteAck(group *models.AlertGroup) error {
+	endpoint, err := s.endpointRepo.GetByID(group.EndpointID)
+	if err != nil {
+		return fmt.Errorf("endpoint %d not found", group.EndpointID)
+	}
+	bot, err := s.botRepo.GetByID(endpoint.BotID)
+	if err != nil {
+		return fmt.Errorf("bot %d not found", endpoint.BotID)
+	}
+	message := s.threadMessage(bot, group).Textf("👀 Alert group #%d (%s) acknowledged by %s", group.ID, alertGroupTitle(group), group.AckedBy)
+	_, err = s.post(bot, group.RoomID, message)
+	return err
+}
+
+func (s *AlertService) GetSilences(projectID uint, activeOnly bool) ([]models.AlertSilence, error) {
+	var activeAt *time.Time
+	if activeOnly {
+		now := s.now()
+		activeAt = &now
+	}
+	silences, err := s.repo.ListSilences(projectID, activeAt)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return silences, nil
+}
+
+func (s *AlertService) GetSilence(id, projectID uint) (*models.AlertSilence, error) {
+	silence, err := s.repo.GetSilence(id)
+	if err != nil || silence.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "silence not found")
+	}
+	return silence, nil
+}
+
+func (s *AlertService) CreateSilence(projectID uint, input *AlertSilenceInput) (*models.AlertSilence, error) {
+	now := s.now()
+	silence := &models.AlertSilence{
+		ProjectID: projectID,
+		Comment:   strings.TrimSpace(input.Comment),
+		CreatedBy: strings.TrimSpace(input.CreatedBy),
+		StartsAt:  now,
+	}
+	if input.StartsAt != nil {
+		silence.StartsAt = *input.StartsAt
+	}
+
+	if len(input.Matchers) == 0 {
+		return nil, errors.New(errors.ErrInvalidData, "matchers is required")
+	}
+	for i, m := range input.Matchers {
+		m.Name = strings.TrimSpace(m.Name)
+		if m.Op == "" {
+			m.Op = models.MatchEqual
+		}
+		if err := validateMatcher(m); err != nil {
+			return nil, errors.New(errors.ErrInvalidData, fmt.Sprintf("matchers[%d]: %v", i, err))
+		}
+		silence.Matchers = append(silence.Matchers, m)
+	}
+
+	switch {
+	case input.EndsAt != nil:
+		silence.EndsAt = *input.EndsAt
+	case input.DurationMinutes != nil && *input.DurationMinutes > 0:
+		silence.EndsAt = silence.StartsAt.Add(time.Duration(*input.DurationMinutes) * time.Minute)
+	default:
+		return nil, errors.New(errors.ErrInvalidData, "endsAt or durationMinutes is required")
+	}
+	if !silence.EndsAt.After(silence.StartsAt) || !silence.EndsAt.After(now) {
+		return nil, errors.New(errors.ErrInvalidData, "endsAt must be in the future and after startsAt")
+	}
+
+	if err := s.repo.SaveSilence(silence); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return silence, nil
+}
+
+func (s *AlertService) ExpireSilence(id, projectID uint) (*models.AlertSilence, error) {
+	silence, err := s.GetSilence(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if silence.EndsAt.After(now) {
+		silence.EndsAt = now
+		if silence.StartsAt.After(now) {
+			silence.StartsAt = now
+		}
+		if err := s.repo.SaveSilence(silence); err != nil {
+			return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+		}
+	}
+	return silence, nil
+}
+
+func validateAlertStatus(status string) error {
+	switch status {
+	case "", models.AlertFiring, models.AlertResolved:
+		return nil
+	default:
+		return errors.New(errors.ErrInvalidData, "status must be firing or resolved")
+	}
+}
+
+func validateMatcher(m models.AlertMatcher) error {
+	if !labelNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("invalid label name %q", m.Name)
+	}
+	switch m.Op {
+	case models.MatchEqual, models.MatchNotEqual:
+		return nil
+	case models.MatchRegexp, models.MatchNotRegexp:
+		if _, err := regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", m.Value, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("op must be one of =, !=, =~, !~")
+	}
+}
+
+// matchesLabels reports whether every matcher holds for labels. A missing
+// label counts as the empty string, as in Alertmanager.
+func matchesLabels(matchers []models.AlertMatcher, labels map[string]string) bool {
+	for _, m := range matchers {
+		value := labels[m.Name]
+		var ok bool
+		switch m.Op {
+		case models.MatchEqual:
+			ok = value == m.Value
+		case models.MatchNotEqual:
+			ok = value != m.Value
+		case models.MatchRegexp, models.MatchNotRegexp:
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				return false
+			}
+			ok = re.MatchString(value) == (m.Op == models.MatchRegexp)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func matchingSilence(silences []models.AlertSilence, labels map[string]string) *models.AlertSilence {
+	for i := range silences {
+		if matchesLabels(silences[i].Matchers, labels) {
+			return &silences[i]
+		}
+	}
+	return nil
+}
+
+func silenceID(silence *models.AlertSilence) *uint {
+	if silence == nil {
+		return nil
+	}
+	id := silence.ID
+	return &id
+}
+
+// alertLabels copies the event's labels, naming the alert after the event's
+// title when the provider has no alertname.
+func alertLabels(e inbound.Event) map[string]string {
+	labels := make(map[string]string, len(e.Labels)+1)
+	maps.Copy(labels, e.Labels)
+	if labels["alertname"] == "" {
+		labels["alertname"] = e.Title
+	}
+	return labels
+}
+
+// alertFingerprint is the provider's fingerprint, or a hash of the labels.
+func alertFingerprint(e inbound.Event, labels map[string]string) string {
+	if e.Fingerprint != "" {
+		if len(e.Fingerprint) <= 64 {
+			return e.Fingerprint
+		}
+		sum := sha256.Sum256([]byte(e.Fingerprint))
+		return hex.EncodeToString(sum[:])
+	}
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		b.WriteString(k + "=" + labels[k] + "\x00")
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// alertSeverity prefers the severity label over the adapter's severity.
+func alertSeverity(e inbound.Event, labels map[string]string) string {
+	if severity := labels["severity"]; severity != "" {
+		return severity
+	}
+	return string(e.Severity)
+}
+
+func alertSummaryText(e inbound.Event) string {
+	if e.Text != "" {
+		return e.Text
+	}
+	return e.Title
+}
+
+// alertGroupTitle names a group by its group-by label values.
+func alertGroupTitle(group *models.AlertGroup) string {
+	var values []string
+	for _, k := range slices.Sorted(maps.Keys(group.Labels)) {
+		if v := group.Labels[k]; v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return fmt.Sprintf("alert group #%d", group.ID)
+	}
+	return strings.Join(values, " / ")
+}
+
+func writeAlertLine(b *chatwork.Builder, alert *models.Alert) {
+	line := "• " + firstLine(alert.Summary)
+	if instance := alert.Labels["instance"]; instance != "" {
+		line += " (" + instance + ")"
+	}
+	if alert.Severity != "" && alert.Status == models.AlertFiring {
+		line = fmt.Sprintf("%s [%s]", line, alert.Severity)
+	}
+	b.Text(line)
+	if alert.URL != "" {
+		b.Text("  " + alert.URL)
+	}
+}
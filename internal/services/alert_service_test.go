@@ -0,0 +1,324 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"gorm.io/gorm"
+)
+
+type fakeAlertRepo struct {
+	repositories.IAlertRepository
+	groups   []*models.AlertGroup
+	alerts   []*models.Alert
+	silences []*models.AlertSilence
+}
+
+func (r *fakeAlertRepo) FindOpenGroup(endpointID uint, groupKey string) (*models.AlertGroup, error) {
+	for _, g := range r.groups {
+		if g.EndpointID == endpointID && g.GroupKey == groupKey && g.Status == models.AlertFiring {
+			copied := *g
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeAlertRepo) GetGroup(id uint) (*models.AlertGroup, error) {
+	for _, g := range r.groups {
+		if g.ID == id {
+			copied := *g
+			copied.Alerts = nil
+			for _, a := range r.alerts {
+				if a.GroupID == id {
+					copied.Alerts = append(copied.Alerts, *a)
+				}
+			}
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAlertRepo) GetGroupByMessage(roomID, messageID string) (*models.AlertGroup, error) {
+	for _, g := range r.groups {
+		if g.RoomID == roomID && g.MessageID == messageID {
+			return r.GetGroup(g.ID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeAlertRepo) ListGroups(projectID uint, status string, paging *utils.Paging) ([]models.AlertGroup, int64, error) {
+	var out []models.AlertGroup
+	for _, g := range r.groups {
+		if g.ProjectID == projectID && (status == "" || g.Status == status) {
+			group, _ := r.GetGroup(g.ID)
+			out = append(out, *group)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (r *fakeAlertRepo) SaveGroup(group *models.AlertGroup) error {
+	copied := *group
+	copied.Alerts = nil
+	for i, g := range r.groups {
+		if g.ID == group.ID {
+			r.groups[i] = &copied
+			return nil
+		}
+	}
+	group.ID = uint(len(r.groups) + 1)
+	copied.ID = group.ID
+	r.groups = append(r.groups, &copied)
+	return nil
+}
+
+func (r *fakeAlertRepo) GetAlertByFingerprint(projectID uint, fingerprint string) (*models.Alert, error) {
+	for _, a := range r.alerts {
+		if a.ProjectID == projectID && a.Fingerprint == fingerprint {
+			copied := *a
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeAlertRepo) CountFiring(groupID uint) (int64, error) {
+	var n int64
+	for _, a := range r.alerts {
+		if a.GroupID == groupID && a.Status == models.AlertFiring {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeAlertRepo) SaveAlert(alert *models.Alert) error {
+	copied := *alert
+	for i, a := range r.alerts {
+		if a.ID == alert.ID {
+			r.alerts[i] = &copied
+			return nil
+		}
+	}
+	alert.ID = uint(len(r.alerts) + 1)
+	copied.ID = alert.ID
+	r.alerts = append(r.alerts, &copied)
+	return nil
+}
+
+func (r *fakeAlertRepo) ListSilences(projectID uint, activeAt *time.Time) ([]models.AlertSilence, error) {
+	var out []models.AlertSilence
+	for _, s := range r.silences {
+		if s.ProjectID == projectID && (activeAt == nil || s.Active(*activeAt)) {
+			out = append(out, *s)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeAlertRepo) GetSilence(id uint) (*models.AlertSilence, error) {
+	for _, s := range r.silences {
+		if s.ID == id {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAlertRepo) SaveSilence(silence *models.AlertSilence) error {
+	copied := *silence
+	for i, s := range r.silences {
+		if s.ID == silence.ID {
+			r.silences[i] = &copied
+			return nil
+		}
+	}
+	silence.ID = uint(len(r.silences) + 1)
+	copied.ID = silence.ID
+	r.silences = append(r.silences, &copied)
+	return nil
+}
+
+// alertmanagerAlert is one alert of an Alertmanager notification: the
+// instance label doubles as its fingerprint.
+type alertmanagerAlert struct {
+	status   string
+	instance string
+}
+
+func alertmanagerPayload(t *testing.T, alerts ...alertmanagerAlert) (http.Header, []byte) {
+	t.Helper()
+	var items []map[string]any
+	for _, a := range alerts {
+		items = append(items, map[string]any{
+			"status":      a.status,
+			"labels":      map[string]string{"alertname": "DiskFull", "instance": a.instance, "severity": "warning"},
+			"annotations": map[string]string{"summary": "Disk / is almost full"},
+			"fingerprint": "fp-" + a.instance,
+		})
+	}
+	body, err := json.Marshal(map[string]any{
+		"version":  "4",
+		"status":   alerts[0].status,
+		"receiver": "chatwork",
+		"alerts":   items,
+	})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return http.Header{"Content-Type": {"application/json"}}, body
+}
+
+func TestAlertGroupingAndResolution(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	alerts := svc.alerts.(*AlertService)
+	alertRepo := alerts.repo.(*fakeAlertRepo)
+
+	header, body := alertmanagerPayload(t, alertmanagerAlert{"firing", "db-1"}, alertmanagerAlert{"firing", "db-2"})
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	messages := srv.Messages(42)
+	if len(messages) != 1 || !strings.Contains(messages[0].Body, "[FIRING:2] DiskFull") ||
+		!strings.Contains(messages[0].Body, "db-1") || !strings.Contains(messages[0].Body, "db-2") {
+		t.Fatalf("expected one firing message for both alerts, got %+v", messages)
+	}
+	if len(alertRepo.groups) != 1 || alertRepo.groups[0].MessageID != messages[0].MessageID {
+		t.Fatalf("expected the group to remember its message, got %+v", alertRepo.groups)
+	}
+
+	// Alertmanager repeats firing alerts; they are not announced again.
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if n := len(srv.Messages(42)); n != 1 {
+		t.Fatalf("expected repeats to be quiet, got %d messages", n)
+	}
+	if last := repo.deliveries[len(repo.deliveries)-1]; last.Status != models.WebhookDeliveryMerged {
+		t.Fatalf("expected the repeat to be recorded as merged, got %q", last.Status)
+	}
+
+	reply := "[rp aid=1 to=42-" + messages[0].MessageID + "]"
+	header, body = alertmanagerPayload(t, alertmanagerAlert{"resolved", "db-1"})
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	messages = srv.Messages(42)
+	if last := messages[len(messages)-1].Body; !strings.HasPrefix(last, reply) || !strings.Contains(last, "[RESOLVED:1] DiskFull (1 still firing)") {
+		t.Fatalf("expected a partial resolution replying to the firing message, got %q", last)
+	}
+
+	header, body = alertmanagerPayload(t, alertmanagerAlert{"resolved", "db-2"})
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	messages = srv.Messages(42)
+	if last := messages[len(messages)-1].Body; !strings.HasPrefix(last, reply) || !strings.Contains(last, "[RESOLVED] DiskFull") {
+		t.Fatalf("expected the resolution to reply to the firing message, got %q", last)
+	}
+	if g := alertRepo.groups[0]; g.Status != models.AlertResolved || g.ResolvedAt == nil {
+		t.Fatalf("expected the group to be closed, got %+v", g)
+	}
+
+	// The next firing alert opens a new group with its own message.
+	header, body = alertmanagerPayload(t, alertmanagerAlert{"firing", "db-1"})
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	messages = srv.Messages(42)
+	if len(alertRepo.groups) != 2 || strings.HasPrefix(messages[len(messages)-1].Body, "[rp") {
+		t.Fatalf("expected a new group and message, got %+v", alertRepo.groups)
+	}
+}
+
+func TestAlertSilences(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	alerts := svc.alerts.(*AlertService)
+
+	if _, err := alerts.CreateSilence(3, &AlertSilenceInput{}); err == nil {
+		t.Fatal("expected a silence without matchers to be rejected")
+	}
+	bad := []models.AlertMatcher{{Name: "instance", Op: models.MatchRegexp, Value: "("}}
+	if _, err := alerts.CreateSilence(3, &AlertSilenceInput{Matchers: bad, DurationMinutes: intPtr(60)}); err == nil {
+		t.Fatal("expected an invalid regexp to be rejected")
+	}
+	matchers := []models.AlertMatcher{{Name: "instance", Op: models.MatchRegexp, Value: "db-.*"}}
+	if _, err := alerts.CreateSilence(3, &AlertSilenceInput{Matchers: matchers}); err == nil {
+		t.Fatal("expected a silence without an expiry to be rejected")
+	}
+	silence, err := alerts.CreateSilence(3, &AlertSilenceInput{Matchers: matchers, DurationMinutes: intPtr(60), CreatedBy: "alice"})
+	if err != nil {
+		t.Fatalf("CreateSilence: %v", err)
+	}
+
+	header, body := alertmanagerPayload(t, alertmanagerAlert{"firing", "db-1"}, alertmanagerAlert{"firing", "web-1"})
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	messages := srv.Messages(42)
+	if len(messages) != 1 || strings.Contains(messages[0].Body, "db-1") || !strings.Contains(messages[0].Body, "[FIRING:1]") {
+		t.Fatalf("expected only the unsilenced alert to be posted, got %+v", messages)
+	}
+
+	header, body = alertmanagerPayload(t, alertmanagerAlert{"firing", "db-1"})
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if last := repo.deliveries[len(repo.deliveries)-1]; last.Status != models.WebhookDeliverySilenced {
+		t.Fatalf("expected the delivery to be silenced, got %q", last.Status)
+	}
+
+	if _, err := alerts.ExpireSilence(silence.ID, 3); err != nil {
+		t.Fatalf("ExpireSilence: %v", err)
+	}
+	if active, _ := alerts.GetSilences(3, true); len(active) != 0 {
+		t.Fatalf("expected no active silences, got %+v", active)
+	}
+	// Once the silence is over the still firing alert is announced in its group.
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	messages = srv.Messages(42)
+	if len(messages) != 2 || !strings.HasPrefix(messages[1].Body, "[rp aid=1 to=42-"+messages[0].MessageID+"]") || !strings.Contains(messages[1].Body, "db-1") {
+		t.Fatalf("expected the alert to be posted under the group's message, got %+v", messages)
+	}
+}
+
+func TestAlertAcknowledge(t *testing.T) {
+	svc, _, srv := newWebhookEndpointTestEnv(t)
+	alerts := svc.alerts.(*AlertService)
+
+	header, body := alertmanagerPayload(t, alertmanagerAlert{"firing", "db-1"})
+	if _, err := svc.Receive("tok-alerts", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if _, err := alerts.Acknowledge(1, 4, "bob"); err == nil {
+		t.Fatal("expected a group of another project to be hidden")
+	}
+	group, err := alerts.Acknowledge(1, 3, "alice")
+	if err != nil || group.AckedBy != "alice" || group.AckedAt == nil {
+		t.Fatalf("expected the group to be acknowledged, got %+v, %v", group, err)
+	}
+	if group, _ := alerts.Acknowledge(1, 3, "bob"); group.AckedBy != "alice" {
+		t.Fatalf("expected the first acknowledgement to stick, got %q", group.AckedBy)
+	}
+
+	messages := srv.Messages(42)
+	if len(messages) != 2 || !strings.HasPrefix(messages[1].Body, "[rp aid=1 to=42-"+messages[0].MessageID+"]") || !strings.Contains(messages[1].Body, "acknowledged by alice") {
+		t.Fatalf("expected one acknowledgement under the firing message, got %+v", messages)
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
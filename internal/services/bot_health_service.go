@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// IBotHealthService validates bot tokens against Chatwork and alerts the
+// admin room when one stops working.
+type IBotHealthService interface {
+	// CheckAll validates every bot token.
+	CheckAll()
+	// Check validates one bot token now and returns its updated state.
+	Check(botID uint) (*models.ChatworkBot, error)
+}
+
+type BotHealthService struct {
+	botRepo       repositories.IChatworkBotRepository
+	scheduleRepo  repositories.IReminderScheduleRepository
+	cveConfigRepo repositories.ICveConfigRepository
+	admin         adminAlerts
+	baseURL       string
+	now           func() time.Time
+}
+
+func NewBotHealthService(
+	botRepo repositories.IChatworkBotRepository,
+	scheduleRepo repositories.IReminderScheduleRepository,
+	cveConfigRepo repositories.ICveConfigRepository,
+	notifications INotificationService,
+) *BotHealthService {
+	return &BotHealthService{
+		botRepo:       botRepo,
+		scheduleRepo:  scheduleRepo,
+		cveConfigRepo: cveConfigRepo,
+		admin:         newAdminAlerts(notifications),
+		baseURL:       chatworkBaseURL(),
+		now:           time.Now,
+	}
+}
+
+func (s *BotHealthService) CheckAll() {
+	bots, err := s.botRepo.ListAll()
+	if err != nil {
+		logger.Errorf("[BotHealth] Failed to load bots: %v", err)
+		return
+	}
+	broken := 0
+	for i := range bots {
+		s.check(&bots[i])
+		if bots[i].HealthStatus == models.BotHealthUnauthorized {
+			broken++
+		}
+	}
+	logger.Infof("[BotHealth] Checked %d bots, %d unauthorized", len(bots), broken)
+}
+
+func (s *BotHealthService) Check(botID uint) (*models.ChatworkBot, error) {
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("bot not found")
+	}
+	s.check(bot)
+	return bot, nil
+}
+
+// check calls /me with the bot's token, stores the result and alerts on a change
+// between healthy and unauthorized.
+func (s *BotHealthService) check(bot *models.ChatworkBot) {
+	ctx, cancel := context.WithTimeout(chatwork.WithPriority(context.Background(), chatwork.PriorityLow), chatworkCallTimeout)
+	defer cancel()
+
+	previous := bot.HealthStatus
+	_, err := chatwork.NewClient(bot.APIToken, chatwork.WithBaseURL(s.baseURL)).Me(ctx)
+	now := s.now()
+	bot.HealthCheckedAt = &now
+	switch {
+	case err == nil:
+		bot.HealthStatus = models.BotHealthHealthy
+		bot.HealthError = ""
+	case chatwork.IsUnauthorized(err) || chatwork.IsForbidden(err):
+		bot.HealthStatus = models.BotHealthUnauthorized
+		bot.HealthError = err.Error()
+	default:
+		// Network trouble or a Chatwork outage says nothing about this token.
+		bot.HealthError = err.Error()
+		logger.Warnf("[BotHealth] bot_id=%d check inconclusive: %v", bot.ID, err)
+	}
+	if err := s.botRepo.UpdateHealth(bot); err != nil {
+		logger.Errorf("[BotHealth] bot_id=%d failed to store health: %v", bot.ID, err)
+	}
+
+	switch {
+	case bot.HealthStatus == models.BotHealthUnauthorized && previous != models.BotHealthUnauthorized:
+		logger.Errorf("[BotHealth] bot_id=%d token rejected: %s", bot.ID, bot.HealthError)
+		s.admin.send("[BotHealth]", s.buildBrokenAlert(bot))
+	case bot.HealthStatus == models.BotHealthHealthy && previous == models.BotHealthUnauthorized:
+		logger.Infof("[BotHealth] bot_id=%d token works again", bot.ID)
+		s.admin.send("[BotHealth]", notifier.Message{
+			Title:    fmt.Sprintf("Chatwork bot recovered: %s", botLabel(bot)),
+			Text:     "The bot's token is accepted by Chatwork again; its schedules and CVE configs will send normally.",
+			Severity: notifier.SeveritySuccess,
+		})
+	}
+}
+
+// buildBrokenAlert lists everything that sends through the bot.
+func (s *BotHealthService) buildBrokenAlert(bot *models.ChatworkBot) notifier.Message {
+	msg := notifier.Message{
+		Title:    fmt.Sprintf("Chatwork bot token rejected: %s", botLabel(bot)),
+		Text:     "Chatwork no longer accepts this bot's API token. Everything below fails until the token is rotated.",
+		Severity: notifier.SeverityCritical,
+		Fields: []notifier.Field{
+			{Name: "Bot", Value: fmt.Sprintf("#%d", bot.ID)},
+			{Name: "Error", Value: bot.HealthError},
+		},
+		Footer:   fmt.Sprintf("Rotate the token with POST /api/v2/bots/%d/rotate-token", bot.ID),
+		Priority: chatwork.PriorityHigh,
+	}
+
+	schedules, err := s.scheduleRepo.GetByBotID(bot.ID)
+	if err != nil {
+		logger.Errorf("[BotHealth] bot_id=%d failed to list schedules: %v", bot.ID, err)
+	}
+	if len(schedules) > 0 {
+		section := notifier.Section{Title: fmt.Sprintf("Schedules (%d)", len(schedules))}
+		for _, sc := range schedules {
+			line := fmt.Sprintf("#%d %s — room %s", sc.ID, sc.Name, sc.ChatworkRoomID)
+			if sc.Project.Name != "" {
+				line = fmt.Sprintf("#%d %s (%s) — room %s", sc.ID, sc.Name, sc.Project.Name, sc.ChatworkRoomID)
+			}
+			if !sc.Active {
+				line += " [paused]"
+			}
+			section.Lines = append(section.Lines, line)
+		}
+		msg.Sections = append(msg.Sections, section)
+	}
+
+	configs, err := s.cveConfigRepo.GetByBotID(bot.ID)
+	if err != nil {
+		logger.Errorf("[BotHealth] bot_id=%d failed to list CVE configs: %v", bot.ID, err)
+	}
+	if len(configs) > 0 {
+		section := notifier.Section{Title: fmt.Sprintf("CVE configs (%d)", len(configs))}
+		for _, cfg := range configs {
+			line := fmt.Sprintf("%s (project #%d) — room %s", cfg.Name, cfg.ProjectID, cfg.NotifyRoomId)
+			if cfg.Status != "active" {
+				line += " [" + cfg.Status + "]"
+			}
+			section.Lines = append(section.Lines, line)
+		}
+		msg.Sections = append(msg.Sections, section)
+	}
+	if len(schedules) == 0 && len(configs) == 0 {
+		msg.Text += " No schedules or CVE configs use it."
+	}
+	return msg
+}
+
+func botLabel(bot *models.ChatworkBot) string {
+	if bot.Name != "" {
+		return bot.Name
+	}
+	return fmt.Sprintf("bot #%d", bot.ID)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+type fakeBotScheduleRepo struct {
+	repositories.IReminderScheduleRepository
+	schedules []models.ReminderSchedule
+}
+
+func (r *fakeBotScheduleRepo) GetByBotID(botID uint) ([]models.ReminderSchedule, error) {
+	return r.schedules, nil
+}
+
+type fakeBotCveConfigRepo struct {
+	repositories.ICveConfigRepository
+	configs []models.CveConfig
+}
+
+func (r *fakeBotCveConfigRepo) GetByBotID(botID uint) ([]models.CveConfig, error) {
+	return r.configs, nil
+}
+
+type sentAlert struct {
+	token, roomID string
+	msg           notifier.Message
+}
+
+type fakeAlertNotifier struct {
+	INotificationService
+	sent []sentAlert
+}
+
+func (n *fakeAlertNotifier) NotifyChatwork(token, roomID string, msg notifier.Message) error {
+	n.sent = append(n.sent, sentAlert{token, roomID, msg})
+	return nil
+}
+
+func TestBotHealthServiceAlertsOnTransitions(t *testing.T) {
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("token", chatwork.Me{AccountID: 1, Name: "Bot"})
+
+	bots := &fakeBotRepo{bots: map[uint]*models.ChatworkBot{7: {ID: 7, Name: "Reminder Bot", APIToken: "revoked", HealthStatus: models.BotHealthHealthy}}}
+	schedules := &fakeBotScheduleRepo{schedules: []models.ReminderSchedule{
+		{ID: 3, Name: "Standup", ChatworkRoomID: "42", Active: true, Project: models.Project{Name: "Apollo"}},
+		{ID: 4, Name: "Retro", ChatworkRoomID: "43"},
+	}}
+	configs := &fakeBotCveConfigRepo{configs: []models.CveConfig{{Name: "Backend deps", ProjectID: 2, NotifyRoomId: "44", Status: "active"}}}
+	alerts := &fakeAlertNotifier{}
+
+	svc := NewBotHealthService(bots, schedules, configs, alerts)
+	svc.baseURL = srv.URL
+	svc.admin = adminAlerts{notifications: alerts, roomID: "900", token: "admin-token"}
+
+	svc.CheckAll()
+	bot := bots.bots[7]
+	if bot.HealthStatus != models.BotHealthUnauthorized || bot.HealthCheckedAt == nil || bot.HealthError == "" {
+		t.Fatalf("expected unauthorized status to be stored, got %+v", bot)
+	}
+	if len(alerts.sent) != 1 {
+		t.Fatalf("expected one alert, got %d", len(alerts.sent))
+	}
+	alert := alerts.sent[0]
+	if alert.roomID != "900" || alert.token != "admin-token" || alert.msg.Severity != notifier.SeverityCritical {
+		t.Fatalf("unexpected alert delivery: %+v", alert)
+	}
+	body := notifier.RenderChatwork(alert.msg)
+	for _, want := range []string{"Reminder Bot", "#3 Standup (Apollo) — room 42", "#4 Retro — room 43 [paused]", "Backend deps (project #2) — room 44", "/bots/7/rotate-token"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("alert missing %q:\n%s", want, body)
+		}
+	}
+
+	// Still broken: no repeated alert.
+	svc.CheckAll()
+	if len(alerts.sent) != 1 {
+		t.Fatalf("expected no repeated alert, got %d", len(alerts.sent))
+	}
+
+	bots.bots[7].APIToken = "token"
+	svc.CheckAll()
+	if bots.bots[7].HealthStatus != models.BotHealthHealthy || bots.bots[7].HealthError != "" {
+		t.Fatalf("expected healthy status, got %+v", bots.bots[7])
+	}
+	if len(alerts.sent) != 2 || alerts.sent[1].msg.Severity != notifier.SeveritySuccess {
+		t.Fatalf("expected a recovery alert, got %+v", alerts.sent)
+	}
+}
+
+func TestBotHealthServiceIgnoresOutages(t *testing.T) {
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("token", chatwork.Me{AccountID: 1})
+	srv.FailNext("GET", "/me", 500, "maintenance")
+
+	bots := &fakeBotRepo{bots: map[uint]*models.ChatworkBot{7: {ID: 7, APIToken: "token", HealthStatus: models.BotHealthHealthy}}}
+	alerts := &fakeAlertNotifier{}
+	svc := NewBotHealthService(bots, &fakeBotScheduleRepo{}, &fakeBotCveConfigRepo{}, alerts)
+	svc.baseURL = srv.URL
+	svc.admin = adminAlerts{notifications: alerts, roomID: "900", token: "admin-token"}
+
+	bot, err := svc.Check(7)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if bot.HealthStatus != models.BotHealthHealthy || bot.HealthError == "" || len(alerts.sent) != 0 {
+		t.Fatalf("expected an outage to keep the status and not alert, got %+v, %d alerts", bot, len(alerts.sent))
+	}
+}
+
+func TestChatworkBotServiceRotateToken(t *testing.T) {
+	svc, bots, _ := newDirectoryTestService(t)
+	if _, err := svc.SyncBot(7); err != nil {
+		t.Fatalf("SyncBot: %v", err)
+	}
+	bots.bots[7].APIToken = "revoked"
+	bots.bots[7].HealthStatus = models.BotHealthUnauthorized
+
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("new-token", chatwork.Me{AccountID: 1, Name: "Bot renamed"})
+	srv.AddAccount("other-account", chatwork.Me{AccountID: 9})
+	svc.baseURL = srv.URL
+
+	_, err := svc.RotateToken(7, "other-account")
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != errors.ErrInvalidData || !strings.Contains(appErr.Message, "account 9") {
+		t.Fatalf("expected a different-account error, got %v", err)
+	}
+	if _, err := svc.RotateToken(7, "bogus"); err == nil {
+		t.Fatal("expected an error for an invalid token")
+	}
+	if bots.bots[7].APIToken != "revoked" {
+		t.Fatal("expected rejected rotations to leave the token unchanged")
+	}
+
+	detail, err := svc.RotateToken(7, "new-token")
+	if err != nil {
+		t.Fatalf("RotateToken: %v", err)
+	}
+	stored := bots.bots[7]
+	if stored.APIToken != "new-token" || stored.HealthStatus != models.BotHealthHealthy || detail.Name != "Bot renamed" || detail.HealthStatus != models.BotHealthHealthy {
+		t.Fatalf("unexpected state after rotation: %+v / %+v", stored, detail)
+	}
+}
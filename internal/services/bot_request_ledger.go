@@ -0,0 +1,79 @@
+package services
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+)
+
+// botRequestLedger keeps the local bot_requests table in step with the
+// pending requests Chatwork lists for each bot.
+type botRequestLedger struct {
+	repo repositories.IBotRequestRepository
+}
+
+// sync records the bot's live pending requests and expires stored pending
+// requests Chatwork no longer lists, i.e. ones answered outside this service.
+func (l botRequestLedger) sync(botID uint, live []chatwork.IncomingRequest, now time.Time) error {
+	listed := make(map[int64]bool, len(live))
+	for _, req := range live {
+		listed[req.RequestID] = true
+		stored, err := l.repo.Get(botID, req.RequestID)
+		if err != nil {
+			return err
+		}
+		if stored == nil {
+			stored = &models.BotRequest{BotID: botID, RequestID: req.RequestID, FirstSeenAt: now}
+		}
+		stored.AccountID = req.AccountID
+		stored.Name = req.Name
+		stored.ChatworkID = req.ChatworkID
+		stored.OrganizationName = req.OrganizationName
+		stored.Department = req.Department
+		stored.AvatarImageURL = req.AvatarImageURL
+		stored.Message = req.Message
+		stored.LastSeenAt = now
+		// A request that reappears after expiring was sent again.
+		if stored.Status == "" || stored.Status == models.BotRequestExpired {
+			stored.Status = models.BotRequestPending
+			stored.DecidedAt = nil
+			stored.DecidedBy = ""
+		}
+		if err := l.repo.Save(stored); err != nil {
+			return err
+		}
+	}
+
+	pending, err := l.repo.ListPending(botID)
+	if err != nil {
+		return err
+	}
+	for i := range pending {
+		if listed[pending[i].RequestID] {
+			continue
+		}
+		pending[i].Status = models.BotRequestExpired
+		pending[i].DecidedAt = &now
+		if err := l.repo.Save(&pending[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decide records that actor accepted or rejected the request.
+func (l botRequestLedger) decide(botID uint, requestID int64, status, actor string, now time.Time) error {
+	stored, err := l.repo.Get(botID, requestID)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		stored = &models.BotRequest{BotID: botID, RequestID: requestID, FirstSeenAt: now, LastSeenAt: now}
+	}
+	stored.Status = status
+	stored.DecidedAt = &now
+	stored.DecidedBy = actor
+	return l.repo.Save(stored)
+}
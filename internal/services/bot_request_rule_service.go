@@ -0,0 +1,409 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// BotRequestRuleInput holds the writable fields of a rule. Nil pointers are
+// left unchanged on update; a BotID of 0 makes the rule apply to every bot.
+type BotRequestRuleInput struct {
+	Name     *string
+	BotID    *uint
+	Field    *string
+	Values   []string
+	Action   *string
+	Priority *int
+	Active   *bool
+}
+
+// BotRequestRunResult counts what one pass over the pending requests did.
+type BotRequestRunResult struct {
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+	Queued   int `json:"queued"`
+	Failed   int `json:"failed"`
+	// Errors lists bots whose pending requests could not be read.
+	Errors []string `json:"errors,omitempty"`
+}
+
+type IBotRequestRuleService interface {
+	GetAll() ([]models.BotRequestRule, error)
+	Create(input *BotRequestRuleInput) (*models.BotRequestRule, error)
+	Update(id uint, input *BotRequestRuleInput) (*models.BotRequestRule, error)
+	Delete(id uint) error
+	// Run decides every bot's pending contact requests by rule. Requests no
+	// rule matches are queued for the admins, who are notified once per request.
+	Run() *BotRequestRunResult
+	GetAudit(filter repositories.BotRequestAuditFilter, paging *utils.Paging) ([]models.BotRequestAudit, int64, error)
+	// RecordManual adds actor's accept or reject of compositeID to the audit
+	// trail. err is the Chatwork failure, if any.
+	RecordManual(compositeID, decision, actor string, err error)
+}
+
+type BotRequestRuleService struct {
+	repo     repositories.IBotRequestRuleRepository
+	botRepo  repositories.IChatworkBotRepository
+	requests botRequestLedger
+	admin    adminAlerts
+	baseURL  string
+	now      func() time.Time
+}
+
+// botRequestRuns serialises rule runs, from the cron job and the API alike,
+// so a request is never decided or queued twice.
+var botRequestRuns sync.Mutex
+
+func NewBotRequestRuleService(
+	repo repositories.IBotRequestRuleRepository,
+	requestRepo repositories.IBotRequestRepository,
+	botRepo repositories.IChatworkBotRepository,
+	notifications INotificationService,
+) *BotRequestRuleService {
+	return &BotRequestRuleService{
+		repo:     repo,
+		botRepo:  botRepo,
+		requests: botRequestLedger{repo: requestRepo},
+		admin:    newAdminAlerts(notifications),
+		baseURL:  chatworkBaseURL(),
+		now:      time.Now,
+	}
+}
+
+func (s *BotRequestRuleService) GetAll() ([]models.BotRequestRule, error) {
+	rules, err := s.repo.List()
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return rules, nil
+}
+
+func (s *BotRequestRuleService) Create(input *BotRequestRuleInput) (*models.BotRequestRule, error) {
+	rule := &models.BotRequestRule{Active: true}
+	applyBotRequestRuleInput(rule, input)
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(rule)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return created, nil
+}
+
+func (s *BotRequestRuleService) Update(id uint, input *BotRequestRuleInput) (*models.BotRequestRule, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(errors.ErrResourceNotFound, "rule not found")
+	}
+
+	applyBotRequestRuleInput(rule, input)
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.Update(rule)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return updated, nil
+}
+
+func (s *BotRequestRuleService) Delete(id uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return errors.New(errors.ErrResourceNotFound, "rule not found")
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *BotRequestRuleService) GetAudit(filter repositories.BotRequestAuditFilter, paging *utils.Paging) ([]models.BotRequestAudit, int64, error) {
+	entries, total, err := s.repo.ListAudits(filter, paging)
+	if err != nil {
+		return nil, 0, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return entries, total, nil
+}
+
+func (s *BotRequestRuleService) Run() *BotRequestRunResult {
+	botRequestRuns.Lock()
+	defer botRequestRuns.Unlock()
+
+	result := &BotRequestRunResult{}
+	rules, err := s.repo.ListActive()
+	if err != nil {
+		logger.Errorf("[BotRequests] Failed to load rules: %v", err)
+		result.Errors = append(result.Errors, fmt.Sprintf("loading rules: %v", err))
+		return result
+	}
+	bots, err := s.botRepo.ListAll()
+	if err != nil {
+		logger.Errorf("[BotRequests] Failed to load bots: %v", err)
+		result.Errors = append(result.Errors, fmt.Sprintf("loading bots: %v", err))
+		return result
+	}
+
+	var queued []notifier.Section
+	for i := range bots {
+		bot := &bots[i]
+		client := chatwork.NewClient(bot.APIToken, chatwork.WithBaseURL(s.baseURL))
+		requests, err := s.pending(client)
+		if err != nil {
+			logger.Errorf("[BotRequests] bot_id=%d failed to list incoming requests: %v", bot.ID, err)
+			result.Errors = append(result.Errors, fmt.Sprintf("bot #%d: %v", bot.ID, err))
+			continue
+		}
+		if err := s.requests.sync(bot.ID, requests, s.now()); err != nil {
+			logger.Errorf("[BotRequests] bot_id=%d failed to store incoming requests: %v", bot.ID, err)
+		}
+
+		section := notifier.Section{Title: botLabel(bot)}
+		for _, req := range requests {
+			if rule := matchBotRequestRule(rules, bot.ID, req); rule != nil {
+				s.decide(client, bot.ID, req, rule, result)
+				continue
+			}
+			if s.queue(bot.ID, req) {
+				result.Queued++
+				section.Lines = append(section.Lines, describeBotRequest(bot.ID, req))
+			}
+		}
+		if len(section.Lines) > 0 {
+			queued = append(queued, section)
+		}
+	}
+
+	if result.Queued > 0 {
+		s.admin.send("[BotRequests]", notifier.Message{
+			Title:    fmt.Sprintf("%d contact request(s) awaiting review", result.Queued),
+			Text:     "No auto-accept rule matched these Chatwork contact requests.",
+			Severity: notifier.SeverityWarning,
+			Sections: queued,
+			Footer:   "Accept with POST /api/v2/bot-requests/{id}/accept or reject with DELETE /api/v2/bot-requests/{id}",
+		})
+	}
+	logger.Infof("[BotRequests] Rules run: %d accepted, %d rejected, %d queued, %d failed",
+		result.Accepted, result.Rejected, result.Queued, result.Failed)
+	return result
+}
+
+func (s *BotRequestRuleService) RecordManual(compositeID, decision, actor string, err error) {
+	botID, requestID, parseErr := parseCompositeID(compositeID)
+	if parseErr != nil {
+		return
+	}
+
+	entry := &models.BotRequestAudit{BotID: botID, RequestID: requestID, Decision: decision, Source: models.BotRequestSourceAdmin, Actor: actor}
+	// The poller saw the request before queueing it; reuse the sender it recorded.
+	if previous, _ := s.repo.LatestAudit(botID, requestID); previous != nil {
+		entry.AccountID = previous.AccountID
+		entry.Name = previous.Name
+		entry.ChatworkID = previous.ChatworkID
+		entry.OrganizationName = previous.OrganizationName
+		entry.Message = previous.Message
+	}
+	if err != nil {
+		entry.Decision = models.BotRequestFailed
+		entry.Error = fmt.Sprintf("%s: %v", decision, err)
+	}
+	s.audit(entry)
+}
+
+func (s *BotRequestRuleService) pending(client *chatwork.Client) ([]chatwork.IncomingRequest, error) {
+	ctx, cancel := context.WithTimeout(chatwork.WithPriority(context.Background(), chatwork.PriorityLow), chatworkQueueTimeout)
+	defer cancel()
+	return client.IncomingRequests(ctx)
+}
+
+// decide applies a matched rule to the request and records the outcome.
+func (s *BotRequestRuleService) decide(client *chatwork.Client, botID uint, req chatwork.IncomingRequest, rule *models.BotRequestRule, result *BotRequestRunResult) {
+	ctx, cancel := context.WithTimeout(chatwork.WithPriority(context.Background(), chatwork.PriorityLow), chatworkQueueTimeout)
+	defer cancel()
+
+	entry := newBotRequestAudit(botID, req, models.BotRequestSourceRules)
+	entry.RuleID = &rule.ID
+	entry.RuleName = rule.Name
+
+	var err error
+	if rule.Action == models.BotRequestActionAccept {
+		_, err = client.AcceptIncomingRequest(ctx, req.RequestID)
+		entry.Decision = models.BotRequestAccepted
+	} else {
+		err = client.RejectIncomingRequest(ctx, req.RequestID)
+		entry.Decision = models.BotRequestRejected
+	}
+
+	switch {
+	case err != nil:
+		logger.Errorf("[BotRequests] bot_id=%d request_id=%d rule %q failed to %s: %v", botID, req.RequestID, rule.Name, rule.Action, err)
+		entry.Decision = models.BotRequestFailed
+		entry.Error = fmt.Sprintf("%s: %v", rule.Action, err)
+		result.Failed++
+	case entry.Decision == models.BotRequestAccepted:
+		logger.Infof("[BotRequests] bot_id=%d request_id=%d accepted by rule %q", botID, req.RequestID, rule.Name)
+		result.Accepted++
+	default:
+		logger.Infof("[BotRequests] bot_id=%d request_id=%d rejected by rule %q", botID, req.RequestID, rule.Name)
+		result.Rejected++
+	}
+	if err == nil {
+		if err := s.requests.decide(botID, req.RequestID, entry.Decision, "rule:"+rule.Name, s.now()); err != nil {
+			logger.Errorf("[BotRequests] bot_id=%d request_id=%d failed to record %s: %v", botID, req.RequestID, entry.Decision, err)
+		}
+	}
+	s.audit(entry)
+}
+
+// queue records an unmatched request as awaiting review. It reports false when
+// the request is already queued, so admins are only notified about it once.
+func (s *BotRequestRuleService) queue(botID uint, req chatwork.IncomingRequest) bool {
+	previous, err := s.repo.LatestAudit(botID, req.RequestID)
+	if err != nil {
+		logger.Errorf("[BotRequests] bot_id=%d request_id=%d failed to read audit: %v", botID, req.RequestID, err)
+		return false
+	}
+	if previous != nil && previous.Decision == models.BotRequestQueued {
+		return false
+	}
+	entry := newBotRequestAudit(botID, req, models.BotRequestSourceRules)
+	entry.Decision = models.BotRequestQueued
+	return s.audit(entry)
+}
+
+func (s *BotRequestRuleService) audit(entry *models.BotRequestAudit) bool {
+	if err := s.repo.CreateAudit(entry); err != nil {
+		logger.Errorf("[BotRequests] bot_id=%d request_id=%d failed to write audit (%s): %v", entry.BotID, entry.RequestID, entry.Decision, err)
+		return false
+	}
+	return true
+}
+
+func (s *BotRequestRuleService) validateRule(rule *models.BotRequestRule) error {
+	if rule.Name == "" {
+		return errors.New(errors.ErrInvalidData, "name is required")
+	}
+	switch rule.Field {
+	case models.BotRequestFieldOrganization, models.BotRequestFieldChatworkID, models.BotRequestFieldKeyword:
+	default:
+		return errors.New(errors.ErrInvalidData, "field must be one of: organization, chatwork_id, keyword")
+	}
+	if len(rule.Values) == 0 {
+		return errors.New(errors.ErrInvalidData, "values must contain at least one entry")
+	}
+	if rule.Action != models.BotRequestActionAccept && rule.Action != models.BotRequestActionReject {
+		return errors.New(errors.ErrInvalidData, "action must be accept or reject")
+	}
+	if rule.BotID != nil {
+		if _, err := s.botRepo.GetByID(*rule.BotID); err != nil {
+			return errors.New(errors.ErrInvalidData, "bot not found")
+		}
+	}
+	return nil
+}
+
+func applyBotRequestRuleInput(rule *models.BotRequestRule, input *BotRequestRuleInput) {
+	if input.Name != nil {
+		rule.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.BotID != nil {
+		rule.BotID = input.BotID
+		if *input.BotID == 0 {
+			rule.BotID = nil
+		}
+	}
+	if input.Field != nil {
+		rule.Field = strings.ToLower(strings.TrimSpace(*input.Field))
+	}
+	if input.Values != nil {
+		values := make([]string, 0, len(input.Values))
+		for _, v := range input.Values {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		rule.Values = values
+	}
+	if input.Action != nil {
+		rule.Action = strings.ToLower(strings.TrimSpace(*input.Action))
+	}
+	if input.Priority != nil {
+		rule.Priority = *input.Priority
+	}
+	if input.Active != nil {
+		rule.Active = *input.Active
+	}
+}
+
+// matchBotRequestRule returns the first rule that applies to the bot and
+// matches the request. rules must be in evaluation order.
+func matchBotRequestRule(rules []models.BotRequestRule, botID uint, req chatwork.IncomingRequest) *models.BotRequestRule {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.BotID != nil && *rule.BotID != botID {
+			continue
+		}
+		for _, v := range rule.Values {
+			if botRequestValueMatches(rule.Field, v, req) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+func botRequestValueMatches(field, value string, req chatwork.IncomingRequest) bool {
+	switch field {
+	case models.BotRequestFieldOrganization:
+		return strings.EqualFold(strings.TrimSpace(req.OrganizationName), value)
+	case models.BotRequestFieldChatworkID:
+		return strings.EqualFold(strings.TrimSpace(req.ChatworkID), value)
+	case models.BotRequestFieldKeyword:
+		return strings.Contains(strings.ToLower(req.Message), strings.ToLower(value))
+	}
+	return false
+}
+
+func newBotRequestAudit(botID uint, req chatwork.IncomingRequest, source string) *models.BotRequestAudit {
+	return &models.BotRequestAudit{
+		BotID:            botID,
+		RequestID:        req.RequestID,
+		AccountID:        req.AccountID,
+		Name:             req.Name,
+		ChatworkID:       req.ChatworkID,
+		OrganizationName: req.OrganizationName,
+		Message:          req.Message,
+		Source:           source,
+	}
+}
+
+// describeBotRequest renders one queued request for the admin alert.
+func describeBotRequest(botID uint, req chatwork.IncomingRequest) string {
+	line := fmt.Sprintf("%d_%d %s", botID, req.RequestID, req.Name)
+	if req.OrganizationName != "" {
+		line += " (" + req.OrganizationName + ")"
+	}
+	if req.ChatworkID != "" {
+		line += " @" + req.ChatworkID
+	}
+	if msg := strings.TrimSpace(req.Message); msg != "" {
+		if len([]rune(msg)) > 80 {
+			msg = string([]rune(msg)[:80]) + "…"
+		}
+		line += fmt.Sprintf(": %q", msg)
+	}
+	return line
+}
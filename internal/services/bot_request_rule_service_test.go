@@ -0,0 +1,190 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+type fakeBotRequestRuleRepo struct {
+	repositories.IBotRequestRuleRepository
+	rules  []models.BotRequestRule
+	audits []models.BotRequestAudit
+}
+
+func (r *fakeBotRequestRuleRepo) ListActive() ([]models.BotRequestRule, error) {
+	var active []models.BotRequestRule
+	for _, rule := range r.rules {
+		if rule.Active {
+			active = append(active, rule)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeBotRequestRuleRepo) Create(rule *models.BotRequestRule) (*models.BotRequestRule, error) {
+	rule.ID = uint(len(r.rules) + 1)
+	r.rules = append(r.rules, *rule)
+	return rule, nil
+}
+
+func (r *fakeBotRequestRuleRepo) CreateAudit(entry *models.BotRequestAudit) error {
+	entry.ID = uint(len(r.audits) + 1)
+	r.audits = append(r.audits, *entry)
+	return nil
+}
+
+func (r *fakeBotRequestRuleRepo) LatestAudit(botID uint, requestID int64) (*models.BotRequestAudit, error) {
+	for i := len(r.audits) - 1; i >= 0; i-- {
+		if r.audits[i].BotID == botID && r.audits[i].RequestID == requestID {
+			return &r.audits[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func newBotRequestRuleTestService(t *testing.T) (*BotRequestRuleService, *fakeBotRequestRuleRepo, *fakeAlertNotifier, *chatworktest.Server) {
+	t.Helper()
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("token-1", chatwork.Me{AccountID: 1})
+	srv.AddAccount("token-2", chatwork.Me{AccountID: 2})
+
+	bots := &fakeBotRepo{bots: map[uint]*models.ChatworkBot{
+		1: {ID: 1, Name: "Reminder Bot", APIToken: "token-1"},
+		2: {ID: 2, Name: "CVE Bot", APIToken: "token-2"},
+	}}
+	repo := &fakeBotRequestRuleRepo{}
+	alerts := &fakeAlertNotifier{}
+	svc := NewBotRequestRuleService(repo, newFakeBotRequestRepo(), bots, alerts)
+	svc.baseURL = srv.URL
+	svc.admin = adminAlerts{notifications: alerts, roomID: "900", token: "admin-token"}
+	return svc, repo, alerts, srv
+}
+
+func TestBotRequestRulesDecideAndQueue(t *testing.T) {
+	svc, repo, alerts, srv := newBotRequestRuleTestService(t)
+	botTwo := uint(2)
+	repo.rules = []models.BotRequestRule{
+		{ID: 1, Name: "Block spammer", Field: models.BotRequestFieldChatworkID, Values: []string{"spam_king"}, Action: models.BotRequestActionReject, Active: true},
+		{ID: 2, Name: "Our company", Field: models.BotRequestFieldOrganization, Values: []string{"VFA Corp"}, Action: models.BotRequestActionAccept, Active: true},
+		{ID: 3, Name: "CVE subscribers", BotID: &botTwo, Field: models.BotRequestFieldKeyword, Values: []string{"cve alerts"}, Action: models.BotRequestActionAccept, Active: true},
+		{ID: 4, Name: "Disabled", Field: models.BotRequestFieldKeyword, Values: []string{"hello"}, Action: models.BotRequestActionReject, Active: false},
+	}
+
+	srv.AddIncomingRequest("token-1", chatwork.IncomingRequest{RequestID: 11, AccountID: 101, Name: "Colleague", OrganizationName: "vfa corp"})
+	srv.AddIncomingRequest("token-1", chatwork.IncomingRequest{RequestID: 12, AccountID: 102, ChatworkID: "SPAM_KING", OrganizationName: "VFA Corp"})
+	srv.AddIncomingRequest("token-1", chatwork.IncomingRequest{RequestID: 13, AccountID: 103, Name: "Stranger", Message: "Please add me for CVE alerts, hello"})
+	srv.AddIncomingRequest("token-2", chatwork.IncomingRequest{RequestID: 21, AccountID: 104, Name: "Ops", Message: "I want CVE Alerts"})
+
+	result := svc.Run()
+	if result.Accepted != 2 || result.Rejected != 1 || result.Queued != 1 || result.Failed != 0 || len(result.Errors) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(srv.IncomingRequests("token-1")) != 1 || len(srv.IncomingRequests("token-2")) != 0 {
+		t.Fatal("expected only the unmatched request to stay pending in Chatwork")
+	}
+
+	decisions := map[int64]models.BotRequestAudit{}
+	for _, a := range repo.audits {
+		decisions[a.RequestID] = a
+	}
+	for requestID, want := range map[int64]string{11: models.BotRequestAccepted, 12: models.BotRequestRejected, 13: models.BotRequestQueued, 21: models.BotRequestAccepted} {
+		if decisions[requestID].Decision != want {
+			t.Fatalf("request %d: expected %s, got %+v", requestID, want, decisions[requestID])
+		}
+	}
+	if a := decisions[12]; a.RuleName != "Block spammer" || a.RuleID == nil || *a.RuleID != 1 || a.Source != models.BotRequestSourceRules {
+		t.Fatalf("expected the first matching rule to be recorded, got %+v", a)
+	}
+
+	requests := svc.requests.repo.(*fakeBotRequestRepo)
+	if r := requests.rows[botRequestKey{1, 11}]; r.Status != models.BotRequestAccepted || r.DecidedBy != "rule:Our company" {
+		t.Fatalf("expected the rule's decision in the request history, got %+v", r)
+	}
+	if r := requests.rows[botRequestKey{1, 13}]; r.Status != models.BotRequestPending {
+		t.Fatalf("expected the queued request to stay pending, got %+v", r)
+	}
+
+	if len(alerts.sent) != 1 {
+		t.Fatalf("expected one admin alert, got %d", len(alerts.sent))
+	}
+	body := notifier.RenderChatwork(alerts.sent[0].msg)
+	if !strings.Contains(body, "1_13 Stranger") || !strings.Contains(body, "Reminder Bot") {
+		t.Fatalf("alert should list the queued request:\n%s", body)
+	}
+
+	// The queued request is neither queued nor announced again.
+	result = svc.Run()
+	if result.Queued != 0 || len(alerts.sent) != 1 || len(repo.audits) != 4 {
+		t.Fatalf("expected no repeat, got %+v, %d alerts, %d audits", result, len(alerts.sent), len(repo.audits))
+	}
+}
+
+func TestBotRequestRulesReportBotErrors(t *testing.T) {
+	svc, _, _, srv := newBotRequestRuleTestService(t)
+	srv.FailNext("GET", "/incoming_requests", 500, "maintenance")
+	srv.AddIncomingRequest("token-2", chatwork.IncomingRequest{RequestID: 21, Name: "Ops"})
+
+	result := svc.Run()
+	if len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0], "bot #1") || result.Queued != 1 {
+		t.Fatalf("expected bot #1 to fail and bot #2 to still be processed, got %+v", result)
+	}
+}
+
+func TestBotRequestRuleValidation(t *testing.T) {
+	svc, _, _, _ := newBotRequestRuleTestService(t)
+	str := func(s string) *string { return &s }
+	missingBot := uint(99)
+
+	cases := map[string]*BotRequestRuleInput{
+		"name":   {Field: str("keyword"), Values: []string{"x"}, Action: str("accept")},
+		"field":  {Name: str("r"), Field: str("email"), Values: []string{"x"}, Action: str("accept")},
+		"values": {Name: str("r"), Field: str("keyword"), Values: []string{" "}, Action: str("accept")},
+		"action": {Name: str("r"), Field: str("keyword"), Values: []string{"x"}, Action: str("ignore")},
+		"bot":    {Name: str("r"), BotID: &missingBot, Field: str("keyword"), Values: []string{"x"}, Action: str("accept")},
+	}
+	for name, input := range cases {
+		_, err := svc.Create(input)
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != errors.ErrInvalidData {
+			t.Fatalf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+
+	allBots := uint(0)
+	rule, err := svc.Create(&BotRequestRuleInput{Name: str(" Partners "), BotID: &allBots, Field: str("Chatwork_ID"), Values: []string{" alice ", ""}, Action: str("Reject")})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if rule.Name != "Partners" || rule.BotID != nil || rule.Field != models.BotRequestFieldChatworkID || len(rule.Values) != 1 || rule.Action != models.BotRequestActionReject || !rule.Active {
+		t.Fatalf("unexpected rule: %+v", rule)
+	}
+}
+
+func TestBotRequestRecordManual(t *testing.T) {
+	svc, repo, _, srv := newBotRequestRuleTestService(t)
+	srv.AddIncomingRequest("token-1", chatwork.IncomingRequest{RequestID: 13, AccountID: 103, Name: "Stranger", OrganizationName: "Acme"})
+	svc.Run()
+
+	svc.RecordManual("1_13", models.BotRequestAccepted, "admin", nil)
+	svc.RecordManual("2_99", models.BotRequestRejected, "user:5", errors.New(errors.ErrInvalidData, "not found"))
+	svc.RecordManual("garbage", models.BotRequestAccepted, "admin", nil)
+
+	if len(repo.audits) != 3 {
+		t.Fatalf("expected queued + 2 manual entries, got %d", len(repo.audits))
+	}
+	accepted := repo.audits[1]
+	if accepted.Decision != models.BotRequestAccepted || accepted.Source != models.BotRequestSourceAdmin || accepted.Actor != "admin" || accepted.Name != "Stranger" || accepted.OrganizationName != "Acme" {
+		t.Fatalf("expected the manual accept to reuse the queued sender, got %+v", accepted)
+	}
+	failed := repo.audits[2]
+	if failed.Decision != models.BotRequestFailed || failed.Actor != "user:5" || !strings.Contains(failed.Error, "not found") {
+		t.Fatalf("expected a failed manual decision, got %+v", failed)
+	}
+}
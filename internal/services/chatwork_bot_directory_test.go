@@ -0,0 +1,183 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+	"gorm.io/gorm"
+)
+
+type fakeBotRepo struct {
+	repositories.IChatworkBotRepository
+	bots map[uint]*models.ChatworkBot
+}
+
+func (r *fakeBotRepo) GetByID(id uint) (*models.ChatworkBot, error) {
+	bot, ok := r.bots[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *bot
+	return &copied, nil
+}
+
+func (r *fakeBotRepo) ListAll() ([]models.ChatworkBot, error) {
+	var bots []models.ChatworkBot
+	for _, bot := range r.bots {
+		bots = append(bots, *bot)
+	}
+	// Same order as the real repository, so runs over several bots are deterministic.
+	sort.Slice(bots, func(i, j int) bool { return bots[i].ID < bots[j].ID })
+	return bots, nil
+}
+
+func (r *fakeBotRepo) UpdateProfile(bot *models.ChatworkBot) error {
+	copied := *bot
+	r.bots[bot.ID] = &copied
+	return nil
+}
+
+func (r *fakeBotRepo) UpdateHealth(bot *models.ChatworkBot) error {
+	stored := r.bots[bot.ID]
+	stored.HealthStatus, stored.HealthCheckedAt, stored.HealthError = bot.HealthStatus, bot.HealthCheckedAt, bot.HealthError
+	return nil
+}
+
+func (r *fakeBotRepo) RotateToken(bot *models.ChatworkBot) error {
+	return r.UpdateProfile(bot)
+}
+
+func (r *fakeBotRepo) UpdateWebhookToken(bot *models.ChatworkBot) error {
+	r.bots[bot.ID].WebhookToken = bot.WebhookToken
+	return nil
+}
+
+type fakeBotRoomRepo struct {
+	repositories.IChatworkBotRoomRepository
+	rooms   map[uint][]models.ChatworkBotRoom
+	members map[uint][]models.ChatworkBotRoomMember
+}
+
+func (r *fakeBotRoomRepo) ReplaceRooms(botID uint, rooms []models.ChatworkBotRoom, members []models.ChatworkBotRoomMember) error {
+	r.rooms[botID], r.members[botID] = rooms, members
+	return nil
+}
+
+func (r *fakeBotRoomRepo) ListRooms(botID uint, search, roomType string, paging *utils.Paging) ([]models.ChatworkBotRoom, int64, error) {
+	return r.rooms[botID], int64(len(r.rooms[botID])), nil
+}
+
+func (r *fakeBotRoomRepo) GetRoom(botID uint, roomID int64) (*models.ChatworkBotRoom, error) {
+	for _, room := range r.rooms[botID] {
+		if room.RoomID == roomID {
+			return &room, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeBotRoomRepo) ListMembers(botID uint, roomID int64) ([]models.ChatworkBotRoomMember, error) {
+	var members []models.ChatworkBotRoomMember
+	for _, m := range r.members[botID] {
+		if m.RoomID == roomID {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func newDirectoryTestService(t *testing.T) (*ChatworkBotService, *fakeBotRepo, *fakeBotRoomRepo) {
+	t.Helper()
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("token", chatwork.Me{AccountID: 1, Name: "Bot", ChatworkID: "bot"})
+	srv.AddRoom(chatwork.Room{RoomID: 10, Name: "My chat", Type: chatwork.RoomTypeMy}, chatwork.Member{AccountID: 1, Role: chatwork.RoleAdmin})
+	srv.AddRoom(chatwork.Room{RoomID: 42, Name: "Team", Type: chatwork.RoomTypeGroup},
+		chatwork.Member{AccountID: 1, Role: chatwork.RoleMember},
+		chatwork.Member{AccountID: 2, Role: chatwork.RoleAdmin, Name: "Alice"},
+	)
+	srv.AddRoom(chatwork.Room{RoomID: 43, Name: "Announcements", Type: chatwork.RoomTypeGroup},
+		chatwork.Member{AccountID: 1, Role: chatwork.RoleReadonly},
+	)
+	srv.AddRoom(chatwork.Room{RoomID: 99, Name: "Elsewhere", Type: chatwork.RoomTypeGroup})
+
+	bots := &fakeBotRepo{bots: map[uint]*models.ChatworkBot{7: {ID: 7, APIToken: "token"}}}
+	rooms := &fakeBotRoomRepo{rooms: map[uint][]models.ChatworkBotRoom{}, members: map[uint][]models.ChatworkBotRoomMember{}}
+	svc := NewChatworkBotService(bots, rooms, nil, newFakeBotRequestRepo())
+	svc.baseURL = srv.URL
+	return svc, bots, rooms
+}
+
+func TestChatworkBotServiceSyncBot(t *testing.T) {
+	svc, bots, rooms := newDirectoryTestService(t)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	detail, err := svc.SyncBot(7)
+	if err != nil {
+		t.Fatalf("SyncBot: %v", err)
+	}
+	if detail.Name != "Bot" || detail.RoomsCount != 3 || detail.SyncedAt == nil || !detail.SyncedAt.Equal(now) {
+		t.Fatalf("unexpected detail: %+v", detail)
+	}
+	if bots.bots[7].AccountID != 1 {
+		t.Fatalf("expected cached profile to be stored, got %+v", bots.bots[7])
+	}
+
+	cached, _, _ := rooms.ListRooms(7, "", "", nil)
+	if len(cached) != 3 {
+		t.Fatalf("expected 3 cached rooms, got %+v", cached)
+	}
+	team, err := rooms.GetRoom(7, 42)
+	if err != nil || team.Name != "Team" || team.Role != chatwork.RoleMember || team.MemberCount != 2 {
+		t.Fatalf("unexpected team room: %+v, %v", team, err)
+	}
+	if members, _ := svc.GetRoomMembers(7, 42); len(members) != 2 || members[1].Name != "Alice" {
+		t.Fatalf("unexpected members: %+v", members)
+	}
+	if _, err := svc.GetRoomMembers(7, 99); err == nil {
+		t.Fatal("expected an error for a room outside the bot's directory")
+	}
+}
+
+func TestChatworkBotServiceSyncRecordsFailure(t *testing.T) {
+	svc, bots, _ := newDirectoryTestService(t)
+	bots.bots[7].APIToken = "revoked"
+
+	if _, err := svc.SyncBot(7); err == nil {
+		t.Fatal("expected sync to fail for a rejected token")
+	}
+	if bots.bots[7].SyncError == "" || bots.bots[7].SyncedAt != nil {
+		t.Fatalf("expected sync error to be recorded, got %+v", bots.bots[7])
+	}
+}
+
+func TestChatworkBotServiceRoomWarnings(t *testing.T) {
+	svc, _, _ := newDirectoryTestService(t)
+	if warnings := svc.RoomWarnings(7, "99", nil); warnings != nil {
+		t.Fatalf("expected no warnings before the first sync, got %v", warnings)
+	}
+	if _, err := svc.SyncBot(7); err != nil {
+		t.Fatalf("SyncBot: %v", err)
+	}
+
+	if warnings := svc.RoomWarnings(7, "42", []int64{2}); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+	if warnings := svc.RoomWarnings(7, "99", nil); len(warnings) != 1 || !strings.Contains(warnings[0], "not a member of room 99") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	if warnings := svc.RoomWarnings(7, "43", nil); len(warnings) != 1 || !strings.Contains(warnings[0], "read-only") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	if warnings := svc.RoomWarnings(7, "42", []int64{2, 5}); len(warnings) != 1 || !strings.Contains(warnings[0], "account 5") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+)
+
+type botRequestKey struct {
+	botID     uint
+	requestID int64
+}
+
+type fakeBotRequestRepo struct {
+	rows map[botRequestKey]*models.BotRequest
+}
+
+func newFakeBotRequestRepo() *fakeBotRequestRepo {
+	return &fakeBotRequestRepo{rows: map[botRequestKey]*models.BotRequest{}}
+}
+
+func (r *fakeBotRequestRepo) Get(botID uint, requestID int64) (*models.BotRequest, error) {
+	if row, ok := r.rows[botRequestKey{botID, requestID}]; ok {
+		cp := *row
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeBotRequestRepo) ListPending(botID uint) ([]models.BotRequest, error) {
+	var pending []models.BotRequest
+	for _, row := range r.rows {
+		if row.BotID == botID && row.Status == models.BotRequestPending {
+			pending = append(pending, *row)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeBotRequestRepo) List(filter repositories.BotRequestFilter, paging *utils.Paging) ([]models.BotRequest, int64, error) {
+	var rows []models.BotRequest
+	for _, row := range r.rows {
+		if (filter.BotID == 0 || row.BotID == filter.BotID) && (filter.Status == "" || row.Status == filter.Status) {
+			rows = append(rows, *row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].RequestID < rows[j].RequestID })
+	return rows, int64(len(rows)), nil
+}
+
+func (r *fakeBotRequestRepo) Save(request *models.BotRequest) error {
+	cp := *request
+	r.rows[botRequestKey{request.BotID, request.RequestID}] = &cp
+	return nil
+}
+
+func TestGetBotRequestsKeepsHistory(t *testing.T) {
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("token-1", chatwork.Me{AccountID: 1})
+	srv.AddIncomingRequest("token-1", chatwork.IncomingRequest{RequestID: 11, AccountID: 101, Name: "Alice", Message: "hi"})
+	srv.AddIncomingRequest("token-1", chatwork.IncomingRequest{RequestID: 12, AccountID: 102, Name: "Bob"})
+	srv.AddIncomingRequest("token-1", chatwork.IncomingRequest{RequestID: 13, AccountID: 103, Name: "Carol"})
+
+	bots := &fakeBotRepo{bots: map[uint]*models.ChatworkBot{
+		1: {ID: 1, Name: "Reminder Bot", APIToken: "token-1"},
+		2: {ID: 2, Name: "Revoked Bot", APIToken: "revoked"},
+	}}
+	requests := newFakeBotRequestRepo()
+	svc := NewChatworkBotService(bots, nil, nil, requests)
+	svc.baseURL = srv.URL
+	firstSeen := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return firstSeen }
+	paging := &utils.Paging{Page: 1, Limit: 50}
+
+	items, total, syncErrors, err := svc.GetBotRequests(repositories.BotRequestFilter{}, paging)
+	if err != nil {
+		t.Fatalf("GetBotRequests: %v", err)
+	}
+	if total != 3 || len(items) != 3 {
+		t.Fatalf("expected 3 requests, got %d", total)
+	}
+	if len(syncErrors) != 1 || syncErrors[0].BotID != 2 || syncErrors[0].BotName != "Revoked Bot" {
+		t.Fatalf("expected the revoked bot to be reported, got %+v", syncErrors)
+	}
+	if items[0].ID != "1_11" || items[0].Status != models.BotRequestPending || !items[0].FirstSeenAt.Equal(firstSeen) ||
+		items[0].SenderInfo.Name != "Alice" || items[0].BotInfo == nil || items[0].BotInfo.Name != "Reminder Bot" {
+		t.Fatalf("unexpected item: %+v", items[0])
+	}
+
+	later := firstSeen.Add(time.Hour)
+	svc.now = func() time.Time { return later }
+	if err := svc.AcceptBotRequest("1_11", "user:3"); err != nil {
+		t.Fatalf("AcceptBotRequest: %v", err)
+	}
+	if err := svc.DeleteBotRequest("1_12", "admin"); err != nil {
+		t.Fatalf("DeleteBotRequest: %v", err)
+	}
+	// Answered in the Chatwork app: disappears without a decision here.
+	srv.AddIncomingRequest("token-1", chatwork.IncomingRequest{RequestID: 14, Name: "Dave"})
+	if err := svc.client("token-1").RejectIncomingRequest(t.Context(), 13); err != nil {
+		t.Fatalf("reject in Chatwork: %v", err)
+	}
+
+	items, _, _, err = svc.GetBotRequests(repositories.BotRequestFilter{}, paging)
+	if err != nil {
+		t.Fatalf("GetBotRequests: %v", err)
+	}
+	want := map[string]string{"1_11": models.BotRequestAccepted, "1_12": models.BotRequestRejected, "1_13": models.BotRequestExpired, "1_14": models.BotRequestPending}
+	if len(items) != len(want) {
+		t.Fatalf("expected %d requests, got %+v", len(want), items)
+	}
+	for _, item := range items {
+		if item.Status != want[item.ID] {
+			t.Fatalf("%s: expected %s, got %s", item.ID, want[item.ID], item.Status)
+		}
+	}
+	accepted := items[0]
+	if accepted.DecidedBy != "user:3" || accepted.DecidedAt == nil || !accepted.DecidedAt.Equal(later) || !accepted.FirstSeenAt.Equal(firstSeen) {
+		t.Fatalf("expected the accept to keep first-seen time and record the actor, got %+v", accepted)
+	}
+	if items[2].DecidedBy != "" || items[2].DecidedAt == nil {
+		t.Fatalf("expected the expired request to have no actor, got %+v", items[2])
+	}
+
+	pending, _, _, _ := svc.GetBotRequests(repositories.BotRequestFilter{Status: models.BotRequestPending}, paging)
+	if len(pending) != 1 || pending[0].ID != "1_14" {
+		t.Fatalf("expected only the new request to be pending, got %+v", pending)
+	}
+}
@@ -1,46 +1,84 @@
 package services
 
 import (
-	"encoding/json"
+	"context"
+	"encoding/base64"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/vfa-khuongdv/golang-cms/internal/models"
 	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
 	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
 )
 
-const chatworkAPIBase = "https://api.chatwork.com/v2"
+const (
+	// chatworkCallTimeout bounds an interactive Chatwork API call, including its wait in the outbound queue.
+	chatworkCallTimeout = 30 * time.Second
+	// chatworkQueueTimeout bounds background sends, which may queue behind a burst of other messages.
+	chatworkQueueTimeout = 5 * time.Minute
+)
 
 type IChatworkBotService interface {
 	GetAll(paging *utils.Paging) ([]models.BotDetail, int64, error)
 	GetBotByID(id uint) (*models.ChatworkBot, error)
 	Create(apiToken string, email *string, description string) (*models.BotDetail, error)
 	Delete(id uint) error
-	GetBotRequests(status string) ([]models.BotRequestItem, error)
-	AcceptBotRequest(compositeID string) error
-	DeleteBotRequest(compositeID string) error
+	// GetBotRequests syncs the bots' pending contact requests into the local
+	// history and lists it. Bots that can't be read are reported in the sync
+	// errors; the listing still succeeds with their last synced state.
+	GetBotRequests(filter repositories.BotRequestFilter, paging *utils.Paging) ([]models.BotRequestItem, int64, []models.BotRequestSyncError, error)
+	// AcceptBotRequest and DeleteBotRequest record actor as the deciding user.
+	AcceptBotRequest(compositeID, actor string) error
+	DeleteBotRequest(compositeID, actor string) error
+
+	// SyncBot refreshes the cached profile, rooms and members of one bot.
+	SyncBot(id uint) (*models.BotDetail, error)
+	// SyncAll refreshes every bot; failures are recorded on the bot and logged.
+	SyncAll()
+	GetRooms(botID uint, search, roomType string, paging *utils.Paging) ([]models.ChatworkBotRoom, int64, error)
+	GetRoomMembers(botID uint, roomID int64) ([]models.ChatworkBotRoomMember, error)
+	// RoomWarnings checks the cached directory for problems sending as the bot
+	// to roomID: the bot missing from the room, a read-only role, or accountIDs
+	// that aren't members. Unknown bots or bots never synced yield no warnings.
+	RoomWarnings(botID uint, roomID string, accountIDs []int64) []string
+	// RotateToken replaces the bot's token with one for the same Chatwork account.
+	RotateToken(id uint, apiToken string) (*models.BotDetail, error)
+	// SetWebhookToken stores the token of the bot's Chatwork outgoing webhook;
+	// an empty token turns the webhook receiver off for the bot.
+	SetWebhookToken(id uint, token string) (*models.BotDetail, error)
 }
 
 type ChatworkBotService struct {
 	repo         repositories.IChatworkBotRepository
+	roomRepo     repositories.IChatworkBotRoomRepository
 	scheduleRepo repositories.IReminderScheduleRepository
-	httpClient   *http.Client
+	requests     botRequestLedger
+	baseURL      string
+	now          func() time.Time
 }
 
-func NewChatworkBotService(repo repositories.IChatworkBotRepository, scheduleRepo repositories.IReminderScheduleRepository) *ChatworkBotService {
+func NewChatworkBotService(
+	repo repositories.IChatworkBotRepository,
+	roomRepo repositories.IChatworkBotRoomRepository,
+	scheduleRepo repositories.IReminderScheduleRepository,
+	requestRepo repositories.IBotRequestRepository,
+) *ChatworkBotService {
 	return &ChatworkBotService{
 		repo:         repo,
+		roomRepo:     roomRepo,
 		scheduleRepo: scheduleRepo,
-		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		requests:     botRequestLedger{repo: requestRepo},
+		baseURL:      chatworkBaseURL(),
+		now:          time.Now,
 	}
 }
 
-// GetAll returns all bots enriched with live Chatwork profile data.
+// GetAll returns all bots with their cached Chatwork profile. Bots that have
+// never been synced are synced on first sight.
 func (s *ChatworkBotService) GetAll(paging *utils.Paging) ([]models.BotDetail, int64, error) {
 	bots, total, err := s.repo.GetAll(paging)
 	if err != nil {
@@ -49,8 +87,12 @@ func (s *ChatworkBotService) GetAll(paging *utils.Paging) ([]models.BotDetail, i
 
 	details := make([]models.BotDetail, 0, len(bots))
 	for i := range bots {
-		detail := s.enrichBot(&bots[i])
-		details = append(details, detail)
+		if bots[i].SyncedAt == nil && bots[i].SyncError == "" {
+			if err := s.syncBot(&bots[i]); err != nil {
+				logger.Warnf("[BotSync] bot_id=%d initial sync failed: %v", bots[i].ID, err)
+			}
+		}
+		details = append(details, s.buildBotDetail(&bots[i]))
 	}
 	return details, total, nil
 }
@@ -68,18 +110,24 @@ func (s *ChatworkBotService) Create(apiToken string, email *string, description
 		return nil, fmt.Errorf("invalid or unauthorized Chatwork API token")
 	}
 
+	now := s.now()
 	bot := &models.ChatworkBot{
-		APIToken:    apiToken,
-		Email:       email,
-		Description: description,
+		APIToken:        apiToken,
+		Email:           email,
+		Description:     description,
+		HealthStatus:    models.BotHealthHealthy,
+		HealthCheckedAt: &now,
 	}
+	applyProfile(bot, profile)
 	created, err := s.repo.Create(bot)
 	if err != nil {
 		return nil, err
 	}
 
-	rooms := s.fetchRooms(created.APIToken)
-	detail := s.buildBotDetail(created, profile, len(rooms))
+	if err := s.syncBot(created); err != nil {
+		logger.Warnf("[BotSync] bot_id=%d sync after create failed: %v", created.ID, err)
+	}
+	detail := s.buildBotDetail(created)
 	return &detail, nil
 }
 
@@ -96,202 +144,411 @@ func (s *ChatworkBotService) Delete(id uint) error {
 	if inUse {
 		return fmt.Errorf("bot is assigned to one or more schedules and cannot be deleted")
 	}
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	return s.roomRepo.DeleteByBot(id)
 }
 
-// GetBotRequests fetches incoming requests from all bots via Chatwork API.
-// status filter: "" = all (pending only on CW side), or "pending" explicitly.
-func (s *ChatworkBotService) GetBotRequests(status string) ([]models.BotRequestItem, error) {
-	// Get all bots (no paging limit — use a large limit)
-	allPaging := &utils.Paging{Page: 1, Limit: 1000}
-	bots, _, err := s.repo.GetAll(allPaging)
+func (s *ChatworkBotService) GetBotRequests(filter repositories.BotRequestFilter, paging *utils.Paging) ([]models.BotRequestItem, int64, []models.BotRequestSyncError, error) {
+	bots, err := s.repo.ListAll()
 	if err != nil {
-		return nil, err
+		return nil, 0, nil, err
 	}
 
-	var items []models.BotRequestItem
+	details := make(map[uint]*models.BotDetail, len(bots))
+	var syncErrors []models.BotRequestSyncError
 	for i := range bots {
 		bot := &bots[i]
-		requests, err := s.fetchIncomingRequests(bot.APIToken)
-		if err != nil {
-			logger.Errorf("[BotRequests] bot_id=%d fetchIncomingRequests failed: %v", bot.ID, err)
-			return nil, fmt.Errorf("bot_id=%d: %w", bot.ID, err)
+		detail := s.buildBotDetail(bot)
+		details[bot.ID] = &detail
+		if filter.BotID != 0 && filter.BotID != bot.ID {
+			continue
 		}
-
-		profile := s.fetchMe(bot.APIToken)
-		botDetail := s.buildBotDetail(bot, profile, 0)
-
-		for _, req := range requests {
-			// Chatwork incoming_requests are always "pending" status
-			// (accepted/rejected ones disappear from the list)
-			reqStatus := "pending"
-			if status != "" && status != reqStatus {
-				continue
-			}
-			sender := &models.SenderInfo{
-				AccountID:        req.AccountID,
-				Name:             req.Name,
-				ChatworkID:       req.ChatworkID,
-				OrganizationName: req.OrganizationName,
-				Department:       req.Department,
-				AvatarImageURL:   req.AvatarImageURL,
-			}
-			items = append(items, models.BotRequestItem{
-				ID:         fmt.Sprintf("%d_%d", bot.ID, req.RequestID),
-				BotID:      bot.ID,
-				BotInfo:    &botDetail,
-				SenderInfo: sender,
-				Message:    req.Message,
-				Status:     reqStatus,
-				CreatedAt:  time.Now().UTC().Format(time.RFC3339), // CW API does not return createdAt
-			})
+		if err := s.syncBotRequests(bot); err != nil {
+			logger.Errorf("[BotRequests] bot_id=%d sync failed: %v", bot.ID, err)
+			syncErrors = append(syncErrors, models.BotRequestSyncError{BotID: bot.ID, BotName: botLabel(bot), Error: err.Error()})
 		}
 	}
-	return items, nil
+
+	requests, total, err := s.requests.repo.List(filter, paging)
+	if err != nil {
+		return nil, 0, syncErrors, err
+	}
+	items := make([]models.BotRequestItem, 0, len(requests))
+	for i := range requests {
+		items = append(items, buildBotRequestItem(&requests[i], details[requests[i].BotID]))
+	}
+	return items, total, syncErrors, nil
 }
 
 // AcceptBotRequest accepts a Chatwork incoming request.
 // compositeID format: "{dbBotID}_{cwRequestID}"
-func (s *ChatworkBotService) AcceptBotRequest(compositeID string) error {
-	botID, cwReqID, err := parseCompositeID(compositeID)
+func (s *ChatworkBotService) AcceptBotRequest(compositeID, actor string) error {
+	bot, cwReqID, err := s.resolveBotRequest(compositeID)
 	if err != nil {
 		return err
 	}
-	bot, err := s.repo.GetByID(botID)
-	if err != nil {
-		return fmt.Errorf("bot not found: %w", err)
+	ctx, cancel := context.WithTimeout(context.Background(), chatworkCallTimeout)
+	defer cancel()
+	if _, err := s.client(bot.APIToken).AcceptIncomingRequest(ctx, cwReqID); err != nil {
+		return fmt.Errorf("chatwork accept failed: %w", err)
 	}
+	s.recordDecision(bot.ID, cwReqID, models.BotRequestAccepted, actor)
+	return nil
+}
 
-	url := fmt.Sprintf("%s/incoming_requests/%d", chatworkAPIBase, cwReqID)
-	req, _ := http.NewRequest(http.MethodPut, url, nil)
-	req.Header.Set("X-ChatWorkToken", bot.APIToken)
-
-	resp, err := s.httpClient.Do(req)
+// DeleteBotRequest rejects/deletes a Chatwork incoming request.
+// compositeID format: "{dbBotID}_{cwRequestID}"
+func (s *ChatworkBotService) DeleteBotRequest(compositeID, actor string) error {
+	bot, cwReqID, err := s.resolveBotRequest(compositeID)
 	if err != nil {
-		return fmt.Errorf("chatwork API error: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("chatwork accept failed (status %d): %s", resp.StatusCode, string(body))
+	ctx, cancel := context.WithTimeout(context.Background(), chatworkCallTimeout)
+	defer cancel()
+	if err := s.client(bot.APIToken).RejectIncomingRequest(ctx, cwReqID); err != nil {
+		return fmt.Errorf("chatwork delete failed: %w", err)
 	}
+	s.recordDecision(bot.ID, cwReqID, models.BotRequestRejected, actor)
 	return nil
 }
 
-// DeleteBotRequest rejects/deletes a Chatwork incoming request.
-// compositeID format: "{dbBotID}_{cwRequestID}"
-func (s *ChatworkBotService) DeleteBotRequest(compositeID string) error {
-	botID, cwReqID, err := parseCompositeID(compositeID)
+// SyncBot refreshes one bot's directory on demand.
+func (s *ChatworkBotService) SyncBot(id uint) (*models.BotDetail, error) {
+	bot, err := s.repo.GetByID(id)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("bot not found")
+	}
+	if err := s.syncBot(bot); err != nil {
+		return nil, err
+	}
+	detail := s.buildBotDetail(bot)
+	return &detail, nil
+}
+
+func (s *ChatworkBotService) SyncAll() {
+	bots, err := s.repo.ListAll()
+	if err != nil {
+		logger.Errorf("[BotSync] Failed to load bots: %v", err)
+		return
+	}
+	synced := 0
+	for i := range bots {
+		if err := s.syncBot(&bots[i]); err != nil {
+			logger.Errorf("[BotSync] bot_id=%d sync failed: %v", bots[i].ID, err)
+			continue
+		}
+		synced++
+	}
+	logger.Infof("[BotSync] Synced %d/%d bots", synced, len(bots))
+}
+
+func (s *ChatworkBotService) GetRooms(botID uint, search, roomType string, paging *utils.Paging) ([]models.ChatworkBotRoom, int64, error) {
+	if _, err := s.repo.GetByID(botID); err != nil {
+		return nil, 0, errors.New(errors.ErrResourceNotFound, "bot not found")
+	}
+	return s.roomRepo.ListRooms(botID, search, roomType, paging)
+}
+
+func (s *ChatworkBotService) GetRoomMembers(botID uint, roomID int64) ([]models.ChatworkBotRoomMember, error) {
+	if _, err := s.roomRepo.GetRoom(botID, roomID); err != nil {
+		return nil, errors.New(errors.ErrResourceNotFound, "room not found in the bot's directory")
 	}
+	return s.roomRepo.ListMembers(botID, roomID)
+}
+
+func (s *ChatworkBotService) RoomWarnings(botID uint, roomID string, accountIDs []int64) []string {
 	bot, err := s.repo.GetByID(botID)
+	if err != nil || bot.SyncedAt == nil {
+		return nil
+	}
+	id, err := chatwork.ParseRoomID(roomID)
+	if err != nil {
+		return nil
+	}
+	name := bot.Name
+	if name == "" {
+		name = fmt.Sprintf("#%d", bot.ID)
+	}
+
+	room, err := s.roomRepo.GetRoom(botID, id)
+	if err != nil {
+		return []string{fmt.Sprintf("bot %s is not a member of room %d (as of last sync %s)", name, id, bot.SyncedAt.UTC().Format(time.RFC3339))}
+	}
+	var warnings []string
+	if room.Role == chatwork.RoleReadonly {
+		warnings = append(warnings, fmt.Sprintf("bot %s is read-only in room %q and cannot post", name, room.Name))
+	}
+	if len(accountIDs) == 0 {
+		return warnings
+	}
+	members, err := s.roomRepo.ListMembers(botID, id)
+	if err != nil {
+		return warnings
+	}
+	inRoom := make(map[int64]bool, len(members))
+	for _, m := range members {
+		inRoom[m.AccountID] = true
+	}
+	for _, accountID := range accountIDs {
+		if !inRoom[accountID] {
+			warnings = append(warnings, fmt.Sprintf("account %d is not a member of room %q", accountID, room.Name))
+		}
+	}
+	return warnings
+}
+
+func (s *ChatworkBotService) RotateToken(id uint, apiToken string) (*models.BotDetail, error) {
+	bot, err := s.repo.GetByID(id)
 	if err != nil {
-		return fmt.Errorf("bot not found: %w", err)
+		return nil, errors.New(errors.ErrResourceNotFound, "bot not found")
+	}
+	profile := s.fetchMe(apiToken)
+	if profile == nil {
+		return nil, errors.New(errors.ErrInvalidData, "invalid or unauthorized Chatwork API token")
+	}
+	// Room memberships belong to the account, so a different account would be a different bot.
+	if bot.AccountID != 0 && profile.AccountID != bot.AccountID {
+		return nil, errors.New(errors.ErrInvalidData, fmt.Sprintf(
+			"token belongs to Chatwork account %d, not %d; register it as a new bot instead", profile.AccountID, bot.AccountID))
+	}
+
+	now := s.now()
+	bot.APIToken = apiToken
+	applyProfile(bot, profile)
+	bot.HealthStatus = models.BotHealthHealthy
+	bot.HealthCheckedAt = &now
+	bot.HealthError = ""
+	if err := s.repo.RotateToken(bot); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
 	}
+	logger.Infof("[Bot] bot_id=%d token rotated", bot.ID)
 
-	url := fmt.Sprintf("%s/incoming_requests/%d", chatworkAPIBase, cwReqID)
-	req, _ := http.NewRequest(http.MethodDelete, url, nil)
-	req.Header.Set("X-ChatWorkToken", bot.APIToken)
+	if err := s.syncBot(bot); err != nil {
+		logger.Warnf("[BotSync] bot_id=%d sync after rotation failed: %v", bot.ID, err)
+	}
+	detail := s.buildBotDetail(bot)
+	return &detail, nil
+}
 
-	resp, err := s.httpClient.Do(req)
+// SetWebhookToken checks that the token is the base64 value Chatwork shows for
+// the webhook before storing it.
+func (s *ChatworkBotService) SetWebhookToken(id uint, token string) (*models.BotDetail, error) {
+	bot, err := s.repo.GetByID(id)
 	if err != nil {
-		return fmt.Errorf("chatwork API error: %w", err)
+		return nil, errors.New(errors.ErrResourceNotFound, "bot not found")
+	}
+	token = strings.TrimSpace(token)
+	if token != "" {
+		if _, err := base64.StdEncoding.DecodeString(token); err != nil {
+			return nil, errors.New(errors.ErrInvalidData, "token must be the base64 token shown in the Chatwork webhook settings")
+		}
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("chatwork delete failed (status %d): %s", resp.StatusCode, string(body))
+
+	bot.WebhookToken = token
+	if err := s.repo.UpdateWebhookToken(bot); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
 	}
-	return nil
+	detail := s.buildBotDetail(bot)
+	return &detail, nil
 }
 
 // ── private helpers ───────────────────────────────────────────────────────────
 
-func (s *ChatworkBotService) enrichBot(bot *models.ChatworkBot) models.BotDetail {
-	profile := s.fetchMe(bot.APIToken)
-	rooms := s.fetchRooms(bot.APIToken)
-	return s.buildBotDetail(bot, profile, len(rooms))
+// syncBot pulls the bot's profile, rooms and room members from Chatwork and
+// replaces the cached copy. Sync calls use the low-priority lane so they never
+// hold up reminders or alerts sent with the same token.
+func (s *ChatworkBotService) syncBot(bot *models.ChatworkBot) error {
+	ctx, cancel := context.WithTimeout(chatwork.WithPriority(context.Background(), chatwork.PriorityLow), chatworkQueueTimeout)
+	defer cancel()
+
+	err := s.pullDirectory(ctx, bot)
+	if err != nil {
+		bot.SyncError = err.Error()
+	} else {
+		now := s.now()
+		bot.SyncedAt = &now
+		bot.SyncError = ""
+	}
+	if updateErr := s.repo.UpdateProfile(bot); updateErr != nil {
+		return fmt.Errorf("failed to store sync status: %w", updateErr)
+	}
+	return err
+}
+
+func (s *ChatworkBotService) pullDirectory(ctx context.Context, bot *models.ChatworkBot) error {
+	client := s.client(bot.APIToken)
+	me, err := client.Me(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch profile: %w", err)
+	}
+	rooms, err := client.Rooms(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch rooms: %w", err)
+	}
+
+	now := s.now()
+	cachedRooms := make([]models.ChatworkBotRoom, 0, len(rooms))
+	var cachedMembers []models.ChatworkBotRoomMember
+	for _, room := range rooms {
+		cached := models.ChatworkBotRoom{
+			BotID:    bot.ID,
+			RoomID:   room.RoomID,
+			Name:     room.Name,
+			Type:     room.Type,
+			Role:     room.Role,
+			IconPath: room.IconPath,
+			SyncedAt: now,
+		}
+		if room.Type != chatwork.RoomTypeMy {
+			members, err := client.Members(ctx, room.RoomID)
+			if err != nil {
+				// Keep the room; its members are refreshed on the next sync.
+				logger.Warnf("[BotSync] bot_id=%d room %d members failed: %v", bot.ID, room.RoomID, err)
+			}
+			for _, m := range members {
+				cachedMembers = append(cachedMembers, models.ChatworkBotRoomMember{
+					BotID:            bot.ID,
+					RoomID:           room.RoomID,
+					AccountID:        m.AccountID,
+					Name:             m.Name,
+					Role:             m.Role,
+					ChatworkID:       m.ChatworkID,
+					OrganizationName: m.OrganizationName,
+					Department:       m.Department,
+					AvatarImageURL:   m.AvatarImageURL,
+				})
+			}
+			cached.MemberCount = len(members)
+		}
+		cachedRooms = append(cachedRooms, cached)
+	}
+
+	if err := s.roomRepo.ReplaceRooms(bot.ID, cachedRooms, cachedMembers); err != nil {
+		return fmt.Errorf("store rooms: %w", err)
+	}
+	applyProfile(bot, me)
+	bot.RoomsCount = len(rooms)
+	return nil
+}
+
+func applyProfile(bot *models.ChatworkBot, profile *chatwork.Me) {
+	bot.AccountID = profile.AccountID
+	bot.ChatworkID = profile.ChatworkID
+	bot.Name = profile.Name
+	bot.AvatarURL = profile.AvatarImageURL
 }
 
-func (s *ChatworkBotService) buildBotDetail(bot *models.ChatworkBot, profile *models.ChatworkMeResponse, roomsCount int) models.BotDetail {
-	detail := models.BotDetail{
+func (s *ChatworkBotService) buildBotDetail(bot *models.ChatworkBot) models.BotDetail {
+	return models.BotDetail{
 		ID:          bot.ID,
+		AccountID:   bot.AccountID,
+		ChatworkID:  bot.ChatworkID,
+		Name:        bot.Name,
 		Email:       bot.Email,
+		AvatarURL:   bot.AvatarURL,
 		Description: bot.Description,
-		RoomsCount:  roomsCount,
+		RoomsCount:  bot.RoomsCount,
+		SyncedAt:    bot.SyncedAt,
+		SyncError:   bot.SyncError,
+
+		HealthStatus:    healthStatus(bot),
+		HealthCheckedAt: bot.HealthCheckedAt,
+		HealthError:     bot.HealthError,
+
+		WebhookConfigured: bot.WebhookToken != "",
 	}
-	if profile != nil {
-		detail.AccountID = profile.AccountID
-		detail.ChatworkID = profile.ChatworkID
-		detail.Name = profile.Name
-		detail.AvatarURL = profile.AvatarImageURL
+}
+
+func healthStatus(bot *models.ChatworkBot) string {
+	if bot.HealthStatus == "" {
+		return models.BotHealthUnknown
 	}
-	return detail
+	return bot.HealthStatus
 }
 
-func (s *ChatworkBotService) fetchMe(apiToken string) *models.ChatworkMeResponse {
-	req, _ := http.NewRequest(http.MethodGet, chatworkAPIBase+"/me", nil)
-	req.Header.Set("X-ChatWorkToken", apiToken)
+func (s *ChatworkBotService) client(apiToken string) *chatwork.Client {
+	return chatwork.NewClient(apiToken, chatwork.WithBaseURL(s.baseURL))
+}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		return nil
+// resolveBotRequest loads the bot addressed by a composite request ID.
+func (s *ChatworkBotService) resolveBotRequest(compositeID string) (*models.ChatworkBot, int64, error) {
+	botID, cwReqID, err := parseCompositeID(compositeID)
+	if err != nil {
+		return nil, 0, err
 	}
-	defer resp.Body.Close()
-
-	var me models.ChatworkMeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&me); err != nil {
-		return nil
+	bot, err := s.repo.GetByID(botID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bot not found: %w", err)
 	}
-	return &me
+	return bot, cwReqID, nil
 }
 
-func (s *ChatworkBotService) fetchRooms(apiToken string) []models.ChatworkRoom {
-	req, _ := http.NewRequest(http.MethodGet, chatworkAPIBase+"/rooms", nil)
-	req.Header.Set("X-ChatWorkToken", apiToken)
+// fetchMe returns the bot's profile, or nil when the token is rejected or Chatwork is unreachable.
+func (s *ChatworkBotService) fetchMe(apiToken string) *chatwork.Me {
+	ctx, cancel := context.WithTimeout(context.Background(), chatworkCallTimeout)
+	defer cancel()
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil || resp.StatusCode != http.StatusOK {
+	me, err := s.client(apiToken).Me(ctx)
+	if err != nil {
 		return nil
 	}
-	defer resp.Body.Close()
-
-	var rooms []models.ChatworkRoom
-	json.NewDecoder(resp.Body).Decode(&rooms) //nolint:errcheck
-	return rooms
+	return me
 }
 
-func (s *ChatworkBotService) fetchIncomingRequests(apiToken string) ([]models.ChatworkIncomingRequest, error) {
-	req, _ := http.NewRequest(http.MethodGet, chatworkAPIBase+"/incoming_requests", nil)
-	req.Header.Set("X-ChatWorkToken", apiToken)
-
-	resp, err := s.httpClient.Do(req)
+// syncBotRequests copies the bot's pending requests into the local history.
+func (s *ChatworkBotService) syncBotRequests(bot *models.ChatworkBot) error {
+	live, err := s.fetchIncomingRequests(bot.APIToken)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer resp.Body.Close()
+	return s.requests.sync(bot.ID, live, s.now())
+}
 
-	// Chatwork returns 204 No Content when there are no pending requests
-	if resp.StatusCode == http.StatusNoContent {
-		return []models.ChatworkIncomingRequest{}, nil
-	}
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("chatwork API %d: %s", resp.StatusCode, string(body))
+// recordDecision stores a decision Chatwork has already applied, so a failure
+// here is logged rather than reported to the caller.
+func (s *ChatworkBotService) recordDecision(botID uint, requestID int64, status, actor string) {
+	if err := s.requests.decide(botID, requestID, status, actor, s.now()); err != nil {
+		logger.Errorf("[BotRequests] bot_id=%d request_id=%d failed to record %s: %v", botID, requestID, status, err)
 	}
+}
 
-	var reqs []models.ChatworkIncomingRequest
-	if err := json.NewDecoder(resp.Body).Decode(&reqs); err != nil {
-		return nil, err
+func (s *ChatworkBotService) fetchIncomingRequests(apiToken string) ([]chatwork.IncomingRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), chatworkCallTimeout)
+	defer cancel()
+
+	// The client maps Chatwork's 204 No Content (no pending requests) to an empty list.
+	return s.client(apiToken).IncomingRequests(ctx)
+}
+
+func buildBotRequestItem(req *models.BotRequest, bot *models.BotDetail) models.BotRequestItem {
+	return models.BotRequestItem{
+		ID:      fmt.Sprintf("%d_%d", req.BotID, req.RequestID),
+		BotID:   req.BotID,
+		BotInfo: bot,
+		SenderInfo: &models.SenderInfo{
+			AccountID:        req.AccountID,
+			Name:             req.Name,
+			ChatworkID:       req.ChatworkID,
+			OrganizationName: req.OrganizationName,
+			Department:       req.Department,
+			AvatarImageURL:   req.AvatarImageURL,
+		},
+		Message:     req.Message,
+		Status:      req.Status,
+		FirstSeenAt: req.FirstSeenAt,
+		LastSeenAt:  req.LastSeenAt,
+		DecidedAt:   req.DecidedAt,
+		DecidedBy:   req.DecidedBy,
+		CreatedAt:   req.FirstSeenAt.UTC().Format(time.RFC3339),
 	}
-	return reqs, nil
 }
 
 // parseCompositeID splits "{botID}_{cwRequestID}" into typed values.
-func parseCompositeID(id string) (uint, int, error) {
+func parseCompositeID(id string) (uint, int64, error) {
 	var botID uint
-	var cwReqID int
+	var cwReqID int64
 	_, err := fmt.Sscanf(strings.Replace(id, "_", " ", 1), "%d %d", &botID, &cwReqID)
 	if err != nil {
 		return 0, 0, fmt.Errorf("invalid request ID format (expected {botID}_{cwRequestID}): %w", err)
@@ -0,0 +1,660 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+)
+
+// IChatworkCommandService answers commands sent to a bot through Chatwork's
+// outgoing webhooks and manages which rooms may run them per project.
+type IChatworkCommandService interface {
+	// HandleWebhook verifies and accepts one webhook request for the bot.
+	// Commands run in the background and reply in the room they came from.
+	HandleWebhook(botID uint, body []byte, signature string) error
+
+	GetGrants(projectID uint) ([]models.ChatworkCommandGrant, error)
+	CreateGrant(projectID uint, input *ChatworkCommandGrantInput) (*models.ChatworkCommandGrant, error)
+	UpdateGrant(id, projectID uint, input *ChatworkCommandGrantInput) (*models.ChatworkCommandGrant, error)
+	DeleteGrant(id, projectID uint) error
+}
+
+// ChatworkCommandGrantInput is the create/update payload of a grant. On update
+// nil fields are left unchanged; an empty AccountIDs lets anyone in the room.
+type ChatworkCommandGrantInput struct {
+	RoomID     *string
+	Commands   []string
+	AccountIDs []int64
+}
+
+type ChatworkCommandService struct {
+	botRepo    repositories.IChatworkBotRepository
+	grantRepo  repositories.IChatworkCommandGrantRepository
+	schedules  IReminderScheduleService
+	cron       ICronService
+	cveConfigs ICveConfigService
+	cveSearch  ICveSearchService
+	alerts     IAlertService
+	escalation IEscalationService
+	baseURL    string
+	dispatch   func(func())
+}
+
+func NewChatworkCommandService(
+	botRepo repositories.IChatworkBotRepository,
+	grantRepo repositories.IChatworkCommandGrantRepository,
+	schedules IReminderScheduleService,
+	cron ICronService,
+	cveConfigs ICveConfigService,
+	cveSearch ICveSearchService,
+	alerts IAlertService,
+	escalation IEscalationService,
+) *ChatworkCommandService {
+	return &ChatworkCommandService{
+		botRepo:    botRepo,
+		grantRepo:  grantRepo,
+		schedules:  schedules,
+		cron:       cron,
+		cveConfigs: cveConfigs,
+		cveSearch:  cveSearch,
+		alerts:     alerts,
+		escalation: escalation,
+		baseURL:    chatworkBaseURL(),
+		dispatch:   func(f func()) { go f() },
+	}
+}
+
+// chatworkCommand is a parsed "/name args" line addressed to the bot.
+type chatworkCommand struct {
+	name string
+	args []string
+
+	bot    *models.ChatworkBot
+	msg    chatwork.WebhookMessage
+	sender int64
+}
+
+func (s *ChatworkCommandService) HandleWebhook(botID uint, body []byte, signature string) error {
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		return errors.New(errors.ErrResourceNotFound, "bot not found")
+	}
+	if bot.WebhookToken == "" || !chatwork.VerifyWebhook(bot.WebhookToken, body, signature) {
+		return errors.New(errors.ErrAuthUnauthorized, "invalid webhook signature")
+	}
+	event, err := chatwork.ParseWebhook(body)
+	if err != nil {
+		return errors.New(errors.ErrInvalidData, err.Error())
+	}
+
+	cmd, ok := parseChatworkCommand(bot, event)
+	if !ok {
+		return nil
+	}
+	logger.Infof("[ChatworkCommand] bot_id=%d room=%d account=%d command=/%s %s",
+		bot.ID, cmd.msg.RoomID, cmd.sender, cmd.name, strings.Join(cmd.args, " "))
+	s.dispatch(func() { s.run(cmd) })
+	return nil
+}
+
+// parseChatworkCommand picks the command out of a message that mentions the
+// bot. Edits and the bot's own messages are ignored so a reply never loops.
+func parseChatworkCommand(bot *models.ChatworkBot, event *chatwork.WebhookEvent) (*chatworkCommand, bool) {
+	msg := event.Event
+	sender := msg.Sender()
+	switch event.Type {
+	case chatwork.WebhookMentionToMe:
+	case chatwork.WebhookMessageCreated:
+		if !chatwork.Mentions(msg.Body, bot.AccountID) {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	if sender == 0 || sender == bot.AccountID {
+		return nil, false
+	}
+
+	for _, line := range strings.Split(chatwork.StripAddressing(msg.Body), "\n") {
+		fields := strings.Fields(line)
+		// Skip the display name Chatwork puts after a mention.
+		for len(fields) > 0 && !strings.HasPrefix(fields[0], "/") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || len(fields[0]) < 2 {
+			continue
+		}
+		return &chatworkCommand{
+			name:   strings.ToLower(strings.TrimPrefix(fields[0], "/")),
+			args:   fields[1:],
+			bot:    bot,
+			msg:    msg,
+			sender: sender,
+		}, true
+	}
+	return nil, false
+}
+
+// run checks the command against the room's grants and replies with its result.
+func (s *ChatworkCommandService) run(cmd *chatworkCommand) {
+	roomID := strconv.FormatInt(cmd.msg.RoomID, 10)
+	grants, err := s.grantRepo.GetByRoomID(roomID)
+	if err != nil {
+		logger.Errorf("[ChatworkCommand] room=%s failed to load grants: %v", roomID, err)
+		s.reply(cmd, "Something went wrong loading this room's permissions. Please try again later.")
+		return
+	}
+	if len(grants) == 0 {
+		s.reply(cmd, "This room is not linked to any project, so I can't run commands here.")
+		return
+	}
+
+	if cmd.name == "help" {
+		s.reply(cmd, helpText(grants, cmd.sender))
+		return
+	}
+	if !isChatworkCommand(cmd.name) {
+		s.reply(cmd, fmt.Sprintf("Unknown command /%s. Send /help to see what I can do.", cmd.name))
+		return
+	}
+
+	projects := allowedProjects(grants, cmd.name, cmd.sender)
+	if len(projects) == 0 {
+		s.reply(cmd, fmt.Sprintf("You are not allowed to run /%s in this room.", cmd.name))
+		return
+	}
+
+	switch cmd.name {
+	case models.CommandSchedules:
+		s.listSchedules(cmd, projects)
+	case models.CommandPause:
+		s.toggleSchedule(cmd, projects, false)
+	case models.CommandResume:
+		s.toggleSchedule(cmd, projects, true)
+	case models.CommandScan:
+		s.scan(cmd, projects)
+	case models.CommandCve:
+		s.lookupCve(cmd)
+	case models.CommandAlerts:
+		s.listAlerts(cmd, projects)
+	case models.CommandAck:
+		s.ackAlerts(cmd, projects)
+	case models.CommandOnCall:
+		s.listOnCall(cmd, projects)
+	}
+}
+
+func (s *ChatworkCommandService) listSchedules(cmd *chatworkCommand, projects []uint) {
+	b := replyTo(cmd)
+	for _, pid := range projects {
+		schedules, err := s.schedules.GetByProjectID(pid)
+		if err != nil {
+			logger.Errorf("[ChatworkCommand] project_id=%d failed to list schedules: %v", pid, err)
+			s.reply(cmd, "Something went wrong loading the schedules. Please try again later.")
+			return
+		}
+		title := fmt.Sprintf("Schedules (project #%d)", pid)
+		if len(schedules) > 0 && schedules[0].Project.Name != "" {
+			title = fmt.Sprintf("Schedules (%s)", schedules[0].Project.Name)
+		}
+		b.Info(title, func(inner *chatwork.Builder) {
+			if len(schedules) == 0 {
+				inner.Text("No schedules.")
+			}
+			for _, sc := range schedules {
+				status := "active"
+				if !sc.Active {
+					status = "paused"
+				}
+				inner.Textf("#%d %s — %s [%s]", sc.ID, sc.Name, sc.CronExpression, status)
+			}
+		})
+	}
+	s.send(cmd, b)
+}
+
+func (s *ChatworkCommandService) toggleSchedule(cmd *chatworkCommand, projects []uint, active bool) {
+	if len(cmd.args) == 0 {
+		s.reply(cmd, fmt.Sprintf("Usage: /%s <schedule id>", cmd.name))
+		return
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(cmd.args[0], "#"), 10, 64)
+	if err != nil {
+		s.reply(cmd, fmt.Sprintf("%q is not a schedule id.", cmd.args[0]))
+		return
+	}
+	schedule, err := s.schedules.GetByID(uint(id))
+	if err != nil || schedule == nil || !containsProject(projects, schedule.ProjectID) {
+		s.reply(cmd, fmt.Sprintf("Schedule #%d was not found in this room's projects.", id))
+		return
+	}
+	if schedule.Active == active {
+		s.reply(cmd, fmt.Sprintf("Schedule #%d %s is already %s.", schedule.ID, schedule.Name, scheduleState(active)))
+		return
+	}
+
+	if err := s.schedules.ToggleActiveStatus(schedule.ID, active); err != nil {
+		logger.Errorf("[ChatworkCommand] schedule_id=%d failed to toggle: %v", schedule.ID, err)
+		s.reply(cmd, fmt.Sprintf("Failed to update schedule #%d.", schedule.ID))
+		return
+	}
+	schedule.Active = active
+	if active {
+		s.cron.Register(schedule)
+	} else {
+		s.cron.Remove(schedule.ID)
+	}
+	s.reply(cmd, fmt.Sprintf("Schedule #%d %s is now %s.", schedule.ID, schedule.Name, scheduleState(active)))
+}
+
+func scheduleState(active bool) string {
+	if active {
+		return "active"
+	}
+	return "paused"
+}
+
+func (s *ChatworkCommandService) scan(cmd *chatworkCommand, projects []uint) {
+	if len(cmd.args) == 0 {
+		s.reply(cmd, "Usage: /scan <config id or name>")
+		return
+	}
+	ref := strings.Join(cmd.args, " ")
+	config := s.findCveConfig(projects, ref)
+	if config == nil {
+		s.reply(cmd, fmt.Sprintf("CVE config %q was not found in this room's projects.", ref))
+		return
+	}
+
+	s.reply(cmd, fmt.Sprintf("Scanning %s…", config.Name))
+	if err := s.cveConfigs.TriggerScan(config.ID, uint(config.ProjectID)); err != nil {
+		s.reply(cmd, fmt.Sprintf("Scan of %s failed: %v", config.Name, err))
+		return
+	}
+	if updated, err := s.cveConfigs.GetByID(config.ID, uint(config.ProjectID)); err == nil {
+		config = updated
+	}
+	s.reply(cmd, fmt.Sprintf("Scan of %s finished: %d vulnerabilities found.", config.Name, config.VulnerabilitiesFound))
+}
+
+// findCveConfig matches ref against config IDs first, then names.
+func (s *ChatworkCommandService) findCveConfig(projects []uint, ref string) *models.CveConfig {
+	for _, pid := range projects {
+		if config, err := s.cveConfigs.GetByID(ref, pid); err == nil && config != nil {
+			return config
+		}
+	}
+	for _, pid := range projects {
+		configs, _, err := s.cveConfigs.GetByProjectID(pid, nil)
+		if err != nil {
+			logger.Errorf("[ChatworkCommand] project_id=%d failed to list CVE configs: %v", pid, err)
+			continue
+		}
+		for i := range configs {
+			if strings.EqualFold(configs[i].Name, ref) {
+				return &configs[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ChatworkCommandService) lookupCve(cmd *chatworkCommand) {
+	if len(cmd.args) == 0 {
+		s.reply(cmd, "Usage: /cve <CVE id>")
+		return
+	}
+	cveID := strings.ToUpper(cmd.args[0])
+	record, err := s.cveSearch.GetByCveID(cveID)
+	if err != nil || record == nil {
+		s.reply(cmd, fmt.Sprintf("%s is not in the local CVE database.", cveID))
+		return
+	}
+
+	b := replyTo(cmd).Info(record.CveID, func(inner *chatwork.Builder) {
+		severity := record.Severity
+		if severity == "" {
+			severity = "unknown"
+		}
+		inner.Textf("Severity: %s (%.1f)", severity, record.BaseScore)
+		if !record.PublishedAt.IsZero() {
+			inner.Textf("Published: %s", record.PublishedAt.Format("2006-01-02"))
+		}
+		inner.Text(record.Description)
+		inner.Text("https://nvd.nist.gov/vuln/detail/" + record.CveID)
+	})
+	s.send(cmd, b)
+}
+
+func (s *ChatworkCommandService) listAlerts(cmd *chatworkCommand, projects []uint) {
+	b := replyTo(cmd)
+	for _, pid := range projects {
+		groups, _, err := s.alerts.GetGroups(pid, models.AlertFiring, &utils.Paging{Page: 1, Limit: 20})
+		if err != nil {
+			logger.Errorf("[ChatworkCommand] project_id=%d failed to list alerts: %v", pid, err)
+			s.reply(cmd, "Something went wrong loading the alerts. Please try again later.")
+			return
+		}
+		b.Info(fmt.Sprintf("Firing alerts (project #%d)", pid), func(inner *chatwork.Builder) {
+			if len(groups) == 0 {
+				inner.Text("Nothing is firing.")
+			}
+			for i := range groups {
+				line := fmt.Sprintf("#%d %s — %d alert(s)", groups[i].ID, alertGroupTitle(&groups[i]), countFiring(groups[i].Alerts))
+				if groups[i].AckedAt != nil {
+					line += " [acked by " + groups[i].AckedBy + "]"
+				}
+				inner.Text(line)
+			}
+		})
+	}
+	s.send(cmd, b)
+}
+
+// ackAlerts acknowledges the groups given by ID, or the escalation or alert
+// group whose message the command replies to.
+func (s *ChatworkCommandService) ackAlerts(cmd *chatworkCommand, projects []uint) {
+	var groups []*models.AlertGroup
+	if len(cmd.args) == 0 {
+		roomID, messageID, ok := chatwork.ReplyTarget(cmd.msg.Body)
+		if !ok {
+			s.reply(cmd, "Usage: /ack <alert group id>, or reply /ack to the alert or escalation message")
+			return
+		}
+		if s.ackEscalation(cmd, projects, strconv.FormatInt(roomID, 10), messageID) {
+			return
+		}
+		group, err := s.alerts.GetGroupByMessage(strconv.FormatInt(roomID, 10), messageID)
+		if err != nil || group == nil || !containsProject(projects, group.ProjectID) {
+			s.reply(cmd, "That message is not an alert from this room's projects.")
+			return
+		}
+		groups = append(groups, group)
+	}
+	for _, arg := range cmd.args {
+		id, err := strconv.ParseUint(strings.TrimPrefix(arg, "#"), 10, 64)
+		if err != nil {
+			s.reply(cmd, fmt.Sprintf("%q is not an alert group id.", arg))
+			return
+		}
+		group := s.findAlertGroup(projects, uint(id))
+		if group == nil {
+			s.reply(cmd, fmt.Sprintf("Alert group #%d was not found in this room's projects.", id))
+			return
+		}
+		groups = append(groups, group)
+	}
+
+	by := fmt.Sprintf("Chatwork account %d", cmd.sender)
+	var lines []string
+	for _, group := range groups {
+		if group.AckedAt != nil {
+			lines = append(lines, fmt.Sprintf("Alert group #%d was already acknowledged by %s.", group.ID, group.AckedBy))
+			continue
+		}
+		if _, err := s.alerts.Acknowledge(group.ID, group.ProjectID, by); err != nil {
+			logger.Errorf("[ChatworkCommand] alert_group_id=%d failed to acknowledge: %v", group.ID, err)
+			lines = append(lines, fmt.Sprintf("Failed to acknowledge alert group #%d.", group.ID))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("Acknowledged alert group #%d %s.", group.ID, alertGroupTitle(group)))
+	}
+	s.reply(cmd, strings.Join(lines, "\n"))
+}
+
+// ackEscalation acknowledges the escalation that posted messageID. It
+// reports false when the message isn't one of this room's escalations.
+func (s *ChatworkCommandService) ackEscalation(cmd *chatworkCommand, projects []uint, roomID, messageID string) bool {
+	if s.escalation == nil {
+		return false
+	}
+	escalation, err := s.escalation.GetEscalationByMessage(roomID, messageID)
+	if err != nil || escalation == nil || !containsProject(projects, escalation.ProjectID) {
+		return false
+	}
+	if escalation.AckedAt != nil {
+		s.reply(cmd, fmt.Sprintf("Escalation #%d was already acknowledged by %s.", escalation.ID, escalation.AckedBy))
+		return true
+	}
+
+	by := fmt.Sprintf("Chatwork account %d", cmd.sender)
+	if _, err := s.escalation.Acknowledge(escalation.ID, escalation.ProjectID, by); err != nil {
+		logger.Errorf("[ChatworkCommand] escalation_id=%d failed to acknowledge: %v", escalation.ID, err)
+		s.reply(cmd, fmt.Sprintf("Failed to acknowledge escalation #%d.", escalation.ID))
+		return true
+	}
+	s.reply(cmd, fmt.Sprintf("Acknowledged escalation #%d %s.", escalation.ID, escalation.Title))
+	return true
+}
+
+// listOnCall shows who is on call now in each rotation of the projects.
+func (s *ChatworkCommandService) listOnCall(cmd *chatworkCommand, projects []uint) {
+	b := replyTo(cmd)
+	now := time.Now()
+	for _, pid := range projects {
+		rotations, err := s.escalation.GetRotations(pid)
+		if err != nil {
+			logger.Errorf("[ChatworkCommand] project_id=%d failed to list rotations: %v", pid, err)
+			s.reply(cmd, "Something went wrong loading the on-call rotations. Please try again later.")
+			return
+		}
+		b.Info(fmt.Sprintf("On call (project #%d)", pid), func(inner *chatwork.Builder) {
+			if len(rotations) == 0 {
+				inner.Text("No rotations yet.")
+			}
+			for i := range rotations {
+				shift, err := s.escalation.OnCall(rotations[i].ID, pid, now)
+				if err != nil || shift == nil {
+					inner.Textf("%s — nobody", rotations[i].Name)
+					continue
+				}
+				name := shift.Name
+				if name == "" {
+					name = fmt.Sprintf("account %d", shift.AccountID)
+				}
+				line := fmt.Sprintf("%s — %s until %s", rotations[i].Name, name, shift.EndsAt.UTC().Format("2006-01-02 15:04 UTC"))
+				if shift.OverrideID != nil {
+					line += " (override)"
+				}
+				inner.Text(line)
+			}
+		})
+	}
+	s.send(cmd, b)
+}
+
+func (s *ChatworkCommandService) findAlertGroup(projects []uint, id uint) *models.AlertGroup {
+	for _, pid := range projects {
+		if group, err := s.alerts.GetGroup(id, pid); err == nil {
+			return group
+		}
+	}
+	return nil
+}
+
+func countFiring(alerts []models.Alert) int {
+	n := 0
+	for _, alert := range alerts {
+		if alert.Status == models.AlertFiring {
+			n++
+		}
+	}
+	return n
+}
+
+// reply answers the command's message with a line of text.
+func (s *ChatworkCommandService) reply(cmd *chatworkCommand, text string) {
+	s.send(cmd, replyTo(cmd).Text(text))
+}
+
+// replyTo starts a message that replies to the command's message.
+func replyTo(cmd *chatworkCommand) *chatwork.Builder {
+	return chatwork.NewBuilder().Reply(cmd.sender, cmd.msg.RoomID, cmd.msg.MessageID)
+}
+
+func (s *ChatworkCommandService) send(cmd *chatworkCommand, b *chatwork.Builder) {
+	ctx, cancel := context.WithTimeout(chatwork.WithPriority(context.Background(), chatwork.PriorityHigh), chatworkCallTimeout)
+	defer cancel()
+	client := chatwork.NewClient(cmd.bot.APIToken, chatwork.WithBaseURL(s.baseURL))
+	for _, part := range b.Split(chatwork.MaxMessageLength) {
+		if _, err := client.PostMessage(ctx, cmd.msg.RoomID, part); err != nil {
+			logger.Errorf("[ChatworkCommand] bot_id=%d room=%d failed to reply: %v", cmd.bot.ID, cmd.msg.RoomID, err)
+			return
+		}
+	}
+}
+
+func helpText(grants []models.ChatworkCommandGrant, sender int64) string {
+	usage := map[string]string{
+		models.CommandSchedules: "/schedules — list the project's schedules",
+		models.CommandPause:     "/pause <id> — pause a schedule",
+		models.CommandResume:    "/resume <id> — resume a schedule",
+		models.CommandScan:      "/scan <config> — run a CVE scan now",
+		models.CommandCve:       "/cve <id> — look up a CVE",
+		models.CommandAlerts:    "/alerts — list firing alert groups",
+		models.CommandAck:       "/ack <id> — acknowledge an alert group, or reply /ack to an alert or escalation message",
+		models.CommandOnCall:    "/oncall — show who is on call",
+	}
+	lines := []string{"Commands you can run here:"}
+	for _, name := range models.ChatworkCommands {
+		if len(allowedProjects(grants, name, sender)) > 0 {
+			lines = append(lines, usage[name])
+		}
+	}
+	if len(lines) == 1 {
+		return "You are not allowed to run any commands in this room."
+	}
+	return strings.Join(lines, "\n")
+}
+
+// allowedProjects returns the projects whose grants let sender run command.
+func allowedProjects(grants []models.ChatworkCommandGrant, command string, sender int64) []uint {
+	var projects []uint
+	for i := range grants {
+		if grants[i].Allows(command, sender) && !containsProject(projects, grants[i].ProjectID) {
+			projects = append(projects, grants[i].ProjectID)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i] < projects[j] })
+	return projects
+}
+
+func containsProject(projects []uint, id uint) bool {
+	for _, p := range projects {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+func isChatworkCommand(name string) bool {
+	for _, c := range models.ChatworkCommands {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ChatworkCommandService) GetGrants(projectID uint) ([]models.ChatworkCommandGrant, error) {
+	grants, err := s.grantRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return grants, nil
+}
+
+func (s *ChatworkCommandService) CreateGrant(projectID uint, input *ChatworkCommandGrantInput) (*models.ChatworkCommandGrant, error) {
+	if input.RoomID == nil {
+		return nil, errors.New(errors.ErrInvalidData, "roomId is required")
+	}
+	grant := &models.ChatworkCommandGrant{ProjectID: projectID}
+	if err := s.applyGrantInput(grant, input); err != nil {
+		return nil, err
+	}
+	if len(grant.Commands) == 0 {
+		return nil, errors.New(errors.ErrInvalidData, "commands is required")
+	}
+
+	created, err := s.grantRepo.Create(grant)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return created, nil
+}
+
+func (s *ChatworkCommandService) UpdateGrant(id, projectID uint, input *ChatworkCommandGrantInput) (*models.ChatworkCommandGrant, error) {
+	grant, err := s.getGrant(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyGrantInput(grant, input); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.grantRepo.Update(grant)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return updated, nil
+}
+
+func (s *ChatworkCommandService) DeleteGrant(id, projectID uint) error {
+	if _, err := s.getGrant(id, projectID); err != nil {
+		return err
+	}
+	if err := s.grantRepo.Delete(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *ChatworkCommandService) getGrant(id, projectID uint) (*models.ChatworkCommandGrant, error) {
+	grant, err := s.grantRepo.GetByID(id)
+	if err != nil || grant.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "command grant not found")
+	}
+	return grant, nil
+}
+
+func (s *ChatworkCommandService) applyGrantInput(grant *models.ChatworkCommandGrant, input *ChatworkCommandGrantInput) error {
+	if input.RoomID != nil {
+		roomID := strings.TrimSpace(*input.RoomID)
+		if _, err := chatwork.ParseRoomID(roomID); err != nil {
+			return errors.New(errors.ErrInvalidData, "roomId must be a Chatwork room ID")
+		}
+		grant.RoomID = roomID
+	}
+	if input.Commands != nil {
+		if len(input.Commands) == 0 {
+			return errors.New(errors.ErrInvalidData, "commands must not be empty")
+		}
+		var commands []string
+		for _, c := range input.Commands {
+			c = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(c), "/"))
+			if !isChatworkCommand(c) {
+				return errors.New(errors.ErrInvalidData, fmt.Sprintf("unknown command %q, expected one of %s", c, strings.Join(models.ChatworkCommands, ", ")))
+			}
+			if !slices.Contains(commands, c) {
+				commands = append(commands, c)
+			}
+		}
+		grant.Commands = commands
+	}
+	if input.AccountIDs != nil {
+		grant.AccountIDs = input.AccountIDs
+	}
+	return nil
+}
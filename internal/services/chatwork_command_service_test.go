@@ -0,0 +1,344 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+)
+
+// webhookToken is a base64 token like the ones Chatwork shows in its webhook settings.
+const webhookToken = "c2VjcmV0LXdlYmhvb2stdG9rZW4tZm9yLXRlc3Rz"
+
+type fakeCommandGrantRepo struct {
+	repositories.IChatworkCommandGrantRepository
+	grants []models.ChatworkCommandGrant
+}
+
+func (r *fakeCommandGrantRepo) GetByRoomID(roomID string) ([]models.ChatworkCommandGrant, error) {
+	var grants []models.ChatworkCommandGrant
+	for _, g := range r.grants {
+		if g.RoomID == roomID {
+			grants = append(grants, g)
+		}
+	}
+	return grants, nil
+}
+
+type fakeCommandSchedules struct {
+	IReminderScheduleService
+	schedules map[uint]*models.ReminderSchedule
+}
+
+func (s *fakeCommandSchedules) GetByID(id uint) (*models.ReminderSchedule, error) {
+	sc, ok := s.schedules[id]
+	if !ok {
+		return nil, errors.New(errors.ErrResourceNotFound, "not found")
+	}
+	copied := *sc
+	return &copied, nil
+}
+
+func (s *fakeCommandSchedules) GetByProjectID(projectID uint) ([]models.ReminderSchedule, error) {
+	var out []models.ReminderSchedule
+	for _, sc := range s.schedules {
+		if sc.ProjectID == projectID {
+			out = append(out, *sc)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeCommandSchedules) ToggleActiveStatus(id uint, active bool) error {
+	s.schedules[id].Active = active
+	return nil
+}
+
+type fakeCommandCron struct {
+	ICronService
+	registered []uint
+	removed    []uint
+}
+
+func (c *fakeCommandCron) Register(s *models.ReminderSchedule) {
+	c.registered = append(c.registered, s.ID)
+}
+
+func (c *fakeCommandCron) Remove(id uint) {
+	c.removed = append(c.removed, id)
+}
+
+type fakeCommandCveSearch struct {
+	ICveSearchService
+	records map[string]*models.CveRecord
+}
+
+func (s *fakeCommandCveSearch) GetByCveID(cveID string) (*models.CveRecord, error) {
+	if r, ok := s.records[cveID]; ok {
+		return r, nil
+	}
+	return nil, errors.New(errors.ErrResourceNotFound, "not found")
+}
+
+type commandTestEnv struct {
+	svc       *ChatworkCommandService
+	srv       *chatworktest.Server
+	schedules *fakeCommandSchedules
+	cron      *fakeCommandCron
+	alerts    *fakeAlertRepo
+	escalator *fakeEscalationRepo
+}
+
+func newCommandTestEnv(t *testing.T) *commandTestEnv {
+	t.Helper()
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("bot-token", chatwork.Me{AccountID: 1, Name: "Release Bot"})
+	srv.AddRoom(chatwork.Room{RoomID: 42, Name: "Team", Type: chatwork.RoomTypeGroup},
+		chatwork.Member{AccountID: 1}, chatwork.Member{AccountID: 2}, chatwork.Member{AccountID: 3})
+	srv.AddRoom(chatwork.Room{RoomID: 43, Name: "Random", Type: chatwork.RoomTypeGroup},
+		chatwork.Member{AccountID: 1}, chatwork.Member{AccountID: 2})
+
+	bots := &fakeBotRepo{bots: map[uint]*models.ChatworkBot{
+		7: {ID: 7, APIToken: "bot-token", AccountID: 1, WebhookToken: webhookToken},
+	}}
+	grants := &fakeCommandGrantRepo{grants: []models.ChatworkCommandGrant{
+		{ID: 1, ProjectID: 3, RoomID: "42", Commands: []string{models.CommandSchedules, models.CommandCve}},
+		{ID: 2, ProjectID: 3, RoomID: "42", Commands: []string{models.CommandPause, models.CommandResume}, AccountIDs: []int64{2}},
+		{ID: 3, ProjectID: 3, RoomID: "42", Commands: []string{models.CommandAlerts, models.CommandAck, models.CommandOnCall}},
+	}}
+	schedules := &fakeCommandSchedules{schedules: map[uint]*models.ReminderSchedule{
+		5: {ID: 5, ProjectID: 3, Name: "Daily standup", CronExpression: "0 9 * * 1-5", Active: true},
+		6: {ID: 6, ProjectID: 4, Name: "Other project", CronExpression: "0 10 * * *", Active: true},
+	}}
+	cron := &fakeCommandCron{}
+	cves := &fakeCommandCveSearch{records: map[string]*models.CveRecord{
+		"CVE-2026-1234": {CveID: "CVE-2026-1234", Severity: "HIGH", BaseScore: 8.1, Description: "Remote code execution in widget parser."},
+	}}
+
+	alertRepo := &fakeAlertRepo{groups: []*models.AlertGroup{
+		{ID: 1, ProjectID: 3, EndpointID: 1, Labels: map[string]string{"alertname": "DiskFull"}, Status: models.AlertFiring, RoomID: "42", MessageID: "3001"},
+		{ID: 2, ProjectID: 4, EndpointID: 1, Labels: map[string]string{"alertname": "Other"}, Status: models.AlertFiring, RoomID: "42", MessageID: "3002"},
+	}}
+	endpoints := &fakeWebhookEndpointRepo{endpoints: map[uint]*models.WebhookEndpoint{1: {ID: 1, ProjectID: 3, BotID: 7, RoomID: "42"}}}
+	alerts := NewAlertService(alertRepo, endpoints, bots)
+	alerts.baseURL = srv.URL
+
+	escalationRepo := newFakeEscalationRepo(t)
+	escalations := NewEscalationService(escalationRepo, bots)
+	escalations.baseURL = srv.URL
+
+	svc := NewChatworkCommandService(bots, grants, schedules, cron, nil, cves, alerts, escalations)
+	svc.baseURL = srv.URL
+	svc.dispatch = func(f func()) { f() }
+	return &commandTestEnv{svc: svc, srv: srv, schedules: schedules, cron: cron, alerts: alertRepo, escalator: escalationRepo}
+}
+
+// deliver sends a signed fixture payload the way Chatwork would.
+func (e *commandTestEnv) deliver(t *testing.T, fixture string) error {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "chatwork_webhooks", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	signature, err := chatwork.SignWebhook(webhookToken, body)
+	if err != nil {
+		t.Fatalf("sign fixture: %v", err)
+	}
+	return e.svc.HandleWebhook(7, body, signature)
+}
+
+func (e *commandTestEnv) lastReply(t *testing.T, roomID int64) string {
+	t.Helper()
+	messages := e.srv.Messages(roomID)
+	if len(messages) == 0 {
+		t.Fatalf("expected a reply in room %d", roomID)
+	}
+	return messages[len(messages)-1].Body
+}
+
+func TestChatworkCommandRejectsBadSignature(t *testing.T) {
+	env := newCommandTestEnv(t)
+	body, _ := os.ReadFile(filepath.Join("testdata", "chatwork_webhooks", "schedules.json"))
+
+	err := env.svc.HandleWebhook(7, body, "bm90LXRoZS1zaWduYXR1cmU=")
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+	if msgs := env.srv.Messages(42); len(msgs) != 0 {
+		t.Fatalf("expected no reply to an unsigned request, got %+v", msgs)
+	}
+}
+
+func TestChatworkCommandSchedules(t *testing.T) {
+	env := newCommandTestEnv(t)
+	if err := env.deliver(t, "schedules.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+
+	reply := env.lastReply(t, 42)
+	if !strings.HasPrefix(reply, "[rp aid=2 to=42-1001]") {
+		t.Fatalf("expected a reply to the command message, got %q", reply)
+	}
+	if !strings.Contains(reply, "#5 Daily standup") || strings.Contains(reply, "Other project") {
+		t.Fatalf("expected only the granted project's schedules, got %q", reply)
+	}
+}
+
+func TestChatworkCommandPauseAndResume(t *testing.T) {
+	env := newCommandTestEnv(t)
+
+	if err := env.deliver(t, "pause_unlisted.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if reply := env.lastReply(t, 42); !strings.Contains(reply, "not allowed to run /pause") {
+		t.Fatalf("expected a permission error, got %q", reply)
+	}
+	if !env.schedules.schedules[5].Active {
+		t.Fatal("schedule must not be paused by an account outside the grant")
+	}
+
+	if err := env.deliver(t, "pause.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if env.schedules.schedules[5].Active || len(env.cron.removed) != 1 {
+		t.Fatalf("expected schedule 5 to be paused and unscheduled, got %+v, %+v", env.schedules.schedules[5], env.cron)
+	}
+	if reply := env.lastReply(t, 42); !strings.Contains(reply, "is now paused") {
+		t.Fatalf("unexpected reply: %q", reply)
+	}
+
+	if err := env.deliver(t, "resume_reply.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if !env.schedules.schedules[5].Active || len(env.cron.registered) != 1 {
+		t.Fatalf("expected schedule 5 to be resumed and scheduled, got %+v, %+v", env.schedules.schedules[5], env.cron)
+	}
+}
+
+func TestChatworkCommandCve(t *testing.T) {
+	env := newCommandTestEnv(t)
+	if err := env.deliver(t, "cve.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+
+	reply := env.lastReply(t, 42)
+	for _, want := range []string{"[info][title]CVE-2026-1234[/title]", "HIGH (8.1)", "https://nvd.nist.gov/vuln/detail/CVE-2026-1234"} {
+		if !strings.Contains(reply, want) {
+			t.Fatalf("expected %q in reply, got %q", want, reply)
+		}
+	}
+}
+
+func TestChatworkCommandAck(t *testing.T) {
+	env := newCommandTestEnv(t)
+
+	if err := env.deliver(t, "alerts.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if reply := env.lastReply(t, 42); !strings.Contains(reply, "#1 DiskFull") || strings.Contains(reply, "Other") {
+		t.Fatalf("expected only the granted project's alerts, got %q", reply)
+	}
+
+	if err := env.deliver(t, "ack_other_project.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if reply := env.lastReply(t, 42); !strings.Contains(reply, "#2 was not found") || env.alerts.groups[1].AckedAt != nil {
+		t.Fatalf("expected another project's group to be refused, got %q", reply)
+	}
+
+	if err := env.deliver(t, "ack_reply.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if g := env.alerts.groups[0]; g.AckedAt == nil || g.AckedBy != "Chatwork account 2" {
+		t.Fatalf("expected the replied-to group to be acknowledged, got %+v", g)
+	}
+	messages := env.srv.Messages(42)
+	if len(messages) < 2 || !strings.Contains(messages[len(messages)-2].Body, "[rp aid=1 to=42-3001]") {
+		t.Fatalf("expected a note under the alert message, got %+v", messages)
+	}
+	if reply := env.lastReply(t, 42); !strings.Contains(reply, "Acknowledged alert group #1 DiskFull") {
+		t.Fatalf("unexpected reply: %q", reply)
+	}
+}
+
+func TestChatworkCommandOnCallAndEscalationAck(t *testing.T) {
+	env := newCommandTestEnv(t)
+
+	if err := env.deliver(t, "oncall.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if reply := env.lastReply(t, 42); !strings.Contains(reply, "Backend — ") || !strings.Contains(reply, "until") {
+		t.Fatalf("expected the rotation's on-call person, got %q", reply)
+	}
+
+	env.escalator.escalations = []*models.Escalation{{ID: 1, ProjectID: 3, PolicyID: 1, Title: "Database down", Status: models.EscalationOpen}}
+	env.escalator.notifications = []*models.EscalationNotification{{ID: 1, EscalationID: 1, RoomID: "42", MessageID: "3003"}}
+	if err := env.deliver(t, "ack_escalation.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if e := env.escalator.escalations[0]; e.Status != models.EscalationAcknowledged || e.AckedBy != "Chatwork account 2" {
+		t.Fatalf("expected the replied-to escalation to be acknowledged, got %+v", e)
+	}
+	if reply := env.lastReply(t, 42); !strings.Contains(reply, "Acknowledged escalation #1 Database down") {
+		t.Fatalf("unexpected reply: %q", reply)
+	}
+	if env.alerts.groups[0].AckedAt != nil {
+		t.Fatal("expected the alert groups to be left alone")
+	}
+}
+
+func TestChatworkCommandIgnoresAndUnlinkedRooms(t *testing.T) {
+	env := newCommandTestEnv(t)
+
+	if err := env.deliver(t, "not_mentioned.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if msgs := env.srv.Messages(42); len(msgs) != 0 {
+		t.Fatalf("expected messages that don't mention the bot to be ignored, got %+v", msgs)
+	}
+
+	if err := env.deliver(t, "unlinked_room.json"); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if reply := env.lastReply(t, 43); !strings.Contains(reply, "not linked to any project") {
+		t.Fatalf("unexpected reply: %q", reply)
+	}
+}
+
+func TestChatworkCommandGrantValidation(t *testing.T) {
+	env := newCommandTestEnv(t)
+	room := "42"
+
+	if _, err := env.svc.CreateGrant(3, &ChatworkCommandGrantInput{RoomID: &room, Commands: []string{"deploy"}}); err == nil {
+		t.Fatal("expected an unknown command to be rejected")
+	}
+	if _, err := env.svc.CreateGrant(3, &ChatworkCommandGrantInput{RoomID: &room}); err == nil {
+		t.Fatal("expected a grant without commands to be rejected")
+	}
+	bad := "not-a-room"
+	if _, err := env.svc.CreateGrant(3, &ChatworkCommandGrantInput{RoomID: &bad, Commands: []string{"cve"}}); err == nil {
+		t.Fatal("expected an invalid room ID to be rejected")
+	}
+}
+
+func TestChatworkBotServiceSetWebhookToken(t *testing.T) {
+	svc, bots, _ := newDirectoryTestService(t)
+
+	if _, err := svc.SetWebhookToken(7, "not base64!"); err == nil {
+		t.Fatal("expected a token that is not base64 to be rejected")
+	}
+	detail, err := svc.SetWebhookToken(7, webhookToken)
+	if err != nil || !detail.WebhookConfigured || bots.bots[7].WebhookToken != webhookToken {
+		t.Fatalf("expected the token to be stored, got %+v, %v", detail, err)
+	}
+	if detail, _ := svc.SetWebhookToken(7, ""); detail.WebhookConfigured {
+		t.Fatal("expected an empty token to turn the webhook off")
+	}
+}
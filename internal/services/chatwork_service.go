@@ -1,16 +1,18 @@
 package services
 
 import (
-	"fmt"
-	"io"
-	"net/http"
-	"net/url" // ← import this
-	"strings"
-	"time"
+	"context"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
 )
 
 type IChatworkService interface {
 	SendMessage(apiKey, roomId, message string) error
+	// SendMessageWithPriority queues the message in the given lane of the token's outbound queue.
+	SendMessageWithPriority(apiKey, roomId, message string, priority chatwork.Priority) error
+	CreateTasks(apiKey, roomId string, input chatwork.CreateTaskInput) ([]int64, error)
+	GetTask(apiKey, roomId string, taskID int64) (*chatwork.Task, error)
 }
 
 type ChatworkService struct {
@@ -19,43 +21,48 @@ type ChatworkService struct {
 
 func NewChatworkService() *ChatworkService {
 	return &ChatworkService{
-		BaseURL: "https://api.chatwork.com/v2",
+		BaseURL: chatworkBaseURL(),
 	}
 }
 
-func (c *ChatworkService) SendMessage(apiKey, roomId, message string) error {
-	// rename local var so it doesn't clash with the url package
-	endpoint := fmt.Sprintf("%s/rooms/%s/messages", c.BaseURL, roomId)
+// chatworkBaseURL returns the Chatwork API root, overridable with CHATWORK_API_BASE_URL
+// to point the services at a proxy or a fake server.
+func chatworkBaseURL() string {
+	return utils.GetEnv("CHATWORK_API_BASE_URL", chatwork.DefaultBaseURL)
+}
 
-	// Prepare URL-encoded form data
-	form := url.Values{}
-	form.Set("body", message)
+func (c *ChatworkService) SendMessage(apiKey, roomId, message string) error {
+	return c.SendMessageWithPriority(apiKey, roomId, message, chatwork.PriorityNormal)
+}
 
-	// Create request with encoded form
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+func (c *ChatworkService) SendMessageWithPriority(apiKey, roomId, message string, priority chatwork.Priority) error {
+	ctx, cancel := context.WithTimeout(chatwork.WithPriority(context.Background(), priority), chatworkQueueTimeout)
+	defer cancel()
 
-	// Set headers
-	req.Header.Set("X-ChatWorkToken", apiKey)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := chatwork.NewClient(apiKey, chatwork.WithBaseURL(c.BaseURL))
+	_, err := client.PostMessageTo(ctx, roomId, message)
+	return err
+}
 
-	// Send request
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+// CreateTasks creates one task per assignee and returns the task IDs.
+func (c *ChatworkService) CreateTasks(apiKey, roomId string, input chatwork.CreateTaskInput) ([]int64, error) {
+	roomID, err := chatwork.ParseRoomID(roomId)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), chatworkQueueTimeout)
+	defer cancel()
 
-	// Debug: read response body
-	body, _ := io.ReadAll(resp.Body)
-	fmt.Println("Response Body:", string(body))
+	return chatwork.NewClient(apiKey, chatwork.WithBaseURL(c.BaseURL)).CreateTask(ctx, roomID, input)
+}
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to send message: received status %d", resp.StatusCode)
+func (c *ChatworkService) GetTask(apiKey, roomId string, taskID int64) (*chatwork.Task, error) {
+	roomID, err := chatwork.ParseRoomID(roomId)
+	if err != nil {
+		return nil, err
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), chatworkQueueTimeout)
+	defer cancel()
 
-	return nil
+	return chatwork.NewClient(apiKey, chatwork.WithBaseURL(c.BaseURL)).Task(ctx, roomID, taskID)
 }
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+)
+
+func TestChatworkServiceSendMessage(t *testing.T) {
+	srv := chatworktest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("token", chatwork.Me{AccountID: 1})
+	srv.AddRoom(chatwork.Room{RoomID: 42}, chatwork.Member{AccountID: 1, Role: chatwork.RoleAdmin})
+
+	svc := &ChatworkService{BaseURL: srv.URL}
+	if err := svc.SendMessage("token", "42", "hello"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if got := srv.Messages(42); len(got) != 1 || got[0].Body != "hello" {
+		t.Fatalf("unexpected messages: %+v", got)
+	}
+
+	err := svc.SendMessage("bad-token", "42", "hello")
+	if !chatwork.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestChatworkBotServiceFetchIncomingRequests(t *testing.T) {
+	srv := chatworktest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("token", chatwork.Me{AccountID: 1, Name: "Bot"})
+
+	svc := &ChatworkBotService{baseURL: srv.URL}
+	requests, err := svc.fetchIncomingRequests("token")
+	if err != nil || len(requests) != 0 {
+		t.Fatalf("expected empty list on 204, got %v, %v", requests, err)
+	}
+
+	srv.AddIncomingRequest("token", chatwork.IncomingRequest{RequestID: 7, AccountID: 99, Name: "Carol"})
+	requests, err = svc.fetchIncomingRequests("token")
+	if err != nil || len(requests) != 1 || requests[0].AccountID != 99 {
+		t.Fatalf("unexpected requests: %v, %v", requests, err)
+	}
+
+	if me := svc.fetchMe("bad-token"); me != nil {
+		t.Fatalf("expected nil profile for rejected token, got %+v", me)
+	}
+	bot := &models.ChatworkBot{ID: 3}
+	applyProfile(bot, svc.fetchMe("token"))
+	if detail := svc.buildBotDetail(bot); detail.AccountID != 1 || detail.Name != "Bot" {
+		t.Fatalf("unexpected bot detail: %+v", detail)
+	}
+}
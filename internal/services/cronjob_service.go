@@ -1,12 +1,15 @@
 package services
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/robfig/cron/v3"
 	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
 	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
 	"gorm.io/gorm"
 )
 
@@ -17,6 +20,18 @@ type CronService struct {
 	db         *gorm.DB
 	lock       sync.Mutex
 	cw         *ChatworkService
+	botRepo    repositories.IChatworkBotRepository
+	tasks      IReminderTaskService
+	bots       IChatworkBotService
+	botHealth  IBotHealthService
+	botRules   IBotRequestRuleService
+	webhooks   IWebhookEndpointService
+	escalation IEscalationService
+
+	notifications INotificationService
+	cveRecordRepo repositories.ICveRecordRepository
+	techStacks    ITechStackService
+	cveReports    ICveReportService
 }
 
 type ICronService interface {
@@ -28,6 +43,12 @@ type ICronService interface {
 	Stop()
 	SyncAll()
 	RegisterCVECrawler()
+	RegisterTaskFollowUps()
+	RegisterBotDirectorySync()
+	RegisterBotHealthCheck()
+	RegisterBotRequestRules()
+	RegisterWebhookFlush()
+	RegisterEscalations()
 }
 
 func NewCronService(db *gorm.DB) *CronService {
@@ -40,6 +61,45 @@ func NewCronService(db *gorm.DB) *CronService {
 	}
 }
 
+// CronDependencies are the services the jobs run with. They are the instances
+// the routes are wired with, so both share one service graph.
+type CronDependencies struct {
+	Chatwork      *ChatworkService
+	BotRepo       repositories.IChatworkBotRepository
+	CveRecordRepo repositories.ICveRecordRepository
+	Tasks         IReminderTaskService
+	Bots          IChatworkBotService
+	BotHealth     IBotHealthService
+	BotRules      IBotRequestRuleService
+	Webhooks      IWebhookEndpointService
+	Escalation    IEscalationService
+	Notifications INotificationService
+	TechStacks    ITechStackService
+	CveReports    ICveReportService
+}
+
+// SetDependencies injects the services the jobs use. It must be called before
+// any job is registered.
+func (cs *CronService) SetDependencies(deps CronDependencies) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	if deps.Chatwork != nil {
+		cs.cw = deps.Chatwork
+	}
+	cs.botRepo = deps.BotRepo
+	cs.cveRecordRepo = deps.CveRecordRepo
+	cs.tasks = deps.Tasks
+	cs.bots = deps.Bots
+	cs.botHealth = deps.BotHealth
+	cs.botRules = deps.BotRules
+	cs.webhooks = deps.Webhooks
+	cs.escalation = deps.Escalation
+	cs.notifications = deps.Notifications
+	cs.techStacks = deps.TechStacks
+	cs.cveReports = deps.CveReports
+}
+
 func (cs *CronService) LoadFromDB() {
 	var schedules []models.ReminderSchedule
 	result := cs.db.Where("active = ?", true).Find(&schedules)
@@ -64,47 +124,34 @@ func (cs *CronService) Register(s *models.ReminderSchedule) {
 	cs.removeReminderScheduleLocked(s.ID)
 
 	entryID, err := cs.c.AddFunc(s.CronExpression, func() {
-		// Resolve the token to use: prefer bot's token if botId is set
-		token := ""
-		if s.ChatworkToken != nil {
-			token = *s.ChatworkToken
-		}
-		if s.BotID != nil {
-			var bot models.ChatworkBot
-			if err := cs.db.First(&bot, *s.BotID).Error; err != nil {
-				logger.Errorf("[Reminder #%d] Failed to fetch bot token for BotID %d: %v", s.ID, *s.BotID, err)
-			} else {
-				token = bot.APIToken
-			}
-		}
-
-		// Mask token for logging
-		maskedToken := ""
-		if len(token) > 4 {
-			maskedToken = token[:4] + "..."
-		} else if len(token) > 0 {
-			maskedToken = "..." // Mask if token is short but not empty
-		} else {
-			maskedToken = "[EMPTY]" // Indicate if token is empty
-		}
-
-		logger.Infof("[Reminder #%d] Attempting to send message. RoomID: '%s', Token (masked): '%s', Message: '%s'", s.ID, s.ChatworkRoomID, maskedToken, s.Message)
-
-		err := cs.cw.SendMessage(token, s.ChatworkRoomID, s.Message)
-
 		logEntry := models.ScheduleLog{
 			ProjectID:  s.ProjectID,
 			ScheduleID: s.ID,
 			Status:     "success",
 		}
 
+		var err error
+		target := fmt.Sprintf("room %s", s.ChatworkRoomID)
+		switch {
+		case s.ChannelID != nil:
+			target = fmt.Sprintf("channel %d", *s.ChannelID)
+			logger.Infof("[Reminder #%d] Attempting to send message to %s. Message: '%s'", s.ID, target, s.Message)
+			err = cs.notifications.Notify(*s.ChannelID, notifier.Message{Text: s.Message})
+		case s.IsTaskAction():
+			err = cs.tasks.CreateTasks(s, resolveScheduleToken(s, cs.botRepo), &logEntry)
+			if err == nil {
+				logger.Infof("[Reminder #%d] Created Chatwork tasks %v in %s", s.ID, logEntry.TaskIDs, target)
+			}
+		default:
+			err = cs.sendReminderToChatwork(s)
+		}
+
 		if err != nil {
-			// Log detailed error. The error 'err' from cs.cw.SendMessage should ideally include details from Chatwork API.
-			logger.Errorf("[Reminder #%d] Error sending message to RoomID '%s'. Token (masked): '%s', Message: '%s'. Error: %v", s.ID, s.ChatworkRoomID, maskedToken, s.Message, err)
+			logger.Errorf("[Reminder #%d] Error sending message to %s. Message: '%s'. Error: %v", s.ID, target, s.Message, err)
 			logEntry.Status = "error"
 			logEntry.ErrorMessage = err.Error()
 		} else {
-			logger.Infof("[Reminder #%d] Successfully sent message to room %s", s.ID, s.ChatworkRoomID)
+			logger.Infof("[Reminder #%d] Successfully sent message to %s", s.ID, target)
 		}
 
 		if dbErr := cs.db.Create(&logEntry).Error; dbErr != nil {
@@ -120,6 +167,26 @@ func (cs *CronService) Register(s *models.ReminderSchedule) {
 	}
 }
 
+// sendReminderToChatwork posts the reminder with its own token or its bot's token.
+func (cs *CronService) sendReminderToChatwork(s *models.ReminderSchedule) error {
+	// Resolve the token to use: prefer bot's token if botId is set
+	token := resolveScheduleToken(s, cs.botRepo)
+
+	// Mask token for logging
+	maskedToken := ""
+	if len(token) > 4 {
+		maskedToken = token[:4] + "..."
+	} else if len(token) > 0 {
+		maskedToken = "..." // Mask if token is short but not empty
+	} else {
+		maskedToken = "[EMPTY]" // Indicate if token is empty
+	}
+
+	logger.Infof("[Reminder #%d] Attempting to send message. RoomID: '%s', Token (masked): '%s', Message: '%s'", s.ID, s.ChatworkRoomID, maskedToken, s.Message)
+
+	return cs.cw.SendMessage(token, s.ChatworkRoomID, s.Message)
+}
+
 func (cs *CronService) Remove(scheduleID uint) {
 	cs.lock.Lock()
 	defer cs.lock.Unlock()
@@ -217,12 +284,17 @@ func (cs *CronService) RegisterCVECrawler() {
 	roomID := utils.GetEnv("CVE_CHATWORK_ROOM_ID", "")
 	apiKey := utils.GetEnv("CVE_CHATWORK_API_KEY", "")
 
-	if roomID == "" || apiKey == "" {
-		logger.Warn("[CVE] CVE_CHATWORK_ROOM_ID or CVE_CHATWORK_API_KEY not configured, skipping CVE crawler job")
-		return
+	nvdAPIKey := utils.GetEnv("NVD_API_KEY", "")
+
+	if (roomID == "" || apiKey == "") && utils.GetEnv("CVE_NOTIFY_CHANNEL_ID", "") == "" {
+		logger.Warn("[CVE] CVE_CHATWORK_ROOM_ID/CVE_CHATWORK_API_KEY or CVE_NOTIFY_CHANNEL_ID not configured, CVE crawler will only store records")
 	}
 
-	cveService := NewCveCrawlerService(roomID, apiKey, "")
+	cveService := NewCveCrawlerService(roomID, apiKey, nvdAPIKey, cs.cveRecordRepo, cs.techStacks)
+	if channelID := utils.GetEnvAsInt("CVE_NOTIFY_CHANNEL_ID", 0); channelID > 0 {
+		cveService.SetNotificationChannel(cs.notifications, uint(channelID))
+	}
+	cveService.SetReportService(cs.cveReports)
 
 	_, err := cs.c.AddFunc("0 0 0 * * *", func() {
 		logger.Info("[CVE] Starting daily CVE crawl job")
@@ -238,6 +310,78 @@ func (cs *CronService) RegisterCVECrawler() {
 	logger.Info("[CVE] CVE crawler job registered successfully (runs daily at 00:00 UTC / 07:00 GMT+7)")
 }
 
+// RegisterTaskFollowUps checks every minute for task runs whose follow-up is due.
+func (cs *CronService) RegisterTaskFollowUps() {
+	if _, err := cs.c.AddFunc("0 * * * * *", cs.tasks.RunFollowUps); err != nil {
+		logger.Errorf("[Reminder] Failed to register task follow-up job: %v", err)
+		return
+	}
+	logger.Info("[Reminder] Task follow-up job registered (runs every minute)")
+}
+
+// RegisterBotDirectorySync refreshes the cached rooms and members of every bot,
+// every 30 minutes unless CHATWORK_BOT_SYNC_CRON says otherwise.
+func (cs *CronService) RegisterBotDirectorySync() {
+	spec := utils.GetEnv("CHATWORK_BOT_SYNC_CRON", "0 */30 * * * *")
+	if _, err := cs.c.AddFunc(spec, cs.bots.SyncAll); err != nil {
+		logger.Errorf("[BotSync] Failed to register bot directory sync job: %v", err)
+		return
+	}
+	logger.Infof("[BotSync] Bot directory sync job registered (%s)", spec)
+}
+
+// RegisterBotHealthCheck validates every bot token, every 15 minutes unless
+// CHATWORK_BOT_HEALTH_CRON says otherwise.
+func (cs *CronService) RegisterBotHealthCheck() {
+	spec := utils.GetEnv("CHATWORK_BOT_HEALTH_CRON", "0 */15 * * * *")
+	if _, err := cs.c.AddFunc(spec, cs.botHealth.CheckAll); err != nil {
+		logger.Errorf("[BotHealth] Failed to register bot health check job: %v", err)
+		return
+	}
+	logger.Infof("[BotHealth] Bot health check job registered (%s)", spec)
+}
+
+// RegisterBotRequestRules applies the contact request rules to every bot, every
+// 5 minutes unless CHATWORK_BOT_REQUEST_CRON says otherwise.
+func (cs *CronService) RegisterBotRequestRules() {
+	spec := utils.GetEnv("CHATWORK_BOT_REQUEST_CRON", "0 */5 * * * *")
+	if _, err := cs.c.AddFunc(spec, func() { cs.botRules.Run() }); err != nil {
+		logger.Errorf("[BotRequests] Failed to register bot request rules job: %v", err)
+		return
+	}
+	logger.Infof("[BotRequests] Bot request rules job registered (%s)", spec)
+}
+
+// RegisterWebhookFlush posts the webhook dedup counts and quiet hours digests
+// that are due, every minute unless WEBHOOK_FLUSH_CRON says otherwise.
+func (cs *CronService) RegisterWebhookFlush() {
+	spec := utils.GetEnv("WEBHOOK_FLUSH_CRON", "0 * * * * *")
+	if _, err := cs.c.AddFunc(spec, func() {
+		if err := cs.webhooks.FlushDue(); err != nil {
+			logger.Errorf("[WebhookEndpoint] Failed to flush buffered events: %v", err)
+		}
+	}); err != nil {
+		logger.Errorf("[WebhookEndpoint] Failed to register webhook flush job: %v", err)
+		return
+	}
+	logger.Infof("[WebhookEndpoint] Webhook flush job registered (%s)", spec)
+}
+
+// RegisterEscalations notifies the next level of escalations nobody
+// acknowledged in time, every minute unless ESCALATION_CRON says otherwise.
+func (cs *CronService) RegisterEscalations() {
+	spec := utils.GetEnv("ESCALATION_CRON", "0 * * * * *")
+	if _, err := cs.c.AddFunc(spec, func() {
+		if err := cs.escalation.EscalateDue(); err != nil {
+			logger.Errorf("[Escalation] Failed to escalate due escalations: %v", err)
+		}
+	}); err != nil {
+		logger.Errorf("[Escalation] Failed to register escalation job: %v", err)
+		return
+	}
+	logger.Infof("[Escalation] Escalation job registered (%s)", spec)
+}
+
 func (cs *CronService) RegisterCVEConfigs() {
 	if cveConfigService == nil {
 		logger.Warn("[CVE] CVE config service not set, skipping CVE config cron jobs")
@@ -5,13 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/mail"
 	"strings"
 	"time"
 
 	"github.com/vfa-khuongdv/golang-cms/internal/models"
 	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
 	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
 )
 
 type ICveConfigService interface {
@@ -41,8 +45,10 @@ type CveAnalysis struct {
 type CveConfigService struct {
 	repo            repositories.ICveConfigRepository
 	logRepo         repositories.ICveScanLogRepository
-	chatworkSvc     *ChatworkService
+	notifications   INotificationService
+	reports         ICveReportService
 	chatworkBotRepo repositories.IChatworkBotRepository
+	escalations     IEscalationService
 }
 
 var supportedOSVEcosystems = map[string]string{
@@ -75,12 +81,14 @@ var supportedOSVEcosystems = map[string]string{
 	"chainguard":     "Chainguard",
 }
 
-func NewCveConfigService(repo repositories.ICveConfigRepository, logRepo repositories.ICveScanLogRepository, botRepo repositories.IChatworkBotRepository) *CveConfigService {
+func NewCveConfigService(repo repositories.ICveConfigRepository, logRepo repositories.ICveScanLogRepository, botRepo repositories.IChatworkBotRepository, notifications INotificationService, reports ICveReportService, escalations IEscalationService) *CveConfigService {
 	return &CveConfigService{
 		repo:            repo,
 		logRepo:         logRepo,
-		chatworkSvc:     NewChatworkService(),
+		notifications:   notifications,
+		reports:         reports,
 		chatworkBotRepo: botRepo,
+		escalations:     escalations,
 	}
 }
 
@@ -129,37 +137,47 @@ func (s *CveConfigService) GetAllForCron() ([]CveConfigForCron, error) {
 }
 
 type CveConfigInput struct {
-	Name             string `json:"name" binding:"required"`
-	RepoUrl          string `json:"repoUrl"`
-	Languages        string `json:"languages" binding:"required"`
-	Cron             string `json:"cron" binding:"required"`
-	Status           string `json:"status"`
-	ApiKey           string `json:"apiKey"`
-	BotID            *int   `json:"botId"`
-	NotifyOnSuccess  bool   `json:"notifyOnSuccess"`
-	NotifyOnFailure  bool   `json:"notifyOnFailure"`
-	NotifyRoomId     string `json:"notifyRoomId"`
-	NotifyOnCritical bool   `json:"notifyOnCritical"`
-	NotifyOnHigh     bool   `json:"notifyOnHigh"`
-	NotifyOnModerate bool   `json:"notifyOnModerate"`
-	NotifyOnLow      bool   `json:"notifyOnLow"`
+	Name               string   `json:"name" binding:"required"`
+	RepoUrl            string   `json:"repoUrl"`
+	Languages          string   `json:"languages" binding:"required"`
+	Cron               string   `json:"cron" binding:"required"`
+	Status             string   `json:"status"`
+	ApiKey             string   `json:"apiKey"`
+	BotID              *int     `json:"botId"`
+	NotifyOnSuccess    bool     `json:"notifyOnSuccess"`
+	NotifyOnFailure    bool     `json:"notifyOnFailure"`
+	NotifyRoomId       string   `json:"notifyRoomId"`
+	ChannelID          *uint    `json:"channelId"`
+	EscalationPolicyID *uint    `json:"escalationPolicyId"`
+	NotifyOnCritical   bool     `json:"notifyOnCritical"`
+	NotifyOnHigh       bool     `json:"notifyOnHigh"`
+	NotifyOnModerate   bool     `json:"notifyOnModerate"`
+	NotifyOnLow        bool     `json:"notifyOnLow"`
+	EmailRecipients    []string `json:"emailRecipients"`
+	EmailAttachCsv     bool     `json:"emailAttachCsv"`
+	EmailDigest        bool     `json:"emailDigest"`
 }
 
 type CveConfigUpdateInput struct {
-	Name             *string `json:"name"`
-	RepoUrl          *string `json:"repoUrl"`
-	Languages        *string `json:"languages"`
-	Cron             *string `json:"cron"`
-	Status           *string `json:"status"`
-	ApiKey           *string `json:"apiKey"`
-	BotID            *int    `json:"botId"`
-	NotifyOnSuccess  *bool   `json:"notifyOnSuccess"`
-	NotifyOnFailure  *bool   `json:"notifyOnFailure"`
-	NotifyRoomId     *string `json:"notifyRoomId"`
-	NotifyOnCritical *bool   `json:"notifyOnCritical"`
-	NotifyOnHigh     *bool   `json:"notifyOnHigh"`
-	NotifyOnModerate *bool   `json:"notifyOnModerate"`
-	NotifyOnLow      *bool   `json:"notifyOnLow"`
+	Name               *string  `json:"name"`
+	RepoUrl            *string  `json:"repoUrl"`
+	Languages          *string  `json:"languages"`
+	Cron               *string  `json:"cron"`
+	Status             *string  `json:"status"`
+	ApiKey             *string  `json:"apiKey"`
+	BotID              *int     `json:"botId"`
+	NotifyOnSuccess    *bool    `json:"notifyOnSuccess"`
+	NotifyOnFailure    *bool    `json:"notifyOnFailure"`
+	NotifyRoomId       *string  `json:"notifyRoomId"`
+	ChannelID          *uint    `json:"channelId"`
+	EscalationPolicyID *uint    `json:"escalationPolicyId"`
+	NotifyOnCritical   *bool    `json:"notifyOnCritical"`
+	NotifyOnHigh       *bool    `json:"notifyOnHigh"`
+	NotifyOnModerate   *bool    `json:"notifyOnModerate"`
+	NotifyOnLow        *bool    `json:"notifyOnLow"`
+	EmailRecipients    []string `json:"emailRecipients"`
+	EmailAttachCsv     *bool    `json:"emailAttachCsv"`
+	EmailDigest        *bool    `json:"emailDigest"`
 }
 
 func (s *CveConfigService) GetByProjectID(projectID uint, paging *utils.Paging) ([]models.CveConfig, int64, error) {
@@ -175,28 +193,44 @@ func (s *CveConfigService) Create(projectID uint, input *CveConfigInput) (*model
 		return nil, fmt.Errorf("name, languages, and cron are required")
 	}
 
+	if err := s.validateChannel(input.ChannelID, projectID); err != nil {
+		return nil, err
+	}
+	if err := s.validateEscalationPolicy(input.EscalationPolicyID, projectID); err != nil {
+		return nil, err
+	}
+	recipients, err := normalizeEmailRecipients(input.EmailRecipients)
+	if err != nil {
+		return nil, err
+	}
+
 	status := input.Status
 	if status == "" {
 		status = "active"
 	}
 
 	config := &models.CveConfig{
-		ID:               generateUUID(),
-		ProjectID:        int(projectID),
-		Name:             input.Name,
-		RepoUrl:          input.RepoUrl,
-		Languages:        input.Languages,
-		Cron:             input.Cron,
-		Status:           status,
-		ApiKey:           input.ApiKey,
-		BotID:            input.BotID,
-		NotifyOnSuccess:  input.NotifyOnSuccess,
-		NotifyOnFailure:  input.NotifyOnFailure,
-		NotifyRoomId:     input.NotifyRoomId,
-		NotifyOnCritical: input.NotifyOnCritical,
-		NotifyOnHigh:     input.NotifyOnHigh,
-		NotifyOnModerate: input.NotifyOnModerate,
-		NotifyOnLow:      input.NotifyOnLow,
+		ID:                 generateUUID(),
+		ProjectID:          int(projectID),
+		Name:               input.Name,
+		RepoUrl:            input.RepoUrl,
+		Languages:          input.Languages,
+		Cron:               input.Cron,
+		Status:             status,
+		ApiKey:             input.ApiKey,
+		BotID:              input.BotID,
+		NotifyOnSuccess:    input.NotifyOnSuccess,
+		NotifyOnFailure:    input.NotifyOnFailure,
+		NotifyRoomId:       input.NotifyRoomId,
+		ChannelID:          input.ChannelID,
+		EscalationPolicyID: input.EscalationPolicyID,
+		NotifyOnCritical:   input.NotifyOnCritical,
+		NotifyOnHigh:       input.NotifyOnHigh,
+		NotifyOnModerate:   input.NotifyOnModerate,
+		NotifyOnLow:        input.NotifyOnLow,
+		EmailRecipients:    recipients,
+		EmailAttachCsv:     input.EmailAttachCsv,
+		EmailDigest:        input.EmailDigest,
 	}
 
 	return s.repo.Create(config)
@@ -238,6 +272,28 @@ func (s *CveConfigService) Update(id string, projectID uint, input *CveConfigUpd
 	if input.NotifyRoomId != nil && *input.NotifyRoomId != "" {
 		config.NotifyRoomId = *input.NotifyRoomId
 	}
+	if input.ChannelID != nil {
+		if err := s.validateChannel(input.ChannelID, projectID); err != nil {
+			return nil, err
+		}
+		// channelId 0 switches back to the Chatwork room settings
+		if *input.ChannelID == 0 {
+			config.ChannelID = nil
+		} else {
+			config.ChannelID = input.ChannelID
+		}
+	}
+	if input.EscalationPolicyID != nil {
+		if err := s.validateEscalationPolicy(input.EscalationPolicyID, projectID); err != nil {
+			return nil, err
+		}
+		// escalationPolicyId 0 stops escalating critical findings
+		if *input.EscalationPolicyID == 0 {
+			config.EscalationPolicyID = nil
+		} else {
+			config.EscalationPolicyID = input.EscalationPolicyID
+		}
+	}
 	if input.NotifyOnCritical != nil {
 		config.NotifyOnCritical = *input.NotifyOnCritical
 	}
@@ -250,10 +306,64 @@ func (s *CveConfigService) Update(id string, projectID uint, input *CveConfigUpd
 	if input.NotifyOnLow != nil {
 		config.NotifyOnLow = *input.NotifyOnLow
 	}
+	if input.EmailRecipients != nil {
+		recipients, err := normalizeEmailRecipients(input.EmailRecipients)
+		if err != nil {
+			return nil, err
+		}
+		config.EmailRecipients = recipients
+	}
+	if input.EmailAttachCsv != nil {
+		config.EmailAttachCsv = *input.EmailAttachCsv
+	}
+	if input.EmailDigest != nil {
+		config.EmailDigest = *input.EmailDigest
+	}
 
 	return s.repo.Update(config)
 }
 
+// validateChannel checks that a referenced notification channel belongs to the project.
+func (s *CveConfigService) validateChannel(channelID *uint, projectID uint) error {
+	if channelID == nil || *channelID == 0 {
+		return nil
+	}
+	if _, err := s.notifications.GetByID(*channelID, projectID); err != nil {
+		return errors.New(errors.ErrInvalidData, "notification channel not found")
+	}
+	return nil
+}
+
+// validateEscalationPolicy checks that a referenced escalation policy belongs to the project.
+func (s *CveConfigService) validateEscalationPolicy(policyID *uint, projectID uint) error {
+	if policyID == nil || *policyID == 0 {
+		return nil
+	}
+	if s.escalations == nil {
+		return errors.New(errors.ErrInvalidData, "escalation policies are not available")
+	}
+	if _, err := s.escalations.GetPolicy(*policyID, projectID); err != nil {
+		return errors.New(errors.ErrInvalidData, "escalation policy not found")
+	}
+	return nil
+}
+
+// normalizeEmailRecipients trims the addresses and drops empty ones.
+func normalizeEmailRecipients(input []string) ([]string, error) {
+	recipients := make([]string, 0, len(input))
+	for _, r := range input {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(r); err != nil {
+			return nil, errors.New(errors.ErrInvalidData, fmt.Sprintf("invalid email recipient: %s", r))
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
 func (s *CveConfigService) Delete(id string, projectID uint) error {
 	if err := s.repo.DeleteVulnerabilitiesByConfigID(id); err != nil {
 		logger.Warnf("Failed to delete vulnerabilities: %v", err)
@@ -332,13 +442,16 @@ func (s *CveConfigService) TriggerScan(id string, projectID uint) error {
 	s.logRepo.Update(createdLog)
 
 	s.sendNotifications(config, vulns)
+	s.sendEmailReport(config, createdLog, vulns)
 
 	return nil
 }
 
 func (s *CveConfigService) sendNotifications(config *models.CveConfig, vulns []models.Vulnerability) {
-	if config.NotifyRoomId == "" {
-		logger.Warn("[CVE] Notification skipped: no notifyRoomId")
+	s.escalateCritical(config, vulns)
+
+	if config.ChannelID == nil && config.NotifyRoomId == "" {
+		logger.Warn("[CVE] Notification skipped: no channel or notifyRoomId")
 		return
 	}
 
@@ -348,6 +461,15 @@ func (s *CveConfigService) sendNotifications(config *models.CveConfig, vulns []m
 	}
 
 	filteredVulns := filterVulnerabilitiesBySeverity(vulns, config)
+	message := buildCVEScanMessage(config, filteredVulns)
+
+	if config.ChannelID != nil {
+		logger.Infof("[CVE] Sending scan result of %s to channel %d", config.Name, *config.ChannelID)
+		if err := s.notifications.Notify(*config.ChannelID, message); err != nil {
+			logger.Errorf("[CVE] Failed to send notification: %v", err)
+		}
+		return
+	}
 
 	token := config.ApiKey
 	if config.BotID != nil && token == "" {
@@ -366,14 +488,66 @@ func (s *CveConfigService) sendNotifications(config *models.CveConfig, vulns []m
 		return
 	}
 
-	message := formatCVEMessage(config, filteredVulns)
-	logger.Infof("[CVE] Sending message to room %s: %s", config.NotifyRoomId, message)
+	logger.Infof("[CVE] Sending scan result of %s to room %s", config.Name, config.NotifyRoomId)
 
-	if err := s.chatworkSvc.SendMessage(token, config.NotifyRoomId, message); err != nil {
+	if err := s.notifications.NotifyChatwork(token, config.NotifyRoomId, message); err != nil {
 		logger.Errorf("[CVE] Failed to send notification: %v", err)
 	}
 }
 
+// escalateCritical pages the on-call people of the config's escalation
+// policy when the scan found critical vulnerabilities. A scan repeating while
+// the escalation is still open doesn't page again.
+func (s *CveConfigService) escalateCritical(config *models.CveConfig, vulns []models.Vulnerability) {
+	if config.EscalationPolicyID == nil || s.escalations == nil {
+		return
+	}
+
+	var critical []models.Vulnerability
+	for _, v := range vulns {
+		if strings.EqualFold(v.Severity, "critical") {
+			critical = append(critical, v)
+		}
+	}
+	if len(critical) == 0 {
+		return
+	}
+
+	var text strings.Builder
+	for i, v := range critical {
+		if i == 5 {
+			fmt.Fprintf(&text, "…and %d more", len(critical)-i)
+			break
+		}
+		fmt.Fprintf(&text, "%s in %s %s\n", v.CVEID, v.Package, v.Version)
+	}
+	trigger := &EscalationTrigger{
+		Source:    "cve",
+		SourceRef: "cve-config:" + config.ID,
+		Title:     fmt.Sprintf("%d critical vulnerabilities in %s", len(critical), config.Name),
+		Text:      strings.TrimSpace(text.String()),
+	}
+	if _, err := s.escalations.Trigger(uint(config.ProjectID), *config.EscalationPolicyID, trigger); err != nil {
+		logger.Errorf("[CVE] config_id=%s failed to escalate: %v", config.ID, err)
+	}
+}
+
+// sendEmailReport emails the scan result, using the same success/failure and severity filters as chat notifications.
+func (s *CveConfigService) sendEmailReport(config *models.CveConfig, scanLog *models.CveScanLog, vulns []models.Vulnerability) {
+	if s.reports == nil || len(config.EmailRecipients) == 0 {
+		return
+	}
+	shouldNotify := (len(vulns) > 0 && config.NotifyOnFailure) || (len(vulns) == 0 && config.NotifyOnSuccess)
+	if !shouldNotify {
+		return
+	}
+
+	logger.Infof("[CVE] Emailing scan report of %s to %d recipients", config.Name, len(config.EmailRecipients))
+	if err := s.reports.SendScanReport(config, scanLog, filterVulnerabilitiesBySeverity(vulns, config)); err != nil {
+		logger.Errorf("[CVE] Failed to email scan report: %v", err)
+	}
+}
+
 func filterVulnerabilitiesBySeverity(vulns []models.Vulnerability, config *models.CveConfig) []models.Vulnerability {
 	var filtered []models.Vulnerability
 	for _, v := range vulns {
@@ -402,60 +576,61 @@ func filterVulnerabilitiesBySeverity(vulns []models.Vulnerability, config *model
 	return filtered
 }
 
-func formatCVEMessage(config *models.CveConfig, vulns []models.Vulnerability) string {
-	emoji := "✅"
-	status := "No Vulnerabilities"
-
-	if len(vulns) > 0 {
-		emoji = "🚨"
-		crit, high, moderate, low := 0, 0, 0, 0
-		for _, v := range vulns {
-			switch strings.ToLower(v.Severity) {
-			case "critical":
-				crit++
-			case "high":
-				high++
-			case "moderate":
-				moderate++
-			case "low":
-				low++
-			}
-		}
-		status = fmt.Sprintf("%d Vulns | C:%d H:%d M:%d L:%d", len(vulns), crit, high, moderate, low)
+// buildCVEScanMessage summarises a scan result; each channel renders it in its own format.
+func buildCVEScanMessage(config *models.CveConfig, vulns []models.Vulnerability) notifier.Message {
+	msg := notifier.Message{
+		Title:    "CVE Scan Result",
+		Text:     "No Vulnerabilities",
+		Severity: notifier.SeveritySuccess,
+		Fields:   []notifier.Field{{Name: "Config", Value: config.Name}},
+		Footer:   "🤖 Bot Dashboard Hub",
 	}
 
-	msg := fmt.Sprintf("[info][title]%s CVE Scan Result[/title]", emoji)
-	msg += fmt.Sprintf("\n%s", status)
-	msg += fmt.Sprintf("\n[hr]\nConfig: %s", config.Name)
+	if len(vulns) == 0 {
+		return msg
+	}
 
-	if len(vulns) > 0 {
-		msg += "\n[hr]"
+	crit, high, moderate, low := 0, 0, 0, 0
+	for _, v := range vulns {
+		switch strings.ToLower(v.Severity) {
+		case "critical":
+			crit++
+		case "high":
+			high++
+		case "moderate":
+			moderate++
+		case "low":
+			low++
+		}
+	}
+	msg.Text = fmt.Sprintf("%d Vulns | C:%d H:%d M:%d L:%d", len(vulns), crit, high, moderate, low)
+	msg.Severity = notifier.SeverityWarning
+	msg.Priority = chatwork.PriorityHigh
+	if crit > 0 || high > 0 {
+		msg.Severity = notifier.SeverityCritical
+	}
 
-		packageVulns := make(map[string][]models.Vulnerability)
-		for _, v := range vulns {
-			packageVulns[v.Package] = append(packageVulns[v.Package], v)
+	// Group by package, keeping the order in which packages were found
+	var packages []string
+	packageVulns := make(map[string][]models.Vulnerability)
+	for _, v := range vulns {
+		if _, ok := packageVulns[v.Package]; !ok {
+			packages = append(packages, v.Package)
 		}
+		packageVulns[v.Package] = append(packageVulns[v.Package], v)
+	}
 
-		count := 0
-		for pkg, pkgVulns := range packageVulns {
-			if count >= 10 {
-				msg += fmt.Sprintf("\n... +%d more packages", len(packageVulns)-count)
-				break
-			}
-			if count > 0 {
-				msg += "\n[hr]"
-			}
-			msg += fmt.Sprintf("\n[%d] %s@%s (%d vuln)", count+1, pkg, pkgVulns[0].Version, len(pkgVulns))
-			for _, v := range pkgVulns {
-				if v.ReferenceURL != "" {
-					msg += fmt.Sprintf("\n- %s", v.ReferenceURL)
-				}
+	// Every package is listed; channels split or trim long messages to their own limits
+	for i, pkg := range packages {
+		pkgVulns := packageVulns[pkg]
+		section := notifier.Section{Title: fmt.Sprintf("[%d] %s@%s (%d vuln)", i+1, pkg, pkgVulns[0].Version, len(pkgVulns))}
+		for _, v := range pkgVulns {
+			if v.ReferenceURL != "" {
+				section.Lines = append(section.Lines, v.ReferenceURL)
 			}
-			count++
 		}
+		msg.Sections = append(msg.Sections, section)
 	}
-	msg += "\n[hr]\n🤖 Bot Dashboard Hub"
-	msg += "\n[/info]"
 
 	return msg
 }
@@ -11,7 +11,12 @@ import (
 	"time"
 
 	"github.com/vfa-khuongdv/golang-cms/internal/constants"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/cpe"
 	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
 )
 
 type NVDTime time.Time
@@ -52,11 +57,14 @@ type NVDVulnerability struct {
 }
 
 type NVDCVE struct {
-	ID           string          `json:"id"`
-	Published    NVDTime         `json:"published"`
-	LastModified NVDTime         `json:"lastModified"`
-	Description  []NVDescription `json:"descriptions"`
-	Metrics      NVMetrics       `json:"metrics"`
+	ID             string             `json:"id"`
+	Published      NVDTime            `json:"published"`
+	LastModified   NVDTime            `json:"lastModified"`
+	Description    []NVDescription    `json:"descriptions"`
+	Metrics        NVMetrics          `json:"metrics"`
+	Weaknesses     []NVDWeakness      `json:"weaknesses,omitempty"`
+	Configurations []NVDConfiguration `json:"configurations,omitempty"`
+	References     []NVDReference     `json:"references,omitempty"`
 }
 
 type NVDescription struct {
@@ -71,45 +79,114 @@ type NVMetrics struct {
 }
 
 type CVSSMetric struct {
-	CVSSData CVSSData `json:"cvssData"`
+	Source              string   `json:"source"`
+	CVSSData            CVSSData `json:"cvssData"`
+	ExploitabilityScore float64  `json:"exploitabilityScore"`
+	ImpactScore         float64  `json:"impactScore"`
 }
 
 type CVSSData struct {
+	VectorString string  `json:"vectorString"`
 	BaseScore    float64 `json:"baseScore"`
 	BaseSeverity string  `json:"baseSeverity"`
 }
 
 type CVSSMetricV2 struct {
-	CVSSData CVSSDataV2 `json:"cvssData"`
+	Source              string     `json:"source"`
+	CVSSData            CVSSDataV2 `json:"cvssData"`
+	BaseSeverity        string     `json:"baseSeverity"`
+	ExploitabilityScore float64    `json:"exploitabilityScore"`
+	ImpactScore         float64    `json:"impactScore"`
 }
 
 type CVSSDataV2 struct {
+	VectorString string  `json:"vectorString"`
 	BaseScore    float64 `json:"baseScore"`
 	BaseSeverity string  `json:"baseSeverity"`
 }
 
+type NVDWeakness struct {
+	Source      string          `json:"source"`
+	Type        string          `json:"type"`
+	Description []NVDescription `json:"description"`
+}
+
+type NVDConfiguration struct {
+	Operator string    `json:"operator,omitempty"`
+	Nodes    []NVDNode `json:"nodes"`
+}
+
+type NVDNode struct {
+	Operator string        `json:"operator"`
+	Negate   bool          `json:"negate"`
+	CpeMatch []NVDCpeMatch `json:"cpeMatch"`
+}
+
+type NVDCpeMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	Criteria              string `json:"criteria"`
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty"`
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty"`
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty"`
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty"`
+}
+
+type NVDReference struct {
+	URL    string   `json:"url"`
+	Source string   `json:"source"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
 type ICveCrawlerService interface {
 	CrawlAndNotify()
 }
 
 type CveCrawlerService struct {
-	cw        *ChatworkService
-	roomID    string
-	apiKey    string
-	nvdAPIKey string
-	languages []string
+	cw         *ChatworkService
+	roomID     string
+	apiKey     string
+	nvdAPIKey  string
+	languages  []string
+	recordRepo repositories.ICveRecordRepository
+	matcher    ITechStackService
+	// nvdURL and sleep are replaced in tests
+	nvdURL string
+	sleep  func(time.Duration)
+
+	notifications INotificationService
+	channelID     uint
+	reports       ICveReportService
 }
 
-func NewCveCrawlerService(roomID, apiKey, nvdAPIKey string) *CveCrawlerService {
+// NewCveCrawlerService creates the daily NVD crawler.
+// recordRepo may be nil, in which case crawled CVEs are only sent to Chatwork.
+// matcher may be nil, in which case crawled CVEs are not matched against project tech stacks.
+func NewCveCrawlerService(roomID, apiKey, nvdAPIKey string, recordRepo repositories.ICveRecordRepository, matcher ITechStackService) *CveCrawlerService {
 	return &CveCrawlerService{
-		cw:        NewChatworkService(),
-		roomID:    roomID,
-		apiKey:    apiKey,
-		nvdAPIKey: nvdAPIKey,
-		languages: constants.CVELanguages,
+		cw:         NewChatworkService(),
+		roomID:     roomID,
+		apiKey:     apiKey,
+		nvdAPIKey:  nvdAPIKey,
+		languages:  constants.CVELanguages,
+		recordRepo: recordRepo,
+		matcher:    matcher,
+		nvdURL:     "https://services.nvd.nist.gov/rest/json/cves/2.0",
+		sleep:      time.Sleep,
 	}
 }
 
+// SetNotificationChannel sends crawl reports to a notification channel
+// instead of the configured Chatwork room.
+func (s *CveCrawlerService) SetNotificationChannel(notifications INotificationService, channelID uint) {
+	s.notifications = notifications
+	s.channelID = channelID
+}
+
+// SetReportService emails the daily digest to CVE configs subscribed to it.
+func (s *CveCrawlerService) SetReportService(reports ICveReportService) {
+	s.reports = reports
+}
+
 func (s *CveCrawlerService) CrawlAndNotify() {
 	logger.Info("Starting CVE daily crawl...")
 
@@ -120,42 +197,130 @@ func (s *CveCrawlerService) CrawlAndNotify() {
 	pubStartDate := yesterday.Format(dateFormat)
 	pubEndDate := now.Format(dateFormat)
 
-	allItems, err := s.fetchAllCVEs(pubStartDate, pubEndDate)
+	vulns, err := s.fetchAllCVEs(pubStartDate, pubEndDate)
 	if err != nil {
 		logger.Errorf("[CVE] Error fetching CVEs: %v", err)
-		s.cw.SendMessage(s.apiKey, s.roomID, fmt.Sprintf("[info][title]🚨 CVE ERROR[/title]\nLỗi khi fetch CVE: %v\n[/info]", err))
+		if s.canNotify() {
+			s.deliver(notifier.Message{
+				Title:    "CVE ERROR",
+				Text:     fmt.Sprintf("Lỗi khi fetch CVE: %v", err),
+				Severity: notifier.SeverityCritical,
+				Priority: chatwork.PriorityHigh,
+			})
+		}
+		return
+	}
+
+	records := s.persistRecords(vulns)
+	if s.matcher != nil {
+		s.matcher.MatchRecords(records)
+	}
+	if s.reports != nil {
+		if err := s.reports.SendCrawlerDigest(s.parseCVEs(vulns), now); err != nil {
+			logger.Errorf("[CVE] Failed to email daily digest: %v", err)
+		}
+	}
+
+	if !s.canNotify() {
+		logger.Info("[CVE] No notification channel or Chatwork room configured, skipping crawl notification")
 		return
 	}
 
+	allItems := s.parseCVEs(vulns)
+
 	if len(allItems) == 0 {
 		logger.Info("No new CVEs found in the last 24 hours")
 		return
 	}
 
-	messages := s.formatMessagesByBatch(allItems, now)
-	if len(messages) == 0 {
-		logger.Info("No CVEs to report")
-		return
+	if err := s.deliver(s.buildDailyCveMessage(allItems, now)); err != nil {
+		logger.Errorf("[CVE] Failed to send CVE report: %v", err)
+	} else {
+		logger.Infof("[CVE] Successfully sent CVE report with %d CVEs", len(allItems))
 	}
+}
+
+func (s *CveCrawlerService) canNotify() bool {
+	if s.notifications != nil && s.channelID != 0 {
+		return true
+	}
+	return s.roomID != "" && s.apiKey != ""
+}
+
+// deliver sends a report to the notification channel, or to the Chatwork room.
+func (s *CveCrawlerService) deliver(msg notifier.Message) error {
+	if s.notifications != nil && s.channelID != 0 {
+		return s.notifications.Notify(s.channelID, msg)
+	}
+	// Parts are paced by the token's outbound queue.
+	parts := notifier.RenderChatworkParts(msg)
+	for i, body := range parts {
+		if err := s.cw.SendMessageWithPriority(s.apiKey, s.roomID, body, msg.Priority); err != nil {
+			return fmt.Errorf("part %d/%d: %w", i+1, len(parts), err)
+		}
+	}
+	return nil
+}
+
+// persistRecords stores every crawled CVE so it can be searched locally
+// and returns the records built from the crawl.
+func (s *CveCrawlerService) persistRecords(vulns []NVDVulnerability) []models.CveRecord {
+	records := make([]models.CveRecord, 0, len(vulns))
+	for _, v := range vulns {
+		records = append(records, buildCveRecord(v.CVE))
+	}
+	if s.recordRepo == nil {
+		return records
+	}
+
+	saved := 0
+	for i := range records {
+		if err := s.recordRepo.Upsert(&records[i]); err != nil {
+			logger.Warnf("[CVE] Failed to persist %s: %v", records[i].CveID, err)
+			continue
+		}
+		saved++
+	}
+	logger.Infof("[CVE] Persisted %d/%d crawled CVE records", saved, len(vulns))
+	return records
+}
 
-	for i, message := range messages {
-		if err := s.cw.SendMessage(s.apiKey, s.roomID, message); err != nil {
-			logger.Errorf("[CVE] Failed to send message %d to Chatwork: %v", i+1, err)
+// fetchAllCVEs pages through the NVD API for the given publication window.
+func (s *CveCrawlerService) fetchAllCVEs(pubStartDate, pubEndDate string) ([]NVDVulnerability, error) {
+	var all []NVDVulnerability
+	startIndex := 0
+
+	for {
+		page, err := s.fetchCVEPage(pubStartDate, pubEndDate, startIndex)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Vulnerabilities...)
+
+		startIndex += len(page.Vulnerabilities)
+		if len(page.Vulnerabilities) == 0 || startIndex >= page.TotalResults {
+			break
+		}
+
+		// NVD asks clients to wait between requests to stay under the public rate limit
+		if s.nvdAPIKey == "" {
+			s.sleep(6 * time.Second)
 		} else {
-			logger.Infof("[CVE] Successfully sent CVE report %d/%d to Chatwork", i+1, len(messages))
+			s.sleep(1 * time.Second)
 		}
-		time.Sleep(1 * time.Second)
 	}
+
+	return all, nil
 }
 
-func (s *CveCrawlerService) fetchAllCVEs(pubStartDate, pubEndDate string) ([]CVEItem, error) {
-	baseURL := "https://services.nvd.nist.gov/rest/json/cves/2.0"
+func (s *CveCrawlerService) fetchCVEPage(pubStartDate, pubEndDate string, startIndex int) (*NVDResponse, error) {
 	queryParams := url.Values{}
 	queryParams.Set("pubStartDate", pubStartDate)
 	queryParams.Set("pubEndDate", pubEndDate)
 	queryParams.Set("resultsPerPage", "100")
+	queryParams.Set("startIndex", fmt.Sprintf("%d", startIndex))
 
-	req, err := http.NewRequest("GET", baseURL+"?"+queryParams.Encode(), nil)
+	req, err := http.NewRequest("GET", s.nvdURL+"?"+queryParams.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -182,8 +347,7 @@ func (s *CveCrawlerService) fetchAllCVEs(pubStartDate, pubEndDate string) ([]CVE
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	items := s.parseCVEs(nvdResp.Vulnerabilities)
-	return items, nil
+	return &nvdResp, nil
 }
 
 func (s *CveCrawlerService) parseCVEs(vulns []NVDVulnerability) []CVEItem {
@@ -200,7 +364,7 @@ func (s *CveCrawlerService) parseCVEs(vulns []NVDVulnerability) []CVEItem {
 			severity = v.CVE.Metrics.CvssMetricV30[0].CVSSData.BaseSeverity
 			baseScore = v.CVE.Metrics.CvssMetricV30[0].CVSSData.BaseScore
 		} else if len(v.CVE.Metrics.CvssMetricV2) > 0 {
-			severity = v.CVE.Metrics.CvssMetricV2[0].CVSSData.BaseSeverity
+			severity = v2Severity(v.CVE.Metrics.CvssMetricV2[0])
 			baseScore = v.CVE.Metrics.CvssMetricV2[0].CVSSData.BaseScore
 		}
 
@@ -208,17 +372,7 @@ func (s *CveCrawlerService) parseCVEs(vulns []NVDVulnerability) []CVEItem {
 			continue
 		}
 
-		description := ""
-		for _, desc := range v.CVE.Description {
-			if desc.Lang == "en" {
-				description = desc.Value
-				break
-			}
-		}
-		if description == "" && len(v.CVE.Description) > 0 {
-			description = v.CVE.Description[0].Value
-		}
-
+		description := englishDescription(v.CVE.Description)
 		if len(description) > 300 {
 			description = description[:300] + "..."
 		}
@@ -238,38 +392,122 @@ func (s *CveCrawlerService) parseCVEs(vulns []NVDVulnerability) []CVEItem {
 	return items
 }
 
-type CVEItem struct {
-	ID          string
-	Severity    string
-	BaseScore   float64
-	Description string
-}
+// buildCveRecord maps an NVD CVE entry to the persisted CveRecord model.
+func buildCveRecord(c NVDCVE) models.CveRecord {
+	record := models.CveRecord{
+		CveID:          c.ID,
+		Description:    englishDescription(c.Description),
+		Severity:       "UNKNOWN",
+		PublishedAt:    time.Time(c.Published),
+		LastModifiedAt: time.Time(c.LastModified),
+	}
 
-func (s *CveCrawlerService) formatMessagesByBatch(items []CVEItem, date time.Time) []string {
-	if len(items) == 0 {
-		return nil
+	if len(c.Metrics.CvssMetricV31) > 0 {
+		m := c.Metrics.CvssMetricV31[0]
+		record.Metrics.V31 = &models.CveCvssMetric{Source: m.Source, VectorString: m.CVSSData.VectorString, BaseScore: m.CVSSData.BaseScore, BaseSeverity: m.CVSSData.BaseSeverity, ExploitabilityScore: m.ExploitabilityScore, ImpactScore: m.ImpactScore}
+	}
+	if len(c.Metrics.CvssMetricV30) > 0 {
+		m := c.Metrics.CvssMetricV30[0]
+		record.Metrics.V30 = &models.CveCvssMetric{Source: m.Source, VectorString: m.CVSSData.VectorString, BaseScore: m.CVSSData.BaseScore, BaseSeverity: m.CVSSData.BaseSeverity, ExploitabilityScore: m.ExploitabilityScore, ImpactScore: m.ImpactScore}
+	}
+	if len(c.Metrics.CvssMetricV2) > 0 {
+		m := c.Metrics.CvssMetricV2[0]
+		record.Metrics.V2 = &models.CveCvssMetric{Source: m.Source, VectorString: m.CVSSData.VectorString, BaseScore: m.CVSSData.BaseScore, BaseSeverity: v2Severity(m), ExploitabilityScore: m.ExploitabilityScore, ImpactScore: m.ImpactScore}
+	}
+
+	// Prefer the newest CVSS version for the headline score
+	switch {
+	case record.Metrics.V31 != nil:
+		record.CvssVersion, record.BaseScore, record.Severity, record.VectorString = "3.1", record.Metrics.V31.BaseScore, record.Metrics.V31.BaseSeverity, record.Metrics.V31.VectorString
+	case record.Metrics.V30 != nil:
+		record.CvssVersion, record.BaseScore, record.Severity, record.VectorString = "3.0", record.Metrics.V30.BaseScore, record.Metrics.V30.BaseSeverity, record.Metrics.V30.VectorString
+	case record.Metrics.V2 != nil:
+		record.CvssVersion, record.BaseScore, record.Severity, record.VectorString = "2.0", record.Metrics.V2.BaseScore, record.Metrics.V2.BaseSeverity, record.Metrics.V2.VectorString
 	}
 
-	const batchSize = 20
-	var messages []string
+	seenCwe := make(map[string]bool)
+	for _, w := range c.Weaknesses {
+		for _, d := range w.Description {
+			cweID := strings.ToUpper(strings.TrimSpace(d.Value))
+			if !strings.HasPrefix(cweID, "CWE-") || seenCwe[cweID] {
+				continue
+			}
+			seenCwe[cweID] = true
+			record.Cwes = append(record.Cwes, models.CveRecordCwe{CweID: cweID})
+		}
+	}
 
-	for i := 0; i < len(items); i += batchSize {
-		end := i + batchSize
-		if end > len(items) {
-			end = len(items)
+	for ci, cfg := range c.Configurations {
+		for ni, node := range cfg.Nodes {
+			for _, match := range node.CpeMatch {
+				entry := models.CveRecordCpe{
+					Criteria:              match.Criteria,
+					Vulnerable:            match.Vulnerable,
+					VersionStartIncluding: match.VersionStartIncluding,
+					VersionStartExcluding: match.VersionStartExcluding,
+					VersionEndIncluding:   match.VersionEndIncluding,
+					VersionEndExcluding:   match.VersionEndExcluding,
+					ConfigIndex:           ci,
+					ConfigOperator:        nodeOperator(cfg.Operator),
+					NodeIndex:             ni,
+					NodeOperator:          nodeOperator(node.Operator),
+					NodeNegate:            node.Negate,
+				}
+				if name, err := cpe.Parse(match.Criteria); err == nil {
+					entry.Vendor = strings.ToLower(name.Vendor)
+					entry.Product = strings.ToLower(name.Product)
+					entry.Version = name.Version
+				}
+				record.Cpes = append(record.Cpes, entry)
+			}
 		}
-		batch := items[i:end]
-		messages = append(messages, s.formatMessageByScoreWithPagination(batch, date, i+1, len(items)))
 	}
 
-	return messages
+	for _, ref := range c.References {
+		record.References = append(record.References, models.CveReference{URL: ref.URL, Source: ref.Source, Tags: ref.Tags})
+	}
+
+	return record
 }
 
-func (s *CveCrawlerService) formatMessageByScoreWithPagination(items []CVEItem, date time.Time, startIndex, total int) string {
-	if len(items) == 0 {
-		return ""
+// nodeOperator normalizes an NVD operator; NVD leaves it out for OR.
+func nodeOperator(op string) string {
+	if strings.EqualFold(op, "AND") {
+		return "AND"
 	}
+	return "OR"
+}
 
+func englishDescription(descriptions []NVDescription) string {
+	for _, desc := range descriptions {
+		if desc.Lang == "en" {
+			return desc.Value
+		}
+	}
+	if len(descriptions) > 0 {
+		return descriptions[0].Value
+	}
+	return ""
+}
+
+// v2Severity reads the CVSS v2 severity, which NVD reports next to cvssData.
+func v2Severity(m CVSSMetricV2) string {
+	if m.BaseSeverity != "" {
+		return m.BaseSeverity
+	}
+	return m.CVSSData.BaseSeverity
+}
+
+type CVEItem struct {
+	ID          string
+	Severity    string
+	BaseScore   float64
+	Description string
+}
+
+// buildDailyCveMessage lists every crawled CVE, critical first.
+// Chatwork delivery splits the message into parts when it is too long.
+func (s *CveCrawlerService) buildDailyCveMessage(items []CVEItem, date time.Time) notifier.Message {
 	criticalCount := 0
 	highCount := 0
 
@@ -281,34 +519,30 @@ func (s *CveCrawlerService) formatMessageByScoreWithPagination(items []CVEItem,
 		}
 	}
 
-	var sb strings.Builder
-
-	title := fmt.Sprintf("🚨 DAILY CVE ALERT - %s (Page %d-%d/%d)", date.Format("02/01/2006"), startIndex, startIndex+len(items)-1, total)
-	sb.WriteString(fmt.Sprintf("[info][title]%s[/title]\n", title))
-	sb.WriteString(fmt.Sprintf("📊 Tổng: 🔴 CRITICAL: %d | 🟠 HIGH: %d\n\n", criticalCount, highCount))
-
-	sb.WriteString(fmt.Sprintf("🔴 CRITICAL (%d):\n", criticalCount))
-	for _, item := range items {
-		if item.Severity == "CRITICAL" {
-			sb.WriteString(fmt.Sprintf("• %s - SCORE: %.1f\n", item.ID, item.BaseScore))
-			sb.WriteString(fmt.Sprintf("  %s\n", item.Description))
-			sb.WriteString(fmt.Sprintf("  🔗 https://nvd.nist.gov/vuln/detail/%s\n", item.ID))
-			sb.WriteString("[hr]\n")
-		}
+	msg := notifier.Message{
+		Title:    fmt.Sprintf("DAILY CVE ALERT - %s (%d CVEs)", date.Format("02/01/2006"), len(items)),
+		Text:     fmt.Sprintf("📊 Tổng: 🔴 CRITICAL: %d | 🟠 HIGH: %d", criticalCount, highCount),
+		Severity: notifier.SeverityCritical,
+		Priority: chatwork.PriorityLow, // a digest; project alerts go first
+		Footer:   "📧 Powered by CVE Crawler",
 	}
 
-	sb.WriteString(fmt.Sprintf("🟠 HIGH (%d):\n", highCount))
-	for _, item := range items {
-		if item.Severity == "HIGH" {
-			sb.WriteString(fmt.Sprintf("• %s - SCORE: %.1f\n", item.ID, item.BaseScore))
-			sb.WriteString(fmt.Sprintf("  %s\n", item.Description))
-			sb.WriteString(fmt.Sprintf("  🔗 https://nvd.nist.gov/vuln/detail/%s\n", item.ID))
-			sb.WriteString("[hr]\n")
+	for _, severity := range []string{"CRITICAL", "HIGH"} {
+		for _, item := range items {
+			if item.Severity != severity {
+				continue
+			}
+			icon := "🟠"
+			if severity == "CRITICAL" {
+				icon = "🔴"
+			}
+			msg.Sections = append(msg.Sections, notifier.Section{
+				Title: fmt.Sprintf("%s %s - SCORE: %.1f", icon, item.ID, item.BaseScore),
+				Lines: []string{item.Description},
+				URL:   fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", item.ID),
+			})
 		}
 	}
 
-	sb.WriteString("📧 Powered by CVE Crawler\n")
-	sb.WriteString("[/info]")
-
-	return sb.String()
+	return msg
 }
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFetchAllCVEsPages(t *testing.T) {
+	const total = 250
+	var starts []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("pubStartDate") != "2026-10-18T00:00:00.000" || q.Get("resultsPerPage") != "100" || r.Header.Get("apiKey") != "nvd-key" {
+			http.Error(w, "unexpected request "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		start, _ := strconv.Atoi(q.Get("startIndex"))
+		starts = append(starts, start)
+
+		var vulns []map[string]interface{}
+		for i := start; i < total && i < start+100; i++ {
+			vulns = append(vulns, map[string]interface{}{"cve": map[string]string{"id": fmt.Sprintf("CVE-2026-%04d", i)}})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resultsPerPage":  len(vulns),
+			"startIndex":      start,
+			"totalResults":    total,
+			"vulnerabilities": vulns,
+		})
+	}))
+	defer server.Close()
+
+	s := NewCveCrawlerService("", "", "nvd-key", nil, nil)
+	s.nvdURL = server.URL
+	var waits []time.Duration
+	s.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	vulns, err := s.fetchAllCVEs("2026-10-18T00:00:00.000", "2026-10-18T23:59:59.999")
+	if err != nil {
+		t.Fatalf("fetchAllCVEs: %v", err)
+	}
+	if len(vulns) != total || vulns[total-1].CVE.ID != "CVE-2026-0249" {
+		t.Fatalf("got %d CVEs, want %d", len(vulns), total)
+	}
+	if fmt.Sprint(starts) != "[0 100 200]" {
+		t.Fatalf("startIndex values = %v", starts)
+	}
+	// With an API key NVD allows a request a second
+	if fmt.Sprint(waits) != "[1s 1s]" {
+		t.Fatalf("waits = %v", waits)
+	}
+}
+
+func TestFetchAllCVEsStopsOnEmptyPage(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// A total that overstates the results must not loop forever
+		json.NewEncoder(w).Encode(map[string]interface{}{"totalResults": 500, "vulnerabilities": []interface{}{}})
+	}))
+	defer server.Close()
+
+	s := NewCveCrawlerService("", "", "", nil, nil)
+	s.nvdURL = server.URL
+	s.sleep = func(time.Duration) {}
+
+	vulns, err := s.fetchAllCVEs("a", "b")
+	if err != nil || len(vulns) != 0 || requests != 1 {
+		t.Fatalf("got %d CVEs, %d requests, %v", len(vulns), requests, err)
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusForbidden)
+	})
+	if _, err := s.fetchAllCVEs("a", "b"); err == nil {
+		t.Fatal("expected an NVD error to be returned")
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/mailer"
+)
+
+// maxReportFindings caps the findings listed in the email body; the CSV has all of them.
+const maxReportFindings = 50
+
+var reportSeverities = []string{"CRITICAL", "HIGH", "MODERATE", "LOW"}
+
+type ICveReportService interface {
+	// SendScanReport emails the scan result to the config's recipients.
+	SendScanReport(config *models.CveConfig, scanLog *models.CveScanLog, vulns []models.Vulnerability) error
+	// SendCrawlerDigest emails the daily NVD digest to every config subscribed to it.
+	SendCrawlerDigest(items []CVEItem, date time.Time) error
+}
+
+type CveReportService struct {
+	mail        mailer.EmailSender
+	configRepo  repositories.ICveConfigRepository
+	frontendURL string
+}
+
+// NewCveReportService creates the CVE email reporter.
+// mail may be nil, in which case reports are skipped.
+func NewCveReportService(mail mailer.EmailSender, configRepo repositories.ICveConfigRepository) *CveReportService {
+	return &CveReportService{
+		mail:        mail,
+		configRepo:  configRepo,
+		frontendURL: strings.TrimRight(configs.GetFrontendURL(), "/"),
+	}
+}
+
+type severityCount struct {
+	Name  string
+	Count int
+}
+
+type cveReportData struct {
+	ConfigName    string
+	ScannedAt     string
+	Total         int
+	Severities    []severityCount
+	Findings      []models.Vulnerability
+	MoreCount     int
+	HasAttachment bool
+	ScanLogURL    string
+}
+
+type cveDigestData struct {
+	Date       string
+	Total      int
+	Severities []severityCount
+	Items      []CVEItem
+}
+
+func (s *CveReportService) SendScanReport(config *models.CveConfig, scanLog *models.CveScanLog, vulns []models.Vulnerability) error {
+	if s.mail == nil || len(config.EmailRecipients) == 0 {
+		return nil
+	}
+
+	sorted := append([]models.Vulnerability(nil), vulns...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+
+	scannedAt := time.Now()
+	if scanLog != nil && scanLog.FinishedAt != nil {
+		scannedAt = *scanLog.FinishedAt
+	}
+
+	data := cveReportData{
+		ConfigName:    config.Name,
+		ScannedAt:     scannedAt.UTC().Format("2006-01-02 15:04 UTC"),
+		Total:         len(sorted),
+		Severities:    countSeverities(len(sorted), func(i int) string { return sorted[i].Severity }),
+		Findings:      sorted,
+		HasAttachment: config.EmailAttachCsv && len(sorted) > 0,
+	}
+	if len(sorted) > maxReportFindings {
+		data.Findings = sorted[:maxReportFindings]
+		data.MoreCount = len(sorted) - maxReportFindings
+	}
+	if s.frontendURL != "" {
+		data.ScanLogURL = fmt.Sprintf("%s/projects/%d/cve-configs/%s/logs", s.frontendURL, config.ProjectID, config.ID)
+	}
+
+	text, err := mailer.RenderText("cve_report_template.txt", data)
+	if err != nil {
+		return fmt.Errorf("failed to render CVE report: %w", err)
+	}
+	html, err := mailer.RenderHTML("cve_report_template.html", data)
+	if err != nil {
+		return fmt.Errorf("failed to render CVE report: %w", err)
+	}
+
+	var attachments []mailer.Attachment
+	if data.HasAttachment {
+		csvData, err := buildVulnerabilityCSV(sorted)
+		if err != nil {
+			return fmt.Errorf("failed to build CSV: %w", err)
+		}
+		attachments = append(attachments, mailer.Attachment{
+			Filename:    fmt.Sprintf("cve-report-%s-%s.csv", slugify(config.Name), scannedAt.UTC().Format("20060102")),
+			ContentType: "text/csv",
+			Data:        csvData,
+		})
+	}
+
+	subject := fmt.Sprintf("[CVE] %s: %d vulnerabilities found", config.Name, len(sorted))
+	if len(sorted) == 0 {
+		subject = fmt.Sprintf("[CVE] %s: no vulnerabilities found", config.Name)
+	}
+	return s.mail.Send(config.EmailRecipients, subject, text, html, attachments...)
+}
+
+func (s *CveReportService) SendCrawlerDigest(items []CVEItem, date time.Time) error {
+	if s.mail == nil || s.configRepo == nil || len(items) == 0 {
+		return nil
+	}
+
+	subscribed, err := s.configRepo.GetEmailDigestConfigs()
+	if err != nil {
+		return fmt.Errorf("failed to load digest subscribers: %w", err)
+	}
+	recipients := uniqueRecipients(subscribed)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	data := cveDigestData{
+		Date:       date.Format("2006-01-02"),
+		Total:      len(items),
+		Severities: countSeverities(len(items), func(i int) string { return items[i].Severity }),
+		Items:      items,
+	}
+
+	text, err := mailer.RenderText("cve_digest_template.txt", data)
+	if err != nil {
+		return fmt.Errorf("failed to render CVE digest: %w", err)
+	}
+	html, err := mailer.RenderHTML("cve_digest_template.html", data)
+	if err != nil {
+		return fmt.Errorf("failed to render CVE digest: %w", err)
+	}
+
+	logger.Infof("[CVE] Sending daily digest of %d CVEs to %d recipients", len(items), len(recipients))
+	subject := fmt.Sprintf("[CVE] Daily digest %s: %d new CVEs", data.Date, len(items))
+	return s.mail.Send(recipients, subject, text, html)
+}
+
+// countSeverities counts n items per severity, keeping only severities that occur.
+func countSeverities(n int, severityAt func(i int) string) []severityCount {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		counts[strings.ToUpper(severityAt(i))]++
+	}
+
+	var result []severityCount
+	for _, name := range reportSeverities {
+		if counts[name] > 0 {
+			result = append(result, severityCount{Name: name, Count: counts[name]})
+			delete(counts, name)
+		}
+	}
+	// Unknown severities go last, in a stable order
+	var others []string
+	for name := range counts {
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		result = append(result, severityCount{Name: name, Count: counts[name]})
+	}
+	return result
+}
+
+func buildVulnerabilityCSV(vulns []models.Vulnerability) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"cve_id", "severity", "score", "package", "version", "summary", "reference_url"}); err != nil {
+		return nil, err
+	}
+	for _, v := range vulns {
+		record := []string{v.CVEID, v.Severity, fmt.Sprintf("%.1f", v.Score), v.Package, v.Version, v.Summary, v.ReferenceURL}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func uniqueRecipients(configs []models.CveConfig) []string {
+	seen := make(map[string]bool)
+	var recipients []string
+	for _, c := range configs {
+		for _, r := range c.EmailRecipients {
+			key := strings.ToLower(r)
+			if !seen[key] {
+				seen[key] = true
+				recipients = append(recipients, r)
+			}
+		}
+	}
+	return recipients
+}
+
+func slugify(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			sb.WriteRune(r)
+		case sb.Len() > 0 && !strings.HasSuffix(sb.String(), "-"):
+			sb.WriteByte('-')
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
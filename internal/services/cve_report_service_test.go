@@ -0,0 +1,168 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/pkg/mailer"
+	"github.com/vfa-khuongdv/golang-cms/pkg/mailer/mailertest"
+)
+
+func newTestReportService(t *testing.T) (*CveReportService, *mailertest.Sink) {
+	t.Helper()
+	sink, err := mailertest.NewSink()
+	if err != nil {
+		t.Fatalf("failed to start SMTP sink: %v", err)
+	}
+	t.Cleanup(func() { sink.Close() })
+
+	sender := mailer.NewGomailSender(mailer.GomailSenderConfig{From: "cve@example.com", Host: sink.Host(), Port: sink.Port()})
+	svc := NewCveReportService(sender, nil)
+	svc.frontendURL = "https://dashboard.example.com"
+	return svc, sink
+}
+
+func TestSendScanReportEmailsSummaryAndCSV(t *testing.T) {
+	svc, sink := newTestReportService(t)
+
+	var vulns []models.Vulnerability
+	for i := 0; i < maxReportFindings+5; i++ {
+		vulns = append(vulns, models.Vulnerability{
+			CVEID:    fmt.Sprintf("CVE-2026-%04d", i),
+			Severity: "MODERATE",
+			Score:    5.0,
+			Package:  "lodash",
+			Version:  "4.17.20",
+		})
+	}
+	vulns = append(vulns, models.Vulnerability{CVEID: "CVE-2026-9999", Severity: "CRITICAL", Score: 9.8, Package: "log4j", Version: "2.14.0", Summary: "RCE, \"quoted\""})
+
+	finished := time.Date(2026, 1, 10, 8, 15, 0, 0, time.UTC)
+	config := &models.CveConfig{ID: "cfg-1", ProjectID: 7, Name: "Backend API", EmailRecipients: []string{"sec@example.com"}, EmailAttachCsv: true}
+	if err := svc.SendScanReport(config, &models.CveScanLog{FinishedAt: &finished}, vulns); err != nil {
+		t.Fatalf("SendScanReport returned error: %v", err)
+	}
+
+	messages := sink.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	if len(messages[0].To) != 1 || messages[0].To[0] != "sec@example.com" {
+		t.Fatalf("unexpected recipients: %v", messages[0].To)
+	}
+
+	parts := readMultipart(t, messages[0].Data)
+	text := parts["text/plain"]
+	for _, want := range []string{
+		"CVE Scan Report: Backend API",
+		"56 vulnerabilities found",
+		"CRITICAL   1",
+		"MODERATE   55",
+		"... and 6 more.",
+		"https://dashboard.example.com/projects/7/cve-configs/cfg-1/logs",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text body missing %q:\n%s", want, text)
+		}
+	}
+	// Highest score is listed first
+	if strings.Index(text, "CVE-2026-9999") > strings.Index(text, "CVE-2026-0000") {
+		t.Fatalf("expected findings sorted by score:\n%s", text)
+	}
+	if !strings.Contains(parts["text/html"], "View scan logs") {
+		t.Fatal("HTML body missing scan log link")
+	}
+
+	csvBody := parts["text/csv"]
+	if !strings.HasPrefix(csvBody, "cve_id,severity,score,package,version,summary,reference_url\n") {
+		t.Fatalf("unexpected CSV header:\n%s", csvBody)
+	}
+	if !strings.Contains(csvBody, `CVE-2026-9999,CRITICAL,9.8,log4j,2.14.0,"RCE, ""quoted""",`) {
+		t.Fatalf("CSV missing escaped row:\n%s", csvBody)
+	}
+	if got := strings.Count(csvBody, "\n"); got != len(vulns)+1 {
+		t.Fatalf("expected %d CSV lines, got %d", len(vulns)+1, got)
+	}
+}
+
+func TestSendScanReportSkipsWithoutRecipients(t *testing.T) {
+	svc, sink := newTestReportService(t)
+
+	if err := svc.SendScanReport(&models.CveConfig{Name: "No email"}, nil, nil); err != nil {
+		t.Fatalf("SendScanReport returned error: %v", err)
+	}
+	if len(sink.Messages()) != 0 {
+		t.Fatal("expected no email without recipients")
+	}
+}
+
+func TestUniqueRecipientsMergesConfigs(t *testing.T) {
+	got := uniqueRecipients([]models.CveConfig{
+		{EmailRecipients: []string{"a@example.com", "b@example.com"}},
+		{EmailRecipients: []string{"A@example.com", "c@example.com"}},
+	})
+	want := []string{"a@example.com", "b@example.com", "c@example.com"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+// readMultipart decodes every leaf part of a MIME message, keyed by content type.
+func readMultipart(t *testing.T, raw string) map[string]string {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	parts := make(map[string]string)
+	collectParts(t, msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body, parts)
+	return parts
+}
+
+func collectParts(t *testing.T, contentType, encoding string, body io.Reader, parts map[string]string) {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("invalid content type %q: %v", contentType, err)
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := r.NextRawPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to read part: %v", err)
+			}
+			collectParts(t, p.Header.Get("Content-Type"), p.Header.Get("Content-Transfer-Encoding"), p, parts)
+		}
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	switch strings.ToLower(encoding) {
+	case "base64":
+		data, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+		if err != nil {
+			t.Fatalf("failed to decode base64: %v", err)
+		}
+	case "quoted-printable":
+		data, err = io.ReadAll(quotedprintable.NewReader(strings.NewReader(string(data))))
+		if err != nil {
+			t.Fatalf("failed to decode quoted-printable: %v", err)
+		}
+	}
+	parts[mediaType] = strings.ReplaceAll(string(data), "\r\n", "\n")
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+)
+
+const cveFacetLimit = 20
+
+type ICveSearchService interface {
+	Search(filter *repositories.CveSearchFilter, paging *utils.Paging) (*CveSearchResult, error)
+	GetByCveID(cveID string) (*models.CveRecord, error)
+}
+
+// CveSearchResult is one page of locally stored CVEs plus facet counts.
+type CveSearchResult struct {
+	Records []models.CveRecord
+	Total   int64
+	Facets  *models.CveSearchFacets
+}
+
+type CveSearchService struct {
+	repo repositories.ICveRecordRepository
+}
+
+func NewCveSearchService(repo repositories.ICveRecordRepository) *CveSearchService {
+	return &CveSearchService{repo: repo}
+}
+
+// Search returns crawled CVEs matching the filter, newest first.
+func (s *CveSearchService) Search(filter *repositories.CveSearchFilter, paging *utils.Paging) (*CveSearchResult, error) {
+	records, total, err := s.repo.Search(filter, paging)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := s.repo.GetFacets(filter, cveFacetLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CveSearchResult{
+		Records: records,
+		Total:   total,
+		Facets:  facets,
+	}, nil
+}
+
+func (s *CveSearchService) GetByCveID(cveID string) (*models.CveRecord, error) {
+	return s.repo.GetByCveID(cveID)
+}
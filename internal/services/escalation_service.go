@@ -0,0 +1,709 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+)
+
+// maxEscalationTimeout is the longest wait for an acknowledgement, in minutes.
+const maxEscalationTimeout = 24 * 60
+
+// IEscalationService manages on-call rotations and escalation policies, and
+// runs escalations: each level mentions its on-call people with [To:], and
+// the next level is notified when nobody acknowledges in time.
+type IEscalationService interface {
+	GetRotations(projectID uint) ([]models.OnCallRotation, error)
+	// GetRotation returns the rotation with its current and future overrides.
+	GetRotation(id, projectID uint) (*models.OnCallRotation, error)
+	CreateRotation(projectID uint, input *OnCallRotationInput) (*models.OnCallRotation, error)
+	UpdateRotation(id, projectID uint, input *OnCallRotationInput) (*models.OnCallRotation, error)
+	// DeleteRotation refuses rotations that a policy still uses.
+	DeleteRotation(id, projectID uint) error
+	// OnCall returns who is on call in the rotation at a time, overrides included.
+	OnCall(id, projectID uint, at time.Time) (*models.OnCallShift, error)
+	CreateOverride(rotationID, projectID uint, input *OnCallOverrideInput) (*models.OnCallOverride, error)
+	DeleteOverride(rotationID, overrideID, projectID uint) error
+
+	GetPolicies(projectID uint) ([]models.EscalationPolicy, error)
+	GetPolicy(id, projectID uint) (*models.EscalationPolicy, error)
+	CreatePolicy(projectID uint, input *EscalationPolicyInput) (*models.EscalationPolicy, error)
+	UpdatePolicy(id, projectID uint, input *EscalationPolicyInput) (*models.EscalationPolicy, error)
+	DeletePolicy(id, projectID uint) error
+
+	// Trigger starts an escalation with the policy and notifies its first
+	// level. While one is open for the same SourceRef, that one is returned
+	// instead of starting another.
+	Trigger(projectID, policyID uint, trigger *EscalationTrigger) (*models.Escalation, error)
+	GetEscalations(projectID uint, status string, paging *utils.Paging) ([]models.Escalation, int64, error)
+	GetEscalation(id, projectID uint) (*models.Escalation, error)
+	// GetEscalationByMessage finds the escalation that posted messageID, for
+	// acknowledging by replying to it. It returns nil when there is none.
+	GetEscalationByMessage(roomID, messageID string) (*models.Escalation, error)
+	// Acknowledge stops the escalation and notes who took it under its latest
+	// message. Acknowledging twice keeps the first acknowledgement.
+	Acknowledge(id, projectID uint, by string) (*models.Escalation, error)
+	// EscalateDue notifies the next level of every escalation whose timeout
+	// passed. Run every minute by the cron job.
+	EscalateDue() error
+}
+
+// OnCallRotationInput holds the writable fields of a rotation. Nil fields are
+// left unchanged on update.
+type OnCallRotationInput struct {
+	Name         *string
+	Handoff      *string
+	StartsAt     *time.Time
+	Timezone     *string
+	Participants *[]models.OnCallParticipant
+}
+
+type OnCallOverrideInput struct {
+	AccountID int64
+	Name      string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Reason    string
+}
+
+// EscalationPolicyInput holds the writable fields of a policy. Nil fields are
+// left unchanged on update.
+type EscalationPolicyInput struct {
+	Name   *string
+	BotID  *uint
+	RoomID *string
+	Levels *[]models.EscalationLevel
+}
+
+// EscalationTrigger describes what needs attention.
+type EscalationTrigger struct {
+	Source    string
+	SourceRef string
+	Title     string
+	Text      string
+}
+
+type EscalationService struct {
+	repo    repositories.IEscalationRepository
+	botRepo repositories.IChatworkBotRepository
+	baseURL string
+	now     func() time.Time
+}
+
+func NewEscalationService(repo repositories.IEscalationRepository, botRepo repositories.IChatworkBotRepository) *EscalationService {
+	return &EscalationService{
+		repo:    repo,
+		botRepo: botRepo,
+		baseURL: chatworkBaseURL(),
+		now:     time.Now,
+	}
+}
+
+func (s *EscalationService) GetRotations(projectID uint) ([]models.OnCallRotation, error) {
+	rotations, err := s.repo.GetRotations(projectID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return rotations, nil
+}
+
+func (s *EscalationService) GetRotation(id, projectID uint) (*models.OnCallRotation, error) {
+	rotation, err := s.repo.GetRotation(id, s.now())
+	if err != nil || rotation.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "rotation not found")
+	}
+	return rotation, nil
+}
+
+func (s *EscalationService) CreateRotation(projectID uint, input *OnCallRotationInput) (*models.OnCallRotation, error) {
+	rotation := &models.OnCallRotation{ProjectID: projectID, Handoff: models.HandoffWeekly}
+	applyRotationInput(rotation, input)
+	if err := validateRotation(rotation); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveRotation(rotation); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return rotation, nil
+}
+
+func (s *EscalationService) UpdateRotation(id, projectID uint, input *OnCallRotationInput) (*models.OnCallRotation, error) {
+	rotation, err := s.GetRotation(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	applyRotationInput(rotation, input)
+	if err := validateRotation(rotation); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveRotation(rotation); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return rotation, nil
+}
+
+func (s *EscalationService) DeleteRotation(id, projectID uint) error {
+	if _, err := s.GetRotation(id, projectID); err != nil {
+		return err
+	}
+	policies, err := s.repo.GetPolicies(projectID)
+	if err != nil {
+		return errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	for _, policy := range policies {
+		for _, level := range policy.Levels {
+			if level.RotationID != nil && *level.RotationID == id {
+				return errors.New(errors.ErrInvalidData, fmt.Sprintf("rotation is used by escalation policy %q", policy.Name))
+			}
+		}
+	}
+	if err := s.repo.DeleteRotation(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *EscalationService) OnCall(id, projectID uint, at time.Time) (*models.OnCallShift, error) {
+	rotation, err := s.repo.GetRotation(id, at)
+	if err != nil || rotation.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "rotation not found")
+	}
+	return onCallAt(rotation, at), nil
+}
+
+func (s *EscalationService) CreateOverride(rotationID, projectID uint, input *OnCallOverrideInput) (*models.OnCallOverride, error) {
+	if _, err := s.GetRotation(rotationID, projectID); err != nil {
+		return nil, err
+	}
+	if input.AccountID <= 0 {
+		return nil, errors.New(errors.ErrInvalidData, "accountId is required")
+	}
+	if !input.EndsAt.After(input.StartsAt) || !input.EndsAt.After(s.now()) {
+		return nil, errors.New(errors.ErrInvalidData, "endsAt must be in the future and after startsAt")
+	}
+
+	override := &models.OnCallOverride{
+		RotationID: rotationID,
+		AccountID:  input.AccountID,
+		Name:       strings.TrimSpace(input.Name),
+		StartsAt:   input.StartsAt,
+		EndsAt:     input.EndsAt,
+		Reason:     strings.TrimSpace(input.Reason),
+	}
+	if err := s.repo.CreateOverride(override); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return override, nil
+}
+
+func (s *EscalationService) DeleteOverride(rotationID, overrideID, projectID uint) error {
+	if _, err := s.GetRotation(rotationID, projectID); err != nil {
+		return err
+	}
+	override, err := s.repo.GetOverride(overrideID)
+	if err != nil || override.RotationID != rotationID {
+		return errors.New(errors.ErrResourceNotFound, "override not found")
+	}
+	if err := s.repo.DeleteOverride(overrideID); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *EscalationService) GetPolicies(projectID uint) ([]models.EscalationPolicy, error) {
+	policies, err := s.repo.GetPolicies(projectID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return policies, nil
+}
+
+func (s *EscalationService) GetPolicy(id, projectID uint) (*models.EscalationPolicy, error) {
+	policy, err := s.repo.GetPolicy(id)
+	if err != nil || policy.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "escalation policy not found")
+	}
+	return policy, nil
+}
+
+func (s *EscalationService) CreatePolicy(projectID uint, input *EscalationPolicyInput) (*models.EscalationPolicy, error) {
+	policy := &models.EscalationPolicy{ProjectID: projectID}
+	applyPolicyInput(policy, input)
+	if err := s.validatePolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePolicy(policy); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return policy, nil
+}
+
+func (s *EscalationService) UpdatePolicy(id, projectID uint, input *EscalationPolicyInput) (*models.EscalationPolicy, error) {
+	policy, err := s.GetPolicy(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	applyPolicyInput(policy, input)
+	if err := s.validatePolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePolicy(policy); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return policy, nil
+}
+
+func (s *EscalationService) DeletePolicy(id, projectID uint) error {
+	if _, err := s.GetPolicy(id, projectID); err != nil {
+		return err
+	}
+	if err := s.repo.DeletePolicy(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *EscalationService) Trigger(projectID, policyID uint, trigger *EscalationTrigger) (*models.Escalation, error) {
+	policy, err := s.GetPolicy(policyID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(trigger.Title) == "" {
+		return nil, errors.New(errors.ErrInvalidData, "title is required")
+	}
+	if trigger.SourceRef != "" {
+		open, err := s.repo.FindOpen(projectID, trigger.SourceRef)
+		if err != nil {
+			return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+		}
+		if open != nil {
+			return open, nil
+		}
+	}
+
+	source := trigger.Source
+	if source == "" {
+		source = "manual"
+	}
+	escalation := &models.Escalation{
+		ProjectID: projectID,
+		PolicyID:  policy.ID,
+		Source:    source,
+		SourceRef: trigger.SourceRef,
+		Title:     strings.TrimSpace(trigger.Title),
+		Text:      strings.TrimSpace(trigger.Text),
+		Status:    models.EscalationOpen,
+	}
+	if err := s.repo.SaveEscalation(escalation); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	if err := s.notifyLevel(policy, escalation, 0); err != nil {
+		return nil, err
+	}
+	return escalation, nil
+}
+
+func (s *EscalationService) GetEscalations(projectID uint, status string, paging *utils.Paging) ([]models.Escalation, int64, error) {
+	switch status {
+	case "", models.EscalationOpen, models.EscalationAcknowledged, models.EscalationExhausted:
+	default:
+		return nil, 0, errors.New(errors.ErrInvalidData, "status must be open, acknowledged or exhausted")
+	}
+	escalations, total, err := s.repo.ListEscalations(projectID, status, paging)
+	if err != nil {
+		return nil, 0, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return escalations, total, nil
+}
+
+func (s *EscalationService) GetEscalation(id, projectID uint) (*models.Escalation, error) {
+	escalation, err := s.repo.GetEscalation(id)
+	if err != nil || escalation.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "escalation not found")
+	}
+	return escalation, nil
+}
+
+func (s *EscalationService) GetEscalationByMessage(roomID, messageID string) (*models.Escalation, error) {
+	escalation, err := s.repo.GetEscalationByMessage(roomID, messageID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return escalation, nil
+}
+
+func (s *EscalationService) Acknowledge(id, projectID uint, by string) (*models.Escalation, error) {
+	escalation, err := s.GetEscalation(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if escalation.AckedAt != nil {
+		return escalation, nil
+	}
+
+	now := s.now()
+	escalation.Status = models.EscalationAcknowledged
+	escalation.AckedBy = strings.TrimSpace(by)
+	escalation.AckedAt = &now
+	escalation.NextAt = nil
+	if err := s.repo.SaveEscalation(escalation); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+
+	// The note under the latest message is best effort.
+	if err := s.noteAck(escalation); err != nil {
+		logger.Warnf("[Escalation] escalation_id=%d failed to post the acknowledgement: %v", escalation.ID, err)
+	}
+	return escalation, nil
+}
+
+func (s *EscalationService) noteAck(escalation *models.Escalation) error {
+	var last *models.EscalationNotification
+	for i := range escalation.Notifications {
+		if escalation.Notifications[i].MessageID != "" {
+			last = &escalation.Notifications[i]
+		}
+	}
+	if last == nil {
+		return nil
+	}
+	policy, err := s.repo.GetPolicy(escalation.PolicyID)
+	if err != nil {
+		return fmt.Errorf("escalation policy %d not found", escalation.PolicyID)
+	}
+	bot, err := s.botRepo.GetByID(policy.BotID)
+	if err != nil {
+		return fmt.Errorf("bot %d not found", policy.BotID)
+	}
+
+	message := chatwork.NewBuilder()
+	if roomID, err := strconv.ParseInt(last.RoomID, 10, 64); err == nil {
+		message.Reply(bot.AccountID, roomID, last.MessageID)
+	}
+	message.Textf("✅ Escalation #%d (%s) acknowledged by %s", escalation.ID, escalation.Title, escalation.AckedBy)
+	_, err = postBotMessage(s.baseURL, bot, last.RoomID, message)
+	return err
+}
+
+func (s *EscalationService) EscalateDue() error {
+	now := s.now()
+	due, err := s.repo.ListDue(now)
+	if err != nil {
+		return errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+
+	for i := range due {
+		escalation := &due[i]
+		policy, err := s.repo.GetPolicy(escalation.PolicyID)
+		if err != nil {
+			// The policy was deleted; nobody is left to notify.
+			logger.Warnf("[Escalation] escalation_id=%d policy %d not found, closing", escalation.ID, escalation.PolicyID)
+			escalation.Status = models.EscalationExhausted
+			escalation.NextAt = nil
+			if err := s.repo.SaveEscalation(escalation); err != nil {
+				logger.Errorf("[Escalation] escalation_id=%d failed to close: %v", escalation.ID, err)
+			}
+			continue
+		}
+
+		next := escalation.Level + 1
+		if next >= len(policy.Levels) {
+			s.exhaust(policy, escalation)
+			continue
+		}
+		if err := s.notifyLevel(policy, escalation, next); err != nil {
+			logger.Errorf("[Escalation] escalation_id=%d failed to escalate: %v", escalation.ID, err)
+		}
+	}
+	return nil
+}
+
+// notifyLevel posts the escalation to a level's room, mentioning its people,
+// and schedules the next level. A failed post is recorded on the
+// notification and doesn't stop the escalation.
+func (s *EscalationService) notifyLevel(policy *models.EscalationPolicy, escalation *models.Escalation, level int) error {
+	now := s.now()
+	spec := policy.Levels[level]
+	room := spec.RoomID
+	if room == "" {
+		room = policy.RoomID
+	}
+
+	targets := s.levelTargets(escalation.ProjectID, spec, now)
+	title := "🚨 " + escalation.Title
+	if level > 0 {
+		title = "⏫ Escalated: " + escalation.Title
+	}
+	message := chatwork.NewBuilder()
+	for _, target := range targets {
+		message.To(target.AccountID, target.Name)
+	}
+	message.Info(title, func(b *chatwork.Builder) {
+		if escalation.Text != "" {
+			b.Text(escalation.Text)
+		}
+		if len(targets) == 0 {
+			b.Text("Nobody is on call for this level.")
+		}
+		b.Textf("Escalation #%d · level %d of %d · reply /ack within %d minutes", escalation.ID, level+1, len(policy.Levels), spec.TimeoutMinutes)
+	})
+
+	notification := &models.EscalationNotification{
+		EscalationID: escalation.ID,
+		Level:        level,
+		RoomID:       room,
+		AccountIDs:   []int64{},
+	}
+	for _, target := range targets {
+		notification.AccountIDs = append(notification.AccountIDs, target.AccountID)
+	}
+	bot, err := s.botRepo.GetByID(policy.BotID)
+	if err != nil {
+		notification.Error = fmt.Sprintf("bot %d not found", policy.BotID)
+	} else if messageID, err := postBotMessage(s.baseURL, bot, room, message); err != nil {
+		notification.Error = err.Error()
+	} else {
+		notification.MessageID = messageID
+	}
+	if notification.Error != "" {
+		logger.Errorf("[Escalation] escalation_id=%d level=%d failed to notify: %s", escalation.ID, level+1, notification.Error)
+	}
+	if err := s.repo.CreateNotification(notification); err != nil {
+		logger.Errorf("[Escalation] escalation_id=%d failed to record the notification: %v", escalation.ID, err)
+	}
+	escalation.Notifications = append(escalation.Notifications, *notification)
+
+	nextAt := now.Add(time.Duration(spec.TimeoutMinutes) * time.Minute)
+	escalation.Level = level
+	escalation.NextAt = &nextAt
+	if err := s.repo.SaveEscalation(escalation); err != nil {
+		return errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return nil
+}
+
+// exhaust closes an escalation whose last level timed out and says so in
+// that level's room.
+func (s *EscalationService) exhaust(policy *models.EscalationPolicy, escalation *models.Escalation) {
+	escalation.Status = models.EscalationExhausted
+	escalation.NextAt = nil
+	if err := s.repo.SaveEscalation(escalation); err != nil {
+		logger.Errorf("[Escalation] escalation_id=%d failed to close: %v", escalation.ID, err)
+		return
+	}
+
+	room := policy.RoomID
+	if escalation.Level < len(policy.Levels) && policy.Levels[escalation.Level].RoomID != "" {
+		room = policy.Levels[escalation.Level].RoomID
+	}
+	bot, err := s.botRepo.GetByID(policy.BotID)
+	if err != nil {
+		logger.Errorf("[Escalation] escalation_id=%d bot %d not found", escalation.ID, policy.BotID)
+		return
+	}
+	message := chatwork.NewBuilder().Textf("⚠️ Nobody acknowledged escalation #%d (%s); every level of %s was notified.", escalation.ID, escalation.Title, policy.Name)
+	if _, err := postBotMessage(s.baseURL, bot, room, message); err != nil {
+		logger.Errorf("[Escalation] escalation_id=%d failed to post: %v", escalation.ID, err)
+	}
+}
+
+// levelTargets is who a level mentions: the rotation's on-call person, then
+// the listed accounts, without duplicates.
+func (s *EscalationService) levelTargets(projectID uint, level models.EscalationLevel, at time.Time) []models.OnCallParticipant {
+	var targets []models.OnCallParticipant
+	seen := make(map[int64]bool)
+	add := func(p models.OnCallParticipant) {
+		if p.AccountID > 0 && !seen[p.AccountID] {
+			seen[p.AccountID] = true
+			targets = append(targets, p)
+		}
+	}
+	if level.RotationID != nil {
+		rotation, err := s.repo.GetRotation(*level.RotationID, at)
+		if err != nil || rotation.ProjectID != projectID {
+			logger.Warnf("[Escalation] rotation %d not found", *level.RotationID)
+		} else if shift := onCallAt(rotation, at); shift != nil {
+			add(models.OnCallParticipant{AccountID: shift.AccountID, Name: shift.Name})
+		}
+	}
+	for _, id := range level.AccountIDs {
+		add(models.OnCallParticipant{AccountID: id})
+	}
+	return targets
+}
+
+// onCallAt works out the shift covering t: the newest override in effect, or
+// else the participant whose turn it is. Handoffs happen at the local time of
+// day of StartsAt, so they don't drift across DST changes.
+func onCallAt(rotation *models.OnCallRotation, t time.Time) *models.OnCallShift {
+	var override *models.OnCallOverride
+	for i := range rotation.Overrides {
+		o := &rotation.Overrides[i]
+		if !t.Before(o.StartsAt) && t.Before(o.EndsAt) && (override == nil || o.ID > override.ID) {
+			override = o
+		}
+	}
+	if override != nil {
+		id := override.ID
+		return &models.OnCallShift{AccountID: override.AccountID, Name: override.Name, StartsAt: override.StartsAt, EndsAt: override.EndsAt, OverrideID: &id}
+	}
+	if len(rotation.Participants) == 0 {
+		return nil
+	}
+
+	loc := rotationLocation(rotation)
+	anchor := rotation.StartsAt.In(loc)
+	local := t.In(loc)
+	days := civilDays(anchor, local)
+	if clock(local) < clock(anchor) {
+		days--
+	}
+	period := 1
+	if rotation.Handoff == models.HandoffWeekly {
+		period = 7
+	}
+	index := floorDiv(days, period)
+
+	start := time.Date(anchor.Year(), anchor.Month(), anchor.Day()+index*period, anchor.Hour(), anchor.Minute(), anchor.Second(), 0, loc)
+	end := time.Date(anchor.Year(), anchor.Month(), anchor.Day()+(index+1)*period, anchor.Hour(), anchor.Minute(), anchor.Second(), 0, loc)
+	p := rotation.Participants[((index%len(rotation.Participants))+len(rotation.Participants))%len(rotation.Participants)]
+	return &models.OnCallShift{AccountID: p.AccountID, Name: p.Name, StartsAt: start, EndsAt: end}
+}
+
+func rotationLocation(rotation *models.OnCallRotation) *time.Location {
+	if rotation.Timezone != "" {
+		if loc, err := time.LoadLocation(rotation.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// civilDays counts the calendar days from a's date to b's date.
+func civilDays(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// clock is the time of day in seconds.
+func clock(t time.Time) int {
+	return t.Hour()*3600 + t.Minute()*60 + t.Second()
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func applyRotationInput(rotation *models.OnCallRotation, input *OnCallRotationInput) {
+	if input.Name != nil {
+		rotation.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Handoff != nil {
+		rotation.Handoff = strings.ToLower(strings.TrimSpace(*input.Handoff))
+	}
+	if input.StartsAt != nil {
+		rotation.StartsAt = *input.StartsAt
+	}
+	if input.Timezone != nil {
+		rotation.Timezone = strings.TrimSpace(*input.Timezone)
+	}
+	if input.Participants != nil {
+		rotation.Participants = *input.Participants
+	}
+}
+
+func validateRotation(rotation *models.OnCallRotation) error {
+	if rotation.Name == "" {
+		return errors.New(errors.ErrInvalidData, "name is required")
+	}
+	if rotation.Handoff != models.HandoffDaily && rotation.Handoff != models.HandoffWeekly {
+		return errors.New(errors.ErrInvalidData, "handoff must be daily or weekly")
+	}
+	if rotation.StartsAt.IsZero() {
+		return errors.New(errors.ErrInvalidData, "startsAt is required")
+	}
+	if rotation.Timezone != "" {
+		if _, err := time.LoadLocation(rotation.Timezone); err != nil {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("unknown timezone %q", rotation.Timezone))
+		}
+	}
+	if len(rotation.Participants) == 0 {
+		return errors.New(errors.ErrInvalidData, "participants is required")
+	}
+	for i, p := range rotation.Participants {
+		if p.AccountID <= 0 {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("participants[%d]: accountId is required", i))
+		}
+	}
+	return nil
+}
+
+func applyPolicyInput(policy *models.EscalationPolicy, input *EscalationPolicyInput) {
+	if input.Name != nil {
+		policy.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.BotID != nil {
+		policy.BotID = *input.BotID
+	}
+	if input.RoomID != nil {
+		policy.RoomID = strings.TrimSpace(*input.RoomID)
+	}
+	if input.Levels != nil {
+		policy.Levels = *input.Levels
+	}
+}
+
+func (s *EscalationService) validatePolicy(policy *models.EscalationPolicy) error {
+	if policy.Name == "" {
+		return errors.New(errors.ErrInvalidData, "name is required")
+	}
+	if _, err := strconv.ParseInt(policy.RoomID, 10, 64); err != nil {
+		return errors.New(errors.ErrInvalidData, "roomId must be a Chatwork room id")
+	}
+	if policy.BotID == 0 {
+		return errors.New(errors.ErrInvalidData, "botId is required")
+	}
+	if _, err := s.botRepo.GetByID(policy.BotID); err != nil {
+		return errors.New(errors.ErrInvalidData, fmt.Sprintf("bot %d not found", policy.BotID))
+	}
+	if len(policy.Levels) == 0 {
+		return errors.New(errors.ErrInvalidData, "levels is required")
+	}
+	for i := range policy.Levels {
+		level := &policy.Levels[i]
+		level.RoomID = strings.TrimSpace(level.RoomID)
+		level.AccountIDs = slices.DeleteFunc(level.AccountIDs, func(id int64) bool { return id <= 0 })
+		if level.RotationID == nil && len(level.AccountIDs) == 0 {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("levels[%d]: rotationId or accountIds is required", i))
+		}
+		if level.RotationID != nil {
+			rotation, err := s.repo.GetRotation(*level.RotationID, s.now())
+			if err != nil || rotation.ProjectID != policy.ProjectID {
+				return errors.New(errors.ErrInvalidData, fmt.Sprintf("levels[%d]: rotation %d not found", i, *level.RotationID))
+			}
+		}
+		if level.RoomID != "" {
+			if _, err := strconv.ParseInt(level.RoomID, 10, 64); err != nil {
+				return errors.New(errors.ErrInvalidData, fmt.Sprintf("levels[%d]: roomId must be a Chatwork room id", i))
+			}
+		}
+		if level.TimeoutMinutes < 1 || level.TimeoutMinutes > maxEscalationTimeout {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("levels[%d]: timeoutMinutes must be between 1 and %d", i, maxEscalationTimeout))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+	"gorm.io/gorm"
+)
+
+type fakeEscalationRepo struct {
+	repositories.IEscalationRepository
+	rotations     map[uint]*models.OnCallRotation
+	overrides     []*models.OnCallOverride
+	policies      map[uint]*models.EscalationPolicy
+	escalations   []*models.Escalation
+	notifications []*models.EscalationNotification
+}
+
+func (r *fakeEscalationRepo) GetRotations(projectID uint) ([]models.OnCallRotation, error) {
+	var rotations []models.OnCallRotation
+	for id := uint(1); id <= uint(len(r.rotations)); id++ {
+		if rotation, ok := r.rotations[id]; ok && rotation.ProjectID == projectID {
+			rotations = append(rotations, *rotation)
+		}
+	}
+	return rotations, nil
+}
+
+func (r *fakeEscalationRepo) GetRotation(id uint, since time.Time) (*models.OnCallRotation, error) {
+	rotation, ok := r.rotations[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *rotation
+	copied.Overrides = nil
+	for _, o := range r.overrides {
+		if o.RotationID == id && o.EndsAt.After(since) {
+			copied.Overrides = append(copied.Overrides, *o)
+		}
+	}
+	return &copied, nil
+}
+
+func (r *fakeEscalationRepo) SaveRotation(rotation *models.OnCallRotation) error {
+	if rotation.ID == 0 {
+		rotation.ID = uint(len(r.rotations) + 1)
+	}
+	r.rotations[rotation.ID] = rotation
+	return nil
+}
+
+func (r *fakeEscalationRepo) CreateOverride(override *models.OnCallOverride) error {
+	override.ID = uint(len(r.overrides) + 1)
+	r.overrides = append(r.overrides, override)
+	return nil
+}
+
+func (r *fakeEscalationRepo) GetPolicies(projectID uint) ([]models.EscalationPolicy, error) {
+	var policies []models.EscalationPolicy
+	for _, policy := range r.policies {
+		if policy.ProjectID == projectID {
+			policies = append(policies, *policy)
+		}
+	}
+	return policies, nil
+}
+
+func (r *fakeEscalationRepo) GetPolicy(id uint) (*models.EscalationPolicy, error) {
+	policy, ok := r.policies[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *policy
+	return &copied, nil
+}
+
+func (r *fakeEscalationRepo) SavePolicy(policy *models.EscalationPolicy) error {
+	if policy.ID == 0 {
+		policy.ID = uint(len(r.policies) + 1)
+	}
+	r.policies[policy.ID] = policy
+	return nil
+}
+
+func (r *fakeEscalationRepo) FindOpen(projectID uint, sourceRef string) (*models.Escalation, error) {
+	for _, e := range r.escalations {
+		if e.ProjectID == projectID && e.SourceRef == sourceRef && e.Status == models.EscalationOpen {
+			return r.GetEscalation(e.ID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeEscalationRepo) GetEscalation(id uint) (*models.Escalation, error) {
+	for _, e := range r.escalations {
+		if e.ID == id {
+			copied := *e
+			copied.Notifications = nil
+			for _, n := range r.notifications {
+				if n.EscalationID == id {
+					copied.Notifications = append(copied.Notifications, *n)
+				}
+			}
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeEscalationRepo) GetEscalationByMessage(roomID, messageID string) (*models.Escalation, error) {
+	for _, n := range r.notifications {
+		if n.RoomID == roomID && n.MessageID == messageID {
+			return r.GetEscalation(n.EscalationID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeEscalationRepo) ListEscalations(projectID uint, status string, paging *utils.Paging) ([]models.Escalation, int64, error) {
+	var escalations []models.Escalation
+	for _, e := range r.escalations {
+		if e.ProjectID == projectID && (status == "" || e.Status == status) {
+			escalations = append(escalations, *e)
+		}
+	}
+	return escalations, int64(len(escalations)), nil
+}
+
+func (r *fakeEscalationRepo) ListDue(now time.Time) ([]models.Escalation, error) {
+	var due []models.Escalation
+	for _, e := range r.escalations {
+		if e.Status == models.EscalationOpen && e.NextAt != nil && !e.NextAt.After(now) {
+			due = append(due, *e)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeEscalationRepo) SaveEscalation(escalation *models.Escalation) error {
+	if escalation.ID == 0 {
+		escalation.ID = uint(len(r.escalations) + 1)
+		copied := *escalation
+		r.escalations = append(r.escalations, &copied)
+		return nil
+	}
+	for i, e := range r.escalations {
+		if e.ID == escalation.ID {
+			copied := *escalation
+			copied.Notifications = nil
+			r.escalations[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *fakeEscalationRepo) CreateNotification(notification *models.EscalationNotification) error {
+	notification.ID = uint(len(r.notifications) + 1)
+	copied := *notification
+	r.notifications = append(r.notifications, &copied)
+	return nil
+}
+
+// newFakeEscalationRepo has a weekly rotation of accounts 2 and 3 in project
+// 3 handing over on Mondays at 09:00 Tokyo time, and a policy paging it in
+// room 42, then account 4 in room 43 after 15 minutes.
+func newFakeEscalationRepo(t *testing.T) *fakeEscalationRepo {
+	t.Helper()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	rotationID := uint(1)
+	return &fakeEscalationRepo{
+		rotations: map[uint]*models.OnCallRotation{
+			1: {
+				ID: 1, ProjectID: 3, Name: "Backend", Handoff: models.HandoffWeekly, Timezone: "Asia/Tokyo",
+				StartsAt:     time.Date(2026, 10, 5, 9, 0, 0, 0, tokyo),
+				Participants: []models.OnCallParticipant{{AccountID: 2, Name: "Alice"}, {AccountID: 3, Name: "Bob"}},
+			},
+		},
+		policies: map[uint]*models.EscalationPolicy{
+			1: {ID: 1, ProjectID: 3, Name: "Critical", BotID: 7, RoomID: "42", Levels: []models.EscalationLevel{
+				{RotationID: &rotationID, TimeoutMinutes: 15},
+				{AccountIDs: []int64{4}, RoomID: "43", TimeoutMinutes: 30},
+			}},
+		},
+	}
+}
+
+func newEscalationTestEnv(t *testing.T) (*EscalationService, *fakeEscalationRepo, *chatworktest.Server, *time.Time) {
+	t.Helper()
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("bot-token", chatwork.Me{AccountID: 1, Name: "Release Bot"})
+	srv.AddRoom(chatwork.Room{RoomID: 42, Name: "Team", Type: chatwork.RoomTypeGroup}, chatwork.Member{AccountID: 1})
+	srv.AddRoom(chatwork.Room{RoomID: 43, Name: "Leads", Type: chatwork.RoomTypeGroup}, chatwork.Member{AccountID: 1})
+
+	repo := newFakeEscalationRepo(t)
+	bots := &fakeBotRepo{bots: map[uint]*models.ChatworkBot{7: {ID: 7, APIToken: "bot-token", AccountID: 1}}}
+
+	now := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC) // Monday 10:00 in Tokyo
+	svc := NewEscalationService(repo, bots)
+	svc.baseURL = srv.URL
+	svc.now = func() time.Time { return now }
+	return svc, repo, srv, &now
+}
+
+func TestOnCallRotationShifts(t *testing.T) {
+	svc, _, _, now := newEscalationTestEnv(t)
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	cases := []struct {
+		at   time.Time
+		want int64
+	}{
+		{time.Date(2026, 10, 5, 9, 0, 0, 0, tokyo), 2},
+		{time.Date(2026, 10, 12, 8, 59, 0, 0, tokyo), 2},
+		{time.Date(2026, 10, 12, 9, 0, 0, 0, tokyo), 3},
+		{time.Date(2026, 10, 19, 10, 0, 0, 0, tokyo), 2},
+		{time.Date(2026, 9, 30, 12, 0, 0, 0, tokyo), 3},
+	}
+	for _, tc := range cases {
+		shift, err := svc.OnCall(1, 3, tc.at)
+		if err != nil {
+			t.Fatalf("OnCall(%s): %v", tc.at, err)
+		}
+		if shift.AccountID != tc.want {
+			t.Errorf("OnCall(%s) = account %d, want %d", tc.at, shift.AccountID, tc.want)
+		}
+	}
+
+	shift, _ := svc.OnCall(1, 3, *now)
+	if want := time.Date(2026, 10, 26, 9, 0, 0, 0, tokyo); !shift.EndsAt.Equal(want) {
+		t.Fatalf("expected the shift to end at the next Monday handoff, got %s", shift.EndsAt)
+	}
+
+	override, err := svc.CreateOverride(1, 3, &OnCallOverrideInput{
+		AccountID: 5, Name: "Carol", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(24 * time.Hour), Reason: "Alice is away",
+	})
+	if err != nil {
+		t.Fatalf("CreateOverride: %v", err)
+	}
+	shift, _ = svc.OnCall(1, 3, *now)
+	if shift.AccountID != 5 || shift.OverrideID == nil || *shift.OverrideID != override.ID {
+		t.Fatalf("expected the override to take the shift, got %+v", shift)
+	}
+	if shift, _ = svc.OnCall(1, 3, now.Add(25*time.Hour)); shift.AccountID != 2 {
+		t.Fatalf("expected the rotation back after the override, got %+v", shift)
+	}
+
+	if _, err := svc.OnCall(1, 4, *now); err == nil {
+		t.Fatal("expected another project's rotation to be hidden")
+	}
+}
+
+func TestEscalationLevelsAndAcknowledge(t *testing.T) {
+	svc, repo, srv, now := newEscalationTestEnv(t)
+
+	escalation, err := svc.Trigger(3, 1, &EscalationTrigger{Source: "manual", SourceRef: "db", Title: "Database down"})
+	if err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	messages := srv.Messages(42)
+	if len(messages) != 1 || !strings.Contains(messages[0].Body, "[To:2]Alice") || !strings.Contains(messages[0].Body, "Database down") {
+		t.Fatalf("expected the on-call person to be mentioned, got %+v", messages)
+	}
+	if again, _ := svc.Trigger(3, 1, &EscalationTrigger{SourceRef: "db", Title: "Database down"}); again.ID != escalation.ID || len(srv.Messages(42)) != 1 {
+		t.Fatalf("expected an open escalation for the same ref to be reused, got %+v", again)
+	}
+
+	*now = now.Add(10 * time.Minute)
+	if err := svc.EscalateDue(); err != nil {
+		t.Fatalf("EscalateDue: %v", err)
+	}
+	if n := len(srv.Messages(43)); n != 0 {
+		t.Fatalf("expected no escalation before the timeout, got %d messages", n)
+	}
+
+	*now = now.Add(5 * time.Minute)
+	if err := svc.EscalateDue(); err != nil {
+		t.Fatalf("EscalateDue: %v", err)
+	}
+	escalated := srv.Messages(43)
+	if len(escalated) != 1 || !strings.Contains(escalated[0].Body, "[To:4]") || !strings.Contains(escalated[0].Body, "Escalated") {
+		t.Fatalf("expected the second level to be paged in its room, got %+v", escalated)
+	}
+	if e := repo.escalations[0]; e.Level != 1 || e.Status != models.EscalationOpen {
+		t.Fatalf("expected the escalation at level 2, got %+v", e)
+	}
+
+	found, err := svc.GetEscalationByMessage("43", escalated[0].MessageID)
+	if err != nil || found == nil || found.ID != escalation.ID {
+		t.Fatalf("expected the escalation to be found by its message, got %+v, %v", found, err)
+	}
+	if _, err := svc.Acknowledge(escalation.ID, 3, "Chatwork account 4"); err != nil {
+		t.Fatalf("Acknowledge: %v", err)
+	}
+	if e := repo.escalations[0]; e.Status != models.EscalationAcknowledged || e.NextAt != nil || e.AckedBy != "Chatwork account 4" {
+		t.Fatalf("expected the escalation to be acknowledged, got %+v", e)
+	}
+	if last := srv.Messages(43); len(last) != 2 || !strings.Contains(last[1].Body, "[rp aid=1 to=43-"+escalated[0].MessageID+"]") {
+		t.Fatalf("expected a note under the latest message, got %+v", last)
+	}
+
+	*now = now.Add(time.Hour)
+	if err := svc.EscalateDue(); err != nil {
+		t.Fatalf("EscalateDue: %v", err)
+	}
+	if n := len(srv.Messages(43)); n != 2 {
+		t.Fatalf("expected an acknowledged escalation to stay quiet, got %d messages", n)
+	}
+}
+
+func TestEscalationExhausted(t *testing.T) {
+	svc, repo, srv, now := newEscalationTestEnv(t)
+
+	if _, err := svc.Trigger(3, 1, &EscalationTrigger{Title: "Queue backlog"}); err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		*now = now.Add(30 * time.Minute)
+		if err := svc.EscalateDue(); err != nil {
+			t.Fatalf("EscalateDue: %v", err)
+		}
+	}
+	if e := repo.escalations[0]; e.Status != models.EscalationExhausted || e.NextAt != nil {
+		t.Fatalf("expected the escalation to be exhausted, got %+v", e)
+	}
+	messages := srv.Messages(43)
+	if len(messages) != 2 || !strings.Contains(messages[1].Body, "Nobody acknowledged") {
+		t.Fatalf("expected a final note in the last level's room, got %+v", messages)
+	}
+}
+
+func TestEscalationPolicyValidation(t *testing.T) {
+	svc, _, _, _ := newEscalationTestEnv(t)
+	otherRotation := uint(9)
+
+	cases := map[string]*EscalationPolicyInput{
+		"no levels":      {Name: strPtr("P"), BotID: uintPtr(7), RoomID: strPtr("42"), Levels: &[]models.EscalationLevel{}},
+		"unknown bot":    {Name: strPtr("P"), BotID: uintPtr(8), RoomID: strPtr("42"), Levels: &[]models.EscalationLevel{{AccountIDs: []int64{2}, TimeoutMinutes: 5}}},
+		"bad room":       {Name: strPtr("P"), BotID: uintPtr(7), RoomID: strPtr("team"), Levels: &[]models.EscalationLevel{{AccountIDs: []int64{2}, TimeoutMinutes: 5}}},
+		"nobody":         {Name: strPtr("P"), BotID: uintPtr(7), RoomID: strPtr("42"), Levels: &[]models.EscalationLevel{{TimeoutMinutes: 5}}},
+		"bad rotation":   {Name: strPtr("P"), BotID: uintPtr(7), RoomID: strPtr("42"), Levels: &[]models.EscalationLevel{{RotationID: &otherRotation, TimeoutMinutes: 5}}},
+		"no timeout":     {Name: strPtr("P"), BotID: uintPtr(7), RoomID: strPtr("42"), Levels: &[]models.EscalationLevel{{AccountIDs: []int64{2}}}},
+		"missing name":   {BotID: uintPtr(7), RoomID: strPtr("42"), Levels: &[]models.EscalationLevel{{AccountIDs: []int64{2}, TimeoutMinutes: 5}}},
+		"bad level room": {Name: strPtr("P"), BotID: uintPtr(7), RoomID: strPtr("42"), Levels: &[]models.EscalationLevel{{AccountIDs: []int64{2}, RoomID: "x", TimeoutMinutes: 5}}},
+	}
+	for name, input := range cases {
+		if _, err := svc.CreatePolicy(3, input); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+
+	if err := svc.DeleteRotation(1, 3); err == nil {
+		t.Fatal("expected a rotation used by a policy to be kept")
+	}
+}
+
+func TestCveScanEscalatesCriticalFindings(t *testing.T) {
+	escalations, repo, srv, _ := newEscalationTestEnv(t)
+	policyID := uint(1)
+	cves := &CveConfigService{escalations: escalations}
+	config := &models.CveConfig{ID: "cfg-1", ProjectID: 3, Name: "api", EscalationPolicyID: &policyID}
+
+	cves.sendNotifications(config, []models.Vulnerability{{CVEID: "CVE-2026-0001", Severity: "HIGH", Package: "lib", Version: "1.0"}})
+	if len(repo.escalations) != 0 {
+		t.Fatalf("expected no escalation without critical findings, got %+v", repo.escalations)
+	}
+
+	critical := []models.Vulnerability{{CVEID: "CVE-2026-0002", Severity: "CRITICAL", Package: "lib", Version: "1.0"}}
+	cves.sendNotifications(config, critical)
+	cves.sendNotifications(config, critical)
+	if len(repo.escalations) != 1 || repo.escalations[0].SourceRef != "cve-config:cfg-1" {
+		t.Fatalf("expected one escalation for the config, got %+v", repo.escalations)
+	}
+	messages := srv.Messages(42)
+	if len(messages) != 1 || !strings.Contains(messages[0].Body, "CVE-2026-0002") || !strings.Contains(messages[0].Body, "[To:2]") {
+		t.Fatalf("expected the on-call person to be paged about the CVE, got %+v", messages)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func uintPtr(n uint) *uint {
+	return &n
+}
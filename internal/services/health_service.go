@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,6 +11,7 @@ import (
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
 )
 
 var serverStartTime int64 = 0
@@ -18,12 +21,24 @@ func init() {
 }
 
 type ChatworkHealth struct {
-	Status      string `json:"status"`
-	Latency     int64  `json:"latency"`
-	APIVersion  string `json:"apiVersion"`
-	LastChecked string `json:"lastChecked"`
+	Status      string              `json:"status"`
+	Latency     int64               `json:"latency"`
+	APIVersion  string              `json:"apiVersion"`
+	LastChecked string              `json:"lastChecked"`
+	Queue       ChatworkQueueHealth `json:"queue"`
 }
 
+// ChatworkQueueHealth summarises the outbound Chatwork queue across all tokens.
+type ChatworkQueueHealth struct {
+	Status    string                `json:"status"`
+	Depth     int                   `json:"depth"`
+	Throttled uint64                `json:"throttled"`
+	Tokens    []chatwork.QueueStats `json:"tokens,omitempty"`
+}
+
+// chatworkQueueDegradedDepth is the queue depth at which Chatwork delivery is reported as degraded.
+const chatworkQueueDegradedDepth = 100
+
 type ServerHealth struct {
 	Status string       `json:"status"`
 	Uptime int64        `json:"uptime"`
@@ -64,12 +79,28 @@ type HealthChatworkService struct {
 
 func NewHealthChatworkService() *HealthChatworkService {
 	return &HealthChatworkService{
-		BaseURL: "https://api.chatwork.com/v2",
+		BaseURL: chatworkBaseURL(),
 	}
 }
 
+// GetChatworkQueueHealth reports the depth and throttling of the shared outbound queue.
+func GetChatworkQueueHealth() ChatworkQueueHealth {
+	health := ChatworkQueueHealth{Status: "operational", Tokens: chatwork.DefaultLimiter().Stats()}
+	for _, t := range health.Tokens {
+		health.Depth += t.Depth
+		health.Throttled += t.Throttled
+	}
+	if health.Depth >= chatworkQueueDegradedDepth {
+		health.Status = "degraded"
+	}
+	return health
+}
+
 func (s *HealthChatworkService) CheckHealth() (ChatworkHealth, error) {
 	start := time.Now()
+	// Per-token stats are for admins only, this check is public
+	queue := GetChatworkQueueHealth()
+	queue.Tokens = nil
 
 	apiKey := os.Getenv("CHATWORK_API_TOKEN")
 	if apiKey == "" {
@@ -78,36 +109,32 @@ func (s *HealthChatworkService) CheckHealth() (ChatworkHealth, error) {
 			Latency:     0,
 			APIVersion:  "v2",
 			LastChecked: time.Now().Format(time.RFC3339),
+			Queue:       queue,
 		}, fmt.Errorf("CHATWORK_API_TOKEN not configured")
 	}
 
-	req, err := http.NewRequest("GET", s.BaseURL+"/me", nil)
-	if err != nil {
-		return ChatworkHealth{
-			Status:      "down",
-			APIVersion:  "v2",
-			LastChecked: time.Now().Format(time.RFC3339),
-		}, err
-	}
-
-	req.Header.Set("X-ChatWorkToken", apiKey)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
+	// The probe bypasses the outbound queue so a backlog does not read as an outage.
+	client := chatwork.NewClient(apiKey, chatwork.WithBaseURL(s.BaseURL), chatwork.WithLimiter(nil), chatwork.WithMaxRateLimitWait(0))
+	_, err := client.Me(ctx)
 	latency := time.Since(start).Milliseconds()
 
-	if err != nil {
+	// Any answer below 500 (including a rejected token or a rate limit) means the API itself is reachable.
+	var apiErr *chatwork.APIError
+	if err != nil && (!errors.As(err, &apiErr) || apiErr.StatusCode >= http.StatusInternalServerError) {
 		return ChatworkHealth{
 			Status:      "down",
 			Latency:     latency,
 			APIVersion:  "v2",
 			LastChecked: time.Now().Format(time.RFC3339),
+			Queue:       queue,
 		}, err
 	}
-	defer resp.Body.Close()
 
 	status := "operational"
-	if latency >= 500 {
+	if latency >= 500 || queue.Status == "degraded" {
 		status = "degraded"
 	}
 
@@ -116,6 +143,7 @@ func (s *HealthChatworkService) CheckHealth() (ChatworkHealth, error) {
 		Latency:     latency,
 		APIVersion:  "v2",
 		LastChecked: time.Now().Format(time.RFC3339),
+		Queue:       queue,
 	}, nil
 }
 
@@ -244,13 +272,19 @@ func GetOverallHealth() OverallHealthResponse {
 		databaseStatus = "degraded"
 	}
 
+	chatworkQueueStatus := "up"
+	if GetChatworkQueueHealth().Status == "degraded" {
+		chatworkQueueStatus = "degraded"
+	}
+
 	checks := map[string]string{
-		"chatwork": chatworkStatus,
-		"server":   serverStatus,
-		"database": databaseStatus,
+		"chatwork":      chatworkStatus,
+		"chatworkQueue": chatworkQueueStatus,
+		"server":        serverStatus,
+		"database":      databaseStatus,
 	}
 
-	statuses := []string{chatworkStatus, serverStatus, databaseStatus}
+	statuses := []string{chatworkStatus, chatworkQueueStatus, serverStatus, databaseStatus}
 	overallStatus := "healthy"
 
 	hasDown := false
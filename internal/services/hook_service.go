@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
 	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
 )
 
@@ -81,61 +82,60 @@ func NewHookService(cw IChatworkService) *HookService {
 }
 
 func (h *HookService) ChatworkHook(payload DiscordPayload) error {
-	chatworkString := ConvertDiscordPayloadToChatwork(payload)
-	logger.Infof("Converted Chatwork message: %s", chatworkString)
+	message := BuildDiscordChatworkMessage(payload)
+	logger.Infof("Converted Chatwork message: %s", message.String())
 
 	// Send the message to Chatwork
 	ROOM_ID := utils.GetEnv("CHATWORK_ROOM_ID", "")
 	API_KEY := utils.GetEnv("CHATWORK_API_TOKEN", "")
 
-	err := h.cw.SendMessage(API_KEY, ROOM_ID, chatworkString)
-	if err != nil {
-		return err
+	for _, body := range message.Split(chatwork.MaxMessageLength) {
+		if err := h.cw.SendMessage(API_KEY, ROOM_ID, body); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 func ConvertDiscordPayloadToChatwork(payload DiscordPayload) string {
-	var builder strings.Builder
+	return BuildDiscordChatworkMessage(payload).String()
+}
 
-	for _, embed := range payload.Embeds {
-		builder.WriteString("[info]")
+// BuildDiscordChatworkMessage lays out each Discord embed as a Chatwork [info] box.
+func BuildDiscordChatworkMessage(payload DiscordPayload) *chatwork.Builder {
+	builder := chatwork.NewBuilder()
 
+	for _, embed := range payload.Embeds {
 		// Title with removed emojis replaced
-		title := removeDiscordIcons(embed.Title)
-		if title != "" {
-			builder.WriteString("[title]" + strings.TrimSpace(title) + "[/title]\n")
-		}
-
-		// Description
-		if embed.Description != "" {
-			description := convertMarkdownLinks(embed.Description)
-			description = convertDiscordIconsToChatwork(description)
-			description = strings.Replace(description, "Open application", "🔗 Application URL", -1)
-			builder.WriteString(description + "\n\n")
-		}
-
-		// Field section
-		for _, field := range embed.Fields {
-			value := convertMarkdownLinks(field.Value)
-			value = convertTimestamps(value)
-			value = convertValueToChatwork(value)
+		title := strings.TrimSpace(removeDiscordIcons(embed.Title))
+
+		builder.Info(title, func(info *chatwork.Builder) {
+			// Description
+			if embed.Description != "" {
+				description := convertMarkdownLinks(embed.Description)
+				description = convertDiscordIconsToChatwork(description)
+				description = strings.Replace(description, "Open application", "🔗 Application URL", -1)
+				info.Text(strings.TrimRight(description, "\n")).Raw("")
+			}
 
-			icon := getFieldIcon(field.Name)
-			builder.WriteString(fmt.Sprintf("%s %s: %s\n", icon, field.Name, value))
-		}
+			// Field section
+			for _, field := range embed.Fields {
+				value := convertMarkdownLinks(field.Value)
+				value = convertTimestamps(value)
+				value = convertValueToChatwork(value)
 
-		// Footer
-		if embed.Footer.Text != "" {
-			builder.WriteString("\n[hr]\n")
-			builder.WriteString("From Discord, sending all our love 💖🤝💬\n")
-		}
+				info.Textf("%s %s: %s", getFieldIcon(field.Name), field.Name, value)
+			}
 
-		builder.WriteString("[/info]\n\n")
+			// Footer
+			if embed.Footer.Text != "" {
+				info.HR().Text("From Discord, sending all our love 💖🤝💬")
+			}
+		})
 	}
 
-	return builder.String()
+	return builder
 }
 
 // Add icons to fields
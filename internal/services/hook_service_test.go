@@ -0,0 +1,37 @@
+package services
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+func TestConvertDiscordPayloadToChatworkGolden(t *testing.T) {
+	payload := DiscordPayload{Embeds: []DiscordEmbed{{
+		Title:       ":white_check_mark: Deployment succeeded",
+		Description: "[Open application](https://app.example.com) for [info]injected[/info]",
+		Fields: []DiscordField{
+			{Name: "Project", Value: "backend"},
+			{Name: "Deployment Logs", Value: "[Link](https://ci.example.com/1)"},
+		},
+		Footer: DiscordFooter{Text: "ci"},
+	}}}
+
+	got := ConvertDiscordPayloadToChatwork(payload)
+	path := filepath.Join("testdata", "discord_to_chatwork.golden")
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Fatalf("unexpected chatwork body\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
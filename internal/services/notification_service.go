@@ -0,0 +1,277 @@
+package services
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/mailer"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// NotificationChannelInput holds the writable fields of a notification channel.
+// Nil pointers are left unchanged on update.
+type NotificationChannelInput struct {
+	Name       *string
+	Type       *string
+	RoomID     *string
+	BotID      *uint
+	Token      *string
+	WebhookURL *string
+	Secret     *string
+	Recipients []string
+	Active     *bool
+}
+
+type INotificationService interface {
+	GetByProjectID(projectID uint) ([]models.NotificationChannel, error)
+	GetByID(id uint, projectID uint) (*models.NotificationChannel, error)
+	Create(projectID uint, input *NotificationChannelInput) (*models.NotificationChannel, error)
+	Update(id uint, projectID uint, input *NotificationChannelInput) (*models.NotificationChannel, error)
+	Delete(id uint, projectID uint) error
+	// Test sends a sample message through the channel.
+	Test(id uint, projectID uint) error
+	// Notify renders msg for the channel's type and delivers it.
+	Notify(channelID uint, msg notifier.Message) error
+	// NotifyChatwork delivers msg to a Chatwork room without a stored channel.
+	NotifyChatwork(token, roomID string, msg notifier.Message) error
+}
+
+type NotificationService struct {
+	repo     repositories.INotificationChannelRepository
+	botRepo  repositories.IChatworkBotRepository
+	registry *notifier.Registry
+	lookupIP func(host string) ([]net.IP, error)
+}
+
+func NewNotificationService(repo repositories.INotificationChannelRepository, botRepo repositories.IChatworkBotRepository, registry *notifier.Registry) *NotificationService {
+	return &NotificationService{
+		repo:     repo,
+		botRepo:  botRepo,
+		registry: registry,
+		lookupIP: net.LookupIP,
+	}
+}
+
+// NewDefaultNotifierRegistry registers every built-in channel, with email
+// enabled when SMTP is configured in the environment.
+func NewDefaultNotifierRegistry() *notifier.Registry {
+	return notifier.NewDefaultRegistry(NewDefaultMailer())
+}
+
+// NewDefaultMailer returns the SMTP sender configured in the environment,
+// or nil when email delivery is disabled.
+func NewDefaultMailer() mailer.EmailSender {
+	config, ok := configs.LoadMailerConfig()
+	if !ok {
+		return nil
+	}
+	return mailer.NewGomailSender(config)
+}
+
+func (s *NotificationService) GetByProjectID(projectID uint) ([]models.NotificationChannel, error) {
+	channels, err := s.repo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return channels, nil
+}
+
+func (s *NotificationService) GetByID(id uint, projectID uint) (*models.NotificationChannel, error) {
+	channel, err := s.repo.GetByID(id)
+	if err != nil || channel.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "notification channel not found")
+	}
+	return channel, nil
+}
+
+func (s *NotificationService) Create(projectID uint, input *NotificationChannelInput) (*models.NotificationChannel, error) {
+	channel := &models.NotificationChannel{ProjectID: projectID, Active: true}
+	applyChannelInput(channel, input)
+
+	if channel.Type == notifier.TypeWebhook && channel.Secret == "" {
+		channel.Secret = "whsec_" + utils.GenerateRandomString(32)
+	}
+	if err := s.validateChannel(channel); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(channel)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return created, nil
+}
+
+func (s *NotificationService) Update(id uint, projectID uint, input *NotificationChannelInput) (*models.NotificationChannel, error) {
+	channel, err := s.GetByID(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyChannelInput(channel, input)
+	if err := s.validateChannel(channel); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.Update(channel)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return updated, nil
+}
+
+func (s *NotificationService) Delete(id uint, projectID uint) error {
+	if _, err := s.GetByID(id, projectID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *NotificationService) Test(id uint, projectID uint) error {
+	channel, err := s.GetByID(id, projectID)
+	if err != nil {
+		return err
+	}
+	return s.send(channel, notifier.Message{
+		Title:    "Test notification",
+		Text:     fmt.Sprintf("Channel %q is configured correctly.", channel.Name),
+		Severity: notifier.SeveritySuccess,
+		Footer:   "🤖 Bot Dashboard Hub",
+	})
+}
+
+func (s *NotificationService) Notify(channelID uint, msg notifier.Message) error {
+	channel, err := s.repo.GetByID(channelID)
+	if err != nil {
+		return fmt.Errorf("notification channel %d not found: %w", channelID, err)
+	}
+	if !channel.Active {
+		return fmt.Errorf("notification channel %d is inactive", channelID)
+	}
+	return s.send(channel, msg)
+}
+
+func (s *NotificationService) NotifyChatwork(token, roomID string, msg notifier.Message) error {
+	return s.registry.Send(notifier.Target{Type: notifier.TypeChatwork, RoomID: roomID, Token: token}, msg)
+}
+
+func (s *NotificationService) send(channel *models.NotificationChannel, msg notifier.Message) error {
+	target, err := s.resolveTarget(channel)
+	if err != nil {
+		return err
+	}
+	err = s.registry.Send(target, msg)
+	var statusErr *notifier.StatusError
+	if stderrors.As(err, &statusErr) {
+		logger.Warnf("[Notification] channel_id=%d %s replied %d: %s", channel.ID, channel.Type, statusErr.StatusCode, statusErr.Body)
+	}
+	return err
+}
+
+// resolveTarget builds the delivery target, loading the bot token for Chatwork channels.
+func (s *NotificationService) resolveTarget(channel *models.NotificationChannel) (notifier.Target, error) {
+	target := notifier.Target{
+		Type:       channel.Type,
+		RoomID:     channel.RoomID,
+		Token:      channel.Token,
+		WebhookURL: channel.WebhookURL,
+		Secret:     channel.Secret,
+		Recipients: channel.Recipients,
+	}
+
+	if channel.Type == notifier.TypeChatwork && channel.BotID != nil {
+		bot, err := s.botRepo.GetByID(*channel.BotID)
+		if err != nil {
+			return target, fmt.Errorf("failed to get bot %d: %w", *channel.BotID, err)
+		}
+		target.Token = bot.APIToken
+	}
+	return target, nil
+}
+
+func applyChannelInput(channel *models.NotificationChannel, input *NotificationChannelInput) {
+	if input.Name != nil {
+		channel.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Type != nil {
+		channel.Type = strings.ToLower(strings.TrimSpace(*input.Type))
+	}
+	if input.RoomID != nil {
+		channel.RoomID = strings.TrimSpace(*input.RoomID)
+	}
+	if input.BotID != nil {
+		channel.BotID = input.BotID
+	}
+	if input.Token != nil {
+		channel.Token = *input.Token
+	}
+	if input.WebhookURL != nil {
+		channel.WebhookURL = strings.TrimSpace(*input.WebhookURL)
+	}
+	if input.Secret != nil {
+		channel.Secret = *input.Secret
+	}
+	if input.Recipients != nil {
+		recipients := make([]string, 0, len(input.Recipients))
+		for _, r := range input.Recipients {
+			if r = strings.TrimSpace(r); r != "" {
+				recipients = append(recipients, r)
+			}
+		}
+		channel.Recipients = recipients
+	}
+	if input.Active != nil {
+		channel.Active = *input.Active
+	}
+}
+
+// validateChannel checks that the settings required by the channel type are
+// present, and that webhook URLs point at a public host.
+func (s *NotificationService) validateChannel(channel *models.NotificationChannel) error {
+	if channel.Name == "" {
+		return errors.New(errors.ErrInvalidData, "name is required")
+	}
+	if !notifier.IsValidType(channel.Type) {
+		return errors.New(errors.ErrInvalidData, fmt.Sprintf("type must be one of: %s", strings.Join(notifier.Types, ", ")))
+	}
+
+	switch channel.Type {
+	case notifier.TypeChatwork:
+		if channel.RoomID == "" {
+			return errors.New(errors.ErrInvalidData, "roomId is required for chatwork channels")
+		}
+		if channel.BotID == nil && channel.Token == "" {
+			return errors.New(errors.ErrInvalidData, "botId or token is required for chatwork channels")
+		}
+	case notifier.TypeEmail:
+		if len(channel.Recipients) == 0 {
+			return errors.New(errors.ErrInvalidData, "recipients are required for email channels")
+		}
+		for _, r := range channel.Recipients {
+			if !strings.Contains(r, "@") {
+				return errors.New(errors.ErrInvalidData, fmt.Sprintf("invalid recipient: %s", r))
+			}
+		}
+	default:
+		u, err := url.Parse(channel.WebhookURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return errors.New(errors.ErrInvalidData, "a valid webhookUrl is required")
+		}
+		if err := notifier.CheckPublicHost(u.Hostname(), s.lookupIP); err != nil {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("webhookUrl must point at a public host: %v", err))
+		}
+	}
+	return nil
+}
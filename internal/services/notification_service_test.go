@@ -0,0 +1,42 @@
+package services
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+func TestValidateChannelRejectsPrivateWebhookHosts(t *testing.T) {
+	svc := NewNotificationService(nil, nil, nil)
+	svc.lookupIP = func(host string) ([]net.IP, error) {
+		switch host {
+		case "hooks.example.com":
+			return []net.IP{net.ParseIP("93.184.216.34")}, nil
+		default:
+			return []net.IP{net.ParseIP("10.0.0.5")}, nil
+		}
+	}
+
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/services/x", true},
+		{"https://intranet.example.com/hook", false},
+		{"http://127.0.0.1:8080/hook", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://[::1]/hook", false},
+	}
+	for _, tt := range tests {
+		err := svc.validateChannel(&models.NotificationChannel{Name: "alerts", Type: notifier.TypeSlack, WebhookURL: tt.url})
+		if tt.ok && err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.url, err)
+		}
+		if !tt.ok && appErrorCode(err) != errors.ErrInvalidData {
+			t.Fatalf("%s: expected ErrInvalidData, got %v", tt.url, err)
+		}
+	}
+}
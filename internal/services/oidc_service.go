@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/oidc"
+)
+
+// oidcAuthRequestTTL is how long the user has to sign in at the provider.
+const oidcAuthRequestTTL = 10 * time.Minute
+
+// IOIDCService signs users in with the OpenID Connect provider and keeps
+// their project roles in line with the provider's groups.
+type IOIDCService interface {
+	// Enabled reports whether a provider is configured.
+	Enabled() bool
+	// Begin starts a sign-in. It returns the provider URL to send the
+	// browser to and the state the provider will send back with the code.
+	Begin(ctx context.Context) (authURL, state string, err error)
+	// Complete finishes the sign-in with the code and state the provider
+	// redirected back with. The user is found by their subject, linked by
+	// verified email or created, and their group roles are synced.
+	Complete(ctx context.Context, code, state string) (*models.User, error)
+
+	GetGroupMappings() ([]models.OIDCGroupMapping, error)
+	CreateGroupMapping(input *OIDCGroupMappingInput) (*models.OIDCGroupMapping, error)
+	DeleteGroupMapping(id uint) error
+}
+
+type OIDCGroupMappingInput struct {
+	Group     string
+	ProjectID uint
+	Role      string
+}
+
+type OIDCService struct {
+	repo        repositories.IOIDCRepository
+	users       repositories.IUserRepository
+	projectRepo repositories.IProjectRepository
+	provider    *oidc.Provider
+	config      configs.OIDCConfig
+	now         func() time.Time
+}
+
+// NewOIDCService leaves single sign-on disabled when config has no issuer.
+func NewOIDCService(repo repositories.IOIDCRepository, users repositories.IUserRepository, projectRepo repositories.IProjectRepository, config configs.OIDCConfig) *OIDCService {
+	s := &OIDCService{
+		repo:        repo,
+		users:       users,
+		projectRepo: projectRepo,
+		config:      config,
+		now:         time.Now,
+	}
+	if config.Issuer != "" {
+		s.provider = oidc.NewProvider(config.Config)
+	}
+	return s
+}
+
+func (s *OIDCService) Enabled() bool {
+	return s.provider != nil
+}
+
+func (s *OIDCService) Begin(ctx context.Context) (string, string, error) {
+	if !s.Enabled() {
+		return "", "", errors.New(errors.ErrResourceNotFound, "single sign-on is not configured")
+	}
+	now := s.now()
+	if err := s.repo.DeleteExpiredAuthRequests(now); err != nil {
+		logger.Warnf("[OIDC] Failed to delete expired sign-in requests: %v", err)
+	}
+
+	req := oidc.NewAuthRequest()
+	authURL, err := s.provider.AuthCodeURL(ctx, req)
+	if err != nil {
+		logger.Errorf("[OIDC] %v", err)
+		return "", "", errors.New(errors.ErrServerInternal, "the single sign-on provider is unavailable")
+	}
+	request := &models.OIDCAuthRequest{
+		StateHash:    hashToken(req.State),
+		Nonce:        req.Nonce,
+		CodeVerifier: req.CodeVerifier,
+		ExpiresAt:    now.Add(oidcAuthRequestTTL),
+	}
+	if err := s.repo.CreateAuthRequest(request); err != nil {
+		return "", "", errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return authURL, req.State, nil
+}
+
+func (s *OIDCService) Complete(ctx context.Context, code, state string) (*models.User, error) {
+	if !s.Enabled() {
+		return nil, errors.New(errors.ErrResourceNotFound, "single sign-on is not configured")
+	}
+	if code == "" || state == "" {
+		return nil, errors.New(errors.ErrInvalidData, "code and state are required")
+	}
+	request, err := s.repo.TakeAuthRequest(hashToken(state))
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if request == nil || !s.now().Before(request.ExpiresAt) {
+		return nil, errors.New(errors.ErrAuthUnauthorized, "the sign-in is invalid or has expired; start again")
+	}
+
+	token, err := s.provider.Exchange(ctx, code, request.CodeVerifier)
+	if err != nil {
+		logger.Warnf("[OIDC] %v", err)
+		return nil, errors.New(errors.ErrAuthUnauthorized, "the single sign-on provider refused the sign-in")
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, token.IDToken, request.Nonce)
+	if err != nil {
+		logger.Warnf("[OIDC] %v", err)
+		return nil, errors.New(errors.ErrAuthUnauthorized, "the single sign-on provider refused the sign-in")
+	}
+
+	user, err := s.findOrProvision(claims)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != models.UserActive {
+		return nil, errors.New(errors.ErrAuthUnauthorized, "the account is disabled")
+	}
+	now := s.now()
+	user.LastLoginAt = &now
+	if err := s.users.Save(user); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	s.syncGroups(user, claims.Strings(s.config.GroupsClaim))
+
+	// Reload for the synced memberships
+	user, err = s.users.GetByID(user.ID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return user, nil
+}
+
+// findOrProvision returns the user linked to the subject. The first time, an
+// existing account with the same verified email is linked, or else a new
+// account is created when auto-provisioning is on.
+func (s *OIDCService) findOrProvision(claims *oidc.Claims) (*models.User, error) {
+	user, err := s.users.GetByOIDCSubject(claims.Subject)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if user != nil {
+		return user, nil
+	}
+
+	email := normalizeEmail(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return nil, errors.New(errors.ErrAuthUnauthorized, "the single sign-on provider didn't share a verified email address")
+	}
+	subject := claims.Subject
+	user, err = s.users.GetByEmail(email)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if user != nil {
+		if user.OIDCSubject != nil {
+			return nil, errors.New(errors.ErrAuthUnauthorized, "the account is linked to another single sign-on user")
+		}
+		user.OIDCSubject = &subject
+		if user.Name == "" {
+			user.Name = strings.TrimSpace(claims.Name)
+		}
+		logger.Infof("[OIDC] Linked user_id=%d to subject %s", user.ID, subject)
+		return user, nil
+	}
+
+	if !s.config.AutoProvision {
+		return nil, errors.New(errors.ErrAuthForbidden, "there is no account for this email; ask an admin for an invitation")
+	}
+	user = &models.User{
+		Email:       email,
+		Name:        strings.TrimSpace(claims.Name),
+		OIDCSubject: &subject,
+		Status:      models.UserActive,
+	}
+	if err := s.users.Save(user); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	logger.Infof("[OIDC] Created user_id=%d for %s", user.ID, email)
+	return user, nil
+}
+
+// syncGroups gives the user the highest role their groups map to in each
+// project with mappings. Roles set by hand are left alone, and a synced role
+// is removed once none of the user's groups map to the project, unless the
+// user is the project's last owner.
+func (s *OIDCService) syncGroups(user *models.User, groups []string) {
+	mappings, err := s.repo.GetGroupMappings()
+	if err != nil {
+		logger.Warnf("[OIDC] user_id=%d failed to load group mappings: %v", user.ID, err)
+		return
+	}
+
+	var projects []uint
+	roles := map[uint]string{}
+	for _, m := range mappings {
+		if !slices.Contains(projects, m.ProjectID) {
+			projects = append(projects, m.ProjectID)
+		}
+		if slices.Contains(groups, m.Group) && !models.RoleAtLeast(roles[m.ProjectID], m.Role) {
+			roles[m.ProjectID] = m.Role
+		}
+	}
+
+	for _, projectID := range projects {
+		if err := s.syncMembership(user.ID, projectID, roles[projectID]); err != nil {
+			logger.Warnf("[OIDC] user_id=%d project_id=%d failed to sync the role: %v", user.ID, projectID, err)
+		}
+	}
+}
+
+func (s *OIDCService) syncMembership(userID, projectID uint, role string) error {
+	member, err := s.users.GetMembership(projectID, userID)
+	if err != nil {
+		return err
+	}
+	if member != nil && member.Source != models.MemberSourceOIDC {
+		return nil
+	}
+	if member != nil && member.Role == models.RoleOwner && role != models.RoleOwner {
+		last, err := s.lastOwner(projectID)
+		if err != nil || last {
+			return err
+		}
+	}
+
+	switch {
+	case role == "" && member != nil:
+		return s.users.DeleteMembership(projectID, userID)
+	case role == "" || (member != nil && member.Role == role):
+		return nil
+	case member == nil:
+		member = &models.ProjectMember{ProjectID: projectID, UserID: userID, Source: models.MemberSourceOIDC}
+	}
+	member.Role = role
+	return s.users.SaveMembership(member)
+}
+
+func (s *OIDCService) lastOwner(projectID uint) (bool, error) {
+	members, err := s.users.GetMembers(projectID)
+	if err != nil {
+		return false, err
+	}
+	owners := 0
+	for _, m := range members {
+		if m.Role == models.RoleOwner {
+			owners++
+		}
+	}
+	return owners <= 1, nil
+}
+
+func (s *OIDCService) GetGroupMappings() ([]models.OIDCGroupMapping, error) {
+	mappings, err := s.repo.GetGroupMappings()
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return mappings, nil
+}
+
+func (s *OIDCService) CreateGroupMapping(input *OIDCGroupMappingInput) (*models.OIDCGroupMapping, error) {
+	group := strings.TrimSpace(input.Group)
+	if group == "" {
+		return nil, errors.New(errors.ErrInvalidData, "group is required")
+	}
+	if !slices.Contains(models.ProjectRoles, input.Role) {
+		return nil, errors.New(errors.ErrInvalidData, "role must be owner, maintainer or viewer")
+	}
+	if _, err := s.projectRepo.GetByID(input.ProjectID); err != nil {
+		return nil, errors.New(errors.ErrInvalidData, "project not found")
+	}
+	mappings, err := s.GetGroupMappings()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mappings {
+		if m.Group == group && m.ProjectID == input.ProjectID {
+			return nil, errors.New(errors.ErrInvalidData, "the group is already mapped to this project")
+		}
+	}
+
+	mapping := &models.OIDCGroupMapping{Group: group, ProjectID: input.ProjectID, Role: input.Role}
+	if err := s.repo.SaveGroupMapping(mapping); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return mapping, nil
+}
+
+func (s *OIDCService) DeleteGroupMapping(id uint) error {
+	if _, err := s.repo.GetGroupMapping(id); err != nil {
+		return errors.New(errors.ErrResourceNotFound, "group mapping not found")
+	}
+	if err := s.repo.DeleteGroupMapping(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
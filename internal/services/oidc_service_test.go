@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/configs"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/oidc"
+	"github.com/vfa-khuongdv/golang-cms/pkg/oidc/oidctest"
+	"gorm.io/gorm"
+)
+
+func (r *fakeUserRepo) GetByOIDCSubject(subject string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.OIDCSubject != nil && *user.OIDCSubject == subject {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+type fakeOIDCRepo struct {
+	repositories.IOIDCRepository
+	requests []*models.OIDCAuthRequest
+	mappings []*models.OIDCGroupMapping
+}
+
+func (r *fakeOIDCRepo) CreateAuthRequest(request *models.OIDCAuthRequest) error {
+	r.requests = append(r.requests, request)
+	return nil
+}
+
+func (r *fakeOIDCRepo) TakeAuthRequest(stateHash string) (*models.OIDCAuthRequest, error) {
+	for i, request := range r.requests {
+		if request.StateHash == stateHash {
+			r.requests = append(r.requests[:i], r.requests[i+1:]...)
+			return request, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeOIDCRepo) DeleteExpiredAuthRequests(now time.Time) error {
+	return nil
+}
+
+func (r *fakeOIDCRepo) GetGroupMappings() ([]models.OIDCGroupMapping, error) {
+	var mappings []models.OIDCGroupMapping
+	for _, m := range r.mappings {
+		mappings = append(mappings, *m)
+	}
+	return mappings, nil
+}
+
+func (r *fakeOIDCRepo) GetGroupMapping(id uint) (*models.OIDCGroupMapping, error) {
+	for _, m := range r.mappings {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOIDCRepo) SaveGroupMapping(mapping *models.OIDCGroupMapping) error {
+	mapping.ID = uint(len(r.mappings) + 1)
+	r.mappings = append(r.mappings, mapping)
+	return nil
+}
+
+func (r *fakeOIDCRepo) DeleteGroupMapping(id uint) error {
+	for i, m := range r.mappings {
+		if m.ID == id {
+			r.mappings = append(r.mappings[:i], r.mappings[i+1:]...)
+		}
+	}
+	return nil
+}
+
+type oidcTestEnv struct {
+	svc      *OIDCService
+	users    *UserService
+	userRepo *fakeUserRepo
+	provider *oidctest.Server
+	now      *time.Time
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+	users, userRepo, now := newUserTestEnv(t)
+	provider := oidctest.NewServer("bot-hub", "client-secret")
+	t.Cleanup(provider.Close)
+
+	config := configs.OIDCConfig{
+		Config: oidc.Config{
+			Issuer:       provider.Issuer(),
+			ClientID:     "bot-hub",
+			ClientSecret: "client-secret",
+			RedirectURL:  "https://app.example.com/auth/oidc/callback",
+		},
+		GroupsClaim:   "groups",
+		AutoProvision: true,
+	}
+	svc := NewOIDCService(&fakeOIDCRepo{}, userRepo, users.projectRepo, config)
+	svc.now = func() time.Time { return *now }
+	return &oidcTestEnv{svc: svc, users: users, userRepo: userRepo, provider: provider, now: now}
+}
+
+// signIn runs the whole flow as the provider user with the subject.
+func (e *oidcTestEnv) signIn(t *testing.T, subject string) (*models.User, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, state, err := e.svc.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	code, returnedState, err := e.provider.Authorize(authURL, subject)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if returnedState != state {
+		t.Fatalf("state = %q, want %q", returnedState, state)
+	}
+	return e.svc.Complete(ctx, code, state)
+}
+
+func TestOIDCProvisionsAndLinksUsers(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.provider.AddUser(oidctest.User{Subject: "alice-sub", Email: "Alice@Example.com", EmailVerified: true, Name: "Alice"})
+	env.provider.AddUser(oidctest.User{Subject: "admin-sub", Email: "admin@example.com", EmailVerified: true})
+	env.provider.AddUser(oidctest.User{Subject: "mallory-sub", Email: "mallory@example.com"})
+
+	alice, err := env.signIn(t, "alice-sub")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if alice.Email != "alice@example.com" || alice.Name != "Alice" || alice.IsAdmin || alice.PasswordHash != "" {
+		t.Fatalf("unexpected provisioned user %+v", alice)
+	}
+	if alice.LastLoginAt == nil || !alice.LastLoginAt.Equal(*env.now) {
+		t.Fatal("expected the sign-in to be recorded")
+	}
+	if again, err := env.signIn(t, "alice-sub"); err != nil || again.ID != alice.ID {
+		t.Fatalf("expected the second sign-in to find the same user, got %v", err)
+	}
+	if _, err := env.users.Login("alice@example.com", ""); err == nil {
+		t.Fatal("expected a provisioned user to have no password")
+	}
+
+	// The existing admin is linked by their verified email
+	admin, err := env.signIn(t, "admin-sub")
+	if err != nil || admin.ID != 1 || !admin.IsAdmin {
+		t.Fatalf("expected the admin to be linked, got %+v, %v", admin, err)
+	}
+	if sub := env.userRepo.users[1].OIDCSubject; sub == nil || *sub != "admin-sub" {
+		t.Fatal("expected the subject to be stored")
+	}
+
+	if _, err := env.signIn(t, "mallory-sub"); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected an unverified email to be refused, got %v", err)
+	}
+
+	// Another subject claiming the linked admin's email can't take the account over
+	env.provider.AddUser(oidctest.User{Subject: "impostor-sub", Email: "admin@example.com", EmailVerified: true})
+	if _, err := env.signIn(t, "impostor-sub"); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected a second subject for the same email to be refused, got %v", err)
+	}
+
+	disabled := models.UserDisabled
+	if _, err := env.users.UpdateUser(alice.ID, &UserInput{Status: &disabled}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if _, err := env.signIn(t, "alice-sub"); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected a disabled user to be refused, got %v", err)
+	}
+}
+
+func TestOIDCWithoutAutoProvisioning(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.svc.config.AutoProvision = false
+	env.provider.AddUser(oidctest.User{Subject: "bob-sub", Email: "bob@example.com", EmailVerified: true})
+
+	if _, err := env.signIn(t, "bob-sub"); appErrorCode(err) != errors.ErrAuthForbidden {
+		t.Fatalf("expected an unknown user to be refused, got %v", err)
+	}
+	if _, err := env.users.CreateUser(&UserInput{Email: ptr("bob@example.com"), Password: ptr("bob-password")}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := env.signIn(t, "bob-sub"); err != nil {
+		t.Fatalf("expected an existing user to sign in, got %v", err)
+	}
+}
+
+func TestOIDCStateIsSingleUse(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.provider.AddUser(oidctest.User{Subject: "alice-sub", Email: "alice@example.com", EmailVerified: true})
+	ctx := context.Background()
+
+	authURL, state, _ := env.svc.Begin(ctx)
+	code, _, _ := env.provider.Authorize(authURL, "alice-sub")
+	if _, err := env.svc.Complete(ctx, code, "forged-state"); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected an unknown state to be refused, got %v", err)
+	}
+	if _, err := env.svc.Complete(ctx, code, state); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if _, err := env.svc.Complete(ctx, code, state); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected a replayed state to be refused, got %v", err)
+	}
+
+	authURL, state, _ = env.svc.Begin(ctx)
+	code, _, _ = env.provider.Authorize(authURL, "alice-sub")
+	*env.now = env.now.Add(oidcAuthRequestTTL)
+	if _, err := env.svc.Complete(ctx, code, state); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected an expired sign-in to be refused, got %v", err)
+	}
+
+	env.svc.provider = nil
+	if _, _, err := env.svc.Begin(ctx); appErrorCode(err) != errors.ErrResourceNotFound {
+		t.Fatalf("expected single sign-on to be off without a provider, got %v", err)
+	}
+}
+
+func TestOIDCGroupRoles(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.userRepo.members = append(env.userRepo.members, &models.ProjectMember{ProjectID: 3, UserID: 1, Role: models.RoleOwner})
+	env.users.projectRepo.(*fakeProjectRepo).projects[4] = &models.Project{ID: 4, Name: "Ops"}
+
+	for _, m := range []OIDCGroupMappingInput{
+		{Group: "eng", ProjectID: 3, Role: models.RoleViewer},
+		{Group: "eng-leads", ProjectID: 3, Role: models.RoleMaintainer},
+		{Group: "sre", ProjectID: 4, Role: models.RoleOwner},
+	} {
+		if _, err := env.svc.CreateGroupMapping(&m); err != nil {
+			t.Fatalf("CreateGroupMapping: %v", err)
+		}
+	}
+	if _, err := env.svc.CreateGroupMapping(&OIDCGroupMappingInput{Group: "eng", ProjectID: 3, Role: models.RoleOwner}); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected a duplicate mapping to be rejected, got %v", err)
+	}
+	if _, err := env.svc.CreateGroupMapping(&OIDCGroupMappingInput{Group: "eng", ProjectID: 99, Role: models.RoleViewer}); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected an unknown project to be rejected, got %v", err)
+	}
+
+	groups := func(g ...string) map[string]interface{} {
+		return map[string]interface{}{"groups": g}
+	}
+	carol := oidctest.User{Subject: "carol-sub", Email: "carol@example.com", EmailVerified: true, Claims: groups("eng", "eng-leads", "sre")}
+	env.provider.AddUser(carol)
+	user, err := env.signIn(t, "carol-sub")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	roleIn := func(projectID uint) string {
+		role, _ := env.users.ProjectRole(user, projectID)
+		return role
+	}
+	if roleIn(3) != models.RoleMaintainer || roleIn(4) != models.RoleOwner || len(user.Memberships) != 2 {
+		t.Fatalf("expected the highest mapped role in each project, got %+v", user.Memberships)
+	}
+
+	// Leaving groups takes the synced roles away, but not the last owner's
+	carol.Claims = groups("eng")
+	env.provider.AddUser(carol)
+	if _, err := env.signIn(t, "carol-sub"); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if roleIn(3) != models.RoleViewer || roleIn(4) != models.RoleOwner {
+		t.Fatalf("expected viewer in 3 and to stay the last owner of 4, got %q and %q", roleIn(3), roleIn(4))
+	}
+
+	// A role set by hand wins over the groups
+	if _, err := env.users.SetMember(3, user.ID, models.RoleOwner); err != nil {
+		t.Fatalf("SetMember: %v", err)
+	}
+	carol.Claims = nil
+	env.provider.AddUser(carol)
+	if _, err := env.signIn(t, "carol-sub"); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if roleIn(3) != models.RoleOwner {
+		t.Fatalf("expected the role set by hand to stay, got %q", roleIn(3))
+	}
+
+	if err := env.svc.DeleteGroupMapping(1); err != nil {
+		t.Fatalf("DeleteGroupMapping: %v", err)
+	}
+	if err := env.svc.DeleteGroupMapping(1); appErrorCode(err) != errors.ErrResourceNotFound {
+		t.Fatalf("expected a deleted mapping to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+)
+
+const (
+	// apiKeyPrefixLen is how much of a key is kept in the clear to tell
+	// keys apart, e.g. "sk_proj_3f9c".
+	apiKeyPrefixLen = 12
+	// apiKeyTouchInterval limits last-used updates to one per key per minute.
+	apiKeyTouchInterval = time.Minute
+	// maxAPIKeyRotationGrace is the longest the old secret may keep working
+	// after a rotation.
+	maxAPIKeyRotationGrace = 7 * 24 * time.Hour
+	// defaultAPIKeyName names the key a project is created with.
+	defaultAPIKeyName = "Default"
+)
+
+// IProjectKeyService manages a project's API keys and verifies the keys
+// sent in X-Project-Key.
+type IProjectKeyService interface {
+	GetKeys(projectID uint) ([]models.ProjectAPIKey, error)
+	// CreateProject creates the project with a "Default" key that has every
+	// scope, in one transaction, and returns the key's secret.
+	CreateProject(project *models.Project, createdBy uint) (*models.Project, *models.ProjectAPIKey, string, error)
+	// CreateKey returns the key and its secret. Only a hash of the secret is
+	// stored, so it can't be shown again.
+	CreateKey(projectID uint, input *ProjectKeyInput, createdBy uint) (*models.ProjectAPIKey, string, error)
+	// UpdateKey changes the key's name, scopes or expiry; its secret stays.
+	UpdateKey(projectID, id uint, input *ProjectKeyInput) (*models.ProjectAPIKey, error)
+	// RotateKey issues a new secret with the key's name, scopes and expiry,
+	// as a new key. The old secret keeps working for grace, or stops at once
+	// when grace is 0.
+	RotateKey(projectID, id uint, grace time.Duration, rotatedBy uint) (*models.ProjectAPIKey, string, error)
+	RevokeKey(projectID, id uint) error
+	// VerifyKey returns the project's active key with the secret and
+	// records its use from ip.
+	VerifyKey(projectID uint, secret, ip string) (*models.ProjectAPIKey, error)
+}
+
+// ProjectKeyInput holds the writable fields of a key. Nil fields are left
+// unchanged on update.
+type ProjectKeyInput struct {
+	Name      *string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+type ProjectKeyService struct {
+	repo        repositories.IProjectAPIKeyRepository
+	projectRepo repositories.IProjectRepository
+	now         func() time.Time
+}
+
+func NewProjectKeyService(repo repositories.IProjectAPIKeyRepository, projectRepo repositories.IProjectRepository) *ProjectKeyService {
+	return &ProjectKeyService{
+		repo:        repo,
+		projectRepo: projectRepo,
+		now:         time.Now,
+	}
+}
+
+func (s *ProjectKeyService) GetKeys(projectID uint) ([]models.ProjectAPIKey, error) {
+	keys, err := s.repo.GetByProject(projectID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return keys, nil
+}
+
+func (s *ProjectKeyService) CreateProject(project *models.Project, createdBy uint) (*models.Project, *models.ProjectAPIKey, string, error) {
+	key := &models.ProjectAPIKey{Name: defaultAPIKeyName, Scopes: models.APIKeyScopes, CreatedBy: createdBy}
+	secret := s.newSecret(key)
+	if err := s.repo.CreateWithProject(project, key); err != nil {
+		return nil, nil, "", errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return project, key, secret, nil
+}
+
+func (s *ProjectKeyService) CreateKey(projectID uint, input *ProjectKeyInput, createdBy uint) (*models.ProjectAPIKey, string, error) {
+	if input.Name == nil || input.Scopes == nil {
+		return nil, "", errors.New(errors.ErrInvalidData, "name and scopes are required")
+	}
+	if _, err := s.projectRepo.GetByID(projectID); err != nil {
+		return nil, "", errors.New(errors.ErrResourceNotFound, "project not found")
+	}
+
+	key := &models.ProjectAPIKey{ProjectID: projectID, CreatedBy: createdBy}
+	if err := s.applyKeyInput(key, input); err != nil {
+		return nil, "", err
+	}
+	secret := s.newSecret(key)
+	if err := s.repo.Save(key); err != nil {
+		return nil, "", errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return key, secret, nil
+}
+
+func (s *ProjectKeyService) UpdateKey(projectID, id uint, input *ProjectKeyInput) (*models.ProjectAPIKey, error) {
+	key, err := s.getKey(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, errors.New(errors.ErrInvalidData, "the key was revoked")
+	}
+	if err := s.applyKeyInput(key, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(key); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return key, nil
+}
+
+func (s *ProjectKeyService) RotateKey(projectID, id uint, grace time.Duration, rotatedBy uint) (*models.ProjectAPIKey, string, error) {
+	if grace < 0 || grace > maxAPIKeyRotationGrace {
+		return nil, "", errors.New(errors.ErrInvalidData, fmt.Sprintf("the grace period must be between 0 and %d minutes", int(maxAPIKeyRotationGrace.Minutes())))
+	}
+	old, err := s.getKey(projectID, id)
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.now()
+	if !old.Active(now) {
+		return nil, "", errors.New(errors.ErrInvalidData, "only active keys can be rotated")
+	}
+
+	next := &models.ProjectAPIKey{
+		ProjectID: old.ProjectID,
+		Name:      old.Name,
+		Scopes:    old.Scopes,
+		ExpiresAt: old.ExpiresAt,
+		CreatedBy: rotatedBy,
+	}
+	secret := s.newSecret(next)
+	oldExpiresAt := now.Add(grace)
+	if old.ExpiresAt != nil && old.ExpiresAt.Before(oldExpiresAt) {
+		oldExpiresAt = *old.ExpiresAt
+	}
+	if err := s.repo.Rotate(old, next, oldExpiresAt); err != nil {
+		return nil, "", errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	logger.Infof("[ProjectKey] project_id=%d key_id=%d rotated to key_id=%d, old secret works until %s", projectID, old.ID, next.ID, oldExpiresAt.UTC().Format(time.RFC3339))
+	return next, secret, nil
+}
+
+func (s *ProjectKeyService) RevokeKey(projectID, id uint) error {
+	key, err := s.getKey(projectID, id)
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	now := s.now()
+	key.RevokedAt = &now
+	if err := s.repo.Save(key); err != nil {
+		return errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return nil
+}
+
+func (s *ProjectKeyService) VerifyKey(projectID uint, secret, ip string) (*models.ProjectAPIKey, error) {
+	key, err := s.repo.GetByHash(projectID, hashToken(secret))
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	now := s.now()
+	if key == nil || !key.Active(now) {
+		return nil, errors.New(errors.ErrAuthUnauthorized, "Invalid or expired project key")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval || key.LastUsedIP != ip {
+		if err := s.repo.Touch(key.ID, now, truncateText(ip, 60)); err != nil {
+			logger.Warnf("[ProjectKey] key_id=%d failed to record its use: %v", key.ID, err)
+		}
+		key.LastUsedAt, key.LastUsedIP = &now, ip
+		if key.MustRotate {
+			logger.Warnf("[ProjectKey] project_id=%d key_id=%d was migrated from the old project secret and must be rotated", projectID, key.ID)
+		}
+	}
+	return key, nil
+}
+
+func (s *ProjectKeyService) getKey(projectID, id uint) (*models.ProjectAPIKey, error) {
+	key, err := s.repo.GetByID(id)
+	if err != nil || key.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "API key not found")
+	}
+	return key, nil
+}
+
+// newSecret generates the key's secret and sets its hash and prefix.
+func (s *ProjectKeyService) newSecret(key *models.ProjectAPIKey) string {
+	secret := "sk_proj_" + randomHex(24)
+	key.KeyHash = hashToken(secret)
+	key.Prefix = secret[:apiKeyPrefixLen]
+	return secret
+}
+
+func (s *ProjectKeyService) applyKeyInput(key *models.ProjectAPIKey, input *ProjectKeyInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" || len(name) > 100 {
+			return errors.New(errors.ErrInvalidData, "name is required and must be at most 100 characters")
+		}
+		key.Name = name
+	}
+	if input.Scopes != nil {
+		if len(input.Scopes) == 0 {
+			return errors.New(errors.ErrInvalidData, "a key needs at least one scope")
+		}
+		scopes := make([]string, 0, len(input.Scopes))
+		for _, scope := range input.Scopes {
+			if !slices.Contains(models.APIKeyScopes, scope) {
+				return errors.New(errors.ErrInvalidData, fmt.Sprintf("unknown scope %q; use %s", scope, strings.Join(models.APIKeyScopes, ", ")))
+			}
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		key.Scopes = scopes
+	}
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(s.now()) {
+			return errors.New(errors.ErrInvalidData, "expiresAt must be in the future")
+		}
+		expiresAt := *input.ExpiresAt
+		key.ExpiresAt = &expiresAt
+	}
+	return nil
+}
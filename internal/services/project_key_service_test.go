@@ -0,0 +1,276 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type fakeProjectKeyRepo struct {
+	repositories.IProjectAPIKeyRepository
+	keys     map[uint]*models.ProjectAPIKey
+	touches  int
+	projects map[uint]*models.Project
+	failKey  bool
+}
+
+func (r *fakeProjectKeyRepo) GetByID(id uint) (*models.ProjectAPIKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (r *fakeProjectKeyRepo) GetByHash(projectID uint, keyHash string) (*models.ProjectAPIKey, error) {
+	for _, key := range r.keys {
+		if key.ProjectID == projectID && key.KeyHash == keyHash {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeProjectKeyRepo) Save(key *models.ProjectAPIKey) error {
+	if key.ID == 0 {
+		key.ID = uint(len(r.keys) + 1)
+	}
+	copied := *key
+	r.keys[key.ID] = &copied
+	return nil
+}
+
+// CreateWithProject keeps the project only if the key could be stored too.
+func (r *fakeProjectKeyRepo) CreateWithProject(project *models.Project, key *models.ProjectAPIKey) error {
+	if r.failKey {
+		return gorm.ErrInvalidData
+	}
+	project.ID = len(r.projects) + 1
+	r.projects[uint(project.ID)] = project
+	key.ProjectID = uint(project.ID)
+	return r.Save(key)
+}
+
+func (r *fakeProjectKeyRepo) Rotate(old *models.ProjectAPIKey, next *models.ProjectAPIKey, oldExpiresAt time.Time) error {
+	if err := r.Save(next); err != nil {
+		return err
+	}
+	r.keys[old.ID].ExpiresAt = &oldExpiresAt
+	return nil
+}
+
+func (r *fakeProjectKeyRepo) Touch(id uint, at time.Time, ip string) error {
+	r.touches++
+	r.keys[id].LastUsedAt, r.keys[id].LastUsedIP = &at, ip
+	return nil
+}
+
+func newProjectKeyTestEnv() (*ProjectKeyService, *fakeProjectKeyRepo, *time.Time) {
+	repo := &fakeProjectKeyRepo{keys: map[uint]*models.ProjectAPIKey{}, projects: map[uint]*models.Project{}}
+	projects := &fakeProjectRepo{projects: map[uint]*models.Project{3: {ID: 3, Name: "Bots"}}}
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc := NewProjectKeyService(repo, projects)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func TestProjectKeyCreateAndVerify(t *testing.T) {
+	svc, repo, now := newProjectKeyTestEnv()
+
+	key, secret, err := svc.CreateKey(3, &ProjectKeyInput{
+		Name:   ptr(" CI "),
+		Scopes: []string{models.ScopeSchedulesRead, models.ScopeCVEScan, models.ScopeSchedulesRead},
+	}, 1)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if !strings.HasPrefix(secret, "sk_proj_") || key.Prefix != secret[:12] || key.Name != "CI" {
+		t.Fatalf("unexpected key %+v with secret %q", key, secret)
+	}
+	if key.KeyHash == secret || len(key.Scopes) != 2 {
+		t.Fatalf("expected a hashed key with deduplicated scopes, got %+v", key)
+	}
+
+	verified, err := svc.VerifyKey(3, secret, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("VerifyKey: %v", err)
+	}
+	if !verified.HasScope(models.ScopeCVEScan) || verified.HasScope(models.ScopeSchedulesWrite) {
+		t.Fatalf("unexpected scopes %v", verified.Scopes)
+	}
+	if repo.keys[key.ID].LastUsedIP != "10.0.0.1" {
+		t.Fatal("expected the use to be recorded")
+	}
+
+	// Uses are recorded once a minute, or when the IP changes
+	svc.VerifyKey(3, secret, "10.0.0.1")
+	if repo.touches != 1 {
+		t.Fatalf("touches = %d, want 1", repo.touches)
+	}
+	svc.VerifyKey(3, secret, "10.0.0.2")
+	*now = now.Add(time.Minute)
+	svc.VerifyKey(3, secret, "10.0.0.2")
+	if repo.touches != 3 {
+		t.Fatalf("touches = %d, want 3", repo.touches)
+	}
+
+	if _, err := svc.VerifyKey(4, secret, "10.0.0.1"); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected the key to be refused for another project, got %v", err)
+	}
+	if _, err := svc.VerifyKey(3, secret+"0", "10.0.0.1"); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected a wrong secret to be refused, got %v", err)
+	}
+}
+
+func TestProjectKeyCreateProject(t *testing.T) {
+	svc, repo, _ := newProjectKeyTestEnv()
+
+	project, key, secret, err := svc.CreateProject(&models.Project{Name: "Alerts"}, 2)
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if key.ProjectID != uint(project.ID) || key.Name != "Default" || len(key.Scopes) != len(models.APIKeyScopes) || key.CreatedBy != 2 {
+		t.Fatalf("unexpected default key %+v", key)
+	}
+	if !strings.HasPrefix(secret, key.Prefix) || key.KeyHash != hashToken(secret) {
+		t.Fatalf("secret %q doesn't belong to the key", secret)
+	}
+
+	repo.failKey = true
+	if _, _, _, err := svc.CreateProject(&models.Project{Name: "Broken"}, 2); appErrorCode(err) != errors.ErrDatabaseInsert {
+		t.Fatalf("expected the failed insert to be reported, got %v", err)
+	}
+	if len(repo.projects) != 1 || len(repo.keys) != 1 {
+		t.Fatalf("expected nothing stored for the failed create, got %d projects and %d keys", len(repo.projects), len(repo.keys))
+	}
+}
+
+func TestProjectKeyValidation(t *testing.T) {
+	svc, _, now := newProjectKeyTestEnv()
+	past := now.Add(-time.Hour)
+
+	for _, input := range []*ProjectKeyInput{
+		{Name: ptr("CI")},
+		{Name: ptr(" "), Scopes: []string{models.ScopeCVERead}},
+		{Name: ptr("CI"), Scopes: []string{}},
+		{Name: ptr("CI"), Scopes: []string{"admin"}},
+		{Name: ptr("CI"), Scopes: []string{models.ScopeCVERead}, ExpiresAt: &past},
+	} {
+		if _, _, err := svc.CreateKey(3, input, 1); appErrorCode(err) != errors.ErrInvalidData {
+			t.Fatalf("expected %+v to be refused, got %v", input, err)
+		}
+	}
+	if _, _, err := svc.CreateKey(9, &ProjectKeyInput{Name: ptr("CI"), Scopes: []string{models.ScopeCVERead}}, 1); appErrorCode(err) != errors.ErrResourceNotFound {
+		t.Fatalf("expected an unknown project to be refused, got %v", err)
+	}
+}
+
+func TestProjectKeyExpiryAndRevoke(t *testing.T) {
+	svc, _, now := newProjectKeyTestEnv()
+	expiresAt := now.Add(24 * time.Hour)
+
+	key, secret, err := svc.CreateKey(3, &ProjectKeyInput{Name: ptr("CI"), Scopes: []string{models.ScopeCVERead}, ExpiresAt: &expiresAt}, 1)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	*now = expiresAt
+	if _, err := svc.VerifyKey(3, secret, ""); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected an expired key to be refused, got %v", err)
+	}
+
+	// Extending the expiry keeps the secret
+	later := now.Add(24 * time.Hour)
+	if _, err := svc.UpdateKey(3, key.ID, &ProjectKeyInput{ExpiresAt: &later}); err != nil {
+		t.Fatalf("UpdateKey: %v", err)
+	}
+	if _, err := svc.VerifyKey(3, secret, ""); err != nil {
+		t.Fatalf("expected the extended key to work, got %v", err)
+	}
+
+	if err := svc.RevokeKey(3, key.ID); err != nil {
+		t.Fatalf("RevokeKey: %v", err)
+	}
+	if _, err := svc.VerifyKey(3, secret, ""); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected a revoked key to be refused, got %v", err)
+	}
+	if _, err := svc.UpdateKey(3, key.ID, &ProjectKeyInput{Name: ptr("Other")}); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected a revoked key not to be updated, got %v", err)
+	}
+	if err := svc.RevokeKey(4, key.ID); appErrorCode(err) != errors.ErrResourceNotFound {
+		t.Fatalf("expected another project's key to be hidden, got %v", err)
+	}
+}
+
+func TestProjectKeyRotation(t *testing.T) {
+	svc, _, now := newProjectKeyTestEnv()
+
+	old, oldSecret, err := svc.CreateKey(3, &ProjectKeyInput{Name: ptr("CI"), Scopes: []string{models.ScopeSchedulesWrite}}, 1)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if _, _, err := svc.RotateKey(3, old.ID, 8*24*time.Hour, 2); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected a grace period over 7 days to be refused, got %v", err)
+	}
+
+	next, nextSecret, err := svc.RotateKey(3, old.ID, time.Hour, 2)
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if next.ID == old.ID || nextSecret == oldSecret || next.Name != "CI" || !next.HasScope(models.ScopeSchedulesWrite) || next.CreatedBy != 2 {
+		t.Fatalf("unexpected rotated key %+v", next)
+	}
+
+	// Both secrets work during the grace period
+	for _, secret := range []string{oldSecret, nextSecret} {
+		if _, err := svc.VerifyKey(3, secret, ""); err != nil {
+			t.Fatalf("expected %q to work during the grace period, got %v", secret, err)
+		}
+	}
+	*now = now.Add(time.Hour)
+	if _, err := svc.VerifyKey(3, oldSecret, ""); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected the old secret to stop after the grace period, got %v", err)
+	}
+	if _, err := svc.VerifyKey(3, nextSecret, ""); err != nil {
+		t.Fatalf("expected the new secret to work, got %v", err)
+	}
+	if _, _, err := svc.RotateKey(3, old.ID, 0, 2); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected an expired key not to be rotated, got %v", err)
+	}
+
+	// Without a grace period the old secret stops at once
+	_, latestSecret, err := svc.RotateKey(3, next.ID, 0, 2)
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if _, err := svc.VerifyKey(3, nextSecret, ""); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected the rotated secret to stop at once, got %v", err)
+	}
+	if _, err := svc.VerifyKey(3, latestSecret, ""); err != nil {
+		t.Fatalf("expected the latest secret to work, got %v", err)
+	}
+}
+
+func TestProjectKeyMigratedMustRotate(t *testing.T) {
+	svc, repo, _ := newProjectKeyTestEnv()
+	repo.keys[1] = &models.ProjectAPIKey{ID: 1, ProjectID: 3, Name: "Default", KeyHash: hashToken("hunter2"), Scopes: models.APIKeyScopes, MustRotate: true}
+
+	key, err := svc.VerifyKey(3, "hunter2", "")
+	if err != nil || !key.MustRotate {
+		t.Fatalf("expected the migrated key to work and be flagged, got %+v, %v", key, err)
+	}
+
+	next, _, err := svc.RotateKey(3, 1, 0, 2)
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if next.MustRotate || !strings.HasPrefix(next.Prefix, "sk_proj_") {
+		t.Fatalf("expected a generated key without the flag, got %+v", next)
+	}
+}
@@ -8,12 +8,13 @@ import (
 
 type IProjectService interface {
 	GetAll() (*[]models.Project, error)
-	GetAllV2(status string, paging *utils.Paging) ([]models.Project, int64, error)
+	// GetAllV2 lists the projects userID is a member of, or every project when
+	// userID is 0.
+	GetAllV2(status string, userID uint, paging *utils.Paging) ([]models.Project, int64, error)
 	GetByID(id uint) (*models.Project, error)
 	Create(project *models.Project) (*models.Project, error)
 	Update(project *models.Project) (*models.Project, error)
 	Delete(id uint) error
-	ValidateSecretKey(id uint, secretKey string) (bool, error)
 }
 
 type ProjectService struct {
@@ -66,25 +67,7 @@ func (s *ProjectService) Delete(id uint) error {
 	return nil
 }
 
-// ValidateSecretKey validates if a project with the given ID has the provided secret key
-// Parameters:
-//   - id: The ID of the project to validate
-//   - secretKey: The secret key to validate against the project
-//
-// Returns:
-//   - bool: True if the secret key is valid, false otherwise
-//   - error: An error if the project wasn't found or any other database error
-func (s *ProjectService) ValidateSecretKey(id uint, secretKey string) (bool, error) {
-	project, err := s.repo.GetByID(id)
-	if err != nil {
-		return false, err
-	}
-
-	// Validate the secret key
-	return project.SecretKey == secretKey, nil
-}
-
 // GetAllV2 retrieves projects with optional status filter and pagination for the V2 API
-func (s *ProjectService) GetAllV2(status string, paging *utils.Paging) ([]models.Project, int64, error) {
-	return s.repo.GetAllV2(status, paging)
+func (s *ProjectService) GetAllV2(status string, userID uint, paging *utils.Paging) ([]models.Project, int64, error) {
+	return s.repo.GetAllV2(status, userID, paging)
 }
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+)
+
+// IReminderTaskService runs schedules whose action creates Chatwork tasks.
+type IReminderTaskService interface {
+	// CreateTasks creates the schedule's tasks and records them on the run log entry.
+	CreateTasks(schedule *models.ReminderSchedule, token string, logEntry *models.ScheduleLog) error
+	// RunFollowUps checks due task runs and nags the room about tasks still open.
+	RunFollowUps()
+}
+
+type ReminderTaskService struct {
+	logRepo repositories.IScheduleLogRepository
+	botRepo repositories.IChatworkBotRepository
+	cw      IChatworkService
+	now     func() time.Time
+}
+
+func NewReminderTaskService(logRepo repositories.IScheduleLogRepository, botRepo repositories.IChatworkBotRepository, cw IChatworkService) *ReminderTaskService {
+	return &ReminderTaskService{
+		logRepo: logRepo,
+		botRepo: botRepo,
+		cw:      cw,
+		now:     time.Now,
+	}
+}
+
+func (s *ReminderTaskService) CreateTasks(schedule *models.ReminderSchedule, token string, logEntry *models.ScheduleLog) error {
+	if len(schedule.TaskAssigneeIDs) == 0 {
+		return fmt.Errorf("task schedule has no assignees")
+	}
+
+	now := s.now()
+	input := chatwork.CreateTaskInput{ToIDs: schedule.TaskAssigneeIDs, LimitType: chatwork.LimitTypeNone}
+	var deadline time.Time
+	if schedule.TaskDeadline != "" {
+		d, err := ParseRelativeDuration(schedule.TaskDeadline)
+		if err != nil {
+			return fmt.Errorf("invalid task deadline: %w", err)
+		}
+		deadline = now.Add(d)
+		input.Limit = deadline.Unix()
+		input.LimitType = chatwork.LimitTypeTime
+		// Whole days read better as a due date than as a time of day
+		if d%(24*time.Hour) == 0 {
+			input.LimitType = chatwork.LimitTypeDate
+		}
+	}
+	input.Body = renderTaskBody(schedule, now, deadline)
+
+	ids, err := s.cw.CreateTasks(token, schedule.ChatworkRoomID, input)
+	if err != nil {
+		return err
+	}
+
+	logEntry.TaskIDs = ids
+	logEntry.TaskRoomID = schedule.ChatworkRoomID
+	if schedule.TaskFollowUp != "" {
+		d, err := ParseRelativeDuration(schedule.TaskFollowUp)
+		if err != nil {
+			logger.Warnf("[Reminder #%d] Invalid task follow-up %q, skipping: %v", schedule.ID, schedule.TaskFollowUp, err)
+			return nil
+		}
+		at := now.Add(d)
+		logEntry.FollowUpAt = &at
+		logEntry.FollowUpStatus = models.FollowUpPending
+	}
+	return nil
+}
+
+func (s *ReminderTaskService) RunFollowUps() {
+	logs, err := s.logRepo.GetDueFollowUps(s.now())
+	if err != nil {
+		logger.Errorf("[Reminder] Failed to load task follow-ups: %v", err)
+		return
+	}
+	for i := range logs {
+		status := s.followUp(&logs[i])
+		if err := s.logRepo.UpdateFollowUpStatus(logs[i].ID, status); err != nil {
+			logger.Errorf("[Reminder] Failed to update follow-up of run log #%d: %v", logs[i].ID, err)
+		}
+	}
+}
+
+// followUp checks one task run and returns its new follow-up status.
+func (s *ReminderTaskService) followUp(logEntry *models.ScheduleLog) string {
+	schedule := &logEntry.Schedule
+	token := resolveScheduleToken(schedule, s.botRepo)
+	if token == "" {
+		logger.Errorf("[Reminder #%d] No Chatwork token to follow up run log #%d", logEntry.ScheduleID, logEntry.ID)
+		return models.FollowUpFailed
+	}
+
+	var open []chatwork.Task
+	for _, id := range logEntry.TaskIDs {
+		task, err := s.cw.GetTask(token, logEntry.TaskRoomID, id)
+		if chatwork.IsNotFound(err) {
+			continue // deleted in Chatwork; nothing to chase
+		}
+		if err != nil {
+			logger.Errorf("[Reminder #%d] Failed to fetch task %d: %v", logEntry.ScheduleID, id, err)
+			return models.FollowUpFailed
+		}
+		if task.Status == chatwork.TaskStatusOpen {
+			open = append(open, *task)
+		}
+	}
+	if len(open) == 0 {
+		return models.FollowUpDone
+	}
+
+	for _, body := range buildTaskNag(schedule, open).Split(chatwork.MaxMessageLength) {
+		if err := s.cw.SendMessage(token, logEntry.TaskRoomID, body); err != nil {
+			logger.Errorf("[Reminder #%d] Failed to send follow-up for run log #%d: %v", logEntry.ScheduleID, logEntry.ID, err)
+			return models.FollowUpFailed
+		}
+	}
+	logger.Infof("[Reminder #%d] Reminded %d open task(s) from run log #%d", logEntry.ScheduleID, len(open), logEntry.ID)
+	return models.FollowUpNagged
+}
+
+// buildTaskNag mentions the assignee of every open task.
+func buildTaskNag(schedule *models.ReminderSchedule, open []chatwork.Task) *chatwork.Builder {
+	return chatwork.NewBuilder().Info(fmt.Sprintf("Still open: %s", schedule.Name), func(info *chatwork.Builder) {
+		for _, task := range open {
+			info.To(task.Account.AccountID, task.Account.Name)
+			line := firstLine(task.Body)
+			if task.LimitTime > 0 {
+				line += fmt.Sprintf(" (due %s)", time.Unix(task.LimitTime, 0).UTC().Format("2006-01-02 15:04 UTC"))
+			}
+			info.Text(line)
+		}
+	})
+}
+
+// renderTaskBody fills the {{schedule}}, {{date}} and {{deadline}} placeholders of the body template.
+func renderTaskBody(schedule *models.ReminderSchedule, now, deadline time.Time) string {
+	deadlineText := "none"
+	if !deadline.IsZero() {
+		deadlineText = deadline.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	body := strings.NewReplacer(
+		"{{schedule}}", schedule.Name,
+		"{{date}}", now.UTC().Format("2006-01-02"),
+		"{{deadline}}", deadlineText,
+	).Replace(schedule.Message)
+	if strings.TrimSpace(body) == "" {
+		body = schedule.Name
+	}
+	return body
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// ParseRelativeDuration parses offsets such as "30m", "4h", "3d" or "1w".
+// Go durations like "1h30m" are accepted too.
+func ParseRelativeDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+	var d time.Duration
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d = time.Duration(n) * unit
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", s)
+	}
+	return d, nil
+}
+
+// ValidateTaskAction checks the task settings of a schedule before it is saved.
+func ValidateTaskAction(schedule *models.ReminderSchedule) error {
+	switch schedule.ActionType {
+	case "", models.ScheduleActionMessage:
+		return nil
+	case models.ScheduleActionTask:
+	default:
+		return errors.New(errors.ErrInvalidData, "actionType must be message or task")
+	}
+	if schedule.ChannelID != nil {
+		return errors.New(errors.ErrInvalidData, "task actions post to a Chatwork room and cannot use channelId")
+	}
+	if len(schedule.TaskAssigneeIDs) == 0 {
+		return errors.New(errors.ErrInvalidData, "taskAssigneeIds is required for task actions")
+	}
+	for _, id := range schedule.TaskAssigneeIDs {
+		if id <= 0 {
+			return errors.New(errors.ErrInvalidData, "taskAssigneeIds must be Chatwork account IDs")
+		}
+	}
+	for field, value := range map[string]string{"taskDeadline": schedule.TaskDeadline, "taskFollowUp": schedule.TaskFollowUp} {
+		if value == "" {
+			continue
+		}
+		if _, err := ParseRelativeDuration(value); err != nil {
+			return errors.New(errors.ErrInvalidData, field+": "+err.Error())
+		}
+	}
+	return nil
+}
+
+// resolveScheduleToken returns the schedule's bot token, or its own token.
+func resolveScheduleToken(schedule *models.ReminderSchedule, botRepo repositories.IChatworkBotRepository) string {
+	if schedule.BotID != nil {
+		bot, err := botRepo.GetByID(*schedule.BotID)
+		if err == nil && bot != nil {
+			return bot.APIToken
+		}
+		logger.Errorf("[Reminder #%d] Failed to fetch bot token for BotID %d: %v", schedule.ID, *schedule.BotID, err)
+	}
+	if schedule.ChatworkToken != nil {
+		return *schedule.ChatworkToken
+	}
+	return ""
+}
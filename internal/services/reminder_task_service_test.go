@@ -0,0 +1,186 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+)
+
+type fakeScheduleLogRepo struct {
+	repositories.IScheduleLogRepository
+	due     []models.ScheduleLog
+	updates map[uint]string
+}
+
+func (r *fakeScheduleLogRepo) GetDueFollowUps(now time.Time) ([]models.ScheduleLog, error) {
+	return r.due, nil
+}
+
+func (r *fakeScheduleLogRepo) UpdateFollowUpStatus(id uint, status string) error {
+	r.updates[id] = status
+	return nil
+}
+
+func newTaskTestServer(t *testing.T) (*chatworktest.Server, *ChatworkService) {
+	t.Helper()
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("token", chatwork.Me{AccountID: 1, Name: "Bot"})
+	srv.AddRoom(chatwork.Room{RoomID: 42, Name: "Team"},
+		chatwork.Member{AccountID: 1, Role: chatwork.RoleAdmin},
+		chatwork.Member{AccountID: 2, Role: chatwork.RoleMember, Name: "Alice"},
+		chatwork.Member{AccountID: 3, Role: chatwork.RoleMember, Name: "Bob"},
+	)
+	return srv, &ChatworkService{BaseURL: srv.URL}
+}
+
+func TestReminderTaskServiceCreateTasks(t *testing.T) {
+	srv, cw := newTaskTestServer(t)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	svc := NewReminderTaskService(&fakeScheduleLogRepo{}, nil, cw)
+	svc.now = func() time.Time { return now }
+
+	schedule := &models.ReminderSchedule{
+		ID:              5,
+		Name:            "Weekly report",
+		ChatworkRoomID:  "42",
+		Message:         "Submit the report for {{date}} by {{deadline}}",
+		ActionType:      models.ScheduleActionTask,
+		TaskAssigneeIDs: []int64{2, 3},
+		TaskDeadline:    "4d",
+		TaskFollowUp:    "2d",
+	}
+	var logEntry models.ScheduleLog
+	if err := svc.CreateTasks(schedule, "token", &logEntry); err != nil {
+		t.Fatalf("CreateTasks: %v", err)
+	}
+
+	if len(logEntry.TaskIDs) != 2 || logEntry.TaskRoomID != "42" {
+		t.Fatalf("expected 2 task IDs recorded for room 42, got %+v", logEntry)
+	}
+	if logEntry.FollowUpStatus != models.FollowUpPending || !logEntry.FollowUpAt.Equal(now.Add(48*time.Hour)) {
+		t.Fatalf("unexpected follow-up: %v %v", logEntry.FollowUpStatus, logEntry.FollowUpAt)
+	}
+
+	tasks := srv.Tasks(42)
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks in Chatwork, got %d", len(tasks))
+	}
+	task := tasks[0]
+	if task.Body != "Submit the report for 2026-03-02 by 2026-03-06 09:00 UTC" {
+		t.Fatalf("unexpected task body %q", task.Body)
+	}
+	if task.LimitType != chatwork.LimitTypeDate || task.LimitTime != now.Add(96*time.Hour).Unix() {
+		t.Fatalf("unexpected deadline %s %d", task.LimitType, task.LimitTime)
+	}
+}
+
+func TestReminderTaskServiceCreateTasksFailsForNonMember(t *testing.T) {
+	_, cw := newTaskTestServer(t)
+	svc := NewReminderTaskService(&fakeScheduleLogRepo{}, nil, cw)
+
+	schedule := &models.ReminderSchedule{ChatworkRoomID: "42", ActionType: models.ScheduleActionTask, TaskAssigneeIDs: []int64{99}}
+	var logEntry models.ScheduleLog
+	if err := svc.CreateTasks(schedule, "token", &logEntry); err == nil {
+		t.Fatal("expected an error for an assignee outside the room")
+	}
+	if logEntry.TaskIDs != nil || logEntry.FollowUpStatus != "" {
+		t.Fatalf("expected nothing recorded on failure, got %+v", logEntry)
+	}
+}
+
+func TestReminderTaskServiceRunFollowUps(t *testing.T) {
+	srv, cw := newTaskTestServer(t)
+	client := chatwork.NewClient("token", chatwork.WithBaseURL(srv.URL), chatwork.WithLimiter(nil))
+	createSvc := NewReminderTaskService(&fakeScheduleLogRepo{}, nil, cw)
+
+	token := "token"
+	schedule := models.ReminderSchedule{
+		ID:              5,
+		Name:            "Weekly report",
+		ChatworkRoomID:  "42",
+		ChatworkToken:   &token,
+		Message:         "Submit the report",
+		ActionType:      models.ScheduleActionTask,
+		TaskAssigneeIDs: []int64{2, 3},
+		TaskFollowUp:    "1d",
+	}
+	var openRun, doneRun models.ScheduleLog
+	if err := createSvc.CreateTasks(&schedule, token, &openRun); err != nil {
+		t.Fatalf("CreateTasks: %v", err)
+	}
+	schedule.TaskAssigneeIDs = []int64{3}
+	if err := createSvc.CreateTasks(&schedule, token, &doneRun); err != nil {
+		t.Fatalf("CreateTasks: %v", err)
+	}
+
+	// Bob finishes both of his tasks; Alice's stays open.
+	for _, id := range []int64{openRun.TaskIDs[1], doneRun.TaskIDs[0]} {
+		if err := client.SetTaskStatus(t.Context(), 42, id, chatwork.TaskStatusDone); err != nil {
+			t.Fatalf("SetTaskStatus: %v", err)
+		}
+	}
+
+	openRun.ID, openRun.Schedule = 1, schedule
+	doneRun.ID, doneRun.Schedule = 2, schedule
+	repo := &fakeScheduleLogRepo{due: []models.ScheduleLog{openRun, doneRun}, updates: map[uint]string{}}
+	NewReminderTaskService(repo, nil, cw).RunFollowUps()
+
+	if repo.updates[1] != models.FollowUpNagged || repo.updates[2] != models.FollowUpDone {
+		t.Fatalf("unexpected follow-up statuses: %v", repo.updates)
+	}
+	messages := srv.Messages(42)
+	if len(messages) != 1 {
+		t.Fatalf("expected one nag message, got %d", len(messages))
+	}
+	body := messages[0].Body
+	if !strings.Contains(body, "[To:2]Alice") || strings.Contains(body, "[To:3]") || !strings.Contains(body, "Still open: Weekly report") {
+		t.Fatalf("unexpected nag message:\n%s", body)
+	}
+}
+
+func TestParseRelativeDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30m":   30 * time.Minute,
+		"4h":    4 * time.Hour,
+		"3d":    72 * time.Hour,
+		"1w":    7 * 24 * time.Hour,
+		"1h30m": 90 * time.Minute,
+	}
+	for in, want := range cases {
+		got, err := ParseRelativeDuration(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseRelativeDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "0d", "-1h", "tomorrow"} {
+		if _, err := ParseRelativeDuration(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestValidateTaskAction(t *testing.T) {
+	channelID := uint(1)
+	valid := models.ReminderSchedule{ActionType: models.ScheduleActionTask, TaskAssigneeIDs: []int64{2}, TaskDeadline: "2d"}
+	if err := ValidateTaskAction(&valid); err != nil {
+		t.Fatalf("expected valid schedule, got %v", err)
+	}
+
+	invalid := []models.ReminderSchedule{
+		{ActionType: "email"},
+		{ActionType: models.ScheduleActionTask},
+		{ActionType: models.ScheduleActionTask, TaskAssigneeIDs: []int64{2}, ChannelID: &channelID},
+		{ActionType: models.ScheduleActionTask, TaskAssigneeIDs: []int64{2}, TaskFollowUp: "soon"},
+	}
+	for i := range invalid {
+		if err := ValidateTaskAction(&invalid[i]); err == nil {
+			t.Fatalf("expected validation error for case %d", i)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+)
+
+const (
+	defaultSessionTTL = 30 * 24 * time.Hour
+	// sessionTouchInterval limits how often a request records LastUsedAt.
+	sessionTouchInterval = time.Minute
+)
+
+// ISessionService keeps track of sign-in sessions. Access tokens name their
+// session and are refused once it is revoked; refresh tokens are rotated on
+// every use, and reusing a rotated one revokes the session.
+type ISessionService interface {
+	// CreateSession starts a session for a user who just signed in and
+	// returns it with its first refresh token.
+	CreateSession(user *models.User, userAgent, ipAddress string) (*models.UserSession, string, error)
+	// Refresh exchanges a refresh token for the next one of the same session.
+	Refresh(refreshToken string) (*models.UserSession, string, error)
+	// Authenticate returns the user an access token was issued to, if its
+	// session is still active and the user still enabled.
+	Authenticate(userID, sessionID uint) (*models.User, error)
+
+	GetActiveSessions(userID uint, paging *utils.Paging) ([]models.UserSession, int64, error)
+	// Revoke ends one session. userID 0 ends anyone's, otherwise the session
+	// must be theirs.
+	Revoke(sessionID, userID uint, reason string) error
+	// RevokeAll ends every active session of the user.
+	RevokeAll(userID uint, reason string) (int64, error)
+}
+
+type SessionService struct {
+	repo  repositories.ISessionRepository
+	users IUserService
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// NewSessionService reads the session lifetime from REFRESH_TOKEN_TTL
+// (a Go duration, 720h by default).
+func NewSessionService(repo repositories.ISessionRepository, users IUserService) *SessionService {
+	ttl := defaultSessionTTL
+	if raw := utils.GetEnv("REFRESH_TOKEN_TTL", ""); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			ttl = d
+		} else {
+			logger.Warnf("[Session] Invalid REFRESH_TOKEN_TTL %q, using %s", raw, defaultSessionTTL)
+		}
+	}
+	return &SessionService{
+		repo:  repo,
+		users: users,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+func (s *SessionService) CreateSession(user *models.User, userAgent, ipAddress string) (*models.UserSession, string, error) {
+	now := s.now()
+	session := &models.UserSession{
+		UserID:     user.ID,
+		UserAgent:  truncateText(userAgent, 250),
+		IPAddress:  truncateText(ipAddress, 60),
+		ExpiresAt:  now.Add(s.ttl),
+		LastUsedAt: &now,
+	}
+	token := "rt_" + randomHex(32)
+	if err := s.repo.CreateSession(session, &models.RefreshToken{TokenHash: hashToken(token)}); err != nil {
+		return nil, "", errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return session, token, nil
+}
+
+func (s *SessionService) Refresh(refreshToken string) (*models.UserSession, string, error) {
+	invalid := errors.New(errors.ErrAuthUnauthorized, "Invalid or expired refresh token")
+
+	old, err := s.repo.GetRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		return nil, "", errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if old == nil {
+		return nil, "", invalid
+	}
+	session, err := s.repo.GetSession(old.SessionID)
+	if err != nil {
+		return nil, "", invalid
+	}
+	now := s.now()
+	if !session.Active(now) {
+		return nil, "", invalid
+	}
+	if old.UsedAt != nil {
+		s.revokeReused(session, now)
+		return nil, "", invalid
+	}
+	if _, err := s.users.GetActiveUser(session.UserID); err != nil {
+		return nil, "", invalid
+	}
+
+	token := "rt_" + randomHex(32)
+	rotated, err := s.repo.RotateRefreshToken(old, &models.RefreshToken{SessionID: session.ID, TokenHash: hashToken(token)}, now)
+	if err != nil {
+		return nil, "", errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	if !rotated {
+		// Another refresh used the token first
+		s.revokeReused(session, now)
+		return nil, "", invalid
+	}
+	session.LastUsedAt = &now
+	return session, token, nil
+}
+
+// revokeReused ends a session whose rotated refresh token came back: either
+// the client misbehaved or the token was stolen, and we can't tell which.
+func (s *SessionService) revokeReused(session *models.UserSession, now time.Time) {
+	logger.Warnf("[Session] session_id=%d user_id=%d refresh token reused, revoking the session", session.ID, session.UserID)
+	if err := s.repo.Revoke(session.ID, models.SessionRevokedReuse, now); err != nil {
+		logger.Errorf("[Session] session_id=%d failed to revoke: %v", session.ID, err)
+	}
+}
+
+func (s *SessionService) Authenticate(userID, sessionID uint) (*models.User, error) {
+	invalid := errors.New(errors.ErrAuthUnauthorized, "Session expired or revoked")
+	if sessionID == 0 {
+		return nil, invalid
+	}
+	session, err := s.repo.GetSession(sessionID)
+	if err != nil || session.UserID != userID {
+		return nil, invalid
+	}
+	now := s.now()
+	if !session.Active(now) {
+		return nil, invalid
+	}
+	user, err := s.users.GetActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.LastUsedAt == nil || now.Sub(*session.LastUsedAt) >= sessionTouchInterval {
+		if err := s.repo.Touch(session.ID, now); err != nil {
+			logger.Warnf("[Session] session_id=%d failed to record use: %v", session.ID, err)
+		}
+	}
+	return user, nil
+}
+
+func (s *SessionService) GetActiveSessions(userID uint, paging *utils.Paging) ([]models.UserSession, int64, error) {
+	sessions, total, err := s.repo.GetActiveSessions(userID, s.now(), paging)
+	if err != nil {
+		return nil, 0, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return sessions, total, nil
+}
+
+func (s *SessionService) Revoke(sessionID, userID uint, reason string) error {
+	session, err := s.repo.GetSession(sessionID)
+	if err != nil || (userID != 0 && session.UserID != userID) {
+		return errors.New(errors.ErrResourceNotFound, "session not found")
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+	if err := s.repo.Revoke(sessionID, reason, s.now()); err != nil {
+		return errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return nil
+}
+
+func (s *SessionService) RevokeAll(userID uint, reason string) (int64, error) {
+	n, err := s.repo.RevokeAll(userID, reason, s.now())
+	if err != nil {
+		return 0, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return n, nil
+}
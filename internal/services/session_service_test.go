@@ -0,0 +1,242 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type fakeSessionRepo struct {
+	repositories.ISessionRepository
+	sessions map[uint]*models.UserSession
+	tokens   []*models.RefreshToken
+}
+
+func (r *fakeSessionRepo) GetSession(id uint) (*models.UserSession, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *fakeSessionRepo) GetActiveSessions(userID uint, now time.Time, paging *utils.Paging) ([]models.UserSession, int64, error) {
+	var sessions []models.UserSession
+	for id := uint(len(r.sessions)); id >= 1; id-- {
+		if s := r.sessions[id]; s.Active(now) && (userID == 0 || s.UserID == userID) {
+			sessions = append(sessions, *s)
+		}
+	}
+	return sessions, int64(len(sessions)), nil
+}
+
+func (r *fakeSessionRepo) CreateSession(session *models.UserSession, token *models.RefreshToken) error {
+	session.ID = uint(len(r.sessions) + 1)
+	copied := *session
+	r.sessions[session.ID] = &copied
+	token.SessionID = session.ID
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeSessionRepo) Touch(id uint, at time.Time) error {
+	r.sessions[id].LastUsedAt = &at
+	return nil
+}
+
+func (r *fakeSessionRepo) Revoke(id uint, reason string, at time.Time) error {
+	if s := r.sessions[id]; s.RevokedAt == nil {
+		s.RevokedAt, s.RevokedReason = &at, reason
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeAll(userID uint, reason string, at time.Time) (int64, error) {
+	var n int64
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.Active(at) {
+			s.RevokedAt, s.RevokedReason = &at, reason
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeSessionRepo) GetRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeSessionRepo) RotateRefreshToken(old *models.RefreshToken, next *models.RefreshToken, at time.Time) (bool, error) {
+	for _, t := range r.tokens {
+		if t.TokenHash == old.TokenHash {
+			if t.UsedAt != nil {
+				return false, nil
+			}
+			t.UsedAt = &at
+		}
+	}
+	r.tokens = append(r.tokens, next)
+	r.sessions[old.SessionID].LastUsedAt = &at
+	return true, nil
+}
+
+func newSessionTestEnv(t *testing.T) (*SessionService, *fakeSessionRepo, *models.User, *time.Time) {
+	t.Helper()
+	users, _, now := newUserTestEnv(t)
+	admin, _ := users.GetUser(1)
+
+	repo := &fakeSessionRepo{sessions: map[uint]*models.UserSession{}}
+	svc := NewSessionService(repo, users)
+	svc.now = func() time.Time { return *now }
+	return svc, repo, admin, now
+}
+
+func TestSessionRefreshRotation(t *testing.T) {
+	svc, repo, admin, now := newSessionTestEnv(t)
+
+	session, first, err := svc.CreateSession(admin, "Mozilla/5.0", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if !session.ExpiresAt.Equal(now.Add(defaultSessionTTL)) || repo.tokens[0].TokenHash == first {
+		t.Fatalf("unexpected session %+v", session)
+	}
+	if user, err := svc.Authenticate(admin.ID, session.ID); err != nil || user.ID != admin.ID {
+		t.Fatalf("Authenticate: %v", err)
+	}
+
+	*now = now.Add(time.Hour)
+	refreshed, second, err := svc.Refresh(first)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if refreshed.ID != session.ID || second == first {
+		t.Fatalf("expected a new token for the same session, got session %d", refreshed.ID)
+	}
+	if !repo.sessions[session.ID].LastUsedAt.Equal(*now) {
+		t.Fatal("expected the refresh to record the session's use")
+	}
+
+	if _, third, err := svc.Refresh(second); err != nil || third == second {
+		t.Fatalf("expected the rotated token to refresh once, got %v", err)
+	}
+
+	// The first token comes back: someone kept a copy, so the session ends
+	if _, _, err := svc.Refresh(first); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected a reused token to be refused, got %v", err)
+	}
+	if s := repo.sessions[session.ID]; s.RevokedAt == nil || s.RevokedReason != models.SessionRevokedReuse {
+		t.Fatalf("expected reuse to revoke the session, got %+v", s)
+	}
+	if _, err := svc.Authenticate(admin.ID, session.ID); err == nil {
+		t.Fatal("expected the session's access tokens to stop working")
+	}
+
+	if _, _, err := svc.Refresh("rt_unknown"); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected an unknown token to be refused, got %v", err)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	svc, _, admin, now := newSessionTestEnv(t)
+
+	session, token, _ := svc.CreateSession(admin, "", "")
+	*now = now.Add(defaultSessionTTL)
+	if _, _, err := svc.Refresh(token); err == nil {
+		t.Fatal("expected an expired session not to refresh")
+	}
+	if _, err := svc.Authenticate(admin.ID, session.ID); err == nil {
+		t.Fatal("expected an expired session's access tokens to stop working")
+	}
+}
+
+func TestSessionAuthenticate(t *testing.T) {
+	svc, _, admin, _ := newSessionTestEnv(t)
+	session, _, _ := svc.CreateSession(admin, "", "")
+
+	if _, err := svc.Authenticate(admin.ID, 0); err == nil {
+		t.Fatal("expected tokens without a session to be refused")
+	}
+	if _, err := svc.Authenticate(admin.ID+1, session.ID); err == nil {
+		t.Fatal("expected a token naming another user's session to be refused")
+	}
+}
+
+func TestSessionLogout(t *testing.T) {
+	svc, _, admin, _ := newSessionTestEnv(t)
+
+	laptop, _, _ := svc.CreateSession(admin, "laptop", "")
+	phone, phoneToken, _ := svc.CreateSession(admin, "phone", "")
+	tablet, _, _ := svc.CreateSession(admin, "tablet", "")
+
+	if err := svc.Revoke(laptop.ID, admin.ID+1, models.SessionRevokedLogout); appErrorCode(err) != errors.ErrResourceNotFound {
+		t.Fatalf("expected another user's session to be hidden, got %v", err)
+	}
+	if err := svc.Revoke(laptop.ID, admin.ID, models.SessionRevokedLogout); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := svc.Authenticate(admin.ID, laptop.ID); err == nil {
+		t.Fatal("expected logout to end the session")
+	}
+	if _, err := svc.Authenticate(admin.ID, phone.ID); err != nil {
+		t.Fatalf("expected the other sessions to stay, got %v", err)
+	}
+
+	sessions, total, _ := svc.GetActiveSessions(admin.ID, &utils.Paging{Page: 1, Limit: 10})
+	if total != 2 || sessions[0].ID != tablet.ID {
+		t.Fatalf("expected the two remaining sessions newest first, got %+v", sessions)
+	}
+
+	n, err := svc.RevokeAll(admin.ID, models.SessionRevokedLogoutAll)
+	if err != nil || n != 2 {
+		t.Fatalf("expected logging out everywhere to revoke 2 sessions, got %d, %v", n, err)
+	}
+	if _, _, err := svc.Refresh(phoneToken); err == nil {
+		t.Fatal("expected a revoked session not to refresh")
+	}
+}
+
+func TestPasswordChangeRevokesSessions(t *testing.T) {
+	svc, repo, admin, _ := newSessionTestEnv(t)
+	users := svc.users.(*UserService)
+	users.SetSessionService(svc)
+
+	_, refresh, err := svc.CreateSession(admin, "Mozilla/5.0", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, _, err := svc.CreateSession(admin, "curl/8.0", "10.0.0.2"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	if _, err := users.UpdateUser(admin.ID, &UserInput{Name: ptr("Admin")}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if repo.sessions[1].RevokedAt != nil {
+		t.Fatal("expected a change without a password to keep the sessions")
+	}
+
+	if _, err := users.UpdateUser(admin.ID, &UserInput{Password: ptr("new-admin-password")}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	for id, session := range repo.sessions {
+		if session.RevokedAt == nil || session.RevokedReason != models.SessionRevokedPassword {
+			t.Fatalf("session %d: expected it revoked by the password change, got %+v", id, session)
+		}
+	}
+	if _, _, err := svc.Refresh(refresh); appErrorCode(err) != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected the old refresh token to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/constants"
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/cpe"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// TechStackInput describes a tech stack entry. Either a known Name, an explicit
+// Vendor/Product pair or a full CPE string must identify the product.
+type TechStackInput struct {
+	Name    string
+	Vendor  string
+	Product string
+	Version string
+	CPE     string
+}
+
+type ITechStackService interface {
+	GetByProjectID(projectID uint) ([]models.ProjectTechStack, error)
+	Create(projectID uint, input TechStackInput) (*models.ProjectTechStack, error)
+	Delete(id uint, projectID uint) error
+	GetFindings(projectID uint, paging *utils.Paging) ([]models.CpeFinding, int64, error)
+	// MatchRecords compares freshly crawled CVEs with every project inventory,
+	// stores new findings and alerts the affected projects.
+	MatchRecords(records []models.CveRecord)
+}
+
+type TechStackService struct {
+	repo        repositories.ITechStackRepository
+	findingRepo repositories.ICpeFindingRepository
+	recordRepo  repositories.ICveRecordRepository
+	projectRepo repositories.IProjectRepository
+	botRepo     repositories.IChatworkBotRepository
+	chatworkSvc IChatworkService
+}
+
+func NewTechStackService(
+	repo repositories.ITechStackRepository,
+	findingRepo repositories.ICpeFindingRepository,
+	recordRepo repositories.ICveRecordRepository,
+	projectRepo repositories.IProjectRepository,
+	botRepo repositories.IChatworkBotRepository,
+	chatworkSvc IChatworkService,
+) *TechStackService {
+	return &TechStackService{
+		repo:        repo,
+		findingRepo: findingRepo,
+		recordRepo:  recordRepo,
+		projectRepo: projectRepo,
+		botRepo:     botRepo,
+		chatworkSvc: chatworkSvc,
+	}
+}
+
+func (s *TechStackService) GetByProjectID(projectID uint) ([]models.ProjectTechStack, error) {
+	items, err := s.repo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return items, nil
+}
+
+// Create adds an inventory entry and records findings for CVEs already stored
+// locally. Backfilled findings are not alerted, only new crawls are.
+func (s *TechStackService) Create(projectID uint, input TechStackInput) (*models.ProjectTechStack, error) {
+	item, err := resolveTechStack(input)
+	if err != nil {
+		return nil, err
+	}
+	item.ProjectID = projectID
+
+	created, err := s.repo.Create(item)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+
+	records, err := s.recordRepo.GetByProduct(created.Vendor, created.Product)
+	if err != nil {
+		logger.Warnf("[CPE] Failed to load stored CVEs for %s: %v", created.CPE, err)
+		return created, nil
+	}
+
+	stack, err := s.repo.GetByProjectID(projectID)
+	if err != nil {
+		logger.Warnf("[CPE] Failed to load tech stack of project %d: %v", projectID, err)
+		stack = []models.ProjectTechStack{*created}
+	}
+
+	matched := 0
+	for i := range records {
+		finding := matchTechStack(created, stack, &records[i])
+		if finding == nil {
+			continue
+		}
+		now := time.Now()
+		finding.NotifiedAt = &now
+		if ok, err := s.findingRepo.CreateIfNotExists(finding); err != nil {
+			logger.Warnf("[CPE] Failed to store finding %s for tech stack %d: %v", finding.CveID, created.ID, err)
+		} else if ok {
+			matched++
+		}
+	}
+	if matched > 0 {
+		logger.Infof("[CPE] Backfilled %d findings for %s (project %d)", matched, created.CPE, projectID)
+	}
+
+	return created, nil
+}
+
+func (s *TechStackService) Delete(id uint, projectID uint) error {
+	if _, err := s.repo.GetByID(id, projectID); err != nil {
+		return errors.New(errors.ErrResourceNotFound, "tech stack not found")
+	}
+	if err := s.findingRepo.DeleteByTechStackID(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	if err := s.repo.Delete(id, projectID); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *TechStackService) GetFindings(projectID uint, paging *utils.Paging) ([]models.CpeFinding, int64, error) {
+	findings, total, err := s.findingRepo.GetByProjectID(projectID, paging)
+	if err != nil {
+		return nil, 0, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return findings, total, nil
+}
+
+func (s *TechStackService) MatchRecords(records []models.CveRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	productSet := map[string]bool{}
+	for _, r := range records {
+		for _, c := range r.Cpes {
+			if c.Vulnerable && c.Product != "" {
+				productSet[c.Product] = true
+			}
+		}
+	}
+	products := make([]string, 0, len(productSet))
+	for p := range productSet {
+		products = append(products, p)
+	}
+
+	items, err := s.repo.GetByProducts(products)
+	if err != nil {
+		logger.Errorf("[CPE] Failed to load tech stacks: %v", err)
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	stacks := map[uint][]models.ProjectTechStack{}
+	newByProject := map[uint][]models.CpeFinding{}
+	for i := range items {
+		stack, ok := stacks[items[i].ProjectID]
+		if !ok {
+			if stack, err = s.repo.GetByProjectID(items[i].ProjectID); err != nil {
+				logger.Warnf("[CPE] Failed to load tech stack of project %d: %v", items[i].ProjectID, err)
+				stack = []models.ProjectTechStack{items[i]}
+			} else {
+				stacks[items[i].ProjectID] = stack
+			}
+		}
+		for j := range records {
+			finding := matchTechStack(&items[i], stack, &records[j])
+			if finding == nil {
+				continue
+			}
+			created, err := s.findingRepo.CreateIfNotExists(finding)
+			if err != nil {
+				logger.Warnf("[CPE] Failed to store finding %s for tech stack %d: %v", finding.CveID, items[i].ID, err)
+				continue
+			}
+			if created {
+				newByProject[items[i].ProjectID] = append(newByProject[items[i].ProjectID], *finding)
+			}
+		}
+	}
+
+	for projectID, findings := range newByProject {
+		logger.Infof("[CPE] %d new findings for project %d", len(findings), projectID)
+		s.alertProject(projectID, findings)
+	}
+}
+
+// alertProject sends the new findings to the project's alert room, if configured.
+func (s *TechStackService) alertProject(projectID uint, findings []models.CpeFinding) {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		logger.Errorf("[CPE] Failed to get project %d: %v", projectID, err)
+		return
+	}
+	if project.AlertRoomID == "" || project.AlertBotID == nil {
+		logger.Infof("[CPE] Project %d has no alert room, skipping notification", projectID)
+		return
+	}
+
+	bot, err := s.botRepo.GetByID(*project.AlertBotID)
+	if err != nil || bot == nil {
+		logger.Errorf("[CPE] Failed to get alert bot %d for project %d: %v", *project.AlertBotID, projectID, err)
+		return
+	}
+
+	for _, body := range notifier.RenderChatworkParts(buildCpeFindingMessage(project, findings)) {
+		if err := s.chatworkSvc.SendMessageWithPriority(bot.APIToken, project.AlertRoomID, body, chatwork.PriorityHigh); err != nil {
+			logger.Errorf("[CPE] Failed to send alert for project %d: %v", projectID, err)
+			return
+		}
+	}
+
+	ids := make([]uint, 0, len(findings))
+	for _, f := range findings {
+		ids = append(ids, f.ID)
+	}
+	if err := s.findingRepo.MarkNotified(ids, time.Now()); err != nil {
+		logger.Warnf("[CPE] Failed to mark findings notified: %v", err)
+	}
+}
+
+// resolveTechStack fills vendor, product and CPE of an inventory entry.
+func resolveTechStack(input TechStackInput) (*models.ProjectTechStack, error) {
+	item := &models.ProjectTechStack{
+		Name:    strings.TrimSpace(input.Name),
+		Vendor:  strings.ToLower(strings.TrimSpace(input.Vendor)),
+		Product: strings.ToLower(strings.TrimSpace(input.Product)),
+		Version: strings.TrimSpace(input.Version),
+	}
+
+	switch {
+	case strings.TrimSpace(input.CPE) != "":
+		name, err := cpe.Parse(input.CPE)
+		if err != nil {
+			return nil, errors.New(errors.ErrInvalidData, err.Error())
+		}
+		item.Vendor = strings.ToLower(name.Vendor)
+		item.Product = strings.ToLower(name.Product)
+		if item.Version == "" && !cpe.IsWildcard(name.Version) {
+			item.Version = name.Version
+		}
+	case item.Vendor != "" && item.Product != "":
+	default:
+		known, ok := constants.CPEProducts[strings.ToLower(item.Name)]
+		if !ok {
+			return nil, errors.New(errors.ErrInvalidData, fmt.Sprintf("unknown tech %q: provide vendor and product or a cpe", item.Name))
+		}
+		item.Vendor = known.Vendor
+		item.Product = known.Product
+	}
+
+	if item.Name == "" {
+		item.Name = item.Product
+	}
+	item.CPE = cpe.Format(item.Vendor, item.Product, item.Version)
+	return item, nil
+}
+
+// matchTechStack returns a finding when one of the record's vulnerable CPE
+// criteria covers the tech stack product and version, or nil otherwise. The
+// criterion must sit in a configuration that holds for the project's whole
+// stack, so "application running on platform" configurations only match
+// projects that list both.
+func matchTechStack(item *models.ProjectTechStack, stack []models.ProjectTechStack, record *models.CveRecord) *models.CpeFinding {
+	for _, cfg := range cpeConfigurations(record.Cpes) {
+		if !cfg.holds(stack) {
+			continue
+		}
+		for _, n := range cfg.nodes {
+			if n.negate {
+				continue
+			}
+			for _, c := range n.cpes {
+				if !c.Vulnerable || !cpeCovers(&c, item) {
+					continue
+				}
+				return &models.CpeFinding{
+					ProjectID:       item.ProjectID,
+					TechStackID:     item.ID,
+					CveID:           record.CveID,
+					Severity:        record.Severity,
+					Score:           record.BaseScore,
+					Product:         item.Product,
+					Version:         item.Version,
+					MatchedCriteria: c.Criteria,
+					Summary:         truncateText(record.Description, 500),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// cpeNode is a node of an NVD configuration: its criteria combined with
+// operator, the result inverted when negate is set.
+type cpeNode struct {
+	operator string
+	negate   bool
+	cpes     []models.CveRecordCpe
+}
+
+// cpeConfiguration is an NVD configuration: its nodes combined with operator.
+type cpeConfiguration struct {
+	operator string
+	nodes    []*cpeNode
+}
+
+// cpeConfigurations rebuilds the configuration tree from the stored criteria,
+// keeping NVD's order.
+func cpeConfigurations(cpes []models.CveRecordCpe) []*cpeConfiguration {
+	var configs []*cpeConfiguration
+	byConfig := map[int]*cpeConfiguration{}
+	byNode := map[[2]int]*cpeNode{}
+	for _, c := range cpes {
+		cfg, ok := byConfig[c.ConfigIndex]
+		if !ok {
+			cfg = &cpeConfiguration{operator: c.ConfigOperator}
+			byConfig[c.ConfigIndex] = cfg
+			configs = append(configs, cfg)
+		}
+		key := [2]int{c.ConfigIndex, c.NodeIndex}
+		n, ok := byNode[key]
+		if !ok {
+			n = &cpeNode{operator: c.NodeOperator, negate: c.NodeNegate}
+			byNode[key] = n
+			cfg.nodes = append(cfg.nodes, n)
+		}
+		n.cpes = append(n.cpes, c)
+	}
+	return configs
+}
+
+func (cfg *cpeConfiguration) holds(stack []models.ProjectTechStack) bool {
+	all := strings.EqualFold(cfg.operator, "AND")
+	for _, n := range cfg.nodes {
+		if n.holds(stack) != all {
+			return !all
+		}
+	}
+	return all && len(cfg.nodes) > 0
+}
+
+func (n *cpeNode) holds(stack []models.ProjectTechStack) bool {
+	all := strings.EqualFold(n.operator, "AND")
+	result := all && len(n.cpes) > 0
+	for i := range n.cpes {
+		if stackCovers(stack, &n.cpes[i]) != all {
+			result = !all
+			break
+		}
+	}
+	return result != n.negate
+}
+
+// stackCovers reports whether any item of the stack is covered by the criterion.
+func stackCovers(stack []models.ProjectTechStack, c *models.CveRecordCpe) bool {
+	for i := range stack {
+		if cpeCovers(c, &stack[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// cpeCovers reports whether the criterion names the item's product and its
+// version window contains the item's version.
+func cpeCovers(c *models.CveRecordCpe, item *models.ProjectTechStack) bool {
+	if !strings.EqualFold(c.Vendor, item.Vendor) || !strings.EqualFold(c.Product, item.Product) {
+		return false
+	}
+	r := cpe.Range{
+		Exact:          c.Version,
+		StartIncluding: c.VersionStartIncluding,
+		StartExcluding: c.VersionStartExcluding,
+		EndIncluding:   c.VersionEndIncluding,
+		EndExcluding:   c.VersionEndExcluding,
+	}
+	return r.Contains(item.Version)
+}
+
+func buildCpeFindingMessage(project *models.Project, findings []models.CpeFinding) notifier.Message {
+	msg := notifier.Message{
+		Title:    fmt.Sprintf("New CVEs affecting %s (%d)", project.Name, len(findings)),
+		Severity: notifier.SeverityCritical,
+		Priority: chatwork.PriorityHigh,
+	}
+	for _, f := range findings {
+		version := f.Version
+		if version == "" {
+			version = "unknown version"
+		}
+		section := notifier.Section{
+			Title: fmt.Sprintf("%s %s (%.1f) - %s %s", f.CveID, f.Severity, f.Score, f.Product, version),
+			URL:   fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", f.CveID),
+		}
+		if f.Summary != "" {
+			section.Lines = []string{truncateText(f.Summary, 200)}
+		}
+		msg.Sections = append(msg.Sections, section)
+	}
+	return msg
+}
+
+func truncateText(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+)
+
+func TestResolveTechStack(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   TechStackInput
+		wantCPE string
+		wantErr bool
+	}{
+		{"known name", TechStackInput{Name: "Nginx", Version: "1.24.0"}, "cpe:2.3:a:f5:nginx:1.24.0:*:*:*:*:*:*:*", false},
+		{"explicit vendor product", TechStackInput{Vendor: "Apache", Product: "log4j", Version: "2.14.1"}, "cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*", false},
+		{"cpe string", TechStackInput{CPE: "cpe:2.3:a:openssl:openssl:3.0.1:*:*:*:*:*:*:*"}, "cpe:2.3:a:openssl:openssl:3.0.1:*:*:*:*:*:*:*", false},
+		{"unknown name", TechStackInput{Name: "my-internal-lib"}, "", true},
+		{"invalid cpe", TechStackInput{CPE: "cpe:/a:foo"}, "", true},
+	}
+
+	for _, tt := range tests {
+		item, err := resolveTechStack(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error, got %+v", tt.name, item)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if item.CPE != tt.wantCPE {
+			t.Fatalf("%s: expected cpe %q, got %q", tt.name, tt.wantCPE, item.CPE)
+		}
+	}
+}
+
+func TestMatchTechStack(t *testing.T) {
+	record := &models.CveRecord{
+		CveID:     "CVE-2021-44228",
+		Severity:  "CRITICAL",
+		BaseScore: 10,
+		Cpes: []models.CveRecordCpe{
+			{Criteria: "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", Vendor: "apache", Product: "log4j", Version: "*", Vulnerable: true, VersionStartIncluding: "2.0.1", VersionEndExcluding: "2.12.2"},
+			{Criteria: "cpe:2.3:o:debian:debian_linux:10.0:*:*:*:*:*:*:*", Vendor: "debian", Product: "debian_linux", Version: "10.0", Vulnerable: false},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		item    models.ProjectTechStack
+		matched bool
+	}{
+		{"inside range", models.ProjectTechStack{ID: 1, ProjectID: 7, Vendor: "apache", Product: "log4j", Version: "2.11.0"}, true},
+		{"fixed version", models.ProjectTechStack{ID: 1, ProjectID: 7, Vendor: "apache", Product: "log4j", Version: "2.12.2"}, false},
+		{"other product", models.ProjectTechStack{ID: 1, ProjectID: 7, Vendor: "apache", Product: "http_server", Version: "2.4.0"}, false},
+		{"non vulnerable criterion", models.ProjectTechStack{ID: 1, ProjectID: 7, Vendor: "debian", Product: "debian_linux", Version: "10.0"}, false},
+	}
+
+	for _, tt := range tests {
+		finding := matchTechStack(&tt.item, []models.ProjectTechStack{tt.item}, record)
+		if (finding != nil) != tt.matched {
+			t.Fatalf("%s: expected matched=%v, got %+v", tt.name, tt.matched, finding)
+		}
+		if finding != nil && (finding.ProjectID != 7 || finding.CveID != "CVE-2021-44228" || finding.TechStackID != 1) {
+			t.Fatalf("%s: unexpected finding %+v", tt.name, finding)
+		}
+	}
+}
+
+func TestMatchTechStackConfigurations(t *testing.T) {
+	// CVE-2018-15473-like: openssh is affected when running on debian 8.0,
+	// while windows_10 is affected unless the patch node matches.
+	record := &models.CveRecord{
+		CveID: "CVE-2024-0001",
+		Cpes: []models.CveRecordCpe{
+			{Criteria: "cpe:2.3:a:openbsd:openssh:*:*:*:*:*:*:*:*", Vendor: "openbsd", Product: "openssh", Version: "*", Vulnerable: true, VersionEndIncluding: "7.7", ConfigIndex: 0, ConfigOperator: "AND", NodeIndex: 0, NodeOperator: "OR"},
+			{Criteria: "cpe:2.3:o:debian:debian_linux:8.0:*:*:*:*:*:*:*", Vendor: "debian", Product: "debian_linux", Version: "8.0", Vulnerable: false, ConfigIndex: 0, ConfigOperator: "AND", NodeIndex: 1, NodeOperator: "OR"},
+			{Criteria: "cpe:2.3:o:microsoft:windows_10:*:*:*:*:*:*:*:*", Vendor: "microsoft", Product: "windows_10", Version: "*", Vulnerable: true, ConfigIndex: 1, ConfigOperator: "AND", NodeIndex: 0, NodeOperator: "OR"},
+			{Criteria: "cpe:2.3:a:microsoft:kb5000001:*:*:*:*:*:*:*:*", Vendor: "microsoft", Product: "kb5000001", Version: "*", Vulnerable: false, ConfigIndex: 1, ConfigOperator: "AND", NodeIndex: 1, NodeOperator: "OR", NodeNegate: true},
+		},
+	}
+
+	openssh := models.ProjectTechStack{ID: 1, ProjectID: 7, Vendor: "openbsd", Product: "openssh", Version: "7.4"}
+	debian8 := models.ProjectTechStack{ID: 2, ProjectID: 7, Vendor: "debian", Product: "debian_linux", Version: "8.0"}
+	debian10 := models.ProjectTechStack{ID: 3, ProjectID: 7, Vendor: "debian", Product: "debian_linux", Version: "10.0"}
+	windows := models.ProjectTechStack{ID: 4, ProjectID: 7, Vendor: "microsoft", Product: "windows_10", Version: "21h2"}
+	patch := models.ProjectTechStack{ID: 5, ProjectID: 7, Vendor: "microsoft", Product: "kb5000001"}
+
+	tests := []struct {
+		name    string
+		item    models.ProjectTechStack
+		stack   []models.ProjectTechStack
+		matched bool
+	}{
+		{"application without platform", openssh, []models.ProjectTechStack{openssh}, false},
+		{"application on platform", openssh, []models.ProjectTechStack{openssh, debian8}, true},
+		{"application on other platform", openssh, []models.ProjectTechStack{openssh, debian10}, false},
+		{"platform is not vulnerable", debian8, []models.ProjectTechStack{openssh, debian8}, false},
+		{"negated node absent", windows, []models.ProjectTechStack{windows}, true},
+		{"negated node present", windows, []models.ProjectTechStack{windows, patch}, false},
+	}
+
+	for _, tt := range tests {
+		finding := matchTechStack(&tt.item, tt.stack, record)
+		if (finding != nil) != tt.matched {
+			t.Fatalf("%s: expected matched=%v, got %+v", tt.name, tt.matched, finding)
+		}
+		if finding != nil && finding.TechStackID != tt.item.ID {
+			t.Fatalf("%s: unexpected finding %+v", tt.name, finding)
+		}
+	}
+}
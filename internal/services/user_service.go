@@ -0,0 +1,489 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+)
+
+const (
+	minPasswordLength = 8
+	invitationTTL     = 7 * 24 * time.Hour
+)
+
+// IUserService manages dashboard users, their project roles and the
+// invitations admins send to new users.
+type IUserService interface {
+	// Login checks the password of an active user and records the sign-in.
+	// Unknown emails, wrong passwords and disabled users get the same error.
+	Login(email, password string) (*models.User, error)
+	// EnsureAdmin creates an admin with the credentials while there are no
+	// users yet, so a fresh install can sign in.
+	EnsureAdmin(email, password string) error
+	// GetActiveUser returns the user a token was issued to, unless they were
+	// deleted or disabled since.
+	GetActiveUser(id uint) (*models.User, error)
+
+	GetUsers(paging *utils.Paging) ([]models.User, int64, error)
+	GetUser(id uint) (*models.User, error)
+	CreateUser(input *UserInput) (*models.User, error)
+	UpdateUser(id uint, input *UserInput) (*models.User, error)
+	// DeleteUser refuses to delete the last active admin.
+	DeleteUser(id uint) error
+
+	// ProjectRole is the user's role in the project, or "" when they aren't a
+	// member. Admins are owners of every project.
+	ProjectRole(user *models.User, projectID uint) (string, error)
+	GetMembers(projectID uint) ([]models.ProjectMember, error)
+	// SetMember adds the user to the project or changes their role.
+	SetMember(projectID, userID uint, role string) (*models.ProjectMember, error)
+	// RemoveMember refuses to remove the project's last owner.
+	RemoveMember(projectID, userID uint) error
+
+	GetInvitations(pendingOnly bool) ([]models.UserInvitation, error)
+	// CreateInvitation returns the invitation and its token. Only a hash of
+	// the token is stored, so it can't be shown again.
+	CreateInvitation(input *InvitationInput, invitedBy uint) (*models.UserInvitation, string, error)
+	RevokeInvitation(id uint) error
+	// AcceptInvitation creates the invited user with their project roles.
+	AcceptInvitation(token, name, password string) (*models.User, error)
+}
+
+// UserInput holds the writable fields of a user. Nil fields are left
+// unchanged on update.
+type UserInput struct {
+	Email    *string
+	Name     *string
+	Password *string
+	IsAdmin  *bool
+	Status   *string
+}
+
+type InvitationInput struct {
+	Email    string
+	Name     string
+	IsAdmin  bool
+	Projects []models.InvitedRole
+}
+
+type UserService struct {
+	repo        repositories.IUserRepository
+	projectRepo repositories.IProjectRepository
+	sessions    ISessionService
+	now         func() time.Time
+}
+
+func NewUserService(repo repositories.IUserRepository, projectRepo repositories.IProjectRepository) *UserService {
+	return &UserService{
+		repo:        repo,
+		projectRepo: projectRepo,
+		now:         time.Now,
+	}
+}
+
+// SetSessionService lets a password change sign the user out everywhere. The
+// session service is built from the user service, hence the setter.
+func (s *UserService) SetSessionService(sessions ISessionService) {
+	s.sessions = sessions
+}
+
+// dummyPasswordHash is compared against when the email is unknown, so a
+// failed login takes as long whether or not the account exists.
+var dummyPasswordHash = utils.HashPassword("not-a-real-password")
+
+func (s *UserService) Login(email, password string) (*models.User, error) {
+	user, err := s.repo.GetByEmail(normalizeEmail(email))
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if user == nil || user.PasswordHash == "" {
+		utils.CheckPasswordHash(password, dummyPasswordHash)
+		return nil, errors.New(errors.ErrAuthUnauthorized, "Invalid email or password")
+	}
+	if !utils.CheckPasswordHash(password, user.PasswordHash) || user.Status != models.UserActive {
+		return nil, errors.New(errors.ErrAuthUnauthorized, "Invalid email or password")
+	}
+
+	now := s.now()
+	user.LastLoginAt = &now
+	if err := s.repo.Save(user); err != nil {
+		logger.Warnf("[User] user_id=%d failed to record the sign-in: %v", user.ID, err)
+	}
+	return user, nil
+}
+
+func (s *UserService) EnsureAdmin(email, password string) error {
+	n, err := s.repo.Count()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	if email == "" || password == "" {
+		logger.Warn("[User] No users yet: set ADMIN_EMAIL and ADMIN_PASSWORD to create the first admin")
+		return nil
+	}
+
+	isAdmin := true
+	if _, err := s.CreateUser(&UserInput{Email: &email, Password: &password, IsAdmin: &isAdmin}); err != nil {
+		return err
+	}
+	logger.Infof("[User] Created the first admin %s", normalizeEmail(email))
+	return nil
+}
+
+func (s *UserService) GetActiveUser(id uint) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil || user.Status != models.UserActive {
+		return nil, errors.New(errors.ErrAuthUnauthorized, "User not found or disabled")
+	}
+	return user, nil
+}
+
+func (s *UserService) GetUsers(paging *utils.Paging) ([]models.User, int64, error) {
+	users, total, err := s.repo.GetUsers(paging)
+	if err != nil {
+		return nil, 0, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return users, total, nil
+}
+
+func (s *UserService) GetUser(id uint) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(errors.ErrResourceNotFound, "user not found")
+	}
+	return user, nil
+}
+
+func (s *UserService) CreateUser(input *UserInput) (*models.User, error) {
+	if input.Email == nil || input.Password == nil {
+		return nil, errors.New(errors.ErrInvalidData, "email and password are required")
+	}
+	user := &models.User{Status: models.UserActive}
+	if err := s.applyUserInput(user, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(user); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return user, nil
+}
+
+func (s *UserService) UpdateUser(id uint, input *UserInput) (*models.User, error) {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	wasActiveAdmin := user.IsAdmin && user.Status == models.UserActive
+	if err := s.applyUserInput(user, input); err != nil {
+		return nil, err
+	}
+	if wasActiveAdmin && (!user.IsAdmin || user.Status != models.UserActive) {
+		if err := s.keepAnAdmin(); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Save(user); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	// A new password ends the sessions (and refresh tokens) issued under the old one
+	if input.Password != nil && s.sessions != nil {
+		if _, err := s.sessions.RevokeAll(user.ID, models.SessionRevokedPassword); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+func (s *UserService) DeleteUser(id uint) error {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+	if user.IsAdmin && user.Status == models.UserActive {
+		if err := s.keepAnAdmin(); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+// keepAnAdmin refuses a change that would take away the last active admin.
+func (s *UserService) keepAnAdmin() error {
+	n, err := s.repo.CountAdmins()
+	if err != nil {
+		return errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if n <= 1 {
+		return errors.New(errors.ErrInvalidData, "the last active admin can't be removed, demoted or disabled")
+	}
+	return nil
+}
+
+func (s *UserService) applyUserInput(user *models.User, input *UserInput) error {
+	if input.Email != nil {
+		email := normalizeEmail(*input.Email)
+		if _, err := mail.ParseAddress(email); err != nil || email == "" {
+			return errors.New(errors.ErrInvalidData, "email is invalid")
+		}
+		if email != user.Email {
+			existing, err := s.repo.GetByEmail(email)
+			if err != nil {
+				return errors.New(errors.ErrDatabaseQuery, err.Error())
+			}
+			if existing != nil {
+				return errors.New(errors.ErrInvalidData, "a user with this email already exists")
+			}
+		}
+		user.Email = email
+	}
+	if input.Name != nil {
+		user.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Password != nil {
+		hash, err := hashPassword(*input.Password)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = hash
+	}
+	if input.IsAdmin != nil {
+		user.IsAdmin = *input.IsAdmin
+	}
+	if input.Status != nil {
+		if *input.Status != models.UserActive && *input.Status != models.UserDisabled {
+			return errors.New(errors.ErrInvalidData, "status must be active or disabled")
+		}
+		user.Status = *input.Status
+	}
+	return nil
+}
+
+func (s *UserService) ProjectRole(user *models.User, projectID uint) (string, error) {
+	if user.IsAdmin {
+		return models.RoleOwner, nil
+	}
+	member, err := s.repo.GetMembership(projectID, user.ID)
+	if err != nil {
+		return "", errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if member == nil {
+		return "", nil
+	}
+	return member.Role, nil
+}
+
+func (s *UserService) GetMembers(projectID uint) ([]models.ProjectMember, error) {
+	members, err := s.repo.GetMembers(projectID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return members, nil
+}
+
+func (s *UserService) SetMember(projectID, userID uint, role string) (*models.ProjectMember, error) {
+	if !slices.Contains(models.ProjectRoles, role) {
+		return nil, errors.New(errors.ErrInvalidData, "role must be owner, maintainer or viewer")
+	}
+	if _, err := s.projectRepo.GetByID(projectID); err != nil {
+		return nil, errors.New(errors.ErrResourceNotFound, "project not found")
+	}
+	if _, err := s.GetUser(userID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.GetMembership(projectID, userID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if member == nil {
+		member = &models.ProjectMember{ProjectID: projectID, UserID: userID}
+	} else if member.Role == models.RoleOwner && role != models.RoleOwner {
+		if err := s.keepAnOwner(projectID); err != nil {
+			return nil, err
+		}
+	}
+	member.Role = role
+	// A role set by hand is kept when the user's groups change
+	member.Source = ""
+	if err := s.repo.SaveMembership(member); err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return member, nil
+}
+
+func (s *UserService) RemoveMember(projectID, userID uint) error {
+	member, err := s.repo.GetMembership(projectID, userID)
+	if err != nil {
+		return errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if member == nil {
+		return errors.New(errors.ErrResourceNotFound, "member not found")
+	}
+	if member.Role == models.RoleOwner {
+		if err := s.keepAnOwner(projectID); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.DeleteMembership(projectID, userID); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+// keepAnOwner refuses a change that would leave the project without an owner.
+func (s *UserService) keepAnOwner(projectID uint) error {
+	members, err := s.repo.GetMembers(projectID)
+	if err != nil {
+		return errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	owners := 0
+	for _, m := range members {
+		if m.Role == models.RoleOwner {
+			owners++
+		}
+	}
+	if owners <= 1 {
+		return errors.New(errors.ErrInvalidData, "a project needs at least one owner")
+	}
+	return nil
+}
+
+func (s *UserService) GetInvitations(pendingOnly bool) ([]models.UserInvitation, error) {
+	invitations, err := s.repo.GetInvitations(pendingOnly)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return invitations, nil
+}
+
+func (s *UserService) CreateInvitation(input *InvitationInput, invitedBy uint) (*models.UserInvitation, string, error) {
+	email := normalizeEmail(input.Email)
+	if _, err := mail.ParseAddress(email); err != nil || email == "" {
+		return nil, "", errors.New(errors.ErrInvalidData, "email is invalid")
+	}
+	existing, err := s.repo.GetByEmail(email)
+	if err != nil {
+		return nil, "", errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if existing != nil {
+		return nil, "", errors.New(errors.ErrInvalidData, "a user with this email already exists")
+	}
+	for i, p := range input.Projects {
+		if !slices.Contains(models.ProjectRoles, p.Role) {
+			return nil, "", errors.New(errors.ErrInvalidData, fmt.Sprintf("projects[%d]: role must be owner, maintainer or viewer", i))
+		}
+		if _, err := s.projectRepo.GetByID(p.ProjectID); err != nil {
+			return nil, "", errors.New(errors.ErrInvalidData, fmt.Sprintf("projects[%d]: project %d not found", i, p.ProjectID))
+		}
+	}
+
+	token := "inv_" + randomHex(24)
+	invitation := &models.UserInvitation{
+		Email:     email,
+		Name:      strings.TrimSpace(input.Name),
+		IsAdmin:   input.IsAdmin,
+		Projects:  input.Projects,
+		TokenHash: hashToken(token),
+		InvitedBy: invitedBy,
+		ExpiresAt: s.now().Add(invitationTTL),
+	}
+	if invitation.Projects == nil {
+		invitation.Projects = []models.InvitedRole{}
+	}
+	if err := s.repo.SaveInvitation(invitation); err != nil {
+		return nil, "", errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return invitation, token, nil
+}
+
+func (s *UserService) RevokeInvitation(id uint) error {
+	invitation, err := s.repo.GetInvitation(id)
+	if err != nil {
+		return errors.New(errors.ErrResourceNotFound, "invitation not found")
+	}
+	if invitation.AcceptedAt != nil {
+		return errors.New(errors.ErrInvalidData, "the invitation was already accepted")
+	}
+	if err := s.repo.DeleteInvitation(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *UserService) AcceptInvitation(token, name, password string) (*models.User, error) {
+	invitation, err := s.repo.GetInvitationByToken(hashToken(token))
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if invitation == nil || invitation.AcceptedAt != nil || !s.now().Before(invitation.ExpiresAt) {
+		return nil, errors.New(errors.ErrInvalidData, "the invitation is invalid or has expired")
+	}
+	existing, err := s.repo.GetByEmail(invitation.Email)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	if existing != nil {
+		return nil, errors.New(errors.ErrInvalidData, "a user with this email already exists")
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(name) == "" {
+		name = invitation.Name
+	}
+	user := &models.User{
+		Email:        invitation.Email,
+		Name:         strings.TrimSpace(name),
+		PasswordHash: hash,
+		IsAdmin:      invitation.IsAdmin,
+		Status:       models.UserActive,
+	}
+	for _, p := range invitation.Projects {
+		user.Memberships = append(user.Memberships, models.ProjectMember{ProjectID: p.ProjectID, Role: p.Role})
+	}
+	now := s.now()
+	invitation.AcceptedAt = &now
+	if err := s.repo.AcceptInvitation(invitation, user); err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return user, nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength {
+		return "", errors.New(errors.ErrInvalidData, fmt.Sprintf("password must be at least %d characters", minPasswordLength))
+	}
+	hash := utils.HashPassword(password)
+	if hash == "" {
+		return "", errors.New(errors.ErrAuthPasswordHashFailed, "failed to hash the password")
+	}
+	return hash, nil
+}
+
+// hashToken is how invitation tokens are stored and looked up.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type fakeUserRepo struct {
+	repositories.IUserRepository
+	users       map[uint]*models.User
+	members     []*models.ProjectMember
+	invitations map[uint]*models.UserInvitation
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: map[uint]*models.User{}, invitations: map[uint]*models.UserInvitation{}}
+}
+
+func (r *fakeUserRepo) GetByID(id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	copied.Memberships = nil
+	for _, m := range r.members {
+		if m.UserID == id {
+			copied.Memberships = append(copied.Memberships, *m)
+		}
+	}
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) Count() (int64, error) {
+	return int64(len(r.users)), nil
+}
+
+func (r *fakeUserRepo) CountAdmins() (int64, error) {
+	var n int64
+	for _, user := range r.users {
+		if user.IsAdmin && user.Status == models.UserActive {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeUserRepo) Save(user *models.User) error {
+	if user.ID == 0 {
+		user.ID = uint(len(r.users) + 1)
+	}
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(id uint) error {
+	delete(r.users, id)
+	return nil
+}
+
+func (r *fakeUserRepo) GetMembership(projectID, userID uint) (*models.ProjectMember, error) {
+	for _, m := range r.members {
+		if m.ProjectID == projectID && m.UserID == userID {
+			copied := *m
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetMembers(projectID uint) ([]models.ProjectMember, error) {
+	var members []models.ProjectMember
+	for _, m := range r.members {
+		if m.ProjectID == projectID {
+			members = append(members, *m)
+		}
+	}
+	return members, nil
+}
+
+func (r *fakeUserRepo) SaveMembership(member *models.ProjectMember) error {
+	for _, m := range r.members {
+		if m.ProjectID == member.ProjectID && m.UserID == member.UserID {
+			m.Role, m.Source = member.Role, member.Source
+			return nil
+		}
+	}
+	copied := *member
+	r.members = append(r.members, &copied)
+	return nil
+}
+
+func (r *fakeUserRepo) DeleteMembership(projectID, userID uint) error {
+	for i, m := range r.members {
+		if m.ProjectID == projectID && m.UserID == userID {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) GetInvitationByToken(tokenHash string) (*models.UserInvitation, error) {
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) SaveInvitation(invitation *models.UserInvitation) error {
+	if invitation.ID == 0 {
+		invitation.ID = uint(len(r.invitations) + 1)
+	}
+	copied := *invitation
+	r.invitations[invitation.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) AcceptInvitation(invitation *models.UserInvitation, user *models.User) error {
+	if err := r.Save(user); err != nil {
+		return err
+	}
+	for i := range user.Memberships {
+		user.Memberships[i].UserID = user.ID
+		if err := r.SaveMembership(&user.Memberships[i]); err != nil {
+			return err
+		}
+	}
+	return r.SaveInvitation(invitation)
+}
+
+type fakeProjectRepo struct {
+	repositories.IProjectRepository
+	projects map[uint]*models.Project
+}
+
+func (r *fakeProjectRepo) GetByID(id uint) (*models.Project, error) {
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return project, nil
+}
+
+func newUserTestEnv(t *testing.T) (*UserService, *fakeUserRepo, *time.Time) {
+	t.Helper()
+	repo := newFakeUserRepo()
+	projects := &fakeProjectRepo{projects: map[uint]*models.Project{3: {ID: 3, Name: "Bots"}}}
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc := NewUserService(repo, projects)
+	svc.now = func() time.Time { return now }
+
+	if err := svc.EnsureAdmin(" Admin@Example.com ", "admin-password"); err != nil {
+		t.Fatalf("EnsureAdmin: %v", err)
+	}
+	return svc, repo, &now
+}
+
+func appErrorCode(err error) int {
+	if appErr, ok := err.(*errors.AppError); ok {
+		return appErr.Code
+	}
+	return 0
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestUserLogin(t *testing.T) {
+	svc, repo, now := newUserTestEnv(t)
+
+	user, err := svc.Login("admin@example.com", "admin-password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !user.IsAdmin || user.LastLoginAt == nil || !user.LastLoginAt.Equal(*now) {
+		t.Fatalf("expected the first admin with the sign-in recorded, got %+v", user)
+	}
+	if repo.users[user.ID].PasswordHash == "admin-password" {
+		t.Fatal("expected the password to be stored hashed")
+	}
+
+	for _, tc := range []struct{ email, password string }{
+		{"admin@example.com", "wrong-password"},
+		{"nobody@example.com", "admin-password"},
+	} {
+		if _, err := svc.Login(tc.email, tc.password); appErrorCode(err) != errors.ErrAuthUnauthorized {
+			t.Errorf("Login(%q, %q): expected unauthorized, got %v", tc.email, tc.password, err)
+		}
+	}
+
+	if err := svc.EnsureAdmin("other@example.com", "other-password"); err != nil || len(repo.users) != 1 {
+		t.Fatalf("expected EnsureAdmin to do nothing once there are users, got %v and %d users", err, len(repo.users))
+	}
+
+	disabled := models.UserDisabled
+	bob, _ := svc.CreateUser(&UserInput{Email: ptr("bob@example.com"), Password: ptr("bob-password")})
+	if _, err := svc.UpdateUser(bob.ID, &UserInput{Status: &disabled}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if _, err := svc.Login("bob@example.com", "bob-password"); err == nil {
+		t.Fatal("expected a disabled user not to sign in")
+	}
+	if _, err := svc.GetActiveUser(bob.ID); err == nil {
+		t.Fatal("expected a disabled user's tokens to stop working")
+	}
+}
+
+func TestUserKeepsAnAdmin(t *testing.T) {
+	svc, _, _ := newUserTestEnv(t)
+
+	notAdmin := false
+	if _, err := svc.UpdateUser(1, &UserInput{IsAdmin: &notAdmin}); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected demoting the last admin to fail, got %v", err)
+	}
+	if err := svc.DeleteUser(1); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected deleting the last admin to fail, got %v", err)
+	}
+
+	isAdmin := true
+	if _, err := svc.CreateUser(&UserInput{Email: ptr("second@example.com"), Password: ptr("second-password"), IsAdmin: &isAdmin}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := svc.DeleteUser(1); err != nil {
+		t.Fatalf("expected deleting an admin to work with another one left, got %v", err)
+	}
+
+	if _, err := svc.CreateUser(&UserInput{Email: ptr("SECOND@example.com"), Password: ptr("whatever-password")}); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected a duplicate email to be rejected, got %v", err)
+	}
+	if _, err := svc.CreateUser(&UserInput{Email: ptr("short@example.com"), Password: ptr("short")}); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected a short password to be rejected, got %v", err)
+	}
+}
+
+func TestProjectRoles(t *testing.T) {
+	svc, _, _ := newUserTestEnv(t)
+	admin, _ := svc.GetUser(1)
+	alice, _ := svc.CreateUser(&UserInput{Email: ptr("alice@example.com"), Password: ptr("alice-password")})
+
+	if role, _ := svc.ProjectRole(admin, 3); role != models.RoleOwner {
+		t.Fatalf("expected admins to own every project, got %q", role)
+	}
+	if role, _ := svc.ProjectRole(alice, 3); role != "" {
+		t.Fatalf("expected no role before joining, got %q", role)
+	}
+
+	if _, err := svc.SetMember(3, alice.ID, "superuser"); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected an unknown role to be rejected, got %v", err)
+	}
+	if _, err := svc.SetMember(9, alice.ID, models.RoleViewer); appErrorCode(err) != errors.ErrResourceNotFound {
+		t.Fatalf("expected an unknown project to be rejected, got %v", err)
+	}
+	if _, err := svc.SetMember(3, alice.ID, models.RoleOwner); err != nil {
+		t.Fatalf("SetMember: %v", err)
+	}
+	if role, _ := svc.ProjectRole(alice, 3); role != models.RoleOwner {
+		t.Fatalf("expected alice to own the project, got %q", role)
+	}
+
+	if _, err := svc.SetMember(3, alice.ID, models.RoleViewer); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected demoting the last owner to fail, got %v", err)
+	}
+	if err := svc.RemoveMember(3, alice.ID); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected removing the last owner to fail, got %v", err)
+	}
+
+	bob, _ := svc.CreateUser(&UserInput{Email: ptr("bob@example.com"), Password: ptr("bob-password")})
+	svc.SetMember(3, bob.ID, models.RoleOwner)
+	if _, err := svc.SetMember(3, alice.ID, models.RoleMaintainer); err != nil {
+		t.Fatalf("expected demoting an owner to work with another one left, got %v", err)
+	}
+	if role, _ := svc.ProjectRole(alice, 3); !models.RoleAtLeast(role, models.RoleViewer) || models.RoleAtLeast(role, models.RoleOwner) {
+		t.Fatalf("expected alice to be a maintainer, got %q", role)
+	}
+}
+
+func TestRoleAtLeast(t *testing.T) {
+	cases := []struct {
+		role, min string
+		want      bool
+	}{
+		{models.RoleOwner, models.RoleViewer, true},
+		{models.RoleMaintainer, models.RoleMaintainer, true},
+		{models.RoleViewer, models.RoleMaintainer, false},
+		{"", models.RoleViewer, false},
+		{"superuser", models.RoleViewer, false},
+	}
+	for _, tc := range cases {
+		if got := models.RoleAtLeast(tc.role, tc.min); got != tc.want {
+			t.Errorf("RoleAtLeast(%q, %q) = %v, want %v", tc.role, tc.min, got, tc.want)
+		}
+	}
+}
+
+func TestUserInvitations(t *testing.T) {
+	svc, repo, now := newUserTestEnv(t)
+
+	if _, _, err := svc.CreateInvitation(&InvitationInput{Email: "admin@example.com"}, 1); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected inviting an existing user to fail, got %v", err)
+	}
+	if _, _, err := svc.CreateInvitation(&InvitationInput{
+		Email: "carol@example.com", Projects: []models.InvitedRole{{ProjectID: 9, Role: models.RoleViewer}},
+	}, 1); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected an unknown project to be rejected, got %v", err)
+	}
+
+	invitation, token, err := svc.CreateInvitation(&InvitationInput{
+		Email: "Carol@example.com", Name: "Carol", Projects: []models.InvitedRole{{ProjectID: 3, Role: models.RoleMaintainer}},
+	}, 1)
+	if err != nil {
+		t.Fatalf("CreateInvitation: %v", err)
+	}
+	if invitation.Email != "carol@example.com" || invitation.TokenHash == token || invitation.InvitedBy != 1 {
+		t.Fatalf("unexpected invitation %+v", invitation)
+	}
+
+	if _, err := svc.AcceptInvitation("inv_wrong", "", "carol-password"); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected an unknown token to be rejected, got %v", err)
+	}
+
+	carol, err := svc.AcceptInvitation(token, "", "carol-password")
+	if err != nil {
+		t.Fatalf("AcceptInvitation: %v", err)
+	}
+	if carol.Name != "Carol" || carol.IsAdmin {
+		t.Fatalf("unexpected user %+v", carol)
+	}
+	if role, _ := svc.ProjectRole(carol, 3); role != models.RoleMaintainer {
+		t.Fatalf("expected the invited role, got %q", role)
+	}
+	if repo.invitations[invitation.ID].AcceptedAt == nil {
+		t.Fatal("expected the invitation to be marked accepted")
+	}
+	if _, err := svc.AcceptInvitation(token, "", "carol-password"); err == nil {
+		t.Fatal("expected an invitation to be accepted only once")
+	}
+	if _, err := svc.Login("carol@example.com", "carol-password"); err != nil {
+		t.Fatalf("expected the invited user to sign in, got %v", err)
+	}
+
+	_, expired, _ := svc.CreateInvitation(&InvitationInput{Email: "dave@example.com"}, 1)
+	*now = now.Add(invitationTTL + time.Minute)
+	if _, err := svc.AcceptInvitation(expired, "", "dave-password"); appErrorCode(err) != errors.ErrInvalidData {
+		t.Fatalf("expected an expired invitation to be rejected, got %v", err)
+	}
+}
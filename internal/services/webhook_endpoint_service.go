@@ -0,0 +1,982 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/inbound"
+	"github.com/vfa-khuongdv/golang-cms/pkg/logger"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+const (
+	// maxDeliveryPayload caps the stored request body; larger payloads are truncated.
+	maxDeliveryPayload = 64 << 10
+	// keepDeliveries is how many deliveries are kept per endpoint.
+	keepDeliveries = 200
+	// maxDedupWindow is the longest dedup window, in minutes.
+	maxDedupWindow = 24 * 60
+	// maxSummaryLength caps the one-line summary kept for buffered events.
+	maxSummaryLength = 200
+)
+
+// chatworkTagMarkup matches Chatwork tags, stripped from template output to
+// summarise it on one line.
+var chatworkTagMarkup = regexp.MustCompile(`\[/?(info|title|hr|code|qt|qtmeta|to|toall|rp|task|picon|piconname|preview|download|dtext)\b[^\]]*\]`)
+
+// WebhookEndpointInput holds the writable fields of an endpoint. Nil pointers
+// are left unchanged on update.
+type WebhookEndpointInput struct {
+	Name             *string
+	Provider         *string
+	Template         *string
+	BotID            *uint
+	RoomID           *string
+	RequireSignature *bool
+	Active           *bool
+
+	Filters            *[]models.WebhookFilter
+	MinSeverity        *string
+	DedupWindowMinutes *int
+	DedupKeys          *[]string
+	QuietStart         *string
+	QuietEnd           *string
+	QuietTimezone      *string
+
+	Alerts       *bool
+	AlertGroupBy *[]string
+}
+
+type IWebhookEndpointService interface {
+	GetByProjectID(projectID uint) ([]models.WebhookEndpoint, error)
+	GetByID(id, projectID uint) (*models.WebhookEndpoint, error)
+	// Create generates the endpoint's URL token and signing secret.
+	Create(projectID uint, input *WebhookEndpointInput) (*models.WebhookEndpoint, error)
+	Update(id, projectID uint, input *WebhookEndpointInput) (*models.WebhookEndpoint, error)
+	Delete(id, projectID uint) error
+	// RotateSecret replaces the signing secret and returns the endpoint with the new one.
+	RotateSecret(id, projectID uint) (*models.WebhookEndpoint, error)
+
+	// Receive verifies, renders and delivers one request to the endpoint with
+	// the given URL token. Every request that reaches an active endpoint is
+	// recorded, including rejected ones. A Chatwork failure is returned as a
+	// plain error alongside the failed delivery.
+	Receive(token string, header http.Header, body []byte) (*models.WebhookDelivery, error)
+
+	GetDeliveries(endpointID, projectID uint, paging *utils.Paging) ([]models.WebhookDelivery, int64, error)
+	GetDelivery(id, endpointID, projectID uint) (*models.WebhookDelivery, error)
+
+	// DryRun renders a sample payload without sending it. With an endpointID
+	// the stored provider and template are used unless input overrides them.
+	DryRun(projectID uint, endpointID *uint, input *WebhookDryRunInput) (*WebhookDryRun, error)
+
+	// FlushDue posts the repeat counts of dedup windows and the quiet hours
+	// digests that are due, then drops them. Run every minute by the cron job.
+	FlushDue() error
+}
+
+// WebhookDryRunInput is a sample request to render. Nil Provider, Template,
+// Filters and MinSeverity fall back to the endpoint's settings.
+type WebhookDryRunInput struct {
+	Provider    *string
+	Template    *string
+	Filters     *[]models.WebhookFilter
+	MinSeverity *string
+	Headers     map[string]string
+	Payload     []byte
+}
+
+// WebhookDryRun is what a request would have produced. Filtered means the
+// filters or minimum severity would have dropped it.
+type WebhookDryRun struct {
+	Provider  string            `json:"provider,omitempty"`
+	EventType string            `json:"eventType,omitempty"`
+	Severity  notifier.Severity `json:"severity"`
+	Summary   string            `json:"summary,omitempty"`
+	Events    []inbound.Event   `json:"events,omitempty"`
+	Rendered  string            `json:"rendered"`
+	Parts     []string          `json:"parts"`
+	Ignored   bool              `json:"ignored"`
+	Filtered  bool              `json:"filtered"`
+}
+
+// webhookRender is a payload converted with an endpoint's settings.
+type webhookRender struct {
+	provider  string
+	eventType string
+	events    []inbound.Event
+	message   *chatwork.Builder
+	// severity is the highest of the events, info when nothing says otherwise.
+	severity notifier.Severity
+	// summary is a one-line description for dedup counts and digests.
+	summary string
+}
+
+type WebhookEndpointService struct {
+	repo     repositories.IWebhookEndpointRepository
+	botRepo  repositories.IChatworkBotRepository
+	adapters *inbound.Registry
+	alerts   IAlertService
+	replays  *inbound.ReplayGuard
+	baseURL  string
+	now      func() time.Time
+}
+
+func NewWebhookEndpointService(repo repositories.IWebhookEndpointRepository, botRepo repositories.IChatworkBotRepository, adapters *inbound.Registry, alerts IAlertService) *WebhookEndpointService {
+	return &WebhookEndpointService{
+		repo:     repo,
+		botRepo:  botRepo,
+		adapters: adapters,
+		alerts:   alerts,
+		baseURL:  chatworkBaseURL(),
+		now:      time.Now,
+	}
+}
+
+// SetReplayGuard turns on replay checks for endpoints that require a signature.
+func (s *WebhookEndpointService) SetReplayGuard(replays *inbound.ReplayGuard) {
+	s.replays = replays
+}
+
+func (s *WebhookEndpointService) GetByProjectID(projectID uint) ([]models.WebhookEndpoint, error) {
+	endpoints, err := s.repo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return endpoints, nil
+}
+
+func (s *WebhookEndpointService) GetByID(id, projectID uint) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetByID(id)
+	if err != nil || endpoint.ProjectID != projectID {
+		return nil, errors.New(errors.ErrResourceNotFound, "webhook endpoint not found")
+	}
+	return endpoint, nil
+}
+
+func (s *WebhookEndpointService) Create(projectID uint, input *WebhookEndpointInput) (*models.WebhookEndpoint, error) {
+	endpoint := &models.WebhookEndpoint{
+		ProjectID:        projectID,
+		Token:            randomHex(24),
+		Secret:           "whsec_" + randomHex(24),
+		RequireSignature: true,
+		Active:           true,
+	}
+	s.applyInput(endpoint, input)
+	if err := s.validate(endpoint); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(endpoint)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseInsert, err.Error())
+	}
+	return created, nil
+}
+
+func (s *WebhookEndpointService) Update(id, projectID uint, input *WebhookEndpointInput) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.GetByID(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	s.applyInput(endpoint, input)
+	if err := s.validate(endpoint); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.Update(endpoint)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return updated, nil
+}
+
+func (s *WebhookEndpointService) Delete(id, projectID uint) error {
+	if _, err := s.GetByID(id, projectID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return errors.New(errors.ErrDatabaseDelete, err.Error())
+	}
+	return nil
+}
+
+func (s *WebhookEndpointService) RotateSecret(id, projectID uint) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.GetByID(id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = "whsec_" + randomHex(24)
+	updated, err := s.repo.Update(endpoint)
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabaseUpdate, err.Error())
+	}
+	return updated, nil
+}
+
+func (s *WebhookEndpointService) Receive(token string, header http.Header, body []byte) (*models.WebhookDelivery, error) {
+	endpoint, err := s.repo.GetByToken(token)
+	if err != nil || !endpoint.Active {
+		return nil, errors.New(errors.ErrResourceNotFound, "webhook endpoint not found")
+	}
+
+	start := s.now()
+	delivery := &models.WebhookDelivery{
+		EndpointID: endpoint.ID,
+		Headers:    deliveryHeaders(header),
+		Payload:    truncatePayload(body),
+	}
+	var replayKey string
+	finish := func(status string, cause error) {
+		delivery.Status = status
+		if cause != nil {
+			delivery.Error = cause.Error()
+		}
+		// A delivery that failed on our side may be retried by the sender.
+		if status == models.WebhookDeliveryFailed && replayKey != "" {
+			if err := s.replays.Forget(replayKey); err != nil {
+				logger.Warnf("[WebhookEndpoint] endpoint_id=%d failed to forget a failed delivery: %v", endpoint.ID, err)
+			}
+		}
+		delivery.DurationMs = s.now().Sub(start).Milliseconds()
+		s.record(delivery)
+	}
+
+	if endpoint.RequireSignature {
+		if scheme, ok := inbound.VerifySignature(header, body, endpoint.Secret, s.now()); !ok {
+			reason := "missing signature"
+			if scheme != "" {
+				reason = "invalid " + scheme + " signature"
+			}
+			err := errors.New(errors.ErrAuthUnauthorized, reason)
+			finish(models.WebhookDeliveryRejected, err)
+			return delivery, err
+		}
+		if s.replays != nil {
+			key, seen, err := s.replays.Seen(fmt.Sprintf("webhook:%d", endpoint.ID), header, s.now())
+			if err != nil {
+				logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to check for a replay: %v", endpoint.ID, err)
+			} else if seen {
+				err := errors.New(errors.ErrAuthUnauthorized, "delivery was already received")
+				finish(models.WebhookDeliveryRejected, err)
+				return delivery, err
+			}
+			replayKey = key
+		}
+	}
+
+	result, err := s.render(endpoint.Provider, endpoint.Template, header, body)
+	if err != nil {
+		finish(models.WebhookDeliveryFailed, err)
+		return delivery, errors.New(errors.ErrInvalidData, err.Error())
+	}
+	delivery.Provider = result.provider
+	delivery.EventType = result.eventType
+
+	var payload *inbound.Payload
+	if len(endpoint.Filters) > 0 || len(endpoint.DedupKeys) > 0 {
+		if payload, err = inbound.NewPayload(header, body); err != nil {
+			finish(models.WebhookDeliveryFailed, err)
+			return delivery, errors.New(errors.ErrInvalidData, err.Error())
+		}
+	}
+	passed, err := passesFilters(endpoint.Filters, endpoint.MinSeverity, payload, result)
+	if err != nil {
+		finish(models.WebhookDeliveryFailed, err)
+		return delivery, errors.New(errors.ErrInvalidData, err.Error())
+	}
+	if result.message == nil {
+		finish(models.WebhookDeliveryIgnored, nil)
+		return delivery, nil
+	}
+	delivery.Rendered = result.message.String()
+	if !passed {
+		finish(models.WebhookDeliveryFiltered, nil)
+		return delivery, nil
+	}
+	// Alerts endpoints post through the alerts subsystem, which has its own
+	// grouping and silences in place of dedup windows and quiet hours.
+	if endpoint.Alerts {
+		return s.receiveAlerts(endpoint, delivery, result, finish)
+	}
+
+	now := s.now()
+	key, err := dedupKey(endpoint.DedupKeys, payload, result, delivery.Rendered)
+	if err != nil {
+		finish(models.WebhookDeliveryFailed, err)
+		return delivery, errors.New(errors.ErrInvalidData, err.Error())
+	}
+	if until, quiet := quietUntil(endpoint, now); quiet && result.severity != notifier.SeverityCritical {
+		err := s.hold(endpoint.ID, key, result, now, until)
+		if err == nil {
+			finish(models.WebhookDeliveryBuffered, nil)
+			return delivery, nil
+		}
+		logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to buffer for the digest, sending now: %v", endpoint.ID, err)
+	}
+	if endpoint.DedupWindowMinutes > 0 {
+		if merged, err := s.merge(endpoint.ID, key, now); err != nil {
+			logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to check the dedup window: %v", endpoint.ID, err)
+		} else if merged {
+			finish(models.WebhookDeliveryMerged, nil)
+			return delivery, nil
+		}
+	}
+
+	if err := s.send(endpoint, result.message); err != nil {
+		logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to deliver: %v", endpoint.ID, err)
+		finish(models.WebhookDeliveryFailed, err)
+		return delivery, err
+	}
+	if endpoint.DedupWindowMinutes > 0 {
+		window := &models.WebhookBufferedEvent{
+			EndpointID:  endpoint.ID,
+			Kind:        models.WebhookBufferDedup,
+			DedupKey:    key,
+			Summary:     result.summary,
+			Severity:    string(result.severity),
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+			FlushAt:     now.Add(time.Duration(endpoint.DedupWindowMinutes) * time.Minute),
+		}
+		if err := s.repo.SaveBuffered(window); err != nil {
+			logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to open a dedup window: %v", endpoint.ID, err)
+		}
+	}
+	finish(models.WebhookDeliveryDelivered, nil)
+	return delivery, nil
+}
+
+func (s *WebhookEndpointService) receiveAlerts(endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery, result *webhookRender, finish func(string, error)) (*models.WebhookDelivery, error) {
+	ingest, err := s.alerts.Ingest(endpoint, result.events)
+	delivery.Rendered = ""
+	if ingest != nil {
+		delivery.Rendered = strings.Join(ingest.Messages, "\n\n")
+	}
+	if err != nil {
+		logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to deliver alerts: %v", endpoint.ID, err)
+		finish(models.WebhookDeliveryFailed, err)
+		return delivery, err
+	}
+	// Nothing posted: the alerts were silenced, or are repeats of alerts
+	// already announced.
+	switch {
+	case len(ingest.Messages) > 0:
+		finish(models.WebhookDeliveryDelivered, nil)
+	case ingest.Silenced > 0:
+		finish(models.WebhookDeliverySilenced, nil)
+	default:
+		finish(models.WebhookDeliveryMerged, nil)
+	}
+	return delivery, nil
+}
+
+func (s *WebhookEndpointService) GetDeliveries(endpointID, projectID uint, paging *utils.Paging) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.GetByID(endpointID, projectID); err != nil {
+		return nil, 0, err
+	}
+	deliveries, total, err := s.repo.ListDeliveries(endpointID, paging)
+	if err != nil {
+		return nil, 0, errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+	return deliveries, total, nil
+}
+
+func (s *WebhookEndpointService) GetDelivery(id, endpointID, projectID uint) (*models.WebhookDelivery, error) {
+	if _, err := s.GetByID(endpointID, projectID); err != nil {
+		return nil, err
+	}
+	delivery, err := s.repo.GetDelivery(id)
+	if err != nil || delivery.EndpointID != endpointID {
+		return nil, errors.New(errors.ErrResourceNotFound, "webhook delivery not found")
+	}
+	return delivery, nil
+}
+
+func (s *WebhookEndpointService) DryRun(projectID uint, endpointID *uint, input *WebhookDryRunInput) (*WebhookDryRun, error) {
+	provider, tmpl, minSeverity := "", "", ""
+	var filters []models.WebhookFilter
+	if endpointID != nil {
+		endpoint, err := s.GetByID(*endpointID, projectID)
+		if err != nil {
+			return nil, err
+		}
+		provider, tmpl = endpoint.Provider, endpoint.Template
+		filters, minSeverity = endpoint.Filters, endpoint.MinSeverity
+	}
+	if input.Provider != nil {
+		provider = normaliseProvider(*input.Provider)
+		if provider != "" {
+			if _, ok := s.adapters.Get(provider); !ok {
+				return nil, errors.New(errors.ErrInvalidData, fmt.Sprintf("provider must be auto or one of: %s", strings.Join(s.adapters.Names(), ", ")))
+			}
+		}
+	}
+	if input.Template != nil {
+		tmpl = *input.Template
+	}
+	if input.Filters != nil {
+		filters = *input.Filters
+	}
+	if input.MinSeverity != nil {
+		minSeverity = strings.ToLower(strings.TrimSpace(*input.MinSeverity))
+	}
+	if err := validateFilters(filters, minSeverity); err != nil {
+		return nil, err
+	}
+	if len(input.Payload) == 0 {
+		return nil, errors.New(errors.ErrInvalidData, "payload is required")
+	}
+
+	header := http.Header{}
+	for k, v := range input.Headers {
+		header.Set(k, v)
+	}
+	result, err := s.render(provider, tmpl, header, input.Payload)
+	if err != nil {
+		return nil, errors.New(errors.ErrInvalidData, err.Error())
+	}
+	var payload *inbound.Payload
+	if len(filters) > 0 {
+		if payload, err = inbound.NewPayload(header, input.Payload); err != nil {
+			return nil, errors.New(errors.ErrInvalidData, err.Error())
+		}
+	}
+	passed, err := passesFilters(filters, minSeverity, payload, result)
+	if err != nil {
+		return nil, errors.New(errors.ErrInvalidData, err.Error())
+	}
+
+	dryRun := &WebhookDryRun{
+		Provider:  result.provider,
+		EventType: result.eventType,
+		Severity:  result.severity,
+		Summary:   result.summary,
+		Events:    result.events,
+		Parts:     []string{},
+		Ignored:   result.message == nil,
+		Filtered:  !passed,
+	}
+	if result.message != nil {
+		dryRun.Rendered = result.message.String()
+		dryRun.Parts = result.message.Split(chatwork.MaxMessageLength)
+	}
+	return dryRun, nil
+}
+
+// render converts a payload with the template when there is one, otherwise
+// with the provider's adapter. A nil message means nothing should be sent.
+func (s *WebhookEndpointService) render(provider, tmpl string, header http.Header, body []byte) (*webhookRender, error) {
+	if strings.TrimSpace(tmpl) != "" {
+		t, err := inbound.ParseTemplate(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := t.Render(header, body)
+		if err != nil {
+			return nil, err
+		}
+		result := &webhookRender{provider: provider, severity: notifier.SeverityInfo}
+		if result.provider == "" {
+			result.provider, _ = s.adapters.Detect(header, body)
+		}
+		// The adapter still decides how severe the payload is, for filters
+		// and quiet hours.
+		if result.provider != "" {
+			if events, err := s.adapters.Parse(result.provider, header, body); err == nil {
+				result.severity = maxSeverity(events)
+			}
+		}
+		if rendered != "" {
+			result.message = inbound.TemplateBuilder(rendered)
+			result.summary = summaryLine(chatworkTagMarkup.ReplaceAllString(rendered, "\n"))
+		}
+		return result, nil
+	}
+
+	events, err := s.adapters.Parse(provider, header, body)
+	if err != nil {
+		return nil, err
+	}
+	result := &webhookRender{events: events, severity: maxSeverity(events)}
+	if len(events) > 0 {
+		result.provider = events[0].Provider
+		result.eventType = events[0].Type
+		result.message = inbound.ChatworkBuilder(events)
+		result.summary = summaryLine(events[0].Title)
+		if len(events) > 1 {
+			result.summary = summaryLine(fmt.Sprintf("%s (+%d more)", events[0].Title, len(events)-1))
+		}
+	}
+	return result, nil
+}
+
+// hold adds an event to the endpoint's quiet hours digest, counting it with
+// the identical events already held.
+func (s *WebhookEndpointService) hold(endpointID uint, key string, result *webhookRender, now, until time.Time) error {
+	held, err := s.repo.FindBuffered(endpointID, models.WebhookBufferDigest, key, now)
+	if err != nil {
+		return err
+	}
+	if held == nil {
+		held = &models.WebhookBufferedEvent{
+			EndpointID:  endpointID,
+			Kind:        models.WebhookBufferDigest,
+			DedupKey:    key,
+			Summary:     result.summary,
+			FirstSeenAt: now,
+			FlushAt:     until,
+		}
+	}
+	held.Count++
+	held.LastSeenAt = now
+	if severityRank(result.severity) >= severityRank(notifier.Severity(held.Severity)) {
+		held.Severity = string(result.severity)
+	}
+	return s.repo.SaveBuffered(held)
+}
+
+// merge counts the event against an open dedup window for key. It reports
+// false when there is none, i.e. the event should be sent.
+func (s *WebhookEndpointService) merge(endpointID uint, key string, now time.Time) (bool, error) {
+	window, err := s.repo.FindBuffered(endpointID, models.WebhookBufferDedup, key, now)
+	if err != nil || window == nil {
+		return false, err
+	}
+	window.Count++
+	window.LastSeenAt = now
+	if err := s.repo.SaveBuffered(window); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *WebhookEndpointService) FlushDue() error {
+	due, err := s.repo.ListDueBuffered(s.now())
+	if err != nil {
+		return errors.New(errors.ErrDatabaseQuery, err.Error())
+	}
+
+	var order []uint
+	byEndpoint := make(map[uint][]models.WebhookBufferedEvent)
+	for _, event := range due {
+		if _, ok := byEndpoint[event.EndpointID]; !ok {
+			order = append(order, event.EndpointID)
+		}
+		byEndpoint[event.EndpointID] = append(byEndpoint[event.EndpointID], event)
+	}
+
+	for _, endpointID := range order {
+		events := byEndpoint[endpointID]
+		s.flushEndpoint(endpointID, events)
+
+		// Rows are dropped even when Chatwork failed; the failure is in the
+		// delivery history and retrying every minute would only pile up.
+		ids := make([]uint, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		if err := s.repo.DeleteBuffered(ids); err != nil {
+			logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to drop flushed events: %v", endpointID, err)
+		}
+	}
+	return nil
+}
+
+// flushEndpoint posts one message with the endpoint's repeat counts and one
+// with its digest. Events of deleted or inactive endpoints are dropped.
+func (s *WebhookEndpointService) flushEndpoint(endpointID uint, events []models.WebhookBufferedEvent) {
+	endpoint, err := s.repo.GetByID(endpointID)
+	if err != nil || !endpoint.Active {
+		return
+	}
+	loc := endpointLocation(endpoint)
+
+	var repeats, digest []models.WebhookBufferedEvent
+	for _, event := range events {
+		switch {
+		case event.Kind == models.WebhookBufferDigest:
+			digest = append(digest, event)
+		case event.Count > 0:
+			repeats = append(repeats, event)
+		}
+	}
+
+	if len(repeats) > 0 {
+		message := chatwork.NewBuilder().Info("🔁 Repeated events", func(b *chatwork.Builder) {
+			for _, event := range repeats {
+				b.Textf("%s: %d more time(s) between %s and %s", event.Summary, event.Count,
+					event.FirstSeenAt.In(loc).Format("15:04"), event.LastSeenAt.In(loc).Format("15:04"))
+			}
+		})
+		s.deliverFlush(endpoint, "repeats", message)
+	}
+
+	if len(digest) > 0 {
+		total := 0
+		for _, event := range digest {
+			total += event.Count
+		}
+		title := fmt.Sprintf("🌙 Quiet hours digest: %d event(s)", total)
+		message := chatwork.NewBuilder().Info(title, func(b *chatwork.Builder) {
+			for _, event := range digest {
+				line := event.Summary
+				if event.Count > 1 {
+					line = fmt.Sprintf("%s (×%d)", line, event.Count)
+				}
+				b.Textf("%s %s — %s", event.FirstSeenAt.In(loc).Format("15:04"), line, event.Severity)
+			}
+		})
+		s.deliverFlush(endpoint, "digest", message)
+	}
+}
+
+// deliverFlush sends a flush message and records it in the delivery history.
+func (s *WebhookEndpointService) deliverFlush(endpoint *models.WebhookEndpoint, eventType string, message *chatwork.Builder) {
+	start := s.now()
+	delivery := &models.WebhookDelivery{
+		EndpointID: endpoint.ID,
+		EventType:  eventType,
+		Headers:    map[string]string{},
+		Rendered:   message.String(),
+		Status:     models.WebhookDeliveryDelivered,
+	}
+	if err := s.send(endpoint, message); err != nil {
+		logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to deliver the %s: %v", endpoint.ID, eventType, err)
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = err.Error()
+	}
+	delivery.DurationMs = s.now().Sub(start).Milliseconds()
+	s.record(delivery)
+}
+
+// send posts the rendered message to the endpoint's room with its bot.
+func (s *WebhookEndpointService) send(endpoint *models.WebhookEndpoint, message *chatwork.Builder) error {
+	bot, err := s.botRepo.GetByID(endpoint.BotID)
+	if err != nil {
+		return fmt.Errorf("bot %d not found", endpoint.BotID)
+	}
+	roomID, err := strconv.ParseInt(endpoint.RoomID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid room id %q", endpoint.RoomID)
+	}
+
+	ctx, cancel := context.WithTimeout(chatwork.WithPriority(context.Background(), chatwork.PriorityHigh), chatworkCallTimeout)
+	defer cancel()
+	client := chatwork.NewClient(bot.APIToken, chatwork.WithBaseURL(s.baseURL))
+	for _, part := range message.Split(chatwork.MaxMessageLength) {
+		if _, err := client.PostMessage(ctx, roomID, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record stores the delivery and trims the endpoint's history. Failing to
+// record never fails the request itself.
+func (s *WebhookEndpointService) record(delivery *models.WebhookDelivery) {
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		logger.Errorf("[WebhookEndpoint] endpoint_id=%d failed to record delivery: %v", delivery.EndpointID, err)
+		return
+	}
+	if err := s.repo.PruneDeliveries(delivery.EndpointID, keepDeliveries); err != nil {
+		logger.Warnf("[WebhookEndpoint] endpoint_id=%d failed to prune deliveries: %v", delivery.EndpointID, err)
+	}
+}
+
+func (s *WebhookEndpointService) applyInput(endpoint *models.WebhookEndpoint, input *WebhookEndpointInput) {
+	if input.Name != nil {
+		endpoint.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Provider != nil {
+		endpoint.Provider = normaliseProvider(*input.Provider)
+	}
+	if input.Template != nil {
+		endpoint.Template = strings.TrimSpace(*input.Template)
+	}
+	if input.BotID != nil {
+		endpoint.BotID = *input.BotID
+	}
+	if input.RoomID != nil {
+		endpoint.RoomID = strings.TrimSpace(*input.RoomID)
+	}
+	if input.RequireSignature != nil {
+		endpoint.RequireSignature = *input.RequireSignature
+	}
+	if input.Active != nil {
+		endpoint.Active = *input.Active
+	}
+	if input.Filters != nil {
+		endpoint.Filters = *input.Filters
+	}
+	if input.MinSeverity != nil {
+		endpoint.MinSeverity = strings.ToLower(strings.TrimSpace(*input.MinSeverity))
+	}
+	if input.DedupWindowMinutes != nil {
+		endpoint.DedupWindowMinutes = *input.DedupWindowMinutes
+	}
+	if input.DedupKeys != nil {
+		endpoint.DedupKeys = *input.DedupKeys
+	}
+	if input.QuietStart != nil {
+		endpoint.QuietStart = strings.TrimSpace(*input.QuietStart)
+	}
+	if input.QuietEnd != nil {
+		endpoint.QuietEnd = strings.TrimSpace(*input.QuietEnd)
+	}
+	if input.QuietTimezone != nil {
+		endpoint.QuietTimezone = strings.TrimSpace(*input.QuietTimezone)
+	}
+	if input.Alerts != nil {
+		endpoint.Alerts = *input.Alerts
+	}
+	if input.AlertGroupBy != nil {
+		endpoint.AlertGroupBy = *input.AlertGroupBy
+	}
+}
+
+func (s *WebhookEndpointService) validate(endpoint *models.WebhookEndpoint) error {
+	if endpoint.Name == "" {
+		return errors.New(errors.ErrInvalidData, "name is required")
+	}
+	if endpoint.Provider != "" {
+		if _, ok := s.adapters.Get(endpoint.Provider); !ok {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("provider must be auto or one of: %s", strings.Join(s.adapters.Names(), ", ")))
+		}
+	}
+	if endpoint.Template != "" {
+		if _, err := inbound.ParseTemplate(endpoint.Template); err != nil {
+			return errors.New(errors.ErrInvalidData, "invalid template: "+err.Error())
+		}
+	}
+	if err := validateFilters(endpoint.Filters, endpoint.MinSeverity); err != nil {
+		return err
+	}
+	if endpoint.DedupWindowMinutes < 0 || endpoint.DedupWindowMinutes > maxDedupWindow {
+		return errors.New(errors.ErrInvalidData, fmt.Sprintf("dedupWindowMinutes must be between 0 and %d", maxDedupWindow))
+	}
+	for _, key := range endpoint.DedupKeys {
+		if err := inbound.ValidatePath(key); err != nil {
+			return errors.New(errors.ErrInvalidData, "invalid dedup key: "+err.Error())
+		}
+	}
+	if (endpoint.QuietStart == "") != (endpoint.QuietEnd == "") {
+		return errors.New(errors.ErrInvalidData, "quietStart and quietEnd must be set together")
+	}
+	if endpoint.QuietStart != "" {
+		start, err1 := time.Parse("15:04", endpoint.QuietStart)
+		end, err2 := time.Parse("15:04", endpoint.QuietEnd)
+		if err1 != nil || err2 != nil {
+			return errors.New(errors.ErrInvalidData, "quietStart and quietEnd must be HH:MM")
+		}
+		if start.Equal(end) {
+			return errors.New(errors.ErrInvalidData, "quietStart and quietEnd must differ")
+		}
+	}
+	if endpoint.QuietTimezone != "" {
+		if _, err := time.LoadLocation(endpoint.QuietTimezone); err != nil {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("unknown quietTimezone %q", endpoint.QuietTimezone))
+		}
+	}
+	if endpoint.Alerts && endpoint.Template != "" {
+		return errors.New(errors.ErrInvalidData, "alerts endpoints are rendered by their provider adapter; remove the template")
+	}
+	for _, name := range endpoint.AlertGroupBy {
+		if !labelNamePattern.MatchString(name) {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("invalid alertGroupBy label %q", name))
+		}
+	}
+	if _, err := strconv.ParseInt(endpoint.RoomID, 10, 64); err != nil {
+		return errors.New(errors.ErrInvalidData, "roomId must be a Chatwork room id")
+	}
+	if endpoint.BotID == 0 {
+		return errors.New(errors.ErrInvalidData, "botId is required")
+	}
+	if _, err := s.botRepo.GetByID(endpoint.BotID); err != nil {
+		return errors.New(errors.ErrInvalidData, fmt.Sprintf("bot %d not found", endpoint.BotID))
+	}
+	return nil
+}
+
+func validateFilters(filters []models.WebhookFilter, minSeverity string) error {
+	for i, f := range filters {
+		if err := toInboundFilter(f).Validate(); err != nil {
+			return errors.New(errors.ErrInvalidData, fmt.Sprintf("filters[%d]: %v", i, err))
+		}
+	}
+	switch notifier.Severity(minSeverity) {
+	case "", notifier.SeverityInfo, notifier.SeverityWarning, notifier.SeverityCritical:
+		return nil
+	default:
+		return errors.New(errors.ErrInvalidData, "minSeverity must be info, warning or critical")
+	}
+}
+
+func toInboundFilter(f models.WebhookFilter) inbound.Filter {
+	return inbound.Filter{Path: f.Path, Op: f.Op, Values: f.Values}
+}
+
+// passesFilters reports whether a rendered request gets past the filters and
+// the minimum severity. payload is only read when there are filters.
+func passesFilters(filters []models.WebhookFilter, minSeverity string, payload *inbound.Payload, result *webhookRender) (bool, error) {
+	if severityRank(result.severity) < severityRank(notifier.Severity(minSeverity)) {
+		return false, nil
+	}
+	if len(filters) == 0 {
+		return true, nil
+	}
+	converted := make([]inbound.Filter, 0, len(filters))
+	for _, f := range filters {
+		converted = append(converted, toInboundFilter(f))
+	}
+	return payload.Match(converted)
+}
+
+// dedupKey identifies identical events: the provider, the event type and
+// either the values at keys or, without keys, the whole rendered message.
+func dedupKey(keys []string, payload *inbound.Payload, result *webhookRender, rendered string) (string, error) {
+	parts := []string{result.provider, result.eventType}
+	if len(keys) == 0 {
+		parts = append(parts, rendered)
+	}
+	for _, key := range keys {
+		values, err := payload.Values(key)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, strings.Join(values, ","))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// quietUntil reports whether now falls in the endpoint's quiet hours and, if
+// so, when they end. Windows may wrap past midnight, e.g. 22:00–07:00.
+func quietUntil(endpoint *models.WebhookEndpoint, now time.Time) (time.Time, bool) {
+	start, err1 := time.Parse("15:04", endpoint.QuietStart)
+	end, err2 := time.Parse("15:04", endpoint.QuietEnd)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(endpointLocation(endpoint))
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	quiet := minute >= from && minute < to
+	if from > to {
+		quiet = minute >= from || minute < to
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, local.Location())
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// endpointLocation is the quiet hours timezone, UTC by default.
+func endpointLocation(endpoint *models.WebhookEndpoint) *time.Location {
+	if endpoint.QuietTimezone != "" {
+		if loc, err := time.LoadLocation(endpoint.QuietTimezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// severityRank orders severities for minSeverity and quiet hours; success
+// counts as info.
+func severityRank(severity notifier.Severity) int {
+	switch severity {
+	case notifier.SeverityWarning:
+		return 1
+	case notifier.SeverityCritical:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func maxSeverity(events []inbound.Event) notifier.Severity {
+	highest := notifier.SeverityInfo
+	for _, e := range events {
+		if severityRank(e.Severity) > severityRank(highest) {
+			highest = e.Severity
+		}
+	}
+	return highest
+}
+
+// summaryLine returns the first non-empty line of s, cut to maxSummaryLength
+// characters.
+func summaryLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) > maxSummaryLength {
+			line = string([]rune(line)[:maxSummaryLength-1]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// normaliseProvider maps "auto" to the empty provider, which detects per request.
+func normaliseProvider(provider string) string {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "auto" {
+		return ""
+	}
+	return provider
+}
+
+// deliveryHeaders keeps the request headers worth showing in the history,
+// without the ones that carry credentials.
+func deliveryHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		if slices.Contains(inbound.SignatureHeaders, canonical) || canonical == "Cookie" {
+			continue
+		}
+		headers[canonical] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+func truncatePayload(body []byte) string {
+	if len(body) <= maxDeliveryPayload {
+		return string(body)
+	}
+	return strings.ToValidUTF8(string(body[:maxDeliveryPayload]), "") + "\n…(truncated)"
+}
+
+// randomHex returns n random bytes hex-encoded, for URL tokens and secrets.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
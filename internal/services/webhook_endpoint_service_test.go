@@ -0,0 +1,522 @@
+package services
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/internal/models"
+	"github.com/vfa-khuongdv/golang-cms/internal/repositories"
+	"github.com/vfa-khuongdv/golang-cms/internal/utils"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+	"github.com/vfa-khuongdv/golang-cms/pkg/errors"
+	"github.com/vfa-khuongdv/golang-cms/pkg/inbound"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+	"github.com/vfa-khuongdv/golang-cms/pkg/ratelimit"
+	"gorm.io/gorm"
+)
+
+type fakeWebhookEndpointRepo struct {
+	repositories.IWebhookEndpointRepository
+	endpoints  map[uint]*models.WebhookEndpoint
+	deliveries []models.WebhookDelivery
+	buffered   []models.WebhookBufferedEvent
+	nextBuffer uint
+}
+
+func (r *fakeWebhookEndpointRepo) FindBuffered(endpointID uint, kind, key string, now time.Time) (*models.WebhookBufferedEvent, error) {
+	for _, e := range r.buffered {
+		if e.EndpointID == endpointID && e.Kind == kind && e.DedupKey == key && e.FlushAt.After(now) {
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeWebhookEndpointRepo) SaveBuffered(event *models.WebhookBufferedEvent) error {
+	for i := range r.buffered {
+		if r.buffered[i].ID == event.ID {
+			r.buffered[i] = *event
+			return nil
+		}
+	}
+	r.nextBuffer++
+	event.ID = r.nextBuffer
+	r.buffered = append(r.buffered, *event)
+	return nil
+}
+
+func (r *fakeWebhookEndpointRepo) ListDueBuffered(now time.Time) ([]models.WebhookBufferedEvent, error) {
+	var due []models.WebhookBufferedEvent
+	for _, e := range r.buffered {
+		if !e.FlushAt.After(now) {
+			due = append(due, e)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeWebhookEndpointRepo) DeleteBuffered(ids []uint) error {
+	r.buffered = slices.DeleteFunc(r.buffered, func(e models.WebhookBufferedEvent) bool {
+		return slices.Contains(ids, e.ID)
+	})
+	return nil
+}
+
+func (r *fakeWebhookEndpointRepo) GetByID(id uint) (*models.WebhookEndpoint, error) {
+	ep, ok := r.endpoints[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *ep
+	return &copied, nil
+}
+
+func (r *fakeWebhookEndpointRepo) GetByToken(token string) (*models.WebhookEndpoint, error) {
+	for _, ep := range r.endpoints {
+		if ep.Token == token {
+			copied := *ep
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWebhookEndpointRepo) Create(ep *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	ep.ID = uint(len(r.endpoints) + 1)
+	copied := *ep
+	r.endpoints[ep.ID] = &copied
+	return ep, nil
+}
+
+func (r *fakeWebhookEndpointRepo) CreateDelivery(d *models.WebhookDelivery) error {
+	d.ID = uint(len(r.deliveries) + 1)
+	r.deliveries = append(r.deliveries, *d)
+	return nil
+}
+
+func (r *fakeWebhookEndpointRepo) PruneDeliveries(endpointID uint, keep int) error {
+	return nil
+}
+
+func (r *fakeWebhookEndpointRepo) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == id {
+			return &r.deliveries[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWebhookEndpointRepo) ListDeliveries(endpointID uint, paging *utils.Paging) ([]models.WebhookDelivery, int64, error) {
+	var out []models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.EndpointID == endpointID {
+			out = append(out, d)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+const endpointSecret = "whsec_endpoint"
+
+func newWebhookEndpointTestEnv(t *testing.T) (*WebhookEndpointService, *fakeWebhookEndpointRepo, *chatworktest.Server) {
+	t.Helper()
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount("bot-token", chatwork.Me{AccountID: 1, Name: "Release Bot"})
+	srv.AddRoom(chatwork.Room{RoomID: 42, Name: "Deploys", Type: chatwork.RoomTypeGroup}, chatwork.Member{AccountID: 1})
+
+	bots := &fakeBotRepo{bots: map[uint]*models.ChatworkBot{7: {ID: 7, APIToken: "bot-token", AccountID: 1}}}
+	repo := &fakeWebhookEndpointRepo{endpoints: map[uint]*models.WebhookEndpoint{
+		1: {ID: 1, ProjectID: 3, Name: "GitHub", Token: "tok-github", Provider: inbound.ProviderGitHub, Secret: endpointSecret, RequireSignature: true, BotID: 7, RoomID: "42", Active: true},
+		2: {ID: 2, ProjectID: 3, Name: "Unsigned", Token: "tok-open", Secret: endpointSecret, BotID: 7, RoomID: "42", Active: true},
+		3: {ID: 3, ProjectID: 3, Name: "Disabled", Token: "tok-off", Secret: endpointSecret, BotID: 7, RoomID: "42"},
+		4: {ID: 4, ProjectID: 3, Name: "Alertmanager", Token: "tok-alerts", Provider: inbound.ProviderAlertmanager, Secret: endpointSecret, BotID: 7, RoomID: "42", Active: true, Alerts: true},
+	}}
+
+	alerts := NewAlertService(&fakeAlertRepo{}, repo, bots)
+	alerts.baseURL = srv.URL
+	svc := NewWebhookEndpointService(repo, bots, inbound.NewDefaultRegistry(), alerts)
+	svc.baseURL = srv.URL
+	return svc, repo, srv
+}
+
+func githubPush(t *testing.T) (http.Header, []byte) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("..", "..", "pkg", "inbound", "testdata", "github_push.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	header.Set("User-Agent", "GitHub-Hookshot/abc")
+	return header, body
+}
+
+func TestWebhookEndpointReceiveSigned(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	header, body := githubPush(t)
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	header.Set("X-Signature-256", "sha256="+notifier.Sign(endpointSecret, ts, body))
+	header.Set("X-Signature-Timestamp", ts)
+
+	delivery, err := svc.Receive("tok-github", header, body)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if delivery.Status != models.WebhookDeliveryDelivered || delivery.Provider != inbound.ProviderGitHub || delivery.EventType != "push" {
+		t.Fatalf("unexpected delivery: %+v", delivery)
+	}
+	messages := srv.Messages(42)
+	if len(messages) != 1 || messages[0].Body != delivery.Rendered {
+		t.Fatalf("expected the rendered message in room 42, got %+v", messages)
+	}
+
+	stored := repo.deliveries[0]
+	if stored.Payload != string(body) || stored.Headers["X-Github-Event"] != "push" {
+		t.Fatalf("expected the raw payload and headers to be recorded, got %+v", stored.Headers)
+	}
+	if _, leaked := stored.Headers["X-Signature-256"]; leaked {
+		t.Fatal("expected signature headers to be left out of the history")
+	}
+}
+
+func TestWebhookEndpointRejectsReplays(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	svc.SetReplayGuard(inbound.NewReplayGuard(ratelimit.NewMemoryStore()))
+	header, body := githubPush(t)
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	header.Set("X-Signature-256", "sha256="+notifier.Sign(endpointSecret, ts, body))
+	header.Set("X-Signature-Timestamp", ts)
+	if _, err := svc.Receive("tok-github", header, body); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	// A changed delivery ID header isn't signed, so it doesn't make a replay new.
+	header.Set("X-GitHub-Delivery", "forged")
+	_, err := svc.Receive("tok-github", header, body)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected the replay to be rejected, got %v", err)
+	}
+	if len(srv.Messages(42)) != 1 {
+		t.Fatal("expected the replay not to be posted")
+	}
+	if last := repo.deliveries[len(repo.deliveries)-1]; last.Status != models.WebhookDeliveryRejected {
+		t.Fatalf("expected the replay to be recorded as rejected, got %+v", last)
+	}
+
+	// A new send gets a new timestamp, and so a new signature.
+	next := strconv.FormatInt(time.Now().Unix()+1, 10)
+	header.Set("X-Signature-256", "sha256="+notifier.Sign(endpointSecret, next, body))
+	header.Set("X-Signature-Timestamp", next)
+	if _, err := svc.Receive("tok-github", header, body); err != nil {
+		t.Fatalf("expected a new send to be accepted, got %v", err)
+	}
+}
+
+func TestWebhookEndpointRejectsBadSignature(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	header, body := githubPush(t)
+	header.Set("X-Hub-Signature-256", "sha256=00")
+
+	_, err := svc.Receive("tok-github", header, body)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != errors.ErrAuthUnauthorized {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+	if len(srv.Messages(42)) != 0 {
+		t.Fatal("expected nothing to be posted for a rejected request")
+	}
+	if len(repo.deliveries) != 1 || repo.deliveries[0].Status != models.WebhookDeliveryRejected || repo.deliveries[0].Rendered != "" {
+		t.Fatalf("expected a rejected delivery to be recorded, got %+v", repo.deliveries)
+	}
+
+	header.Del("X-Hub-Signature-256")
+	if _, err := svc.Receive("tok-github", header, body); err == nil || !strings.Contains(err.Error(), "missing signature") {
+		t.Fatalf("expected an unsigned request to be rejected, got %v", err)
+	}
+}
+
+func TestWebhookEndpointUnsignedAndErrors(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	header, body := githubPush(t)
+
+	// Detected provider on an endpoint that doesn't require a signature
+	delivery, err := svc.Receive("tok-open", header, body)
+	if err != nil || delivery.Provider != inbound.ProviderGitHub {
+		t.Fatalf("expected the provider to be detected, got %+v, %v", delivery, err)
+	}
+	if len(srv.Messages(42)) != 1 {
+		t.Fatal("expected the message to be posted")
+	}
+
+	if _, err := svc.Receive("tok-open", http.Header{}, []byte(`{"hello":"world"}`)); err == nil {
+		t.Fatal("expected an unrecognised payload to fail")
+	}
+	if last := repo.deliveries[len(repo.deliveries)-1]; last.Status != models.WebhookDeliveryFailed || last.Error == "" {
+		t.Fatalf("expected a failed delivery with its error, got %+v", last)
+	}
+
+	for _, token := range []string{"tok-off", "unknown"} {
+		_, err := svc.Receive(token, header, body)
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != errors.ErrResourceNotFound {
+			t.Fatalf("%s: expected not found, got %v", token, err)
+		}
+	}
+
+	if _, err := svc.GetDelivery(1, 1, 3); err == nil {
+		t.Fatal("expected a delivery of another endpoint not to be found")
+	}
+	if d, err := svc.GetDelivery(1, 2, 3); err != nil || d.EndpointID != 2 {
+		t.Fatalf("expected the delivery, got %+v, %v", d, err)
+	}
+	if _, _, err := svc.GetDeliveries(2, 4, &utils.Paging{Page: 1, Limit: 10}); err == nil {
+		t.Fatal("expected another project's endpoint not to be found")
+	}
+}
+
+func TestWebhookEndpointCreate(t *testing.T) {
+	svc, _, _ := newWebhookEndpointTestEnv(t)
+	name, room, bot, provider := "Sentry", "42", uint(7), "sentry"
+
+	ep, err := svc.Create(3, &WebhookEndpointInput{Name: &name, RoomID: &room, BotID: &bot, Provider: &provider})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(ep.Token) != 48 || !strings.HasPrefix(ep.Secret, "whsec_") || !ep.RequireSignature || !ep.Active {
+		t.Fatalf("unexpected endpoint: %+v", ep)
+	}
+
+	bad := "travis"
+	if _, err := svc.Create(3, &WebhookEndpointInput{Name: &name, RoomID: &room, BotID: &bot, Provider: &bad}); err == nil {
+		t.Fatal("expected an unknown provider to be rejected")
+	}
+	missing := uint(99)
+	if _, err := svc.Create(3, &WebhookEndpointInput{Name: &name, RoomID: &room, BotID: &missing}); err == nil {
+		t.Fatal("expected an unknown bot to be rejected")
+	}
+}
+
+func TestWebhookEndpointTemplate(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	repo.endpoints[4] = &models.WebhookEndpoint{
+		ID: 4, ProjectID: 3, Name: "Coolify", Token: "tok-tmpl", BotID: 7, RoomID: "42", Active: true,
+		Template: `{{ if eq .status "success" }}[info][title]✅ {{ .application }} deployed[/title]🔗 {{ .url }}[/info]{{ end }}`,
+	}
+
+	delivery, err := svc.Receive("tok-tmpl", http.Header{}, []byte(`{"status":"success","application":"web [toall]","url":"https://app.example.com"}`))
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	want := "[info][title]✅ web [\u200btoall] deployed[/title]🔗 https://app.example.com[/info]"
+	if delivery.Status != models.WebhookDeliveryDelivered || delivery.Rendered != want {
+		t.Fatalf("unexpected delivery: %+v", delivery)
+	}
+	if messages := srv.Messages(42); len(messages) != 1 || messages[0].Body != want {
+		t.Fatalf("expected the rendered template in room 42, got %+v", messages)
+	}
+
+	// The template's condition doesn't match, so nothing is sent
+	delivery, err = svc.Receive("tok-tmpl", http.Header{}, []byte(`{"status":"running"}`))
+	if err != nil || delivery.Status != models.WebhookDeliveryIgnored {
+		t.Fatalf("expected the delivery to be ignored, got %+v, %v", delivery, err)
+	}
+	if len(srv.Messages(42)) != 1 {
+		t.Fatal("expected no message for an ignored delivery")
+	}
+}
+
+func TestWebhookEndpointDryRun(t *testing.T) {
+	svc, _, srv := newWebhookEndpointTestEnv(t)
+	_, body := githubPush(t)
+
+	// Stored provider of endpoint 1, with the event header from the sample
+	id := uint(1)
+	result, err := svc.DryRun(3, &id, &WebhookDryRunInput{Headers: map[string]string{"X-GitHub-Event": "push"}, Payload: body})
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if result.Provider != inbound.ProviderGitHub || result.EventType != "push" || len(result.Events) != 1 || len(result.Parts) != 1 || result.Rendered == "" {
+		t.Fatalf("unexpected dry run: %+v", result)
+	}
+
+	// A template override renders without saving
+	tmpl := `{{ .repository.name }}: {{ len .commits }} commit(s)`
+	result, err = svc.DryRun(3, nil, &WebhookDryRunInput{Template: &tmpl, Payload: body})
+	if err != nil || !strings.HasSuffix(result.Rendered, "commit(s)") {
+		t.Fatalf("unexpected template dry run: %+v, %v", result, err)
+	}
+	if len(srv.Messages(42)) != 0 {
+		t.Fatal("expected a dry run not to send anything")
+	}
+
+	broken := `{{ if }}`
+	if _, err := svc.DryRun(3, nil, &WebhookDryRunInput{Template: &broken, Payload: body}); err == nil {
+		t.Fatal("expected a broken template to be rejected")
+	}
+	other := uint(1)
+	if _, err := svc.DryRun(4, &other, &WebhookDryRunInput{Payload: body}); err == nil {
+		t.Fatal("expected another project's endpoint not to be found")
+	}
+}
+
+func TestWebhookEndpointFilters(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	header, body := githubPush(t)
+
+	repo.endpoints[2].Filters = []models.WebhookFilter{{Path: "$.ref", Op: inbound.FilterEq, Values: []string{"refs/heads/release"}}}
+	delivery, err := svc.Receive("tok-open", header, body)
+	if err != nil || delivery.Status != models.WebhookDeliveryFiltered || delivery.Rendered == "" {
+		t.Fatalf("expected the push to be filtered, got %+v, %v", delivery, err)
+	}
+
+	repo.endpoints[2].Filters = []models.WebhookFilter{
+		{Path: "$.ref", Op: inbound.FilterIn, Values: []string{"refs/heads/main", "refs/heads/release"}},
+		{Path: "header:X-GitHub-Event", Op: inbound.FilterEq, Values: []string{"push"}},
+	}
+	if delivery, err = svc.Receive("tok-open", header, body); err != nil || delivery.Status != models.WebhookDeliveryDelivered {
+		t.Fatalf("expected the push to be delivered, got %+v, %v", delivery, err)
+	}
+
+	// A push is info, below the minimum severity
+	repo.endpoints[2].MinSeverity = "warning"
+	if delivery, err = svc.Receive("tok-open", header, body); err != nil || delivery.Status != models.WebhookDeliveryFiltered {
+		t.Fatalf("expected the push to be filtered by severity, got %+v, %v", delivery, err)
+	}
+	if len(srv.Messages(42)) != 1 {
+		t.Fatalf("expected only the matching push to be posted, got %d", len(srv.Messages(42)))
+	}
+
+	id := uint(2)
+	result, err := svc.DryRun(3, &id, &WebhookDryRunInput{Headers: map[string]string{"X-GitHub-Event": "push"}, Payload: body})
+	if err != nil || !result.Filtered || result.Severity != notifier.SeverityInfo {
+		t.Fatalf("expected the dry run to report the filter, got %+v, %v", result, err)
+	}
+
+	name, room, bot := "Bad", "42", uint(7)
+	bad := []models.WebhookFilter{{Path: "$.ref", Op: "like", Values: []string{"main"}}}
+	if _, err := svc.Create(3, &WebhookEndpointInput{Name: &name, RoomID: &room, BotID: &bot, Filters: &bad}); err == nil {
+		t.Fatal("expected an unknown filter op to be rejected")
+	}
+	start, end := "22:00", "22:00"
+	if _, err := svc.Create(3, &WebhookEndpointInput{Name: &name, RoomID: &room, BotID: &bot, QuietStart: &start, QuietEnd: &end}); err == nil {
+		t.Fatal("expected an empty quiet window to be rejected")
+	}
+}
+
+func TestWebhookEndpointDedup(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	now := time.Date(2025, 10, 9, 10, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	repo.endpoints[4] = &models.WebhookEndpoint{
+		ID: 4, ProjectID: 3, Name: "Uptime", Token: "tok-up", BotID: 7, RoomID: "42", Active: true,
+		Template:           `[info][title]{{ .check }} is {{ .state }}[/title]{{ .detail }}[/info]`,
+		DedupWindowMinutes: 10,
+		DedupKeys:          []string{"$.check", "$.state"},
+	}
+	down := func(detail string) []byte {
+		return []byte(`{"check":"api","state":"down","detail":"` + detail + `"}`)
+	}
+
+	var statuses []string
+	for i, payload := range [][]byte{down("timeout"), down("502"), down("timeout"), []byte(`{"check":"db","state":"down"}`)} {
+		now = now.Add(time.Minute)
+		delivery, err := svc.Receive("tok-up", http.Header{}, payload)
+		if err != nil {
+			t.Fatalf("Receive %d: %v", i, err)
+		}
+		statuses = append(statuses, delivery.Status)
+	}
+	want := []string{models.WebhookDeliveryDelivered, models.WebhookDeliveryMerged, models.WebhookDeliveryMerged, models.WebhookDeliveryDelivered}
+	if !slices.Equal(statuses, want) {
+		t.Fatalf("got statuses %v, want %v", statuses, want)
+	}
+	if len(srv.Messages(42)) != 2 {
+		t.Fatalf("expected one message per distinct event, got %d", len(srv.Messages(42)))
+	}
+
+	// Nothing is due before the window closes
+	if err := svc.FlushDue(); err != nil || len(srv.Messages(42)) != 2 {
+		t.Fatalf("expected nothing to flush yet, got %v", err)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if err := svc.FlushDue(); err != nil {
+		t.Fatalf("FlushDue: %v", err)
+	}
+	messages := srv.Messages(42)
+	if len(messages) != 3 || !strings.Contains(messages[2].Body, "api is down: 2 more time(s) between 10:01 and 10:03") {
+		t.Fatalf("expected one repeat count for api, got %+v", messages)
+	}
+	if len(repo.buffered) != 0 {
+		t.Fatalf("expected flushed windows to be dropped, got %+v", repo.buffered)
+	}
+	if last := repo.deliveries[len(repo.deliveries)-1]; last.EventType != "repeats" || last.Status != models.WebhookDeliveryDelivered {
+		t.Fatalf("expected the repeat count in the delivery history, got %+v", last)
+	}
+
+	// After the window an identical event goes out again
+	if delivery, err := svc.Receive("tok-up", http.Header{}, down("timeout")); err != nil || delivery.Status != models.WebhookDeliveryDelivered {
+		t.Fatalf("expected a new window, got %+v, %v", delivery, err)
+	}
+}
+
+func TestWebhookEndpointQuietHours(t *testing.T) {
+	svc, repo, srv := newWebhookEndpointTestEnv(t)
+	// 23:30 in Tokyo
+	now := time.Date(2025, 10, 9, 14, 30, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	repo.endpoints[2].QuietStart = "22:00"
+	repo.endpoints[2].QuietEnd = "07:00"
+	repo.endpoints[2].QuietTimezone = "Asia/Tokyo"
+
+	alert := func(name, severity string) []byte {
+		return []byte(`{"version":"4","groupKey":"{}:{}","status":"firing","receiver":"chatwork","alerts":[{"status":"firing","labels":{"alertname":"` + name + `","severity":"` + severity + `"},"annotations":{"summary":"` + name + `"}}]}`)
+	}
+	for _, payload := range [][]byte{alert("DiskFull", "warning"), alert("DiskFull", "warning"), alert("HighLatency", "info")} {
+		delivery, err := svc.Receive("tok-open", http.Header{}, payload)
+		if err != nil || delivery.Status != models.WebhookDeliveryBuffered {
+			t.Fatalf("expected the alert to be held, got %+v, %v", delivery, err)
+		}
+	}
+
+	// Critical events still go out during quiet hours
+	delivery, err := svc.Receive("tok-open", http.Header{}, alert("DatabaseDown", "critical"))
+	if err != nil || delivery.Status != models.WebhookDeliveryDelivered {
+		t.Fatalf("expected the critical alert to be sent, got %+v, %v", delivery, err)
+	}
+	if len(srv.Messages(42)) != 1 || len(repo.buffered) != 2 {
+		t.Fatalf("expected 1 message and 2 held events, got %d and %d", len(srv.Messages(42)), len(repo.buffered))
+	}
+	if flushAt := repo.buffered[0].FlushAt; !flushAt.Equal(time.Date(2025, 10, 9, 22, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the digest at 07:00 Tokyo, got %v", flushAt)
+	}
+
+	now = time.Date(2025, 10, 9, 22, 0, 0, 0, time.UTC)
+	if err := svc.FlushDue(); err != nil {
+		t.Fatalf("FlushDue: %v", err)
+	}
+	messages := srv.Messages(42)
+	if len(messages) != 2 {
+		t.Fatalf("expected one digest, got %d messages", len(messages))
+	}
+	digest := messages[1].Body
+	for _, want := range []string{"Quiet hours digest: 3 event(s)", "23:30", "(×2)"} {
+		if !strings.Contains(digest, want) {
+			t.Errorf("expected %q in the digest:\n%s", want, digest)
+		}
+	}
+
+	// Outside quiet hours events are sent straight away
+	if delivery, err := svc.Receive("tok-open", http.Header{}, alert("DiskFull", "warning")); err != nil || delivery.Status != models.WebhookDeliveryDelivered {
+		t.Fatalf("expected the alert to be sent, got %+v, %v", delivery, err)
+	}
+}
@@ -0,0 +1,689 @@
+// Package chatworktest provides an in-memory fake of the Chatwork API v2 for tests.
+package chatworktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+)
+
+// Request is a call received by the fake server.
+type Request struct {
+	Method string
+	Path   string
+	Token  string
+	Form   map[string]string
+}
+
+type room struct {
+	info     chatwork.Room
+	members  []chatwork.Member
+	messages []chatwork.Message
+	tasks    []chatwork.Task
+	files    []chatwork.File
+}
+
+type failure struct {
+	status int
+	errors []string
+}
+
+// Server is a fake Chatwork API. Accounts are registered per token; rooms are
+// only visible to tokens whose account is a member.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	accounts  map[string]chatwork.Me
+	rooms     map[int64]*room
+	contacts  map[string][]chatwork.Contact
+	incoming  map[string][]chatwork.IncomingRequest
+	failures  map[string][]failure
+	rateLimit *chatwork.RateLimit
+	requests  []Request
+	nextID    int64
+}
+
+// NewServer starts a fake server; call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		accounts: make(map[string]chatwork.Me),
+		rooms:    make(map[int64]*room),
+		contacts: make(map[string][]chatwork.Contact),
+		incoming: make(map[string][]chatwork.IncomingRequest),
+		failures: make(map[string][]failure),
+		nextID:   1000,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /me", s.handleMe)
+	mux.HandleFunc("GET /contacts", s.handleContacts)
+	mux.HandleFunc("GET /incoming_requests", s.handleIncomingRequests)
+	mux.HandleFunc("PUT /incoming_requests/{request_id}", s.handleAcceptRequest)
+	mux.HandleFunc("DELETE /incoming_requests/{request_id}", s.handleRejectRequest)
+	mux.HandleFunc("GET /rooms", s.handleRooms)
+	mux.HandleFunc("POST /rooms", s.handleCreateRoom)
+	mux.HandleFunc("GET /rooms/{room_id}", s.withRoom(s.handleRoom))
+	mux.HandleFunc("PUT /rooms/{room_id}", s.withRoom(s.handleUpdateRoom))
+	mux.HandleFunc("DELETE /rooms/{room_id}", s.withRoom(s.handleDeleteRoom))
+	mux.HandleFunc("GET /rooms/{room_id}/members", s.withRoom(s.handleMembers))
+	mux.HandleFunc("PUT /rooms/{room_id}/members", s.withRoom(s.handleUpdateMembers))
+	mux.HandleFunc("GET /rooms/{room_id}/messages", s.withRoom(s.handleMessages))
+	mux.HandleFunc("POST /rooms/{room_id}/messages", s.withRoom(s.handlePostMessage))
+	mux.HandleFunc("PUT /rooms/{room_id}/messages/read", s.withRoom(s.handleReadStatus))
+	mux.HandleFunc("PUT /rooms/{room_id}/messages/unread", s.withRoom(s.handleReadStatus))
+	mux.HandleFunc("GET /rooms/{room_id}/messages/{message_id}", s.withRoom(s.handleMessage))
+	mux.HandleFunc("PUT /rooms/{room_id}/messages/{message_id}", s.withRoom(s.handleUpdateMessage))
+	mux.HandleFunc("DELETE /rooms/{room_id}/messages/{message_id}", s.withRoom(s.handleDeleteMessage))
+	mux.HandleFunc("GET /rooms/{room_id}/tasks", s.withRoom(s.handleTasks))
+	mux.HandleFunc("POST /rooms/{room_id}/tasks", s.withRoom(s.handleCreateTask))
+	mux.HandleFunc("GET /rooms/{room_id}/tasks/{task_id}", s.withRoom(s.handleTask))
+	mux.HandleFunc("PUT /rooms/{room_id}/tasks/{task_id}/status", s.withRoom(s.handleTaskStatus))
+	mux.HandleFunc("GET /rooms/{room_id}/files", s.withRoom(s.handleFiles))
+	mux.HandleFunc("POST /rooms/{room_id}/files", s.withRoom(s.handleUploadFile))
+	mux.HandleFunc("GET /rooms/{room_id}/files/{file_id}", s.withRoom(s.handleFile))
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// ── setup ────────────────────────────────────────────────────────────────────
+
+// AddAccount registers the account that token authenticates as.
+func (s *Server) AddAccount(token string, me chatwork.Me) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[token] = me
+}
+
+// AddRoom creates a room with the given members.
+func (s *Server) AddRoom(info chatwork.Room, members ...chatwork.Member) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if info.Type == "" {
+		info.Type = chatwork.RoomTypeGroup
+	}
+	s.rooms[info.RoomID] = &room{info: info, members: members}
+}
+
+func (s *Server) AddContact(token string, contact chatwork.Contact) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contacts[token] = append(s.contacts[token], contact)
+}
+
+func (s *Server) AddIncomingRequest(token string, request chatwork.IncomingRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.incoming[token] = append(s.incoming[token], request)
+}
+
+// SetRateLimit makes every response carry the given x-ratelimit-* headers.
+// A limit with Remaining 0 answers 429 until Reset.
+func (s *Server) SetRateLimit(limit *chatwork.RateLimit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimit = limit
+}
+
+// FailNext makes the next request matching method and path fail with status.
+func (s *Server) FailNext(method, path string, status int, errors ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := method + " " + path
+	s.failures[key] = append(s.failures[key], failure{status: status, errors: errors})
+}
+
+// ── inspection ───────────────────────────────────────────────────────────────
+
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) Messages(roomID int64) []chatwork.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.rooms[roomID]; ok {
+		return append([]chatwork.Message(nil), r.messages...)
+	}
+	return nil
+}
+
+func (s *Server) Tasks(roomID int64) []chatwork.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.rooms[roomID]; ok {
+		return append([]chatwork.Task(nil), r.tasks...)
+	}
+	return nil
+}
+
+func (s *Server) Contacts(token string) []chatwork.Contact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]chatwork.Contact(nil), s.contacts[token]...)
+}
+
+func (s *Server) IncomingRequests(token string) []chatwork.IncomingRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]chatwork.IncomingRequest(nil), s.incoming[token]...)
+}
+
+// ── plumbing ─────────────────────────────────────────────────────────────────
+
+// middleware records the request, applies rate limits and forced failures and authenticates the token.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-ChatWorkToken")
+		form := map[string]string{}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			if err := r.ParseMultipartForm(10 << 20); err == nil {
+				for k, v := range r.MultipartForm.Value {
+					form[k] = v[0]
+				}
+			}
+		} else if err := r.ParseForm(); err == nil {
+			// net/http ignores DELETE bodies, but Chatwork reads action_type from one.
+			if r.Method == http.MethodDelete {
+				body, _ := io.ReadAll(r.Body)
+				values, _ := url.ParseQuery(string(body))
+				for k, v := range values {
+					r.Form[k] = append(r.Form[k], v...)
+				}
+			}
+			for k, v := range r.Form {
+				form[k] = v[0]
+			}
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Token: token, Form: form})
+		var limit *chatwork.RateLimit
+		exhausted := false
+		if s.rateLimit != nil {
+			exhausted = s.rateLimit.Remaining == 0 && time.Now().Before(s.rateLimit.Reset)
+			if s.rateLimit.Remaining > 0 {
+				s.rateLimit.Remaining--
+			}
+			current := *s.rateLimit
+			limit = &current
+		}
+		key := r.Method + " " + r.URL.Path
+		var fail *failure
+		if queue := s.failures[key]; len(queue) > 0 {
+			fail = &queue[0]
+			s.failures[key] = queue[1:]
+		}
+		_, known := s.accounts[token]
+		s.mu.Unlock()
+
+		if limit != nil {
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(limit.Reset.Unix(), 10))
+			if exhausted && fail == nil {
+				writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+		}
+		if fail != nil {
+			writeError(w, fail.status, fail.errors...)
+			return
+		}
+		if !known {
+			writeError(w, http.StatusUnauthorized, "Invalid API token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withRoom resolves {room_id} and checks that the caller is a member.
+func (s *Server) withRoom(handler func(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		roomID, err := strconv.ParseInt(r.PathValue("room_id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid room_id")
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		me := s.accounts[r.Header.Get("X-ChatWorkToken")]
+		rm, ok := s.rooms[roomID]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Room not found")
+			return
+		}
+		if memberIndex(rm, me.AccountID) < 0 {
+			writeError(w, http.StatusForbidden, "You don't have permission to access this room")
+			return
+		}
+		handler(w, r, rm, me)
+	}
+}
+
+func memberIndex(rm *room, accountID int64) int {
+	for i, m := range rm.members {
+		if m.AccountID == accountID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
+
+func writeError(w http.ResponseWriter, status int, errors ...string) {
+	if len(errors) == 0 {
+		errors = []string{http.StatusText(status)}
+	}
+	writeJSON(w, status, map[string][]string{"errors": errors})
+}
+
+func parseIDs(s string) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func accountOf(me chatwork.Me) chatwork.Account {
+	return chatwork.Account{AccountID: me.AccountID, Name: me.Name, AvatarImageURL: me.AvatarImageURL}
+}
+
+// ── handlers ─────────────────────────────────────────────────────────────────
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.accounts[r.Header.Get("X-ChatWorkToken")])
+}
+
+func (s *Server) handleContacts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	contacts := s.contacts[r.Header.Get("X-ChatWorkToken")]
+	if len(contacts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, contacts)
+}
+
+func (s *Server) handleIncomingRequests(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := s.incoming[r.Header.Get("X-ChatWorkToken")]
+	if len(requests) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, requests)
+}
+
+// takeRequest removes a pending request of the token's account.
+func (s *Server) takeRequest(r *http.Request) (chatwork.IncomingRequest, bool) {
+	token := r.Header.Get("X-ChatWorkToken")
+	id, _ := strconv.ParseInt(r.PathValue("request_id"), 10, 64)
+	for i, req := range s.incoming[token] {
+		if req.RequestID == id {
+			s.incoming[token] = append(s.incoming[token][:i], s.incoming[token][i+1:]...)
+			return req, true
+		}
+	}
+	return chatwork.IncomingRequest{}, false
+}
+
+func (s *Server) handleAcceptRequest(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req, ok := s.takeRequest(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Request not found")
+		return
+	}
+	contact := chatwork.Contact{
+		AccountID:        req.AccountID,
+		RoomID:           s.newID(),
+		Name:             req.Name,
+		ChatworkID:       req.ChatworkID,
+		OrganizationID:   req.OrganizationID,
+		OrganizationName: req.OrganizationName,
+		Department:       req.Department,
+		AvatarImageURL:   req.AvatarImageURL,
+	}
+	token := r.Header.Get("X-ChatWorkToken")
+	s.contacts[token] = append(s.contacts[token], contact)
+	writeJSON(w, http.StatusOK, contact)
+}
+
+func (s *Server) handleRejectRequest(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.takeRequest(r); !ok {
+		writeError(w, http.StatusNotFound, "Request not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRooms(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	me := s.accounts[r.Header.Get("X-ChatWorkToken")]
+	rooms := []chatwork.Room{}
+	for _, rm := range s.rooms {
+		if i := memberIndex(rm, me.AccountID); i >= 0 {
+			info := rm.info
+			info.Role = rm.members[i].Role
+			info.MessageNum = len(rm.messages)
+			info.TaskNum = len(rm.tasks)
+			info.FileNum = len(rm.files)
+			rooms = append(rooms, info)
+		}
+	}
+	writeJSON(w, http.StatusOK, rooms)
+}
+
+func (s *Server) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.FormValue("name") == "" || r.FormValue("members_admin_ids") == "" {
+		writeError(w, http.StatusBadRequest, "name and members_admin_ids are required")
+		return
+	}
+	rm := &room{info: chatwork.Room{RoomID: s.newID(), Name: r.FormValue("name"), Type: chatwork.RoomTypeGroup, Description: r.FormValue("description")}}
+	for role, field := range map[string]string{chatwork.RoleAdmin: "members_admin_ids", chatwork.RoleMember: "members_member_ids", chatwork.RoleReadonly: "members_readonly_ids"} {
+		for _, id := range parseIDs(r.FormValue(field)) {
+			rm.members = append(rm.members, chatwork.Member{AccountID: id, Role: role})
+		}
+	}
+	s.rooms[rm.info.RoomID] = rm
+	writeJSON(w, http.StatusOK, map[string]int64{"room_id": rm.info.RoomID})
+}
+
+func (s *Server) handleRoom(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	info := rm.info
+	info.Role = rm.members[memberIndex(rm, me.AccountID)].Role
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) handleUpdateRoom(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	if name := r.FormValue("name"); name != "" {
+		rm.info.Name = name
+	}
+	if description := r.FormValue("description"); description != "" {
+		rm.info.Description = description
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"room_id": rm.info.RoomID})
+}
+
+func (s *Server) handleDeleteRoom(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	switch r.FormValue("action_type") {
+	case "leave":
+		i := memberIndex(rm, me.AccountID)
+		rm.members = append(rm.members[:i], rm.members[i+1:]...)
+	case "delete":
+		if rm.members[memberIndex(rm, me.AccountID)].Role != chatwork.RoleAdmin {
+			writeError(w, http.StatusForbidden, "Only admins can delete the room")
+			return
+		}
+		delete(s.rooms, rm.info.RoomID)
+	default:
+		writeError(w, http.StatusBadRequest, "action_type must be leave or delete")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleMembers(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	writeJSON(w, http.StatusOK, rm.members)
+}
+
+func (s *Server) handleUpdateMembers(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	existing := make(map[int64]chatwork.Member)
+	for _, m := range rm.members {
+		existing[m.AccountID] = m
+	}
+	result := chatwork.RoomMembers{
+		Admin:    parseIDs(r.FormValue("members_admin_ids")),
+		Member:   parseIDs(r.FormValue("members_member_ids")),
+		Readonly: parseIDs(r.FormValue("members_readonly_ids")),
+	}
+	var members []chatwork.Member
+	for role, ids := range map[string][]int64{chatwork.RoleAdmin: result.Admin, chatwork.RoleMember: result.Member, chatwork.RoleReadonly: result.Readonly} {
+		for _, id := range ids {
+			m := existing[id]
+			m.AccountID = id
+			m.Role = role
+			members = append(members, m)
+		}
+	}
+	rm.members = members
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	if len(rm.messages) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, rm.messages)
+}
+
+func (s *Server) handlePostMessage(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	body := r.FormValue("body")
+	if body == "" {
+		writeError(w, http.StatusBadRequest, "Parameter [body] is required")
+		return
+	}
+	now := time.Now().Unix()
+	msg := chatwork.Message{MessageID: strconv.FormatInt(s.newID(), 10), Account: accountOf(me), Body: body, SendTime: now}
+	rm.messages = append(rm.messages, msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message_id": msg.MessageID})
+}
+
+func (s *Server) findMessage(rm *room, r *http.Request) int {
+	for i, m := range rm.messages {
+		if m.MessageID == r.PathValue("message_id") {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	i := s.findMessage(rm, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Message not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, rm.messages[i])
+}
+
+func (s *Server) handleUpdateMessage(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	i := s.findMessage(rm, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Message not found")
+		return
+	}
+	rm.messages[i].Body = r.FormValue("body")
+	rm.messages[i].UpdateTime = time.Now().Unix()
+	writeJSON(w, http.StatusOK, map[string]string{"message_id": rm.messages[i].MessageID})
+}
+
+func (s *Server) handleDeleteMessage(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	i := s.findMessage(rm, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Message not found")
+		return
+	}
+	id := rm.messages[i].MessageID
+	rm.messages = append(rm.messages[:i], rm.messages[i+1:]...)
+	writeJSON(w, http.StatusOK, map[string]string{"message_id": id})
+}
+
+func (s *Server) handleReadStatus(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	writeJSON(w, http.StatusOK, chatwork.ReadStatus{})
+}
+
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	tasks := []chatwork.Task{}
+	for _, t := range rm.tasks {
+		if id := r.URL.Query().Get("account_id"); id != "" && id != strconv.FormatInt(t.Account.AccountID, 10) {
+			continue
+		}
+		if status := r.URL.Query().Get("status"); status != "" && status != t.Status {
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	if len(tasks) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	body := r.FormValue("body")
+	toIDs := parseIDs(r.FormValue("to_ids"))
+	if body == "" || len(toIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "body and to_ids are required")
+		return
+	}
+	limit, _ := strconv.ParseInt(r.FormValue("limit"), 10, 64)
+	limitType := r.FormValue("limit_type")
+	if limitType == "" {
+		limitType = chatwork.LimitTypeTime
+		if limit == 0 {
+			limitType = chatwork.LimitTypeNone
+		}
+	}
+
+	messageID := strconv.FormatInt(s.newID(), 10)
+	ids := []int64{}
+	for _, to := range toIDs {
+		i := memberIndex(rm, to)
+		if i < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Account %d is not a member of the room", to))
+			return
+		}
+		task := chatwork.Task{
+			TaskID:            s.newID(),
+			Room:              chatwork.TaskRoom{RoomID: rm.info.RoomID, Name: rm.info.Name},
+			Account:           chatwork.Account{AccountID: to, Name: rm.members[i].Name, AvatarImageURL: rm.members[i].AvatarImageURL},
+			AssignedByAccount: accountOf(me),
+			MessageID:         messageID,
+			Body:              body,
+			LimitTime:         limit,
+			Status:            chatwork.TaskStatusOpen,
+			LimitType:         limitType,
+		}
+		rm.tasks = append(rm.tasks, task)
+		ids = append(ids, task.TaskID)
+	}
+	writeJSON(w, http.StatusOK, map[string][]int64{"task_ids": ids})
+}
+
+func (s *Server) findTask(rm *room, r *http.Request) int {
+	id, _ := strconv.ParseInt(r.PathValue("task_id"), 10, 64)
+	for i, t := range rm.tasks {
+		if t.TaskID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) handleTask(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	i := s.findTask(rm, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Task not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, rm.tasks[i])
+}
+
+func (s *Server) handleTaskStatus(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	i := s.findTask(rm, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Task not found")
+		return
+	}
+	status := r.FormValue("body")
+	if status != chatwork.TaskStatusOpen && status != chatwork.TaskStatusDone {
+		writeError(w, http.StatusBadRequest, "body must be open or done")
+		return
+	}
+	rm.tasks[i].Status = status
+	writeJSON(w, http.StatusOK, map[string]int64{"task_id": rm.tasks[i].TaskID})
+}
+
+func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	if len(rm.files) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, rm.files)
+}
+
+func (s *Server) handleUploadFile(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Parameter [file] is required")
+		return
+	}
+	defer file.Close()
+	size, _ := io.Copy(io.Discard, file)
+
+	messageID := strconv.FormatInt(s.newID(), 10)
+	body := "[download:" + header.Filename + "]"
+	if msg := r.FormValue("message"); msg != "" {
+		body = msg + "\n" + body
+	}
+	rm.messages = append(rm.messages, chatwork.Message{MessageID: messageID, Account: accountOf(me), Body: body, SendTime: time.Now().Unix()})
+
+	f := chatwork.File{FileID: s.newID(), Account: accountOf(me), MessageID: messageID, Filename: header.Filename, Filesize: size, UploadTime: time.Now().Unix()}
+	rm.files = append(rm.files, f)
+	writeJSON(w, http.StatusOK, map[string]int64{"file_id": f.FileID})
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request, rm *room, me chatwork.Me) {
+	id, _ := strconv.ParseInt(r.PathValue("file_id"), 10, 64)
+	for _, f := range rm.files {
+		if f.FileID == id {
+			if r.URL.Query().Get("create_download_url") == "1" {
+				f.DownloadURL = fmt.Sprintf("%s/download/%d", s.URL, f.FileID)
+			}
+			writeJSON(w, http.StatusOK, f)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "File not found")
+}
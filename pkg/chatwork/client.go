@@ -0,0 +1,274 @@
+package chatwork
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the Chatwork API v2 endpoint.
+const DefaultBaseURL = "https://api.chatwork.com/v2"
+
+// DefaultMaxRateLimitWait is how long a request waits for the rate limit
+// window to reset before failing with a rate-limit error.
+const DefaultMaxRateLimitWait = 10 * time.Second
+
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// Client calls the Chatwork API with one account's token.
+// Clients are cheap; create one per token. Rate-limit state and the outbound
+// queue are shared by every client using the same token.
+type Client struct {
+	token        string
+	baseURL      string
+	httpClient   *http.Client
+	limits       *RateLimits
+	limiter      *Limiter
+	maxLimitWait time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL points the client at another API root, e.g. a fake server in tests.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithRateLimits sets where rate-limit headers are tracked. Defaults to a process-wide store.
+func WithRateLimits(limits *RateLimits) Option {
+	return func(c *Client) {
+		if limits != nil {
+			c.limits = limits
+		}
+	}
+}
+
+// WithLimiter sets the outbound queue requests wait in. Defaults to
+// DefaultLimiter; nil sends without client-side throttling.
+func WithLimiter(limiter *Limiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithMaxRateLimitWait sets how long a request may wait for an exhausted
+// rate limit to reset. Zero fails immediately.
+func WithMaxRateLimitWait(d time.Duration) Option {
+	return func(c *Client) {
+		c.maxLimitWait = d
+	}
+}
+
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{
+		token:        token,
+		baseURL:      DefaultBaseURL,
+		httpClient:   defaultHTTPClient,
+		limits:       defaultRateLimits,
+		limiter:      defaultLimiter,
+		maxLimitWait: DefaultMaxRateLimitWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// RateLimit returns the last rate-limit state reported for the client's token.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	return c.limits.Get(c.token)
+}
+
+// RateLimit is the state reported by Chatwork's x-ratelimit-* headers.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimits tracks the latest rate-limit state per token.
+type RateLimits struct {
+	mu     sync.Mutex
+	tokens map[string]RateLimit
+}
+
+var defaultRateLimits = NewRateLimits()
+
+func NewRateLimits() *RateLimits {
+	return &RateLimits{tokens: make(map[string]RateLimit)}
+}
+
+func (r *RateLimits) Get(token string) (RateLimit, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	limit, ok := r.tokens[token]
+	return limit, ok
+}
+
+func (r *RateLimits) set(token string, limit RateLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token] = limit
+}
+
+// parseRateLimit reads the x-ratelimit-* headers; ok is false when they are absent.
+func parseRateLimit(h http.Header) (RateLimit, bool) {
+	limit, err1 := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	remaining, err2 := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	reset, err3 := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return RateLimit{}, false
+	}
+	return RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}, true
+}
+
+// waitForRateLimit blocks while the token's limit is exhausted, up to maxLimitWait.
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	limit, ok := c.limits.Get(c.token)
+	if !ok || limit.Remaining > 0 {
+		return nil
+	}
+	wait := time.Until(limit.Reset)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > c.maxLimitWait {
+		return &APIError{StatusCode: http.StatusTooManyRequests, Errors: []string{"rate limit exhausted until " + limit.Reset.UTC().Format(time.RFC3339)}, RateLimit: &limit}
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// do sends a request and decodes a JSON response into out (when non-nil).
+// A 204 response leaves out untouched. The request first waits its turn in the
+// token's queue, in the lane set on ctx with WithPriority.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string, out any) error {
+	if c.token == "" {
+		return &APIError{StatusCode: http.StatusUnauthorized, Errors: []string{"chatwork API token is empty"}}
+	}
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx, c.token); err != nil {
+			return err
+		}
+	}
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return err
+	}
+
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-ChatWorkToken", c.token)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var rateLimit *RateLimit
+	if limit, ok := parseRateLimit(resp.Header); ok {
+		c.limits.set(c.token, limit)
+		if c.limiter != nil {
+			c.limiter.observe(c.token, limit)
+		}
+		rateLimit = &limit
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp, rateLimit)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, "", out)
+}
+
+// send issues a form-encoded POST, PUT or DELETE.
+func (c *Client) send(ctx context.Context, method, path string, form url.Values, out any) error {
+	if form == nil {
+		return c.do(ctx, method, path, nil, nil, "", out)
+	}
+	return c.do(ctx, method, path, nil, strings.NewReader(form.Encode()), "application/x-www-form-urlencoded", out)
+}
+
+func (c *Client) sendMultipart(ctx context.Context, path string, fields map[string]string, fileField, filename string, data io.Reader, out any) error {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+	part, err := w.CreateFormFile(fileField, filename)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, path, nil, &buf, w.FormDataContentType(), out)
+}
+
+func joinIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func boolParam(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
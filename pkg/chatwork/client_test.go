@@ -0,0 +1,352 @@
+package chatwork_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork/chatworktest"
+)
+
+const (
+	botToken  = "bot-token"
+	botID     = int64(11)
+	aliceID   = int64(22)
+	testRoom  = int64(500)
+	otherRoom = int64(600)
+)
+
+func newFakeServer(t *testing.T) *chatworktest.Server {
+	t.Helper()
+	srv := chatworktest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount(botToken, chatwork.Me{AccountID: botID, Name: "CMS Bot"})
+	srv.AddRoom(chatwork.Room{RoomID: testRoom, Name: "Ops"},
+		chatwork.Member{AccountID: botID, Role: chatwork.RoleAdmin, Name: "CMS Bot"},
+		chatwork.Member{AccountID: aliceID, Role: chatwork.RoleMember, Name: "Alice"},
+	)
+	srv.AddRoom(chatwork.Room{RoomID: otherRoom, Name: "Private"},
+		chatwork.Member{AccountID: aliceID, Role: chatwork.RoleAdmin},
+	)
+	return srv
+}
+
+func newTestClient(srv *chatworktest.Server, token string, opts ...chatwork.Option) *chatwork.Client {
+	opts = append([]chatwork.Option{
+		chatwork.WithBaseURL(srv.URL),
+		chatwork.WithRateLimits(chatwork.NewRateLimits()),
+		chatwork.WithLimiter(nil),
+	}, opts...)
+	return chatwork.NewClient(token, opts...)
+}
+
+func TestClient_MeAndRooms(t *testing.T) {
+	srv := newFakeServer(t)
+	client := newTestClient(srv, botToken)
+	ctx := context.Background()
+
+	me, err := client.Me(ctx)
+	if err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	if me.AccountID != botID || me.Name != "CMS Bot" {
+		t.Fatalf("unexpected me: %+v", me)
+	}
+
+	rooms, err := client.Rooms(ctx)
+	if err != nil {
+		t.Fatalf("Rooms: %v", err)
+	}
+	if len(rooms) != 1 || rooms[0].RoomID != testRoom || rooms[0].Role != chatwork.RoleAdmin {
+		t.Fatalf("expected only the Ops room, got %+v", rooms)
+	}
+
+	members, err := client.Members(ctx, testRoom)
+	if err != nil {
+		t.Fatalf("Members: %v", err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("expected 2 members, got %d", len(members))
+	}
+}
+
+func TestClient_MessagesRoundTrip(t *testing.T) {
+	srv := newFakeServer(t)
+	client := newTestClient(srv, botToken)
+	ctx := context.Background()
+
+	messages, err := client.Messages(ctx, testRoom, true)
+	if err != nil {
+		t.Fatalf("Messages on empty room: %v", err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("expected no messages, got %d", len(messages))
+	}
+
+	id, err := client.PostMessageTo(ctx, " 500 ", "hello [To:22] Alice")
+	if err != nil {
+		t.Fatalf("PostMessageTo: %v", err)
+	}
+	if err := client.UpdateMessage(ctx, testRoom, id, "edited"); err != nil {
+		t.Fatalf("UpdateMessage: %v", err)
+	}
+	msg, err := client.Message(ctx, testRoom, id)
+	if err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+	if msg.Body != "edited" || msg.Account.AccountID != botID {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+
+	if err := client.DeleteMessage(ctx, testRoom, id); err != nil {
+		t.Fatalf("DeleteMessage: %v", err)
+	}
+	if got := srv.Messages(testRoom); len(got) != 0 {
+		t.Fatalf("expected message to be deleted, got %+v", got)
+	}
+}
+
+func TestClient_TasksRoundTrip(t *testing.T) {
+	srv := newFakeServer(t)
+	client := newTestClient(srv, botToken)
+	ctx := context.Background()
+	deadline := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	ids, err := client.CreateTask(ctx, testRoom, chatwork.CreateTaskInput{
+		Body:  "Patch CVE-2024-0001",
+		ToIDs: []int64{aliceID},
+		Limit: deadline.Unix(),
+	})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("expected 1 task ID, got %v", ids)
+	}
+
+	form := srv.Requests()[0].Form
+	if form["to_ids"] != "22" || form["limit"] != "1767366000" {
+		t.Fatalf("unexpected task form: %v", form)
+	}
+
+	if err := client.SetTaskStatus(ctx, testRoom, ids[0], chatwork.TaskStatusDone); err != nil {
+		t.Fatalf("SetTaskStatus: %v", err)
+	}
+	task, err := client.Task(ctx, testRoom, ids[0])
+	if err != nil {
+		t.Fatalf("Task: %v", err)
+	}
+	if task.Status != chatwork.TaskStatusDone || task.LimitType != chatwork.LimitTypeTime || task.LimitTime != deadline.Unix() || task.Account.AccountID != aliceID {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+
+	open, err := client.Tasks(ctx, testRoom, chatwork.TaskFilter{Status: chatwork.TaskStatusOpen})
+	if err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+	if len(open) != 0 {
+		t.Fatalf("expected no open tasks, got %d", len(open))
+	}
+}
+
+func TestClient_IncomingRequests(t *testing.T) {
+	srv := newFakeServer(t)
+	client := newTestClient(srv, botToken)
+	ctx := context.Background()
+
+	requests, err := client.IncomingRequests(ctx)
+	if err != nil {
+		t.Fatalf("IncomingRequests on 204: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("expected no requests, got %d", len(requests))
+	}
+
+	srv.AddIncomingRequest(botToken, chatwork.IncomingRequest{RequestID: 1, AccountID: 33, Name: "Bob"})
+	srv.AddIncomingRequest(botToken, chatwork.IncomingRequest{RequestID: 2, AccountID: 44, Name: "Eve"})
+
+	contact, err := client.AcceptIncomingRequest(ctx, 1)
+	if err != nil {
+		t.Fatalf("AcceptIncomingRequest: %v", err)
+	}
+	if contact.AccountID != 33 || contact.Name != "Bob" {
+		t.Fatalf("unexpected contact: %+v", contact)
+	}
+	if err := client.RejectIncomingRequest(ctx, 2); err != nil {
+		t.Fatalf("RejectIncomingRequest: %v", err)
+	}
+	if err := client.RejectIncomingRequest(ctx, 2); !chatwork.IsNotFound(err) {
+		t.Fatalf("expected not found on second reject, got %v", err)
+	}
+
+	contacts, err := client.Contacts(ctx)
+	if err != nil {
+		t.Fatalf("Contacts: %v", err)
+	}
+	if len(contacts) != 1 || contacts[0].AccountID != 33 {
+		t.Fatalf("unexpected contacts: %+v", contacts)
+	}
+}
+
+func TestClient_RoomLifecycle(t *testing.T) {
+	srv := newFakeServer(t)
+	client := newTestClient(srv, botToken)
+	ctx := context.Background()
+
+	roomID, err := client.CreateRoom(ctx, chatwork.CreateRoomInput{
+		Name:    "Incident",
+		Members: chatwork.RoomMembers{Admin: []int64{botID}, Member: []int64{aliceID}},
+	})
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if err := client.UpdateRoom(ctx, roomID, chatwork.UpdateRoomInput{Name: "Incident #1"}); err != nil {
+		t.Fatalf("UpdateRoom: %v", err)
+	}
+	room, err := client.Room(ctx, roomID)
+	if err != nil {
+		t.Fatalf("Room: %v", err)
+	}
+	if room.Name != "Incident #1" {
+		t.Fatalf("expected renamed room, got %q", room.Name)
+	}
+	if err := client.DeleteRoom(ctx, roomID); err != nil {
+		t.Fatalf("DeleteRoom: %v", err)
+	}
+	if _, err := client.Room(ctx, roomID); !chatwork.IsNotFound(err) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestClient_UploadFile(t *testing.T) {
+	srv := newFakeServer(t)
+	client := newTestClient(srv, botToken)
+	ctx := context.Background()
+
+	fileID, err := client.UploadFile(ctx, testRoom, "report.csv", strings.NewReader("id,score\nCVE-1,9.8\n"), "Weekly report")
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	file, err := client.File(ctx, testRoom, fileID, true)
+	if err != nil {
+		t.Fatalf("File: %v", err)
+	}
+	if file.Filename != "report.csv" || file.Filesize != 19 || file.DownloadURL == "" {
+		t.Fatalf("unexpected file: %+v", file)
+	}
+	messages := srv.Messages(testRoom)
+	if len(messages) != 1 || !strings.HasPrefix(messages[0].Body, "Weekly report") {
+		t.Fatalf("expected upload message, got %+v", messages)
+	}
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	srv := newFakeServer(t)
+	ctx := context.Background()
+
+	_, err := newTestClient(srv, "wrong-token").Me(ctx)
+	if !chatwork.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Invalid API token") {
+		t.Fatalf("expected server message in error, got %q", err.Error())
+	}
+
+	client := newTestClient(srv, botToken)
+	if _, err := client.PostMessage(ctx, otherRoom, "hi"); !chatwork.IsForbidden(err) {
+		t.Fatalf("expected forbidden for non-member room, got %v", err)
+	}
+	if _, err := client.PostMessageTo(ctx, "not-a-room", "hi"); err == nil {
+		t.Fatal("expected error for invalid room ID")
+	}
+
+	srv.FailNext(http.MethodGet, "/me", http.StatusInternalServerError, "boom")
+	_, err = client.Me(ctx)
+	apiErr, ok := err.(*chatwork.APIError)
+	if !ok || apiErr.StatusCode != http.StatusInternalServerError || apiErr.Errors[0] != "boom" {
+		t.Fatalf("expected APIError 500 boom, got %#v", err)
+	}
+}
+
+func TestClient_RateLimitTracking(t *testing.T) {
+	srv := newFakeServer(t)
+	ctx := context.Background()
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	srv.SetRateLimit(&chatwork.RateLimit{Limit: 300, Remaining: 2, Reset: reset})
+
+	limits := chatwork.NewRateLimits()
+	client := newTestClient(srv, botToken, chatwork.WithRateLimits(limits), chatwork.WithMaxRateLimitWait(time.Second))
+
+	if _, err := client.Me(ctx); err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	limit, ok := client.RateLimit()
+	if !ok || limit.Limit != 300 || limit.Remaining != 1 || !limit.Reset.Equal(reset) {
+		t.Fatalf("unexpected rate limit: %+v (ok=%v)", limit, ok)
+	}
+
+	// Another client for the same token shares the store.
+	shared := newTestClient(srv, botToken, chatwork.WithRateLimits(limits), chatwork.WithMaxRateLimitWait(time.Second))
+	if _, err := shared.Me(ctx); err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+
+	// The limit is now exhausted and resets beyond the allowed wait, so the
+	// client fails fast without calling the server.
+	before := len(srv.Requests())
+	_, err := client.Me(ctx)
+	if !chatwork.IsRateLimited(err) {
+		t.Fatalf("expected rate-limited error, got %v", err)
+	}
+	if len(srv.Requests()) != before {
+		t.Fatal("expected no request while the limit is exhausted")
+	}
+}
+
+func TestClient_RateLimitWaitsForReset(t *testing.T) {
+	srv := newFakeServer(t)
+	ctx := context.Background()
+	reset := time.Now().Add(1500 * time.Millisecond).Truncate(time.Second).Add(time.Second)
+	srv.SetRateLimit(&chatwork.RateLimit{Limit: 1, Remaining: 1, Reset: reset})
+
+	client := newTestClient(srv, botToken, chatwork.WithMaxRateLimitWait(5*time.Second))
+	if _, err := client.Me(ctx); err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+
+	srv.SetRateLimit(&chatwork.RateLimit{Limit: 1, Remaining: 1, Reset: reset.Add(time.Minute)})
+	if _, err := client.Me(ctx); err != nil {
+		t.Fatalf("expected the second call to wait for the reset, got %v", err)
+	}
+	if time.Now().Before(reset) {
+		t.Fatal("expected the second call to happen after the reset")
+	}
+
+	// A cancelled context stops the wait.
+	srv.SetRateLimit(&chatwork.RateLimit{Limit: 1, Remaining: 0, Reset: time.Now().Add(2 * time.Second)})
+	waiting := newTestClient(srv, botToken, chatwork.WithMaxRateLimitWait(5*time.Second))
+	if _, err := waiting.Me(ctx); !chatwork.IsRateLimited(err) {
+		t.Fatalf("expected 429 from the server, got %v", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := waiting.Me(cancelled); err != context.Canceled {
+		t.Fatalf("expected context cancellation while waiting, got %v", err)
+	}
+}
+
+func TestParseRoomID(t *testing.T) {
+	cases := map[string]int64{"123": 123, " 123 ": 123, "#!rid123": 123, "rid42": 42}
+	for in, want := range cases {
+		got, err := chatwork.ParseRoomID(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseRoomID(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := chatwork.ParseRoomID("abc"); err == nil {
+		t.Fatal("expected error for non-numeric room ID")
+	}
+}
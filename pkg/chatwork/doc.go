@@ -0,0 +1,17 @@
+// Package chatwork is a client for the Chatwork API v2 and a builder for
+// Chatwork message markup.
+//
+// A Client covers the account, contacts, incoming requests, rooms, members,
+// messages, tasks and files endpoints. It takes a context on every call,
+// returns *APIError for non-2xx responses and tracks the x-ratelimit-*
+// headers per token, waiting briefly for an exhausted limit to reset.
+//
+// A Builder collects typed blocks ([info], [title], [hr], [code], [qt],
+// [task], mentions and text) and renders them as one body, or as ordered
+// parts that each fit under the Chatwork body limit.
+//
+// ParseWebhook, VerifyWebhook and Mentions handle the requests Chatwork's
+// outgoing webhooks send when a bot is mentioned.
+//
+// Package chatworktest provides a fake Chatwork server for tests.
+package chatwork
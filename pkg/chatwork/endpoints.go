@@ -0,0 +1,336 @@
+package chatwork
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ── account ──────────────────────────────────────────────────────────────────
+
+func (c *Client) Me(ctx context.Context) (*Me, error) {
+	var me Me
+	if err := c.get(ctx, "/me", nil, &me); err != nil {
+		return nil, err
+	}
+	return &me, nil
+}
+
+func (c *Client) Contacts(ctx context.Context) ([]Contact, error) {
+	contacts := []Contact{}
+	if err := c.get(ctx, "/contacts", nil, &contacts); err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// ── incoming requests ────────────────────────────────────────────────────────
+
+// IncomingRequests lists pending contact requests. Chatwork answers 204 when there are none.
+func (c *Client) IncomingRequests(ctx context.Context) ([]IncomingRequest, error) {
+	requests := []IncomingRequest{}
+	if err := c.get(ctx, "/incoming_requests", nil, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// AcceptIncomingRequest accepts a contact request and returns the new contact.
+func (c *Client) AcceptIncomingRequest(ctx context.Context, requestID int64) (*Contact, error) {
+	var contact Contact
+	if err := c.send(ctx, http.MethodPut, fmt.Sprintf("/incoming_requests/%d", requestID), nil, &contact); err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// RejectIncomingRequest declines a contact request.
+func (c *Client) RejectIncomingRequest(ctx context.Context, requestID int64) error {
+	return c.send(ctx, http.MethodDelete, fmt.Sprintf("/incoming_requests/%d", requestID), nil, nil)
+}
+
+// ── rooms ────────────────────────────────────────────────────────────────────
+
+func (c *Client) Rooms(ctx context.Context) ([]Room, error) {
+	rooms := []Room{}
+	if err := c.get(ctx, "/rooms", nil, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (c *Client) Room(ctx context.Context, roomID int64) (*Room, error) {
+	var room Room
+	if err := c.get(ctx, roomPath(roomID, ""), nil, &room); err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// CreateRoom creates a group chat and returns its room ID.
+func (c *Client) CreateRoom(ctx context.Context, input CreateRoomInput) (int64, error) {
+	form := url.Values{}
+	form.Set("name", input.Name)
+	form.Set("members_admin_ids", joinIDs(input.Members.Admin))
+	if len(input.Members.Member) > 0 {
+		form.Set("members_member_ids", joinIDs(input.Members.Member))
+	}
+	if len(input.Members.Readonly) > 0 {
+		form.Set("members_readonly_ids", joinIDs(input.Members.Readonly))
+	}
+	if input.Description != "" {
+		form.Set("description", input.Description)
+	}
+	if input.IconPreset != "" {
+		form.Set("icon_preset", input.IconPreset)
+	}
+
+	var out struct {
+		RoomID int64 `json:"room_id"`
+	}
+	if err := c.send(ctx, http.MethodPost, "/rooms", form, &out); err != nil {
+		return 0, err
+	}
+	return out.RoomID, nil
+}
+
+// UpdateRoom changes the non-empty fields of a room.
+func (c *Client) UpdateRoom(ctx context.Context, roomID int64, input UpdateRoomInput) error {
+	form := url.Values{}
+	if input.Name != "" {
+		form.Set("name", input.Name)
+	}
+	if input.Description != "" {
+		form.Set("description", input.Description)
+	}
+	if input.IconPreset != "" {
+		form.Set("icon_preset", input.IconPreset)
+	}
+	return c.send(ctx, http.MethodPut, roomPath(roomID, ""), form, nil)
+}
+
+// LeaveRoom leaves a group chat.
+func (c *Client) LeaveRoom(ctx context.Context, roomID int64) error {
+	return c.send(ctx, http.MethodDelete, roomPath(roomID, ""), url.Values{"action_type": {"leave"}}, nil)
+}
+
+// DeleteRoom deletes a group chat; only room admins can do this.
+func (c *Client) DeleteRoom(ctx context.Context, roomID int64) error {
+	return c.send(ctx, http.MethodDelete, roomPath(roomID, ""), url.Values{"action_type": {"delete"}}, nil)
+}
+
+// ── members ──────────────────────────────────────────────────────────────────
+
+func (c *Client) Members(ctx context.Context, roomID int64) ([]Member, error) {
+	members := []Member{}
+	if err := c.get(ctx, roomPath(roomID, "/members"), nil, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// UpdateMembers replaces the room's member list and returns the resulting roles.
+func (c *Client) UpdateMembers(ctx context.Context, roomID int64, members RoomMembers) (*RoomMembers, error) {
+	form := url.Values{}
+	form.Set("members_admin_ids", joinIDs(members.Admin))
+	if len(members.Member) > 0 {
+		form.Set("members_member_ids", joinIDs(members.Member))
+	}
+	if len(members.Readonly) > 0 {
+		form.Set("members_readonly_ids", joinIDs(members.Readonly))
+	}
+
+	var out RoomMembers
+	if err := c.send(ctx, http.MethodPut, roomPath(roomID, "/members"), form, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ── messages ─────────────────────────────────────────────────────────────────
+
+// Messages lists up to 100 messages. With force false only messages since the
+// last call are returned; Chatwork answers 204 when there are none.
+func (c *Client) Messages(ctx context.Context, roomID int64, force bool) ([]Message, error) {
+	messages := []Message{}
+	query := url.Values{"force": {boolParam(force)}}
+	if err := c.get(ctx, roomPath(roomID, "/messages"), query, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (c *Client) Message(ctx context.Context, roomID int64, messageID string) (*Message, error) {
+	var message Message
+	if err := c.get(ctx, roomPath(roomID, "/messages/"+url.PathEscape(messageID)), nil, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// PostMessage sends body to the room and returns the new message ID.
+func (c *Client) PostMessage(ctx context.Context, roomID int64, body string) (string, error) {
+	var out struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := c.send(ctx, http.MethodPost, roomPath(roomID, "/messages"), url.Values{"body": {body}}, &out); err != nil {
+		return "", err
+	}
+	return out.MessageID, nil
+}
+
+// PostMessageTo is PostMessage for callers that store room IDs as strings.
+func (c *Client) PostMessageTo(ctx context.Context, roomID string, body string) (string, error) {
+	id, err := ParseRoomID(roomID)
+	if err != nil {
+		return "", err
+	}
+	return c.PostMessage(ctx, id, body)
+}
+
+func (c *Client) UpdateMessage(ctx context.Context, roomID int64, messageID, body string) error {
+	return c.send(ctx, http.MethodPut, roomPath(roomID, "/messages/"+url.PathEscape(messageID)), url.Values{"body": {body}}, nil)
+}
+
+func (c *Client) DeleteMessage(ctx context.Context, roomID int64, messageID string) error {
+	return c.send(ctx, http.MethodDelete, roomPath(roomID, "/messages/"+url.PathEscape(messageID)), nil, nil)
+}
+
+// MarkRead marks messages up to messageID as read; an empty ID marks every message.
+func (c *Client) MarkRead(ctx context.Context, roomID int64, messageID string) (*ReadStatus, error) {
+	form := url.Values{}
+	if messageID != "" {
+		form.Set("message_id", messageID)
+	}
+	var status ReadStatus
+	if err := c.send(ctx, http.MethodPut, roomPath(roomID, "/messages/read"), form, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// MarkUnread marks messages from messageID onwards as unread.
+func (c *Client) MarkUnread(ctx context.Context, roomID int64, messageID string) (*ReadStatus, error) {
+	var status ReadStatus
+	if err := c.send(ctx, http.MethodPut, roomPath(roomID, "/messages/unread"), url.Values{"message_id": {messageID}}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ── tasks ────────────────────────────────────────────────────────────────────
+
+func (c *Client) Tasks(ctx context.Context, roomID int64, filter TaskFilter) ([]Task, error) {
+	query := url.Values{}
+	if filter.AccountID != 0 {
+		query.Set("account_id", strconv.FormatInt(filter.AccountID, 10))
+	}
+	if filter.AssignedByAccountID != 0 {
+		query.Set("assigned_by_account_id", strconv.FormatInt(filter.AssignedByAccountID, 10))
+	}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+
+	tasks := []Task{}
+	if err := c.get(ctx, roomPath(roomID, "/tasks"), query, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (c *Client) Task(ctx context.Context, roomID, taskID int64) (*Task, error) {
+	var task Task
+	if err := c.get(ctx, roomPath(roomID, fmt.Sprintf("/tasks/%d", taskID)), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTask assigns a task to every account in input.ToIDs and returns the task IDs.
+func (c *Client) CreateTask(ctx context.Context, roomID int64, input CreateTaskInput) ([]int64, error) {
+	form := url.Values{}
+	form.Set("body", input.Body)
+	form.Set("to_ids", joinIDs(input.ToIDs))
+	if input.Limit > 0 {
+		form.Set("limit", strconv.FormatInt(input.Limit, 10))
+	}
+	if input.LimitType != "" {
+		form.Set("limit_type", input.LimitType)
+	}
+
+	var out struct {
+		TaskIDs []int64 `json:"task_ids"`
+	}
+	if err := c.send(ctx, http.MethodPost, roomPath(roomID, "/tasks"), form, &out); err != nil {
+		return nil, err
+	}
+	return out.TaskIDs, nil
+}
+
+// SetTaskStatus marks a task TaskStatusDone or TaskStatusOpen.
+func (c *Client) SetTaskStatus(ctx context.Context, roomID, taskID int64, status string) error {
+	return c.send(ctx, http.MethodPut, roomPath(roomID, fmt.Sprintf("/tasks/%d/status", taskID)), url.Values{"body": {status}}, nil)
+}
+
+// ── files ────────────────────────────────────────────────────────────────────
+
+// Files lists files in the room, optionally only those uploaded by accountID.
+func (c *Client) Files(ctx context.Context, roomID int64, accountID int64) ([]File, error) {
+	query := url.Values{}
+	if accountID != 0 {
+		query.Set("account_id", strconv.FormatInt(accountID, 10))
+	}
+	files := []File{}
+	if err := c.get(ctx, roomPath(roomID, "/files"), query, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// File returns file details, with a temporary download URL when requested.
+func (c *Client) File(ctx context.Context, roomID, fileID int64, withDownloadURL bool) (*File, error) {
+	query := url.Values{}
+	if withDownloadURL {
+		query.Set("create_download_url", "1")
+	}
+	var file File
+	if err := c.get(ctx, roomPath(roomID, fmt.Sprintf("/files/%d", fileID)), query, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// UploadFile posts a file with an optional message and returns the file ID.
+func (c *Client) UploadFile(ctx context.Context, roomID int64, filename string, data io.Reader, message string) (int64, error) {
+	fields := map[string]string{}
+	if message != "" {
+		fields["message"] = message
+	}
+	var out struct {
+		FileID int64 `json:"file_id"`
+	}
+	if err := c.sendMultipart(ctx, roomPath(roomID, "/files"), fields, "file", filename, data, &out); err != nil {
+		return 0, err
+	}
+	return out.FileID, nil
+}
+
+func roomPath(roomID int64, suffix string) string {
+	return fmt.Sprintf("/rooms/%d%s", roomID, suffix)
+}
+
+// ParseRoomID parses a room ID stored as text, as in schedules and channels.
+func ParseRoomID(roomID string) (int64, error) {
+	// Accept IDs copied from room URLs, e.g. "#!rid123456"
+	roomID = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(roomID), "#!"), "rid")
+	id, err := strconv.ParseInt(roomID, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid chatwork room ID %q", roomID)
+	}
+	return id, nil
+}
@@ -0,0 +1,63 @@
+package chatwork
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// APIError is a non-2xx response from the Chatwork API.
+type APIError struct {
+	StatusCode int
+	Errors     []string   // messages from the {"errors": [...]} body
+	RateLimit  *RateLimit // set when the response carried x-ratelimit-* headers
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("chatwork API error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("chatwork API error: status %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+}
+
+func newAPIError(resp *http.Response, rateLimit *RateLimit) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, RateLimit: rateLimit}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	var payload struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && len(payload.Errors) > 0 {
+		apiErr.Errors = payload.Errors
+	} else if text := strings.TrimSpace(string(body)); text != "" {
+		apiErr.Errors = []string{text}
+	}
+	return apiErr
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// IsUnauthorized reports whether the token was rejected.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether the token lacks access, e.g. the bot is not a room member.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsRateLimited reports whether the request hit the token's rate limit.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
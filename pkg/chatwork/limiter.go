@@ -0,0 +1,276 @@
+package chatwork
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Chatwork allows roughly 300 requests per token every 5 minutes.
+const (
+	DefaultRequestsPerWindow = 300
+	DefaultRateWindow        = 5 * time.Minute
+	DefaultBurst             = 60
+)
+
+// Priority selects the lane a request waits in when its token is throttled.
+// Higher lanes are always served first; within a lane requests are FIFO.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota // reminders, hook forwarding, API calls
+	PriorityHigh                   // alerts
+	PriorityLow                    // digests and reports
+)
+
+// lanes lists priorities in the order they are served.
+var lanes = [...]Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+func (p Priority) lane() int {
+	for i, l := range lanes {
+		if l == p {
+			return i
+		}
+	}
+	return 1
+}
+
+type priorityKey struct{}
+
+// WithPriority marks requests made with ctx as belonging to a priority lane.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom returns the lane set by WithPriority, PriorityNormal by default.
+func PriorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// Limiter is the shared outbound queue: a token bucket per API token with
+// priority lanes for requests that have to wait.
+type Limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens        float64
+	last          time.Time
+	lanes         [len(lanes)][]*waiter
+	timer         *time.Timer
+	sent          uint64
+	throttled     uint64
+	lastThrottled time.Time
+}
+
+type waiter struct {
+	ready chan struct{}
+}
+
+var defaultLimiter = NewLimiter(DefaultRequestsPerWindow, DefaultRateWindow, DefaultBurst)
+
+// DefaultLimiter returns the process-wide limiter used by clients without WithLimiter.
+func DefaultLimiter() *Limiter {
+	return defaultLimiter
+}
+
+// NewLimiter allows requests per window for each token, with bursts of up to burst requests.
+func NewLimiter(requests int, window time.Duration, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:    float64(requests) / window.Seconds(),
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Wait blocks until token may send a request. Waiting requests are released
+// in lane order as the bucket refills.
+func (l *Limiter) Wait(ctx context.Context, token string) error {
+	l.mu.Lock()
+	b := l.bucket(token)
+	l.refill(b)
+	if b.queued() == 0 && b.tokens >= 1 {
+		b.tokens--
+		b.sent++
+		l.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{ready: make(chan struct{})}
+	lane := PriorityFrom(ctx).lane()
+	b.lanes[lane] = append(b.lanes[lane], w)
+	b.throttled++
+	b.lastThrottled = l.now()
+	l.schedule(token, b)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, queued := range b.lanes[lane] {
+			if queued == w {
+				b.lanes[lane] = append(b.lanes[lane][:i], b.lanes[lane][i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// Released while cancelling: the slot was used up, so let the caller go ahead.
+		return nil
+	}
+}
+
+// observe caps the bucket at what the server reports as remaining.
+func (l *Limiter) observe(token string, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(token)
+	l.refill(b)
+	if float64(limit.Remaining) < b.tokens {
+		b.tokens = float64(limit.Remaining)
+	}
+}
+
+func (l *Limiter) bucket(token string) *bucket {
+	b, ok := l.buckets[token]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: l.now()}
+		l.buckets[token] = b
+	}
+	return b
+}
+
+func (l *Limiter) refill(b *bucket) {
+	now := l.now()
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+}
+
+func (b *bucket) queued() int {
+	n := 0
+	for _, lane := range b.lanes {
+		n += len(lane)
+	}
+	return n
+}
+
+// dispatch releases waiters while tokens are available; l.mu must be held.
+func (l *Limiter) dispatch(token string, b *bucket) {
+	b.timer = nil
+	l.refill(b)
+	for b.tokens >= 1 {
+		w := b.pop()
+		if w == nil {
+			break
+		}
+		b.tokens--
+		b.sent++
+		close(w.ready)
+	}
+	l.schedule(token, b)
+}
+
+// schedule arms a timer for the next token when requests are waiting; l.mu must be held.
+func (l *Limiter) schedule(token string, b *bucket) {
+	if b.timer != nil || b.queued() == 0 {
+		return
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	if wait < 0 {
+		wait = 0
+	}
+	b.timer = time.AfterFunc(wait, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.dispatch(token, b)
+	})
+}
+
+func (b *bucket) pop() *waiter {
+	for i, lane := range b.lanes {
+		if len(lane) > 0 {
+			b.lanes[i] = lane[1:]
+			return lane[0]
+		}
+	}
+	return nil
+}
+
+// QueueStats describes one token's bucket and waiting requests.
+type QueueStats struct {
+	Token           string         `json:"token"` // masked
+	Depth           int            `json:"depth"`
+	Lanes           map[string]int `json:"lanes"`
+	Available       int            `json:"available"`
+	Sent            uint64         `json:"sent"`
+	Throttled       uint64         `json:"throttled"`
+	LastThrottledAt *time.Time     `json:"lastThrottledAt,omitempty"`
+}
+
+// Stats returns the state of every token seen so far, deepest queue first.
+func (l *Limiter) Stats() []QueueStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make([]QueueStats, 0, len(l.buckets))
+	for token, b := range l.buckets {
+		l.refill(b)
+		s := QueueStats{
+			Token:     MaskToken(token),
+			Depth:     b.queued(),
+			Lanes:     make(map[string]int, len(lanes)),
+			Available: int(b.tokens),
+			Sent:      b.sent,
+			Throttled: b.throttled,
+		}
+		for i, p := range lanes {
+			s.Lanes[p.String()] = len(b.lanes[i])
+		}
+		if !b.lastThrottled.IsZero() {
+			t := b.lastThrottled
+			s.LastThrottledAt = &t
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Depth != stats[j].Depth {
+			return stats[i].Depth > stats[j].Depth
+		}
+		return stats[i].Token < stats[j].Token
+	})
+	return stats
+}
+
+// MaskToken hides all but the last four characters of an API token.
+func MaskToken(token string) string {
+	if len(token) <= 4 {
+		return "****"
+	}
+	return "****" + token[len(token)-4:]
+}
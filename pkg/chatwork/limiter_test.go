@@ -0,0 +1,128 @@
+package chatwork
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForDepth polls until token has depth queued requests.
+func waitForDepth(t *testing.T, l *Limiter, depth int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		stats := l.Stats()
+		if len(stats) > 0 && stats[0].Depth == depth {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("queue never reached depth %d", depth)
+}
+
+func TestLimiter_BurstThenThrottle(t *testing.T) {
+	l := NewLimiter(20, time.Second, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx, "token-a"); err != nil {
+			t.Fatalf("Wait %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected the third request to wait for a refill, took %v", elapsed)
+	}
+
+	// Other tokens have their own bucket.
+	start = time.Now()
+	if err := l.Wait(ctx, "token-b"); err != nil || time.Since(start) > 20*time.Millisecond {
+		t.Fatalf("expected token-b to send immediately, err=%v", err)
+	}
+
+	stats := l.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("expected stats for 2 tokens, got %d", len(stats))
+	}
+	for _, s := range stats {
+		if s.Token == "****en-a" && (s.Sent != 3 || s.Throttled != 1 || s.LastThrottledAt == nil) {
+			t.Fatalf("unexpected stats for token-a: %+v", s)
+		}
+	}
+}
+
+func TestLimiter_PriorityLanes(t *testing.T) {
+	l := NewLimiter(20, time.Second, 1)
+	if err := l.Wait(context.Background(), "token"); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	for i, p := range []Priority{PriorityLow, PriorityNormal, PriorityLow, PriorityHigh} {
+		wg.Add(1)
+		go func(p Priority) {
+			defer wg.Done()
+			if err := l.Wait(WithPriority(context.Background(), p), "token"); err != nil {
+				t.Errorf("Wait(%s): %v", p, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+		}(p)
+		waitForDepth(t, l, i+1)
+	}
+
+	lanes := l.Stats()[0].Lanes
+	if lanes["high"] != 1 || lanes["normal"] != 1 || lanes["low"] != 2 {
+		t.Fatalf("unexpected lanes: %v", lanes)
+	}
+
+	wg.Wait()
+	want := []Priority{PriorityHigh, PriorityNormal, PriorityLow, PriorityLow}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected release order %v, got %v", want, order)
+		}
+	}
+}
+
+func TestLimiter_CancelLeavesQueue(t *testing.T) {
+	l := NewLimiter(1, time.Minute, 1)
+	if err := l.Wait(context.Background(), "token"); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.Wait(ctx, "token") }()
+	waitForDepth(t, l, 1)
+	cancel()
+
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if depth := l.Stats()[0].Depth; depth != 0 {
+		t.Fatalf("expected cancelled request to leave the queue, depth %d", depth)
+	}
+}
+
+func TestLimiter_ObserveCapsTokens(t *testing.T) {
+	l := NewLimiter(1, time.Minute, 10)
+	l.observe("token", RateLimit{Limit: 300, Remaining: 1, Reset: time.Now().Add(time.Minute)})
+	if got := l.Stats()[0].Available; got != 1 {
+		t.Fatalf("expected server-reported remaining to cap the bucket, got %d", got)
+	}
+}
+
+func TestPriorityFromDefaultsToNormal(t *testing.T) {
+	if p := PriorityFrom(context.Background()); p != PriorityNormal {
+		t.Fatalf("expected normal priority, got %s", p)
+	}
+	if got := MaskToken("abcdef123456"); got != "****3456" {
+		t.Fatalf("unexpected mask %q", got)
+	}
+}
@@ -0,0 +1,321 @@
+package chatwork
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is the largest body, in characters, that Split produces by default.
+// It stays below the 65,535 character limit of the Chatwork messages API.
+const MaxMessageLength = 60000
+
+// partMarkerReserve is the room kept in each part for its "(i/n)" marker.
+const partMarkerReserve = 16
+
+// tagPattern matches the opening of every Chatwork tag, so user content cannot inject markup.
+var tagPattern = regexp.MustCompile(`(?i)\[(/?)(info|title|hr|code|qt|qtmeta|to|toall|rp|task|picon|piconname|preview|download|dtext)\b`)
+
+// Escape neutralises Chatwork tags in user content by inserting a zero-width
+// space after the opening bracket. The text looks the same when displayed.
+func Escape(s string) string {
+	return tagPattern.ReplaceAllString(s, "[\u200b$1$2")
+}
+
+type blockKind int
+
+const (
+	kindText blockKind = iota
+	kindHR
+	kindInfo
+	kindGroup
+	kindCode
+	kindQuote
+	kindTask
+)
+
+// block is one unit of a message. Blocks are joined by newlines and are only
+// split across parts when a single block is larger than a part.
+type block struct {
+	kind     blockKind
+	text     string // rendered content, already escaped
+	open     string // opening markup of wrapped blocks, e.g. "[qt][qtmeta aid=1 time=2]"
+	close    string // closing markup of wrapped blocks
+	children []block
+}
+
+// Builder assembles a Chatwork message. Methods return the builder so calls can be chained.
+type Builder struct {
+	blocks []block
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Text adds a line of user content, escaping any Chatwork tags.
+func (b *Builder) Text(s string) *Builder {
+	return b.Raw(Escape(s))
+}
+
+// Textf adds a formatted line; the arguments are escaped, the format is not.
+func (b *Builder) Textf(format string, args ...any) *Builder {
+	escaped := make([]any, len(args))
+	for i, a := range args {
+		if s, ok := a.(string); ok {
+			escaped[i] = Escape(s)
+		} else {
+			escaped[i] = a
+		}
+	}
+	return b.Raw(fmt.Sprintf(format, escaped...))
+}
+
+// Raw adds markup that is sent as is, e.g. a user-authored reminder body.
+func (b *Builder) Raw(s string) *Builder {
+	b.blocks = append(b.blocks, block{kind: kindText, text: s})
+	return b
+}
+
+// HR adds a horizontal rule.
+func (b *Builder) HR() *Builder {
+	b.blocks = append(b.blocks, block{kind: kindHR, text: "[hr]"})
+	return b
+}
+
+// To mentions an account, e.g. "[To:123]Alice".
+func (b *Builder) To(accountID int64, name string) *Builder {
+	return b.Raw(fmt.Sprintf("[To:%d]%s", accountID, Escape(name)))
+}
+
+// ToAll mentions every member of the room.
+func (b *Builder) ToAll() *Builder {
+	return b.Raw("[toall]")
+}
+
+// Reply adds a reply marker to a message posted by accountID.
+func (b *Builder) Reply(accountID int64, roomID int64, messageID string) *Builder {
+	return b.Raw(fmt.Sprintf("[rp aid=%d to=%d-%s]", accountID, roomID, messageID))
+}
+
+// Info adds an [info] box. fill adds the blocks inside it; title may be empty.
+func (b *Builder) Info(title string, fill func(inner *Builder)) *Builder {
+	inner := NewBuilder()
+	if fill != nil {
+		fill(inner)
+	}
+	open := "[info]"
+	if title != "" {
+		open += "[title]" + Escape(title) + "[/title]"
+	}
+	b.blocks = append(b.blocks, block{kind: kindInfo, open: open, close: "[/info]", children: inner.blocks})
+	return b
+}
+
+// Group adds blocks that are kept in the same part whenever they fit,
+// e.g. a section heading and its lines.
+func (b *Builder) Group(fill func(inner *Builder)) *Builder {
+	inner := NewBuilder()
+	fill(inner)
+	b.blocks = append(b.blocks, block{kind: kindGroup, children: inner.blocks})
+	return b
+}
+
+// Code adds a [code] block. Its content is escaped so it renders literally.
+func (b *Builder) Code(s string) *Builder {
+	b.blocks = append(b.blocks, block{kind: kindCode, text: Escape(s), open: "[code]", close: "[/code]"})
+	return b
+}
+
+// Quote adds a [qt] block attributed to accountID; sentAt may be zero.
+func (b *Builder) Quote(accountID int64, sentAt time.Time, s string) *Builder {
+	meta := fmt.Sprintf("[qtmeta aid=%d]", accountID)
+	if !sentAt.IsZero() {
+		meta = fmt.Sprintf("[qtmeta aid=%d time=%d]", accountID, sentAt.Unix())
+	}
+	b.blocks = append(b.blocks, block{kind: kindQuote, text: Escape(s), open: "[qt]" + meta, close: "[/qt]"})
+	return b
+}
+
+// Task adds a task mention for accountID, with an optional deadline.
+func (b *Builder) Task(accountID int64, limit time.Time, s string) *Builder {
+	open := fmt.Sprintf("[task aid=%d st=open]", accountID)
+	if !limit.IsZero() {
+		open = fmt.Sprintf("[task aid=%d st=open lt=%d]", accountID, limit.Unix())
+	}
+	b.blocks = append(b.blocks, block{kind: kindTask, text: Escape(s), open: open, close: "[/task]"})
+	return b
+}
+
+// Append adds the blocks of other, e.g. to send several rendered events as one message.
+func (b *Builder) Append(other *Builder) *Builder {
+	b.blocks = append(b.blocks, other.blocks...)
+	return b
+}
+
+// Len reports the number of top-level blocks.
+func (b *Builder) Len() int {
+	return len(b.blocks)
+}
+
+// String renders the whole message as one body.
+func (b *Builder) String() string {
+	return renderBlocks(b.blocks)
+}
+
+// Split renders the message as ordered parts of at most limit characters.
+// A message that fits is returned as one part without a marker; otherwise each
+// part starts with "(i/n)". Info boxes that span parts are closed and reopened,
+// with their title, in every part. limit <= 0 means MaxMessageLength.
+func (b *Builder) Split(limit int) []string {
+	if limit <= 0 {
+		limit = MaxMessageLength
+	}
+	body := b.String()
+	if length(body) <= limit {
+		return []string{body}
+	}
+
+	budget := limit - partMarkerReserve
+	if budget < 1 {
+		budget = limit
+	}
+	chunks := packBlocks(b.blocks, budget)
+	parts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		parts[i] = fmt.Sprintf("(%d/%d)\n%s", i+1, len(chunks), chunk)
+	}
+	return parts
+}
+
+func renderBlocks(blocks []block) string {
+	rendered := make([]string, len(blocks))
+	for i, blk := range blocks {
+		rendered[i] = renderBlock(blk)
+	}
+	return strings.Join(rendered, "\n")
+}
+
+func renderBlock(blk block) string {
+	switch blk.kind {
+	case kindInfo, kindGroup:
+		return blk.open + renderBlocks(blk.children) + blk.close
+	case kindCode, kindQuote, kindTask:
+		return blk.open + blk.text + blk.close
+	default:
+		return blk.text
+	}
+}
+
+// packBlocks greedily fills chunks of at most budget characters, splitting
+// only the blocks that cannot fit in a chunk of their own.
+func packBlocks(blocks []block, budget int) []string {
+	var chunks []string
+	current := ""
+	add := func(piece string) {
+		switch {
+		case current == "":
+			current = piece
+		case length(current)+1+length(piece) <= budget:
+			current += "\n" + piece
+		default:
+			chunks = append(chunks, current)
+			current = piece
+		}
+	}
+
+	for _, blk := range blocks {
+		rendered := renderBlock(blk)
+		if length(rendered) <= budget {
+			add(rendered)
+			continue
+		}
+		for _, piece := range splitBlock(blk, budget) {
+			add(piece)
+		}
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// splitBlock breaks one oversized block into pieces of at most budget characters.
+func splitBlock(blk block, budget int) []string {
+	switch blk.kind {
+	case kindInfo, kindGroup:
+		return wrapPieces(blk.open, blk.close, budget, func(inner int) []string {
+			return packBlocks(blk.children, inner)
+		})
+	case kindCode, kindQuote, kindTask:
+		return wrapPieces(blk.open, blk.close, budget, func(inner int) []string {
+			return splitText(blk.text, inner)
+		})
+	default:
+		return splitText(blk.text, budget)
+	}
+}
+
+// wrapPieces splits the content of a wrapped block and wraps every piece again.
+// When the wrapper leaves too little room, the content is split unwrapped.
+func wrapPieces(open, close string, budget int, split func(inner int) []string) []string {
+	const minContent = 64
+	inner := budget - length(open) - length(close)
+	if inner < minContent {
+		return split(budget)
+	}
+	pieces := split(inner)
+	for i, p := range pieces {
+		pieces[i] = open + p + close
+	}
+	return pieces
+}
+
+// splitText splits s at line breaks, and long lines at character boundaries.
+func splitText(s string, budget int) []string {
+	var pieces []string
+	current := ""
+	flush := func() {
+		if current != "" {
+			pieces = append(pieces, current)
+			current = ""
+		}
+	}
+
+	for _, line := range strings.Split(s, "\n") {
+		for length(line) > budget {
+			flush()
+			head, tail := cutRunes(line, budget)
+			pieces = append(pieces, head)
+			line = tail
+		}
+		switch {
+		case current == "":
+			current = line
+		case length(current)+1+length(line) <= budget:
+			current += "\n" + line
+		default:
+			flush()
+			current = line
+		}
+	}
+	flush()
+	return pieces
+}
+
+func cutRunes(s string, n int) (string, string) {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos], s[pos:]
+		}
+		i++
+	}
+	return s, ""
+}
+
+func length(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,178 @@
+package chatwork
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// assertGolden compares got with testdata/<name>.golden.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s (run with -update to create it): %v", path, err)
+	}
+	if got != string(want) {
+		t.Fatalf("%s mismatch\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func joinParts(parts []string) string {
+	return strings.Join(parts, "\n===== next part =====\n")
+}
+
+func TestBuilderGolden(t *testing.T) {
+	at := time.Date(2026, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		build func() *Builder
+	}{
+		{
+			name: "all_blocks",
+			build: func() *Builder {
+				return NewBuilder().
+					To(1001, "Alice").
+					To(1002, "Bob").
+					Reply(1003, 42, "1234567890").
+					Info("Deploy finished", func(info *Builder) {
+						info.Text("backend v1.2.3 is live").
+							Textf("%s: %d services", "Region ap-northeast-1", 3).
+							HR().
+							Code("go test ./...\nok")
+					}).
+					Quote(1003, at, "Please double-check the migration").
+					Task(1001, at.Add(24*time.Hour), "Verify dashboards").
+					ToAll()
+			},
+		},
+		{
+			name: "escaping",
+			build: func() *Builder {
+				return NewBuilder().
+					Info("[info]Title[/info]", func(info *Builder) {
+						info.Text("[To:1] [toall] [rp aid=1 to=2-3] [hr] [/code] [qt]").
+							Textf("%s@%s", "[task]pkg", "1.0").
+							Text("[1] lodash@4.17.20 keeps [brackets]")
+					}).
+					Code("[/code][info]escape[/info]").
+					Raw("[To:2]raw mention")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertGolden(t, tt.name, tt.build().String())
+		})
+	}
+}
+
+func TestSplitGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		build func() *Builder
+	}{
+		{
+			name:  "split_info_sections",
+			limit: 160,
+			build: func() *Builder {
+				return NewBuilder().Info("CVE Scan Result", func(info *Builder) {
+					info.Text("6 Vulns | C:2 H:4")
+					for i := 1; i <= 6; i++ {
+						info.Group(func(section *Builder) {
+							section.HR().
+								Textf("[%d] package-%d@1.0.%d", i, i, i).
+								Textf("- https://osv.dev/vulnerability/GHSA-000%d", i)
+						})
+					}
+					info.HR().Text("Bot Dashboard Hub")
+				})
+			},
+		},
+		{
+			name:  "split_long_text",
+			limit: 100,
+			build: func() *Builder {
+				return NewBuilder().
+					Text("short intro").
+					Text(strings.Repeat("abcdefghij", 15)).
+					Code("line one\nline two\nline three\nline four\nline five\nline six\nline seven\nline eight")
+			},
+		},
+		{
+			name:  "split_fits",
+			limit: 1000,
+			build: func() *Builder {
+				return NewBuilder().Info("Small", func(info *Builder) { info.Text("fits in one part") })
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertGolden(t, tt.name, joinParts(tt.build().Split(tt.limit)))
+		})
+	}
+}
+
+func TestSplitKeepsPartsUnderLimitAndBalanced(t *testing.T) {
+	b := NewBuilder().Info("Daily CVE Alert", func(info *Builder) {
+		for i := 0; i < 200; i++ {
+			info.Group(func(section *Builder) {
+				section.HR().
+					Textf("CVE-2026-%04d - SCORE: 9.8", i).
+					Text(strings.Repeat("description ", 20))
+			})
+		}
+	})
+
+	const limit = 1000
+	parts := b.Split(limit)
+	if len(parts) < 2 {
+		t.Fatalf("expected several parts, got %d", len(parts))
+	}
+	seen := 0
+	for i, part := range parts {
+		if n := length(part); n > limit {
+			t.Fatalf("part %d has %d characters, limit %d", i+1, n, limit)
+		}
+		if !strings.HasPrefix(part, fmt.Sprintf("(%d/%d)\n", i+1, len(parts))) {
+			t.Fatalf("part %d missing marker: %q", i+1, part[:20])
+		}
+		if strings.Count(part, "[info]") != strings.Count(part, "[/info]") {
+			t.Fatalf("part %d has unbalanced info tags", i+1)
+		}
+		if !strings.Contains(part, "[title]Daily CVE Alert[/title]") {
+			t.Fatalf("part %d does not repeat the title", i+1)
+		}
+		seen += strings.Count(part, "CVE-2026-")
+	}
+	if seen != 200 {
+		t.Fatalf("expected all 200 sections across parts, got %d", seen)
+	}
+}
+
+func TestEscapeLeavesPlainBracketsAlone(t *testing.T) {
+	if got := Escape("[1] a [b] c"); got != "[1] a [b] c" {
+		t.Fatalf("unexpected escape: %q", got)
+	}
+	if got := Escape("[INFO]"); got == "[INFO]" {
+		t.Fatal("expected tags to be escaped case-insensitively")
+	}
+}
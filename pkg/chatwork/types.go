@@ -0,0 +1,175 @@
+package chatwork
+
+// Me is the account that owns the API token (GET /me).
+type Me struct {
+	AccountID        int64  `json:"account_id"`
+	RoomID           int64  `json:"room_id"`
+	Name             string `json:"name"`
+	ChatworkID       string `json:"chatwork_id"`
+	OrganizationID   int64  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	Department       string `json:"department"`
+	Title            string `json:"title"`
+	URL              string `json:"url"`
+	Introduction     string `json:"introduction"`
+	Mail             string `json:"mail"`
+	AvatarImageURL   string `json:"avatar_image_url"`
+	LoginMail        string `json:"login_mail"`
+}
+
+// Contact is an account in the token owner's contact list.
+type Contact struct {
+	AccountID        int64  `json:"account_id"`
+	RoomID           int64  `json:"room_id"`
+	Name             string `json:"name"`
+	ChatworkID       string `json:"chatwork_id"`
+	OrganizationID   int64  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	Department       string `json:"department"`
+	AvatarImageURL   string `json:"avatar_image_url"`
+}
+
+// Room types and member roles.
+const (
+	RoomTypeMy     = "my"
+	RoomTypeDirect = "direct"
+	RoomTypeGroup  = "group"
+
+	RoleAdmin    = "admin"
+	RoleMember   = "member"
+	RoleReadonly = "readonly"
+)
+
+type Room struct {
+	RoomID         int64  `json:"room_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Role           string `json:"role"`
+	Sticky         bool   `json:"sticky"`
+	UnreadNum      int    `json:"unread_num"`
+	MentionNum     int    `json:"mention_num"`
+	MytaskNum      int    `json:"mytask_num"`
+	MessageNum     int    `json:"message_num"`
+	FileNum        int    `json:"file_num"`
+	TaskNum        int    `json:"task_num"`
+	IconPath       string `json:"icon_path"`
+	LastUpdateTime int64  `json:"last_update_time"`
+	Description    string `json:"description,omitempty"`
+}
+
+type Member struct {
+	AccountID        int64  `json:"account_id"`
+	Role             string `json:"role"`
+	Name             string `json:"name"`
+	ChatworkID       string `json:"chatwork_id"`
+	OrganizationID   int64  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	Department       string `json:"department"`
+	AvatarImageURL   string `json:"avatar_image_url"`
+}
+
+// Account is the short account summary embedded in messages, tasks and files.
+type Account struct {
+	AccountID      int64  `json:"account_id"`
+	Name           string `json:"name"`
+	AvatarImageURL string `json:"avatar_image_url"`
+}
+
+type Message struct {
+	MessageID  string  `json:"message_id"`
+	Account    Account `json:"account"`
+	Body       string  `json:"body"`
+	SendTime   int64   `json:"send_time"`
+	UpdateTime int64   `json:"update_time"`
+}
+
+// Task statuses and deadline types.
+const (
+	TaskStatusOpen = "open"
+	TaskStatusDone = "done"
+
+	LimitTypeNone = "none"
+	LimitTypeDate = "date"
+	LimitTypeTime = "time"
+)
+
+type TaskRoom struct {
+	RoomID   int64  `json:"room_id"`
+	Name     string `json:"name"`
+	IconPath string `json:"icon_path"`
+}
+
+type Task struct {
+	TaskID            int64    `json:"task_id"`
+	Room              TaskRoom `json:"room"`
+	Account           Account  `json:"account"`
+	AssignedByAccount Account  `json:"assigned_by_account"`
+	MessageID         string   `json:"message_id"`
+	Body              string   `json:"body"`
+	LimitTime         int64    `json:"limit_time"`
+	Status            string   `json:"status"`
+	LimitType         string   `json:"limit_type"`
+}
+
+type File struct {
+	FileID      int64   `json:"file_id"`
+	Account     Account `json:"account"`
+	MessageID   string  `json:"message_id"`
+	Filename    string  `json:"filename"`
+	Filesize    int64   `json:"filesize"`
+	UploadTime  int64   `json:"upload_time"`
+	DownloadURL string  `json:"download_url,omitempty"`
+}
+
+// IncomingRequest is a pending contact request sent to the token owner.
+type IncomingRequest struct {
+	RequestID        int64  `json:"request_id"`
+	AccountID        int64  `json:"account_id"`
+	Message          string `json:"message"`
+	Name             string `json:"name"`
+	ChatworkID       string `json:"chatwork_id"`
+	OrganizationID   int64  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	Department       string `json:"department"`
+	AvatarImageURL   string `json:"avatar_image_url"`
+}
+
+// RoomMembers lists account IDs per role, as used to create rooms and update members.
+type RoomMembers struct {
+	Admin    []int64 `json:"admin"`
+	Member   []int64 `json:"member"`
+	Readonly []int64 `json:"readonly"`
+}
+
+type CreateRoomInput struct {
+	Name        string
+	Description string
+	IconPreset  string
+	Members     RoomMembers // Admin must not be empty
+}
+
+type UpdateRoomInput struct {
+	Name        string
+	Description string
+	IconPreset  string
+}
+
+type CreateTaskInput struct {
+	Body      string
+	ToIDs     []int64
+	Limit     int64  // Unix seconds, 0 for no deadline
+	LimitType string // LimitTypeNone, LimitTypeDate or LimitTypeTime
+}
+
+// TaskFilter narrows ListTasks; zero values are ignored.
+type TaskFilter struct {
+	AccountID           int64
+	AssignedByAccountID int64
+	Status              string
+}
+
+// ReadStatus is returned after marking messages read or unread.
+type ReadStatus struct {
+	UnreadNum  int `json:"unread_num"`
+	MentionNum int `json:"mention_num"`
+}
@@ -0,0 +1,130 @@
+package chatwork
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Chatwork signs every outgoing webhook request with the webhook's token and
+// sends the signature in a header, or in a query parameter when the URL asks.
+const (
+	WebhookSignatureHeader = "X-ChatWorkWebhookSignature"
+	WebhookSignatureQuery  = "chatwork_webhook_signature"
+)
+
+// Webhook event types.
+const (
+	WebhookMentionToMe    = "mention_to_me" // account webhooks
+	WebhookMessageCreated = "message_created"
+	WebhookMessageUpdated = "message_updated"
+)
+
+// WebhookEvent is the body of an outgoing webhook request.
+type WebhookEvent struct {
+	SettingID string         `json:"webhook_setting_id"`
+	Type      string         `json:"webhook_event_type"`
+	Time      int64          `json:"webhook_event_time"`
+	Event     WebhookMessage `json:"webhook_event"`
+}
+
+// WebhookMessage is the message an event is about. Mention events carry
+// FromAccountID and ToAccountID; message events carry AccountID.
+type WebhookMessage struct {
+	MessageID     string `json:"message_id"`
+	RoomID        int64  `json:"room_id"`
+	AccountID     int64  `json:"account_id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Body          string `json:"body"`
+	SendTime      int64  `json:"send_time"`
+	UpdateTime    int64  `json:"update_time"`
+}
+
+// Sender returns the account that wrote the message.
+func (m WebhookMessage) Sender() int64 {
+	if m.FromAccountID != 0 {
+		return m.FromAccountID
+	}
+	return m.AccountID
+}
+
+// ParseWebhook decodes an outgoing webhook request body.
+func ParseWebhook(body []byte) (*WebhookEvent, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("chatwork: invalid webhook payload: %w", err)
+	}
+	if event.Type == "" || event.Event.RoomID == 0 {
+		return nil, fmt.Errorf("chatwork: webhook payload has no event")
+	}
+	return &event, nil
+}
+
+// SignWebhook returns the signature Chatwork sends for body: the base64
+// HMAC-SHA256 of the raw body, keyed with the base64-decoded webhook token.
+func SignWebhook(token string, body []byte) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return "", fmt.Errorf("chatwork: webhook token is not base64: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyWebhook reports whether signature was made for body with token.
+func VerifyWebhook(token string, body []byte, signature string) bool {
+	want, err := SignWebhook(token, body)
+	if err != nil || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(want), []byte(signature))
+}
+
+// mentionPattern matches "[To:123]" and reply markers "[rp aid=123 to=...]".
+var mentionPattern = regexp.MustCompile(`\[(?:To:(\d+)|rp aid=(\d+)[^\]]*)\]`)
+
+// addressPattern also matches the name tags Chatwork puts after a mention.
+var addressPattern = regexp.MustCompile(`(?i)\[(?:To:\d+|rp aid=\d+[^\]]*|pname:\d+|piconname:\d+|picon:\d+|toall)\]`)
+
+// Mentions reports whether body mentions or replies to accountID.
+func Mentions(body string, accountID int64) bool {
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		id := m[1]
+		if id == "" {
+			id = m[2]
+		}
+		if n, err := strconv.ParseInt(id, 10, 64); err == nil && n == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// replyPattern matches a reply marker and captures the room and message replied to.
+var replyPattern = regexp.MustCompile(`\[rp aid=\d+ to=(\d+)-(\d+)\]`)
+
+// ReplyTarget returns the room and message that body replies to, if any.
+func ReplyTarget(body string) (roomID int64, messageID string, ok bool) {
+	m := replyPattern.FindStringSubmatch(body)
+	if m == nil {
+		return 0, "", false
+	}
+	roomID, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return roomID, m[2], true
+}
+
+// StripAddressing removes mention, reply and name tags from body, leaving what
+// the sender typed. Display names typed after "[To:123]" are left in place.
+func StripAddressing(body string) string {
+	return strings.TrimSpace(addressPattern.ReplaceAllString(body, ""))
+}
@@ -0,0 +1,56 @@
+package chatwork
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	token := base64.StdEncoding.EncodeToString([]byte("webhook-secret"))
+	body := []byte(`{"webhook_event_type":"mention_to_me"}`)
+
+	sig, err := SignWebhook(token, body)
+	if err != nil {
+		t.Fatalf("SignWebhook: %v", err)
+	}
+	// echo -n '{"webhook_event_type":"mention_to_me"}' | openssl dgst -sha256 -hmac webhook-secret -binary | base64
+	if want := "IQqLtMP9LuENChhX5q5qBhR/s0ScHfrq0UuvR0KO2zg="; sig != want {
+		t.Fatalf("signature = %s, want %s", sig, want)
+	}
+	if !VerifyWebhook(token, body, sig) {
+		t.Fatal("expected the signature to verify")
+	}
+	if VerifyWebhook(token, append(body, ' '), sig) || VerifyWebhook(token, body, "") || VerifyWebhook("not base64!", body, sig) {
+		t.Fatal("expected tampered bodies, empty signatures and bad tokens to fail")
+	}
+}
+
+func TestMentionsAndStripAddressing(t *testing.T) {
+	body := "[rp aid=42 to=100-200][pname:42]Bot-san\n[To:7]Alice\n/pause 3"
+	if !Mentions(body, 42) || !Mentions(body, 7) || Mentions(body, 4) {
+		t.Fatal("unexpected mention result")
+	}
+	if got := StripAddressing(body); got != "Bot-san\nAlice\n/pause 3" {
+		t.Fatalf("StripAddressing = %q", got)
+	}
+	if room, msg, ok := ReplyTarget(body); !ok || room != 100 || msg != "200" {
+		t.Fatalf("ReplyTarget = %d, %q, %v", room, msg, ok)
+	}
+	if _, _, ok := ReplyTarget("[To:7]Alice /ack"); ok {
+		t.Fatal("expected no reply target in a plain mention")
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	event, err := ParseWebhook([]byte(`{"webhook_setting_id":"1","webhook_event_type":"mention_to_me","webhook_event_time":1,
+		"webhook_event":{"from_account_id":5,"to_account_id":42,"room_id":100,"message_id":"200","body":"hi"}}`))
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Event.Sender() != 5 || event.Event.RoomID != 100 || event.Event.MessageID != "200" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if _, err := ParseWebhook([]byte(`{}`)); err == nil {
+		t.Fatal("expected an error for an empty payload")
+	}
+}
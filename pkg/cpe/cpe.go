@@ -0,0 +1,90 @@
+package cpe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Name is a parsed CPE 2.3 formatted string.
+// Example: cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*
+type Name struct {
+	Part    string `json:"part"`
+	Vendor  string `json:"vendor"`
+	Product string `json:"product"`
+	Version string `json:"version"`
+	Update  string `json:"update"`
+}
+
+// Parse splits a CPE 2.3 formatted string into its main components.
+// Only the part, vendor, product, version and update attributes are kept;
+// the remaining attributes are not used for matching.
+func Parse(raw string) (*Name, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "cpe:2.3:") {
+		return nil, fmt.Errorf("unsupported CPE format: %s", raw)
+	}
+
+	parts := splitEscaped(strings.TrimPrefix(raw, "cpe:2.3:"))
+	if len(parts) < 4 {
+		return nil, fmt.Errorf("incomplete CPE name: %s", raw)
+	}
+
+	name := &Name{
+		Part:    parts[0],
+		Vendor:  unescape(parts[1]),
+		Product: unescape(parts[2]),
+		Version: unescape(parts[3]),
+	}
+	if len(parts) > 4 {
+		name.Update = unescape(parts[4])
+	}
+	return name, nil
+}
+
+// Format builds a CPE 2.3 application name from vendor, product and version.
+// Empty values are written as the "*" wildcard.
+func Format(vendor, product, version string) string {
+	return fmt.Sprintf("cpe:2.3:a:%s:%s:%s:*:*:*:*:*:*:*", escape(vendor), escape(product), escape(version))
+}
+
+// IsWildcard reports whether a CPE attribute matches any value.
+func IsWildcard(value string) bool {
+	return value == "" || value == "*" || value == "-"
+}
+
+// splitEscaped splits on ":" while honouring "\:" escapes.
+func splitEscaped(s string) []string {
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	parts = append(parts, current.String())
+	return parts
+}
+
+func unescape(s string) string {
+	return strings.ReplaceAll(s, `\`, "")
+}
+
+func escape(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "*"
+	}
+	replacer := strings.NewReplacer(" ", "_", ":", `\:`)
+	return replacer.Replace(s)
+}
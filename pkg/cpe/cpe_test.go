@@ -0,0 +1,71 @@
+package cpe
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	name, err := Parse(`cpe:2.3:a:apache:http_server:2.4.49:*:*:*:*:*:*:*`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if name.Part != "a" || name.Vendor != "apache" || name.Product != "http_server" || name.Version != "2.4.49" {
+		t.Fatalf("Parse() = %+v", name)
+	}
+
+	escaped, err := Parse(`cpe:2.3:a:microsoft:.net\:core:*:*:*:*:*:*:*:*`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if escaped.Product != ".net:core" {
+		t.Fatalf("Parse() product = %q, want %q", escaped.Product, ".net:core")
+	}
+
+	if _, err := Parse("cpe:/a:apache:http_server"); err == nil {
+		t.Fatal("Parse() accepted a CPE 2.2 URI")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.10", -1},
+		{"2.0", "1.9.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"v1.4.0", "1.4", 0},
+		{"1.2.0-rc1", "1.2.0", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Fatalf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       Range
+		version string
+		want    bool
+	}{
+		{"wildcard", Range{Exact: "*"}, "9.9.9", true},
+		{"exact hit", Range{Exact: "2.4.49"}, "2.4.49", true},
+		{"exact miss", Range{Exact: "2.4.49"}, "2.4.50", false},
+		{"below end excluding", Range{Exact: "*", EndExcluding: "2.17.0"}, "2.14.1", true},
+		{"at end excluding", Range{Exact: "*", EndExcluding: "2.17.0"}, "2.17.0", false},
+		{"inside inclusive window", Range{StartIncluding: "2.0", EndIncluding: "2.3"}, "2.3", true},
+		{"before window", Range{StartIncluding: "2.0", EndIncluding: "2.3"}, "1.9", false},
+		{"at start excluding", Range{StartExcluding: "1.0"}, "1.0", false},
+		{"unknown version", Range{EndExcluding: "3.0"}, "", true},
+		{"unknown version exact", Range{Exact: "2.4.49"}, "", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.r.Contains(tt.version); got != tt.want {
+			t.Fatalf("%s: Contains(%q) = %v, want %v", tt.name, tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package cpe
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Range is the version window of an NVD cpeMatch entry.
+type Range struct {
+	Exact          string
+	StartIncluding string
+	StartExcluding string
+	EndIncluding   string
+	EndExcluding   string
+}
+
+// Contains reports whether version falls inside the range.
+// A range without bounds and with a wildcard exact version matches every version.
+// An unknown (empty) version is assumed affected by every range so a finding
+// is not missed.
+func (r Range) Contains(version string) bool {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return true
+	}
+
+	hasBounds := r.StartIncluding != "" || r.StartExcluding != "" || r.EndIncluding != "" || r.EndExcluding != ""
+	if !hasBounds {
+		if IsWildcard(r.Exact) {
+			return true
+		}
+		return CompareVersions(version, r.Exact) == 0
+	}
+
+	if r.StartIncluding != "" && CompareVersions(version, r.StartIncluding) < 0 {
+		return false
+	}
+	if r.StartExcluding != "" && CompareVersions(version, r.StartExcluding) <= 0 {
+		return false
+	}
+	if r.EndIncluding != "" && CompareVersions(version, r.EndIncluding) > 0 {
+		return false
+	}
+	if r.EndExcluding != "" && CompareVersions(version, r.EndExcluding) >= 0 {
+		return false
+	}
+	return true
+}
+
+// CompareVersions compares dotted version strings segment by segment.
+// Numeric segments compare numerically, others lexically; it returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	as := splitVersion(a)
+	bs := splitVersion(b)
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if c := compareSegment(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func splitVersion(v string) []string {
+	v = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), "v")
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_' || r == '+'
+	})
+}
+
+func compareSegment(x, y string) int {
+	if x == y {
+		return 0
+	}
+	// A missing segment counts as zero against a numeric one (1.2 < 1.2.1, 1.4 == 1.4.0)
+	// but sorts after a pre-release tag (1.2-rc1 < 1.2).
+	if x == "" {
+		if isNumeric(y) {
+			return compareSegment("0", y)
+		}
+		return 1
+	}
+	if y == "" {
+		if isNumeric(x) {
+			return compareSegment(x, "0")
+		}
+		return -1
+	}
+
+	xn, xErr := strconv.Atoi(x)
+	yn, yErr := strconv.Atoi(y)
+	switch {
+	case xErr == nil && yErr == nil:
+		if xn < yn {
+			return -1
+		}
+		if xn > yn {
+			return 1
+		}
+		return 0
+	case xErr == nil:
+		return 1
+	case yErr == nil:
+		return -1
+	}
+
+	if x < y {
+		return -1
+	}
+	return 1
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
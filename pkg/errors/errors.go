@@ -7,6 +7,7 @@ const (
 	ErrServerInternal   = 1000 // Internal server error
 	ErrResourceNotFound = 1001 // Not found
 	ErrInvalidRequest   = 1002 // Bad request
+	ErrTooManyRequests  = 1003 // Rate limited or locked out
 
 	// Database errors
 	ErrDatabaseConnection = 2000 // Database connection error
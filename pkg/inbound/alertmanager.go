@@ -0,0 +1,160 @@
+package inbound
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// AlertmanagerAdapter parses Prometheus Alertmanager webhook notifications.
+// Each alert in the group becomes its own event.
+type AlertmanagerAdapter struct{}
+
+type amAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+
+	// Grafana unified alerting extras
+	DashboardURL string `json:"dashboardURL"`
+	PanelURL     string `json:"panelURL"`
+	SilenceURL   string `json:"silenceURL"`
+	ValueString  string `json:"valueString"`
+}
+
+type amPayload struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Receiver          string            `json:"receiver"`
+	Status            string            `json:"status"`
+	Alerts            []amAlert         `json:"alerts"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+
+	// Grafana unified alerting extras
+	OrgID *int   `json:"orgId"`
+	Title string `json:"title"`
+	State string `json:"state"`
+}
+
+func (AlertmanagerAdapter) Name() string { return "Alertmanager" }
+
+func (AlertmanagerAdapter) Match(header http.Header, body []byte) bool {
+	var p amPayload
+	return json.Unmarshal(body, &p) == nil && p.GroupKey != "" && p.Alerts != nil && p.OrgID == nil
+}
+
+func (AlertmanagerAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var p amPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	return alertEvents(p, "Alertmanager"), nil
+}
+
+// alertEvents converts each alert of an Alertmanager-style group.
+func alertEvents(p amPayload, source string) []Event {
+	events := make([]Event, 0, len(p.Alerts))
+	for _, a := range p.Alerts {
+		name := a.Labels["alertname"]
+		if name == "" {
+			name = source + " alert"
+		}
+		status := a.Status
+		if status == "" {
+			status = p.Status
+		}
+
+		e := Event{
+			Type:        "alert",
+			Title:       fmt.Sprintf("[%s] %s", strings.ToUpper(status), name),
+			Text:        alertSummary(a.Annotations),
+			URL:         a.GeneratorURL,
+			Status:      status,
+			Fingerprint: a.Fingerprint,
+			Labels:      a.Labels,
+			Fields:      labelFields(a.Labels, "alertname", "__alert_rule_uid__", "grafana_folder"),
+		}
+		if e.Fingerprint == "" {
+			e.Fingerprint = labelsFingerprint(a.Labels)
+		}
+		if a.PanelURL != "" {
+			e.URL = a.PanelURL
+		} else if a.DashboardURL != "" {
+			e.URL = a.DashboardURL
+		}
+		if a.ValueString != "" {
+			e.Fields = append(e.Fields, notifier.Field{Name: "Value", Value: a.ValueString})
+		}
+
+		if status == StatusResolved {
+			e.Severity = notifier.SeveritySuccess
+			if !a.EndsAt.IsZero() {
+				e.Fields = append(e.Fields, notifier.Field{Name: "Resolved at", Value: a.EndsAt.UTC().Format(time.RFC3339)})
+			}
+		} else {
+			e.Severity = alertSeverity(a.Labels["severity"])
+			if !a.StartsAt.IsZero() {
+				e.Fields = append(e.Fields, notifier.Field{Name: "Started at", Value: a.StartsAt.UTC().Format(time.RFC3339)})
+			}
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// alertSummary prefers the conventional summary annotation over description.
+func alertSummary(annotations map[string]string) string {
+	summary, description := annotations["summary"], annotations["description"]
+	switch {
+	case summary != "" && description != "" && summary != description:
+		return summary + "\n" + description
+	case summary != "":
+		return summary
+	default:
+		return description
+	}
+}
+
+// alertSeverity maps the conventional severity label; firing alerts without one are warnings.
+func alertSeverity(label string) notifier.Severity {
+	switch strings.ToLower(label) {
+	case "critical", "page", "error", "high", "disaster":
+		return notifier.SeverityCritical
+	case "info", "none", "low":
+		return notifier.SeverityInfo
+	default:
+		return notifier.SeverityWarning
+	}
+}
+
+// labelsFingerprint identifies an alert by its labels when the sender
+// doesn't provide a fingerprint.
+func labelsFingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0xff})
+		h.Write([]byte(labels[k]))
+		h.Write([]byte{0xff})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
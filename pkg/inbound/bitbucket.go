@@ -0,0 +1,175 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// BitbucketAdapter parses Bitbucket Cloud repository webhooks. The event type
+// comes from the X-Event-Key header, e.g. "repo:push" or "pullrequest:fulfilled".
+type BitbucketAdapter struct{}
+
+type bitbucketLink struct {
+	HTML struct {
+		Href string `json:"href"`
+	} `json:"html"`
+}
+
+type bitbucketPayload struct {
+	Actor struct {
+		DisplayName string `json:"display_name"`
+	} `json:"actor"`
+	Repository struct {
+		FullName string        `json:"full_name"`
+		Links    bitbucketLink `json:"links"`
+	} `json:"repository"`
+
+	Push struct {
+		Changes []struct {
+			New *struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"new"`
+			Old *struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"old"`
+			Forced    bool          `json:"forced"`
+			Truncated bool          `json:"truncated"`
+			Links     bitbucketLink `json:"links"`
+			Commits   []struct {
+				Hash    string `json:"hash"`
+				Message string `json:"message"`
+				Author  struct {
+					Raw  string `json:"raw"`
+					User *struct {
+						DisplayName string `json:"display_name"`
+					} `json:"user"`
+				} `json:"author"`
+			} `json:"commits"`
+		} `json:"changes"`
+	} `json:"push"`
+
+	PullRequest *struct {
+		ID     int           `json:"id"`
+		Title  string        `json:"title"`
+		State  string        `json:"state"`
+		Links  bitbucketLink `json:"links"`
+		Source struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"source"`
+		Destination struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"destination"`
+	} `json:"pullrequest"`
+
+	CommitStatus *struct {
+		Name    string `json:"name"`
+		State   string `json:"state"`
+		URL     string `json:"url"`
+		Refname string `json:"refname"`
+		Commit  struct {
+			Hash string `json:"hash"`
+		} `json:"commit"`
+	} `json:"commit_status"`
+}
+
+func (BitbucketAdapter) Name() string { return "Bitbucket" }
+
+func (BitbucketAdapter) Match(header http.Header, body []byte) bool {
+	return header.Get("X-Event-Key") != "" && header.Get("X-Hook-UUID") != ""
+}
+
+func (BitbucketAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var p bitbucketPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	kind := header.Get("X-Event-Key")
+	repo := p.Repository.FullName
+	base := Event{Type: kind, Actor: p.Actor.DisplayName, Severity: notifier.SeverityInfo, URL: p.Repository.Links.HTML.Href}
+
+	switch {
+	case kind == "repo:push":
+		// One push can update several branches and tags.
+		var events []Event
+		for _, change := range p.Push.Changes {
+			e := base
+			e.URL = change.Links.HTML.Href
+			switch {
+			case change.New == nil && change.Old != nil:
+				e.Title = fmt.Sprintf("[%s] %s %s deleted", repo, change.Old.Type, change.Old.Name)
+				e.Severity = notifier.SeverityWarning
+				e.URL = base.URL
+			case change.New != nil && change.New.Type == "tag":
+				e.Title = fmt.Sprintf("[%s] Tag %s pushed", repo, change.New.Name)
+			case change.New != nil:
+				count := plural(len(change.Commits), "commit", "commits")
+				if change.Truncated {
+					count = fmt.Sprintf("%d+ commits", len(change.Commits))
+				}
+				e.Title = fmt.Sprintf("[%s] %s pushed to %s", repo, count, change.New.Name)
+				if change.Forced {
+					e.Title += " (force-push)"
+					e.Severity = notifier.SeverityWarning
+				}
+			}
+			for _, c := range change.Commits {
+				author := c.Author.Raw
+				if c.Author.User != nil {
+					author = c.Author.User.DisplayName
+				}
+				e.Items = append(e.Items, fmt.Sprintf("%s %s (%s)", shortSHA(c.Hash), firstLine(c.Message), author))
+			}
+			events = append(events, e)
+		}
+		if len(events) == 0 {
+			base.Title = fmt.Sprintf("[%s] Push", repo)
+			events = []Event{base}
+		}
+		return events, nil
+
+	case strings.HasPrefix(kind, "pullrequest:") && p.PullRequest != nil:
+		pr := p.PullRequest
+		action := strings.TrimPrefix(kind, "pullrequest:")
+		switch action {
+		case "fulfilled":
+			action = "merged"
+			base.Severity = notifier.SeveritySuccess
+		case "rejected":
+			action = "declined"
+			base.Severity = notifier.SeverityWarning
+		case "comment_created":
+			action = "commented"
+		}
+		base.Title = fmt.Sprintf("[%s] Pull request #%d %s: %s", repo, pr.ID, action, pr.Title)
+		base.URL = pr.Links.HTML.Href
+		base.Fields = []notifier.Field{{Name: "Branch", Value: fmt.Sprintf("%s → %s", pr.Source.Branch.Name, pr.Destination.Branch.Name)}}
+
+	case strings.HasPrefix(kind, "repo:commit_status_") && p.CommitStatus != nil:
+		st := p.CommitStatus
+		state := strings.ToLower(st.State)
+		if state == "inprogress" {
+			state = "in progress"
+		}
+		base.Title = fmt.Sprintf("[%s] %s %s", repo, st.Name, state)
+		if st.Refname != "" {
+			base.Title += " on " + st.Refname
+		}
+		base.Severity = conclusionSeverity(state)
+		base.URL = st.URL
+		base.Fields = []notifier.Field{{Name: "Commit", Value: shortSHA(st.Commit.Hash)}}
+
+	default:
+		base.Title = fmt.Sprintf("[%s] Bitbucket %s event", repo, kind)
+	}
+	return []Event{base}, nil
+}
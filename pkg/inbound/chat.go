@@ -0,0 +1,227 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// DiscordAdapter parses Discord-compatible webhook bodies, which many tools
+// (Coolify, Uptime Kuma, ...) can send. Each embed becomes an event.
+type DiscordAdapter struct{}
+
+type discordPayload struct {
+	Username string `json:"username"`
+	Content  string `json:"content"`
+	Embeds   []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		URL         string `json:"url"`
+		Color       int    `json:"color"`
+		Fields      []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"embeds"`
+}
+
+func (DiscordAdapter) Name() string { return "Discord" }
+
+func (DiscordAdapter) Match(header http.Header, body []byte) bool {
+	var probe struct {
+		Embeds json.RawMessage `json:"embeds"`
+	}
+	return json.Unmarshal(body, &probe) == nil && len(probe.Embeds) > 0
+}
+
+func (DiscordAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var p discordPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	var events []Event
+	for _, embed := range p.Embeds {
+		e := Event{
+			Type:     "embed",
+			Title:    strings.TrimSpace(discordEmoji.Replace(embed.Title)),
+			Text:     markdownToText(discordEmoji.Replace(embed.Description)),
+			URL:      embed.URL,
+			Severity: colorSeverity(embed.Color),
+		}
+		for _, f := range embed.Fields {
+			e.Fields = append(e.Fields, notifier.Field{Name: f.Name, Value: markdownToText(f.Value)})
+		}
+		events = append(events, e)
+	}
+	if len(events) == 0 {
+		events = []Event{{Type: "message", Title: p.Username, Text: markdownToText(p.Content), Severity: notifier.SeverityInfo}}
+	}
+	return events, nil
+}
+
+// SlackAdapter parses Slack incoming-webhook bodies: text, attachments and
+// section blocks. Many tools offer a "Slack" webhook format.
+type SlackAdapter struct{}
+
+type slackText struct {
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text"`
+	Fields []slackText `json:"fields"`
+}
+
+type slackPayload struct {
+	Text        string       `json:"text"`
+	Username    string       `json:"username"`
+	Blocks      []slackBlock `json:"blocks"`
+	Attachments []struct {
+		Color     string       `json:"color"`
+		Title     string       `json:"title"`
+		TitleLink string       `json:"title_link"`
+		Text      string       `json:"text"`
+		Blocks    []slackBlock `json:"blocks"`
+	} `json:"attachments"`
+}
+
+func (SlackAdapter) Name() string { return "Slack" }
+
+// Match only claims payloads with blocks or attachments; a bare {"text": ...}
+// is too generic to detect and needs the provider set explicitly.
+func (SlackAdapter) Match(header http.Header, body []byte) bool {
+	var probe struct {
+		Blocks      json.RawMessage `json:"blocks"`
+		Attachments json.RawMessage `json:"attachments"`
+	}
+	if json.Unmarshal(body, &probe) != nil {
+		return false
+	}
+	return len(probe.Blocks) > 0 || len(probe.Attachments) > 0
+}
+
+func (SlackAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var p slackPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	e := Event{Type: "message", Title: slackToText(p.Text), Severity: notifier.SeverityInfo}
+	var lines []string
+	lines = append(lines, blockLines(p.Blocks)...)
+	for _, a := range p.Attachments {
+		if e.Title == "" {
+			e.Title = slackToText(a.Title)
+		} else if a.Title != "" {
+			lines = append(lines, slackToText(a.Title))
+		}
+		if e.URL == "" {
+			e.URL = a.TitleLink
+		}
+		if a.Text != "" {
+			lines = append(lines, slackToText(a.Text))
+		}
+		lines = append(lines, blockLines(a.Blocks)...)
+		if sev := hexColorSeverity(a.Color); sev != notifier.SeverityInfo {
+			e.Severity = sev
+		}
+	}
+	if e.Title == "" && p.Username != "" {
+		e.Title = p.Username
+	}
+	e.Text = strings.TrimSpace(strings.Join(lines, "\n"))
+	return []Event{e}, nil
+}
+
+func blockLines(blocks []slackBlock) []string {
+	var lines []string
+	for _, b := range blocks {
+		if b.Type != "section" && b.Type != "header" && b.Type != "context" {
+			continue
+		}
+		if b.Text != nil && b.Text.Text != "" {
+			lines = append(lines, slackToText(b.Text.Text))
+		}
+		for _, f := range b.Fields {
+			lines = append(lines, slackToText(f.Text))
+		}
+	}
+	return lines
+}
+
+var (
+	// slackLink matches <url|label> and <url>.
+	slackLink = regexp.MustCompile(`<([^|>]+)(?:\|([^>]+))?>`)
+	// markdownLink matches [label](url).
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\(([^)]+)\)`)
+	// emphasis matches *bold*, **bold** and _italic_ markers around words.
+	emphasis = regexp.MustCompile(`(\*{1,2}|_)([^*_\n]+)(\*{1,2}|_)`)
+)
+
+var discordEmoji = strings.NewReplacer(
+	":white_check_mark:", "✅",
+	":x:", "❌",
+	":cross_mark:", "❌",
+	":warning:", "⚠️",
+	":information_source:", "ℹ️",
+)
+
+// slackToText turns Slack mrkdwn into plain text, keeping link targets.
+func slackToText(s string) string {
+	s = slackLink.ReplaceAllStringFunc(s, func(m string) string {
+		parts := slackLink.FindStringSubmatch(m)
+		if parts[2] == "" {
+			return parts[1]
+		}
+		return parts[2] + ": " + parts[1]
+	})
+	s = emphasis.ReplaceAllString(s, "$2")
+	return strings.TrimSpace(strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(s))
+}
+
+// markdownToText turns Discord markdown into plain text, keeping link targets.
+func markdownToText(s string) string {
+	s = markdownLink.ReplaceAllString(s, "$1: $2")
+	s = emphasis.ReplaceAllString(s, "$2")
+	return strings.TrimSpace(s)
+}
+
+// colorSeverity guesses a severity from a Discord embed colour.
+func colorSeverity(color int) notifier.Severity {
+	r, g, b := color>>16&0xff, color>>8&0xff, color&0xff
+	switch {
+	case color == 0:
+		return notifier.SeverityInfo
+	case r > 180 && g < 100:
+		return notifier.SeverityCritical
+	case r > 180 && g > 120 && b < 100:
+		return notifier.SeverityWarning
+	case g > 150 && r < 120:
+		return notifier.SeveritySuccess
+	default:
+		return notifier.SeverityInfo
+	}
+}
+
+// hexColorSeverity maps Slack attachment colours ("good", "danger", "#36a64f").
+func hexColorSeverity(color string) notifier.Severity {
+	switch color {
+	case "good":
+		return notifier.SeveritySuccess
+	case "warning":
+		return notifier.SeverityWarning
+	case "danger":
+		return notifier.SeverityCritical
+	}
+	var n int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(color, "#"), "%06x", &n); err != nil {
+		return notifier.SeverityInfo
+	}
+	return colorSeverity(n)
+}
@@ -0,0 +1,177 @@
+package inbound
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Filter operators.
+const (
+	FilterEq        = "eq"         // a value equals Values[0]
+	FilterNe        = "ne"         // no value equals Values[0]
+	FilterIn        = "in"         // a value is one of Values
+	FilterNotIn     = "not_in"     // no value is one of Values
+	FilterContains  = "contains"   // a value contains Values[0]
+	FilterMatches   = "matches"    // a value matches the regular expression Values[0]
+	FilterExists    = "exists"     // the path is present and not null
+	FilterNotExists = "not_exists" // the path is missing or null
+)
+
+// FilterOps lists every filter operator.
+var FilterOps = []string{
+	FilterEq, FilterNe, FilterIn, FilterNotIn, FilterContains, FilterMatches,
+	FilterExists, FilterNotExists,
+}
+
+// headerPrefix selects a request header instead of a payload field, e.g.
+// "header:X-GitHub-Event".
+const headerPrefix = "header:"
+
+// Filter is a condition on one field of a request. Path is a JSONPath into
+// the payload or "header:<Name>". A path with a wildcard yields every match;
+// positive operators need one of them to match, negative ones need none to.
+type Filter struct {
+	Path   string   `json:"path"`
+	Op     string   `json:"op"`
+	Values []string `json:"values,omitempty"`
+}
+
+// ValidatePath checks a filter or key path: a JSONPath or "header:<Name>".
+func ValidatePath(path string) error {
+	if name, ok := strings.CutPrefix(path, headerPrefix); ok {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid path %q: empty header name", path)
+		}
+		return nil
+	}
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("path is required")
+	}
+	_, err := parsePath(path)
+	return err
+}
+
+// Validate checks the operator, its number of values and the path syntax.
+func (f Filter) Validate() error {
+	if err := ValidatePath(f.Path); err != nil {
+		return err
+	}
+
+	switch f.Op {
+	case FilterEq, FilterNe, FilterContains:
+		if len(f.Values) != 1 {
+			return fmt.Errorf("%s needs exactly one value", f.Op)
+		}
+	case FilterMatches:
+		if len(f.Values) != 1 {
+			return fmt.Errorf("%s needs exactly one value", f.Op)
+		}
+		if _, err := regexp.Compile(f.Values[0]); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", f.Values[0], err)
+		}
+	case FilterIn, FilterNotIn:
+		if len(f.Values) == 0 {
+			return fmt.Errorf("%s needs at least one value", f.Op)
+		}
+	case FilterExists, FilterNotExists:
+		if len(f.Values) != 0 {
+			return fmt.Errorf("%s takes no values", f.Op)
+		}
+	default:
+		return fmt.Errorf("op must be one of: %s", strings.Join(FilterOps, ", "))
+	}
+	return nil
+}
+
+// Payload is a decoded request that filters and keys are evaluated against.
+// Unlike template rendering, strings are kept as sent.
+type Payload struct {
+	header http.Header
+	body   any
+}
+
+// NewPayload decodes a JSON body. A body that isn't JSON is an error.
+func NewPayload(header http.Header, body []byte) (*Payload, error) {
+	v, err := decodeJSON(body, false)
+	if err != nil {
+		return nil, err
+	}
+	return &Payload{header: header, body: v}, nil
+}
+
+// Values returns the string form of every value at path: none when it is
+// missing or null, several for a wildcard or an array.
+func (p *Payload) Values(path string) ([]string, error) {
+	if name, ok := strings.CutPrefix(path, headerPrefix); ok {
+		return p.header.Values(strings.TrimSpace(name)), nil
+	}
+
+	v, err := Lookup(p.body, path)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	var collect func(v any)
+	collect = func(v any) {
+		switch x := v.(type) {
+		case nil:
+		case []any:
+			for _, item := range x {
+				collect(item)
+			}
+		default:
+			values = append(values, toString(x))
+		}
+	}
+	collect(v)
+	return values, nil
+}
+
+// Match reports whether the payload satisfies every filter.
+func (p *Payload) Match(filters []Filter) (bool, error) {
+	for _, f := range filters {
+		ok, err := p.match(f)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (p *Payload) match(f Filter) (bool, error) {
+	values, err := p.Values(f.Path)
+	if err != nil {
+		return false, err
+	}
+
+	some := func(pred func(string) bool) bool {
+		return slices.ContainsFunc(values, pred)
+	}
+	switch f.Op {
+	case FilterEq:
+		return some(func(v string) bool { return v == f.Values[0] }), nil
+	case FilterNe:
+		return !some(func(v string) bool { return v == f.Values[0] }), nil
+	case FilterIn:
+		return some(func(v string) bool { return slices.Contains(f.Values, v) }), nil
+	case FilterNotIn:
+		return !some(func(v string) bool { return slices.Contains(f.Values, v) }), nil
+	case FilterContains:
+		return some(func(v string) bool { return strings.Contains(v, f.Values[0]) }), nil
+	case FilterMatches:
+		re, err := regexp.Compile(f.Values[0])
+		if err != nil {
+			return false, err
+		}
+		return some(re.MatchString), nil
+	case FilterExists:
+		return len(values) > 0, nil
+	case FilterNotExists:
+		return len(values) == 0, nil
+	default:
+		return false, fmt.Errorf("unknown filter op %q", f.Op)
+	}
+}
@@ -0,0 +1,71 @@
+package inbound
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPayloadMatch(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "workflow_run")
+	payload, err := NewPayload(header, []byte(`{
+		"ref": "refs/heads/main",
+		"workflow_run": {"conclusion": "failure", "name": "[CI] build"},
+		"commits": [{"author": {"name": "alice"}}, {"author": {"name": "bob"}}],
+		"draft": false,
+		"note": null
+	}`))
+	if err != nil {
+		t.Fatalf("NewPayload: %v", err)
+	}
+
+	cases := []struct {
+		filter Filter
+		want   bool
+	}{
+		{Filter{Path: "$.ref", Op: FilterEq, Values: []string{"refs/heads/main"}}, true},
+		{Filter{Path: "ref", Op: FilterNe, Values: []string{"refs/heads/main"}}, false},
+		{Filter{Path: "$.workflow_run.conclusion", Op: FilterIn, Values: []string{"failure", "timed_out"}}, true},
+		{Filter{Path: "$.workflow_run.conclusion", Op: FilterNotIn, Values: []string{"success"}}, true},
+		{Filter{Path: "$.workflow_run.name", Op: FilterContains, Values: []string{"[CI]"}}, true},
+		{Filter{Path: "$.commits[*].author.name", Op: FilterEq, Values: []string{"bob"}}, true},
+		{Filter{Path: "$.commits[*].author.name", Op: FilterNe, Values: []string{"bob"}}, false},
+		{Filter{Path: "$.ref", Op: FilterMatches, Values: []string{`^refs/heads/(main|release/.*)$`}}, true},
+		{Filter{Path: "$.draft", Op: FilterEq, Values: []string{"false"}}, true},
+		{Filter{Path: "$.note", Op: FilterExists}, false},
+		{Filter{Path: "$.missing", Op: FilterNotExists}, true},
+		{Filter{Path: "$.missing", Op: FilterNe, Values: []string{"x"}}, true},
+		{Filter{Path: "header:X-GitHub-Event", Op: FilterEq, Values: []string{"workflow_run"}}, true},
+	}
+	for _, tc := range cases {
+		if err := tc.filter.Validate(); err != nil {
+			t.Fatalf("%+v: Validate: %v", tc.filter, err)
+		}
+		got, err := payload.Match([]Filter{tc.filter})
+		if err != nil || got != tc.want {
+			t.Errorf("%+v: got %v (%v), want %v", tc.filter, got, err, tc.want)
+		}
+	}
+
+	both := []Filter{cases[0].filter, cases[1].filter}
+	if ok, _ := payload.Match(both); ok {
+		t.Fatal("expected filters to be ANDed")
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	for _, f := range []Filter{
+		{Path: "", Op: FilterExists},
+		{Path: "$.a[", Op: FilterExists},
+		{Path: "header:", Op: FilterExists},
+		{Path: "$.a", Op: "like", Values: []string{"x"}},
+		{Path: "$.a", Op: FilterEq},
+		{Path: "$.a", Op: FilterIn},
+		{Path: "$.a", Op: FilterExists, Values: []string{"x"}},
+		{Path: "$.a", Op: FilterMatches, Values: []string{"("}},
+	} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", f)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// GitHubAdapter parses repository webhooks. The event type comes from the
+// X-GitHub-Event header.
+type GitHubAdapter struct{}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubRepo struct {
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+}
+
+type githubPayload struct {
+	Action     string     `json:"action"`
+	Repository githubRepo `json:"repository"`
+	Sender     githubUser `json:"sender"`
+
+	// push
+	Ref     string `json:"ref"`
+	Compare string `json:"compare"`
+	Created bool   `json:"created"`
+	Deleted bool   `json:"deleted"`
+	Forced  bool   `json:"forced"`
+	Pusher  struct {
+		Name string `json:"name"`
+	} `json:"pusher"`
+	Commits []struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commits"`
+
+	PullRequest *struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Merged  bool   `json:"merged"`
+		Draft   bool   `json:"draft"`
+		Head    struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+
+	Issue *struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	} `json:"issue"`
+
+	Comment *struct {
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	} `json:"comment"`
+
+	Release *struct {
+		TagName    string `json:"tag_name"`
+		Name       string `json:"name"`
+		Body       string `json:"body"`
+		HTMLURL    string `json:"html_url"`
+		Prerelease bool   `json:"prerelease"`
+	} `json:"release"`
+
+	WorkflowRun *struct {
+		Name       string `json:"name"`
+		HeadBranch string `json:"head_branch"`
+		HeadSHA    string `json:"head_sha"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+		RunNumber  int    `json:"run_number"`
+	} `json:"workflow_run"`
+
+	Zen string `json:"zen"`
+}
+
+func (GitHubAdapter) Name() string { return "GitHub" }
+
+func (GitHubAdapter) Match(header http.Header, body []byte) bool {
+	return header.Get("X-GitHub-Event") != ""
+}
+
+func (GitHubAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var p githubPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	kind := header.Get("X-GitHub-Event")
+	repo := p.Repository.FullName
+	e := Event{Type: kind, Actor: p.Sender.Login, Severity: notifier.SeverityInfo, URL: p.Repository.HTMLURL}
+
+	switch {
+	case kind == "ping":
+		e.Title = fmt.Sprintf("[%s] GitHub webhook connected", repo)
+		e.Text = p.Zen
+
+	case kind == "push":
+		branch := branchName(p.Ref)
+		e.Actor = p.Pusher.Name
+		e.URL = p.Compare
+		switch {
+		case p.Deleted:
+			e.Title = fmt.Sprintf("[%s] %s deleted", repo, branch)
+			e.Severity = notifier.SeverityWarning
+		case len(p.Commits) == 0 && p.Created:
+			e.Title = fmt.Sprintf("[%s] %s created", repo, branch)
+		default:
+			e.Title = fmt.Sprintf("[%s] %s pushed to %s", repo, plural(len(p.Commits), "commit", "commits"), branch)
+			if p.Forced {
+				e.Title += " (force-push)"
+				e.Severity = notifier.SeverityWarning
+			}
+		}
+		for _, c := range p.Commits {
+			e.Items = append(e.Items, fmt.Sprintf("%s %s (%s)", shortSHA(c.ID), firstLine(c.Message), c.Author.Name))
+		}
+
+	case kind == "pull_request" && p.PullRequest != nil:
+		pr := p.PullRequest
+		action := p.Action
+		if action == "closed" && pr.Merged {
+			action = "merged"
+			e.Severity = notifier.SeveritySuccess
+		}
+		e.Title = fmt.Sprintf("[%s] Pull request #%d %s: %s", repo, pr.Number, strings.ReplaceAll(action, "_", " "), pr.Title)
+		e.URL = pr.HTMLURL
+		e.Fields = []notifier.Field{{Name: "Branch", Value: fmt.Sprintf("%s → %s", pr.Head.Ref, pr.Base.Ref)}}
+
+	case kind == "issue_comment" && p.Issue != nil && p.Comment != nil:
+		e.Title = fmt.Sprintf("[%s] New comment on #%d: %s", repo, p.Issue.Number, p.Issue.Title)
+		e.Text = truncate(p.Comment.Body, 500)
+		e.URL = p.Comment.HTMLURL
+
+	case kind == "issues" && p.Issue != nil:
+		e.Title = fmt.Sprintf("[%s] Issue #%d %s: %s", repo, p.Issue.Number, p.Action, p.Issue.Title)
+		e.URL = p.Issue.HTMLURL
+
+	case kind == "release" && p.Release != nil:
+		r := p.Release
+		name := r.Name
+		if name == "" {
+			name = r.TagName
+		}
+		e.Title = fmt.Sprintf("[%s] Release %s %s", repo, name, p.Action)
+		if r.Prerelease {
+			e.Title += " (pre-release)"
+		}
+		e.Text = truncate(r.Body, 500)
+		e.URL = r.HTMLURL
+		if p.Action == "published" {
+			e.Severity = notifier.SeveritySuccess
+		}
+
+	case kind == "workflow_run" && p.WorkflowRun != nil:
+		run := p.WorkflowRun
+		state := run.Status
+		if run.Status == "completed" {
+			state = run.Conclusion
+		}
+		e.Title = fmt.Sprintf("[%s] %s #%d %s on %s", repo, run.Name, run.RunNumber, state, run.HeadBranch)
+		e.Severity = conclusionSeverity(state)
+		e.URL = run.HTMLURL
+		e.Fields = []notifier.Field{{Name: "Commit", Value: shortSHA(run.HeadSHA)}}
+
+	default:
+		e.Title = fmt.Sprintf("[%s] GitHub %s event", repo, strings.ReplaceAll(kind, "_", " "))
+		if p.Action != "" {
+			e.Title += " (" + p.Action + ")"
+		}
+	}
+	return []Event{e}, nil
+}
+
+// conclusionSeverity maps CI results ("success", "failed", "FAILURE", ...) to a severity.
+func conclusionSeverity(result string) notifier.Severity {
+	switch strings.ToLower(result) {
+	case "success", "successful", "passed", "fixed":
+		return notifier.SeveritySuccess
+	case "failure", "failed", "error", "timed_out", "broken", "still failing":
+		return notifier.SeverityCritical
+	case "cancelled", "canceled", "aborted", "unstable", "action_required", "skipped", "stopped":
+		return notifier.SeverityWarning
+	default:
+		return notifier.SeverityInfo
+	}
+}
@@ -0,0 +1,164 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// GitLabAdapter parses project webhooks. The event type comes from the
+// payload's object_kind; GitLab also sends it in X-Gitlab-Event.
+type GitLabAdapter struct{}
+
+type gitlabPayload struct {
+	ObjectKind string `json:"object_kind"`
+	UserName   string `json:"user_name"`
+	User       struct {
+		Name string `json:"name"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+
+	// push and tag_push
+	Ref               string `json:"ref"`
+	Before            string `json:"before"`
+	After             string `json:"after"`
+	TotalCommitsCount int    `json:"total_commits_count"`
+	Commits           []struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commits"`
+
+	ObjectAttributes struct {
+		ID           int      `json:"id"`
+		IID          int      `json:"iid"`
+		Title        string   `json:"title"`
+		Action       string   `json:"action"`
+		State        string   `json:"state"`
+		Status       string   `json:"status"`
+		URL          string   `json:"url"`
+		Ref          string   `json:"ref"`
+		SHA          string   `json:"sha"`
+		Duration     *float64 `json:"duration"`
+		SourceBranch string   `json:"source_branch"`
+		TargetBranch string   `json:"target_branch"`
+		Note         string   `json:"note"`
+	} `json:"object_attributes"`
+
+	Builds []struct {
+		Name   string `json:"name"`
+		Stage  string `json:"stage"`
+		Status string `json:"status"`
+	} `json:"builds"`
+}
+
+// zeroSHA is the "before" or "after" commit of a created or deleted ref.
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+func (GitLabAdapter) Name() string { return "GitLab" }
+
+func (GitLabAdapter) Match(header http.Header, body []byte) bool {
+	return header.Get("X-Gitlab-Event") != ""
+}
+
+func (GitLabAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var p gitlabPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	project := p.Project.PathWithNamespace
+	attrs := p.ObjectAttributes
+	actor := p.User.Name
+	if actor == "" {
+		actor = p.UserName
+	}
+	e := Event{Type: p.ObjectKind, Actor: actor, Severity: notifier.SeverityInfo, URL: p.Project.WebURL}
+
+	switch p.ObjectKind {
+	case "push", "tag_push":
+		ref := branchName(p.Ref)
+		switch {
+		case p.After == zeroSHA:
+			e.Title = fmt.Sprintf("[%s] %s deleted", project, ref)
+			e.Severity = notifier.SeverityWarning
+		case p.ObjectKind == "tag_push":
+			e.Title = fmt.Sprintf("[%s] Tag %s pushed", project, ref)
+		default:
+			e.Title = fmt.Sprintf("[%s] %s pushed to %s", project, plural(p.TotalCommitsCount, "commit", "commits"), ref)
+			e.URL = fmt.Sprintf("%s/-/compare/%s...%s", p.Project.WebURL, shortSHA(p.Before), shortSHA(p.After))
+			if p.Before == zeroSHA {
+				e.URL = fmt.Sprintf("%s/-/tree/%s", p.Project.WebURL, ref)
+			}
+		}
+		for _, c := range p.Commits {
+			e.Items = append(e.Items, fmt.Sprintf("%s %s (%s)", shortSHA(c.ID), firstLine(c.Message), c.Author.Name))
+		}
+
+	case "merge_request":
+		action := attrs.Action
+		if action == "" {
+			action = attrs.State
+		}
+		if action == "merge" {
+			action = "merged"
+			e.Severity = notifier.SeveritySuccess
+		}
+		e.Title = fmt.Sprintf("[%s] Merge request !%d %s: %s", project, attrs.IID, pastTense(action), attrs.Title)
+		e.URL = attrs.URL
+		e.Fields = []notifier.Field{{Name: "Branch", Value: fmt.Sprintf("%s → %s", attrs.SourceBranch, attrs.TargetBranch)}}
+
+	case "pipeline":
+		e.Title = fmt.Sprintf("[%s] Pipeline #%d %s on %s", project, attrs.ID, attrs.Status, attrs.Ref)
+		e.Severity = conclusionSeverity(attrs.Status)
+		e.URL = fmt.Sprintf("%s/-/pipelines/%d", p.Project.WebURL, attrs.ID)
+		e.Fields = []notifier.Field{{Name: "Commit", Value: shortSHA(attrs.SHA)}}
+		if attrs.Duration != nil {
+			e.Fields = append(e.Fields, notifier.Field{Name: "Duration", Value: fmt.Sprintf("%.0fs", *attrs.Duration)})
+		}
+		for _, b := range p.Builds {
+			if b.Status == "failed" {
+				e.Items = append(e.Items, fmt.Sprintf("%s (%s) failed", b.Name, b.Stage))
+			}
+		}
+		if len(e.Items) > 0 {
+			e.ItemsTitle = "Failed jobs"
+		}
+
+	case "issue":
+		e.Title = fmt.Sprintf("[%s] Issue #%d %s: %s", project, attrs.IID, pastTense(attrs.Action), attrs.Title)
+		e.URL = attrs.URL
+
+	case "note":
+		e.Title = fmt.Sprintf("[%s] New comment", project)
+		e.Text = truncate(attrs.Note, 500)
+		e.URL = attrs.URL
+
+	default:
+		e.Title = fmt.Sprintf("[%s] GitLab %s event", project, strings.ReplaceAll(p.ObjectKind, "_", " "))
+	}
+	return []Event{e}, nil
+}
+
+// pastTense turns GitLab's "open", "close" and "reopen" actions into
+// "opened", "closed" and "reopened"; other actions are returned unchanged.
+func pastTense(action string) string {
+	switch action {
+	case "open":
+		return "opened"
+	case "close":
+		return "closed"
+	case "reopen":
+		return "reopened"
+	case "update":
+		return "updated"
+	}
+	return action
+}
@@ -0,0 +1,90 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// GrafanaAdapter parses Grafana webhook contact points. Unified alerting sends
+// an Alertmanager-style group; legacy dashboard alerts send one rule.
+type GrafanaAdapter struct{}
+
+type grafanaLegacyPayload struct {
+	Title       string `json:"title"`
+	RuleID      int64  `json:"ruleId"`
+	RuleName    string `json:"ruleName"`
+	RuleURL     string `json:"ruleUrl"`
+	State       string `json:"state"`
+	Message     string `json:"message"`
+	EvalMatches []struct {
+		Metric string            `json:"metric"`
+		Value  float64           `json:"value"`
+		Tags   map[string]string `json:"tags"`
+	} `json:"evalMatches"`
+	Tags map[string]string `json:"tags"`
+}
+
+func (GrafanaAdapter) Name() string { return "Grafana" }
+
+func (GrafanaAdapter) Match(header http.Header, body []byte) bool {
+	var probe struct {
+		OrgID    *int   `json:"orgId"`
+		RuleName string `json:"ruleName"`
+		RuleURL  string `json:"ruleUrl"`
+	}
+	if json.Unmarshal(body, &probe) != nil {
+		return false
+	}
+	return probe.OrgID != nil || (probe.RuleName != "" && probe.RuleURL != "")
+}
+
+func (GrafanaAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var unified amPayload
+	if err := json.Unmarshal(body, &unified); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	if unified.Alerts != nil {
+		return alertEvents(unified, "Grafana"), nil
+	}
+
+	var p grafanaLegacyPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	status := StatusFiring
+	severity := alertSeverity(p.Tags["severity"])
+	switch p.State {
+	case "ok":
+		status, severity = StatusResolved, notifier.SeveritySuccess
+	case "no_data", "pending":
+		severity = notifier.SeverityWarning
+	case "paused":
+		severity = notifier.SeverityInfo
+	}
+
+	e := Event{
+		Type:        "alert",
+		Title:       fmt.Sprintf("[%s] %s", strings.ToUpper(strings.ReplaceAll(p.State, "_", " ")), p.RuleName),
+		Text:        p.Message,
+		URL:         p.RuleURL,
+		Severity:    severity,
+		Status:      status,
+		Fingerprint: fmt.Sprintf("grafana-rule-%d", p.RuleID),
+		Labels:      map[string]string{"alertname": p.RuleName},
+		Fields:      labelFields(p.Tags),
+	}
+	for k, v := range p.Tags {
+		e.Labels[k] = v
+	}
+	for _, m := range p.EvalMatches {
+		e.Items = append(e.Items, fmt.Sprintf("%s = %g", m.Metric, m.Value))
+	}
+	if len(e.Items) > 0 {
+		e.ItemsTitle = "Matched series"
+	}
+	return []Event{e}, nil
+}
@@ -0,0 +1,254 @@
+// Package inbound turns webhook payloads from third-party tools (GitHub,
+// GitLab, Jenkins, Alertmanager, ...) into structured events. Each provider
+// has an Adapter; events render into Chatwork messages through notifier.
+package inbound
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// Providers supported by the default registry.
+const (
+	ProviderGitHub       = "github"
+	ProviderGitLab       = "gitlab"
+	ProviderBitbucket    = "bitbucket"
+	ProviderJenkins      = "jenkins"
+	ProviderSentry       = "sentry"
+	ProviderGrafana      = "grafana"
+	ProviderAlertmanager = "alertmanager"
+	ProviderDiscord      = "discord"
+	ProviderSlack        = "slack"
+)
+
+// Providers lists every built-in provider in detection order.
+var Providers = []string{
+	ProviderGitHub, ProviderGitLab, ProviderBitbucket, ProviderSentry,
+	ProviderGrafana, ProviderAlertmanager, ProviderJenkins, ProviderDiscord,
+	ProviderSlack,
+}
+
+// Alert statuses reported by alerting providers.
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Event is one thing that happened in a provider, e.g. a push, a failed
+// pipeline or a firing alert.
+type Event struct {
+	Provider string            `json:"provider"`
+	Type     string            `json:"type"` // provider event type, e.g. "push" or "merge_request"
+	Title    string            `json:"title"`
+	Text     string            `json:"text,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Actor    string            `json:"actor,omitempty"`
+	Severity notifier.Severity `json:"severity"`
+	Fields   []notifier.Field  `json:"fields,omitempty"`
+
+	// Items are the lines listed under the summary, e.g. commits or matched series.
+	ItemsTitle string   `json:"itemsTitle,omitempty"`
+	Items      []string `json:"items,omitempty"`
+
+	// Alerting providers identify each alert so repeats and resolutions can be matched.
+	Status      string            `json:"status,omitempty"`
+	Fingerprint string            `json:"fingerprint,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// Adapter parses the payloads of one provider.
+type Adapter interface {
+	// Name is the provider's display name, e.g. "GitHub".
+	Name() string
+	// Match reports whether the request looks like one of this provider's webhooks.
+	Match(header http.Header, body []byte) bool
+	// Parse converts the payload into events. Payloads the adapter doesn't
+	// know return a single generic event rather than an error.
+	Parse(header http.Header, body []byte) ([]Event, error)
+}
+
+// Registry looks adapters up by provider.
+type Registry struct {
+	adapters map[string]Adapter
+	order    []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{adapters: make(map[string]Adapter)}
+}
+
+// NewDefaultRegistry registers every built-in provider.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(ProviderGitHub, GitHubAdapter{})
+	r.Register(ProviderGitLab, GitLabAdapter{})
+	r.Register(ProviderBitbucket, BitbucketAdapter{})
+	r.Register(ProviderSentry, SentryAdapter{})
+	r.Register(ProviderGrafana, GrafanaAdapter{})
+	r.Register(ProviderAlertmanager, AlertmanagerAdapter{})
+	r.Register(ProviderJenkins, JenkinsAdapter{})
+	r.Register(ProviderDiscord, DiscordAdapter{})
+	r.Register(ProviderSlack, SlackAdapter{})
+	return r
+}
+
+// Register adds an adapter; adapters registered first are tried first by Detect.
+func (r *Registry) Register(provider string, a Adapter) {
+	if _, ok := r.adapters[provider]; !ok {
+		r.order = append(r.order, provider)
+	}
+	r.adapters[provider] = a
+}
+
+func (r *Registry) Get(provider string) (Adapter, bool) {
+	a, ok := r.adapters[provider]
+	return a, ok
+}
+
+// Names returns the registered providers in registration order.
+func (r *Registry) Names() []string {
+	return append([]string(nil), r.order...)
+}
+
+// Detect returns the first provider whose adapter matches the request.
+func (r *Registry) Detect(header http.Header, body []byte) (string, bool) {
+	for _, name := range r.order {
+		if r.adapters[name].Match(header, body) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Parse converts the payload with the provider's adapter. An empty provider
+// is detected from the request.
+func (r *Registry) Parse(provider string, header http.Header, body []byte) ([]Event, error) {
+	if provider == "" {
+		detected, ok := r.Detect(header, body)
+		if !ok {
+			return nil, fmt.Errorf("unrecognised webhook payload")
+		}
+		provider = detected
+	}
+	a, ok := r.adapters[provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported webhook provider: %s", provider)
+	}
+	events, err := a.Parse(header, body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", a.Name(), err)
+	}
+	for i := range events {
+		events[i].Provider = provider
+	}
+	return events, nil
+}
+
+// maxItems caps the lines listed under an event; the rest are summarised.
+const maxItems = 10
+
+// Message converts the event into a channel-agnostic notification.
+func (e Event) Message() notifier.Message {
+	msg := notifier.Message{
+		Title:    e.Title,
+		Text:     e.Text,
+		Severity: e.Severity,
+		Fields:   e.Fields,
+	}
+	if e.Actor != "" {
+		msg.Fields = append([]notifier.Field{{Name: "By", Value: e.Actor}}, msg.Fields...)
+	}
+	if len(e.Items) > 0 || e.URL != "" {
+		section := notifier.Section{Title: e.ItemsTitle, URL: e.URL}
+		section.Lines = e.Items
+		if len(e.Items) > maxItems {
+			section.Lines = append(append([]string(nil), e.Items[:maxItems]...), fmt.Sprintf("… and %d more", len(e.Items)-maxItems))
+		}
+		msg.Sections = []notifier.Section{section}
+	}
+	if msg.Title == "" {
+		msg.Title = e.Provider + " " + e.Type
+	}
+	return msg
+}
+
+// ChatworkBuilder renders the events as one Chatwork message, an [info] box each.
+func ChatworkBuilder(events []Event) *chatwork.Builder {
+	b := chatwork.NewBuilder()
+	for _, e := range events {
+		b.Append(notifier.ChatworkBuilder(e.Message()))
+	}
+	return b
+}
+
+// RenderChatwork renders the events as one Chatwork message body.
+func RenderChatwork(events []Event) string {
+	return ChatworkBuilder(events).String()
+}
+
+// labelFields turns labels into sorted fields, skipping the ones in omit.
+func labelFields(labels map[string]string, omit ...string) []notifier.Field {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		skip := false
+		for _, o := range omit {
+			if k == o {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	fields := make([]notifier.Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, notifier.Field{Name: k, Value: labels[k]})
+	}
+	return fields
+}
+
+// firstLine returns the first line of a commit message or description.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// shortSHA abbreviates a commit hash like git does.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// truncate shortens long free text such as release notes.
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(r[:n])) + "…"
+}
+
+// branchName strips the refs/heads/ or refs/tags/ prefix of a git ref.
+func branchName(ref string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
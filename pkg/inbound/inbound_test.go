@@ -0,0 +1,121 @@
+package inbound
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+// fixtures are real-world shaped payloads with the headers each provider sends.
+var fixtures = []struct {
+	name     string
+	provider string
+	header   map[string]string
+}{
+	{"github_push", ProviderGitHub, map[string]string{"X-GitHub-Event": "push"}},
+	{"github_pull_request", ProviderGitHub, map[string]string{"X-GitHub-Event": "pull_request"}},
+	{"github_workflow_run", ProviderGitHub, map[string]string{"X-GitHub-Event": "workflow_run"}},
+	{"gitlab_push", ProviderGitLab, map[string]string{"X-Gitlab-Event": "Push Hook"}},
+	{"gitlab_merge_request", ProviderGitLab, map[string]string{"X-Gitlab-Event": "Merge Request Hook"}},
+	{"gitlab_pipeline", ProviderGitLab, map[string]string{"X-Gitlab-Event": "Pipeline Hook"}},
+	{"bitbucket_push", ProviderBitbucket, map[string]string{"X-Event-Key": "repo:push", "X-Hook-UUID": "b0c1d2e3"}},
+	{"bitbucket_pullrequest_fulfilled", ProviderBitbucket, map[string]string{"X-Event-Key": "pullrequest:fulfilled", "X-Hook-UUID": "b0c1d2e3"}},
+	{"jenkins_failure", ProviderJenkins, nil},
+	{"sentry_issue", ProviderSentry, map[string]string{"Sentry-Hook-Resource": "issue"}},
+	{"sentry_metric_alert", ProviderSentry, map[string]string{"Sentry-Hook-Resource": "metric_alert"}},
+	{"grafana_unified", ProviderGrafana, nil},
+	{"grafana_legacy", ProviderGrafana, nil},
+	{"alertmanager", ProviderAlertmanager, nil},
+	{"discord_embed", ProviderDiscord, nil},
+	{"slack_attachments", ProviderSlack, nil},
+}
+
+func loadFixture(t *testing.T, name string, headers map[string]string) (http.Header, []byte) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	header := http.Header{}
+	for k, v := range headers {
+		header.Set(k, v)
+	}
+	return header, body
+}
+
+func TestAdaptersGolden(t *testing.T) {
+	registry := NewDefaultRegistry()
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			header, body := loadFixture(t, f.name, f.header)
+			events, err := registry.Parse(f.provider, header, body)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			got := RenderChatwork(events)
+			path := filepath.Join("testdata", f.name+".golden")
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Fatalf("unexpected chatwork body\n--- got ---\n%s\n--- want ---\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestRegistryDetect(t *testing.T) {
+	registry := NewDefaultRegistry()
+	for _, f := range fixtures {
+		header, body := loadFixture(t, f.name, f.header)
+		if got, ok := registry.Detect(header, body); !ok || got != f.provider {
+			t.Errorf("%s: detected %q, want %q", f.name, got, f.provider)
+		}
+	}
+
+	if _, ok := registry.Detect(http.Header{}, []byte(`{"text":"hello"}`)); ok {
+		t.Fatal("expected an unknown payload not to be detected")
+	}
+	if _, err := registry.Parse("", http.Header{}, []byte(`{"text":"hello"}`)); err == nil {
+		t.Fatal("expected an error for an unrecognised payload")
+	}
+	if _, err := registry.Parse("travis", http.Header{}, []byte(`{}`)); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
+
+func TestAlertEventsCarryStatusAndFingerprint(t *testing.T) {
+	header, body := loadFixture(t, "alertmanager", nil)
+	events, err := NewDefaultRegistry().Parse(ProviderAlertmanager, header, body)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected one event per alert, got %d", len(events))
+	}
+	if events[0].Status != StatusFiring || events[0].Fingerprint != "d3c6a1f0e9b24c11" || events[0].Severity != notifier.SeverityWarning {
+		t.Fatalf("unexpected firing event: %+v", events[0])
+	}
+	if events[1].Status != StatusResolved || events[1].Severity != notifier.SeveritySuccess || events[1].Labels["instance"] != "db-2:9100" {
+		t.Fatalf("unexpected resolved event: %+v", events[1])
+	}
+
+	legacy := []byte(`{"alerts":[{"status":"firing","labels":{"alertname":"A","job":"x"}}],"groupKey":"g"}`)
+	events, _ = NewDefaultRegistry().Parse(ProviderAlertmanager, http.Header{}, legacy)
+	if len(events) != 1 || events[0].Fingerprint == "" {
+		t.Fatalf("expected a fingerprint derived from labels, got %+v", events)
+	}
+}
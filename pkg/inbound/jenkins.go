@@ -0,0 +1,97 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// JenkinsAdapter parses the JSON sent by the Jenkins Notification plugin.
+type JenkinsAdapter struct{}
+
+type jenkinsPayload struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	URL         string `json:"url"`
+	Build       *struct {
+		FullURL  string `json:"full_url"`
+		Number   int    `json:"number"`
+		Phase    string `json:"phase"`
+		Status   string `json:"status"`
+		Duration int64  `json:"duration"` // milliseconds
+		Log      string `json:"log"`
+		SCM      struct {
+			URL    string `json:"url"`
+			Branch string `json:"branch"`
+			Commit string `json:"commit"`
+		} `json:"scm"`
+		Parameters map[string]any `json:"parameters"`
+	} `json:"build"`
+}
+
+func (JenkinsAdapter) Name() string { return "Jenkins" }
+
+func (JenkinsAdapter) Match(header http.Header, body []byte) bool {
+	var p jenkinsPayload
+	return json.Unmarshal(body, &p) == nil && p.Build != nil && p.Build.Phase != ""
+}
+
+func (JenkinsAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var p jenkinsPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	if p.Build == nil {
+		return nil, fmt.Errorf("payload has no build")
+	}
+	b := p.Build
+	name := p.DisplayName
+	if name == "" {
+		name = p.Name
+	}
+
+	e := Event{Type: strings.ToLower(b.Phase), URL: b.FullURL, Severity: notifier.SeverityInfo}
+	switch b.Phase {
+	case "STARTED", "QUEUED":
+		e.Title = fmt.Sprintf("Jenkins %s #%d %s", name, b.Number, strings.ToLower(b.Phase))
+	default:
+		status := b.Status
+		if status == "" {
+			status = strings.ToLower(b.Phase)
+		}
+		e.Title = fmt.Sprintf("Jenkins %s #%d: %s", name, b.Number, status)
+		e.Severity = conclusionSeverity(status)
+	}
+
+	if b.SCM.Branch != "" {
+		e.Fields = append(e.Fields, notifier.Field{Name: "Branch", Value: branchName(strings.TrimPrefix(b.SCM.Branch, "origin/"))})
+	}
+	if b.SCM.Commit != "" {
+		e.Fields = append(e.Fields, notifier.Field{Name: "Commit", Value: shortSHA(b.SCM.Commit)})
+	}
+	if b.Duration > 0 {
+		e.Fields = append(e.Fields, notifier.Field{Name: "Duration", Value: fmt.Sprintf("%ds", b.Duration/1000)})
+	}
+	if e.Severity == notifier.SeverityCritical && b.Log != "" {
+		e.ItemsTitle = "Log"
+		e.Items = lastLines(b.Log, 5)
+	}
+	return []Event{e}, nil
+}
+
+// lastLines returns the last n non-empty lines of a build log.
+func lastLines(log string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
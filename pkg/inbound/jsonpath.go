@@ -0,0 +1,133 @@
+package inbound
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a JSONPath expression: a key, an index or a
+// wildcard over every element.
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parsePath parses the JSONPath subset templates use: an optional "$",
+// ".key", "['key']", "[0]", "[-1]" and the "[*]" / ".*" wildcards, e.g.
+// "$.commits[*].author.name". The leading "$." may be left out.
+func parsePath(path string) ([]pathSegment, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+	if p != "" && p[0] != '.' && p[0] != '[' {
+		p = "." + p
+	}
+
+	var segments []pathSegment
+	for p != "" {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			name := p[:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid path %q: empty key", path)
+			}
+			if name == "*" {
+				segments = append(segments, pathSegment{wildcard: true})
+			} else {
+				segments = append(segments, pathSegment{key: name})
+			}
+			p = p[end:]
+
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed [", path)
+			}
+			inner := strings.TrimSpace(p[1:end])
+			p = p[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid path %q: bad index %q", path, inner)
+				}
+				segments = append(segments, pathSegment{index: i, isIndex: true})
+			}
+
+		default:
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// Lookup evaluates path against a decoded JSON value. Missing keys and
+// out-of-range indexes yield nil. A path with a wildcard returns every match
+// as a []any.
+func Lookup(root any, path string) (any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []any{root}
+	multi := false
+	for _, seg := range segments {
+		var next []any
+		for _, node := range nodes {
+			switch {
+			case seg.wildcard:
+				multi = true
+				switch v := node.(type) {
+				case []any:
+					next = append(next, v...)
+				case map[string]any:
+					for _, k := range slices.Sorted(maps.Keys(v)) {
+						next = append(next, v[k])
+					}
+				}
+			case seg.isIndex:
+				if list, ok := node.([]any); ok {
+					i := seg.index
+					if i < 0 {
+						i += len(list)
+					}
+					if i >= 0 && i < len(list) {
+						next = append(next, list[i])
+					}
+				}
+			default:
+				if obj, ok := node.(map[string]any); ok {
+					if v, ok := obj[seg.key]; ok {
+						next = append(next, v)
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+
+	if multi {
+		if nodes == nil {
+			nodes = []any{}
+		}
+		return nodes, nil
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	return nodes[0], nil
+}
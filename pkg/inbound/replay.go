@@ -0,0 +1,79 @@
+package inbound
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ReplayWindow is how long a signed delivery is remembered. A request whose
+// signature was already accepted within the window is a replay.
+const ReplayWindow = 24 * time.Hour
+
+// deliveryIDHeaders carry the sender's ID of the delivery. They are not
+// covered by any signature, so they only identify deliveries authenticated
+// with a shared token.
+var deliveryIDHeaders = []string{"X-Gitlab-Event-UUID", "X-GitHub-Delivery", "X-Request-UUID", "Request-ID"}
+
+// DeliveryID returns what identifies a delivery for replay checks. For the
+// HMAC schemes that is the signature, which a replay has to reuse; with
+// X-Signature-Timestamp it differs for every send, without it two deliveries
+// with the same body look the same. Shared-token schemes fall back to the
+// sender's delivery ID header. The result is empty when there is neither.
+func DeliveryID(header http.Header) string {
+	if sig := header.Get("X-Signature-256"); sig != "" {
+		return sig + "." + header.Get("X-Signature-Timestamp")
+	}
+	for _, name := range []string{"X-Hub-Signature-256", "X-Hub-Signature", "Sentry-Hook-Signature"} {
+		if sig := header.Get(name); sig != "" {
+			return sig
+		}
+	}
+	for _, name := range deliveryIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// ReplayStore counts hits on a key within a window; ratelimit.Store satisfies it.
+type ReplayStore interface {
+	Incr(key string, window time.Duration, now time.Time) (hits int, resetAt time.Time, err error)
+	Delete(key string) error
+}
+
+// ReplayGuard remembers the deliveries it has seen for ReplayWindow.
+type ReplayGuard struct {
+	store ReplayStore
+}
+
+func NewReplayGuard(store ReplayStore) *ReplayGuard {
+	return &ReplayGuard{store: store}
+}
+
+// Seen records the delivery under scope and reports whether it was already
+// recorded. key is empty, and seen false, for deliveries without an ID.
+func (g *ReplayGuard) Seen(scope string, header http.Header, now time.Time) (key string, seen bool, err error) {
+	id := DeliveryID(header)
+	if id == "" {
+		return "", false, nil
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSpace(id)))
+	key = "replay:" + scope + ":" + hex.EncodeToString(sum[:16])
+	hits, _, err := g.store.Incr(key, ReplayWindow, now)
+	if err != nil {
+		return "", false, err
+	}
+	return key, hits > 1, nil
+}
+
+// Forget drops a recorded delivery, so the sender may retry one that failed.
+func (g *ReplayGuard) Forget(key string) error {
+	if key == "" {
+		return nil
+	}
+	return g.store.Delete(key)
+}
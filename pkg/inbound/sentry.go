@@ -0,0 +1,167 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+)
+
+// SentryAdapter parses Sentry integration webhooks (the resource is in the
+// Sentry-Hook-Resource header) and the legacy WebHooks plugin payload.
+type SentryAdapter struct{}
+
+type sentryIssue struct {
+	ID        string `json:"id"`
+	ShortID   string `json:"shortId"`
+	Title     string `json:"title"`
+	Culprit   string `json:"culprit"`
+	Level     string `json:"level"`
+	Status    string `json:"status"`
+	Count     string `json:"count"`
+	UserCount int    `json:"userCount"`
+	WebURL    string `json:"web_url"`
+	Permalink string `json:"permalink"`
+	Project   struct {
+		Slug string `json:"slug"`
+	} `json:"project"`
+}
+
+type sentryPayload struct {
+	Action string `json:"action"`
+	Actor  struct {
+		Name string `json:"name"`
+	} `json:"actor"`
+	Data struct {
+		Issue *sentryIssue `json:"issue"`
+		Event *struct {
+			Title       string `json:"title"`
+			Culprit     string `json:"culprit"`
+			Level       string `json:"level"`
+			WebURL      string `json:"web_url"`
+			IssueID     string `json:"issue_id"`
+			Environment string `json:"environment"`
+			Release     string `json:"release"`
+		} `json:"event"`
+		TriggeredRule    string `json:"triggered_rule"`
+		DescriptionTitle string `json:"description_title"`
+		DescriptionText  string `json:"description_text"`
+		WebURL           string `json:"web_url"`
+	} `json:"data"`
+
+	// Legacy WebHooks plugin
+	ProjectName string `json:"project_name"`
+	Message     string `json:"message"`
+	URL         string `json:"url"`
+	Level       string `json:"level"`
+	Culprit     string `json:"culprit"`
+}
+
+func (SentryAdapter) Name() string { return "Sentry" }
+
+func (SentryAdapter) Match(header http.Header, body []byte) bool {
+	if header.Get("Sentry-Hook-Resource") != "" {
+		return true
+	}
+	var p sentryPayload
+	return json.Unmarshal(body, &p) == nil && p.ProjectName != "" && p.Culprit != "" && p.URL != ""
+}
+
+func (SentryAdapter) Parse(header http.Header, body []byte) ([]Event, error) {
+	var p sentryPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	resource := header.Get("Sentry-Hook-Resource")
+	e := Event{Type: resource, Actor: p.Actor.Name, Severity: notifier.SeverityInfo}
+
+	switch {
+	case resource == "issue" && p.Data.Issue != nil:
+		issue := p.Data.Issue
+		e.Type = "issue." + p.Action
+		e.Title = fmt.Sprintf("[%s] Issue %s %s: %s", issue.Project.Slug, issue.ShortID, p.Action, issue.Title)
+		e.Text = issue.Culprit
+		e.URL = issue.WebURL
+		if e.URL == "" {
+			e.URL = issue.Permalink
+		}
+		switch p.Action {
+		case "resolved":
+			e.Severity = notifier.SeveritySuccess
+		case "created", "unresolved":
+			e.Severity = levelSeverity(issue.Level)
+		}
+		if issue.Level != "" {
+			e.Fields = append(e.Fields, notifier.Field{Name: "Level", Value: issue.Level})
+		}
+		if issue.Count != "" {
+			e.Fields = append(e.Fields, notifier.Field{Name: "Events", Value: issue.Count})
+		}
+		if p.Actor.Name == "Sentry" {
+			e.Actor = ""
+		}
+
+	case resource == "event_alert" && p.Data.Event != nil:
+		ev := p.Data.Event
+		e.Title = ev.Title
+		e.Text = ev.Culprit
+		e.URL = ev.WebURL
+		e.Severity = levelSeverity(ev.Level)
+		e.Actor = ""
+		if p.Data.TriggeredRule != "" {
+			e.Fields = append(e.Fields, notifier.Field{Name: "Alert rule", Value: p.Data.TriggeredRule})
+		}
+		if ev.Environment != "" {
+			e.Fields = append(e.Fields, notifier.Field{Name: "Environment", Value: ev.Environment})
+		}
+		if ev.Release != "" {
+			e.Fields = append(e.Fields, notifier.Field{Name: "Release", Value: ev.Release})
+		}
+
+	case resource == "metric_alert":
+		e.Type = "metric_alert." + p.Action
+		e.Title = p.Data.DescriptionTitle
+		e.Text = p.Data.DescriptionText
+		e.URL = p.Data.WebURL
+		e.Actor = ""
+		switch p.Action {
+		case "critical":
+			e.Severity, e.Status = notifier.SeverityCritical, StatusFiring
+		case "warning":
+			e.Severity, e.Status = notifier.SeverityWarning, StatusFiring
+		case "resolved":
+			e.Severity, e.Status = notifier.SeveritySuccess, StatusResolved
+		}
+
+	case resource == "" && p.ProjectName != "":
+		e.Type = "legacy"
+		e.Title = fmt.Sprintf("[%s] %s", p.ProjectName, firstLine(p.Message))
+		e.Text = p.Culprit
+		e.URL = p.URL
+		e.Severity = levelSeverity(p.Level)
+		if p.Level != "" {
+			e.Fields = []notifier.Field{{Name: "Level", Value: p.Level}}
+		}
+
+	default:
+		e.Title = fmt.Sprintf("Sentry %s event", strings.ReplaceAll(resource, "_", " "))
+		if p.Action != "" {
+			e.Title += " (" + p.Action + ")"
+		}
+	}
+	return []Event{e}, nil
+}
+
+// levelSeverity maps a Sentry level to a severity.
+func levelSeverity(level string) notifier.Severity {
+	switch level {
+	case "fatal", "error":
+		return notifier.SeverityCritical
+	case "warning":
+		return notifier.SeverityWarning
+	default:
+		return notifier.SeverityInfo
+	}
+}
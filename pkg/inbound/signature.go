@@ -0,0 +1,99 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureTolerance is how far a signed timestamp may be from now.
+const SignatureTolerance = 5 * time.Minute
+
+// Signature schemes accepted by VerifySignature.
+const (
+	SchemeTimestamped = "x-signature-256+timestamp" // notifier.Sign: HMAC-SHA256 over "timestamp.body"
+	SchemeHMAC        = "x-signature-256"           // HMAC-SHA256 over the body
+	SchemeHub         = "x-hub-signature"           // GitHub, Bitbucket, Gitea: "sha256=<hex>" or "sha1=<hex>"
+	SchemeSentry      = "sentry-hook-signature"     // Sentry integrations: hex HMAC-SHA256 over the body
+	SchemeGitLabToken = "x-gitlab-token"            // GitLab sends the secret itself
+	SchemeBearer      = "authorization"             // Alertmanager, Grafana: "Bearer <secret>"
+)
+
+// VerifySignature checks the request against secret with whichever scheme the
+// sender used and returns that scheme. Senders that can only send a shared
+// token (GitLab, Alertmanager, Grafana) are compared in constant time.
+func VerifySignature(header http.Header, body []byte, secret string, now time.Time) (string, bool) {
+	if secret == "" {
+		return "", false
+	}
+
+	if sig := header.Get("X-Signature-256"); sig != "" {
+		sig = strings.TrimPrefix(sig, "sha256=")
+		if ts := header.Get("X-Signature-Timestamp"); ts != "" {
+			unix, err := strconv.ParseInt(ts, 10, 64)
+			if err != nil || now.Sub(time.Unix(unix, 0)).Abs() > SignatureTolerance {
+				return SchemeTimestamped, false
+			}
+			return SchemeTimestamped, hexEqual(sig, mac(sha256.New, secret, []byte(ts), []byte("."), body))
+		}
+		return SchemeHMAC, hexEqual(sig, mac(sha256.New, secret, body))
+	}
+
+	for _, name := range []string{"X-Hub-Signature-256", "X-Hub-Signature"} {
+		sig := header.Get(name)
+		if sig == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(sig, "sha256="):
+			return SchemeHub, hexEqual(strings.TrimPrefix(sig, "sha256="), mac(sha256.New, secret, body))
+		case strings.HasPrefix(sig, "sha1="):
+			return SchemeHub, hexEqual(strings.TrimPrefix(sig, "sha1="), mac(sha1.New, secret, body))
+		default:
+			return SchemeHub, false
+		}
+	}
+
+	if sig := header.Get("Sentry-Hook-Signature"); sig != "" {
+		return SchemeSentry, hexEqual(sig, mac(sha256.New, secret, body))
+	}
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return SchemeGitLabToken, tokenEqual(token, secret)
+	}
+	if auth := header.Get("Authorization"); auth != "" {
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		return SchemeBearer, ok && tokenEqual(strings.TrimSpace(token), secret)
+	}
+	return "", false
+}
+
+// SignatureHeaders are the request headers that carry credentials; they are
+// not kept in delivery logs.
+var SignatureHeaders = []string{
+	"Authorization", "X-Signature-256", "X-Signature-Timestamp", "X-Hub-Signature",
+	"X-Hub-Signature-256", "Sentry-Hook-Signature", "X-Gitlab-Token",
+}
+
+func mac(h func() hash.Hash, secret string, parts ...[]byte) []byte {
+	m := hmac.New(h, []byte(secret))
+	for _, p := range parts {
+		m.Write(p)
+	}
+	return m.Sum(nil)
+}
+
+func hexEqual(sig string, want []byte) bool {
+	got, err := hex.DecodeString(strings.TrimSpace(sig))
+	return err == nil && hmac.Equal(got, want)
+}
+
+func tokenEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
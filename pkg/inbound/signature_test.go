@@ -0,0 +1,96 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/notifier"
+	"github.com/vfa-khuongdv/golang-cms/pkg/ratelimit"
+)
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"ok":true}`)
+	now := time.Unix(1760000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(body)
+	bodyMAC := hex.EncodeToString(m.Sum(nil))
+
+	cases := []struct {
+		name   string
+		header map[string]string
+		scheme string
+		wantOK bool
+		atTime time.Time
+	}{
+		{"timestamped", map[string]string{"X-Signature-256": "sha256=" + notifier.Sign(secret, ts, body), "X-Signature-Timestamp": ts}, SchemeTimestamped, true, now},
+		{"timestamped stale", map[string]string{"X-Signature-256": "sha256=" + notifier.Sign(secret, ts, body), "X-Signature-Timestamp": ts}, SchemeTimestamped, false, now.Add(10 * time.Minute)},
+		{"plain hmac", map[string]string{"X-Signature-256": bodyMAC}, SchemeHMAC, true, now},
+		{"github", map[string]string{"X-Hub-Signature-256": "sha256=" + bodyMAC}, SchemeHub, true, now},
+		{"github wrong", map[string]string{"X-Hub-Signature-256": "sha256=" + bodyMAC[:62] + "00"}, SchemeHub, false, now},
+		{"sentry", map[string]string{"Sentry-Hook-Signature": bodyMAC}, SchemeSentry, true, now},
+		{"gitlab", map[string]string{"X-Gitlab-Token": secret}, SchemeGitLabToken, true, now},
+		{"gitlab wrong", map[string]string{"X-Gitlab-Token": "nope"}, SchemeGitLabToken, false, now},
+		{"bearer", map[string]string{"Authorization": "Bearer " + secret}, SchemeBearer, true, now},
+		{"basic", map[string]string{"Authorization": "Basic " + secret}, SchemeBearer, false, now},
+		{"unsigned", nil, "", false, now},
+	}
+	for _, tc := range cases {
+		header := http.Header{}
+		for k, v := range tc.header {
+			header.Set(k, v)
+		}
+		scheme, ok := VerifySignature(header, body, secret, tc.atTime)
+		if scheme != tc.scheme || ok != tc.wantOK {
+			t.Errorf("%s: got (%q, %v), want (%q, %v)", tc.name, scheme, ok, tc.scheme, tc.wantOK)
+		}
+	}
+
+	tampered := http.Header{}
+	tampered.Set("X-Hub-Signature-256", "sha256="+bodyMAC)
+	if _, ok := VerifySignature(tampered, []byte(`{"ok":false}`), secret, now); ok {
+		t.Fatal("expected a modified body to be rejected")
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	guard := NewReplayGuard(ratelimit.NewMemoryStore())
+	now := time.Now()
+	signed := http.Header{"X-Hub-Signature-256": {"sha256=abc"}, "X-Github-Delivery": {"d-1"}}
+
+	if _, seen, err := guard.Seen("ep", signed, now); err != nil || seen {
+		t.Fatalf("first delivery: seen=%v err=%v", seen, err)
+	}
+	signed.Set("X-GitHub-Delivery", "d-2")
+	key, seen, _ := guard.Seen("ep", signed, now.Add(time.Minute))
+	if !seen {
+		t.Fatal("expected the same signature to be a replay whatever the delivery ID")
+	}
+	if _, seen, _ := guard.Seen("other", signed, now); seen {
+		t.Fatal("expected scopes to be kept apart")
+	}
+	if _, seen, _ := guard.Seen("ep", signed, now.Add(ReplayWindow+time.Minute)); seen {
+		t.Fatal("expected the delivery to be forgotten after the window")
+	}
+
+	if err := guard.Forget(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, seen, _ := guard.Seen("ep", signed, now); seen {
+		t.Fatal("expected a forgotten delivery to be accepted again")
+	}
+
+	token := http.Header{"Authorization": {"Bearer s3cret"}}
+	for i := 0; i < 2; i++ {
+		if key, seen, _ := guard.Seen("ep", token, now); seen || key != "" {
+			t.Fatal("expected deliveries without an ID to never be replays")
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+)
+
+// maxTemplateOutput caps what a template may render, so a loop over a large
+// payload can't build an unbounded message.
+const maxTemplateOutput = 256 << 10
+
+// Template renders arbitrary JSON payloads into Chatwork messages with a
+// user-defined Go text/template. The payload is the template's dot, so
+// {{ .repository.name }} reads a field; get adds JSONPath-style lookups and
+// the usual {{ if }} / {{ range }} actions give conditionals and loops.
+//
+// Every string in the payload and headers is escaped before rendering, so
+// payload content can't inject Chatwork tags; markup written in the template
+// itself is kept. raw undoes the escaping for a trusted value.
+type Template struct {
+	src  string
+	tmpl *template.Template
+}
+
+// ParseTemplate compiles src, reporting syntax errors and unknown functions.
+func ParseTemplate(src string) (*Template, error) {
+	tmpl, err := template.New("webhook").
+		Option("missingkey=zero").
+		Funcs(templateFuncs(nil, nil)).
+		Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{src: src, tmpl: tmpl}, nil
+}
+
+// Source returns the template text.
+func (t *Template) Source() string {
+	return t.src
+}
+
+// Render executes the template against a JSON body. The result is trimmed;
+// an empty result means the payload should not produce a message.
+func (t *Template) Render(header http.Header, body []byte) (string, error) {
+	payload, err := decodePayload(body)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(templateFuncs(payload, header))
+
+	out := &limitedBuffer{limit: maxTemplateOutput}
+	if err := tmpl.Execute(out, payload); err != nil {
+		return "", err
+	}
+	rendered := strings.ReplaceAll(out.String(), "<no value>", "")
+	return strings.TrimSpace(rendered), nil
+}
+
+// TemplateBuilder wraps rendered template output in a builder, one block per
+// line, so long messages are split between lines.
+func TemplateBuilder(rendered string) *chatwork.Builder {
+	b := chatwork.NewBuilder()
+	for _, line := range strings.Split(rendered, "\n") {
+		b.Raw(line)
+	}
+	return b
+}
+
+// decodePayload decodes a JSON body keeping integers exact and escapes every
+// string in it. Integers become int64 so {{ eq .count 3 }} works.
+func decodePayload(body []byte) (any, error) {
+	return decodeJSON(body, true)
+}
+
+// decodeJSON is decodePayload with escaping optional; filters compare the
+// payload's own strings.
+func decodeJSON(body []byte, escape bool) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("payload is not valid JSON: %w", err)
+	}
+	return normalise(v, escape), nil
+}
+
+func normalise(v any, escape bool) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			x[k] = normalise(val, escape)
+		}
+		return x
+	case []any:
+		for i, val := range x {
+			x[i] = normalise(val, escape)
+		}
+		return x
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+		f, _ := x.Float64()
+		return f
+	case string:
+		if escape {
+			return chatwork.Escape(x)
+		}
+		return x
+	default:
+		return v
+	}
+}
+
+// templateFuncs are the helpers available to templates. get and header read
+// the request being rendered; at parse time they are placeholders.
+func templateFuncs(payload any, header http.Header) template.FuncMap {
+	return template.FuncMap{
+		"get": func(path string, root ...any) (any, error) {
+			if len(root) > 0 {
+				return Lookup(root[0], path)
+			}
+			return Lookup(payload, path)
+		},
+		"header": func(name string) string {
+			return chatwork.Escape(header.Get(name))
+		},
+		"default": func(def, v any) any {
+			if isEmpty(v) {
+				return def
+			}
+			return v
+		},
+		"join": func(sep string, v any) string {
+			list, ok := v.([]any)
+			if !ok {
+				return toString(v)
+			}
+			parts := make([]string, 0, len(list))
+			for _, item := range list {
+				parts = append(parts, toString(item))
+			}
+			return strings.Join(parts, sep)
+		},
+		"first": func(v any) any {
+			if list, ok := v.([]any); ok && len(list) > 0 {
+				return list[0]
+			}
+			return nil
+		},
+		"last": func(v any) any {
+			if list, ok := v.([]any); ok && len(list) > 0 {
+				return list[len(list)-1]
+			}
+			return nil
+		},
+		"limit": func(n int, v any) []any {
+			list, _ := v.([]any)
+			if n >= 0 && len(list) > n {
+				return list[:n]
+			}
+			return list
+		},
+		"add": func(a, b any) any {
+			x, y := toNumber(a), toNumber(b)
+			if sum := x + y; sum == float64(int64(sum)) {
+				return int64(sum)
+			}
+			return x + y
+		},
+		"upper": func(v any) string { return strings.ToUpper(toString(v)) },
+		"lower": func(v any) string { return strings.ToLower(toString(v)) },
+		"trim":  func(v any) string { return strings.TrimSpace(toString(v)) },
+		"contains": func(sub string, v any) bool {
+			return strings.Contains(toString(v), sub)
+		},
+		"hasPrefix": func(prefix string, v any) bool {
+			return strings.HasPrefix(toString(v), prefix)
+		},
+		"replace": func(old, new string, v any) string {
+			return strings.ReplaceAll(toString(v), old, new)
+		},
+		"truncate": func(n int, v any) string {
+			return truncate(toString(v), n)
+		},
+		"firstLine": func(v any) string { return firstLine(toString(v)) },
+		"shortSHA":  func(v any) string { return shortSHA(toString(v)) },
+		"date":      formatDate,
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"raw": func(v any) string {
+			return strings.ReplaceAll(toString(v), "[\u200b", "[")
+		},
+	}
+}
+
+// formatDate formats an RFC 3339 string or a Unix timestamp (seconds or
+// milliseconds) with a Go layout, in UTC. Other values are returned as is.
+func formatDate(layout string, v any) string {
+	var t time.Time
+	switch x := v.(type) {
+	case int64:
+		if x > 1e12 {
+			t = time.UnixMilli(x)
+		} else {
+			t = time.Unix(x, 0)
+		}
+	case float64:
+		t = time.UnixMilli(int64(x * 1000))
+	case string:
+		parsed, err := time.Parse(time.RFC3339, x)
+		if err != nil {
+			return x
+		}
+		t = parsed
+	default:
+		return toString(v)
+	}
+	return t.UTC().Format(layout)
+}
+
+func toString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case map[string]any, []any:
+		b, _ := json.Marshal(x)
+		return string(b)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func toNumber(v any) float64 {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case float64:
+		return x
+	default:
+		return 0
+	}
+}
+
+func isEmpty(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case []any:
+		return len(x) == 0
+	case map[string]any:
+		return len(x) == 0
+	case bool:
+		return !x
+	default:
+		return false
+	}
+}
+
+// limitedBuffer fails the render once the output exceeds limit bytes.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("output exceeds %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,100 @@
+package inbound
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTemplateRender(t *testing.T) {
+	header, body := loadFixture(t, "github_push", map[string]string{"X-GitHub-Event": "push"})
+
+	src := `[info][title]{{ header "X-GitHub-Event" }} to {{ .repository.full_name }} by {{ get "$.pusher.name" }}[/title]
+{{- range $i, $c := limit 2 .commits }}
+{{ add $i 1 }}. {{ shortSHA $c.id }} {{ firstLine $c.message }}
+{{- end }}
+{{ if gt (len .commits) 2 }}…and {{ len .commits }} in total{{ end }}
+Authors: {{ join ", " (get "$.commits[*].author.name") }}
+Missing: [{{ .nope }}]{{ get "$.nope" | default "n/a" }}[/info]`
+	tmpl, err := ParseTemplate(src)
+	if err != nil {
+		t.Fatalf("ParseTemplate: %v", err)
+	}
+	got, err := tmpl.Render(header, body)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	for _, want := range []string{
+		"[info][title]push to",
+		"1. ",
+		"Authors: ",
+		"Missing: []n/a[/info]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<no value>") {
+		t.Errorf("expected missing keys to render empty, got\n%s", got)
+	}
+}
+
+func TestTemplateEscapesPayload(t *testing.T) {
+	tmpl, err := ParseTemplate(`[info]{{ .text }}|{{ raw .text }}|{{ if eq .count 3 }}three{{ end }}|{{ date "2006-01-02" .at }}[/info]`)
+	if err != nil {
+		t.Fatalf("ParseTemplate: %v", err)
+	}
+	got, err := tmpl.Render(http.Header{}, []byte(`{"text":"[toall] hi","count":3,"at":1760000000}`))
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	want := "[info][\u200btoall] hi|[toall] hi|three|2025-10-09[/info]"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	if _, err := ParseTemplate(`{{ if .a }}`); err == nil {
+		t.Fatal("expected an unclosed action to fail")
+	}
+	if _, err := ParseTemplate(`{{ exec "rm" }}`); err == nil {
+		t.Fatal("expected an unknown function to fail")
+	}
+
+	tmpl, _ := ParseTemplate(`{{ get "$.a[" }}`)
+	if _, err := tmpl.Render(http.Header{}, []byte(`{}`)); err == nil {
+		t.Fatal("expected an invalid path to fail at render time")
+	}
+	if _, err := tmpl.Render(http.Header{}, []byte(`not json`)); err == nil {
+		t.Fatal("expected a non-JSON payload to fail")
+	}
+
+	empty, _ := ParseTemplate(`{{ if eq .action "closed" }}closed{{ end }}`)
+	if got, err := empty.Render(http.Header{}, []byte(`{"action":"opened"}`)); err != nil || got != "" {
+		t.Fatalf("expected an empty render, got %q, %v", got, err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	payload, _ := decodePayload([]byte(`{"a":{"b":[{"c":1},{"c":2}],"d e":"x"}}`))
+	cases := map[string]any{
+		"$.a.b[0].c":  int64(1),
+		"a.b[-1].c":   int64(2),
+		"$.a['d e']":  "x",
+		"$.a.b[5].c":  nil,
+		"$.missing.x": nil,
+	}
+	for path, want := range cases {
+		got, err := Lookup(payload, path)
+		if err != nil || got != want {
+			t.Errorf("%s: got %v (%v), want %v", path, got, err, want)
+		}
+	}
+
+	all, _ := Lookup(payload, "$.a.b[*].c")
+	if list, ok := all.([]any); !ok || len(list) != 2 || list[1] != int64(2) {
+		t.Fatalf("unexpected wildcard result: %#v", all)
+	}
+}
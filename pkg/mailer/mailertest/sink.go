@@ -0,0 +1,137 @@
+// Package mailertest provides a local SMTP sink for tests that send email.
+package mailertest
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message is one email accepted by the sink.
+type Message struct {
+	From string
+	To   []string
+	Data string // raw RFC 5322 message
+}
+
+// Sink is a minimal SMTP server that accepts every message without auth or TLS.
+type Sink struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// NewSink starts a sink on a random local port.
+func NewSink() (*Sink, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Sink{listener: l}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+func (s *Sink) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+func (s *Sink) Port() int {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// Messages returns a copy of every message received so far.
+func (s *Sink) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+func (s *Sink) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Sink) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Sink) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost SMTP sink")
+	var current Message
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			current = Message{From: trimAddress(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			current.To = append(current.To, trimAddress(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" || l == ".\n" {
+					break
+				}
+				// Undo dot-stuffing
+				l = strings.TrimPrefix(l, ".")
+				data.WriteString(l)
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 OK: queued")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func trimAddress(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, " "); i >= 0 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "<>")
+}
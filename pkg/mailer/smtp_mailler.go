@@ -2,12 +2,20 @@ package mailer
 
 import (
 	"errors"
+	"io"
 
 	"gopkg.in/gomail.v2"
 )
 
 type EmailSender interface {
-	Send(to []string, subject, body string) error
+	Send(to []string, subject, plainText, html string, attachments ...Attachment) error
+}
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
 }
 
 type GomailSenderConfig struct {
@@ -22,6 +30,8 @@ type GomailSender struct {
 	config GomailSenderConfig
 }
 
+var _ EmailSender = (*GomailSender)(nil)
+
 func NewGomailSender(config GomailSenderConfig) *GomailSender {
 	return &GomailSender{config: config}
 }
@@ -30,11 +40,13 @@ func NewGomailSender(config GomailSenderConfig) *GomailSender {
 // Parameters:
 //   - to: slice of recipient email addresses
 //   - subject: email subject line
-//   - body: html email body content
+//   - plainText: plain text email body content
+//   - html: html email body content
+//   - attachments: optional files attached to the email
 //
 // Returns:
 //   - error if sending fails, nil on success
-func (s *GomailSender) Send(to []string, subject, plainText, html string) error {
+func (s *GomailSender) Send(to []string, subject, plainText, html string, attachments ...Attachment) error {
 
 	// Validate inputs
 	if len(to) == 0 {
@@ -52,11 +64,28 @@ func (s *GomailSender) Send(to []string, subject, plainText, html string) error
 	m.SetHeader("To", to...)
 	m.SetHeader("Subject", subject)
 	// Add plain text and HTML alternatives
-	if plainText != "" {
+	switch {
+	case plainText != "" && html != "":
 		m.SetBody("text/plain", plainText)
-	}
-	if html != "" {
 		m.AddAlternative("text/html", html)
+	case plainText != "":
+		m.SetBody("text/plain", plainText)
+	default:
+		m.SetBody("text/html", html)
+	}
+
+	for _, a := range attachments {
+		data := a.Data
+		settings := []gomail.FileSetting{
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(data)
+				return err
+			}),
+		}
+		if a.ContentType != "" {
+			settings = append(settings, gomail.SetHeader(map[string][]string{"Content-Type": {a.ContentType}}))
+		}
+		m.Attach(a.Filename, settings...)
 	}
 
 	// Create a new dialer and send the email
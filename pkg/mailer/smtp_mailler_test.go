@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/mailer/mailertest"
+)
+
+func TestGomailSenderDeliversMultipartWithAttachment(t *testing.T) {
+	sink, err := mailertest.NewSink()
+	if err != nil {
+		t.Fatalf("failed to start SMTP sink: %v", err)
+	}
+	defer sink.Close()
+
+	sender := NewGomailSender(GomailSenderConfig{From: "bot@example.com", Host: sink.Host(), Port: sink.Port()})
+	err = sender.Send([]string{"dev@example.com", "ops@example.com"}, "Report", "plain body", "<p>html body</p>", Attachment{
+		Filename:    "report.csv",
+		ContentType: "text/csv",
+		Data:        []byte("cve_id,severity\nCVE-2024-0001,HIGH\n"),
+	})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	messages := sink.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	msg := messages[0]
+	if msg.From != "bot@example.com" {
+		t.Fatalf("unexpected sender: %s", msg.From)
+	}
+	if len(msg.To) != 2 {
+		t.Fatalf("expected 2 recipients, got %v", msg.To)
+	}
+	for _, want := range []string{"Subject: Report", "text/plain", "text/html", `filename="report.csv"`, "text/csv"} {
+		if !strings.Contains(msg.Data, want) {
+			t.Fatalf("message missing %q:\n%s", want, msg.Data)
+		}
+	}
+}
+
+func TestGomailSenderRejectsEmptyContent(t *testing.T) {
+	sender := NewGomailSender(GomailSenderConfig{From: "bot@example.com", Host: "127.0.0.1", Port: 1})
+	if err := sender.Send(nil, "Report", "body", ""); err == nil {
+		t.Fatal("expected error for empty recipients")
+	}
+	if err := sender.Send([]string{"dev@example.com"}, "Report", "", ""); err == nil {
+		t.Fatal("expected error for empty body")
+	}
+}
+
+func TestRenderTemplatesEscapeHTML(t *testing.T) {
+	data := map[string]any{
+		"Date":       "2026-01-10",
+		"Total":      1,
+		"Severities": []map[string]any{{"Name": "HIGH", "Count": 1}},
+		"Items":      []map[string]any{{"ID": "CVE-2026-0001", "Severity": "HIGH", "BaseScore": 8.1, "Description": "<script>x</script>"}},
+	}
+
+	html, err := RenderHTML("cve_digest_template.html", data)
+	if err != nil {
+		t.Fatalf("RenderHTML returned error: %v", err)
+	}
+	if strings.Contains(html, "<script>x</script>") {
+		t.Fatal("expected description to be escaped in HTML")
+	}
+	if !strings.Contains(html, `class="badge high"`) {
+		t.Fatal("expected severity badge class")
+	}
+
+	text, err := RenderText("cve_digest_template.txt", data)
+	if err != nil {
+		t.Fatalf("RenderText returned error: %v", err)
+	}
+	if !strings.Contains(text, "CVE-2026-0001 [HIGH 8.1]") {
+		t.Fatalf("unexpected text body:\n%s", text)
+	}
+}
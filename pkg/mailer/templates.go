@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// RenderHTML executes an embedded HTML template, escaping data for HTML.
+func RenderHTML(name string, data any) (string, error) {
+	tmpl, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap{"lower": strings.ToLower}).ParseFS(templateFS, "templates/"+name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderText executes an embedded plain-text template.
+func RenderText(name string, data any) (string, error) {
+	tmpl, err := texttemplate.New(name).Funcs(texttemplate.FuncMap{"lower": strings.ToLower}).ParseFS(templateFS, "templates/"+name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
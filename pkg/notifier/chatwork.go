@@ -0,0 +1,88 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+)
+
+// ChatworkNotifier posts messages to a Chatwork room.
+type ChatworkNotifier struct {
+	BaseURL string
+	client  *http.Client
+}
+
+func NewChatworkNotifier(client *http.Client) *ChatworkNotifier {
+	return &ChatworkNotifier{BaseURL: chatwork.DefaultBaseURL, client: client}
+}
+
+func (n *ChatworkNotifier) Send(target Target, msg Message) error {
+	if target.RoomID == "" || target.Token == "" {
+		return fmt.Errorf("chatwork room and token are required")
+	}
+
+	parts := RenderChatworkParts(msg)
+	for i, body := range parts {
+		if err := n.post(target, body, msg.Priority); err != nil {
+			if len(parts) > 1 {
+				return fmt.Errorf("part %d/%d: %w", i+1, len(parts), err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ChatworkNotifier) post(target Target, body string, priority chatwork.Priority) error {
+	client := chatwork.NewClient(target.Token, chatwork.WithBaseURL(n.BaseURL), chatwork.WithHTTPClient(n.client))
+	_, err := client.PostMessageTo(chatwork.WithPriority(context.Background(), priority), target.RoomID, body)
+	return err
+}
+
+// RenderChatwork renders the message with Chatwork [info]/[title]/[hr] markup.
+func RenderChatwork(m Message) string {
+	return ChatworkBuilder(m).String()
+}
+
+// RenderChatworkParts renders the message as ordered bodies that each fit
+// under the Chatwork body limit.
+func RenderChatworkParts(m Message) []string {
+	return ChatworkBuilder(m).Split(chatwork.MaxMessageLength)
+}
+
+// ChatworkBuilder lays the message out in an [info] box. Structured content is
+// escaped; plain messages are kept verbatim so reminders can use mentions.
+func ChatworkBuilder(m Message) *chatwork.Builder {
+	b := chatwork.NewBuilder()
+	if m.IsPlain() {
+		return b.Raw(m.Text)
+	}
+
+	return b.Info(titleWithEmoji(m), func(info *chatwork.Builder) {
+		if m.Text != "" {
+			info.Text(m.Text)
+		}
+		for _, f := range m.Fields {
+			info.Textf("%s: %s", f.Name, f.Value)
+		}
+		for _, s := range m.Sections {
+			info.Group(func(section *chatwork.Builder) {
+				section.HR()
+				if s.Title != "" {
+					section.Text(s.Title)
+				}
+				for _, line := range s.Lines {
+					section.Text("- " + line)
+				}
+				if s.URL != "" {
+					section.Text(s.URL)
+				}
+			})
+		}
+		if m.Footer != "" {
+			info.HR().Text(m.Footer)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package notifier
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Discord embed limits.
+const (
+	discordMaxFields      = 25
+	discordMaxDescription = 4096
+	discordMaxFieldValue  = 1024
+)
+
+// DiscordNotifier posts to a Discord channel webhook.
+type DiscordNotifier struct {
+	client *http.Client
+}
+
+func NewDiscordNotifier(client *http.Client) *DiscordNotifier {
+	return &DiscordNotifier{client: client}
+}
+
+func (n *DiscordNotifier) Send(target Target, msg Message) error {
+	return postJSON(n.client, target.WebhookURL, RenderDiscord(msg), nil)
+}
+
+// RenderDiscord renders the message as a Discord webhook payload with one embed.
+func RenderDiscord(m Message) map[string]any {
+	if m.IsPlain() {
+		return map[string]any{"content": limit(m.Text, 2000)}
+	}
+
+	embed := map[string]any{
+		"color": severityColor(m.Severity),
+	}
+	if m.Title != "" {
+		embed["title"] = limit(titleWithEmoji(m), 256)
+	}
+	if m.Text != "" {
+		embed["description"] = limit(m.Text, discordMaxDescription)
+	}
+
+	var fields []map[string]any
+	for _, f := range m.Fields {
+		fields = append(fields, map[string]any{"name": limit(f.Name, 256), "value": limit(f.Value, discordMaxFieldValue), "inline": true})
+	}
+	for _, s := range m.Sections {
+		var lines []string
+		for _, line := range s.Lines {
+			lines = append(lines, "• "+line)
+		}
+		if s.URL != "" {
+			lines = append(lines, s.URL)
+		}
+		name := s.Title
+		if name == "" {
+			name = "​"
+		}
+		value := strings.Join(lines, "\n")
+		if value == "" {
+			value = "​"
+		}
+		fields = append(fields, map[string]any{"name": limit(name, 256), "value": limit(value, discordMaxFieldValue), "inline": false})
+	}
+	if len(fields) > discordMaxFields {
+		fields = fields[:discordMaxFields]
+	}
+	if len(fields) > 0 {
+		embed["fields"] = fields
+	}
+	if m.Footer != "" {
+		embed["footer"] = map[string]any{"text": limit(m.Footer, 2048)}
+	}
+
+	return map[string]any{"embeds": []map[string]any{embed}}
+}
+
+// limit truncates s to max runes.
+func limit(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/mailer"
+)
+
+// EmailNotifier sends the message as a multipart text/HTML email.
+type EmailNotifier struct {
+	mail mailer.EmailSender
+}
+
+func NewEmailNotifier(mail mailer.EmailSender) *EmailNotifier {
+	return &EmailNotifier{mail: mail}
+}
+
+func (n *EmailNotifier) Send(target Target, msg Message) error {
+	if n.mail == nil {
+		return fmt.Errorf("email delivery is not configured")
+	}
+	if len(target.Recipients) == 0 {
+		return fmt.Errorf("email recipients are required")
+	}
+
+	subject, text, html, err := RenderEmail(msg)
+	if err != nil {
+		return err
+	}
+	return n.mail.Send(target.Recipients, subject, text, html)
+}
+
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html><body style="font-family:Arial,sans-serif;color:#1d1c1d">
+{{if .Title}}<h2 style="border-left:4px solid {{.Color}};padding-left:8px">{{.Title}}</h2>{{end}}
+{{if .Text}}<p style="white-space:pre-line">{{.Text}}</p>{{end}}
+{{if .Fields}}<table cellpadding="4" style="border-collapse:collapse">
+{{range .Fields}}<tr><th align="left">{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>{{end}}
+{{range .Sections}}<hr>
+{{if .Title}}<h4>{{if .URL}}<a href="{{.URL}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h4>{{end}}
+{{if .Lines}}<ul>{{range .Lines}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if and .URL (not .Title)}}<p><a href="{{.URL}}">{{.URL}}</a></p>{{end}}
+{{end}}
+{{if .Footer}}<hr><p style="color:#616061;font-size:12px">{{.Footer}}</p>{{end}}
+</body></html>`))
+
+// RenderEmail returns the subject, plain-text and HTML bodies of the message.
+func RenderEmail(m Message) (subject, text, html string, err error) {
+	subject = titleWithEmoji(m)
+	if subject == "" {
+		subject = "Notification"
+	}
+	text = plainText(m)
+
+	var buf bytes.Buffer
+	if err := emailTemplate.Execute(&buf, struct {
+		Message
+		Color string
+	}{m, fmt.Sprintf("#%06X", severityColor(m.Severity))}); err != nil {
+		return "", "", "", fmt.Errorf("failed to render email: %w", err)
+	}
+	return subject, text, buf.String(), nil
+}
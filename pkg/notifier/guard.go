@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// blockedNets are ranges that are public-looking but must never be reached
+// from a user-supplied webhook URL (carrier-grade NAT, benchmarking, 6to4 relay, NAT64).
+var blockedNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{"100.64.0.0/10", "198.18.0.0/15", "192.0.0.0/24", "64:ff9b::/96"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// IsPublicIP reports whether ip is a routable public address. Loopback,
+// private, link-local (including the 169.254.169.254 metadata endpoint),
+// unspecified and multicast addresses are not.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckPublicHost resolves host with lookup and fails unless every address is public.
+func CheckPublicHost(host string, lookup func(host string) ([]net.IP, error)) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return fmt.Errorf("%s is not a public address", host)
+		}
+		return nil
+	}
+	ips, err := lookup(host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	if len(ips) == 0 {
+		return fmt.Errorf("%s does not resolve to any address", host)
+	}
+	for _, ip := range ips {
+		if !IsPublicIP(ip) {
+			return fmt.Errorf("%s resolves to %s, which is not a public address", host, ip)
+		}
+	}
+	return nil
+}
+
+// NewPublicClient returns an HTTP client that refuses to connect to
+// non-public addresses. The check runs on the address actually dialled, so a
+// host that resolved to a public IP at validation time can't be re-pointed
+// at an internal one later. Proxies from the environment are ignored for the
+// same reason.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("refusing to connect to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// Redirects are dialled through the same transport, so they get the same check.
+	}
+}
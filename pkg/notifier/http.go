@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// postJSON sends body as JSON and fails on any non-2xx status.
+func postJSON(client *http.Client, url string, body any, headers map[string]string) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return postRaw(client, url, payload, "application/json", headers)
+}
+
+func postRaw(client *http.Client, url string, payload []byte, contentType string, headers map[string]string) error {
+	if url == "" {
+		return fmt.Errorf("webhook URL is required")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// StatusError is returned when the receiver answers with a non-2xx status.
+// Body holds the start of the response for server-side logs; it is left out
+// of Error so that whatever the receiver sent never reaches the API caller.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received status %d", e.StatusCode)
+}
@@ -0,0 +1,208 @@
+// Package notifier delivers structured messages to chat and email channels.
+// A Message is rendered into each channel's own format (Chatwork markup,
+// Slack blocks, Discord embeds, Teams adaptive cards, JSON or email) at send time.
+package notifier
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/chatwork"
+	"github.com/vfa-khuongdv/golang-cms/pkg/mailer"
+)
+
+// Channel types supported by the default registry.
+const (
+	TypeChatwork = "chatwork"
+	TypeSlack    = "slack"
+	TypeDiscord  = "discord"
+	TypeTeams    = "teams"
+	TypeWebhook  = "webhook"
+	TypeEmail    = "email"
+)
+
+// Types lists every supported channel type.
+var Types = []string{TypeChatwork, TypeSlack, TypeDiscord, TypeTeams, TypeWebhook, TypeEmail}
+
+// Severity drives colours and icons in rendered messages.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeveritySuccess  Severity = "success"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Message is a channel-agnostic notification.
+// A message with only Text is sent verbatim, which keeps user-authored
+// reminder bodies untouched.
+type Message struct {
+	Title    string    `json:"title,omitempty"`
+	Text     string    `json:"text,omitempty"`
+	Severity Severity  `json:"severity,omitempty"`
+	Fields   []Field   `json:"fields,omitempty"`
+	Sections []Section `json:"sections,omitempty"`
+	Footer   string    `json:"footer,omitempty"`
+
+	// Priority is the outbound queue lane for channels that throttle per token (Chatwork).
+	Priority chatwork.Priority `json:"-"`
+}
+
+// Field is a short key/value fact shown in the message summary.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Section is a titled block of lines, e.g. one vulnerable package.
+type Section struct {
+	Title string   `json:"title,omitempty"`
+	Lines []string `json:"lines,omitempty"`
+	URL   string   `json:"url,omitempty"`
+}
+
+// IsPlain reports whether the message is free text without structure.
+func (m Message) IsPlain() bool {
+	return m.Title == "" && len(m.Fields) == 0 && len(m.Sections) == 0 && m.Footer == ""
+}
+
+// Target holds the delivery settings of one channel.
+type Target struct {
+	Type       string
+	RoomID     string   // chatwork
+	Token      string   // chatwork API token
+	WebhookURL string   // slack, discord, teams, webhook
+	Secret     string   // webhook HMAC secret
+	Recipients []string // email
+}
+
+// Notifier sends a rendered message to one kind of channel.
+type Notifier interface {
+	Send(target Target, msg Message) error
+}
+
+// Registry dispatches messages to the notifier registered for the target type.
+type Registry struct {
+	notifiers map[string]Notifier
+}
+
+func NewRegistry() *Registry {
+	return &Registry{notifiers: make(map[string]Notifier)}
+}
+
+// NewDefaultRegistry registers every built-in channel. mail may be nil,
+// in which case email targets fail with an error.
+func NewDefaultRegistry(mail mailer.EmailSender) *Registry {
+	client := &http.Client{Timeout: 10 * time.Second}
+	// Webhook URLs come from users, so those channels may only reach public addresses.
+	public := NewPublicClient(10 * time.Second)
+
+	r := NewRegistry()
+	r.Register(TypeChatwork, NewChatworkNotifier(client))
+	r.Register(TypeSlack, NewSlackNotifier(public))
+	r.Register(TypeDiscord, NewDiscordNotifier(public))
+	r.Register(TypeTeams, NewTeamsNotifier(public))
+	r.Register(TypeWebhook, NewWebhookNotifier(public))
+	r.Register(TypeEmail, NewEmailNotifier(mail))
+	return r
+}
+
+func (r *Registry) Register(channelType string, n Notifier) {
+	r.notifiers[channelType] = n
+}
+
+func (r *Registry) Send(target Target, msg Message) error {
+	n, ok := r.notifiers[target.Type]
+	if !ok {
+		return fmt.Errorf("unsupported channel type: %s", target.Type)
+	}
+	return n.Send(target, msg)
+}
+
+// IsValidType reports whether channelType is a built-in channel type.
+func IsValidType(channelType string) bool {
+	for _, t := range Types {
+		if t == channelType {
+			return true
+		}
+	}
+	return false
+}
+
+// plainText renders the message as unformatted text, used by text-only channels.
+func plainText(m Message) string {
+	if m.IsPlain() {
+		return m.Text
+	}
+
+	var sb strings.Builder
+	if m.Title != "" {
+		sb.WriteString(m.Title)
+		sb.WriteString("\n\n")
+	}
+	if m.Text != "" {
+		sb.WriteString(m.Text)
+		sb.WriteString("\n")
+	}
+	for _, f := range m.Fields {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", f.Name, f.Value))
+	}
+	for _, s := range m.Sections {
+		sb.WriteString("\n")
+		if s.Title != "" {
+			sb.WriteString(s.Title)
+			sb.WriteString("\n")
+		}
+		for _, line := range s.Lines {
+			sb.WriteString("- ")
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+		if s.URL != "" {
+			sb.WriteString(s.URL)
+			sb.WriteString("\n")
+		}
+	}
+	if m.Footer != "" {
+		sb.WriteString("\n")
+		sb.WriteString(m.Footer)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func severityEmoji(s Severity) string {
+	switch s {
+	case SeveritySuccess:
+		return "✅"
+	case SeverityWarning:
+		return "⚠️"
+	case SeverityCritical:
+		return "🚨"
+	default:
+		return "ℹ️"
+	}
+}
+
+// severityColor returns the RGB colour of a severity.
+func severityColor(s Severity) int {
+	switch s {
+	case SeveritySuccess:
+		return 0x2EB67D
+	case SeverityWarning:
+		return 0xECB22E
+	case SeverityCritical:
+		return 0xE01E5A
+	default:
+		return 0x1D9BD1
+	}
+}
+
+func titleWithEmoji(m Message) string {
+	if m.Title == "" || m.Severity == "" {
+		return m.Title
+	}
+	return severityEmoji(m.Severity) + " " + m.Title
+}
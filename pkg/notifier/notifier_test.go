@@ -0,0 +1,180 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var scanMessage = Message{
+	Title:    "CVE Scan Result",
+	Text:     "2 Vulns | C:1 H:1 M:0 L:0",
+	Severity: SeverityCritical,
+	Fields:   []Field{{Name: "Config", Value: "backend"}},
+	Sections: []Section{{Title: "[1] lodash@4.17.20 (2 vuln)", Lines: []string{"https://osv.dev/GHSA-1"}}},
+	Footer:   "Bot Dashboard Hub",
+}
+
+func TestRenderChatwork(t *testing.T) {
+	got := RenderChatwork(scanMessage)
+	want := "[info][title]🚨 CVE Scan Result[/title]2 Vulns | C:1 H:1 M:0 L:0\nConfig: backend\n[hr]\n[1] lodash@4.17.20 (2 vuln)\n- https://osv.dev/GHSA-1\n[hr]\nBot Dashboard Hub[/info]"
+	if got != want {
+		t.Fatalf("unexpected chatwork body:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := RenderChatwork(Message{Text: "[To:123] stand-up time"}); got != "[To:123] stand-up time" {
+		t.Fatalf("plain message should be sent verbatim, got %q", got)
+	}
+}
+
+func TestRenderSlackEscapesAndFallsBack(t *testing.T) {
+	payload := RenderSlack(Message{Title: "a <b>", Text: "x & y"})
+	blocks, ok := payload["blocks"].([]map[string]any)
+	if !ok || len(blocks) != 2 {
+		t.Fatalf("expected header and section blocks, got %#v", payload["blocks"])
+	}
+	section := blocks[1]["text"].(map[string]any)["text"]
+	if section != "x &amp; y" {
+		t.Fatalf("expected escaped text, got %q", section)
+	}
+	if payload["text"] == "" {
+		t.Fatalf("expected fallback text")
+	}
+}
+
+func TestRenderDiscordAndTeams(t *testing.T) {
+	discord := RenderDiscord(scanMessage)
+	embed := discord["embeds"].([]map[string]any)[0]
+	if embed["color"] != severityColor(SeverityCritical) {
+		t.Fatalf("unexpected embed color %v", embed["color"])
+	}
+	if fields := embed["fields"].([]map[string]any); len(fields) != 2 {
+		t.Fatalf("expected 2 embed fields, got %d", len(fields))
+	}
+
+	teams := RenderTeams(scanMessage)
+	raw, _ := json.Marshal(teams)
+	if !strings.Contains(string(raw), "application/vnd.microsoft.card.adaptive") || !strings.Contains(string(raw), "FactSet") {
+		t.Fatalf("unexpected teams payload: %s", raw)
+	}
+}
+
+func TestRenderEmail(t *testing.T) {
+	subject, text, html, err := RenderEmail(Message{Title: "Report <1>", Text: "line"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if subject != "Report <1>" || !strings.Contains(text, "line") {
+		t.Fatalf("unexpected subject/text: %q %q", subject, text)
+	}
+	if !strings.Contains(html, "Report &lt;1&gt;") {
+		t.Fatalf("expected escaped HTML title, got %s", html)
+	}
+}
+
+func TestWebhookNotifierSignsPayload(t *testing.T) {
+	var gotSig, gotTs string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get(HeaderSignature)
+		gotTs = r.Header.Get(HeaderTimestamp)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.Client())
+	n.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	if err := n.Send(Target{Type: TypeWebhook, WebhookURL: server.URL, Secret: "s3cret"}, scanMessage); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotTs != "1700000000" {
+		t.Fatalf("unexpected timestamp %q", gotTs)
+	}
+	if want := "sha256=" + Sign("s3cret", gotTs, gotBody); gotSig != want {
+		t.Fatalf("signature mismatch: got %q want %q", gotSig, want)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil || payload.Title != scanMessage.Title {
+		t.Fatalf("unexpected payload %s (%v)", gotBody, err)
+	}
+}
+
+func TestRegistryRejectsUnknownType(t *testing.T) {
+	r := NewDefaultRegistry(nil)
+	if err := r.Send(Target{Type: "pager"}, Message{Text: "x"}); err == nil {
+		t.Fatalf("expected error for unknown channel type")
+	}
+	if err := r.Send(Target{Type: TypeEmail, Recipients: []string{"a@example.com"}}, Message{Text: "x"}); err == nil {
+		t.Fatalf("expected error when email is not configured")
+	}
+}
+
+func TestSlackNotifierReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewSlackNotifier(server.Client()).Send(Target{WebhookURL: server.URL}, Message{Text: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected 403 error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "invalid_token") {
+		t.Fatalf("response body leaked into the error: %v", err)
+	}
+}
+
+func TestCheckPublicHost(t *testing.T) {
+	lookup := func(addrs ...string) func(string) ([]net.IP, error) {
+		return func(string) ([]net.IP, error) {
+			var ips []net.IP
+			for _, a := range addrs {
+				ips = append(ips, net.ParseIP(a))
+			}
+			return ips, nil
+		}
+	}
+	tests := []struct {
+		name   string
+		host   string
+		lookup func(string) ([]net.IP, error)
+		ok     bool
+	}{
+		{"public name", "hooks.example.com", lookup("93.184.216.34"), true},
+		{"public literal", "93.184.216.34", nil, true},
+		{"loopback literal", "127.0.0.1", nil, false},
+		{"metadata literal", "169.254.169.254", nil, false},
+		{"ipv6 loopback", "::1", nil, false},
+		{"private name", "intranet.example.com", lookup("10.0.0.5"), false},
+		{"mixed answers", "rebind.example.com", lookup("93.184.216.34", "192.168.1.1"), false},
+		{"carrier nat", "cgnat.example.com", lookup("100.64.0.1"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckPublicHost(tt.host, tt.lookup); (err == nil) != tt.ok {
+				t.Fatalf("CheckPublicHost(%q) = %v, want ok=%v", tt.host, err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPublicClientRefusesLoopback(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(NewPublicClient(time.Second)).Send(Target{WebhookURL: server.URL}, Message{Text: "hi"})
+	if err == nil || hit {
+		t.Fatalf("expected the public client to refuse %s, got err=%v hit=%v", server.URL, err, hit)
+	}
+}
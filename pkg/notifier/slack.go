@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const slackMaxBlocks = 50
+
+// SlackNotifier posts to a Slack incoming webhook.
+type SlackNotifier struct {
+	client *http.Client
+}
+
+func NewSlackNotifier(client *http.Client) *SlackNotifier {
+	return &SlackNotifier{client: client}
+}
+
+func (n *SlackNotifier) Send(target Target, msg Message) error {
+	return postJSON(n.client, target.WebhookURL, RenderSlack(msg), nil)
+}
+
+// RenderSlack renders the message as a Slack Block Kit payload.
+func RenderSlack(m Message) map[string]any {
+	fallback := plainText(m)
+	if m.IsPlain() {
+		return map[string]any{"text": fallback}
+	}
+
+	var blocks []map[string]any
+	if m.Title != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": titleWithEmoji(m), "emoji": true},
+		})
+	}
+	if m.Text != "" {
+		blocks = append(blocks, slackSection(slackEscape(m.Text)))
+	}
+	if len(m.Fields) > 0 {
+		fields := make([]map[string]any, 0, len(m.Fields))
+		for _, f := range m.Fields {
+			fields = append(fields, map[string]any{
+				"type": "mrkdwn",
+				"text": fmt.Sprintf("*%s*\n%s", slackEscape(f.Name), slackEscape(f.Value)),
+			})
+		}
+		// Slack allows at most 10 fields per section block
+		for i := 0; i < len(fields); i += 10 {
+			end := i + 10
+			if end > len(fields) {
+				end = len(fields)
+			}
+			blocks = append(blocks, map[string]any{"type": "section", "fields": fields[i:end]})
+		}
+	}
+	for _, s := range m.Sections {
+		blocks = append(blocks, map[string]any{"type": "divider"})
+		var sb strings.Builder
+		if s.Title != "" {
+			if s.URL != "" {
+				sb.WriteString(fmt.Sprintf("*<%s|%s>*", s.URL, slackEscape(s.Title)))
+			} else {
+				sb.WriteString(fmt.Sprintf("*%s*", slackEscape(s.Title)))
+			}
+		} else if s.URL != "" {
+			sb.WriteString(s.URL)
+		}
+		for _, line := range s.Lines {
+			sb.WriteString("\n• ")
+			sb.WriteString(slackEscape(line))
+		}
+		blocks = append(blocks, slackSection(sb.String()))
+	}
+	if m.Footer != "" {
+		blocks = append(blocks, map[string]any{
+			"type":     "context",
+			"elements": []map[string]any{{"type": "mrkdwn", "text": slackEscape(m.Footer)}},
+		})
+	}
+
+	// Slack rejects payloads with more than 50 blocks
+	if len(blocks) > slackMaxBlocks {
+		dropped := len(blocks) - slackMaxBlocks + 1
+		blocks = append(blocks[:slackMaxBlocks-1], map[string]any{
+			"type":     "context",
+			"elements": []map[string]any{{"type": "mrkdwn", "text": fmt.Sprintf("… %d more blocks, see the full report", dropped)}},
+		})
+	}
+
+	return map[string]any{"text": fallback, "blocks": blocks}
+}
+
+func slackSection(text string) map[string]any {
+	return map[string]any{
+		"type": "section",
+		"text": map[string]any{"type": "mrkdwn", "text": text},
+	}
+}
+
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,86 @@
+package notifier
+
+import (
+	"net/http"
+)
+
+// TeamsNotifier posts an adaptive card to a Microsoft Teams workflow or incoming webhook.
+type TeamsNotifier struct {
+	client *http.Client
+}
+
+func NewTeamsNotifier(client *http.Client) *TeamsNotifier {
+	return &TeamsNotifier{client: client}
+}
+
+func (n *TeamsNotifier) Send(target Target, msg Message) error {
+	return postJSON(n.client, target.WebhookURL, RenderTeams(msg), nil)
+}
+
+// RenderTeams renders the message as a Teams message carrying an adaptive card.
+func RenderTeams(m Message) map[string]any {
+	var body []map[string]any
+
+	if m.Title != "" {
+		body = append(body, map[string]any{
+			"type":   "TextBlock",
+			"text":   titleWithEmoji(m),
+			"size":   "Large",
+			"weight": "Bolder",
+			"color":  teamsColor(m.Severity),
+			"wrap":   true,
+		})
+	}
+	if m.Text != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": m.Text, "wrap": true})
+	}
+	if len(m.Fields) > 0 {
+		facts := make([]map[string]any, 0, len(m.Fields))
+		for _, f := range m.Fields {
+			facts = append(facts, map[string]any{"title": f.Name, "value": f.Value})
+		}
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+	for _, s := range m.Sections {
+		items := []map[string]any{}
+		if s.Title != "" {
+			items = append(items, map[string]any{"type": "TextBlock", "text": s.Title, "weight": "Bolder", "wrap": true})
+		}
+		for _, line := range s.Lines {
+			items = append(items, map[string]any{"type": "TextBlock", "text": "- " + line, "wrap": true, "spacing": "None"})
+		}
+		if s.URL != "" {
+			items = append(items, map[string]any{"type": "TextBlock", "text": "[" + s.URL + "](" + s.URL + ")", "wrap": true, "spacing": "None"})
+		}
+		body = append(body, map[string]any{"type": "Container", "separator": true, "items": items})
+	}
+	if m.Footer != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": m.Footer, "size": "Small", "isSubtle": true, "wrap": true})
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+func teamsColor(s Severity) string {
+	switch s {
+	case SeveritySuccess:
+		return "Good"
+	case SeverityWarning:
+		return "Warning"
+	case SeverityCritical:
+		return "Attention"
+	default:
+		return "Default"
+	}
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers set on generic webhook deliveries.
+const (
+	HeaderSignature = "X-Signature-256"
+	HeaderTimestamp = "X-Signature-Timestamp"
+)
+
+// WebhookNotifier posts the message as JSON to any HTTP endpoint.
+// When the target has a secret, the request is signed with HMAC-SHA256 over
+// "<timestamp>.<body>" and sent as "sha256=<hex>" in X-Signature-256.
+type WebhookNotifier struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewWebhookNotifier(client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{client: client, now: time.Now}
+}
+
+// WebhookPayload is the JSON body of a generic webhook delivery.
+type WebhookPayload struct {
+	Message
+	Rendered string    `json:"rendered"`
+	SentAt   time.Time `json:"sentAt"`
+}
+
+func (n *WebhookNotifier) Send(target Target, msg Message) error {
+	now := n.now().UTC()
+	payload, err := json.Marshal(WebhookPayload{Message: msg, Rendered: plainText(msg), SentAt: now})
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	headers := map[string]string{}
+	if target.Secret != "" {
+		ts := strconv.FormatInt(now.Unix(), 10)
+		headers[HeaderTimestamp] = ts
+		headers[HeaderSignature] = "sha256=" + Sign(target.Secret, ts, payload)
+	}
+	return postRaw(n.client, target.WebhookURL, payload, "application/json", headers)
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" so receivers can verify deliveries.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,107 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+// keySet holds the provider's signing keys by kid.
+type keySet struct {
+	byKid map[string]interface{}
+	// only is set when the JWKS has a single key, for tokens without a kid
+	only interface{}
+}
+
+func (s *keySet) find(kid string) (interface{}, bool) {
+	if kid == "" && s.only != nil {
+		return s.only, true
+	}
+	key, ok := s.byKid[kid]
+	return key, ok
+}
+
+func (d *jwksDocument) parse() (*keySet, error) {
+	set := &keySet{byKid: make(map[string]interface{})}
+	for _, k := range d.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("oidc: jwks key %q: %w", k.Kid, err)
+		}
+		if key == nil {
+			continue
+		}
+		set.byKid[k.Kid] = key
+		if len(d.Keys) == 1 {
+			set.only = key
+		}
+	}
+	return set, nil
+}
+
+// publicKey decodes RSA and EC keys; other key types are skipped.
+func (k *jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,362 @@
+// Package oidc signs users in with an OpenID Connect provider using the
+// authorization code flow with PKCE.
+//
+// A Provider discovers the issuer's endpoints on first use, builds the
+// authorization URL for an AuthRequest, exchanges the returned code for
+// tokens and verifies the ID token against the issuer's JWKS.
+//
+// Package oidctest provides a mock provider for tests.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// DefaultScopes are requested when Config.Scopes is empty.
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// Config identifies the client to the provider.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Metadata is the part of the discovery document the flow needs.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider runs the flow against one issuer. It is safe for concurrent use.
+type Provider struct {
+	config     Config
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     *keySet
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(p *Provider) {
+		if httpClient != nil {
+			p.httpClient = httpClient
+		}
+	}
+}
+
+// WithClock sets the time ID tokens are checked against.
+func WithClock(now func() time.Time) Option {
+	return func(p *Provider) {
+		if now != nil {
+			p.now = now
+		}
+	}
+}
+
+// NewProvider doesn't contact the issuer; discovery happens on first use,
+// so an unreachable provider doesn't stop the server from starting.
+func NewProvider(config Config, opts ...Option) *Provider {
+	config.Issuer = strings.TrimRight(config.Issuer, "/")
+	if len(config.Scopes) == 0 {
+		config.Scopes = DefaultScopes
+	}
+	p := &Provider{
+		config:     config,
+		httpClient: defaultHTTPClient,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// AuthRequest holds the secrets of one sign-in attempt. Keep it server-side
+// until the provider redirects back.
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// NewAuthRequest generates a fresh state, nonce and PKCE code verifier.
+func NewAuthRequest() AuthRequest {
+	return AuthRequest{
+		State:        randomString(24),
+		Nonce:        randomString(24),
+		CodeVerifier: randomString(48),
+	}
+}
+
+// CodeChallenge is the S256 PKCE challenge of a code verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL is where to send the user's browser to sign in.
+func (p *Provider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	md, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {req.State},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {CodeChallenge(req.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(md.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return md.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Token is the token endpoint's response.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Exchange trades the authorization code for tokens, proving possession of
+// the code verifier.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	md, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var token Token
+	if err := p.do(req, &token); err != nil {
+		return nil, fmt.Errorf("oidc: token exchange: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("oidc: token response has no id_token")
+	}
+	return &token, nil
+}
+
+// Claims are the verified claims of an ID token.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// Raw holds every claim, for provider-specific ones such as groups.
+	Raw map[string]interface{}
+}
+
+// Strings returns a claim that is a string or a list of strings.
+func (c *Claims) Strings(name string) []string {
+	switch v := c.Raw[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// VerifyIDToken checks the ID token's signature, issuer, audience, expiry
+// and nonce.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	md, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, raw, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(p.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid id token: %w", err)
+	}
+
+	if got, _ := raw["nonce"].(string); got != nonce {
+		return nil, fmt.Errorf("oidc: id token nonce doesn't match")
+	}
+	// With several audiences, the token must have been issued to us
+	if aud, _ := raw.GetAudience(); len(aud) > 1 {
+		if azp, _ := raw["azp"].(string); azp != p.config.ClientID {
+			return nil, fmt.Errorf("oidc: id token was issued to %q", azp)
+		}
+	}
+
+	claims := &Claims{Raw: raw}
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Email, _ = raw["email"].(string)
+	claims.Name, _ = raw["name"].(string)
+	switch v := raw["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("oidc: id token has no subject")
+	}
+	return claims, nil
+}
+
+// Metadata returns the discovery document, fetching it on first use.
+func (p *Provider) Metadata(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	md := p.metadata
+	p.mu.Unlock()
+	if md != nil {
+		return md, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	md = &Metadata{}
+	if err := p.do(req, md); err != nil {
+		return nil, fmt.Errorf("oidc: discovery: %w", err)
+	}
+	if strings.TrimRight(md.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("oidc: discovery returned issuer %q, want %q", md.Issuer, p.config.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: discovery document is missing endpoints")
+	}
+
+	p.mu.Lock()
+	p.metadata = md
+	p.mu.Unlock()
+	return md, nil
+}
+
+// key finds the signing key, refetching the JWKS once when the kid is
+// unknown so key rotation at the provider is picked up.
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	keys := p.keys
+	p.mu.Unlock()
+	if keys != nil {
+		if key, ok := keys.find(kid); ok {
+			return key, nil
+		}
+	}
+
+	md, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, md.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var doc jwksDocument
+	if err := p.do(req, &doc); err != nil {
+		return nil, fmt.Errorf("oidc: jwks: %w", err)
+	}
+	keys, err = doc.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	if key, ok := keys.find(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: no signing key %q", kid)
+}
+
+func (p *Provider) do(req *http.Request, out interface{}) error {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return fmt.Errorf("%s: %s", oauthErr.Error, oauthErr.Description)
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
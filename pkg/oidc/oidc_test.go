@@ -0,0 +1,146 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vfa-khuongdv/golang-cms/pkg/oidc"
+	"github.com/vfa-khuongdv/golang-cms/pkg/oidc/oidctest"
+)
+
+func newTestProvider(t *testing.T, secret string) (*oidc.Provider, *oidctest.Server) {
+	t.Helper()
+	server := oidctest.NewServer("bot-hub", secret)
+	t.Cleanup(server.Close)
+	server.AddUser(oidctest.User{
+		Subject:       "alice",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Name:          "Alice",
+		Claims:        map[string]interface{}{"groups": []string{"eng", "ops"}},
+	})
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:       server.Issuer() + "/",
+		ClientID:     "bot-hub",
+		ClientSecret: secret,
+		RedirectURL:  "https://app.example.com/sso/callback",
+	})
+	return provider, server
+}
+
+func TestAuthCodeFlow(t *testing.T) {
+	for _, secret := range []string{"", "client-secret"} {
+		provider, server := newTestProvider(t, secret)
+		ctx := context.Background()
+
+		req := oidc.NewAuthRequest()
+		authURL, err := provider.AuthCodeURL(ctx, req)
+		if err != nil {
+			t.Fatalf("AuthCodeURL: %v", err)
+		}
+		q, _ := url.ParseQuery(authURL[strings.Index(authURL, "?")+1:])
+		if q.Get("scope") != "openid email profile" || q.Get("code_challenge") != oidc.CodeChallenge(req.CodeVerifier) {
+			t.Fatalf("unexpected authorization URL %s", authURL)
+		}
+
+		code, state, err := server.Authorize(authURL, "alice")
+		if err != nil {
+			t.Fatalf("Authorize: %v", err)
+		}
+		if state != req.State {
+			t.Fatalf("state = %q, want %q", state, req.State)
+		}
+
+		token, err := provider.Exchange(ctx, code, req.CodeVerifier)
+		if err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		claims, err := provider.VerifyIDToken(ctx, token.IDToken, req.Nonce)
+		if err != nil {
+			t.Fatalf("VerifyIDToken: %v", err)
+		}
+		if claims.Subject != "alice" || claims.Email != "alice@example.com" || !claims.EmailVerified || claims.Name != "Alice" {
+			t.Fatalf("unexpected claims %+v", claims)
+		}
+		if groups := claims.Strings("groups"); len(groups) != 2 || groups[1] != "ops" {
+			t.Fatalf("groups = %v", groups)
+		}
+
+		// Codes are single-use
+		if _, err := provider.Exchange(ctx, code, req.CodeVerifier); err == nil {
+			t.Fatal("expected a used code to be refused")
+		}
+	}
+}
+
+func TestExchangeChecksPKCE(t *testing.T) {
+	provider, server := newTestProvider(t, "")
+	ctx := context.Background()
+
+	req := oidc.NewAuthRequest()
+	authURL, _ := provider.AuthCodeURL(ctx, req)
+	code, _, err := server.Authorize(authURL, "alice")
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if _, err := provider.Exchange(ctx, code, oidc.NewAuthRequest().CodeVerifier); err == nil {
+		t.Fatal("expected another request's code verifier to be refused")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider, server := newTestProvider(t, "")
+	ctx := context.Background()
+	user := oidctest.User{Subject: "alice"}
+
+	token, _ := server.SignIDToken(user, "nonce-1")
+	if _, err := provider.VerifyIDToken(ctx, token, "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if _, err := provider.VerifyIDToken(ctx, token, "nonce-2"); err == nil {
+		t.Fatal("expected a nonce mismatch to be refused")
+	}
+
+	// A rotated key is picked up from the JWKS
+	server.RotateKey()
+	token, _ = server.SignIDToken(user, "nonce-1")
+	if _, err := provider.VerifyIDToken(ctx, token, "nonce-1"); err != nil {
+		t.Fatalf("expected the new key to verify, got %v", err)
+	}
+
+	server.Now = func() time.Time { return time.Now().Add(-time.Hour) }
+	token, _ = server.SignIDToken(user, "nonce-1")
+	if _, err := provider.VerifyIDToken(ctx, token, "nonce-1"); err == nil {
+		t.Fatal("expected an expired token to be refused")
+	}
+
+	server.Now = time.Now
+	user.Claims = map[string]interface{}{"aud": "another-client"}
+	token, _ = server.SignIDToken(user, "nonce-1")
+	if _, err := provider.VerifyIDToken(ctx, token, "nonce-1"); err == nil {
+		t.Fatal("expected a token for another client to be refused")
+	}
+
+	user.Claims = map[string]interface{}{"iss": "https://evil.example.com"}
+	token, _ = server.SignIDToken(user, "nonce-1")
+	if _, err := provider.VerifyIDToken(ctx, token, "nonce-1"); err == nil {
+		t.Fatal("expected a token from another issuer to be refused")
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, token[:len(token)-4]+"AAAA", "nonce-1"); err == nil {
+		t.Fatal("expected a tampered token to be refused")
+	}
+}
+
+func TestDiscoveryChecksIssuer(t *testing.T) {
+	server := oidctest.NewServer("bot-hub", "")
+	defer server.Close()
+
+	provider := oidc.NewProvider(oidc.Config{Issuer: server.Issuer() + "/tenant", ClientID: "bot-hub"})
+	if _, err := provider.Metadata(context.Background()); err == nil {
+		t.Fatal("expected discovery under another issuer to fail")
+	}
+}
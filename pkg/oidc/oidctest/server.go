@@ -0,0 +1,298 @@
+// Package oidctest provides a mock OpenID Connect provider for tests.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vfa-khuongdv/golang-cms/pkg/oidc"
+)
+
+// User is an account at the mock provider. Claims are added to its ID tokens.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Claims        map[string]interface{}
+}
+
+type grant struct {
+	user          User
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+}
+
+// Server is a mock provider with discovery, JWKS, authorization and token
+// endpoints. The authorization endpoint signs in the user named by the
+// login_hint parameter without a login page.
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+	// IDTokenTTL is how long issued ID tokens are valid.
+	IDTokenTTL time.Duration
+	// Now is the time ID tokens are issued at.
+	Now func() time.Time
+
+	mu         sync.Mutex
+	key        *rsa.PrivateKey
+	keyVersion int
+	users      map[string]User
+	grants     map[string]grant
+	nextCode   int
+}
+
+// NewServer starts a provider for one client; call Close when done.
+func NewServer(clientID, clientSecret string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: generating key: %v", err))
+	}
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		keyVersion:   1,
+		users:        make(map[string]User),
+		grants:       make(map[string]grant),
+		IDTokenTTL:   5 * time.Minute,
+		Now:          time.Now,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("GET /jwks", s.handleJWKS)
+	mux.HandleFunc("GET /authorize", s.handleAuthorize)
+	mux.HandleFunc("POST /token", s.handleToken)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Issuer is the provider's issuer URL.
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// AddUser registers or replaces an account.
+func (s *Server) AddUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.Subject] = user
+}
+
+// RotateKey replaces the signing key, as providers do from time to time.
+func (s *Server) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: generating key: %v", err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+	s.keyVersion++
+}
+
+func (s *Server) kid() string {
+	return fmt.Sprintf("key-%d", s.keyVersion)
+}
+
+// Authorize follows an authorization URL as the user with the subject and
+// returns the code and state the provider redirects back with.
+func (s *Server) Authorize(authURL, subject string) (code, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	q.Set("login_hint", subject)
+	u.RawQuery = q.Encode()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return "", "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("oidctest: authorize returned %d", resp.StatusCode)
+	}
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	if e := loc.Query().Get("error"); e != "" {
+		return "", "", fmt.Errorf("oidctest: authorize error %s", e)
+	}
+	return loc.Query().Get("code"), loc.Query().Get("state"), nil
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	pub := s.key.PublicKey
+	kid := s.kid()
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": kid,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if q.Get("client_id") != s.ClientID || redirectURI == "" {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+	back, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := url.Values{"state": {q.Get("state")}}
+
+	s.mu.Lock()
+	user, ok := s.users[q.Get("login_hint")]
+	switch {
+	case q.Get("response_type") != "code":
+		params.Set("error", "unsupported_response_type")
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		params.Set("error", "invalid_request")
+	case !ok:
+		params.Set("error", "access_denied")
+	default:
+		s.nextCode++
+		code := fmt.Sprintf("code-%d", s.nextCode)
+		s.grants[code] = grant{
+			user:          user,
+			redirectURI:   redirectURI,
+			nonce:         q.Get("nonce"),
+			codeChallenge: q.Get("code_challenge"),
+		}
+		params.Set("code", code)
+	}
+	s.mu.Unlock()
+
+	back.RawQuery = params.Encode()
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.ClientID || (s.ClientSecret != "" && clientSecret != s.ClientSecret) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	code := r.PostForm.Get("code")
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		tokenError(w, "unsupported_grant_type")
+		return
+	case !ok || g.redirectURI != r.PostForm.Get("redirect_uri"):
+		tokenError(w, "invalid_grant")
+		return
+	case oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	idToken, err := s.SignIDToken(g.user, g.nonce)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "at-" + code,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// SignIDToken issues an ID token for the user with the current key.
+func (s *Server) SignIDToken(user User, nonce string) (string, error) {
+	now := s.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            user.Subject,
+		"aud":            s.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(s.IDTokenTTL).Unix(),
+		"nonce":          nonce,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+	}
+	for k, v := range user.Claims {
+		claims[k] = v
+	}
+
+	s.mu.Lock()
+	key, kid := s.key, s.kid()
+	s.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
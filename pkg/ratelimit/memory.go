@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type counter struct {
+	hits    int
+	resetAt time.Time
+}
+
+// MemoryStore keeps counters in the process. Each replica counts on its own.
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*counter
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*counter)}
+}
+
+func (s *MemoryStore) Incr(key string, window time.Duration, now time.Time) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.resetAt) {
+		c = &counter{resetAt: now.Add(window)}
+		s.counters[key] = c
+	}
+	c.hits++
+	return c.hits, c.resetAt, nil
+}
+
+func (s *MemoryStore) Peek(key string, now time.Time) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.resetAt) {
+		return 0, time.Time{}, nil
+	}
+	return c.hits, c.resetAt, nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.counters, key)
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, c := range s.counters {
+		if !now.Before(c.resetAt) {
+			delete(s.counters, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// Package ratelimit counts requests in fixed windows and locks keys out with
+// exponential backoff after repeated failures.
+//
+// Counters live in a Store: MemoryStore for a single process, or SQLStore to
+// share them between replicas through the database.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often windows that have ended are deleted from the store.
+const sweepInterval = 5 * time.Minute
+
+// DefaultForget is how long failures are remembered when a Backoff has no Forget.
+const DefaultForget = 24 * time.Hour
+
+// Store keeps a hit counter per key for a fixed window.
+type Store interface {
+	// Incr counts a hit on key and returns the hits in the current window
+	// and when it ends. A window of length window starts with the first hit
+	// after the previous one has ended.
+	Incr(key string, window time.Duration, now time.Time) (hits int, resetAt time.Time, err error)
+	// Peek returns the hits in key's current window, or 0 once it has ended.
+	Peek(key string, now time.Time) (hits int, resetAt time.Time, err error)
+	Delete(key string) error
+	// DeleteExpired drops the windows that ended before now.
+	DeleteExpired(now time.Time) error
+}
+
+// Rule allows Limit hits per Window. The zero Rule allows everything.
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRule parses "<limit>/<window>", e.g. "10/1m" or "100/1h". "" and
+// "off" give the zero Rule.
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "off" {
+		return Rule{}, nil
+	}
+	limit, window, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("ratelimit: rule %q is not <limit>/<window>", s)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || n < 1 {
+		return Rule{}, fmt.Errorf("ratelimit: rule %q needs a positive limit", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d <= 0 {
+		return Rule{}, fmt.Errorf("ratelimit: rule %q needs a positive window", s)
+	}
+	return Rule{Limit: n, Window: d}, nil
+}
+
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Window > 0
+}
+
+func (r Rule) String() string {
+	if !r.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", r.Limit, r.Window)
+}
+
+// Backoff locks a key out once it fails After times in a row: for Base at
+// first, doubling with each further failure up to Max. Failures are
+// forgotten after a success, or Forget (DefaultForget when unset) after the
+// first one. The zero Backoff never locks out.
+type Backoff struct {
+	After  int
+	Base   time.Duration
+	Max    time.Duration
+	Forget time.Duration
+}
+
+func (b Backoff) Enabled() bool {
+	return b.After > 0 && b.Base > 0
+}
+
+// Duration is how long the failures-th failure in a row locks the key out.
+func (b Backoff) Duration(failures int) time.Duration {
+	if !b.Enabled() || failures < b.After {
+		return 0
+	}
+	d := b.Base
+	for i := b.After; i < failures; i++ {
+		d *= 2
+		if b.Max > 0 && d >= b.Max {
+			return b.Max
+		}
+	}
+	if b.Max > 0 && d > b.Max {
+		return b.Max
+	}
+	return d
+}
+
+// Result is the outcome of a hit.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// Limiter applies rules and backoffs to keys, counting in its store.
+type Limiter struct {
+	store Store
+	now   func() time.Time
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func New(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// Allow counts a hit on key and reports whether it is within rule.
+func (l *Limiter) Allow(key string, rule Rule) (Result, error) {
+	if !rule.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	now := l.now()
+	l.sweep(now)
+
+	hits, resetAt, err := l.store.Incr("hits:"+key, rule.Window, now)
+	if err != nil {
+		return Result{Allowed: true}, err
+	}
+	return Result{
+		Allowed:   hits <= rule.Limit,
+		Limit:     rule.Limit,
+		Remaining: max(rule.Limit-hits, 0),
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// Exceeded reports whether key has already used up rule, without counting a
+// hit. Together with Allow it limits only some requests, e.g. failed ones.
+func (l *Limiter) Exceeded(key string, rule Rule) (Result, error) {
+	if !rule.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	hits, resetAt, err := l.store.Peek("hits:"+key, l.now())
+	if err != nil {
+		return Result{Allowed: true}, err
+	}
+	return Result{
+		Allowed:   hits < rule.Limit,
+		Limit:     rule.Limit,
+		Remaining: max(rule.Limit-hits, 0),
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// LockedUntil returns when key's lockout ends, or the zero time when it
+// isn't locked out.
+func (l *Limiter) LockedUntil(key string) (time.Time, error) {
+	locks, until, err := l.store.Peek("lock:"+key, l.now())
+	if err != nil || locks == 0 {
+		return time.Time{}, err
+	}
+	return until, nil
+}
+
+// Fail records a failure on key and locks it out when backoff says so. It
+// returns when the lockout ends, or the zero time.
+func (l *Limiter) Fail(key string, backoff Backoff) (time.Time, error) {
+	if !backoff.Enabled() {
+		return time.Time{}, nil
+	}
+	now := l.now()
+	forget := backoff.Forget
+	if forget <= 0 {
+		forget = DefaultForget
+	}
+	failures, _, err := l.store.Incr("fail:"+key, forget, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d := backoff.Duration(failures)
+	if d == 0 {
+		return time.Time{}, nil
+	}
+	// Start a fresh lockout window of d
+	if err := l.store.Delete("lock:" + key); err != nil {
+		return time.Time{}, err
+	}
+	_, until, err := l.store.Incr("lock:"+key, d, now)
+	return until, err
+}
+
+// Succeed forgets key's failures and ends its lockout. Without failures it
+// only reads, as it runs on every request authenticated with a valid key.
+func (l *Limiter) Succeed(key string) error {
+	if failures, _, err := l.store.Peek("fail:"+key, l.now()); err == nil && failures == 0 {
+		return nil
+	}
+	if err := l.store.Delete("fail:" + key); err != nil {
+		return err
+	}
+	return l.store.Delete("lock:" + key)
+}
+
+// sweep deletes ended windows every sweepInterval. Errors are ignored; the
+// next sweep tries again.
+func (l *Limiter) sweep(now time.Time) {
+	l.mu.Lock()
+	if now.Sub(l.lastSweep) < sweepInterval {
+		l.mu.Unlock()
+		return
+	}
+	l.lastSweep = now
+	l.mu.Unlock()
+	_ = l.store.DeleteExpired(now)
+}
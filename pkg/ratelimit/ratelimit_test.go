@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLimiter() (*Limiter, *MemoryStore, *time.Time) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	l := New(store)
+	l.now = func() time.Time { return now }
+	return l, store, &now
+}
+
+func TestParseRule(t *testing.T) {
+	for s, want := range map[string]Rule{
+		"10/1m":     {Limit: 10, Window: time.Minute},
+		" 5 / 15m ": {Limit: 5, Window: 15 * time.Minute},
+		"off":       {},
+		"":          {},
+	} {
+		got, err := ParseRule(s)
+		if err != nil || got != want {
+			t.Fatalf("ParseRule(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"10", "0/1m", "-1/1m", "10/soon", "10/0s"} {
+		if _, err := ParseRule(s); err == nil {
+			t.Fatalf("expected ParseRule(%q) to fail", s)
+		}
+	}
+}
+
+func TestAllow(t *testing.T) {
+	l, _, now := newTestLimiter()
+	rule := Rule{Limit: 3, Window: time.Minute}
+
+	for i := 1; i <= 3; i++ {
+		result, err := l.Allow("ip:10.0.0.1", rule)
+		if err != nil || !result.Allowed || result.Remaining != 3-i {
+			t.Fatalf("hit %d: %+v, %v", i, result, err)
+		}
+	}
+	result, _ := l.Allow("ip:10.0.0.1", rule)
+	if result.Allowed || !result.ResetAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected the 4th hit to be refused until the window ends, got %+v", result)
+	}
+	if result, _ := l.Allow("ip:10.0.0.2", rule); !result.Allowed {
+		t.Fatal("expected another key to have its own counter")
+	}
+
+	*now = now.Add(time.Minute)
+	if result, _ := l.Allow("ip:10.0.0.1", rule); !result.Allowed || result.Remaining != 2 {
+		t.Fatalf("expected a new window, got %+v", result)
+	}
+
+	// The zero rule allows everything
+	for i := 0; i < 10; i++ {
+		if result, _ := l.Allow("ip:10.0.0.1", Rule{}); !result.Allowed {
+			t.Fatal("expected the zero rule to allow every hit")
+		}
+	}
+}
+
+func TestExceeded(t *testing.T) {
+	l, _, now := newTestLimiter()
+	rule := Rule{Limit: 2, Window: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		if result, _ := l.Exceeded("fail:p1", rule); !result.Allowed {
+			t.Fatalf("check %d: expected room before the limit, got %+v", i+1, result)
+		}
+		l.Allow("fail:p1", rule)
+	}
+	result, _ := l.Exceeded("fail:p1", rule)
+	if result.Allowed || !result.ResetAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected the limit to be used up until the window ends, got %+v", result)
+	}
+	if again, _ := l.Exceeded("fail:p1", rule); again.Remaining != 0 || again.Allowed {
+		t.Fatalf("expected Exceeded not to count a hit, got %+v", again)
+	}
+
+	*now = now.Add(time.Minute)
+	if result, _ := l.Exceeded("fail:p1", rule); !result.Allowed {
+		t.Fatalf("expected a new window, got %+v", result)
+	}
+}
+
+func TestBackoffDuration(t *testing.T) {
+	b := Backoff{After: 3, Base: time.Minute, Max: 10 * time.Minute}
+	for failures, want := range map[int]time.Duration{
+		2:  0,
+		3:  time.Minute,
+		4:  2 * time.Minute,
+		6:  8 * time.Minute,
+		7:  10 * time.Minute,
+		50: 10 * time.Minute,
+	} {
+		if got := b.Duration(failures); got != want {
+			t.Fatalf("Duration(%d) = %s, want %s", failures, got, want)
+		}
+	}
+	if (Backoff{}).Duration(100) != 0 {
+		t.Fatal("expected the zero backoff never to lock out")
+	}
+}
+
+func TestLockout(t *testing.T) {
+	l, _, now := newTestLimiter()
+	b := Backoff{After: 2, Base: time.Minute, Max: time.Hour, Forget: 24 * time.Hour}
+	key := "login:alice:10.0.0.1"
+
+	if until, _ := l.Fail(key, b); !until.IsZero() {
+		t.Fatal("expected no lockout after the first failure")
+	}
+	until, err := l.Fail(key, b)
+	if err != nil || !until.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected a 1m lockout, got %s, %v", until, err)
+	}
+	if locked, _ := l.LockedUntil(key); !locked.Equal(until) {
+		t.Fatalf("LockedUntil = %s, want %s", locked, until)
+	}
+
+	// Each further failure doubles the lockout
+	*now = until
+	if locked, _ := l.LockedUntil(key); !locked.IsZero() {
+		t.Fatal("expected the lockout to have ended")
+	}
+	if until, _ := l.Fail(key, b); !until.Equal(now.Add(2 * time.Minute)) {
+		t.Fatalf("expected a 2m lockout, got %s", until)
+	}
+
+	// A success forgets the failures
+	if err := l.Succeed(key); err != nil {
+		t.Fatalf("Succeed: %v", err)
+	}
+	if locked, _ := l.LockedUntil(key); !locked.IsZero() {
+		t.Fatal("expected a success to end the lockout")
+	}
+	if until, _ := l.Fail(key, b); !until.IsZero() {
+		t.Fatal("expected the failures to start over after a success")
+	}
+
+	// Failures are forgotten a while after the first one
+	*now = now.Add(25 * time.Hour)
+	if until, _ := l.Fail(key, b); !until.IsZero() {
+		t.Fatal("expected old failures to be forgotten")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	l, store, now := newTestLimiter()
+	l.Allow("a", Rule{Limit: 1, Window: time.Minute})
+	*now = now.Add(sweepInterval)
+	l.Allow("b", Rule{Limit: 1, Window: time.Hour})
+
+	if _, ok := store.counters["hits:a"]; ok {
+		t.Fatal("expected the ended window to be swept")
+	}
+	if _, ok := store.counters["hits:b"]; !ok {
+		t.Fatal("expected the current window to be kept")
+	}
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxSQLKeyLen is the length of rate_limit_counters.bucket; longer keys are
+// stored as their SHA-256.
+const maxSQLKeyLen = 191
+
+// SQLStore keeps counters in the MySQL rate_limit_counters table, so every
+// replica shares them.
+type SQLStore struct {
+	db *gorm.DB
+}
+
+func NewSQLStore(db *gorm.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+type sqlCounter struct {
+	Hits    int
+	ResetAt time.Time
+}
+
+func (s *SQLStore) Incr(key string, window time.Duration, now time.Time) (int, time.Time, error) {
+	key = sqlKey(key)
+	now = now.UTC()
+	var c sqlCounter
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The upsert locks the row until commit, so the read below sees this
+		// hit and no other replica's in between
+		err := tx.Exec("INSERT INTO `rate_limit_counters` (`bucket`, `hits`, `reset_at`) VALUES (?, 1, ?) "+
+			"ON DUPLICATE KEY UPDATE `hits` = IF(`reset_at` <= ?, 1, `hits` + 1), "+
+			"`reset_at` = IF(`reset_at` <= ?, VALUES(`reset_at`), `reset_at`)",
+			key, now.Add(window), now, now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Raw("SELECT `hits`, `reset_at` FROM `rate_limit_counters` WHERE `bucket` = ?", key).Scan(&c).Error
+	})
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return c.Hits, c.ResetAt, nil
+}
+
+func (s *SQLStore) Peek(key string, now time.Time) (int, time.Time, error) {
+	var counters []sqlCounter
+	err := s.db.Raw("SELECT `hits`, `reset_at` FROM `rate_limit_counters` WHERE `bucket` = ? AND `reset_at` > ?", sqlKey(key), now.UTC()).
+		Scan(&counters).Error
+	if err != nil || len(counters) == 0 {
+		return 0, time.Time{}, err
+	}
+	return counters[0].Hits, counters[0].ResetAt, nil
+}
+
+func (s *SQLStore) Delete(key string) error {
+	return s.db.Exec("DELETE FROM `rate_limit_counters` WHERE `bucket` = ?", sqlKey(key)).Error
+}
+
+func (s *SQLStore) DeleteExpired(now time.Time) error {
+	return s.db.Exec("DELETE FROM `rate_limit_counters` WHERE `reset_at` <= ?", now.UTC()).Error
+}
+
+func sqlKey(key string) string {
+	if len(key) <= maxSQLKeyLen {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
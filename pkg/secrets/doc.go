@@ -0,0 +1,13 @@
+// Package secrets encrypts credentials stored in the database.
+//
+// Values are envelope-encrypted: each one gets a fresh AES-256-GCM data key,
+// and the data key is wrapped by a key-encryption key held by a KeyProvider
+// (keys from the environment, or a file-backed local KMS stand-in). The stored
+// form records the wrapping key's ID, so keys can be rotated and existing
+// rows re-encrypted with Rotate.
+//
+// Model fields opt in with the GORM tag `serializer:secret` and keep their
+// plain string type. API payloads never carry stored secrets: responses show
+// a Ref, and request bodies accept an Input that tells a new value apart from
+// a Ref echoed back by the client.
+package secrets
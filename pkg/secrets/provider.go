@@ -0,0 +1,201 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaticKeys is a KeyProvider over a fixed set of keys, typically from the
+// environment. Keep retired keys in the set until Rotate has re-encrypted
+// every row that uses them.
+type StaticKeys struct {
+	active string
+	keys   map[string][]byte
+}
+
+func NewStaticKeys(active string, keys map[string][]byte) (*StaticKeys, error) {
+	for id, key := range keys {
+		if err := validateKey(id, key); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("secrets: active key %q is not in the key set", active)
+	}
+	return &StaticKeys{active: active, keys: keys}, nil
+}
+
+// ParseKeys parses "id1:base64key,id2:base64key".
+func ParseKeys(spec string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("secrets: key entry %q must be id:base64key", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("secrets: key %q is not valid base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+func (s *StaticKeys) ActiveKeyID() string {
+	return s.active
+}
+
+func (s *StaticKeys) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	return seal(key, dataKey, []byte(keyID))
+}
+
+func (s *StaticKeys) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	return open(key, wrapped, []byte(keyID))
+}
+
+// LocalKMS stands in for a cloud KMS during development and on single-host
+// installs: key material lives in a JSON file (mode 0600) and never leaves
+// this type; callers only get data keys wrapped and unwrapped.
+type LocalKMS struct {
+	path string
+
+	mu    sync.RWMutex
+	state localKMSState
+}
+
+type localKMSState struct {
+	ActiveKeyID string        `json:"activeKeyId"`
+	Keys        []localKMSKey `json:"keys"`
+}
+
+type localKMSKey struct {
+	ID        string    `json:"id"`
+	Key       []byte    `json:"key"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// OpenLocalKMS loads the key file at path, creating it with a first key if it doesn't exist.
+func OpenLocalKMS(path string) (*LocalKMS, error) {
+	kms := &LocalKMS{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if _, err := kms.Rotate(); err != nil {
+			return nil, err
+		}
+		return kms, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &kms.state); err != nil {
+		return nil, fmt.Errorf("secrets: read local KMS %s: %w", path, err)
+	}
+	if _, ok := kms.key(kms.state.ActiveKeyID); !ok {
+		return nil, fmt.Errorf("secrets: local KMS %s has no active key", path)
+	}
+	return kms, nil
+}
+
+// Rotate generates a new key, makes it active and saves the key file.
+// Older keys are kept so existing values still decrypt.
+func (k *LocalKMS) Rotate() (string, error) {
+	key := make([]byte, KeySize)
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	id := fmt.Sprintf("local-%s-%x", now.Format("20060102T150405"), suffix)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	next := localKMSState{ActiveKeyID: id, Keys: append(append([]localKMSKey(nil), k.state.Keys...), localKMSKey{ID: id, Key: key, CreatedAt: now})}
+	data, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(k.path, data, 0o600); err != nil {
+		return "", fmt.Errorf("secrets: write local KMS %s: %w", k.path, err)
+	}
+	k.state = next
+	return id, nil
+}
+
+// KeyIDs lists the IDs of all keys, oldest first.
+func (k *LocalKMS) KeyIDs() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	keys := append([]localKMSKey(nil), k.state.Keys...)
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = key.ID
+	}
+	return ids
+}
+
+func (k *LocalKMS) ActiveKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.state.ActiveKeyID
+}
+
+func (k *LocalKMS) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	key, ok := k.key(keyID)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	return seal(key, dataKey, []byte(keyID))
+}
+
+func (k *LocalKMS) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.key(keyID)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	return open(key, wrapped, []byte(keyID))
+}
+
+func (k *LocalKMS) key(id string) ([]byte, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.state.Keys {
+		if key.ID == id {
+			return key.Key, true
+		}
+	}
+	return nil, false
+}
+
+func validateKey(id string, key []byte) error {
+	if id == "" || strings.ContainsAny(id, ":,") {
+		return fmt.Errorf("secrets: invalid key ID %q", id)
+	}
+	if len(key) != KeySize {
+		return fmt.Errorf("secrets: key %q must be %d bytes, got %d", id, KeySize, len(key))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Ref is how a stored secret appears in API responses: whether one is set
+// and a short hint to recognise it by, never the value itself.
+type Ref struct {
+	Set  bool   `json:"set"`
+	Hint string `json:"hint,omitempty"`
+}
+
+// RefOf describes value with its last four characters as the hint.
+func RefOf(value string) Ref {
+	if value == "" {
+		return Ref{}
+	}
+	if len(value) <= 8 {
+		return Ref{Set: true, Hint: "****"}
+	}
+	return Ref{Set: true, Hint: "****" + value[len(value)-4:]}
+}
+
+// RefOfPtr is RefOf for optional fields.
+func RefOfPtr(value *string) Ref {
+	if value == nil {
+		return Ref{}
+	}
+	return RefOf(*value)
+}
+
+// Input is a secret field in a request body. A JSON string sets a new value;
+// null, "" or a Ref object (a response echoed back unchanged) keep the stored one.
+type Input struct {
+	value string
+}
+
+func (in *Input) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		in.value = ""
+	case len(data) > 0 && data[0] == '"':
+		return json.Unmarshal(data, &in.value)
+	case len(data) > 0 && data[0] == '{':
+		var ref Ref
+		if err := json.Unmarshal(data, &ref); err != nil {
+			return err
+		}
+		in.value = ""
+	default:
+		return fmt.Errorf("secret must be a string or a reference object")
+	}
+	return nil
+}
+
+// IsSet reports whether the request supplied a new value.
+func (in Input) IsSet() bool {
+	return in.value != ""
+}
+
+// Value is the new secret, or "" when the stored one should be kept.
+func (in Input) Value() string {
+	return in.value
+}
+
+// Ptr returns the new value, or nil when the stored one should be kept.
+func (in Input) Ptr() *string {
+	if in.value == "" {
+		return nil
+	}
+	v := in.value
+	return &v
+}
@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// RotationResult counts the values of one column checked and re-encrypted by Rotate.
+type RotationResult struct {
+	Table   string
+	Column  string
+	Scanned int
+	Rotated int
+}
+
+// Rotate re-encrypts, under the keyring's active key, every value of the
+// models' `serializer:secret` columns that is plaintext or wrapped by an older
+// key. Soft-deleted rows are included. With dryRun nothing is written.
+func Rotate(db *gorm.DB, keyring *Keyring, dryRun bool, models ...interface{}) ([]RotationResult, error) {
+	var results []RotationResult
+	cache := &sync.Map{}
+	for _, model := range models {
+		s, err := schema.Parse(model, cache, db.NamingStrategy)
+		if err != nil {
+			return results, err
+		}
+		if s.PrioritizedPrimaryField == nil {
+			return results, fmt.Errorf("secrets: %s has no primary key", s.Table)
+		}
+		for _, field := range s.Fields {
+			if field.TagSettings["SERIALIZER"] != SerializerName {
+				continue
+			}
+			result, err := rotateColumn(db, keyring, dryRun, s.Table, s.PrioritizedPrimaryField.DBName, field.DBName)
+			results = append(results, result)
+			if err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
+
+func rotateColumn(db *gorm.DB, keyring *Keyring, dryRun bool, table, pk, column string) (RotationResult, error) {
+	result := RotationResult{Table: table, Column: column}
+
+	type row struct {
+		ID    uint64
+		Value *string
+	}
+	var rows []row
+	err := db.Table(table).
+		Select(fmt.Sprintf("%s AS id, %s AS value", pk, column)).
+		Where(fmt.Sprintf("%s IS NOT NULL AND %s <> ''", column, column)).
+		Scan(&rows).Error
+	if err != nil {
+		return result, fmt.Errorf("secrets: read %s.%s: %w", table, column, err)
+	}
+
+	for _, r := range rows {
+		result.Scanned++
+		if r.Value == nil || !keyring.NeedsRotation(*r.Value) {
+			continue
+		}
+		plaintext, err := keyring.Decrypt(*r.Value)
+		if err != nil {
+			return result, fmt.Errorf("secrets: %s.%s id=%d: %w", table, column, r.ID, err)
+		}
+		encrypted, err := keyring.Encrypt(plaintext)
+		if err != nil {
+			return result, err
+		}
+		if !dryRun {
+			if err := db.Table(table).Where(pk+" = ?", r.ID).Update(column, encrypted).Error; err != nil {
+				return result, fmt.Errorf("secrets: update %s.%s id=%d: %w", table, column, r.ID, err)
+			}
+		}
+		result.Rotated++
+	}
+	return result, nil
+}
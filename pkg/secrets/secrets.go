@@ -0,0 +1,179 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// prefix marks an encrypted value: enc:v1:<keyID>:<wrapped data key>:<ciphertext>.
+const prefix = "enc:v1:"
+
+// KeySize is the size of key-encryption and data keys (AES-256).
+const KeySize = 32
+
+var (
+	ErrNoKeyring = errors.New("secrets: value is encrypted but no key is configured")
+	ErrMalformed = errors.New("secrets: malformed encrypted value")
+)
+
+// KeyProvider holds the key-encryption keys and wraps data keys with them.
+type KeyProvider interface {
+	// ActiveKeyID is the key new values are encrypted with.
+	ActiveKeyID() string
+	WrapKey(keyID string, dataKey []byte) ([]byte, error)
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Keyring encrypts and decrypts stored values with a KeyProvider.
+type Keyring struct {
+	provider KeyProvider
+}
+
+func NewKeyring(provider KeyProvider) *Keyring {
+	return &Keyring{provider: provider}
+}
+
+// Encrypt seals plaintext under a new data key wrapped by the active key.
+// The empty string stays empty so "not set" survives a round trip.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	keyID := k.provider.ActiveKeyID()
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrapped, err := k.provider.WrapKey(keyID, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("secrets: wrap data key: %w", err)
+	}
+	sealed, err := seal(dataKey, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawStdEncoding
+	return prefix + keyID + ":" + enc.EncodeToString(wrapped) + ":" + enc.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt. Values without the encryption
+// prefix are legacy plaintext and are returned unchanged.
+func (k *Keyring) Decrypt(stored string) (string, error) {
+	keyID, wrapped, sealed, err := parse(stored)
+	if err != nil || keyID == "" {
+		return stored, err
+	}
+	dataKey, err := k.provider.UnwrapKey(keyID, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("secrets: unwrap data key: %w", err)
+	}
+	plaintext, err := open(dataKey, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether stored is plaintext or wrapped by a key other than the active one.
+func (k *Keyring) NeedsRotation(stored string) bool {
+	if stored == "" {
+		return false
+	}
+	keyID, ok := KeyID(stored)
+	return !ok || keyID != k.provider.ActiveKeyID()
+}
+
+// IsEncrypted reports whether s is in the encrypted format.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// KeyID returns the ID of the key that wrapped an encrypted value.
+func KeyID(stored string) (string, bool) {
+	keyID, _, _, err := parse(stored)
+	return keyID, err == nil && keyID != ""
+}
+
+// parse splits an encrypted value; it returns an empty key ID for plaintext.
+func parse(stored string) (keyID string, wrapped, sealed []byte, err error) {
+	if !IsEncrypted(stored) {
+		return "", nil, nil, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(stored, prefix), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, ErrMalformed
+	}
+	enc := base64.RawStdEncoding
+	if wrapped, err = enc.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	if sealed, err = enc.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	return parts[0], wrapped, sealed, nil
+}
+
+// seal encrypts with AES-256-GCM and prepends the nonce.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrMalformed
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secrets: key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultKeyring *Keyring
+)
+
+// SetDefault installs the keyring used by the GORM serializer. With no
+// keyring, new values are stored as plaintext.
+func SetDefault(k *Keyring) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKeyring = k
+}
+
+// Default returns the keyring installed by SetDefault, or nil.
+func Default() *Keyring {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultKeyring
+}
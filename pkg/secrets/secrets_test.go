@@ -0,0 +1,227 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func newStaticKeyring(t *testing.T, active string) *Keyring {
+	t.Helper()
+	provider, err := NewStaticKeys(active, map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	if err != nil {
+		t.Fatalf("NewStaticKeys: %v", err)
+	}
+	return NewKeyring(provider)
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	keyring := newStaticKeyring(t, "k1")
+
+	stored, err := keyring.Encrypt("cw-token-1234")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(stored) || strings.Contains(stored, "cw-token") {
+		t.Fatalf("expected an encrypted value, got %q", stored)
+	}
+	if id, ok := KeyID(stored); !ok || id != "k1" {
+		t.Fatalf("expected key k1, got %q", id)
+	}
+	again, _ := keyring.Encrypt("cw-token-1234")
+	if again == stored {
+		t.Fatal("expected a fresh data key and nonce per value")
+	}
+
+	plaintext, err := keyring.Decrypt(stored)
+	if err != nil || plaintext != "cw-token-1234" {
+		t.Fatalf("Decrypt = %q, %v", plaintext, err)
+	}
+	if legacy, err := keyring.Decrypt("plain-token"); err != nil || legacy != "plain-token" {
+		t.Fatalf("expected legacy plaintext to pass through, got %q, %v", legacy, err)
+	}
+	if empty, _ := keyring.Encrypt(""); empty != "" {
+		t.Fatalf("expected empty string to stay empty, got %q", empty)
+	}
+
+	tampered := stored[:len(stored)-2] + "AA"
+	if _, err := keyring.Decrypt(tampered); err == nil {
+		t.Fatal("expected tampered ciphertext to fail")
+	}
+	if _, err := keyring.Decrypt(prefix + "k1:???"); err != ErrMalformed {
+		t.Fatalf("expected ErrMalformed, got %v", err)
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old := newStaticKeyring(t, "k1")
+	stored, _ := old.Encrypt("secret")
+
+	current := newStaticKeyring(t, "k2")
+	if !current.NeedsRotation(stored) || !current.NeedsRotation("plaintext") || current.NeedsRotation("") {
+		t.Fatal("expected old-key and plaintext values to need rotation")
+	}
+	if plaintext, err := current.Decrypt(stored); err != nil || plaintext != "secret" {
+		t.Fatalf("expected retired key to still decrypt, got %q, %v", plaintext, err)
+	}
+	rotated, _ := current.Encrypt("secret")
+	if current.NeedsRotation(rotated) {
+		t.Fatal("expected value under the active key to be current")
+	}
+
+	onlyNew, _ := NewStaticKeys("k2", map[string][]byte{"k2": testKey(2)})
+	if _, err := NewKeyring(onlyNew).Decrypt(stored); err == nil {
+		t.Fatal("expected decrypt to fail once the old key is dropped")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testKey(3))
+	keys, err := ParseKeys("a:" + encoded + ", b:" + encoded)
+	if err != nil || len(keys) != 2 || !bytes.Equal(keys["b"], testKey(3)) {
+		t.Fatalf("ParseKeys = %v, %v", keys, err)
+	}
+	for _, spec := range []string{"nokey", "a:not-base64!"} {
+		if _, err := ParseKeys(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+	if _, err := NewStaticKeys("a", map[string][]byte{"a": []byte("short")}); err == nil {
+		t.Fatal("expected error for a short key")
+	}
+	if _, err := NewStaticKeys("missing", keys); err == nil {
+		t.Fatal("expected error for an unknown active key")
+	}
+}
+
+func TestLocalKMS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kms.json")
+	kms, err := OpenLocalKMS(path)
+	if err != nil {
+		t.Fatalf("OpenLocalKMS: %v", err)
+	}
+	first := kms.ActiveKeyID()
+	stored, err := NewKeyring(kms).Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	second, err := kms.Rotate()
+	if err != nil || second == first {
+		t.Fatalf("Rotate = %q, %v", second, err)
+	}
+
+	reopened, err := OpenLocalKMS(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if reopened.ActiveKeyID() != second || len(reopened.KeyIDs()) != 2 {
+		t.Fatalf("expected the rotated key to persist, got %q %v", reopened.ActiveKeyID(), reopened.KeyIDs())
+	}
+	keyring := NewKeyring(reopened)
+	if plaintext, err := keyring.Decrypt(stored); err != nil || plaintext != "secret" {
+		t.Fatalf("Decrypt after rotation = %q, %v", plaintext, err)
+	}
+	if !keyring.NeedsRotation(stored) {
+		t.Fatal("expected value under the first key to need rotation")
+	}
+}
+
+type secretModel struct {
+	ID       uint
+	Token    string  `gorm:"serializer:secret"`
+	Optional *string `gorm:"serializer:secret"`
+}
+
+func TestSerializer(t *testing.T) {
+	s, err := schema.Parse(&secretModel{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("schema.Parse: %v", err)
+	}
+	token, optional := s.LookUpField("Token"), s.LookUpField("Optional")
+	ctx := context.Background()
+
+	t.Cleanup(func() { SetDefault(nil) })
+	SetDefault(nil)
+	plain := "abc"
+	model := &secretModel{Token: "abc", Optional: &plain}
+	if v, err := token.Serializer.Value(ctx, token, reflect.ValueOf(model), model.Token); err != nil || v != "abc" {
+		t.Fatalf("expected plaintext without a keyring, got %v, %v", v, err)
+	}
+
+	SetDefault(newStaticKeyring(t, "k1"))
+	v, err := token.Serializer.Value(ctx, token, reflect.ValueOf(model), model.Token)
+	if err != nil || !IsEncrypted(v.(string)) {
+		t.Fatalf("expected encrypted value, got %v, %v", v, err)
+	}
+	if v, _ := optional.Serializer.Value(ctx, optional, reflect.ValueOf(model), (*string)(nil)); v != nil {
+		t.Fatalf("expected nil for a nil pointer, got %v", v)
+	}
+
+	var loaded secretModel
+	dst := reflect.ValueOf(&loaded).Elem()
+	if err := token.Serializer.Scan(ctx, token, dst, []byte(v.(string))); err != nil || loaded.Token != "abc" {
+		t.Fatalf("Scan = %q, %v", loaded.Token, err)
+	}
+	if err := optional.Serializer.Scan(ctx, optional, dst, v.(string)); err != nil || loaded.Optional == nil || *loaded.Optional != "abc" {
+		t.Fatalf("Scan pointer = %v, %v", loaded.Optional, err)
+	}
+	if err := optional.Serializer.Scan(ctx, optional, dst, nil); err != nil || loaded.Optional != nil {
+		t.Fatalf("expected nil pointer for NULL, got %v, %v", loaded.Optional, err)
+	}
+	if err := token.Serializer.Scan(ctx, token, dst, "legacy"); err != nil || loaded.Token != "legacy" {
+		t.Fatalf("expected legacy plaintext to load, got %q, %v", loaded.Token, err)
+	}
+
+	SetDefault(nil)
+	if err := token.Serializer.Scan(ctx, token, dst, v.(string)); err == nil {
+		t.Fatal("expected an error reading an encrypted value without a keyring")
+	}
+}
+
+func TestRefAndInput(t *testing.T) {
+	if ref := RefOf("cwk_0123456789"); !ref.Set || ref.Hint != "****6789" {
+		t.Fatalf("unexpected ref %+v", ref)
+	}
+	if ref := RefOf("short"); ref.Hint != "****" {
+		t.Fatalf("expected short values to be fully hidden, got %+v", ref)
+	}
+	if ref := RefOfPtr(nil); ref.Set {
+		t.Fatalf("expected unset ref, got %+v", ref)
+	}
+
+	var body struct {
+		New   Input `json:"new"`
+		Echo  Input `json:"echo"`
+		Null  Input `json:"null"`
+		Empty Input `json:"empty"`
+		Gone  Input `json:"gone"`
+	}
+	raw := `{"new":"tok-1","echo":{"set":true,"hint":"****6789"},"null":null,"empty":""}`
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !body.New.IsSet() || body.New.Value() != "tok-1" || *body.New.Ptr() != "tok-1" {
+		t.Fatalf("expected a new value, got %+v", body.New)
+	}
+	for name, in := range map[string]Input{"echo": body.Echo, "null": body.Null, "empty": body.Empty, "gone": body.Gone} {
+		if in.IsSet() || in.Ptr() != nil {
+			t.Fatalf("expected %s to keep the stored value", name)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"new":42}`), &body); err == nil {
+		t.Fatal("expected an error for a number")
+	}
+}
@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the GORM serializer that encrypts a column with the default keyring.
+const SerializerName = "secret"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Serializer encrypts string and *string fields on write and decrypts them on read.
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+		field.ReflectValueOf(ctx, dst).Set(reflect.Zero(field.FieldType))
+		return nil
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("secrets: unsupported column value %T for %s", dbValue, field.Name)
+	}
+
+	plaintext := stored
+	if IsEncrypted(stored) {
+		keyring := Default()
+		if keyring == nil {
+			return fmt.Errorf("%w (field %s)", ErrNoKeyring, field.Name)
+		}
+		var err error
+		if plaintext, err = keyring.Decrypt(stored); err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+	}
+
+	value := reflect.ValueOf(plaintext)
+	if field.FieldType.Kind() == reflect.Ptr {
+		value = reflect.ValueOf(&plaintext)
+	}
+	field.ReflectValueOf(ctx, dst).Set(value)
+	return nil
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext string
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = v
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		plaintext = *v
+	default:
+		return nil, fmt.Errorf("secrets: unsupported field type %T for %s", fieldValue, field.Name)
+	}
+
+	keyring := Default()
+	if keyring == nil {
+		return plaintext, nil
+	}
+	return keyring.Encrypt(plaintext)
+}